@@ -0,0 +1,219 @@
+// Package aihistory records AI requests and their answers so they can be
+// replayed with different provider settings and looked up later as a
+// notebook of past sessions.
+package aihistory
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+)
+
+// ErrEmpty is returned by Last when nothing was recorded for a repository.
+var ErrEmpty = errors.New("no AI requests recorded for this repository yet")
+
+// Selection is the diff an AI command looked at, in a form that can be
+// loaded again.
+type Selection struct {
+	Staged            bool                  `json:"staged,omitempty"`
+	File              string                `json:"file,omitempty"`
+	Stash             *int                  `json:"stash,omitempty"`
+	Against           string                `json:"against,omitempty"`
+	Untracked         bool                  `json:"untracked,omitempty"`
+	RecurseSubmodules bool                  `json:"recurseSubmodules,omitempty"`
+	Renames           git.RenameDetection   `json:"renames"`
+	Whitespace        git.WhitespaceOptions `json:"whitespace"`
+	Question          string                `json:"question,omitempty"`
+	Structured        bool                  `json:"structured,omitempty"`
+	Refine            *bool                 `json:"refine,omitempty"`
+	Ticket            string                `json:"ticket,omitempty"`
+	Paths             []string              `json:"paths,omitempty"`
+}
+
+// Entry is one answered request. ID is derived from the time, repository
+// and kind when the entry is appended; Ref names what was looked at
+// ("working tree", a commit, a branch comparison) and DiffHash identifies
+// the exact diff that was sent.
+type Entry struct {
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time"`
+	Repo        string    `json:"repo"`
+	Kind        string    `json:"kind"`
+	Source      string    `json:"source,omitempty"`
+	Ref         string    `json:"ref,omitempty"`
+	Selection   Selection `json:"selection"`
+	DiffHash    string    `json:"diffHash,omitempty"`
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model"`
+	Temperature float64   `json:"temperature"`
+	Prompt      string    `json:"prompt,omitempty"`
+	Response    string    `json:"response"`
+	// Preloaded marks a diff `again` cannot select again, such as a
+	// fetched pull request or a commit picked in the web UI.
+	Preloaded bool `json:"preloaded,omitempty"`
+}
+
+// ErrNotFound is returned by Find when no entry has the ID.
+var ErrNotFound = errors.New("no AI session with that ID")
+
+// HashDiffs identifies a diff by content, so sessions about the same
+// changes can be recognized.
+func HashDiffs(diffs []git.ParsedDiff) string {
+	b, _ := json.Marshal(diffs)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:6])
+}
+
+func entryID(e Entry) string {
+	sum := sha256.Sum256([]byte(e.Time.UTC().Format(time.RFC3339Nano) + "\x00" + e.Repo + "\x00" + e.Kind))
+	return hex.EncodeToString(sum[:4])
+}
+
+// Store is an append-only JSON Lines file of entries.
+type Store struct {
+	path string
+}
+
+// Open returns the store in config.DataDir.
+func Open() *Store {
+	return NewStore(filepath.Join(config.DataDir(), "ai-history.jsonl"))
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) Append(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.ID == "" {
+		e.ID = entryID(e)
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Entries returns every recorded entry, oldest first. Lines that fail to
+// decode are skipped so one bad write does not hide the rest.
+func (s *Store) Entries() ([]Entry, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			// Entries from before IDs existed get theirs on read.
+			if e.ID == "" {
+				e.ID = entryID(e)
+			}
+			out = append(out, e)
+		}
+	}
+	return out, sc.Err()
+}
+
+// Last returns the most recent replayable entry recorded for repo.
+func (s *Store) Last(repo string) (Entry, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return Entry{}, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Repo == repo && !entries[i].Preloaded {
+			return entries[i], nil
+		}
+	}
+	return Entry{}, ErrEmpty
+}
+
+// Query selects entries for Search. Empty fields match everything; Text
+// matches the question, ref, prompt and response case-insensitively.
+type Query struct {
+	Repo  string
+	Kind  string
+	Text  string
+	Limit int
+}
+
+// Search returns the entries matching q, newest first.
+func (s *Store) Search(q Query) ([]Entry, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	out := []Entry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if (q.Repo != "" && e.Repo != q.Repo) || (q.Kind != "" && !strings.HasPrefix(e.Kind, q.Kind)) {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(strings.Join([]string{e.Selection.Question, e.Ref, e.Prompt, e.Response}, "\n")), text) {
+			continue
+		}
+		out = append(out, e)
+		if q.Limit > 0 && len(out) == q.Limit {
+			break
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+	return out, nil
+}
+
+// Find returns the entry whose ID starts with id. A prefix shared by
+// several entries is an error.
+func (s *Store) Find(id string) (Entry, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return Entry{}, err
+	}
+	var found []Entry
+	for _, e := range entries {
+		if id != "" && strings.HasPrefix(e.ID, id) {
+			found = append(found, e)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Entry{}, ErrNotFound
+	case 1:
+		return found[0], nil
+	}
+	return Entry{}, fmt.Errorf("session ID %q is ambiguous (%d matches)", id, len(found))
+}
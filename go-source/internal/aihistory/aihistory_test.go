@@ -0,0 +1,87 @@
+package aihistory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"difflearn-go/internal/git"
+)
+
+func TestStoreLastPerRepo(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "data", "ai-history.jsonl"))
+	if _, err := s.Last("/repo/a"); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("expected ErrEmpty from an empty store, got %v", err)
+	}
+	stash := 2
+	for _, e := range []Entry{
+		{Repo: "/repo/a", Kind: "explain", Selection: Selection{Staged: true}},
+		{Repo: "/repo/a", Kind: "review", Selection: Selection{Stash: &stash}, Model: "gpt-4o"},
+		{Repo: "/repo/b", Kind: "summary"},
+	} {
+		if err := s.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, _ := os.OpenFile(s.Path(), os.O_APPEND|os.O_WRONLY, 0)
+	_, _ = f.WriteString("{not json\n")
+	f.Close()
+
+	last, err := s.Last("/repo/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Kind != "review" || last.Selection.Stash == nil || *last.Selection.Stash != 2 || last.Time.IsZero() {
+		t.Fatalf("unexpected last entry: %+v", last)
+	}
+	entries, err := s.Entries()
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected 3 decodable entries, got %d (%v)", len(entries), err)
+	}
+}
+
+func TestStoreSearchAndFind(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "ai-history.jsonl"))
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, e := range []Entry{
+		{Repo: "/repo/a", Kind: "explain", Ref: "working tree", Response: "Adds a retry loop around the HTTP call."},
+		{Repo: "/repo/a", Kind: "ask", Selection: Selection{Question: "Why a mutex?"}, Response: "To guard the cache."},
+		{Repo: "/repo/b", Kind: "explain", Response: "Renames a variable."},
+		{Repo: "/repo/a", Kind: "explain-file", Ref: "abc1234", Response: "The parser now handles retry headers."},
+	} {
+		e.Time = base.Add(time.Duration(i) * time.Minute)
+		if err := s.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.Search(Query{Repo: "/repo/a", Kind: "explain", Text: "RETRY"})
+	if err != nil || len(got) != 2 || got[0].Ref != "abc1234" || got[1].Ref != "working tree" {
+		t.Fatalf("Search = %+v, %v", got, err)
+	}
+	if got, _ := s.Search(Query{Repo: "/repo/a", Limit: 1}); len(got) != 1 || got[0].Kind != "explain-file" {
+		t.Fatalf("Search with limit = %+v", got)
+	}
+	if got, _ := s.Search(Query{Text: "mutex"}); len(got) != 1 || got[0].Kind != "ask" {
+		t.Fatalf("Search on the question = %+v", got)
+	}
+
+	all, _ := s.Entries()
+	e, err := s.Find(all[1].ID[:6])
+	if err != nil || e.Kind != "ask" {
+		t.Fatalf("Find = %+v, %v", e, err)
+	}
+	if _, err := s.Find("zzzz"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestHashDiffs(t *testing.T) {
+	a := []git.ParsedDiff{{NewFile: "a.go", Additions: 1}}
+	b := []git.ParsedDiff{{NewFile: "a.go", Additions: 2}}
+	if HashDiffs(a) == HashDiffs(b) || HashDiffs(a) != HashDiffs([]git.ParsedDiff{{NewFile: "a.go", Additions: 1}}) {
+		t.Fatal("HashDiffs should depend only on the diff content")
+	}
+}
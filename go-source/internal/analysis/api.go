@@ -0,0 +1,172 @@
+package analysis
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// APIChange is a public declaration that was added, removed or had its
+// signature changed. Old and New are the declaration lines.
+type APIChange struct {
+	File string     `json:"file"`
+	Name string     `json:"name"`
+	Kind ChangeKind `json:"kind"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+// Breaking reports whether callers of the old API may stop working.
+func (c APIChange) Breaking() bool {
+	return c.Kind == ChangeRemoved || c.Kind == ChangeModified
+}
+
+var publicPatterns = map[string][]*regexp.Regexp{
+	".go": {
+		regexp.MustCompile(`^func\s+([A-Z]\w*)\s*[\[(]`),
+		regexp.MustCompile(`^type\s+([A-Z]\w*)`),
+		regexp.MustCompile(`^(?:const|var)\s+([A-Z]\w*)`),
+	},
+	// Top-level names without a leading underscore are public by convention.
+	".py": {
+		regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z]\w*)\s*\(`),
+		regexp.MustCompile(`^class\s+([A-Za-z]\w*)`),
+	},
+	".rs": {
+		regexp.MustCompile(`^\s*pub\s+(?:async\s+)?(?:fn|struct|enum|trait|type|const|static)\s+([A-Za-z_]\w*)`),
+	},
+	".java": {
+		regexp.MustCompile(`^\s*public\s[\w<>\[\],\s]*?\b(?:class|interface|enum|record)\s+([A-Za-z_]\w*)`),
+		regexp.MustCompile(`^\s*public\s[\w<>\[\],\s]*\s([A-Za-z_]\w*)\s*\(`),
+	},
+}
+
+// goMethodRe matches methods; only those of exported types are public.
+var goMethodRe = regexp.MustCompile(`^func\s+\(\s*(?:\w+\s+)?\*?([A-Za-z_]\w*)[^)]*\)\s*([A-Z]\w*)\s*[\[(]`)
+
+var jsPublicPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^export\s+(?:default\s+)?(?:async\s+)?(?:function\*?|class|interface|type|enum|const|let|var)\s+([A-Za-z_$][\w$]*)`),
+}
+
+// PublicDeclaration returns the name a line declares when it is part of the
+// public API of the file: exported Go names (methods as Type.Method), JavaScript and TypeScript
+// exports, top-level Python names, Rust pub items and public Java members.
+// Test files have no public API.
+func PublicDeclaration(path, line string) (string, bool) {
+	if isTestPath(path) {
+		return "", false
+	}
+	var patterns []*regexp.Regexp
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".go":
+		if m := goMethodRe.FindStringSubmatch(line); m != nil {
+			if m[1][0] < 'A' || m[1][0] > 'Z' {
+				return "", false
+			}
+			return m[1] + "." + m[2], true
+		}
+		patterns = publicPatterns[ext]
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		patterns = jsPublicPatterns
+	case ".kt", ".cs", ".scala":
+		patterns = publicPatterns[".java"]
+	default:
+		patterns = publicPatterns[ext]
+	}
+	for _, re := range patterns {
+		if m := re.FindStringSubmatch(line); m != nil {
+			if strings.HasPrefix(m[1], "_") {
+				return "", false
+			}
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// DetectAPIChanges compares the public declarations removed and added by
+// diffs. A declaration whose line changed is Modified, unless only its
+// whitespace did; one removed from a file and added unchanged to another
+// moved and is left out.
+func DetectAPIChanges(diffs []git.ParsedDiff) []APIChange {
+	type decl struct{ file, name string }
+	removed := map[decl]string{}
+	added := map[decl]string{}
+	var order []decl
+	for _, d := range diffs {
+		// Renamed files keep their declarations under the new path.
+		path := d.NewFile
+		if d.IsDeleted {
+			path = d.OldFile
+		}
+		for _, h := range d.Hunks {
+			for _, l := range h.Lines {
+				file := path
+				if l.Type == git.LineDelete {
+					file = d.OldFile
+				}
+				name, ok := PublicDeclaration(file, l.Content)
+				if !ok {
+					continue
+				}
+				k := decl{path, name}
+				signature := strings.Join(strings.Fields(strings.TrimSuffix(strings.TrimSpace(l.Content), "{")), " ")
+				switch l.Type {
+				case git.LineAdd:
+					added[k] = signature
+				case git.LineDelete:
+					removed[k] = signature
+				default:
+					continue
+				}
+				order = append(order, k)
+			}
+		}
+	}
+
+	moved := map[string]bool{}
+	for k, sig := range removed {
+		if _, ok := added[k]; ok {
+			continue
+		}
+		for a, asig := range added {
+			if a.name == k.name && asig == sig {
+				moved[k.name+"\x00"+sig] = true
+			}
+		}
+	}
+	changes := make([]APIChange, 0)
+	seen := map[decl]bool{}
+	for _, k := range order {
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		before, wasRemoved := removed[k]
+		after, wasAdded := added[k]
+		switch {
+		case wasRemoved && wasAdded:
+			if before != after {
+				changes = append(changes, APIChange{File: k.file, Name: k.name, Kind: ChangeModified, Old: before, New: after})
+			}
+		case wasRemoved:
+			if !moved[k.name+"\x00"+before] {
+				changes = append(changes, APIChange{File: k.file, Name: k.name, Kind: ChangeRemoved, Old: before})
+			}
+		case wasAdded:
+			if !moved[k.name+"\x00"+after] {
+				changes = append(changes, APIChange{File: k.file, Name: k.name, Kind: ChangeAdded, New: after})
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].File != changes[j].File {
+			return changes[i].File < changes[j].File
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
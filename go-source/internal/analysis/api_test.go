@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+func TestDetectAPIChanges(t *testing.T) {
+	diffs := []git.ParsedDiff{
+		{OldFile: "client.go", NewFile: "client.go", Hunks: []git.ParsedHunk{hunkWith(
+			del("func NewClient(url string) *Client {"), add("func NewClient(url string, timeout time.Duration) *Client {"),
+			del("func (c *Client) Close() {"),
+			add("func (c *Client) Ping() error {"),
+			del("func helper() {"),
+			del("func (c *client) Reset() {"),
+			del("type  Options struct {"), add("type Options struct {"),
+		)}},
+		{OldFile: "util.go", NewFile: "util.go", IsDeleted: true, Hunks: []git.ParsedHunk{hunkWith(del("func Join(a, b string) string {"))}},
+		{OldFile: "strings.go", NewFile: "strings.go", IsNew: true, Hunks: []git.ParsedHunk{hunkWith(add("func Join(a, b string) string {"))}},
+		{OldFile: "web/api.ts", NewFile: "web/api.ts", Hunks: []git.ParsedHunk{hunkWith(del("export function fetchUser(id: string) {"), add("function internal() {"))}},
+		{OldFile: "client_test.go", NewFile: "client_test.go", Hunks: []git.ParsedHunk{hunkWith(del("func TestClient(t *testing.T) {"))}},
+	}
+
+	got := map[string]APIChange{}
+	for _, c := range DetectAPIChanges(diffs) {
+		got[c.Name] = c
+	}
+	if len(got) != 4 {
+		t.Fatalf("unexpected API changes: %+v", got)
+	}
+	if c := got["NewClient"]; c.Kind != ChangeModified || c.New != "func NewClient(url string, timeout time.Duration) *Client" || !c.Breaking() {
+		t.Errorf("NewClient = %+v", c)
+	}
+	if c := got["Client.Close"]; c.Kind != ChangeRemoved || !c.Breaking() {
+		t.Errorf("Close = %+v", c)
+	}
+	if c := got["Client.Ping"]; c.Kind != ChangeAdded || c.Breaking() {
+		t.Errorf("Ping = %+v", c)
+	}
+	if c := got["fetchUser"]; c.Kind != ChangeRemoved || c.File != "web/api.ts" {
+		t.Errorf("fetchUser = %+v", c)
+	}
+}
@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+type DependencyChange struct {
+	Manifest   string     `json:"manifest"`
+	Name       string     `json:"name"`
+	OldVersion string     `json:"oldVersion,omitempty"`
+	NewVersion string     `json:"newVersion,omitempty"`
+	Kind       ChangeKind `json:"kind"`
+}
+
+var (
+	goModRequireRe = regexp.MustCompile(`^\s*(?:require\s+)?([\w.\-]+\.[\w.\-/]+)\s+(v[\w.\-+]+)`)
+	packageJSONRe  = regexp.MustCompile(`^\s*"(@?[\w.\-/]+)"\s*:\s*"([~^<>=]*\d[^"]*|latest|workspace:[^"]*)"`)
+	requirementsRe = regexp.MustCompile(`^\s*([A-Za-z0-9_.\-\[\]]+)\s*(?:[=<>~!]=?\s*([\w.\-*]+))?\s*$`)
+	cargoRe        = regexp.MustCompile(`^\s*([\w\-]+)\s*=\s*(?:"([^"]+)"|\{.*version\s*=\s*"([^"]+)")`)
+)
+
+// IsManifest reports whether path is a dependency manifest DiffLearn understands.
+func IsManifest(path string) bool {
+	switch filepath.Base(path) {
+	case "go.mod", "package.json", "requirements.txt", "Cargo.toml":
+		return true
+	}
+	return false
+}
+
+func parseDependencyLine(manifest, line string) (string, string, bool) {
+	switch filepath.Base(manifest) {
+	case "go.mod":
+		if m := goModRequireRe.FindStringSubmatch(line); len(m) == 3 {
+			return m[1], m[2], true
+		}
+	case "package.json":
+		if m := packageJSONRe.FindStringSubmatch(line); len(m) == 3 {
+			return m[1], m[2], true
+		}
+	case "requirements.txt":
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") {
+			return "", "", false
+		}
+		if m := requirementsRe.FindStringSubmatch(line); len(m) == 3 {
+			return m[1], m[2], true
+		}
+	case "Cargo.toml":
+		if m := cargoRe.FindStringSubmatch(line); len(m) == 4 {
+			version := m[2]
+			if version == "" {
+				version = m[3]
+			}
+			return m[1], version, true
+		}
+	}
+	return "", "", false
+}
+
+// DetectDependencyChanges compares added and removed manifest lines to report
+// dependencies that were added, removed or had their version changed.
+func DetectDependencyChanges(diffs []git.ParsedDiff) []DependencyChange {
+	changes := make([]DependencyChange, 0)
+	for _, d := range diffs {
+		manifest := d.NewFile
+		if d.IsDeleted {
+			manifest = d.OldFile
+		}
+		if !IsManifest(manifest) {
+			continue
+		}
+		added := map[string]string{}
+		removed := map[string]string{}
+		for _, h := range d.Hunks {
+			for _, l := range h.Lines {
+				name, version, ok := parseDependencyLine(manifest, l.Content)
+				if !ok {
+					continue
+				}
+				switch l.Type {
+				case git.LineAdd:
+					added[name] = version
+				case git.LineDelete:
+					removed[name] = version
+				}
+			}
+		}
+		for name, version := range added {
+			if old, ok := removed[name]; ok {
+				if old != version {
+					changes = append(changes, DependencyChange{Manifest: manifest, Name: name, OldVersion: old, NewVersion: version, Kind: ChangeUpdated})
+				}
+				continue
+			}
+			changes = append(changes, DependencyChange{Manifest: manifest, Name: name, NewVersion: version, Kind: ChangeAdded})
+		}
+		for name, version := range removed {
+			if _, ok := added[name]; !ok {
+				changes = append(changes, DependencyChange{Manifest: manifest, Name: name, OldVersion: version, Kind: ChangeRemoved})
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Manifest != changes[j].Manifest {
+			return changes[i].Manifest < changes[j].Manifest
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
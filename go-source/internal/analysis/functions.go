@@ -0,0 +1,140 @@
+package analysis
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+	ChangeUpdated  ChangeKind = "updated"
+)
+
+type FunctionChange struct {
+	File string     `json:"file"`
+	Name string     `json:"name"`
+	Kind ChangeKind `json:"kind"`
+}
+
+var functionPatterns = map[string][]*regexp.Regexp{
+	".go": {
+		regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)\s*[\[(]`),
+	},
+	".py": {
+		regexp.MustCompile(`^\s*(?:async\s+)?def\s+([A-Za-z_]\w*)\s*\(`),
+		regexp.MustCompile(`^\s*class\s+([A-Za-z_]\w*)`),
+	},
+	".rs": {
+		regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?fn\s+([A-Za-z_]\w*)`),
+	},
+	".rb": {
+		regexp.MustCompile(`^\s*def\s+(?:self\.)?([A-Za-z_]\w*[?!]?)`),
+	},
+	".java": {
+		regexp.MustCompile(`^\s*(?:public|private|protected)[\w<>\[\],\s]*\s([A-Za-z_]\w*)\s*\(`),
+	},
+}
+
+var jsPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+([A-Za-z_$][\w$]*)`),
+	regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:async\s+)?(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*=>`),
+	regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?class\s+([A-Za-z_$][\w$]*)`),
+}
+
+func patternsFor(path string) []*regexp.Regexp {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		return jsPatterns
+	case ".kt", ".cs", ".scala":
+		return functionPatterns[".java"]
+	}
+	return functionPatterns[ext]
+}
+
+// FunctionName returns the declared function/class name on a source line, if
+// the line looks like a declaration in the file's language.
+func FunctionName(path, line string) (string, bool) {
+	for _, re := range patternsFor(path) {
+		if m := re.FindStringSubmatch(line); len(m) > 1 {
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// DetectFunctionChanges lists declarations added or removed by the diff, plus
+// functions whose bodies changed according to hunk header context.
+func DetectFunctionChanges(diffs []git.ParsedDiff) []FunctionChange {
+	changes := make([]FunctionChange, 0)
+	for _, d := range diffs {
+		path := d.NewFile
+		if d.IsDeleted {
+			path = d.OldFile
+		}
+		added := map[string]bool{}
+		removed := map[string]bool{}
+		modified := map[string]bool{}
+		for _, h := range d.Hunks {
+			if name, ok := hunkContextFunction(path, h.Header); ok {
+				modified[name] = true
+			}
+			for _, l := range h.Lines {
+				name, ok := FunctionName(path, l.Content)
+				if !ok {
+					continue
+				}
+				switch l.Type {
+				case git.LineAdd:
+					added[name] = true
+				case git.LineDelete:
+					removed[name] = true
+				}
+			}
+		}
+		for name := range added {
+			if removed[name] {
+				modified[name] = true
+				continue
+			}
+			changes = append(changes, FunctionChange{File: path, Name: name, Kind: ChangeAdded})
+		}
+		for name := range removed {
+			if !added[name] {
+				changes = append(changes, FunctionChange{File: path, Name: name, Kind: ChangeRemoved})
+			}
+		}
+		for name := range modified {
+			if added[name] != removed[name] {
+				continue
+			}
+			changes = append(changes, FunctionChange{File: path, Name: name, Kind: ChangeModified})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].File != changes[j].File {
+			return changes[i].File < changes[j].File
+		}
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func hunkContextFunction(path, header string) (string, bool) {
+	idx := strings.LastIndex(header, "@@")
+	if idx < 0 || idx+2 >= len(header) {
+		return "", false
+	}
+	return FunctionName(path, strings.TrimSpace(header[idx+2:]))
+}
@@ -0,0 +1,224 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+type FileChange struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"oldPath,omitempty"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Hunks     int    `json:"hunks"`
+}
+
+type MovedFile struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Similarity float64 `json:"similarity"`
+}
+
+type OfflineReport struct {
+	Files        []FileChange       `json:"files"`
+	Moves        []MovedFile        `json:"moves"`
+	Functions    []FunctionChange   `json:"functions"`
+	Dependencies []DependencyChange `json:"dependencies"`
+	Additions    int                `json:"additions"`
+	Deletions    int                `json:"deletions"`
+}
+
+func fileStatus(d git.ParsedDiff) string {
+	switch {
+	case d.IsNew:
+		return "added"
+	case d.IsDeleted:
+		return "deleted"
+	case d.IsCopied:
+		return "copied"
+	case d.IsRenamed && filepath.Base(d.OldFile) == filepath.Base(d.NewFile):
+		return "moved"
+	case d.IsRenamed:
+		return "renamed"
+	case d.IsBinary:
+		return "binary"
+	default:
+		return "modified"
+	}
+}
+
+// Analyze builds the heuristic report used when no LLM is available.
+func Analyze(diffs []git.ParsedDiff) OfflineReport {
+	report := OfflineReport{
+		Files:        make([]FileChange, 0, len(diffs)),
+		Moves:        detectMoves(diffs),
+		Functions:    DetectFunctionChanges(diffs),
+		Dependencies: DetectDependencyChanges(diffs),
+	}
+	for _, d := range diffs {
+		fc := FileChange{Path: d.NewFile, Status: fileStatus(d), Additions: d.Additions, Deletions: d.Deletions, Hunks: len(d.Hunks)}
+		if d.IsRenamed || d.IsCopied {
+			fc.OldPath = d.OldFile
+		}
+		if d.IsDeleted {
+			fc.Path = d.OldFile
+		}
+		report.Files = append(report.Files, fc)
+		report.Additions += d.Additions
+		report.Deletions += d.Deletions
+	}
+	return report
+}
+
+// detectMoves pairs deleted and added files whose contents largely overlap,
+// catching moves that git reported as a delete plus an add.
+func detectMoves(diffs []git.ParsedDiff) []MovedFile {
+	moves := make([]MovedFile, 0)
+	used := map[int]bool{}
+	for _, del := range diffs {
+		if !del.IsDeleted {
+			continue
+		}
+		removed := lineSet(del, git.LineDelete)
+		if len(removed) == 0 {
+			continue
+		}
+		best, bestScore := -1, 0.0
+		for j, add := range diffs {
+			if !add.IsNew || used[j] {
+				continue
+			}
+			score := overlap(removed, lineSet(add, git.LineAdd))
+			if score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best >= 0 && bestScore >= 0.6 {
+			used[best] = true
+			moves = append(moves, MovedFile{From: del.OldFile, To: diffs[best].NewFile, Similarity: bestScore})
+		}
+	}
+	return moves
+}
+
+func lineSet(d git.ParsedDiff, kind git.ParsedLineType) map[string]bool {
+	set := map[string]bool{}
+	for _, h := range d.Hunks {
+		for _, l := range h.Lines {
+			if l.Type == kind && strings.TrimSpace(l.Content) != "" {
+				set[strings.TrimSpace(l.Content)] = true
+			}
+		}
+	}
+	return set
+}
+
+func overlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+	larger := len(a)
+	if len(b) > larger {
+		larger = len(b)
+	}
+	return float64(shared) / float64(larger)
+}
+
+// OfflineExplanation renders the heuristic report as Markdown so explain and
+// summary always have something useful to show without an API key.
+func OfflineExplanation(diffs []git.ParsedDiff) string {
+	r := Analyze(diffs)
+	out := make([]string, 0)
+	out = append(out, "## Change overview (offline analysis)", "")
+	out = append(out, fmt.Sprintf("%d file(s) changed, +%d -%d.", len(r.Files), r.Additions, r.Deletions), "")
+
+	byStatus := map[string]int{}
+	for _, f := range r.Files {
+		byStatus[f.Status]++
+	}
+	statuses := make([]string, 0, len(byStatus))
+	for s, n := range byStatus {
+		statuses = append(statuses, fmt.Sprintf("%d %s", n, s))
+	}
+	sort.Strings(statuses)
+	out = append(out, "**Breakdown:** "+strings.Join(statuses, ", "), "")
+
+	out = append(out, "### Files", "")
+	for _, f := range r.Files {
+		line := fmt.Sprintf("- `%s` — %s", f.Path, f.Status)
+		if f.OldPath != "" {
+			line = fmt.Sprintf("- `%s` → `%s` — %s", f.OldPath, f.Path, f.Status)
+		}
+		if f.Additions > 0 || f.Deletions > 0 {
+			line += fmt.Sprintf(" (+%d -%d in %d hunk(s))", f.Additions, f.Deletions, f.Hunks)
+		}
+		out = append(out, line)
+	}
+	out = append(out, "")
+
+	if len(r.Moves) > 0 {
+		out = append(out, "### Likely moves", "")
+		for _, m := range r.Moves {
+			out = append(out, fmt.Sprintf("- `%s` → `%s` (%.0f%% of lines match)", m.From, m.To, m.Similarity*100))
+		}
+		out = append(out, "")
+	}
+
+	if len(r.Functions) > 0 {
+		out = append(out, "### Functions and types", "")
+		for _, fn := range r.Functions {
+			out = append(out, fmt.Sprintf("- %s `%s` in `%s`", fn.Kind, fn.Name, fn.File))
+		}
+		out = append(out, "")
+	}
+
+	if len(r.Dependencies) > 0 {
+		out = append(out, "### Dependencies", "")
+		for _, dep := range r.Dependencies {
+			switch dep.Kind {
+			case ChangeUpdated:
+				out = append(out, fmt.Sprintf("- updated `%s` %s → %s (%s)", dep.Name, dep.OldVersion, dep.NewVersion, dep.Manifest))
+			case ChangeAdded:
+				out = append(out, fmt.Sprintf("- added `%s` %s (%s)", dep.Name, dep.NewVersion, dep.Manifest))
+			default:
+				out = append(out, fmt.Sprintf("- removed `%s` %s (%s)", dep.Name, dep.OldVersion, dep.Manifest))
+			}
+		}
+		out = append(out, "")
+	}
+
+	out = append(out, "_Generated without an LLM. Configure a provider for a full explanation._")
+	return strings.Join(out, "\n")
+}
+
+// OfflineSummary is a one-paragraph heuristic summary.
+func OfflineSummary(diffs []git.ParsedDiff) string {
+	r := Analyze(diffs)
+	parts := []string{fmt.Sprintf("%d file(s) changed (+%d -%d)", len(r.Files), r.Additions, r.Deletions)}
+
+	counts := map[ChangeKind]int{}
+	for _, fn := range r.Functions {
+		counts[fn.Kind]++
+	}
+	if len(r.Functions) > 0 {
+		parts = append(parts, fmt.Sprintf("%d function(s) added, %d removed, %d modified", counts[ChangeAdded], counts[ChangeRemoved], counts[ChangeModified]))
+	}
+	if len(r.Moves) > 0 {
+		parts = append(parts, fmt.Sprintf("%d file(s) moved", len(r.Moves)))
+	}
+	if len(r.Dependencies) > 0 {
+		parts = append(parts, fmt.Sprintf("%d dependency change(s)", len(r.Dependencies)))
+	}
+	return strings.Join(parts, "; ") + "."
+}
@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+func hunkWith(lines ...git.ParsedLine) git.ParsedHunk {
+	return git.ParsedHunk{Header: "@@ -1,1 +1,1 @@", Lines: lines}
+}
+
+func add(content string) git.ParsedLine { return git.ParsedLine{Type: git.LineAdd, Content: content} }
+func del(content string) git.ParsedLine {
+	return git.ParsedLine{Type: git.LineDelete, Content: content}
+}
+
+func TestDetectFunctionChanges(t *testing.T) {
+	diffs := []git.ParsedDiff{{
+		NewFile: "server.go",
+		Hunks: []git.ParsedHunk{
+			hunkWith(add("func (s *Server) Start() error {"), del("func legacyStart() {")),
+			{Header: "@@ -10,2 +10,2 @@ func handle(w http.ResponseWriter) {", Lines: []git.ParsedLine{add("\treturn")}},
+		},
+	}}
+
+	changes := DetectFunctionChanges(diffs)
+	got := map[string]ChangeKind{}
+	for _, c := range changes {
+		got[c.Name] = c.Kind
+	}
+	if got["Start"] != ChangeAdded || got["legacyStart"] != ChangeRemoved || got["handle"] != ChangeModified {
+		t.Fatalf("unexpected function changes: %+v", changes)
+	}
+}
+
+func TestDetectDependencyChanges(t *testing.T) {
+	diffs := []git.ParsedDiff{
+		{NewFile: "go.mod", Hunks: []git.ParsedHunk{hunkWith(del("\tgithub.com/spf13/cobra v1.8.0"), add("\tgithub.com/spf13/cobra v1.8.1"), add("\tgithub.com/google/uuid v1.6.0"))}},
+		{NewFile: "web/package.json", Hunks: []git.ParsedHunk{hunkWith(del(`    "lodash": "^4.17.20",`))}},
+	}
+
+	changes := DetectDependencyChanges(diffs)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 dependency changes, got %+v", changes)
+	}
+	kinds := map[string]ChangeKind{}
+	for _, c := range changes {
+		kinds[c.Name] = c.Kind
+	}
+	if kinds["github.com/spf13/cobra"] != ChangeUpdated || kinds["github.com/google/uuid"] != ChangeAdded || kinds["lodash"] != ChangeRemoved {
+		t.Fatalf("unexpected dependency kinds: %+v", changes)
+	}
+}
+
+func TestOfflineExplanationDetectsMoves(t *testing.T) {
+	body := []string{"package util", "func A() {}", "func B() {}"}
+	var removed, added []git.ParsedLine
+	for _, l := range body {
+		removed = append(removed, del(l))
+		added = append(added, add(l))
+	}
+	diffs := []git.ParsedDiff{
+		{OldFile: "old/util.go", NewFile: "old/util.go", IsDeleted: true, Hunks: []git.ParsedHunk{hunkWith(removed...)}, Deletions: 3},
+		{OldFile: "pkg/util.go", NewFile: "pkg/util.go", IsNew: true, Hunks: []git.ParsedHunk{hunkWith(added...)}, Additions: 3},
+	}
+
+	text := OfflineExplanation(diffs)
+	if !strings.Contains(text, "`old/util.go` → `pkg/util.go`") {
+		t.Fatalf("expected move in explanation:\n%s", text)
+	}
+	if !strings.Contains(OfflineSummary(diffs), "1 file(s) moved") {
+		t.Fatalf("expected move in summary: %s", OfflineSummary(diffs))
+	}
+}
@@ -0,0 +1,277 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+type Severity string
+
+const (
+	SeverityCritical  Severity = "critical"
+	SeverityImportant Severity = "important"
+	SeverityMinor     Severity = "minor"
+)
+
+var severityRank = map[Severity]int{SeverityCritical: 0, SeverityImportant: 1, SeverityMinor: 2}
+
+// SeverityAtLeast reports whether s is as severe as threshold or more.
+func SeverityAtLeast(s, threshold Severity) bool {
+	rs, ok := severityRank[s]
+	if !ok {
+		return false
+	}
+	rt, ok := severityRank[threshold]
+	if !ok {
+		return false
+	}
+	return rs <= rt
+}
+
+func ParseSeverity(v string) (Severity, bool) {
+	s := Severity(strings.ToLower(strings.TrimSpace(v)))
+	_, ok := severityRank[s]
+	return s, ok
+}
+
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+	Source   string   `json:"source"`
+}
+
+func (f Finding) String() string {
+	location := f.File
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return fmt.Sprintf("[%s] %s %s — %s", f.Severity, location, f.Rule, f.Message)
+}
+
+type lineRule struct {
+	name     string
+	severity Severity
+	message  string
+	match    func(path, content string) bool
+}
+
+var (
+	todoRe       = regexp.MustCompile(`\b(TODO|FIXME|XXX|HACK)\b`)
+	awsKeyRe     = regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`)
+	privateKeyRe = regexp.MustCompile(`-----BEGIN (?:RSA |EC |OPENSSH |DSA )?PRIVATE KEY-----`)
+	tokenRe      = regexp.MustCompile(`\b(?:ghp|gho|github_pat|sk|xox[bap])[-_][A-Za-z0-9_\-]{16,}`)
+	assignedRe   = regexp.MustCompile(`(?i)(api[_-]?key|secret|token|passw(?:or)?d|client[_-]?secret)["']?\s*[:=]\s*["'][^"'\s]{8,}["']`)
+	goIgnoredRe  = regexp.MustCompile(`(?:^\s*_\s*=|,\s*_\s*:?=)\s*[\w.]+\(`)
+	goErrAssign  = regexp.MustCompile(`\berr\s*:?=`)
+)
+
+var debugPatterns = map[string]*regexp.Regexp{
+	".go":   regexp.MustCompile(`(?:^|[^.\w])(?:println|print)\(|\bspew\.Dump\(|\bpp\.Print(?:ln)?\(`),
+	".js":   regexp.MustCompile(`\bconsole\.(?:log|debug|trace)\(|\bdebugger;`),
+	".py":   regexp.MustCompile(`^\s*(?:print\(|breakpoint\(\)|import pdb|pdb\.set_trace\(\))`),
+	".rs":   regexp.MustCompile(`\b(?:println!|dbg!)\(`),
+	".java": regexp.MustCompile(`System\.out\.print(?:ln)?\(`),
+	".rb":   regexp.MustCompile(`^\s*(?:puts|p|pp)\s|binding\.pry`),
+}
+
+func debugPatternFor(path string) *regexp.Regexp {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".ts", ".tsx", ".jsx", ".mjs", ".cjs":
+		ext = ".js"
+	}
+	return debugPatterns[ext]
+}
+
+func isTestPath(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") || strings.HasPrefix(base, "test_")
+}
+
+var lineRules = []lineRule{
+	{
+		name:     "todo-added",
+		severity: SeverityMinor,
+		message:  "new TODO/FIXME marker added",
+		match:    func(path, content string) bool { return todoRe.MatchString(content) },
+	},
+	{
+		name:     "debug-output",
+		severity: SeverityMinor,
+		message:  "debug output left in code",
+		match: func(path, content string) bool {
+			re := debugPatternFor(path)
+			if re == nil || isTestPath(path) {
+				return false
+			}
+			return re.MatchString(content)
+		},
+	},
+	{
+		name:     "possible-secret",
+		severity: SeverityCritical,
+		message:  "value looks like a hard-coded credential",
+		match: func(path, content string) bool {
+			return awsKeyRe.MatchString(content) || privateKeyRe.MatchString(content) || tokenRe.MatchString(content) || assignedRe.MatchString(content)
+		},
+	},
+	{
+		name:     "ignored-error",
+		severity: SeverityMinor,
+		message:  "return value discarded with _; check whether an error is being ignored",
+		match: func(path, content string) bool {
+			return strings.HasSuffix(path, ".go") && !isTestPath(path) && goIgnoredRe.MatchString(content)
+		},
+	},
+}
+
+const largeFunctionLines = 80
+
+// RunRules applies the built-in static checks to the lines added by diffs.
+func RunRules(diffs []git.ParsedDiff) []Finding {
+	findings := make([]Finding, 0)
+	for _, d := range diffs {
+		if d.IsBinary || d.IsDeleted {
+			continue
+		}
+		for _, h := range d.Hunks {
+			for _, l := range h.Lines {
+				if l.Type != git.LineAdd {
+					continue
+				}
+				line := 0
+				if l.NewLineNumber != nil {
+					line = *l.NewLineNumber
+				}
+				for _, rule := range lineRules {
+					if rule.match(d.NewFile, l.Content) {
+						findings = append(findings, Finding{Rule: rule.name, Severity: rule.severity, File: d.NewFile, Line: line, Message: rule.message, Source: "rule"})
+					}
+				}
+			}
+			findings = append(findings, checkLargeFunctions(d.NewFile, h)...)
+			findings = append(findings, checkUncheckedErrors(d.NewFile, h)...)
+		}
+	}
+	SortFindings(findings)
+	return findings
+}
+
+// checkLargeFunctions flags newly declared functions followed by a long run of
+// added lines in the same hunk.
+func checkLargeFunctions(path string, h git.ParsedHunk) []Finding {
+	findings := make([]Finding, 0)
+	for i, l := range h.Lines {
+		if l.Type != git.LineAdd {
+			continue
+		}
+		name, ok := FunctionName(path, l.Content)
+		if !ok {
+			continue
+		}
+		length := 0
+		for _, next := range h.Lines[i+1:] {
+			if next.Type != git.LineAdd {
+				break
+			}
+			if _, isDecl := FunctionName(path, next.Content); isDecl {
+				break
+			}
+			length++
+		}
+		if length >= largeFunctionLines {
+			line := 0
+			if l.NewLineNumber != nil {
+				line = *l.NewLineNumber
+			}
+			findings = append(findings, Finding{Rule: "large-function", Severity: SeverityImportant, File: path, Line: line, Message: fmt.Sprintf("new function %s is %d+ lines; consider splitting it", name, length), Source: "rule"})
+		}
+	}
+	return findings
+}
+
+// checkUncheckedErrors looks for added Go err assignments whose following
+// lines never mention err.
+func checkUncheckedErrors(path string, h git.ParsedHunk) []Finding {
+	if !strings.HasSuffix(path, ".go") || isTestPath(path) {
+		return nil
+	}
+	lines := make([]git.ParsedLine, 0, len(h.Lines))
+	for _, l := range h.Lines {
+		if l.Type != git.LineDelete {
+			lines = append(lines, l)
+		}
+	}
+	findings := make([]Finding, 0)
+	for i, l := range lines {
+		if l.Type != git.LineAdd || !goErrAssign.MatchString(l.Content) || strings.Contains(l.Content, "if ") {
+			continue
+		}
+		window := lines[i+1:]
+		if len(window) == 0 {
+			continue
+		}
+		if len(window) > 3 {
+			window = window[:3]
+		}
+		checked := false
+		for _, next := range window {
+			if strings.Contains(next.Content, "err") {
+				checked = true
+				break
+			}
+		}
+		if !checked {
+			line := 0
+			if l.NewLineNumber != nil {
+				line = *l.NewLineNumber
+			}
+			findings = append(findings, Finding{Rule: "unchecked-error", Severity: SeverityImportant, File: path, Line: line, Message: "err is assigned but not checked in the following lines", Source: "rule"})
+		}
+	}
+	return findings
+}
+
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if severityRank[findings[i].Severity] != severityRank[findings[j].Severity] {
+			return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+		}
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+}
+
+// FormatFindings renders findings as a Markdown list grouped by severity.
+func FormatFindings(findings []Finding) string {
+	if len(findings) == 0 {
+		return "No issues found by static pre-checks."
+	}
+	out := make([]string, 0)
+	current := Severity("")
+	for _, f := range findings {
+		if f.Severity != current {
+			if current != "" {
+				out = append(out, "")
+			}
+			current = f.Severity
+			out = append(out, fmt.Sprintf("**%s**", strings.ToUpper(string(current[:1]))+string(current[1:])))
+		}
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		out = append(out, fmt.Sprintf("- `%s` %s (%s)", location, f.Message, f.Rule))
+	}
+	return strings.Join(out, "\n")
+}
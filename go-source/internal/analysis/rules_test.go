@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+func numbered(start int, lines ...git.ParsedLine) []git.ParsedLine {
+	for i := range lines {
+		n := start + i
+		lines[i].NewLineNumber = &n
+	}
+	return lines
+}
+
+func TestRunRules(t *testing.T) {
+	diffs := []git.ParsedDiff{
+		{
+			NewFile: "internal/api/handler.go",
+			Hunks: []git.ParsedHunk{{Lines: numbered(10,
+				add("\t// TODO: handle retries"),
+				add("\tprintln(\"debug\", req)"),
+				add("\tapiKey := \"sk-live-abcdefghijklmnop1234\""),
+				add("\tdata, err := load()"),
+				add("\treturn data"),
+			)}},
+		},
+		{
+			NewFile: "web/app.ts",
+			Hunks:   []git.ParsedHunk{{Lines: numbered(1, add("console.log(state)"))}},
+		},
+	}
+
+	findings := RunRules(diffs)
+	rules := map[string]Finding{}
+	for _, f := range findings {
+		rules[f.Rule] = f
+	}
+	for _, name := range []string{"todo-added", "debug-output", "possible-secret", "unchecked-error"} {
+		if _, ok := rules[name]; !ok {
+			t.Fatalf("expected %s finding, got %+v", name, findings)
+		}
+	}
+	if findings[0].Severity != SeverityCritical {
+		t.Fatalf("expected critical findings first, got %+v", findings[0])
+	}
+	if rules["todo-added"].Line != 10 {
+		t.Fatalf("expected line number on finding, got %+v", rules["todo-added"])
+	}
+	if !strings.Contains(FormatFindings(findings), "**Critical**") {
+		t.Fatalf("expected grouped markdown output")
+	}
+}
+
+func TestRunRulesIgnoresTestFiles(t *testing.T) {
+	diffs := []git.ParsedDiff{{
+		NewFile: "handler_test.go",
+		Hunks:   []git.ParsedHunk{{Lines: numbered(1, add("\tprintln(got)"))}},
+	}}
+	if findings := RunRules(diffs); len(findings) != 0 {
+		t.Fatalf("expected no findings in tests, got %+v", findings)
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	if !SeverityAtLeast(SeverityCritical, SeverityImportant) || SeverityAtLeast(SeverityMinor, SeverityImportant) {
+		t.Fatalf("unexpected severity ordering")
+	}
+}
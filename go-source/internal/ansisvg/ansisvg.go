@@ -0,0 +1,285 @@
+// Package ansisvg draws colored terminal output as an SVG image of a
+// terminal window, for putting a diff on a slide, in docs or in a post. It
+// understands the SGR escape sequences the formatter, chroma and glamour
+// write (16, 256 and 24-bit colors, bold, faint, italic, underline) and
+// drops every other escape sequence.
+package ansisvg
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Options control the picture around the text.
+type Options struct {
+	// Title is shown in the window bar; an empty title leaves the bar out.
+	Title string
+	// FontSize is in pixels, 14 by default.
+	FontSize float64
+}
+
+// Colors of the window and the 16 basic ANSI colors, taken from the web
+// UI's dark theme so images match it.
+const (
+	background = "#0d1117"
+	foreground = "#e6edf3"
+	barColor   = "#161b22"
+)
+
+var basic = [16]string{
+	"#484f58", "#ff7b72", "#3fb950", "#d29922", "#58a6ff", "#bc8cff", "#39c5cf", "#b1bac4",
+	"#6e7681", "#ffa198", "#56d364", "#e3b341", "#79c0ff", "#d2a8ff", "#56d4dd", "#ffffff",
+}
+
+type style struct {
+	fg, bg                         string
+	bold, faint, italic, underline bool
+}
+
+// run is text of one style starting at a column of a line.
+type run struct {
+	col, width int
+	text       string
+	style      style
+}
+
+// Render draws text, which may hold ANSI escape sequences, as an SVG
+// document sized to fit its longest line.
+func Render(text string, opts Options) string {
+	if opts.FontSize <= 0 {
+		opts.FontSize = 14
+	}
+	lines := parse(strings.TrimRight(text, "\n"))
+	cols := 0
+	for _, l := range lines {
+		if n := len(l); n > 0 {
+			cols = max(cols, l[n-1].col+l[n-1].width)
+		}
+	}
+	cellW, cellH := opts.FontSize*0.6, opts.FontSize*1.4
+	pad := opts.FontSize
+	top := pad
+	if opts.Title != "" {
+		top += opts.FontSize * 2
+	}
+	width := float64(cols)*cellW + 2*pad
+	height := top + float64(len(lines))*cellH + pad
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n", num(width), num(height), num(width), num(height))
+	fmt.Fprintf(&b, "<style>text{font-family:\"JetBrains Mono\",Menlo,Consolas,\"DejaVu Sans Mono\",monospace;font-size:%spx;white-space:pre}</style>\n", num(opts.FontSize))
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" rx="8" fill="%s"/>`+"\n", background)
+	if opts.Title != "" {
+		bar := opts.FontSize * 2
+		fmt.Fprintf(&b, `<path d="M0 8a8 8 0 0 1 8-8h%s a8 8 0 0 1 8 8v%sh-%sz" fill="%s"/>`+"\n", num(width-16), num(bar-8), num(width), barColor)
+		for i, c := range []string{"#ff5f57", "#febc2e", "#28c840"} {
+			fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n", num(pad+float64(i)*opts.FontSize*1.2), num(bar/2), num(opts.FontSize*0.4), c)
+		}
+		fmt.Fprintf(&b, `<text x="%s" y="%s" fill="#8b949e" text-anchor="middle" dominant-baseline="central">%s</text>`+"\n", num(width/2), num(bar/2), html.EscapeString(opts.Title))
+	}
+	for i, l := range lines {
+		y := top + float64(i)*cellH
+		// One rectangle per stretch of a background, however many
+		// foreground colors are drawn on it.
+		for j := 0; j < len(l); j++ {
+			bg, from, to := l[j].style.bg, l[j].col, l[j].col+l[j].width
+			for j+1 < len(l) && l[j+1].style.bg == bg && l[j+1].col == to {
+				j++
+				to += l[j].width
+			}
+			if bg != "" {
+				fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n", num(pad+float64(from)*cellW), num(y), num(float64(to-from)*cellW), num(cellH), bg)
+			}
+		}
+		for _, r := range l {
+			if strings.TrimSpace(r.text) == "" {
+				continue
+			}
+			fmt.Fprintf(&b, `<text x="%s" y="%s" fill="%s"%s>%s</text>`+"\n", num(pad+float64(r.col)*cellW), num(y+cellH*0.75), fill(r.style), attrs(r.style), html.EscapeString(r.text))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+func fill(s style) string {
+	if s.fg == "" {
+		return foreground
+	}
+	return s.fg
+}
+
+func attrs(s style) string {
+	var a strings.Builder
+	if s.bold {
+		a.WriteString(` font-weight="bold"`)
+	}
+	if s.faint {
+		a.WriteString(` opacity="0.6"`)
+	}
+	if s.italic {
+		a.WriteString(` font-style="italic"`)
+	}
+	if s.underline {
+		a.WriteString(` text-decoration="underline"`)
+	}
+	return a.String()
+}
+
+// parse splits text into lines of styled runs, expanding tabs and
+// measuring wide characters such as emoji as two columns.
+func parse(text string) [][]run {
+	var lines [][]run
+	var cur []run
+	var st style
+	var buf strings.Builder
+	col, start := 0, 0
+	flush := func() {
+		if buf.Len() > 0 {
+			cur = append(cur, run{col: start, width: col - start, text: buf.String(), style: st})
+			buf.Reset()
+		}
+		start = col
+	}
+	rs := []rune(text)
+	for i := 0; i < len(rs); i++ {
+		switch c := rs[i]; {
+		case c == '\n':
+			flush()
+			lines = append(lines, cur)
+			cur, col, start = nil, 0, 0
+		case c == '\r':
+		case c == '\t':
+			n := 8 - col%8
+			buf.WriteString(strings.Repeat(" ", n))
+			col += n
+		case c == 0x1b && i+1 < len(rs) && rs[i+1] == '[':
+			j := i + 2
+			for j < len(rs) && (rs[j] < 0x40 || rs[j] > 0x7e) {
+				j++
+			}
+			if j < len(rs) && rs[j] == 'm' {
+				flush()
+				st = apply(st, string(rs[i+2:j]))
+			}
+			i = j
+		case c == 0x1b && i+1 < len(rs) && rs[i+1] == ']':
+			// OSC, such as a hyperlink, ends with BEL or ESC \.
+			j := i + 2
+			for j < len(rs) && rs[j] != 0x07 && !(rs[j] == 0x1b && j+1 < len(rs) && rs[j+1] == '\\') {
+				j++
+			}
+			if j < len(rs) && rs[j] == 0x1b {
+				j++
+			}
+			i = j
+		case c == 0x1b:
+			i++
+		case c < 0x20:
+		default:
+			buf.WriteRune(c)
+			col += runewidth.RuneWidth(c)
+		}
+	}
+	flush()
+	return append(lines, cur)
+}
+
+// apply changes st by the parameters of an SGR sequence.
+func apply(st style, params string) style {
+	if params == "" {
+		return style{}
+	}
+	p := strings.Split(params, ";")
+	for i := 0; i < len(p); i++ {
+		n, err := strconv.Atoi(p[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			st = style{}
+		case n == 1:
+			st.bold = true
+		case n == 2:
+			st.faint = true
+		case n == 3:
+			st.italic = true
+		case n == 4:
+			st.underline = true
+		case n == 22:
+			st.bold, st.faint = false, false
+		case n == 23:
+			st.italic = false
+		case n == 24:
+			st.underline = false
+		case n >= 30 && n <= 37:
+			st.fg = basic[n-30]
+		case n >= 90 && n <= 97:
+			st.fg = basic[n-90+8]
+		case n == 39:
+			st.fg = ""
+		case n >= 40 && n <= 47:
+			st.bg = basic[n-40]
+		case n >= 100 && n <= 107:
+			st.bg = basic[n-100+8]
+		case n == 49:
+			st.bg = ""
+		case n == 38 || n == 48:
+			c, used := extended(p[i+1:])
+			i += used
+			if n == 38 {
+				st.fg = c
+			} else {
+				st.bg = c
+			}
+		}
+	}
+	return st
+}
+
+// extended reads the color after 38 or 48: 5;n for the 256-color palette
+// or 2;r;g;b, returning it and how many parameters it took.
+func extended(p []string) (string, int) {
+	if len(p) >= 2 && p[0] == "5" {
+		n, _ := strconv.Atoi(p[1])
+		return palette256(n), 2
+	}
+	if len(p) >= 4 && p[0] == "2" {
+		r, _ := strconv.Atoi(p[1])
+		g, _ := strconv.Atoi(p[2])
+		b, _ := strconv.Atoi(p[3])
+		return fmt.Sprintf("#%02x%02x%02x", r&255, g&255, b&255), 4
+	}
+	return "", len(p)
+}
+
+func palette256(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return basic[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	default:
+		g := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", g, g, g)
+	}
+}
@@ -0,0 +1,68 @@
+package ansisvg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRenderColorsAndEscapes(t *testing.T) {
+	svg := Render("\x1b[1;32m+ok\x1b[0m <a&b>\n\x1b[48;5;22mbg\x1b[0m \x1b[38;2;1;2;3mrgb\x1b[39m\x1b]8;;http://x\x07link\x1b]8;;\x07", Options{Title: "a...b"})
+	for _, want := range []string{
+		`fill="#3fb950" font-weight="bold">+ok</text>`,
+		`> &lt;a&amp;b&gt;</text>`,
+		`fill="#005f00"/>`,
+		`fill="#010203">rgb</text>`,
+		`>link</text>`,
+		`>a...b</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("svg lacks %q:\n%s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "\x1b") || strings.Contains(svg, "http://x") {
+		t.Errorf("escape sequences leaked into the svg:\n%s", svg)
+	}
+}
+
+func TestRenderMeasuresColumns(t *testing.T) {
+	lines := parse("a\tb\n💬x\x1b[31my")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d", len(lines))
+	}
+	if r := lines[0][0]; r.text != "a       b" || r.width != 9 {
+		t.Errorf("tab expanded to %q (width %d)", r.text, r.width)
+	}
+	// The emoji takes two columns, so the red y starts in the fourth.
+	if r := lines[1][1]; r.col != 3 || r.text != "y" || r.style.fg != basic[1] {
+		t.Errorf("second run = %+v", r)
+	}
+	// Without a title the window is just the padded text: 9 columns of
+	// 8.4px and 14px of padding on each side.
+	if svg := Render("a\tb", Options{}); !strings.Contains(svg, `width="103.6"`) {
+		t.Errorf("unexpected size: %s", svg[:strings.Index(svg, "\n")])
+	}
+}
+
+func TestPNGUsesARasterizer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake rasterizer is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nwhile [ $# -gt 1 ]; do [ \"$1\" = -o ] && out=$2; shift; done\ncp \"$1\" \"$out\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "rsvg-convert"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	png, err := PNG("<svg/>")
+	if err != nil || string(png) != "<svg/>" {
+		t.Fatalf("PNG = %q, %v", png, err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := PNG("<svg/>"); err == nil || !strings.Contains(err.Error(), "--format svg") {
+		t.Errorf("missing rasterizer error = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package ansisvg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// rasterizers are the programs PNG tries, in order, with the arguments that
+// turn in.svg into out.png at twice the size, so text stays sharp on
+// high-density screens. ImageMagick 6's convert is skipped on Windows,
+// where that name is the system's FAT-to-NTFS disk converter.
+var rasterizers = []struct {
+	name      string
+	skipOnWin bool
+	args      func(in, out string) []string
+}{
+	{"rsvg-convert", false, func(in, out string) []string { return []string{"--zoom", "2", "-o", out, in} }},
+	{"resvg", false, func(in, out string) []string { return []string{"--zoom", "2", in, out} }},
+	{"magick", false, func(in, out string) []string { return []string{"-density", "192", "-background", "none", in, out} }},
+	{"convert", true, func(in, out string) []string { return []string{"-density", "192", "-background", "none", in, out} }},
+	{"inkscape", false, func(in, out string) []string {
+		return []string{in, "--export-type=png", "--export-filename=" + out, "--export-dpi=192"}
+	}},
+}
+
+// PNG converts an SVG from Render with the first SVG rasterizer found on
+// PATH. Go's standard library has no font rendering, so drawing the text
+// is left to one of them.
+func PNG(svg string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "difflearn-png-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	in, out := filepath.Join(dir, "in.svg"), filepath.Join(dir, "out.png")
+	if err := os.WriteFile(in, []byte(svg), 0o600); err != nil {
+		return nil, err
+	}
+	for _, r := range rasterizers {
+		if r.skipOnWin && runtime.GOOS == "windows" {
+			continue
+		}
+		path, err := exec.LookPath(r.name)
+		if err != nil {
+			continue
+		}
+		var stderr bytes.Buffer
+		cmd := exec.Command(path, r.args(in, out)...)
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				msg = err.Error()
+			}
+			return nil, fmt.Errorf("%s: %s", r.name, msg)
+		}
+		return os.ReadFile(out)
+	}
+	return nil, fmt.Errorf("PNG export needs rsvg-convert, resvg, ImageMagick or Inkscape on PATH; --format svg works without them")
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Role is what an API token may do. Each role includes the ones below it.
+type Role int
+
+const (
+	rolePublic Role = iota
+	// RoleRead views diffs, history and progress.
+	RoleRead
+	// RoleAI also triggers LLM calls.
+	RoleAI
+	// RoleAdmin also changes the repository, e.g. switches branches.
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleRead:
+		return "read"
+	case RoleAI:
+		return "ai"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "public"
+}
+
+// ParseRole accepts read (or read-only), ai and admin.
+func ParseRole(s string) (Role, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "read", "read-only", "readonly":
+		return RoleRead, true
+	case "ai":
+		return RoleAI, true
+	case "admin":
+		return RoleAdmin, true
+	}
+	return rolePublic, false
+}
+
+type apiToken struct {
+	secret string
+	role   Role
+}
+
+// Tokens are the API keys the server accepts. Without any, auth is off and
+// every request is allowed, as before tokens existed.
+type Tokens []apiToken
+
+// ParseTokens reads DIFFLEARN_API_TOKENS: comma-separated role:token
+// entries, e.g. "admin:s3cret,ai:t0ken,read:v1ew".
+func ParseTokens(spec string) (Tokens, error) {
+	var tokens Tokens
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, secret, ok := strings.Cut(entry, ":")
+		role, known := ParseRole(name)
+		if !ok || !known || strings.TrimSpace(secret) == "" {
+			return nil, fmt.Errorf("invalid API token entry %q (use role:token with role read, ai or admin)", redactToken(entry))
+		}
+		tokens = append(tokens, apiToken{secret: strings.TrimSpace(secret), role: role})
+	}
+	return tokens, nil
+}
+
+func redactToken(entry string) string {
+	if name, _, ok := strings.Cut(entry, ":"); ok {
+		return name + ":…"
+	}
+	return "…"
+}
+
+// lookup finds the request's token: a bearer token, or a token query
+// parameter for URLs the browser loads itself, such as images.
+func (t Tokens) lookup(r *http.Request) (apiToken, bool) {
+	secret := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if secret == "" {
+		secret = r.URL.Query().Get("token")
+	}
+	if secret == "" {
+		return apiToken{}, false
+	}
+	for _, tok := range t {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(tok.secret)) == 1 {
+			return tok, true
+		}
+	}
+	return apiToken{}, false
+}
+
+// key names the token in the usage ledger without storing the secret.
+func (tok apiToken) key() string {
+	sum := sha256.Sum256([]byte(tok.secret))
+	return tok.role.String() + ":" + hex.EncodeToString(sum[:4])
+}
+
+type tokenKeyContext struct{}
+
+// TokenKey returns the ledger key of the request's API token, or "" when
+// the server has no tokens.
+func TokenKey(r *http.Request) string {
+	key, _ := r.Context().Value(tokenKeyContext{}).(string)
+	return key
+}
+
+// requiredRole is the least role a request needs. The web UI shell is
+// public so the browser can ask for a token, and team endpoints check the
+// team token themselves.
+func requiredRole(r *http.Request) Role {
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodOptions,
+		path == "/styles.css", path == "/app.js",
+		path == "/" && strings.Contains(r.Header.Get("Accept"), "text/html"),
+		strings.HasPrefix(path, "/team/"):
+		return rolePublic
+	case path == "/branch/switch":
+		return RoleAdmin
+	case path == "/explain", path == "/review", path == "/ask", path == "/summary",
+		path == "/explain/file", path == "/compare", path == "/annotate":
+		return RoleAI
+	}
+	return RoleRead
+}
+
+// Middleware rejects requests whose token lacks the role the endpoint needs:
+// 401 without a valid token, 403 with one of too low a role.
+func (t Tokens) Middleware(next http.Handler) http.Handler {
+	if len(t) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		need := requiredRole(r)
+		if need == rolePublic {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		tok, ok := t.lookup(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="difflearn"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"success": false, "error": "an API token is required"})
+			return
+		}
+		if tok.role < need {
+			writeJSON(w, http.StatusForbidden, map[string]any{"success": false, "error": fmt.Sprintf("this needs a %s token; yours is %s", need, tok.role)})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKeyContext{}, tok.key())))
+	})
+}
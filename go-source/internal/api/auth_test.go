@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTokensMiddleware(t *testing.T) {
+	tokens, err := ParseTokens("read:r1, ai:a1,admin:x1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := tokens.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	cases := []struct {
+		method, path, accept, token string
+		want                        int
+	}{
+		{"GET", "/", "text/html", "", 200},
+		{"GET", "/app.js", "", "", 200},
+		{"GET", "/", "application/json", "", 401},
+		{"GET", "/diff/local", "", "wrong", 401},
+		{"GET", "/diff/local", "", "r1", 200},
+		{"POST", "/explain", "", "r1", 403},
+		{"POST", "/explain", "", "a1", 200},
+		{"POST", "/branch/switch", "", "a1", 403},
+		{"POST", "/branch/switch", "", "x1", 200},
+		{"POST", "/team/events", "", "", 200},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != c.want {
+			t.Errorf("%s %s with %q: got %d, want %d", c.method, c.path, c.token, w.Code, c.want)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/diff/blob/abc?path=a.png&token=r1", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected the token query parameter to be accepted, got %d", w.Code)
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	if tokens, err := ParseTokens(""); err != nil || len(tokens) != 0 {
+		t.Fatalf("ParseTokens(\"\") = %v, %v", tokens, err)
+	}
+	for _, bad := range []string{"owner:abc", "ai:", "justatoken"} {
+		if _, err := ParseTokens(bad); err == nil {
+			t.Errorf("ParseTokens(%q) should fail", bad)
+		}
+	}
+}
+
+func TestTokenKey(t *testing.T) {
+	tokens, _ := ParseTokens("ai:a1,ai:a2")
+	var keys []string
+	h := tokens.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, TokenKey(r))
+	}))
+	for _, secret := range []string{"a1", "a2", "a1"} {
+		req := httptest.NewRequest("POST", "/review", nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if len(keys) != 3 || keys[0] != keys[2] || keys[0] == keys[1] || !strings.HasPrefix(keys[0], "ai:") || strings.HasSuffix(keys[0], ":a1") {
+		t.Fatalf("got keys %v", keys)
+	}
+}
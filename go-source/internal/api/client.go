@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/llm"
+)
+
+// Client calls the AI endpoints of a running server, so other DiffLearn
+// processes share its usage ledger, budgets and rate limits instead of
+// calling the provider themselves.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+// AIRequest selects the diff and model of an AI request. It mirrors the
+// request body the web UI sends.
+type AIRequest struct {
+	Question          string `json:"question,omitempty"`
+	Staged            bool   `json:"staged,omitempty"`
+	Stash             *int   `json:"stash,omitempty"`
+	Against           string `json:"against,omitempty"`
+	Untracked         bool   `json:"untracked,omitempty"`
+	RecurseSubmodules bool   `json:"recurseSubmodules,omitempty"`
+	Similarity        int    `json:"similarity,omitempty"`
+	FindCopies        bool   `json:"findCopies,omitempty"`
+	NoRenames         bool   `json:"noRenames,omitempty"`
+	IgnoreWhitespace  bool   `json:"ignoreWhitespace,omitempty"`
+	IgnoreSpaceChange bool   `json:"ignoreSpaceChange,omitempty"`
+	IgnoreBlankLines  bool   `json:"ignoreBlankLines,omitempty"`
+	Algorithm         string `json:"algorithm,omitempty"`
+	Provider          string `json:"provider,omitempty"`
+	Model             string `json:"model,omitempty"`
+	Refine            *bool  `json:"refine,omitempty"`
+	Level             string `json:"level,omitempty"`
+	Language          string `json:"language,omitempty"`
+	Client            string `json:"client,omitempty"`
+}
+
+// AIResponse is the answer of an AI endpoint.
+type AIResponse struct {
+	Content  string
+	Provider string
+	Model    string
+	Refined  bool
+	Findings []analysis.Finding
+	Budget   *llm.BudgetReport
+}
+
+var (
+	// ErrServerUnauthorized means the server wants a token with the AI role.
+	ErrServerUnauthorized = errors.New("the server rejected the API token")
+	// ErrServerOffline means the server has no LLM configured.
+	ErrServerOffline = errors.New("the server has no LLM configured")
+	// ErrServerUnreachable means the request never got an answer.
+	ErrServerUnreachable = errors.New("the server is not reachable")
+)
+
+// aiResponseFields names the answer field of each AI endpoint.
+var aiResponseFields = map[string]string{"explain": "explanation", "review": "review", "ask": "answer", "summary": "summary"}
+
+// ClientToken is the token the CLI sends to a local server:
+// DIFFLEARN_SERVER_TOKEN, or else the strongest AI-capable token of
+// DIFFLEARN_API_TOKENS, which the server itself reads.
+func ClientToken() string {
+	if tok := config.Setting("DIFFLEARN_SERVER_TOKEN"); tok != "" {
+		return tok
+	}
+	tokens, _ := ParseTokens(config.Setting("DIFFLEARN_API_TOKENS"))
+	best := apiToken{}
+	for _, tok := range tokens {
+		if tok.role >= RoleAI && tok.role > best.role {
+			best = tok
+		}
+	}
+	return best.secret
+}
+
+// AI runs kind (explain, review, summary or ask) on the server.
+func (c Client) AI(kind string, req AIRequest) (AIResponse, error) {
+	field, ok := aiResponseFields[kind]
+	if !ok {
+		return AIResponse{}, fmt.Errorf("unknown AI command: %s", kind)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return AIResponse{}, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, strings.TrimRight(c.BaseURL, "/")+"/"+kind, bytes.NewReader(body))
+	if err != nil {
+		return AIResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return AIResponse{}, fmt.Errorf("%w: %v", ErrServerUnreachable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return AIResponse{}, ErrServerUnauthorized
+	}
+
+	var payload struct {
+		Success bool                       `json:"success"`
+		Error   string                     `json:"error"`
+		Data    map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return AIResponse{}, fmt.Errorf("unexpected response from %s: %w", c.BaseURL, err)
+	}
+	if !payload.Success {
+		if payload.Error == "" {
+			payload.Error = resp.Status
+		}
+		return AIResponse{}, errors.New(payload.Error)
+	}
+	var available *bool
+	_ = json.Unmarshal(payload.Data["llmAvailable"], &available)
+	if available != nil && !*available {
+		return AIResponse{}, ErrServerOffline
+	}
+	var out AIResponse
+	_ = json.Unmarshal(payload.Data[field], &out.Content)
+	_ = json.Unmarshal(payload.Data["provider"], &out.Provider)
+	_ = json.Unmarshal(payload.Data["model"], &out.Model)
+	_ = json.Unmarshal(payload.Data["refined"], &out.Refined)
+	_ = json.Unmarshal(payload.Data["findings"], &out.Findings)
+	if raw, ok := payload.Data["budget"]; ok {
+		var report llm.BudgetReport
+		if json.Unmarshal(raw, &report) == nil {
+			out.Budget = &report
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientAI(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/review" || r.Header.Get("Authorization") != "Bearer s3cret" {
+			t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{
+			"review":   "Looks fine.",
+			"provider": "openai",
+			"model":    "gpt-4o",
+			"findings": []map[string]any{{"rule": "todo", "severity": "minor", "file": "a.go", "message": "TODO left"}},
+			"budget":   map[string]any{"chunks": 2},
+		}})
+	}))
+	defer srv.Close()
+
+	resp, err := Client{BaseURL: srv.URL, Token: "s3cret"}.AI("review", AIRequest{Staged: true, Client: "cli"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Looks fine." || resp.Model != "gpt-4o" || len(resp.Findings) != 1 || resp.Budget == nil || resp.Budget.Chunks != 2 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if got["staged"] != true || got["client"] != "cli" {
+		t.Fatalf("request body not sent: %v", got)
+	}
+}
+
+func TestClientAIErrors(t *testing.T) {
+	status, payload := 0, map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, status, payload)
+	}))
+	defer srv.Close()
+	c := Client{BaseURL: srv.URL}
+
+	status, payload = http.StatusUnauthorized, map[string]any{"success": false, "error": "an API token is required"}
+	if _, err := c.AI("explain", AIRequest{}); !errors.Is(err, ErrServerUnauthorized) {
+		t.Fatalf("expected ErrServerUnauthorized, got %v", err)
+	}
+	status, payload = 200, map[string]any{"success": true, "data": map[string]any{"llmAvailable": false, "prompt": "..."}}
+	if _, err := c.AI("explain", AIRequest{}); !errors.Is(err, ErrServerOffline) {
+		t.Fatalf("expected ErrServerOffline, got %v", err)
+	}
+	status, payload = 429, map[string]any{"success": false, "error": "budget exceeded"}
+	if _, err := c.AI("explain", AIRequest{}); err == nil || err.Error() != "budget exceeded" {
+		t.Fatalf("expected the server's error, got %v", err)
+	}
+	if _, err := (Client{BaseURL: "http://127.0.0.1:1"}).AI("explain", AIRequest{}); !errors.Is(err, ErrServerUnreachable) {
+		t.Fatalf("expected ErrServerUnreachable, got %v", err)
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	t.Setenv("DIFFLEARN_SERVER_TOKEN", "")
+	t.Setenv("DIFFLEARN_API_TOKENS", "read:r1,ai:a1,admin:x1")
+	if tok := ClientToken(); tok != "x1" {
+		t.Fatalf("expected the admin token, got %q", tok)
+	}
+	t.Setenv("DIFFLEARN_SERVER_TOKEN", "mine")
+	if tok := ClientToken(); tok != "mine" {
+		t.Fatalf("DIFFLEARN_SERVER_TOKEN should win, got %q", tok)
+	}
+}
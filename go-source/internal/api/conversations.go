@@ -0,0 +1,66 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"difflearn-go/internal/llm"
+)
+
+// conversationTTL is how long an idle /ask conversation is kept in memory.
+const conversationTTL = time.Hour
+
+// maxConversations caps the conversations kept; the least recently used go
+// first.
+const maxConversations = 200
+
+// conversationStore keeps /ask conversations by ID for the lifetime of the
+// server. Each conversation belongs to the API token that started it.
+type conversationStore struct {
+	mu    sync.Mutex
+	byKey map[string]llm.Conversation
+}
+
+func newConversationStore() *conversationStore {
+	return &conversationStore{byKey: map[string]llm.Conversation{}}
+}
+
+// get returns a copy of the token's conversation id, or a new conversation
+// when id is empty, unknown or expired.
+func (s *conversationStore) get(token, id string) llm.Conversation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.byKey[token+"/"+id]; ok && id != "" && time.Since(c.Updated) < conversationTTL {
+		c.Turns = append([]llm.ConversationTurn(nil), c.Turns...)
+		return c
+	}
+	return llm.Conversation{ID: newConversationID(), Updated: time.Now()}
+}
+
+func (s *conversationStore) put(token string, c llm.Conversation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byKey[token+"/"+c.ID] = c
+	for key, old := range s.byKey {
+		if time.Since(old.Updated) >= conversationTTL {
+			delete(s.byKey, key)
+		}
+	}
+	for len(s.byKey) > maxConversations {
+		oldest := ""
+		for key, old := range s.byKey {
+			if oldest == "" || old.Updated.Before(s.byKey[oldest].Updated) {
+				oldest = key
+			}
+		}
+		delete(s.byKey, oldest)
+	}
+}
+
+func newConversationID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
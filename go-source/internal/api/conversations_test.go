@@ -0,0 +1,34 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"difflearn-go/internal/llm"
+)
+
+func TestConversationStore(t *testing.T) {
+	s := newConversationStore()
+	c := s.get("", "")
+	if c.ID == "" || len(c.Turns) != 0 {
+		t.Fatalf("expected a new conversation, got %+v", c)
+	}
+	c.Turns = append(c.Turns, llm.ConversationTurn{Question: "q", Answer: "a"})
+	s.put("", c)
+
+	if got := s.get("", c.ID); len(got.Turns) != 1 {
+		t.Fatalf("expected the stored turn, got %+v", got)
+	}
+	if got := s.get("other-token", c.ID); got.ID == c.ID {
+		t.Fatal("another token must not continue the conversation")
+	}
+
+	c.Updated = time.Now().Add(-2 * conversationTTL)
+	s.put("", c)
+	if got := s.get("", c.ID); got.ID == c.ID {
+		t.Fatal("expired conversation should start over")
+	}
+	if len(s.byKey) != 0 {
+		t.Fatalf("expired conversations should be dropped, %d left", len(s.byKey))
+	}
+}
@@ -1,28 +1,191 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"difflearn-go/internal/aihistory"
+	"difflearn-go/internal/analysis"
 	"difflearn-go/internal/config"
+	"difflearn-go/internal/discovery"
 	"difflearn-go/internal/git"
+	"difflearn-go/internal/learning"
 	"difflearn-go/internal/llm"
+	"difflearn-go/internal/team"
+	"difflearn-go/internal/update"
+	"difflearn-go/internal/usage"
 	webassets "difflearn-go/web"
 )
 
 type diffRequestBody struct {
 	Question     string `json:"question"`
+	Path         string `json:"path"`
 	Staged       bool   `json:"staged"`
 	Commit       string `json:"commit"`
 	BranchBase   string `json:"branchBase"`
 	BranchTarget string `json:"branchTarget"`
 	BranchMode   string `json:"branchMode"`
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+	Refine       *bool  `json:"refine"`
+	Stash        *int   `json:"stash"`
+	Against      string `json:"against"`
+	Untracked    bool   `json:"untracked"`
+	// RecurseSubmodules expands submodule pointer changes into their diffs.
+	RecurseSubmodules bool   `json:"recurseSubmodules"`
+	Similarity        int    `json:"similarity"`
+	FindCopies        bool   `json:"findCopies"`
+	NoRenames         bool   `json:"noRenames"`
+	IgnoreWhitespace  bool   `json:"ignoreWhitespace"`
+	IgnoreSpaceChange bool   `json:"ignoreSpaceChange"`
+	IgnoreBlankLines  bool   `json:"ignoreBlankLines"`
+	Algorithm         string `json:"algorithm"`
+	// Structured asks /review for JSON issues instead of prose.
+	Structured bool `json:"structured"`
+	// Scope "all" takes staged, unstaged and untracked changes together.
+	Scope string `json:"scope"`
+	// ConversationID continues an earlier /ask conversation.
+	ConversationID string `json:"conversationId"`
+	// Client is "cli" when a DiffLearn command forwarded the request.
+	Client string `json:"client"`
+	// Level is the audience level: beginner, intermediate or expert.
+	Level string `json:"level"`
+	// Language is the language answers are written in, e.g. "German" or "de".
+	Language string `json:"language"`
+}
+
+// diffOptions collects the rename, whitespace and algorithm settings of a
+// request body. Unknown algorithms fall back to the configured default.
+func (b diffRequestBody) diffOptions() git.DiffOptions {
+	algorithm, _ := git.ParseDiffAlgorithm(b.Algorithm)
+	return git.DiffOptions{Renames: b.renameDetection(), Whitespace: b.whitespace(), Algorithm: algorithm}
+}
+
+func (b diffRequestBody) whitespace() git.WhitespaceOptions {
+	return git.WhitespaceOptions{IgnoreWhitespace: b.IgnoreWhitespace, IgnoreSpaceChange: b.IgnoreSpaceChange, IgnoreBlankLines: b.IgnoreBlankLines}
+}
+
+func (b diffRequestBody) renameDetection() git.RenameDetection {
+	return git.RenameDetection{Threshold: b.Similarity, Copies: b.FindCopies, Disable: b.NoRenames}
+}
+
+// renameDetectionFromQuery reads the similarity, copies and renames query
+// parameters shared by the diff endpoints.
+func renameDetectionFromQuery(q url.Values) git.RenameDetection {
+	threshold, _ := strconv.Atoi(q.Get("similarity"))
+	return git.RenameDetection{Threshold: threshold, Copies: q.Get("copies") == "true", Disable: q.Get("renames") == "false"}
+}
+
+// diffOptionsFromQuery is diffOptions for the GET endpoints.
+func diffOptionsFromQuery(q url.Values) git.DiffOptions {
+	algorithm, _ := git.ParseDiffAlgorithm(q.Get("algorithm"))
+	return git.DiffOptions{Renames: renameDetectionFromQuery(q), Whitespace: whitespaceFromQuery(q), Algorithm: algorithm}
+}
+
+// pageDiffs applies the offset and limit query parameters to a file list so
+// commits touching thousands of files can be loaded in parts. It returns nil
+// pagination when no limit was asked for.
+func pageDiffs(diffs []git.ParsedDiff, q url.Values) ([]git.ParsedDiff, map[string]any) {
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		return diffs, nil
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	offset = max(0, min(offset, len(diffs)))
+	end := min(offset+limit, len(diffs))
+	return diffs[offset:end], map[string]any{
+		"offset":     offset,
+		"limit":      limit,
+		"totalFiles": len(diffs),
+		"hasMore":    end < len(diffs),
+	}
+}
+
+// whitespaceFromQuery reads the ignoreWhitespace, ignoreSpaceChange and
+// ignoreBlankLines query parameters.
+func whitespaceFromQuery(q url.Values) git.WhitespaceOptions {
+	return git.WhitespaceFromOptions(map[string]string{
+		"ignoreWhitespace":  q.Get("ignoreWhitespace"),
+		"ignoreSpaceChange": q.Get("ignoreSpaceChange"),
+		"ignoreBlankLines":  q.Get("ignoreBlankLines"),
+	})
+}
+
+// blobHandler serves the raw content of a blob (/diff/blob/<oid>?path=...),
+// used for before/after previews of binary files. path selects the mime type
+// and lets unstaged working tree content be served.
+func blobHandler(g *git.GitExtractor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oid := strings.TrimPrefix(r.URL.Path, "/diff/blob/")
+		path := r.URL.Query().Get("path")
+		data, err := g.ReadBlob(oid, path)
+		if err != nil {
+			writeJSON(w, 404, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", git.DetectMimeType(path, data))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		// SVGs can carry scripts; never let a blob execute in our origin.
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+		w.Header().Set("Cache-Control", "private, max-age=3600")
+		w.Write(data)
+	}
+}
+
+// addRubricResults attaches the per-criterion breakdown of a rubric review.
+func addRubricResults(data map[string]any, review string, rubric []config.RubricCriterion) {
+	if len(rubric) == 0 {
+		return
+	}
+	results := llm.ParseRubricReview(review, rubric)
+	data["rubric"] = results
+	data["rubricScore"] = llm.WeightedScore(results)
+	data["failedGates"] = llm.FailedGates(results)
+}
+
+// addStructuredReview replaces the raw JSON answer of a structured review with
+// its parsed issues and a readable Markdown rendering.
+func addStructuredReview(data map[string]any, content string) {
+	result, err := llm.ParseReviewResult(content)
+	if err != nil {
+		data["structuredError"] = err.Error()
+		return
+	}
+	data["structured"] = result
+	data["review"] = llm.FormatReviewMarkdown(result)
+}
+
+func requestConfig(body diffRequestBody) (config.Config, error) {
+	cfg, err := config.WithOverrides(config.LoadConfig(), body.Provider, body.Model)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Language = strings.TrimSpace(body.Language)
+	return withLevel(cfg, body.Level)
+}
+
+// withLevel sets the audience level a request asks for; empty keeps the
+// server's default.
+func withLevel(cfg config.Config, value string) (config.Config, error) {
+	level, err := llm.ParseLevel(value)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Level = string(level)
+	return cfg, nil
 }
 
 func normalizeBranchMode(mode string) git.BranchDiffMode {
@@ -41,21 +204,37 @@ func formattedDiffPayload(formatter *git.DiffFormatter, diffs []git.ParsedDiff,
 	return parsed
 }
 
-func resolveBranchComparison(g *git.GitExtractor, base, target string, mode git.BranchDiffMode) ([]git.ParsedDiff, map[string]any, error) {
-	baseResolved, err := g.EnsureLocalBranch(base)
+func resolveBranchComparison(g *git.GitExtractor, base, target string, mode git.BranchDiffMode, options ...git.DiffOptions) ([]git.ParsedDiff, map[string]any, error) {
+	baseResolved, targetResolved, err := resolveBranchPair(g, base, target)
 	if err != nil {
 		return nil, nil, err
 	}
-	targetResolved, err := g.EnsureLocalBranch(target)
+	opts := git.DiffOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	diffs, err := g.GetBranchDiffWithOptions(baseResolved.ResolvedLocalBranch, targetResolved.ResolvedLocalBranch, mode, opts)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	diffs, err := g.GetBranchDiff(baseResolved.ResolvedLocalBranch, targetResolved.ResolvedLocalBranch, mode)
+	return diffs, branchComparisonMetadata(baseResolved, targetResolved, mode), nil
+}
+
+func resolveBranchPair(g *git.GitExtractor, base, target string) (git.EnsureBranchResult, git.EnsureBranchResult, error) {
+	baseResolved, err := g.EnsureLocalBranch(base)
 	if err != nil {
-		return nil, nil, err
+		return git.EnsureBranchResult{}, git.EnsureBranchResult{}, err
 	}
+	targetResolved, err := g.EnsureLocalBranch(target)
+	if err != nil {
+		return git.EnsureBranchResult{}, git.EnsureBranchResult{}, err
+	}
+	return baseResolved, targetResolved, nil
+}
 
+func branchComparisonMetadata(baseResolved, targetResolved git.EnsureBranchResult, mode git.BranchDiffMode) map[string]any {
 	localizedBranches := make([]string, 0)
 	if baseResolved.Localized {
 		localizedBranches = append(localizedBranches, baseResolved.ResolvedLocalBranch)
@@ -81,15 +260,13 @@ func resolveBranchComparison(g *git.GitExtractor, base, target string, mode git.
 		messages = append(messages, targetResolved.Message)
 	}
 
-	comparison := map[string]any{
+	return map[string]any{
 		"baseResolved":      baseResolved.ResolvedLocalBranch,
 		"targetResolved":    targetResolved.ResolvedLocalBranch,
 		"mode":              mode,
 		"localizedBranches": localizedBranches,
 		"messages":          messages,
 	}
-
-	return diffs, comparison, nil
 }
 
 func StartAPIServer(port int, repoPath string) error {
@@ -99,8 +276,24 @@ func StartAPIServer(port int, repoPath string) error {
 	if repoPath == "" {
 		repoPath = "."
 	}
-	g := git.NewGitExtractor(repoPath)
+	llm.UseRepository(repoPath)
+	tokens, err := ParseTokens(config.Setting("DIFFLEARN_API_TOKENS"))
+	if err != nil {
+		return err
+	}
+	pool := git.NewPooledRunner(git.ExecRunner{}, config.GitWorkers())
+	var runner git.GitRunner = pool
+	if dir := git.CacheDir(); dir != "" {
+		// Cache hits skip the pool: they run no git.
+		runner = git.NewCachingRunner(runner, dir)
+	}
+	repo := git.NewGitExtractorWithRunner(repoPath, runner)
+	// gitFor is the extractor of one request: its git commands share the
+	// pool fairly with other requests' and stop when the request ends.
+	gitFor := func(r *http.Request) *git.GitExtractor { return repo.WithContext(r.Context()) }
 	formatter := git.NewDiffFormatter()
+	gitTimeout := config.GitTimeout()
+	conversations := newConversationStore()
 
 	webDir, hasDiskWeb := findWebDir(repoPath)
 
@@ -108,13 +301,15 @@ func StartAPIServer(port int, repoPath string) error {
 	withCORS := func(h http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			w.Header().Set("Access-Control-Allow-Methods", "GET,POST,OPTIONS")
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusNoContent)
 				return
 			}
-			h(w, r)
+			ctx, cancel := context.WithTimeout(r.Context(), gitTimeout)
+			defer cancel()
+			h(w, r.WithContext(ctx))
 		}
 	}
 
@@ -126,6 +321,7 @@ func StartAPIServer(port int, repoPath string) error {
 	}))
 
 	mux.HandleFunc("/", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
 		accept := r.Header.Get("Accept")
 		if strings.Contains(accept, "text/html") {
 			serveWebAsset(w, r, hasDiskWeb, webDir, "index.html", "text/html")
@@ -134,15 +330,88 @@ func StartAPIServer(port int, repoPath string) error {
 		cfg := config.LoadConfig()
 		writeJSON(w, 200, map[string]any{
 			"name":         "difflearn",
-			"version":      "0.3.0",
+			"version":      update.GetCurrentVersion(),
 			"status":       "running",
 			"llmAvailable": config.IsLLMAvailable(cfg),
 			"llmProvider":  cfg.Provider,
 			"cwd":          g.RepoPath(),
+			"git":          pool.Stats(),
+		})
+	}))
+
+	mux.HandleFunc("/progress", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		events, err := learning.Open().Events()
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": learning.Compute(events, learning.GoalFromConfig(), time.Now())})
+	}))
+
+	// Team mode: members push learning events and leads read progress. It
+	// is off unless DIFFLEARN_TEAM_TOKEN is set on the server.
+	teamHandler := team.Handler(config.Setting("DIFFLEARN_TEAM_TOKEN"), team.Open())
+	mux.HandleFunc("/team/", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		if config.Setting("DIFFLEARN_TEAM_TOKEN") == "" {
+			writeJSON(w, 404, map[string]any{"success": false, "error": "team mode is disabled on this server (set DIFFLEARN_TEAM_TOKEN)"})
+			return
+		}
+		teamHandler(w, r)
+	}))
+
+	// Usage of the LLM budgets: the whole server's, and the caller's own when
+	// it sent an API token.
+	mux.HandleFunc("/usage", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		budget, err := usage.LoadBudget()
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		ledger, key, now := usage.Open(), TokenKey(r), time.Now()
+		caps, err := ledger.Status(budget, key, now)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		total, err := ledger.Summary("", now)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		data := map[string]any{"budget": caps, "usage": total}
+		if key != "" {
+			own, err := ledger.Summary(key, now)
+			if err != nil {
+				writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+				return
+			}
+			data["token"] = map[string]any{"key": key, "usage": own}
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": data})
+	}))
+
+	mux.HandleFunc("/providers", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		cfg := config.LoadConfig()
+		writeJSON(w, 200, map[string]any{
+			"success": true,
+			"data": map[string]any{
+				"active":    map[string]any{"provider": cfg.Provider, "model": cfg.Model},
+				"providers": config.ListProviderOptions(cfg),
+			},
 		})
 	}))
 
+	mux.HandleFunc("/repo", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		info, err := gitFor(r).GetRepoInfo()
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": info})
+	}))
+
 	mux.HandleFunc("/branches", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
 		branches, err := g.GetBranchesDetailed()
 		if err != nil {
 			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
@@ -154,22 +423,52 @@ func StartAPIServer(port int, repoPath string) error {
 			return
 		}
 
+		tags, err := g.GetTags()
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+
 		writeJSON(w, 200, map[string]any{
 			"success": true,
 			"data": map[string]any{
 				"currentBranch": current,
+				"defaultBranch": g.DefaultBranch(),
 				"branches":      branches,
+				"tags":          tags,
 			},
 		})
 	}))
 
+	mux.HandleFunc("/tags", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		tags, err := gitFor(r).GetTags()
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": tags})
+	}))
+
 	mux.HandleFunc("/diff/local", withCORS(func(w http.ResponseWriter, r *http.Request) {
-		staged := r.URL.Query().Get("staged") == "true"
+		g := gitFor(r)
+		// scope=all merges staged, unstaged and untracked changes and labels
+		// each file; scope=staged is the same as staged=true.
+		scope := r.URL.Query().Get("scope")
+		all := scope == "all"
+		staged := r.URL.Query().Get("staged") == "true" || scope == "staged"
+		against := r.URL.Query().Get("against")
+		untracked := r.URL.Query().Get("untracked") == "true"
+		if all {
+			staged, against, untracked = false, "HEAD", true
+		}
+		recurse := r.URL.Query().Get("recurseSubmodules") == "true"
 		format := r.URL.Query().Get("format")
 		if format == "" {
 			format = "json"
 		}
-		diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: staged})
+		options := diffOptionsFromQuery(r.URL.Query())
+		options.Staged, options.Against, options.IncludeUntracked, options.RecurseSubmodules, options.All = staged, against, untracked, recurse, all
+		diffs, err := g.GetLocalDiff(options)
 		if err != nil {
 			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
 			return
@@ -178,7 +477,7 @@ func StartAPIServer(port int, repoPath string) error {
 		case "markdown":
 			w.Write([]byte(formatter.ToMarkdown(diffs)))
 		case "raw":
-			raw, err := g.GetRawDiff(map[bool]string{true: "staged", false: "local"}[staged], nil)
+			raw, err := g.GetRawDiff(map[bool]string{true: "staged", false: "local"}[staged], map[string]string{"against": against, "untracked": strconv.FormatBool(untracked), "ignoreWhitespace": r.URL.Query().Get("ignoreWhitespace"), "ignoreSpaceChange": r.URL.Query().Get("ignoreSpaceChange"), "ignoreBlankLines": r.URL.Query().Get("ignoreBlankLines"), "algorithm": r.URL.Query().Get("algorithm")})
 			if err != nil {
 				writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
 				return
@@ -189,18 +488,75 @@ func StartAPIServer(port int, repoPath string) error {
 		}
 	}))
 
+	mux.HandleFunc("/diff/stages", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		views, err := gitFor(r).GetStageViews(r.URL.Query()["path"]...)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": views})
+	}))
+
 	mux.HandleFunc("/diff/commit/", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
 		sha := strings.TrimPrefix(r.URL.Path, "/diff/commit/")
 		sha2 := r.URL.Query().Get("compare")
-		diffs, err := g.GetCommitDiff(sha, sha2)
+		diffs, err := g.GetCommitDiffWithOptions(sha, sha2, diffOptionsFromQuery(r.URL.Query()))
 		if err != nil {
 			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
 			return
 		}
+		if r.URL.Query().Get("recurseSubmodules") == "true" {
+			diffs = g.ExpandSubmodules(diffs)
+		}
+		if sha2 == "" {
+			if hash, err := g.ResolveRef(sha); err == nil {
+				learning.Record(g.RepoPath(), learning.KindCommit, hash)
+			}
+		}
+		diffs, pagination := pageDiffs(diffs, r.URL.Query())
+		payload := formattedDiffPayload(formatter, diffs, nil)
+		if pagination != nil {
+			payload["pagination"] = pagination
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": payload})
+	}))
+
+	mux.HandleFunc("/diff/blob/", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		blobHandler(gitFor(r))(w, r)
+	}))
+
+	mux.HandleFunc("/diff/stash", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
+		stashes, err := g.GetStashList()
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": stashes})
+	}))
+
+	mux.HandleFunc("/diff/stash/", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
+		index, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/diff/stash/"))
+		if err != nil {
+			writeJSON(w, 400, map[string]any{"success": false, "error": "invalid stash index"})
+			return
+		}
+		diffs, err := g.GetStashDiff(index)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		if r.URL.Query().Get("format") == "markdown" {
+			w.Write([]byte(formatter.ToMarkdown(diffs)))
+			return
+		}
 		writeJSON(w, 200, map[string]any{"success": true, "data": formattedDiffPayload(formatter, diffs, nil)})
 	}))
 
 	mux.HandleFunc("/diff/branch", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
 		base := r.URL.Query().Get("base")
 		target := r.URL.Query().Get("target")
 		if base == "" || target == "" {
@@ -213,11 +569,14 @@ func StartAPIServer(port int, repoPath string) error {
 			format = "json"
 		}
 
-		diffs, comparison, err := resolveBranchComparison(g, base, target, mode)
+		diffs, comparison, err := resolveBranchComparison(g, base, target, mode, diffOptionsFromQuery(r.URL.Query()))
 		if err != nil {
 			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
 			return
 		}
+		if r.URL.Query().Get("recurseSubmodules") == "true" {
+			diffs = g.ExpandSubmodules(diffs)
+		}
 
 		if format == "markdown" {
 			w.Write([]byte(formatter.ToMarkdown(diffs)))
@@ -227,7 +586,68 @@ func StartAPIServer(port int, repoPath string) error {
 		writeJSON(w, 200, map[string]any{"success": true, "data": formattedDiffPayload(formatter, diffs, comparison)})
 	}))
 
+	mux.HandleFunc("/diff/branch/summary", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
+		base := r.URL.Query().Get("base")
+		target := r.URL.Query().Get("target")
+		if base == "" || target == "" {
+			writeJSON(w, 400, map[string]any{"success": false, "error": "base and target are required"})
+			return
+		}
+		mode := normalizeBranchMode(r.URL.Query().Get("mode"))
+
+		baseResolved, targetResolved, err := resolveBranchPair(g, base, target)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		stats, err := g.GetBranchNumstat(baseResolved.ResolvedLocalBranch, targetResolved.ResolvedLocalBranch, mode)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+
+		adds, dels := 0, 0
+		for _, s := range stats {
+			adds += s.Additions
+			dels += s.Deletions
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{
+			"summary":    map[string]any{"files": len(stats), "additions": adds, "deletions": dels},
+			"files":      stats,
+			"comparison": branchComparisonMetadata(baseResolved, targetResolved, mode),
+		}})
+	}))
+
+	mux.HandleFunc("/diff/branch/file", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
+		q := r.URL.Query()
+		base, target, path := q.Get("base"), q.Get("target"), q.Get("path")
+		if base == "" || target == "" || path == "" {
+			writeJSON(w, 400, map[string]any{"success": false, "error": "base, target and path are required"})
+			return
+		}
+		mode := normalizeBranchMode(q.Get("mode"))
+
+		baseResolved, targetResolved, err := resolveBranchPair(g, base, target)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		paths := []string{path}
+		if oldPath := q.Get("oldPath"); oldPath != "" && oldPath != path {
+			paths = append(paths, oldPath)
+		}
+		diffs, err := g.GetBranchFileDiff(baseResolved.ResolvedLocalBranch, targetResolved.ResolvedLocalBranch, paths, mode)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": formattedDiffPayload(formatter, diffs, nil)})
+	}))
+
 	mux.HandleFunc("/diff/branch/", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
 		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/diff/branch/"), "/")
 		if len(parts) < 2 {
 			writeJSON(w, 400, map[string]any{"success": false, "error": "branch1 and branch2 required"})
@@ -241,7 +661,7 @@ func StartAPIServer(port int, repoPath string) error {
 			format = "json"
 		}
 
-		diffs, comparison, err := resolveBranchComparison(g, branch1, branch2, mode)
+		diffs, comparison, err := resolveBranchComparison(g, branch1, branch2, mode, diffOptionsFromQuery(r.URL.Query()))
 		if err != nil {
 			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
 			return
@@ -256,6 +676,7 @@ func StartAPIServer(port int, repoPath string) error {
 	}))
 
 	mux.HandleFunc("/branch/switch", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
 		var body struct {
 			Branch    string `json:"branch"`
 			AutoStash *bool  `json:"autoStash"`
@@ -280,21 +701,67 @@ func StartAPIServer(port int, repoPath string) error {
 		writeJSON(w, 200, map[string]any{"success": true, "data": result})
 	}))
 
-	mux.HandleFunc("/history", withCORS(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/graph", withCORS(func(w http.ResponseWriter, r *http.Request) {
 		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit == 0 {
+			limit = 50
+		}
+		graph, err := gitFor(r).GetCommitGraph(limit, r.URL.Query().Get("all") == "true")
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": graph})
+	}))
+
+	mux.HandleFunc("/history", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
+		q := r.URL.Query()
+		limit, _ := strconv.Atoi(q.Get("limit"))
 		if limit == 0 {
 			limit = 10
 		}
-		commits, err := g.GetCommitHistory(limit)
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		maxFiles, _ := strconv.Atoi(q.Get("files"))
+		page, err := g.GetCommitHistoryPage(q.Get("cursor"), offset, limit, maxFiles)
+		if err != nil {
+			status := 500
+			if q.Get("cursor") != "" {
+				status = 400
+			}
+			writeJSON(w, status, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": page.Commits, "pagination": map[string]any{
+			"head":       page.Head,
+			"offset":     page.Offset,
+			"limit":      page.Limit,
+			"hasMore":    page.HasMore,
+			"nextCursor": page.NextCursor,
+		}})
+	}))
+
+	// Reverted commits and their reverts and reapplies, so the history list
+	// can show them as one story.
+	mux.HandleFunc("/history/reverts", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit <= 0 {
+			limit = 500
+		}
+		pairs, err := gitFor(r).FindRevertPairs(fmt.Sprintf("--max-count=%d", limit))
 		if err != nil {
 			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
 			return
 		}
-		writeJSON(w, 200, map[string]any{"success": true, "data": commits})
+		if pairs == nil {
+			pairs = []git.RevertPair{}
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": pairs})
 	}))
 
 	aiHandler := func(kind string) http.HandlerFunc {
 		return withCORS(func(w http.ResponseWriter, r *http.Request) {
+			g := gitFor(r)
 			var body diffRequestBody
 			_ = json.NewDecoder(r.Body).Decode(&body)
 
@@ -309,14 +776,20 @@ func StartAPIServer(port int, repoPath string) error {
 				return
 			}
 
-			cfg := config.LoadConfig()
+			cfg, err := requestConfig(body)
+			if err != nil {
+				writeJSON(w, 400, map[string]any{"success": false, "error": err.Error()})
+				return
+			}
 			if !config.IsLLMAvailable(cfg) {
 				prompt := ""
 				switch kind {
 				case "explain":
 					prompt = llm.CreateExplainPrompt(formatter, diffs)
 				case "review":
-					prompt = llm.CreateReviewPrompt(formatter, diffs)
+					findings := analysis.RunRules(diffs)
+					writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"llmAvailable": false, "prompt": llm.CreateReviewPrompt(formatter, diffs), "findings": findings, "review": analysis.FormatFindings(findings), "offline": true}})
+					return
 				case "ask":
 					if body.Question == "" {
 						writeJSON(w, 400, map[string]any{"success": false, "error": "Question is required"})
@@ -324,56 +797,369 @@ func StartAPIServer(port int, repoPath string) error {
 					}
 					prompt = llm.CreateQuestionPrompt(formatter, diffs, body.Question)
 				case "summary":
-					writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"summary": formatter.ToSummary(diffs), "llmAvailable": false}})
+					writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"summary": analysis.OfflineSummary(diffs) + "\n\n" + formatter.ToSummary(diffs), "llmAvailable": false, "offline": true}})
 					return
 				}
-				writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"llmAvailable": false, "prompt": prompt, "message": "No LLM API key configured. Use the prompt with your own LLM."}})
+				data := map[string]any{"llmAvailable": false, "prompt": prompt, "message": "No LLM API key configured. Use the prompt with your own LLM."}
+				if kind == "explain" {
+					data["explanation"] = analysis.OfflineExplanation(diffs)
+					data["offline"] = true
+				}
+				writeJSON(w, 200, map[string]any{"success": true, "data": data})
 				return
 			}
 
-			client := llm.NewClient(cfg)
-			prompt := ""
+			client := llm.NewClient(cfg).For(TokenKey(r), kind)
+			var build func([]git.ParsedDiff) string
+			var rc llm.ReviewContext
 			respField := ""
 			switch kind {
 			case "explain":
-				prompt = llm.CreateExplainPrompt(formatter, diffs)
+				build = func(d []git.ParsedDiff) string { return llm.CreateExplainPrompt(formatter, d) }
 				respField = "explanation"
 			case "review":
-				prompt = llm.CreateReviewPrompt(formatter, diffs)
+				repoCfg, err := config.LoadRepoConfig(repoPath)
+				if err != nil {
+					writeJSON(w, 400, map[string]any{"success": false, "error": err.Error()})
+					return
+				}
+				rc = llm.ReviewContext{Findings: analysis.RunRules(diffs), Rubric: repoCfg.Rubric, Structured: body.Structured}
+				build = func(d []git.ParsedDiff) string { return llm.CreateReviewPromptWithContext(formatter, d, rc) }
 				respField = "review"
 			case "ask":
 				if body.Question == "" {
 					writeJSON(w, 400, map[string]any{"success": false, "error": "Question is required"})
 					return
 				}
-				prompt = llm.CreateQuestionPrompt(formatter, diffs, body.Question)
+				build = func(d []git.ParsedDiff) string { return llm.CreateQuestionPrompt(formatter, d, body.Question) }
 				respField = "answer"
 			case "summary":
-				prompt = llm.CreateSummaryPrompt(formatter, diffs)
+				build = func(d []git.ParsedDiff) string { return llm.CreateSummaryPrompt(formatter, d) }
 				respField = "summary"
 			}
-			resp, err := client.Chat([]llm.ChatMessage{{Role: "system", Content: llm.SystemPrompt}, {Role: "user", Content: prompt}})
+			budget := llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens)
+			refine := cfg.RefineReview
+			if body.Refine != nil {
+				refine = *body.Refine
+			}
+			if kind == "review" && refine && !rc.Structured {
+				result, err := llm.ReviewWithRefinement(client, formatter, diffs, budget, rc)
+				if err != nil {
+					writeLLMError(w, err)
+					return
+				}
+				data := map[string]any{"review": result.Final, "draft": result.Draft, "refined": true, "findings": rc.Findings, "usage": result.Usage, "provider": cfg.Provider, "model": cfg.Model}
+				addRubricResults(data, result.Final, rc.Rubric)
+				learning.Record(g.RepoPath(), kind, body.Commit)
+				recordSession(g, cfg, kind, body, diffs, build(diffs), result.Final)
+				writeJSON(w, 200, map[string]any{"success": true, "data": data})
+				return
+			}
+			// Questions continue the conversation they name, so follow-ups
+			// see the earlier questions and answers.
+			var conv llm.Conversation
+			var resp llm.LLMResponse
+			var report llm.BudgetReport
+			if kind == "ask" {
+				conv = conversations.get(TokenKey(r), body.ConversationID)
+				resp, report, err = conv.Ask(client, formatter, diffs, budget, body.Question)
+			} else {
+				resp, report, err = llm.RunBudgeted(client, formatter, diffs, budget, build)
+			}
 			if err != nil {
-				writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+				writeLLMError(w, err)
 				return
 			}
-			data := map[string]any{respField: resp.Content, "usage": resp.Usage}
+			// A fallback provider may have answered instead.
+			answered := cfg
+			if resp.Provider != "" {
+				answered.Provider, answered.Model = resp.Provider, resp.Model
+			}
+			data := map[string]any{respField: resp.Content, "usage": resp.Usage, "provider": answered.Provider, "model": answered.Model}
+			if kind == "ask" {
+				conversations.put(TokenKey(r), conv)
+				data["conversationId"] = conv.ID
+				data["turns"] = len(conv.Turns)
+			}
 			if kind == "summary" {
 				data["basicSummary"] = formatter.ToSummary(diffs)
 			}
+			if kind == "review" {
+				data["findings"] = rc.Findings
+				if rc.Structured {
+					addStructuredReview(data, resp.Content)
+				} else {
+					addRubricResults(data, resp.Content, rc.Rubric)
+				}
+			}
+			if report.Chunks > 1 || report.HasOmissions() {
+				data["budget"] = report
+			}
+			learning.Record(g.RepoPath(), kind, body.Commit)
+			recordSession(g, answered, kind, body, diffs, build(diffs), resp.Content)
 			writeJSON(w, 200, map[string]any{"success": true, "data": data})
 		})
 	}
 
+	mux.HandleFunc("/explain/file", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
+		var body diffRequestBody
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if strings.TrimSpace(body.Path) == "" {
+			writeJSON(w, 400, map[string]any{"success": false, "error": "path is required"})
+			return
+		}
+
+		diffs, err := g.GetFileDiff(body.Path, body.Commit)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		if len(diffs) == 0 {
+			writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"explanation": "No changes.", "path": body.Path}})
+			return
+		}
+
+		cfg, err := requestConfig(body)
+		if err != nil {
+			writeJSON(w, 400, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		budget := llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens)
+		build := func(d []git.ParsedDiff) string { return llm.CreateFileExplainPrompt(formatter, d, body.Path) }
+		if !config.IsLLMAvailable(cfg) {
+			fitted, _ := budget.Fit(formatter, diffs)
+			writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"llmAvailable": false, "prompt": build(fitted), "path": body.Path, "explanation": analysis.OfflineExplanation(diffs), "offline": true, "message": "No LLM API key configured. Use the prompt with your own LLM."}})
+			return
+		}
+
+		resp, report, err := llm.RunBudgeted(llm.NewClient(cfg).For(TokenKey(r), "explain/file"), formatter, diffs, budget, build)
+		if err != nil {
+			writeLLMError(w, err)
+			return
+		}
+		data := map[string]any{"explanation": resp.Content, "path": body.Path, "usage": resp.Usage}
+		if report.Chunks > 1 || report.HasOmissions() {
+			data["budget"] = report
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": data})
+	}))
+
+	mux.HandleFunc("/compare", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
+		var body struct {
+			diffRequestBody
+			Kind   string   `json:"kind"`
+			Models []string `json:"models"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Models) < 2 {
+			writeJSON(w, 400, map[string]any{"success": false, "error": "at least two models are required"})
+			return
+		}
+
+		cfg := config.LoadConfig()
+		cfg.Language = strings.TrimSpace(body.Language)
+		cfg, err := withLevel(cfg, body.Level)
+		if err != nil {
+			writeJSON(w, 400, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		cfgs := make([]config.Config, 0, len(body.Models))
+		for _, spec := range body.Models {
+			resolved, err := config.ResolveModelSpec(cfg, spec)
+			if err != nil {
+				writeJSON(w, 400, map[string]any{"success": false, "error": err.Error()})
+				return
+			}
+			cfgs = append(cfgs, resolved)
+		}
+
+		diffs, err := getDiffForRequest(g, body.diffRequestBody)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		if len(diffs) == 0 {
+			writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"answers": []llm.ModelAnswer{}, "message": "No changes."}})
+			return
+		}
+		diffs, _ = llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens).Fit(formatter, diffs)
+
+		prompt := ""
+		switch body.Kind {
+		case "", "explain":
+			prompt = llm.CreateExplainPrompt(formatter, diffs)
+		case "review":
+			prompt = llm.CreateReviewPrompt(formatter, diffs)
+		case "summary":
+			prompt = llm.CreateSummaryPrompt(formatter, diffs)
+		case "ask":
+			if body.Question == "" {
+				writeJSON(w, 400, map[string]any{"success": false, "error": "Question is required"})
+				return
+			}
+			prompt = llm.CreateQuestionPrompt(formatter, diffs, body.Question)
+		default:
+			writeJSON(w, 400, map[string]any{"success": false, "error": "unknown kind: " + body.Kind})
+			return
+		}
+
+		answers := llm.CompareModelsFor(TokenKey(r), "compare", cfgs, []llm.ChatMessage{{Role: "system", Content: llm.SystemPrompt}, {Role: "user", Content: prompt}})
+		writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"answers": answers}})
+	}))
+
+	// Past AI sessions of this repository (all with all=true), newest
+	// first; the list leaves out prompts, /sessions/<id> has everything.
+	mux.HandleFunc("/sessions", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		query := aihistory.Query{Kind: q.Get("kind"), Text: q.Get("q"), Limit: 50}
+		if n, err := strconv.Atoi(q.Get("limit")); err == nil && n >= 0 {
+			query.Limit = n
+		}
+		if q.Get("all") != "true" {
+			query.Repo = gitFor(r).RepoPath()
+		}
+		entries, err := aihistory.Open().Search(query)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		for i := range entries {
+			entries[i].Prompt = ""
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": entries})
+	}))
+	mux.HandleFunc("/sessions/", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		entry, err := aihistory.Open().Find(strings.TrimPrefix(r.URL.Path, "/sessions/"))
+		if errors.Is(err, aihistory.ErrNotFound) {
+			writeJSON(w, 404, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, 400, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": entry})
+	}))
+
 	mux.HandleFunc("/explain", aiHandler("explain"))
 	mux.HandleFunc("/review", aiHandler("review"))
 	mux.HandleFunc("/ask", aiHandler("ask"))
 	mux.HandleFunc("/summary", aiHandler("summary"))
 
-	addr := fmt.Sprintf(":%d", port)
+	mux.HandleFunc("/annotate", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		g := gitFor(r)
+		var body diffRequestBody
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		diffs, err := getDiffForRequest(g, body)
+		if err != nil {
+			writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		if len(diffs) == 0 {
+			writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"annotations": []git.Annotation{}, "dropped": []git.Annotation{}}})
+			return
+		}
+		cfg, err := requestConfig(body)
+		if err != nil {
+			writeJSON(w, 400, map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		if !config.IsLLMAvailable(cfg) {
+			writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"llmAvailable": false, "prompt": llm.CreateAnnotationPrompt(diffs), "message": "No LLM API key configured. Use the prompt with your own LLM."}})
+			return
+		}
+		result, err := llm.Annotate(llm.NewClient(cfg).For(TokenKey(r), "annotate"), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens))
+		if err != nil {
+			writeLLMError(w, err)
+			return
+		}
+		writeJSON(w, 200, map[string]any{"success": true, "data": map[string]any{"annotations": result.Annotations, "dropped": result.Dropped, "budget": result.Budget, "usage": result.Usage, "provider": cfg.Provider, "model": cfg.Model}})
+	}))
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
 	fmt.Printf("\n🔍 DiffLearn Web UI running at http://localhost:%d\n", port)
-	fmt.Printf("   API available at http://localhost:%d/diff/local\n\n", port)
-	return http.ListenAndServe(addr, mux)
+	fmt.Printf("   API available at http://localhost:%d/diff/local\n", port)
+	if len(tokens) > 0 {
+		fmt.Printf("   API tokens required (%d configured)\n", len(tokens))
+	}
+
+	// Editor extensions and other DiffLearn commands find the server
+	// through its discovery file, and on the network through mDNS.
+	self, unregister, err := discovery.Register(discovery.Server{Port: port, Repo: repo.RepoPath(), Version: update.GetCurrentVersion()})
+	if err != nil {
+		fmt.Printf("   Discovery file not written: %v\n", err)
+	}
+	defer unregister()
+	if config.MDNS() {
+		if withdraw, err := discovery.Advertise(self); err != nil {
+			fmt.Printf("   mDNS advertisement failed: %v\n", err)
+		} else {
+			defer withdraw()
+			fmt.Printf("   Advertised over mDNS as %s\n", discovery.ServiceType)
+		}
+	}
+	fmt.Println()
+
+	srv := &http.Server{Handler: tokens.Middleware(mux)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// recordSession keeps an answered web request in the session history.
+func recordSession(g *git.GitExtractor, cfg config.Config, kind string, body diffRequestBody, diffs []git.ParsedDiff, prompt, response string) {
+	ref := "working tree"
+	switch {
+	case body.BranchBase != "" && body.BranchTarget != "":
+		ref = body.BranchBase + "..." + body.BranchTarget
+		if normalizeBranchMode(body.BranchMode) == git.BranchModeDouble {
+			ref = body.BranchBase + ".." + body.BranchTarget
+		}
+	case body.Commit != "":
+		ref = body.Commit
+	case body.Stash != nil:
+		ref = fmt.Sprintf("stash@{%d}", *body.Stash)
+	case body.Against != "":
+		ref = body.Against
+	case body.Staged:
+		ref = "staged"
+	}
+	source := "web"
+	if body.Client == "cli" {
+		source = "cli"
+	}
+	_ = aihistory.Open().Append(aihistory.Entry{
+		Repo:   g.RepoPath(),
+		Kind:   kind,
+		Source: source,
+		Ref:    ref,
+		Selection: aihistory.Selection{
+			Staged:    body.Staged,
+			Stash:     body.Stash,
+			Against:   body.Against,
+			Untracked: body.Untracked,
+			Question:  body.Question,
+		},
+		DiffHash:    aihistory.HashDiffs(diffs),
+		Provider:    string(cfg.Provider),
+		Model:       cfg.Model,
+		Temperature: cfg.Temperature,
+		Prompt:      prompt,
+		Response:    response,
+		Preloaded:   body.Commit != "" || body.BranchBase != "",
+	})
 }
 
 func findWebDir(repoPath string) (string, bool) {
@@ -406,6 +1192,16 @@ func serveWebAsset(w http.ResponseWriter, r *http.Request, hasDiskWeb bool, webD
 	_, _ = w.Write(data)
 }
 
+// writeLLMError answers a failed LLM call: 429 when a budget is used up,
+// 500 otherwise.
+func writeLLMError(w http.ResponseWriter, err error) {
+	if errors.Is(err, usage.ErrBudgetExceeded) {
+		writeJSON(w, http.StatusTooManyRequests, map[string]any{"success": false, "error": err.Error(), "budgetExceeded": true})
+		return
+	}
+	writeJSON(w, 500, map[string]any{"success": false, "error": err.Error()})
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -413,21 +1209,36 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 }
 
 func getDiffForRequest(g *git.GitExtractor, body diffRequestBody) ([]git.ParsedDiff, error) {
+	diffs, err := selectDiffForRequest(g, body)
+	if err != nil || !body.RecurseSubmodules {
+		return diffs, err
+	}
+	return g.ExpandSubmodules(diffs), nil
+}
+
+func selectDiffForRequest(g *git.GitExtractor, body diffRequestBody) ([]git.ParsedDiff, error) {
 	if body.BranchBase != "" && body.BranchTarget != "" {
 		mode := normalizeBranchMode(body.BranchMode)
-		diffs, _, err := resolveBranchComparison(g, body.BranchBase, body.BranchTarget, mode)
+		diffs, _, err := resolveBranchComparison(g, body.BranchBase, body.BranchTarget, mode, body.diffOptions())
 		return diffs, err
 	}
 
+	if body.Stash != nil {
+		return g.GetStashDiff(*body.Stash)
+	}
+
 	if body.Commit != "" {
 		if strings.Contains(body.Commit, "..") {
 			parts := strings.SplitN(body.Commit, "..", 2)
 			if len(parts) == 2 {
-				return g.GetCommitDiff(parts[0], parts[1])
+				return g.GetCommitDiffWithOptions(parts[0], parts[1], body.diffOptions())
 			}
 		}
-		return g.GetCommitDiff(body.Commit, "")
+		return g.GetCommitDiffWithOptions(body.Commit, "", body.diffOptions())
 	}
 
-	return g.GetLocalDiff(git.DiffOptions{Staged: body.Staged})
+	options := body.diffOptions()
+	options.Staged, options.Against, options.IncludeUntracked = body.Staged, body.Against, body.Untracked
+	options.All = body.Scope == "all"
+	return g.GetLocalDiff(options)
 }
@@ -3,6 +3,9 @@ package api
 import (
 	"io"
 	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -48,19 +51,52 @@ func TestNormalizeBranchMode(t *testing.T) {
 	}
 }
 
-func TestResolveBranchComparisonLocalBranches(t *testing.T) {
-	g := git.NewGitExtractor("../../..")
-	current, err := g.GetCurrentBranch()
-	if err != nil {
-		t.Fatalf("GetCurrentBranch() error = %v", err)
+// fixtureRepo creates a repository whose "feature" branch adds one file on
+// top of the initial branch, which is returned as base.
+func fixtureRepo(t *testing.T) (dir, base string) {
+	t.Helper()
+	dir = t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
 	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test")
+	write("go.mod", "module fixture\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	base = run("rev-parse", "--abbrev-ref", "HEAD")
+	run("checkout", "-q", "-b", "feature")
+	write("feature.go", "package fixture\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "add feature")
+	run("checkout", "-q", base)
+	return dir, base
+}
 
-	diffs, comparison, err := resolveBranchComparison(g, current, current, git.BranchModeTriple)
+func TestResolveBranchComparisonLocalBranches(t *testing.T) {
+	dir, base := fixtureRepo(t)
+	g := git.NewGitExtractor(dir)
+
+	diffs, comparison, err := resolveBranchComparison(g, base, "feature", git.BranchModeTriple)
 	if err != nil {
 		t.Fatalf("resolveBranchComparison() error = %v", err)
 	}
-	if diffs == nil {
-		t.Fatalf("expected diff slice")
+	if len(diffs) != 1 || diffs[0].NewFile != "feature.go" {
+		t.Fatalf("expected feature.go in the branch diff, got %+v", diffs)
 	}
 	if comparison["baseResolved"] == nil || comparison["targetResolved"] == nil {
 		t.Fatalf("expected comparison metadata, got %+v", comparison)
@@ -68,15 +104,13 @@ func TestResolveBranchComparisonLocalBranches(t *testing.T) {
 }
 
 func TestGetDiffForRequestBranchPrecedence(t *testing.T) {
-	g := git.NewGitExtractor("../../..")
-	current, err := g.GetCurrentBranch()
-	if err != nil {
-		t.Fatalf("GetCurrentBranch() error = %v", err)
-	}
+	dir, base := fixtureRepo(t)
+	recorder := git.NewRecordingRunner(nil)
+	g := git.NewGitExtractorWithRunner(dir, recorder)
 
 	diffs, err := getDiffForRequest(g, diffRequestBody{
-		BranchBase:   current,
-		BranchTarget: current,
+		BranchBase:   base,
+		BranchTarget: "feature",
 		BranchMode:   "double",
 		Commit:       "deadbeef",
 		Staged:       true,
@@ -84,7 +118,35 @@ func TestGetDiffForRequestBranchPrecedence(t *testing.T) {
 	if err != nil {
 		t.Fatalf("getDiffForRequest() error = %v", err)
 	}
-	if diffs == nil {
-		t.Fatalf("expected diff slice")
+	if len(diffs) != 1 || diffs[0].NewFile != "feature.go" {
+		t.Fatalf("expected the branch diff, got %+v", diffs)
+	}
+	for _, call := range recorder.Calls() {
+		joined := strings.Join(call, " ")
+		if strings.Contains(joined, "deadbeef") || strings.Contains(joined, "--cached") {
+			t.Fatalf("branch comparison should win over commit and staged, but ran: git %s", joined)
+		}
+	}
+}
+
+func TestBlobHandler(t *testing.T) {
+	dir, _ := fixtureRepo(t)
+	g := git.NewGitExtractor(dir)
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD:go.mod").Output()
+	if err != nil {
+		t.Fatalf("resolve go.mod blob: %v", err)
+	}
+	oid := strings.TrimSpace(string(out))
+
+	w := httptest.NewRecorder()
+	blobHandler(g)(w, httptest.NewRequest("GET", "/diff/blob/"+oid+"?path=go.mod", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "module fixture") {
+		t.Fatalf("unexpected blob response %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	blobHandler(g)(w, httptest.NewRequest("GET", "/diff/blob/HEAD", nil))
+	if w.Code != 404 {
+		t.Fatalf("expected 404 for a non-oid, got %d", w.Code)
 	}
 }
@@ -0,0 +1,436 @@
+// Package backup bundles the DiffLearn settings and data directory into one
+// archive for moving to another machine. Credentials from the settings file,
+// its profiles' included, are left out, or sealed with a passphrase when one
+// is given.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+// FormatVersion is the archive layout written by Export. Version 2 added
+// the profiles of ~/.difflearn, which version 1 readers would restore as
+// top-level settings.
+const FormatVersion = 2
+
+// DataFiles are the stores of the data directory that a backup carries:
+// learning progress, quiz scores, AI history and notes, the usage ledger
+// and team records. Server pid, log and discovery files are left behind.
+var DataFiles = []string{"learning.jsonl", "quiz-scores.jsonl", "ai-history.jsonl", "llm-usage.jsonl", "team.jsonl"}
+
+// DataDirs are directories of the data directory copied as a whole.
+var DataDirs = []string{"prompts"}
+
+const (
+	manifestName = "manifest.json"
+	settingsName = "settings"
+	secretsName  = "secrets.enc"
+	dataPrefix   = "data/"
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Files   []string  `json:"files"`
+	// Secrets is "excluded" or "encrypted"; SecretKeys names the settings,
+	// as "<profile>/<key>" for those of a profile.
+	Secrets    string   `json:"secrets"`
+	SecretKeys []string `json:"secretKeys,omitempty"`
+}
+
+// ErrPassphraseRequired is returned by Import when the archive carries
+// encrypted secrets and no passphrase was given.
+var ErrPassphraseRequired = errors.New("the backup holds encrypted secrets; a passphrase is required to restore them")
+
+// Export writes the archive to w. With a passphrase the secret settings are
+// included, encrypted; without one they are dropped.
+func Export(w io.Writer, passphrase string) (Manifest, error) {
+	m := Manifest{Version: FormatVersion, Created: time.Now().UTC(), Secrets: "excluded"}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: m.Created}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	add := func(name string, data []byte) error {
+		m.Files = append(m.Files, name)
+		return write(name, data)
+	}
+
+	settings, secrets := splitSettings(fileSettings())
+	m.SecretKeys = sortedKeys(secrets)
+	if len(settings) > 0 {
+		if err := add(settingsName, []byte(formatSettings(settings))); err != nil {
+			return m, err
+		}
+	}
+	if passphrase != "" && len(secrets) > 0 {
+		plain, err := json.Marshal(secrets)
+		if err != nil {
+			return m, err
+		}
+		sealed, err := seal(plain, passphrase)
+		if err != nil {
+			return m, err
+		}
+		if err := add(secretsName, sealed); err != nil {
+			return m, err
+		}
+		m.Secrets = "encrypted"
+	}
+
+	dataDir := config.DataDir()
+	for _, name := range DataFiles {
+		data, err := os.ReadFile(filepath.Join(dataDir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return m, err
+		}
+		if err := add(dataPrefix+name, data); err != nil {
+			return m, err
+		}
+	}
+	for _, dir := range DataDirs {
+		entries, err := os.ReadDir(filepath.Join(dataDir, dir))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return m, err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dataDir, dir, e.Name()))
+			if err != nil {
+				return m, err
+			}
+			if err := add(dataPrefix+dir+"/"+e.Name(), data); err != nil {
+				return m, err
+			}
+		}
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return m, err
+	}
+	if err := write(manifestName, append(manifest, '\n')); err != nil {
+		return m, err
+	}
+	if err := tw.Close(); err != nil {
+		return m, err
+	}
+	return m, gz.Close()
+}
+
+// ImportResult reports what Import changed.
+type ImportResult struct {
+	Manifest Manifest
+	// Settings are the keys written to ~/.difflearn, secrets included;
+	// those of a profile are named "<profile>/<key>".
+	Settings []string
+	// Restored are data files written or extended with new records.
+	Restored []string
+	// Skipped are files and settings kept because they already exist.
+	Skipped []string
+}
+
+// Import restores an archive written by Export. JSONL stores gain the
+// records they do not have yet, so importing twice changes nothing. Settings
+// and prompt templates that already exist are kept unless overwrite is set.
+func Import(r io.Reader, passphrase string, overwrite bool) (ImportResult, error) {
+	var res ImportResult
+	files, err := readArchive(r)
+	if err != nil {
+		return res, err
+	}
+	raw, ok := files[manifestName]
+	if !ok {
+		return res, fmt.Errorf("not a DiffLearn backup: %s is missing", manifestName)
+	}
+	if err := json.Unmarshal(raw, &res.Manifest); err != nil {
+		return res, fmt.Errorf("%s: %w", manifestName, err)
+	}
+	if res.Manifest.Version > FormatVersion {
+		return res, fmt.Errorf("backup format %d is newer than this DiffLearn understands (%d)", res.Manifest.Version, FormatVersion)
+	}
+
+	values := parseSettings(string(files[settingsName]))
+	if sealed, ok := files[secretsName]; ok {
+		if passphrase == "" {
+			return res, ErrPassphraseRequired
+		}
+		plain, err := open(sealed, passphrase)
+		if err != nil {
+			return res, err
+		}
+		var secrets map[string]string
+		if err := json.Unmarshal(plain, &secrets); err != nil {
+			return res, err
+		}
+		for k, v := range secrets {
+			values[k] = v
+		}
+	}
+	current := fileSettings()
+	pending := map[string]map[string]string{}
+	for _, k := range sortedKeys(values) {
+		if cur, exists := current[k]; exists {
+			if cur == values[k] {
+				continue
+			}
+			if !overwrite {
+				res.Skipped = append(res.Skipped, k)
+				continue
+			}
+		}
+		profile, key := splitKey(k)
+		if pending[profile] == nil {
+			pending[profile] = map[string]string{}
+		}
+		pending[profile][key] = values[k]
+		res.Settings = append(res.Settings, k)
+	}
+	for _, profile := range sortedKeys(pending) {
+		if err := config.SaveProfileSettings(profile, pending[profile]); err != nil {
+			return res, err
+		}
+	}
+
+	dataDir := config.DataDir()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rel, ok := strings.CutPrefix(name, dataPrefix)
+		if !ok {
+			continue
+		}
+		if !allowed(rel) {
+			return res, fmt.Errorf("unexpected file in backup: %s", name)
+		}
+		target := filepath.Join(dataDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return res, err
+		}
+		if strings.HasSuffix(rel, ".jsonl") {
+			added, err := mergeLines(target, files[name])
+			if err != nil {
+				return res, err
+			}
+			if added {
+				res.Restored = append(res.Restored, rel)
+			}
+			continue
+		}
+		if current, err := os.ReadFile(target); err == nil {
+			if bytes.Equal(current, files[name]) {
+				continue
+			}
+			if !overwrite {
+				res.Skipped = append(res.Skipped, rel)
+				continue
+			}
+		}
+		if err := os.WriteFile(target, files[name], 0o600); err != nil {
+			return res, err
+		}
+		res.Restored = append(res.Restored, rel)
+	}
+	return res, nil
+}
+
+// allowed keeps archive entries to the known stores, so a crafted archive
+// cannot write elsewhere.
+func allowed(rel string) bool {
+	for _, name := range DataFiles {
+		if rel == name {
+			return true
+		}
+	}
+	dir, file := path.Split(rel)
+	for _, d := range DataDirs {
+		if dir == d+"/" && file != "" && file != "." && file != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a DiffLearn backup: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[h.Name] = data
+	}
+}
+
+// mergeLines appends the lines of data that target does not have yet and
+// reports whether it added any.
+func mergeLines(target string, data []byte) (bool, error) {
+	existing, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(string(existing), "\n") {
+		seen[line] = true
+	}
+	var add bytes.Buffer
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if strings.TrimSpace(line) == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		add.WriteString(line + "\n")
+	}
+	if err := s.Err(); err != nil {
+		return false, err
+	}
+	if add.Len() == 0 {
+		return false, nil
+	}
+	f, err := os.OpenFile(target, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		if _, err := f.WriteString("\n"); err != nil {
+			return false, err
+		}
+	}
+	_, err = f.Write(add.Bytes())
+	return true, err
+}
+
+// fileSettings returns the settings of ~/.difflearn, with the keys of each
+// profile named "<profile>/<key>".
+func fileSettings() map[string]string {
+	all := config.FileSettings()
+	for profile, settings := range config.Profiles() {
+		for k, v := range settings {
+			all[profile+"/"+k] = v
+		}
+	}
+	return all
+}
+
+// splitKey separates a key of fileSettings into its profile, "" for the
+// top-level settings, and the setting. Setting names never hold a slash,
+// so profile names may.
+func splitKey(k string) (profile, key string) {
+	if i := strings.LastIndex(k, "/"); i >= 0 {
+		return k[:i], k[i+1:]
+	}
+	return "", k
+}
+
+func splitSettings(all map[string]string) (plain, secret map[string]string) {
+	plain, secret = map[string]string{}, map[string]string{}
+	for k, v := range all {
+		if _, key := splitKey(k); config.IsSecretSetting(key) {
+			secret[k] = v
+		} else {
+			plain[k] = v
+		}
+	}
+	return plain, secret
+}
+
+// formatSettings writes values the way ~/.difflearn lays them out: the
+// top-level keys, then a [profile <name>] section for each profile.
+func formatSettings(values map[string]string) string {
+	var b strings.Builder
+	section := ""
+	// Sorting by section first puts the top-level keys, section "", ahead.
+	keys := sortedKeys(values)
+	sort.SliceStable(keys, func(i, j int) bool {
+		pi, _ := splitKey(keys[i])
+		pj, _ := splitKey(keys[j])
+		return pi < pj
+	})
+	for _, k := range keys {
+		profile, key := splitKey(k)
+		if profile != section {
+			section = profile
+			b.WriteString("[profile " + profile + "]\n")
+		}
+		b.WriteString(key + "=" + values[k] + "\n")
+	}
+	return b.String()
+}
+
+// parseSettings reads formatSettings, naming the keys of a profile
+// "<profile>/<key>".
+func parseSettings(src string) map[string]string {
+	values := map[string]string{}
+	prefix := ""
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if inner, ok := strings.CutPrefix(line, "[profile "); ok && strings.HasSuffix(inner, "]") {
+			prefix = strings.TrimSpace(strings.TrimSuffix(inner, "]")) + "/"
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && strings.TrimSpace(k) != "" {
+			values[prefix+strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return values
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,196 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"difflearn-go/internal/config"
+)
+
+// machine points HOME and the data directory at fresh directories.
+func machine(t *testing.T) (home, data string) {
+	t.Helper()
+	home, data = t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("DIFFLEARN_DATA_DIR", data)
+	return home, data
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exportFrom(t *testing.T, passphrase string) ([]byte, Manifest) {
+	t.Helper()
+	home, data := machine(t)
+	writeFile(t, filepath.Join(home, ".difflearn"), "DIFFLEARN_LLM_PROVIDER=openai\nOPENAI_API_KEY=sk-secret\n")
+	writeFile(t, filepath.Join(data, "learning.jsonl"), "{\"kind\":\"explain\"}\n")
+	writeFile(t, filepath.Join(data, "prompts", "review.tmpl"), "{{.Default}}")
+	writeFile(t, filepath.Join(data, "web.log"), "not carried")
+	var buf bytes.Buffer
+	m, err := Export(&buf, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), m
+}
+
+func TestExportImportWithoutSecrets(t *testing.T) {
+	archive, m := exportFrom(t, "")
+	if m.Secrets != "excluded" || len(m.SecretKeys) != 1 || bytes.Contains(archive, []byte("sk-secret")) {
+		t.Fatalf("secrets must stay out of the archive: %+v", m)
+	}
+
+	home, data := machine(t)
+	writeFile(t, filepath.Join(data, "learning.jsonl"), "{\"kind\":\"review\"}\n")
+	res, err := Import(bytes.NewReader(archive), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, _ := os.ReadFile(filepath.Join(home, ".difflearn"))
+	if !bytes.Contains(settings, []byte("DIFFLEARN_LLM_PROVIDER=openai")) || bytes.Contains(settings, []byte("OPENAI_API_KEY")) {
+		t.Fatalf("unexpected settings after import:\n%s", settings)
+	}
+	learning, _ := os.ReadFile(filepath.Join(data, "learning.jsonl"))
+	if string(learning) != "{\"kind\":\"review\"}\n{\"kind\":\"explain\"}\n" {
+		t.Fatalf("learning records should be merged:\n%s", learning)
+	}
+	if _, err := os.Stat(filepath.Join(data, "prompts", "review.tmpl")); err != nil {
+		t.Fatal("prompt template not restored")
+	}
+	if _, err := os.Stat(filepath.Join(data, "web.log")); !os.IsNotExist(err) {
+		t.Fatal("server logs must not be carried over")
+	}
+	if len(res.Restored) != 2 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	// A second import finds everything in place.
+	res, err = Import(bytes.NewReader(archive), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Restored) != 0 || len(res.Settings) != 0 || len(res.Skipped) != 0 {
+		t.Fatalf("importing again should change nothing: %+v", res)
+	}
+
+	writeFile(t, filepath.Join(data, "prompts", "review.tmpl"), "mine")
+	if res, _ = Import(bytes.NewReader(archive), "", false); len(res.Skipped) != 1 {
+		t.Fatalf("a changed template should be kept: %+v", res)
+	}
+	if res, _ = Import(bytes.NewReader(archive), "", true); len(res.Restored) != 1 {
+		t.Fatalf("--force should replace the template: %+v", res)
+	}
+}
+
+func TestExportImportEncryptedSecrets(t *testing.T) {
+	archive, m := exportFrom(t, "correct horse")
+	if m.Secrets != "encrypted" || bytes.Contains(archive, []byte("sk-secret")) {
+		t.Fatalf("secrets should be encrypted: %+v", m)
+	}
+	machine(t)
+	if _, err := Import(bytes.NewReader(archive), "", false); !errors.Is(err, ErrPassphraseRequired) {
+		t.Fatalf("expected ErrPassphraseRequired, got %v", err)
+	}
+	if _, err := Import(bytes.NewReader(archive), "wrong", false); !errors.Is(err, ErrBadPassphrase) {
+		t.Fatalf("expected ErrBadPassphrase, got %v", err)
+	}
+	if _, err := Import(bytes.NewReader(archive), "correct horse", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := config.FileSettings()["OPENAI_API_KEY"]; got != "sk-secret" {
+		t.Fatalf("secret not restored, got %q", got)
+	}
+}
+
+func TestExportImportProfiles(t *testing.T) {
+	home, _ := machine(t)
+	writeFile(t, filepath.Join(home, ".difflearn"), "DIFFLEARN_LLM_PROVIDER=openai\n\n[profile work]\nDIFFLEARN_LLM_PROVIDER=claude\nANTHROPIC_API_KEY=sk-work\n")
+	var buf bytes.Buffer
+	m, err := Export(&buf, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.SecretKeys) != 1 || m.SecretKeys[0] != "work/ANTHROPIC_API_KEY" || bytes.Contains(buf.Bytes(), []byte("sk-work")) {
+		t.Fatalf("profile secrets should be sealed: %+v", m)
+	}
+
+	machine(t)
+	res, err := Import(bytes.NewReader(buf.Bytes()), "correct horse", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := config.Profiles()["work"]
+	if work["DIFFLEARN_LLM_PROVIDER"] != "claude" || work["ANTHROPIC_API_KEY"] != "sk-work" {
+		t.Fatalf("profile not restored: %v", work)
+	}
+	if got := config.FileSettings(); got["DIFFLEARN_LLM_PROVIDER"] != "openai" || got["ANTHROPIC_API_KEY"] != "" {
+		t.Fatalf("profile settings leaked into the top level: %v", got)
+	}
+	if len(res.Settings) != 3 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestImportKeepsStoresPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no Unix permissions")
+	}
+	archive, _ := exportFrom(t, "")
+	_, data := machine(t)
+	data = filepath.Join(data, "fresh")
+	t.Setenv("DIFFLEARN_DATA_DIR", data)
+	if _, err := Import(bytes.NewReader(archive), "", false); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]os.FileMode{
+		data:                                  0o700,
+		filepath.Join(data, "prompts"):        0o700,
+		filepath.Join(data, "learning.jsonl"): 0o600,
+		filepath.Join(data, "prompts", "review.tmpl"): 0o600,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s has mode %o, want %o", path, got, want)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	for rel, want := range map[string]bool{
+		"learning.jsonl":        true,
+		"prompts/review.tmpl":   true,
+		"../.bashrc":            false,
+		"prompts/../x":          false,
+		"servers/3000.json":     false,
+		"prompts/nested/a.tmpl": false,
+	} {
+		if got := allowed(rel); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestPBKDF2(t *testing.T) {
+	// RFC 7914 section 11, PBKDF2-HMAC-SHA256 with one iteration.
+	got := hex.EncodeToString(pbkdf2Key([]byte("passwd"), []byte("salt"), 1, 64))
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got != want {
+		t.Fatalf("pbkdf2Key = %s", got)
+	}
+}
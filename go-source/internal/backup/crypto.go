@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// sealMagic starts an encrypted blob: version 1 is PBKDF2-HMAC-SHA256 with
+// pbkdf2Iterations, then AES-256-GCM.
+const sealMagic = "DLSEC1"
+
+const (
+	pbkdf2Iterations = 600000
+	saltSize         = 16
+)
+
+// ErrBadPassphrase is returned when encrypted secrets do not open.
+var ErrBadPassphrase = errors.New("wrong passphrase or damaged backup")
+
+// seal encrypts plain as magic | salt | nonce | ciphertext.
+func seal(plain []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte(sealMagic), salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plain, []byte(sealMagic)), nil
+}
+
+func open(sealed []byte, passphrase string) ([]byte, error) {
+	if len(sealed) < len(sealMagic)+saltSize || string(sealed[:len(sealMagic)]) != sealMagic {
+		return nil, ErrBadPassphrase
+	}
+	rest := sealed[len(sealMagic):]
+	gcm, err := newGCM(passphrase, rest[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	rest = rest[saltSize:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrBadPassphrase
+	}
+	plain, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], []byte(sealMagic))
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return plain, nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2Key([]byte(passphrase), salt, pbkdf2Iterations, 32))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2Key is PBKDF2 with HMAC-SHA256 (RFC 8018); the module still targets
+// a Go release without crypto/pbkdf2.
+func pbkdf2Key(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
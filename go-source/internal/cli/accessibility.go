@@ -0,0 +1,44 @@
+package cli
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+
+	"difflearn-go/internal/git"
+)
+
+// accessibleMode is set by --accessible or DIFFLEARN_ACCESSIBLE. It switches
+// every terminal surface to plain, linear text for screen readers.
+var accessibleMode bool
+
+// syntaxHighlight is turned off by --no-syntax or DIFFLEARN_SYNTAX_HIGHLIGHT=false.
+var syntaxHighlight = true
+
+func setAccessible(on bool) {
+	accessibleMode = on
+	if on {
+		color.NoColor = true
+	}
+}
+
+func terminalOptions() git.FormatterOptions {
+	return git.FormatterOptions{Accessible: accessibleMode, SyntaxHighlight: syntaxHighlight}
+}
+
+// styled renders text with style unless accessible mode is on.
+func styled(style lipgloss.Style, text string) string {
+	if accessibleMode {
+		return text
+	}
+	return style.Render(text)
+}
+
+// programOptions keeps screen readers in the normal buffer, where earlier
+// announcements stay readable.
+func programOptions() []tea.ProgramOption {
+	if accessibleMode {
+		return nil
+	}
+	return []tea.ProgramOption{tea.WithAltScreen()}
+}
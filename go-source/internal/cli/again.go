@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/aihistory"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+func againCmd(repoPath *string) *cobra.Command {
+	var model string
+	var temperature float64
+	cmd := &cobra.Command{
+		Use:   "again",
+		Short: "Re-run the last AI command for this repository",
+		Long:  "Replay the most recent explain, review, summary or ask request with the same diff selection, optionally with a different model or temperature. The diff is loaded again, so edits made since then are included.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := git.NewGitExtractor(*repoPath)
+			last, err := aihistory.Open().Last(g.RepoPath())
+			if err != nil {
+				return err
+			}
+			opts := optionsFromSelection(last.Selection)
+			opts.Model = model
+			if cmd.Flags().Changed("temperature") {
+				opts.Temperature = &temperature
+			}
+			fmt.Println(color.HiBlackString(i18n.T("cli.again", last.Kind, describeRef(opts), last.Time.Local().Format("2006-01-02 15:04"))))
+			kind := last.Kind
+			switch kind {
+			case "explain-file":
+				kind = "explain"
+			case "explain-narrative":
+				kind, opts.Narrative = "explain", true
+			}
+			return runLLMCommand(*repoPath, kind, opts)
+		},
+	}
+	cmd.Flags().StringVar(&model, "model", "", "Model to use instead of the configured one (model, prefix or provider:model)")
+	cmd.Flags().Float64Var(&temperature, "temperature", 0, "Sampling temperature to use instead of the configured one")
+	return cmd
+}
+
+func withCommandOverrides(cfg config.Config, opts llmCommandOptions) (config.Config, error) {
+	if opts.Model != "" {
+		resolved, err := config.ResolveModelSpec(cfg, opts.Model)
+		if err != nil {
+			return config.Config{}, err
+		}
+		cfg = resolved
+	}
+	if opts.Temperature != nil {
+		cfg.Temperature = *opts.Temperature
+	}
+	return cfg, nil
+}
+
+// recordAIRequest saves an answered request for `again` and `sessions`.
+// Pull request and piped diffs are kept as sessions but never replayed,
+// since they cannot be loaded from the repository.
+func recordAIRequest(g git.Repository, cfg config.Config, kind string, opts llmCommandOptions, content string) {
+	_ = aihistory.Open().Append(aihistory.Entry{
+		Repo:        g.RepoPath(),
+		Kind:        kind,
+		Source:      "cli",
+		Ref:         describeRef(opts),
+		Selection:   historySelection(opts),
+		DiffHash:    opts.DiffHash,
+		Provider:    string(cfg.Provider),
+		Model:       cfg.Model,
+		Temperature: cfg.Temperature,
+		Prompt:      opts.Prompt,
+		Response:    content,
+		Preloaded:   opts.Preloaded != nil,
+	})
+}
+
+func historySelection(opts llmCommandOptions) aihistory.Selection {
+	return aihistory.Selection{
+		Staged:            opts.Staged,
+		File:              opts.File,
+		Stash:             opts.Stash,
+		Against:           opts.Against,
+		Untracked:         opts.Untracked,
+		RecurseSubmodules: opts.RecurseSubmodules,
+		Renames:           opts.Renames,
+		Whitespace:        opts.Whitespace,
+		Question:          opts.Question,
+		Structured:        opts.Structured,
+		Refine:            opts.Refine,
+		Ticket:            opts.Ticket,
+		Paths:             opts.Paths,
+	}
+}
+
+func optionsFromSelection(sel aihistory.Selection) llmCommandOptions {
+	return llmCommandOptions{
+		Staged:            sel.Staged,
+		File:              sel.File,
+		Stash:             sel.Stash,
+		Against:           sel.Against,
+		Untracked:         sel.Untracked,
+		RecurseSubmodules: sel.RecurseSubmodules,
+		Renames:           sel.Renames,
+		Whitespace:        sel.Whitespace,
+		Question:          sel.Question,
+		Structured:        sel.Structured,
+		Refine:            sel.Refine,
+		Ticket:            sel.Ticket,
+		Paths:             sel.Paths,
+	}
+}
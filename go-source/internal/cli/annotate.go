@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func annotateCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
+	cmd := &cobra.Command{
+		Use:   "annotate",
+		Short: "Show local changes with AI review comments attached to their lines",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnnotate(*repoPath, opts)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Annotate only staged changes")
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&opts.Untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().StringVar(&opts.File, "file", "", "Annotate changes to a single file")
+	addRenameFlags(cmd, &opts.Renames)
+	addWhitespaceFlags(cmd, &opts.Whitespace)
+	return cmd
+}
+
+func runAnnotate(repoPath string, opts llmCommandOptions) error {
+	cfg := config.LoadConfig()
+	formatter := git.NewDiffFormatter()
+	diffs, err := loadCommandDiffs(git.NewGitExtractor(repoPath), opts)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+		return nil
+	}
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		fmt.Println(llm.CreateAnnotationPrompt(diffs))
+		return nil
+	}
+	fmt.Println(color.HiBlackString(i18n.T("tui.status.annotating")))
+	result, err := llm.Annotate(llm.NewClient(cfg), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens))
+	if err != nil {
+		return err
+	}
+	if notice := result.Budget.Notice(); notice != "" {
+		fmt.Println(color.YellowString(notice) + "\n")
+	}
+	view := terminalOptions()
+	view.Annotations = result.Annotations
+	fmt.Println(formatter.ToTerminal(diffs, view))
+	if len(result.Dropped) > 0 {
+		fmt.Println(color.HiBlackString(i18n.T("cli.annotationsDropped", len(result.Dropped))))
+	}
+	return nil
+}
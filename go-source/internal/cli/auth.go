@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/i18n"
+)
+
+func authCmd() *cobra.Command {
+	var login, asJSON bool
+	cmd := &cobra.Command{
+		Use:   "auth [provider]",
+		Short: "Check and fix the sign-in of CLI providers (claude, codex, gemini, cursor)",
+		Long:  "Without arguments, shows for each CLI provider whether it is installed and signed in. With a provider it checks that one and, with --login or after confirming, runs its login command, then checks again. Providers whose CLI has no status command are reported as installed but unchecked.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				if login {
+					return fmt.Errorf("--login needs a provider, e.g. difflearn auth codex --login")
+				}
+				return printAuthStatuses(asJSON)
+			}
+			provider, ok := config.ParseCLIProvider(args[0])
+			if !ok {
+				return fmt.Errorf("unknown CLI provider %q (use gemini, claude, codex or cursor)", args[0])
+			}
+			// Not being signed in is an expected outcome, not a usage error.
+			cmd.SilenceUsage = true
+			return runAuth(provider, login, asJSON)
+		},
+	}
+	cmd.Flags().BoolVar(&login, "login", false, "Run the provider's login command without asking")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the status as JSON")
+	return cmd
+}
+
+func printAuthStatuses(asJSON bool) error {
+	statuses := make([]config.CLIAuthStatus, 0, len(config.CLIProviders()))
+	for _, p := range config.CLIProviders() {
+		statuses = append(statuses, config.CheckCLIAuth(p))
+	}
+	if asJSON {
+		b, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	active := config.LoadConfig().Provider
+	for _, s := range statuses {
+		printAuthStatus(s, s.Provider == active)
+	}
+	return nil
+}
+
+func runAuth(provider config.LLMProvider, login, asJSON bool) error {
+	status := config.CheckCLIAuth(provider)
+	if asJSON {
+		b, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return authError(status)
+	}
+	printAuthStatus(status, false)
+	if status.State == config.AuthNotInstalled || status.State == config.AuthSignedIn && !login {
+		return authError(status)
+	}
+	if !login && !confirm(i18n.T("cli.auth.confirmLogin", provider)) {
+		return authError(status)
+	}
+
+	command := config.GetCLIAuthCommand(provider)
+	if hint := config.GetCLIAuthHint(provider); len(hint) > 0 {
+		fmt.Println(color.CyanString(i18n.T("cli.auth.sessionHint", strings.Join(hint, ", "))))
+	}
+	fmt.Println(color.HiBlackString("$ " + strings.Join(command, " ")))
+	c := exec.Command(command[0], command[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s: %w", strings.Join(command, " "), err)
+	}
+
+	status = config.CheckCLIAuth(provider)
+	fmt.Println()
+	printAuthStatus(status, false)
+	return authError(status)
+}
+
+func printAuthStatus(s config.CLIAuthStatus, active bool) {
+	name := string(s.Provider)
+	if active {
+		name += " " + i18n.T("cli.auth.active")
+	}
+	switch s.State {
+	case config.AuthSignedIn:
+		fmt.Printf("%s %s — %s\n", color.GreenString("✓"), name, i18n.T("cli.auth.signedIn"))
+	case config.AuthSignedOut:
+		fmt.Printf("%s %s — %s\n", color.RedString("✗"), name, i18n.T("cli.auth.signedOut"))
+		fmt.Println("  " + color.HiBlackString(i18n.T("cli.auth.fixLogin", authAlias(s.Provider))))
+	case config.AuthNotInstalled:
+		fmt.Printf("%s %s — %s\n", color.HiBlackString("-"), name, i18n.T("cli.auth.notInstalled", s.Command))
+	default:
+		fmt.Printf("%s %s — %s\n", color.YellowString("?"), name, i18n.T("cli.auth.unknown"))
+		fmt.Println("  " + color.HiBlackString(i18n.T("cli.auth.fixLogin", authAlias(s.Provider))))
+	}
+}
+
+// authError makes a provider that cannot be used yet fail the command, so
+// scripts can check the exit status.
+func authError(s config.CLIAuthStatus) error {
+	if s.State == config.AuthNotInstalled || s.State == config.AuthSignedOut {
+		return fmt.Errorf("%s", i18n.T("cli.auth.notReady", s.Provider))
+	}
+	return nil
+}
+
+// authAlias is the short provider name shown in suggested commands.
+func authAlias(p config.LLMProvider) string {
+	return strings.TrimSuffix(strings.TrimSuffix(string(p), "-cli"), "-code")
+}
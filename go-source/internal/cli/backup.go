@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"difflearn-go/internal/backup"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/i18n"
+)
+
+func backupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Export or import DiffLearn settings and data for another machine",
+		Long:  "Bundles ~/.difflearn, your prompt templates, learning progress and quiz scores, AI history and notes, the usage ledger and team records into one .tar.gz archive. API keys, tokens, passwords and webhooks are left out unless --encrypt seals them with a passphrase, read from DIFFLEARN_BACKUP_PASSPHRASE or asked for in the terminal.",
+	}
+	cmd.AddCommand(backupExportCmd(), backupImportCmd())
+	return cmd
+}
+
+func backupExportCmd() *cobra.Command {
+	var encrypt bool
+	cmd := &cobra.Command{
+		Use:   "export [file|-]",
+		Short: "Write the backup archive (difflearn-backup-<date>.tar.gz by default, - for stdout)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			path := "difflearn-backup-" + time.Now().Format("20060102") + ".tar.gz"
+			if len(args) == 1 {
+				path = args[0]
+			}
+			passphrase := ""
+			if encrypt {
+				var err error
+				if passphrase, err = readPassphrase(true); err != nil {
+					return err
+				}
+			}
+			var out io.Writer = os.Stdout
+			if path != "-" {
+				// The archive may hold encrypted keys and personal notes.
+				f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+			m, err := backup.Export(out, passphrase)
+			if err != nil {
+				return err
+			}
+			if path == "-" {
+				return nil
+			}
+			fmt.Println(color.GreenString(i18n.T("cli.backup.exported", path, len(m.Files))))
+			if len(m.SecretKeys) > 0 && m.Secrets == "excluded" {
+				fmt.Println(color.YellowString(i18n.T("cli.backup.secretsExcluded", strings.Join(m.SecretKeys, ", "))))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&encrypt, "encrypt", false, "Include API keys and tokens, encrypted with a passphrase")
+	return cmd
+}
+
+func backupImportCmd() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "import <file|->",
+		Short: "Restore a backup archive",
+		Long:  "Restores settings, prompt templates and data from an archive made by backup export. History, progress and other records are merged, so importing twice changes nothing; settings and templates you already have are kept unless --force is given.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			var archive []byte
+			var err error
+			if args[0] == "-" {
+				archive, err = io.ReadAll(os.Stdin)
+			} else {
+				archive, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			passphrase := config.Setting("DIFFLEARN_BACKUP_PASSPHRASE")
+			res, err := backup.Import(bytes.NewReader(archive), passphrase, force)
+			if errors.Is(err, backup.ErrPassphraseRequired) && args[0] != "-" && term.IsTerminal(int(os.Stdin.Fd())) {
+				if passphrase, err = readPassphrase(false); err != nil {
+					return err
+				}
+				res, err = backup.Import(bytes.NewReader(archive), passphrase, force)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Println(color.GreenString(i18n.T("cli.backup.imported", res.Manifest.Created.Local().Format("2006-01-02 15:04"))))
+			for _, k := range res.Settings {
+				fmt.Printf("  %s → %s\n", k, config.FilePath())
+			}
+			for _, f := range res.Restored {
+				fmt.Println("  " + f)
+			}
+			for _, s := range res.Skipped {
+				fmt.Println(color.HiBlackString("  " + i18n.T("cli.backup.skipped", s)))
+			}
+			if res.Manifest.Secrets == "excluded" && len(res.Manifest.SecretKeys) > 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.backup.secretsMissing", strings.Join(res.Manifest.SecretKeys, ", "))))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Replace settings and prompt templates that already exist")
+	return cmd
+}
+
+// readPassphrase takes DIFFLEARN_BACKUP_PASSPHRASE or asks for one without
+// echoing it, twice when confirm is set.
+func readPassphrase(confirm bool) (string, error) {
+	if p := config.Setting("DIFFLEARN_BACKUP_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("%s", i18n.T("cli.backup.noPassphrase"))
+	}
+	ask := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+	p, err := ask(i18n.T("cli.backup.passphrase"))
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", fmt.Errorf("%s", i18n.T("cli.backup.noPassphrase"))
+	}
+	if confirm {
+		again, err := ask(i18n.T("cli.backup.passphraseAgain"))
+		if err != nil {
+			return "", err
+		}
+		if again != p {
+			return "", fmt.Errorf("%s", i18n.T("cli.backup.mismatch"))
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+)
+
+func benchCmd(repoPath *string) *cobra.Command {
+	var opts benchOptions
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Time diff extraction, parsing and formatting",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBench(*repoPath, opts)
+		},
+	}
+	cmd.Flags().IntVarP(&opts.Iterations, "iterations", "n", 5, "Runs per stage")
+	cmd.Flags().StringVar(&opts.Commit, "commit", "HEAD", "Commit whose diff is benchmarked alongside local changes")
+	cmd.Flags().IntVar(&opts.SyntheticFiles, "synthetic-files", 500, "Files in the synthetic diff (0 to skip it)")
+	cmd.Flags().StringVar(&opts.CPUProfile, "cpuprofile", "", "Write a CPU profile to this file")
+	cmd.Flags().StringVar(&opts.MemProfile, "memprofile", "", "Write a heap profile to this file")
+	return cmd
+}
+
+type benchOptions struct {
+	Iterations     int
+	Commit         string
+	SyntheticFiles int
+	CPUProfile     string
+	MemProfile     string
+}
+
+type benchResult struct {
+	Name  string
+	Bytes int
+	Runs  []time.Duration
+}
+
+func runBench(repoPath string, opts benchOptions) error {
+	if opts.Iterations < 1 {
+		opts.Iterations = 1
+	}
+	if opts.CPUProfile != "" {
+		f, err := os.Create(opts.CPUProfile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	g := git.NewGitExtractor(repoPath)
+	if !g.IsRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	inputs := []struct {
+		name string
+		load func() (string, error)
+	}{
+		{"local", func() (string, error) { return g.GetRawDiff("local", nil) }},
+		{"commit " + opts.Commit, func() (string, error) {
+			return g.GetRawDiff("commit", map[string]string{"commit1": opts.Commit})
+		}},
+	}
+
+	var results []benchResult
+	for _, in := range inputs {
+		var raw string
+		extract := benchResult{Name: "extract " + in.name}
+		for i := 0; i < opts.Iterations; i++ {
+			start := time.Now()
+			out, err := in.load()
+			if err != nil {
+				return fmt.Errorf("%s: %w", in.name, err)
+			}
+			extract.Runs = append(extract.Runs, time.Since(start))
+			raw = out
+		}
+		extract.Bytes = len(raw)
+		results = append(results, extract)
+		results = append(results, benchRaw(in.name, raw, opts.Iterations)...)
+	}
+	if opts.SyntheticFiles > 0 {
+		raw := git.SyntheticDiff(opts.SyntheticFiles, 4, 40)
+		results = append(results, benchRaw(fmt.Sprintf("synthetic %d files", opts.SyntheticFiles), raw, opts.Iterations)...)
+	}
+
+	printBenchResults(results, opts.Iterations)
+
+	if opts.MemProfile != "" {
+		f, err := os.Create(opts.MemProfile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// benchRaw times parsing and each formatter on an already extracted diff.
+func benchRaw(name, raw string, iterations int) []benchResult {
+	parser := git.NewDiffParser()
+	formatter := git.NewDiffFormatter()
+	var diffs []git.ParsedDiff
+	stages := []struct {
+		label string
+		run   func()
+	}{
+		{"parse", func() { diffs = parser.Parse(raw) }},
+		{"terminal", func() { formatter.ToTerminal(diffs, git.FormatterOptions{}) }},
+		{"markdown", func() { formatter.ToMarkdown(diffs) }},
+		{"json", func() { formatter.ToJSON(diffs) }},
+	}
+	results := make([]benchResult, 0, len(stages))
+	for _, st := range stages {
+		r := benchResult{Name: st.label + " " + name, Bytes: len(raw)}
+		for i := 0; i < iterations; i++ {
+			start := time.Now()
+			st.run()
+			r.Runs = append(r.Runs, time.Since(start))
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func printBenchResults(results []benchResult, iterations int) {
+	fmt.Println(color.New(color.Bold).Sprintf("%-36s %10s %10s %10s %10s", fmt.Sprintf("stage (%d runs)", iterations), "min", "median", "max", "MB/s"))
+	for _, r := range results {
+		runs := append([]time.Duration(nil), r.Runs...)
+		sort.Slice(runs, func(i, j int) bool { return runs[i] < runs[j] })
+		median := runs[len(runs)/2]
+		throughput := "-"
+		if r.Bytes > 0 && median > 0 {
+			throughput = fmt.Sprintf("%.1f", float64(r.Bytes)/median.Seconds()/1e6)
+		}
+		fmt.Printf("%-36s %10s %10s %10s %10s\n", r.Name, roundDuration(runs[0]), roundDuration(median), roundDuration(runs[len(runs)-1]), throughput)
+	}
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d > time.Second:
+		return d.Round(time.Millisecond)
+	case d > time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+type changelogEntry struct {
+	Hash        string        `json:"hash"`
+	Scope       string        `json:"scope,omitempty"`
+	Description string        `json:"description"`
+	Author      string        `json:"author"`
+	Stats       git.DiffStats `json:"stats"`
+	// Notes is the AI description of a notable change.
+	Notes string `json:"notes,omitempty"`
+}
+
+type changelogSection struct {
+	Type    string           `json:"type"`
+	Title   string           `json:"title"`
+	Entries []changelogEntry `json:"entries"`
+}
+
+type changelogReport struct {
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Title    string             `json:"title"`
+	Date     string             `json:"date"`
+	Sections []changelogSection `json:"sections"`
+}
+
+// minorChangelogTypes are never picked as notable changes.
+var minorChangelogTypes = map[string]bool{"docs": true, "test": true, "style": true, "ci": true, "chore": true, "build": true}
+
+func changelogCmd(repoPath *string) *cobra.Command {
+	var format, out, title string
+	var noAI bool
+	var notable int
+	cmd := &cobra.Command{
+		Use:   "changelog <from> [<to>]",
+		Short: "Write a changelog for the commits between two refs, grouped by conventional-commit type",
+		Long:  "Groups the commits in from..to (to defaults to HEAD) by their conventional-commit type (feat, fix, perf, ...) with breaking changes first and everything else under Other changes. When an LLM is configured, the largest changes get a short description written from their diff. The Markdown output can be pasted into CHANGELOG.md or release notes.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("invalid --format %q (use markdown or json)", format)
+			}
+			to := "HEAD"
+			if len(args) == 2 {
+				to = args[1]
+			}
+			report, err := buildChangelog(*repoPath, args[0], to, title, notable, noAI)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				return writeReport(b, out)
+			}
+			return writeReport([]byte(changelogMarkdown(report)), out)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown or json")
+	cmd.Flags().StringVarP(&out, "output", "o", "", "Write the changelog to a file instead of stdout")
+	cmd.Flags().StringVar(&title, "title", "", "Heading of the release (default: <to>, or Unreleased for HEAD)")
+	cmd.Flags().IntVar(&notable, "notable", 5, "How many of the largest changes get an AI description")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Only group the commits, without AI descriptions")
+	return cmd
+}
+
+// buildChangelog groups the commits in from..to and, unless noAI or no LLM
+// is configured, describes the notable ones. Progress goes to stderr so
+// stdout stays the changelog.
+func buildChangelog(repoPath, from, to, title string, notable int, noAI bool) (changelogReport, error) {
+	g := git.NewGitExtractor(repoPath)
+	rangeSpec := from + ".." + to
+	all, err := g.GetCommitsWithBodies(rangeSpec)
+	if err != nil {
+		return changelogReport{}, err
+	}
+	commits := make([]git.CommitInfo, 0, len(all))
+	for _, c := range all {
+		// Merge commits repeat the changes of the commits they bring in.
+		if len(c.Files) == 0 && strings.HasPrefix(c.Message, "Merge ") {
+			continue
+		}
+		commits = append(commits, c)
+	}
+	if title == "" {
+		title = to
+		if to == "HEAD" {
+			title = i18n.T("cli.changelog.unreleased")
+		}
+	}
+	report := changelogReport{From: from, To: to, Title: title, Sections: []changelogSection{}}
+	if len(commits) == 0 {
+		return report, nil
+	}
+	if t, err := time.Parse(time.RFC3339, commits[0].Date); err == nil {
+		report.Date = t.Format("2006-01-02")
+	}
+
+	parser := git.NewDiffParser()
+	type ref struct{ section, entry int }
+	var candidates []ref
+	for si, group := range git.GroupCommitsByType(commits) {
+		section := changelogSection{Type: group.Type, Title: i18n.T("cli.changelog.section." + group.Type)}
+		for _, c := range group.Commits {
+			cc := git.ParseConventionalCommit(c.Message, c.Body)
+			diffs, err := g.GetCommitDiff(c.Hash, "")
+			if err != nil {
+				return changelogReport{}, err
+			}
+			section.Entries = append(section.Entries, changelogEntry{
+				Hash: c.Hash, Scope: cc.Scope, Description: cc.Description, Author: c.Author, Stats: parser.GetStats(diffs),
+			})
+			if !minorChangelogTypes[cc.Type] || cc.Breaking {
+				candidates = append(candidates, ref{si, len(section.Entries) - 1})
+			}
+		}
+		report.Sections = append(report.Sections, section)
+	}
+	if noAI || notable <= 0 || len(candidates) == 0 {
+		return report, nil
+	}
+	cfg := config.LoadConfig()
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.noLLMOffline")))
+		return report, nil
+	}
+
+	size := func(r ref) int {
+		s := report.Sections[r.section].Entries[r.entry].Stats
+		return s.Additions + s.Deletions
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return size(candidates[i]) > size(candidates[j]) })
+	if len(candidates) > notable {
+		candidates = candidates[:notable]
+	}
+	formatter := git.NewDiffFormatter()
+	client := llm.NewClient(cfg).For("", "summary")
+	byHash := map[string]git.CommitInfo{}
+	for _, c := range commits {
+		byHash[c.Hash] = c
+	}
+	for _, r := range candidates {
+		entry := &report.Sections[r.section].Entries[r.entry]
+		commit := byHash[entry.Hash]
+		diffs, err := g.GetCommitDiff(entry.Hash, "")
+		if err != nil {
+			return changelogReport{}, err
+		}
+		fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.exportGenerating", short(entry.Hash, 7))))
+		build := func(d []git.ParsedDiff) string { return llm.CreateChangelogEntryPrompt(formatter, commit, d) }
+		resp, _, err := llm.RunBudgeted(client, formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), build)
+		if err != nil {
+			return changelogReport{}, err
+		}
+		entry.Notes = strings.TrimSpace(resp.Content)
+	}
+	return report, nil
+}
+
+func changelogMarkdown(report changelogReport) string {
+	var sb strings.Builder
+	sb.WriteString("## " + report.Title)
+	if report.Date != "" {
+		sb.WriteString(" (" + report.Date + ")")
+	}
+	sb.WriteString("\n\n")
+	if len(report.Sections) == 0 {
+		sb.WriteString(i18n.T("cli.noCommitsInRange", report.From+".."+report.To) + "\n")
+	}
+	for _, s := range report.Sections {
+		sb.WriteString("### " + s.Title + "\n\n")
+		for _, e := range s.Entries {
+			writeChangelogEntry(&sb, e)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func writeChangelogEntry(sb *strings.Builder, e changelogEntry) {
+	sb.WriteString("- ")
+	if e.Scope != "" {
+		sb.WriteString("**" + e.Scope + ":** ")
+	}
+	sb.WriteString(fmt.Sprintf("%s (%s)\n", e.Description, short(e.Hash, 7)))
+	if e.Notes != "" {
+		sb.WriteString("  " + strings.ReplaceAll(e.Notes, "\n", "\n  ") + "\n")
+	}
+}
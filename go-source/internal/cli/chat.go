@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/aihistory"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+	"difflearn-go/internal/llm"
+)
+
+func chatCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
+	var file string
+	cmd := &cobra.Command{
+		Use:   "chat",
+		Short: "Talk about local changes with follow-up questions",
+		Long:  "Opens a chat about the local changes. Every question sees the earlier questions and answers, so follow-ups like \"why?\" work, and the diff is loaded again for each question so the conversation follows your edits. Slash commands switch the diff mid-conversation: /staged, /local, /against <ref>, /file <path>, plus /context, /reset and /quit; /help lists them.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file != "" {
+				opts.Paths = []string{file}
+			}
+			return runChat(*repoPath, opts)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Talk about staged changes only")
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&opts.Untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().StringVar(&file, "file", "", "Limit the conversation to changes in a single file")
+	cmd.Flags().StringVar(&opts.Model, "model", "", "Model to use instead of the configured one (model, prefix or provider:model)")
+	return cmd
+}
+
+func runChat(repoPath string, opts llmCommandOptions) error {
+	cfg, err := withCommandOverrides(config.LoadConfig(), opts)
+	if err != nil {
+		return err
+	}
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		return nil
+	}
+	s := &chatSession{
+		g:         git.NewGitExtractor(repoPath),
+		formatter: git.NewDiffFormatter(),
+		cfg:       cfg,
+		client:    llm.NewClient(cfg).For("", "ask"),
+		budget:    llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens),
+		opts:      opts,
+	}
+	if diffs, err := loadCommandDiffs(s.g, opts); err != nil {
+		return err
+	} else if len(diffs) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+		return nil
+	}
+
+	fmt.Println(color.HiBlackString(i18n.T("cli.chat.intro", s.describe())))
+	if isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		// No alternate screen: answers stay in the terminal's scrollback.
+		_, err := tea.NewProgram(chatModel{session: s}).Run()
+		return err
+	}
+	return runChatLines(os.Stdin, os.Stdout, s)
+}
+
+// chatSession is the state of one chat: the diff selection the slash
+// commands change and the conversation so far.
+type chatSession struct {
+	g         *git.GitExtractor
+	formatter *git.DiffFormatter
+	cfg       config.Config
+	client    llm.Chatter
+	budget    llm.TokenBudget
+	opts      llmCommandOptions
+	conv      llm.Conversation
+}
+
+func (s *chatSession) describe() string {
+	if len(s.opts.Paths) > 0 {
+		return strings.Join(s.opts.Paths, ", ") + " @ " + describeRef(s.opts)
+	}
+	return describeRef(s.opts)
+}
+
+// ask answers question about the current diff and returns the rendered
+// answer.
+func (s *chatSession) ask(question string) (string, error) {
+	diffs, err := loadCommandDiffs(s.g, s.opts)
+	if err != nil {
+		return "", err
+	}
+	if len(diffs) == 0 {
+		return color.YellowString(i18n.T("cli.noChanges")), nil
+	}
+	resp, report, err := s.conv.Ask(s.client, s.formatter, diffs, s.budget, question)
+	if err != nil {
+		return "", err
+	}
+	turn := s.opts
+	turn.Question = question
+	turn.DiffHash = aihistory.HashDiffs(diffs)
+	recordAIRequest(s.g, s.cfg, "ask", turn, resp.Content)
+	learning.Record(s.g.RepoPath(), "ask", "")
+
+	out := renderMarkdown(resp.Content)
+	if notice := report.Notice(); notice != "" {
+		out = color.YellowString(notice) + "\n" + out
+	}
+	return out, nil
+}
+
+// command runs a slash command and returns what to show; quit is set by
+// /quit and /exit.
+func (s *chatSession) command(line string) (reply string, quit bool) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "/quit", "/exit", "/q":
+		return "", true
+	case "/help", "/?":
+		return i18n.T("cli.chat.commands"), false
+	case "/reset":
+		s.conv.Reset()
+		return i18n.T("cli.chat.reset"), false
+	case "/context":
+		return s.contextSummary(), false
+	case "/staged":
+		s.opts.Staged, s.opts.Against = true, ""
+	case "/local":
+		s.opts.Staged, s.opts.Against = false, ""
+	case "/against":
+		if arg == "" {
+			return i18n.T("cli.chat.usage", "/against <ref>"), false
+		}
+		if _, err := s.g.ResolveRef(arg); err != nil {
+			return color.RedString(err.Error()), false
+		}
+		s.opts.Staged, s.opts.Against = false, arg
+	case "/file":
+		// Without a path the whole diff is back in context.
+		s.opts.Paths = nil
+		if arg != "" {
+			s.opts.Paths = []string{arg}
+		}
+	default:
+		return color.YellowString(i18n.T("cli.chat.unknown", name)), false
+	}
+	return s.contextSummary(), false
+}
+
+// contextSummary names the current selection and its changed files.
+func (s *chatSession) contextSummary() string {
+	diffs, err := loadCommandDiffs(s.g, s.opts)
+	if err != nil {
+		return color.RedString(err.Error())
+	}
+	var b strings.Builder
+	b.WriteString(i18n.T("cli.chat.context", s.describe(), len(diffs)))
+	for _, d := range diffs {
+		fmt.Fprintf(&b, "\n  %s %s %s", d.NewFile, color.GreenString("+%d", d.Additions), color.RedString("-%d", d.Deletions))
+	}
+	if len(diffs) == 0 {
+		b.WriteString("\n" + color.YellowString(i18n.T("cli.noChanges")))
+	}
+	return b.String()
+}
+
+// runChatLines is the chat for pipes and scripts: one question or slash
+// command per line, until an empty line or the end of input.
+func runChatLines(in io.Reader, out io.Writer, s *chatSession) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Fprint(out, color.CyanString("› "))
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			return nil
+		}
+		if strings.HasPrefix(line, "/") {
+			reply, quit := s.command(line)
+			if quit {
+				return nil
+			}
+			fmt.Fprintln(out, reply)
+			continue
+		}
+		answer, err := s.ask(line)
+		if err != nil {
+			fmt.Fprintln(out, color.RedString(err.Error()))
+			continue
+		}
+		fmt.Fprintln(out, answer)
+	}
+}
+
+type chatAnswerMsg struct {
+	answer string
+	err    error
+}
+
+// chatModel is the terminal chat: an input line under the transcript, which
+// is printed into the scrollback as it grows.
+type chatModel struct {
+	session *chatSession
+	input   string
+	waiting bool
+}
+
+func (m chatModel) Init() tea.Cmd { return nil }
+
+func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case chatAnswerMsg:
+		m.waiting = false
+		if msg.err != nil {
+			return m, tea.Println(color.RedString(msg.err.Error()))
+		}
+		return m, tea.Println(msg.answer)
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc || (msg.Type == tea.KeyCtrlD && m.input == "") {
+			return m, tea.Quit
+		}
+		if m.waiting {
+			return m, nil
+		}
+		switch msg.Type {
+		case tea.KeyRunes, tea.KeySpace:
+			m.input += string(msg.Runes)
+		case tea.KeyBackspace:
+			if r := []rune(m.input); len(r) > 0 {
+				m.input = string(r[:len(r)-1])
+			}
+		case tea.KeyCtrlU:
+			m.input = ""
+		case tea.KeyEnter:
+			line := strings.TrimSpace(m.input)
+			m.input = ""
+			if line == "" {
+				return m, nil
+			}
+			echo := tea.Println(color.CyanString("› ") + line)
+			if strings.HasPrefix(line, "/") {
+				reply, quit := m.session.command(line)
+				if quit {
+					return m, tea.Sequence(echo, tea.Quit)
+				}
+				return m, tea.Sequence(echo, tea.Println(reply))
+			}
+			m.waiting = true
+			s := m.session
+			return m, tea.Batch(echo, func() tea.Msg {
+				answer, err := s.ask(line)
+				return chatAnswerMsg{answer: answer, err: err}
+			})
+		}
+	}
+	return m, nil
+}
+
+func (m chatModel) View() string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	if m.waiting {
+		return styled(dim, i18n.T("cli.chat.thinking"))
+	}
+	cursor := "█"
+	if accessibleMode {
+		cursor = ""
+	}
+	return "› " + m.input + cursor + "\n" + styled(dim, i18n.T("cli.chat.help", m.session.describe()))
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+// ciReport is what `ci` emits as JSON.
+type ciReport struct {
+	Range       string             `json:"range"`
+	Passed      bool               `json:"passed"`
+	FailOn      analysis.Severity  `json:"failOn"`
+	Blocking    int                `json:"blocking"`
+	FailedGates []string           `json:"failedGates"`
+	Stats       git.DiffStats      `json:"stats"`
+	Findings    []analysis.Finding `json:"findings"`
+	Review      *llm.ReviewResult  `json:"review,omitempty"`
+	Model       string             `json:"model,omitempty"`
+	Notice      string             `json:"notice,omitempty"`
+}
+
+func ciCmd(repoPath *string) *cobra.Command {
+	var format, failOn, out string
+	var noAI bool
+	cmd := &cobra.Command{
+		Use:   "ci [<ref1>..<ref2>]",
+		Short: "Review a revision range non-interactively, with JSON or SARIF output and a severity-based exit code",
+		Long:  "Runs the static pre-checks and, when an LLM is configured, a structured AI review of the range, then prints a report. Without a range it reviews origin/<target>...HEAD using GITHUB_BASE_REF or CI_MERGE_REQUEST_TARGET_BRANCH_NAME, falling back to @{upstream}...HEAD. Gating criteria from the .difflearn.yaml rubric fail when the review tags them with a critical issue. Exit codes: 0 passed, 1 blocked by --fail-on or a gate, 2 error.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			threshold, ok := analysis.ParseSeverity(failOn)
+			if !ok {
+				return &exitError{code: 2, err: fmt.Errorf("invalid --fail-on %q (use critical, important or minor)", failOn)}
+			}
+			if format != "json" && format != "sarif" && format != "text" {
+				return &exitError{code: 2, err: fmt.Errorf("invalid --format %q (use json, sarif or text)", format)}
+			}
+			rangeSpec := ciRange()
+			if len(args) == 1 {
+				rangeSpec = args[0]
+			}
+			report, err := runCI(*repoPath, rangeSpec, threshold, noAI)
+			if err != nil {
+				return &exitError{code: 2, err: err}
+			}
+			if err := writeCIReport(report, format, out, cmd.Root().Version); err != nil {
+				return &exitError{code: 2, err: err}
+			}
+			if !report.Passed {
+				return &exitError{code: 1, err: errors.New(ciFailure(report))}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "Output format: json, sarif or text")
+	cmd.Flags().StringVar(&failOn, "fail-on", string(analysis.SeverityCritical), "Lowest severity that fails the run: critical, important or minor")
+	cmd.Flags().StringVarP(&out, "output", "o", "", "Write the report to a file instead of stdout")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Only run the static pre-checks")
+	return cmd
+}
+
+// ciRange picks the range to review when none is given: the pull or merge
+// request's target branch on GitHub Actions and GitLab CI, else the upstream.
+func ciRange() string {
+	for _, env := range []string{"GITHUB_BASE_REF", "CI_MERGE_REQUEST_TARGET_BRANCH_NAME"} {
+		if branch := os.Getenv(env); branch != "" {
+			return "origin/" + branch + "...HEAD"
+		}
+	}
+	return "@{upstream}...HEAD"
+}
+
+func runCI(repoPath, rangeSpec string, threshold analysis.Severity, noAI bool) (ciReport, error) {
+	diffs, err := git.NewGitExtractor(repoPath).GetRangeDiff(rangeSpec)
+	if err != nil {
+		return ciReport{}, err
+	}
+	report := ciReport{
+		Range:       rangeSpec,
+		FailOn:      threshold,
+		FailedGates: []string{},
+		Stats:       git.NewDiffParser().GetStats(diffs),
+		Findings:    analysis.RunRules(diffs),
+	}
+	cfg := config.LoadConfig()
+	if !noAI && len(diffs) > 0 && config.IsLLMAvailable(cfg) {
+		result, gates, notice, err := quietStructuredReview(repoPath, cfg, diffs, report.Findings, "")
+		if err != nil {
+			return ciReport{}, err
+		}
+		report.Review = &result
+		report.Model = cfg.Model
+		report.Notice = notice
+		report.FailedGates = gates
+	}
+	report.Blocking = countBlocking(report.Findings, report.Review, threshold)
+	report.Passed = report.Blocking == 0 && len(report.FailedGates) == 0
+	return report, nil
+}
+
+// quietStructuredReview runs a structured review without printing anything,
+// for output that must stay machine-readable. It also returns the rubric
+// gates the review failed and any budget notice. An answer that is not
+// valid JSON is an error, since it cannot be judged.
+func quietStructuredReview(repoPath string, cfg config.Config, diffs []git.ParsedDiff, findings []analysis.Finding, ticket string) (llm.ReviewResult, []string, string, error) {
+	repoCfg, err := config.LoadRepoConfig(repoPath)
+	if err != nil {
+		return llm.ReviewResult{}, nil, "", err
+	}
+	formatter := git.NewDiffFormatter()
+	rc := llm.ReviewContext{Findings: findings, Rubric: repoCfg.Rubric, Structured: true, Ticket: ticket}
+	build := func(d []git.ParsedDiff) string { return llm.CreateReviewPromptWithContext(formatter, d, rc) }
+	resp, budget, err := llm.RunBudgeted(llm.NewClient(cfg), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), build)
+	if err != nil {
+		return llm.ReviewResult{}, nil, "", err
+	}
+	result, err := llm.ParseReviewResult(resp.Content)
+	if err != nil {
+		return llm.ReviewResult{}, nil, "", err
+	}
+	return result, llm.FailedStructuredGates(result, repoCfg.Rubric), budget.Notice(), nil
+}
+
+func writeCIReport(report ciReport, format, out, version string) error {
+	var b []byte
+	var err error
+	switch format {
+	case "sarif":
+		b, err = llm.ToSARIF(report.Findings, report.Review, version)
+	case "text":
+		b = []byte(formatCIText(report))
+	default:
+		b, err = json.MarshalIndent(report, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	return writeReport(b, out)
+}
+
+// writeReport prints a machine-readable report to stdout, or writes it to
+// out as is, without the front matter --out adds to answers.
+func writeReport(b []byte, out string) error {
+	b = append(b, '\n')
+	if out == "" {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(out, b, 0o644)
+}
+
+// formatCIText is plain text for build logs, which rarely render color.
+func formatCIText(report ciReport) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %d file(s) changed, +%d -%d\n\n", report.Range, report.Stats.Files, report.Stats.Additions, report.Stats.Deletions))
+	if len(report.Findings) > 0 {
+		sb.WriteString(i18n.T("cli.findings") + "\n")
+		for _, f := range report.Findings {
+			sb.WriteString("  " + f.String() + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	if report.Review != nil {
+		sb.WriteString(llm.FormatReviewMarkdown(*report.Review) + "\n\n")
+	}
+	if report.Notice != "" {
+		sb.WriteString(report.Notice + "\n")
+	}
+	if report.Passed {
+		sb.WriteString(i18n.T("cli.ciPassed", report.FailOn))
+	} else {
+		sb.WriteString(ciFailure(report))
+	}
+	return sb.String()
+}
+
+func ciFailure(report ciReport) string {
+	reasons := make([]string, 0, len(report.FailedGates)+1)
+	if report.Blocking > 0 {
+		reasons = append(reasons, i18n.T("cli.reviewBlocked", report.Blocking, report.FailOn))
+	}
+	for _, name := range report.FailedGates {
+		reasons = append(reasons, i18n.T("cli.gateFailed", name))
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func resolveCompareModels(cfg config.Config, specs []string) ([]config.Config, error) {
+	if len(specs) < 2 {
+		return nil, fmt.Errorf("--compare-models needs at least two models, e.g. gpt-4o,claude-sonnet")
+	}
+	cfgs := make([]config.Config, 0, len(specs))
+	for _, spec := range specs {
+		resolved, err := config.ResolveModelSpec(cfg, spec)
+		if err != nil {
+			return nil, err
+		}
+		cfgs = append(cfgs, resolved)
+	}
+	return cfgs, nil
+}
+
+func runModelComparison(cfgs []config.Config, label, prompt string) error {
+	names := make([]string, 0, len(cfgs))
+	for _, c := range cfgs {
+		names = append(names, c.Model)
+	}
+	fmt.Printf("%s\n\n", color.GreenString("📝 "+i18n.T("cli.label.comparing", label, strings.Join(names, " vs "))+":"))
+
+	answers := llm.CompareModels(cfgs, []llm.ChatMessage{{Role: "system", Content: llm.SystemPrompt}, {Role: "user", Content: prompt}})
+	fmt.Println(renderSideBySide(answers, terminalWidth()))
+	return nil
+}
+
+func renderSideBySide(answers []llm.ModelAnswer, width int) string {
+	if len(answers) == 0 {
+		return ""
+	}
+	if accessibleMode {
+		return renderSequential(answers)
+	}
+	gap := 2
+	colWidth := (width - gap*(len(answers)-1)) / len(answers)
+	if colWidth < 20 {
+		colWidth = 20
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	failed := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	meta := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	column := lipgloss.NewStyle().Width(colWidth)
+
+	columns := make([]string, 0, len(answers)*2)
+	for i, a := range answers {
+		body := a.Content
+		if a.Error != "" {
+			body = failed.Render("Error: " + a.Error)
+		}
+		header := title.Render(fmt.Sprintf("%s (%s)", a.Model, a.Provider)) + "\n" + meta.Render(fmt.Sprintf("%.1fs", float64(a.DurationMs)/1000)) + "\n" + strings.Repeat("─", colWidth)
+		columns = append(columns, column.Render(header+"\n"+body))
+		if i < len(answers)-1 {
+			columns = append(columns, strings.Repeat(" ", gap))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+func renderSequential(answers []llm.ModelAnswer) string {
+	parts := make([]string, 0, len(answers))
+	for i, a := range answers {
+		body := a.Content
+		if a.Error != "" {
+			body = "Error: " + a.Error
+		}
+		parts = append(parts, fmt.Sprintf("%s\n%s\n\n%s", i18n.T("a11y.answer", i+1, len(answers), a.Model, a.Provider, float64(a.DurationMs)/1000), body, i18n.T("a11y.answerEnd", a.Model)))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func terminalWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	return 120
+}
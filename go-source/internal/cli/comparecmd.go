@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+func compareCmd(repoPath *string) *cobra.Command {
+	var explain, review, summary bool
+	var whitespace git.WhitespaceOptions
+	var opts llmCommandOptions
+	cmd := &cobra.Command{
+		Use:   "compare <pathA> <pathB>",
+		Short: "View, explain or review the differences between two files or directories, in or out of a repository",
+		Long:  "Compares with git diff --no-index, so the paths (relative to the current directory) do not have to be in a repository. Directories are compared file by file.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			diffs, err := git.NewGitExtractor(".").DiffPaths(args[0], args[1], git.DiffOptions{Whitespace: whitespace})
+			if err != nil {
+				return err
+			}
+			if len(diffs) == 0 {
+				fmt.Println(color.GreenString(i18n.T("cli.compareIdentical", args[0], args[1])))
+				return nil
+			}
+			opts.Preloaded = diffs
+			opts.Ref = args[0] + " " + args[1]
+			switch {
+			case explain:
+				return runLLMCommand(*repoPath, "explain", opts)
+			case review:
+				return runLLMCommand(*repoPath, "review", opts)
+			case summary:
+				return runLLMCommand(*repoPath, "summary", opts)
+			}
+			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&explain, "explain", false, "Get an AI explanation of the differences")
+	cmd.Flags().BoolVar(&review, "review", false, "Get an AI review of the second path as a change to the first")
+	cmd.Flags().BoolVar(&summary, "summary", false, "Get a quick summary of the differences")
+	addWhitespaceFlags(cmd, &whitespace)
+	addOutputFlags(cmd, &opts)
+	return cmd
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/i18n"
+)
+
+// settingValue returns the value in effect for k and where it comes from:
+// the active profile, the environment, ~/.difflearn or nowhere. Settings
+// loaded from the file into the environment count as the file's.
+func settingValue(k config.SettingKey, file map[string]string) (string, string) {
+	if v, ok := config.Profiles()[config.ActiveProfile()][k.Name]; ok {
+		return v, "profile"
+	}
+	fileValue, inFile := file[k.Name]
+	if env := os.Getenv(k.Name); env != "" && (!inFile || env != fileValue) {
+		return env, "env"
+	}
+	if inFile {
+		return fileValue, "file"
+	}
+	return "", ""
+}
+
+func shownValue(k config.SettingKey, value string, reveal bool) string {
+	if k.Secret() && !reveal {
+		return config.MaskSecret(value)
+	}
+	return value
+}
+
+// profileToEdit is the profile --profile names explicitly; config set and
+// unset then edit its section instead of the keys before the first one.
+func profileToEdit(cmd *cobra.Command) string {
+	if f := cmd.Flag("profile"); f != nil && f.Changed {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// settingSaved reports a change to ~/.difflearn and warns when something
+// with precedence hides it.
+func settingSaved(k config.SettingKey, profile, message, fileBefore string) {
+	fmt.Println(color.GreenString(message))
+	if profile != "" {
+		return
+	}
+	if active := config.ActiveProfile(); active != "" {
+		if _, ok := config.Profiles()[active][k.Name]; ok {
+			fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.configSet.overriddenByProfile", active, k.Alias)))
+			return
+		}
+	}
+	if env := os.Getenv(k.Name); env != "" && env != fileBefore {
+		fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.configSet.overridden", k.Name)))
+	}
+}
+
+func configSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Save a setting to ~/.difflearn",
+		Long:  "Saves a setting to ~/.difflearn, keeping its comments and other lines. Keys are the names listed by `difflearn config keys` (provider, model, level, ...) or the environment variables they stand for (DIFFLEARN_LLM_PROVIDER, ...). Values are checked before anything is written. With --profile the setting goes into that profile, which is created when missing.",
+		Example: "  difflearn config set provider anthropic\n" +
+			"  difflearn config set temperature 0.2\n" +
+			"  difflearn config set OPENAI_API_KEY sk-...\n" +
+			"  difflearn config set --profile home provider ollama",
+		Args:        cobra.ExactArgs(2),
+		Annotations: map[string]string{"newProfile": "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			profile := profileToEdit(cmd)
+			before := config.FileSettings()
+			k, err := config.SetSetting(profile, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if profile != "" {
+				value := config.Profiles()[profile][k.Name]
+				settingSaved(k, profile, i18n.T("cli.configSet.savedProfile", k.Alias, shownValue(k, value, false), profile, config.FilePath()), "")
+				return nil
+			}
+			value := config.FileSettings()[k.Name]
+			was := before[k.Name]
+			if os.Getenv(k.Name) == value {
+				was = value
+			}
+			settingSaved(k, profile, i18n.T("cli.configSet.saved", k.Alias, shownValue(k, value, false), config.FilePath()), was)
+			return nil
+		},
+	}
+}
+
+func configGetCmd() *cobra.Command {
+	var reveal bool
+	cmd := &cobra.Command{
+		Use:   "get [key]",
+		Short: "Print a setting, or every setting that is set and where it comes from",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			file := config.FileSettings()
+			if len(args) == 1 {
+				k, err := config.LookupSettingKey(args[0])
+				if err != nil {
+					return err
+				}
+				value, _ := settingValue(k, file)
+				if value == "" {
+					return fmt.Errorf("%s is not set", k.Alias)
+				}
+				fmt.Println(shownValue(k, value, reveal))
+				return nil
+			}
+			width := 0
+			for _, k := range config.SettingKeys {
+				width = max(width, len(k.Alias))
+			}
+			shown := 0
+			for _, k := range config.SettingKeys {
+				value, source := settingValue(k, file)
+				if value == "" {
+					continue
+				}
+				shown++
+				from := config.FilePath()
+				switch source {
+				case "env":
+					from = i18n.T("cli.configSet.fromEnv", k.Name)
+				case "profile":
+					from = i18n.T("cli.configSet.profileIn", config.ActiveProfile(), config.FilePath())
+				}
+				fmt.Printf("%-*s  %s  %s\n", width, k.Alias, shownValue(k, value, reveal), color.HiBlackString(from))
+			}
+			if shown == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.configSet.none")))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&reveal, "reveal", false, "Print API keys, tokens and passwords instead of masking them")
+	return cmd
+}
+
+func configUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a setting from ~/.difflearn",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			profile := profileToEdit(cmd)
+			before := config.FileSettings()
+			k, removed, err := config.UnsetSetting(profile, args[0])
+			if err != nil {
+				return err
+			}
+			where := config.FilePath()
+			if profile != "" {
+				where = i18n.T("cli.configSet.profileIn", profile, config.FilePath())
+			}
+			if !removed {
+				fmt.Println(color.YellowString(i18n.T("cli.configSet.notInFile", k.Alias, where)))
+				return nil
+			}
+			settingSaved(k, profile, i18n.T("cli.configSet.removed", k.Alias, where), before[k.Name])
+			return nil
+		},
+	}
+}
+
+func configKeysCmd() *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "List the settings config set accepts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jsonOut {
+				return printJSON(config.SettingKeys)
+			}
+			width := 0
+			for _, k := range config.SettingKeys {
+				width = max(width, len(k.Alias))
+			}
+			for _, k := range config.SettingKeys {
+				help := k.Help
+				if len(k.Values) > 0 {
+					help += " (" + strings.Join(k.Values, ", ") + ")"
+				}
+				fmt.Printf("%-*s  %s  %s\n", width, k.Alias, help, color.HiBlackString(k.Name))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the keys as JSON")
+	return cmd
+}
+
+func configProfilesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "profiles",
+		Short: "List the profiles of ~/.difflearn and the provider and model each picks",
+		Long:  "Profiles are [profile <name>] sections of ~/.difflearn holding settings that replace the others while the profile is in use. Pick one with --profile, DIFFLEARN_PROFILE or profile: in a repository's .difflearn.yaml.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles := config.Profiles()
+			if len(profiles) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.configSet.noProfiles")))
+				return nil
+			}
+			active := config.ActiveProfile()
+			for _, name := range config.ProfileNames() {
+				marker := "  "
+				if name == active {
+					marker = color.GreenString("* ")
+				}
+				settings := profiles[name]
+				summary := strings.TrimSpace(settings["DIFFLEARN_LLM_PROVIDER"] + " " + settings["DIFFLEARN_MODEL"])
+				fmt.Printf("%s%s  %s\n", marker, name, color.HiBlackString(i18n.T("cli.configSet.profileSummary", summary, len(settings))))
+			}
+			return nil
+		},
+	}
+}
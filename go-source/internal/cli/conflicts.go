@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func conflictsCmd(repoPath *string) *cobra.Command {
+	var noAI, write, yes bool
+	cmd := &cobra.Command{
+		Use:   "conflicts [path...]",
+		Short: "Explain merge conflicts and propose resolutions",
+		Long:  "Finds the files with merge conflicts (or takes paths), shows each conflict's ours, base and theirs versions side by side and asks the LLM to explain it and propose a resolution. The base is read from diff3 markers or, without them, recovered from the index. With --write the proposed resolutions replace the conflict markers once you confirm; check the result and git add the file yourself.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if write && noAI {
+				return fmt.Errorf("--write needs the AI resolution; drop --no-ai")
+			}
+			g := git.NewGitExtractor(*repoPath)
+			paths := args
+			if len(paths) == 0 {
+				var err error
+				if paths, err = g.GetConflictedFiles(); err != nil {
+					return err
+				}
+			}
+			if len(paths) == 0 {
+				fmt.Println(color.GreenString(i18n.T("cli.conflicts.none")))
+				return nil
+			}
+			cfg := config.LoadConfig()
+			for _, path := range paths {
+				if err := runConflictFile(g, cfg, path, noAI, write, yes); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Only show the conflicts side by side")
+	cmd.Flags().BoolVar(&write, "write", false, "Write the proposed resolutions into the file after confirmation")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Write without asking for confirmation (with --write)")
+	return cmd
+}
+
+func runConflictFile(g *git.GitExtractor, cfg config.Config, path string, noAI, write, yes bool) error {
+	f, err := g.LoadConflictedFile(path)
+	if err != nil {
+		return err
+	}
+	if len(f.Conflicts) == 0 {
+		fmt.Println(color.GreenString(i18n.T("cli.conflicts.noMarkers", path)) + "\n")
+		return nil
+	}
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.conflicts.header", path, len(f.Conflicts))) + "\n")
+	for i, c := range f.Conflicts {
+		fmt.Println(color.CyanString(i18n.T("cli.conflicts.at", i+1, c.Line)))
+		fmt.Println(renderConflict(c, terminalWidth()) + "\n")
+	}
+	if noAI {
+		return nil
+	}
+	prompt := llm.CreateConflictPrompt(f)
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		fmt.Println(prompt)
+		return nil
+	}
+	answer, err := streamLLMResponse(llm.NewClient(cfg), cfg, git.NewDiffFormatter(), nil, i18n.T("cli.label.conflicts", path), func([]git.ParsedDiff) string { return prompt })
+	if err != nil || !write {
+		return err
+	}
+	fmt.Println()
+	resolutions, err := llm.ParseConflictResolutions(answer, len(f.Conflicts))
+	if err != nil {
+		return err
+	}
+	resolved, err := f.Resolve(resolutions)
+	if err != nil {
+		return err
+	}
+	if !yes && !confirm(i18n.T("cli.conflicts.confirm", path)) {
+		fmt.Println(color.YellowString(i18n.T("cli.conflicts.skipped", path)))
+		return nil
+	}
+	full := filepath.Join(g.RepoPath(), path)
+	info, err := os.Stat(full)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(full, []byte(resolved), info.Mode().Perm()); err != nil {
+		return err
+	}
+	fmt.Println(color.GreenString(i18n.T("cli.conflicts.written", path, path)) + "\n")
+	return nil
+}
+
+// renderConflict shows the sides of a conflict in columns, or one after the
+// other in accessible mode.
+func renderConflict(c git.Conflict, width int) string {
+	type side struct{ title, body string }
+	sides := []side{{i18n.T("cli.conflicts.ours", c.OursLabel), c.Ours}}
+	if c.HasBase {
+		sides = append(sides, side{i18n.T("cli.conflicts.base"), c.Base})
+	}
+	sides = append(sides, side{i18n.T("cli.conflicts.theirs", c.TheirsLabel), c.Theirs})
+	if accessibleMode {
+		parts := make([]string, 0, len(sides))
+		for _, s := range sides {
+			parts = append(parts, s.title+":\n"+strings.TrimRight(s.body, "\n"))
+		}
+		return strings.Join(parts, "\n\n")
+	}
+	gap := 2
+	colWidth := (width - gap*(len(sides)-1)) / len(sides)
+	if colWidth < 20 {
+		colWidth = 20
+	}
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	column := lipgloss.NewStyle().Width(colWidth)
+	columns := make([]string, 0, len(sides)*2)
+	for i, s := range sides {
+		header := title.Render(s.title) + "\n" + strings.Repeat("─", colWidth)
+		columns = append(columns, column.Render(header+"\n"+strings.TrimRight(s.body, "\n")))
+		if i < len(sides)-1 {
+			columns = append(columns, strings.Repeat(" ", gap))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// stdin is shared by every prompt: a reader per prompt would buffer away
+// the piped answers meant for the next ones.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on stdin; anything but y or yes is no.
+func confirm(question string) bool {
+	fmt.Print(question + " [y/N] ")
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
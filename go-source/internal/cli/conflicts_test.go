@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+func TestConflictsWrite(t *testing.T) {
+	answer := "Both sides change x; keep the larger value.\n\n### Resolution 1\n\n```go\nx := 3\n```\n"
+	cfg := fakeLLM(t, answer)
+	repo := t.TempDir()
+	if err := exec.Command("git", "init", "-q", repo).Run(); err != nil {
+		t.Skip("git is not available")
+	}
+	conflicted := "a\n<<<<<<< ours\nx := 1\n=======\nx := 2\n>>>>>>> theirs\nb\n"
+	for _, name := range []string{"one.go", "two.go"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(conflicted), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Piped answers for both files: the second prompt must still see its own.
+	old := stdin
+	stdin = bufio.NewReader(strings.NewReader("y\nn\n"))
+	t.Cleanup(func() { stdin = old })
+
+	g := git.NewGitExtractor(repo)
+	for _, name := range []string{"one.go", "two.go"} {
+		if err := runConflictFile(g, cfg, name, false, true, false); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if b, _ := os.ReadFile(filepath.Join(repo, "one.go")); string(b) != "a\nx := 3\nb\n" {
+		t.Fatalf("one.go was not resolved:\n%s", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(repo, "two.go")); string(b) != conflicted {
+		t.Fatalf("two.go was written without confirmation:\n%s", b)
+	}
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+	"difflearn-go/internal/llm"
+)
+
+func exerciseCmd(repoPath *string) *cobra.Command {
+	var dir, check string
+	var keep bool
+	cmd := &cobra.Command{
+		Use:   "exercise <sha>",
+		Short: "Re-implement a commit yourself from its message and AI-written requirements, then compare",
+		Long:  "Checks out the commit's parent into a temporary git worktree and shows only the commit message and requirements the model writes from the diff (offline, the files it touches). Make the change in the worktree, then press Enter: DiffLearn diffs your attempt against the real commit and the model explains the differences. When stdin is not a terminal, or to stop and come back later, check the attempt with --check <dir>. The worktree is removed after the check unless --keep is given.",
+		Example: "  difflearn exercise 3f2a9c1\n" +
+			"  difflearn exercise 3f2a9c1 --check /tmp/difflearn-exercise-3f2a9c1",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			g := git.NewGitExtractor(*repoPath)
+			sha, err := g.ResolveRef(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := g.ResolveRef(sha + "^"); err != nil {
+				return fmt.Errorf("%s is a root commit: there is no parent to start the exercise from", short(sha, 7))
+			}
+			commits, err := g.GetCommitsWithBodies(sha + "^!")
+			if err != nil || len(commits) == 0 {
+				return fmt.Errorf("cannot read commit %s: %v", short(sha, 7), err)
+			}
+			commit := commits[0]
+			message := strings.TrimSpace(commit.Message + "\n\n" + commit.Body)
+			if check != "" {
+				return checkExercise(g, sha, message, check, keep)
+			}
+			return startExercise(g, sha, message, dir, keep)
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "", "Where to create the worktree (default a new temporary directory)")
+	cmd.Flags().StringVar(&check, "check", "", "Compare the attempt in this worktree with the commit")
+	cmd.Flags().BoolVar(&keep, "keep", false, "Keep the worktree after checking the attempt")
+	return cmd
+}
+
+func startExercise(g *git.GitExtractor, sha, message, dir string, keep bool) error {
+	commitDiffs, err := g.GetCommitDiff(sha, "")
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "difflearn-exercise-"+short(sha, 7)+"-"); err != nil {
+			return err
+		}
+	}
+	if err := g.AddWorktree(dir, sha+"^"); err != nil {
+		os.Remove(dir)
+		return err
+	}
+
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.exercise.header", short(sha, 7))))
+	fmt.Println()
+	fmt.Println(renderMarkdown("> " + strings.ReplaceAll(message, "\n", "\n> ")))
+	fmt.Println()
+	cfg := config.LoadConfig()
+	formatter := git.NewDiffFormatter()
+	if config.IsLLMAvailable(cfg) {
+		build := func(d []git.ParsedDiff) string {
+			return llm.CreateExerciseRequirementsPrompt(formatter, message, d)
+		}
+		if _, err := streamLLMResponse(llm.NewClient(cfg).For("", "exercise"), cfg, formatter, commitDiffs, i18n.T("cli.label.requirements"), build); err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString(err.Error()))
+		}
+	} else {
+		fmt.Println(color.YellowString(i18n.T("cli.exercise.offline")))
+		for _, d := range commitDiffs {
+			name := d.NewFile
+			if d.IsDeleted {
+				name = d.OldFile
+			}
+			fmt.Printf("  %s %s\n", name, color.HiBlackString("+%d -%d", d.Additions, d.Deletions))
+		}
+	}
+	fmt.Println()
+	fmt.Println(color.CyanString(i18n.T("cli.exercise.workIn", dir)))
+
+	if !isTerminal(os.Stdin) {
+		fmt.Println(i18n.T("cli.exercise.checkLater", short(sha, 7), dir))
+		return nil
+	}
+	fmt.Print(i18n.T("cli.exercise.pressEnter"))
+	if _, err := stdin.ReadString('\n'); err != nil {
+		fmt.Println()
+		fmt.Println(i18n.T("cli.exercise.checkLater", short(sha, 7), dir))
+		return nil
+	}
+	return checkExercise(g, sha, message, dir, keep)
+}
+
+// checkExercise diffs the attempt in dir against the real commit and has
+// the model explain the differences.
+func checkExercise(g *git.GitExtractor, sha, message, dir string, keep bool) error {
+	attempt := git.NewGitExtractor(dir)
+	if err := attempt.IntendToAdd(); err != nil {
+		return err
+	}
+	changed, err := attempt.GetLocalDiff(git.DiffOptions{Against: "HEAD"})
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.exercise.nothingYet", short(sha, 7), dir)))
+		return nil
+	}
+	difference, err := attempt.GetLocalDiff(git.DiffOptions{Against: sha})
+	if err != nil {
+		return err
+	}
+	learning.Record(g.RepoPath(), learning.KindExercise, sha)
+
+	if len(difference) == 0 {
+		fmt.Println(color.GreenString(i18n.T("cli.exercise.identical")))
+	} else {
+		fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.exercise.differences", len(difference))))
+		fmt.Println(color.HiBlackString(i18n.T("cli.exercise.legend")))
+		fmt.Println()
+		formatter := git.NewDiffFormatter()
+		fmt.Println(formatter.ToTerminal(difference, terminalOptions()))
+		cfg := config.LoadConfig()
+		if config.IsLLMAvailable(cfg) {
+			commitDiffs, err := g.GetCommitDiff(sha, "")
+			if err != nil {
+				return err
+			}
+			build := func(d []git.ParsedDiff) string {
+				return llm.CreateExerciseFeedbackPrompt(formatter, message, commitDiffs, d)
+			}
+			if _, err := streamLLMResponse(llm.NewClient(cfg).For("", "exercise"), cfg, formatter, difference, i18n.T("cli.label.exerciseFeedback"), build); err != nil {
+				return err
+			}
+		} else {
+			fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		}
+	}
+
+	if keep {
+		fmt.Println(color.HiBlackString(i18n.T("cli.exercise.kept", dir)))
+		return nil
+	}
+	return g.RemoveWorktree(dir)
+}
@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"golang.org/x/term"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/ansisvg"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+// aiSections are the kinds --with-ai accepts, in report order.
+var aiSections = []string{"summary", "explain", "review"}
+
+type reportSection struct {
+	Kind    string `json:"kind"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+func exportCmd(repoPath *string) *cobra.Command {
+	var staged, againstDefault, card bool
+	var format, ticketArg string
+	var withAI []string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export diff in various formats",
+		Long:  "With --with-ai the export becomes a change report: AI summary, explanation and review sections (or the ones listed, e.g. --with-ai=summary,review) come before the diff, ready to paste into a pull request description. Without an LLM the offline analysis fills the sections. --against-default exports the current branch's changes since it forked from the default branch instead of the working tree, which is what a pull request would show. --format svg and png draw the colored terminal diff, or with --card a summary of the change, as a picture of a terminal window for slides, docs and posts; png needs rsvg-convert, resvg, ImageMagick or Inkscape.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "png" && term.IsTerminal(int(os.Stdout.Fd())) {
+				return fmt.Errorf("not writing a PNG to the terminal; redirect it to a file, e.g. difflearn export --format png > diff.png")
+			}
+			g := git.NewGitExtractor(*repoPath)
+			formatter := git.NewDiffFormatter()
+			var base string
+			var diffs []git.ParsedDiff
+			var err error
+			if againstDefault {
+				if base, err = resolveBase(g, ""); err != nil {
+					return err
+				}
+				diffs, err = g.GetBranchDiff(base, "HEAD")
+			} else {
+				diffs, err = g.GetLocalDiff(git.DiffOptions{Staged: staged})
+			}
+			if err != nil {
+				return err
+			}
+			var sections []reportSection
+			if len(withAI) > 0 && len(diffs) > 0 {
+				opts := llmCommandOptions{Staged: staged}
+				if againstDefault {
+					opts.Preloaded, opts.Ref = diffs, base+"...HEAD"
+				}
+				if err := loadTicket(ticketArg, &opts); err != nil {
+					return err
+				}
+				sections, err = generateReportSections(*repoPath, withAI, opts, formatter, diffs)
+				if err != nil {
+					return err
+				}
+			}
+			switch format {
+			case "json":
+				if sections == nil {
+					fmt.Println(formatter.ToJSON(diffs))
+					return nil
+				}
+				b, err := json.MarshalIndent(map[string]any{"sections": sections, "diffs": diffs}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+			case "terminal":
+				for _, s := range sections {
+					fmt.Printf("%s\n\n%s\n\n", color.GreenString("📝 "+s.Title+":"), renderMarkdown(s.Content))
+				}
+				fmt.Println(formatter.ToTerminal(diffs, terminalOptions()))
+			case "patch":
+				kind := "local"
+				if staged {
+					kind = "staged"
+				}
+				if againstDefault {
+					kind = "branch"
+				}
+				raw, err := g.GetRawDiff(kind, map[string]string{"binary": "true", "branch1": base, "branch2": "HEAD"})
+				if err != nil {
+					return err
+				}
+				// git apply skips the sections as text before the first diff.
+				fmt.Print(sectionsMarkdown(sections) + raw)
+			case "svg", "png":
+				title := "working tree"
+				switch {
+				case againstDefault:
+					title = base + "...HEAD"
+				case staged:
+					title = "staged changes"
+				}
+				image, err := renderImage(format, title, sections, diffs, card)
+				if err != nil {
+					return err
+				}
+				_, err = os.Stdout.Write(image)
+				return err
+			case "html":
+				prelude, err := sectionsHTML(sections)
+				if err != nil {
+					return err
+				}
+				fmt.Println(formatter.ToHTMLReport(diffs, prelude))
+			default:
+				fmt.Println(sectionsMarkdown(sections) + formatter.ToMarkdown(diffs))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&format, "format", "f", "markdown", "Output format: json, markdown, terminal, html, patch, svg, png")
+	cmd.Flags().BoolVar(&card, "card", false, "With svg or png, draw a summary of the change instead of the whole diff")
+	cmd.Flags().BoolVarP(&staged, "staged", "s", false, "Export only staged changes")
+	cmd.Flags().BoolVar(&againstDefault, "against-default", false, "Export the current branch's changes against the default branch")
+	cmd.Flags().StringSliceVar(&withAI, "with-ai", nil, "Add AI sections before the diff: summary, explain, review (all when given without a value)")
+	cmd.Flags().Lookup("with-ai").NoOptDefVal = strings.Join(aiSections, ",")
+	addTicketFlag(cmd, &ticketArg)
+	return cmd
+}
+
+// generateReportSections asks for each requested kind in report order,
+// printing progress to stderr so stdout stays the report.
+func generateReportSections(repoPath string, kinds []string, opts llmCommandOptions, formatter *git.DiffFormatter, diffs []git.ParsedDiff) ([]reportSection, error) {
+	wanted := map[string]bool{}
+	for _, k := range kinds {
+		k = strings.TrimSpace(k)
+		if !slices.Contains(aiSections, k) {
+			return nil, fmt.Errorf("unknown --with-ai section %q (use %s)", k, strings.Join(aiSections, ", "))
+		}
+		wanted[k] = true
+	}
+	cfg := config.LoadConfig()
+	online := config.IsLLMAvailable(cfg)
+	if !online {
+		fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.noLLMOffline")))
+	}
+	sections := make([]reportSection, 0, len(wanted))
+	for _, kind := range aiSections {
+		if !wanted[kind] {
+			continue
+		}
+		build, label, _, err := promptFor(repoPath, kind, opts, formatter, diffs)
+		if err != nil {
+			return nil, err
+		}
+		if !online {
+			sections = append(sections, reportSection{Kind: kind, Title: label, Content: offlineSection(kind, diffs)})
+			continue
+		}
+		fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.exportGenerating", label)))
+		resp, report, err := llm.RunBudgeted(llm.NewClient(cfg), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), build)
+		if err != nil {
+			return nil, err
+		}
+		if notice := report.Notice(); notice != "" {
+			fmt.Fprintln(os.Stderr, color.YellowString(notice))
+		}
+		sections = append(sections, reportSection{Kind: kind, Title: label, Content: strings.TrimSpace(resp.Content)})
+	}
+	return sections, nil
+}
+
+// renderImage draws the report as a terminal would show it, colors
+// included even when stdout is not a terminal, and returns it as SVG or
+// PNG.
+func renderImage(format, title string, sections []reportSection, diffs []git.ParsedDiff, card bool) ([]byte, error) {
+	prev := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = prev }()
+	formatter := git.NewDiffFormatter()
+	var parts []string
+	for _, s := range sections {
+		parts = append(parts, color.GreenString("📝 "+s.Title+":")+"\n\n"+imageMarkdown(s.Content))
+	}
+	if card {
+		parts = append(parts, formatter.ToSummaryCard(title, diffs))
+	} else {
+		view := terminalOptions()
+		view.Accessible = false
+		parts = append(parts, formatter.ToTerminal(diffs, view))
+	}
+	svg := ansisvg.Render(strings.Join(parts, "\n\n"), ansisvg.Options{Title: "DiffLearn · " + title})
+	if format == "svg" {
+		return []byte(svg), nil
+	}
+	return ansisvg.PNG(svg)
+}
+
+// imageMarkdown renders markdown for an image: the dark style, since
+// images have a dark background, wrapped to a width that fits a slide.
+func imageMarkdown(text string) string {
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle("dark"), glamour.WithWordWrap(100))
+	if err != nil {
+		return text
+	}
+	out, err := r.Render(text)
+	if err != nil {
+		return text
+	}
+	return strings.Trim(out, "\n")
+}
+
+func offlineSection(kind string, diffs []git.ParsedDiff) string {
+	switch kind {
+	case "summary":
+		return analysis.OfflineSummary(diffs)
+	case "explain":
+		return analysis.OfflineExplanation(diffs)
+	}
+	findings := analysis.RunRules(diffs)
+	if len(findings) == 0 {
+		return i18n.T("cli.noFindings")
+	}
+	return analysis.FormatFindings(findings)
+}
+
+func sectionsMarkdown(sections []reportSection) string {
+	var sb strings.Builder
+	for _, s := range sections {
+		sb.WriteString("## " + s.Title + "\n\n" + s.Content + "\n\n")
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("---\n\n")
+	}
+	return sb.String()
+}
+
+func sectionsHTML(sections []reportSection) (string, error) {
+	if len(sections) == 0 {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	if err := md.Convert([]byte(sectionsMarkdown(sections)), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+func fileCmd(repoPath *string) *cobra.Command {
+	var number int
+	var noInteractive bool
+	cmd := &cobra.Command{
+		Use:   "file <path>",
+		Short: "Show the diff history of a single file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := git.NewGitExtractor(*repoPath)
+			revisions, err := g.GetFileHistory(args[0], number)
+			if err != nil {
+				return err
+			}
+			if len(revisions) == 0 {
+				fmt.Println(color.YellowString(i18n.T("file.noHistory", args[0])))
+				return nil
+			}
+			if !noInteractive {
+				return RunFileHistoryView(args[0], revisions)
+			}
+			formatter := git.NewDiffFormatter()
+			for _, rev := range revisions {
+				fmt.Println(formatRevisionHeader(rev))
+				fmt.Println(formatter.ToTerminal(rev.Diffs, terminalOptions()))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&number, "number", "n", 10, "Number of commits to show")
+	cmd.Flags().BoolVar(&noInteractive, "no-interactive", false, "Print history without interactive mode")
+	return cmd
+}
+
+func formatRevisionHeader(rev git.FileRevision) string {
+	t, _ := time.Parse(time.RFC3339, rev.Commit.Date)
+	return fmt.Sprintf("%s %s %s (%s)", color.YellowString(short(rev.Commit.Hash, 7)), color.HiBlackString(t.Format("2006-01-02")), rev.Commit.Message, color.HiBlackString(rev.Commit.Author))
+}
+
+type fileHistoryModel struct {
+	path      string
+	revisions []git.FileRevision
+	index     int
+	offset    int
+	height    int
+}
+
+func RunFileHistoryView(path string, revisions []git.FileRevision) error {
+	m := fileHistoryModel{path: path, revisions: revisions, height: 30}
+	p := tea.NewProgram(m, programOptions()...)
+	_, err := p.Run()
+	return err
+}
+
+func (m fileHistoryModel) Init() tea.Cmd { return nil }
+
+func (m fileHistoryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "left", "h", "n":
+			if m.index < len(m.revisions)-1 {
+				m.index++
+				m.offset = 0
+			}
+		case "right", "l", "p":
+			if m.index > 0 {
+				m.index--
+				m.offset = 0
+			}
+		case "down", "j":
+			m.offset++
+		case "up", "k":
+			if m.offset > 0 {
+				m.offset--
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m fileHistoryModel) View() string {
+	header := styled(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("13")), "🔍 DiffLearn • "+m.path)
+	rev := m.revisions[m.index]
+	position := i18n.T("file.revision", m.index+1, len(m.revisions))
+	status := styled(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), position+" • "+i18n.T("file.help"))
+	if accessibleMode {
+		header = "DiffLearn, " + m.path
+	}
+
+	body := strings.Split(git.NewDiffFormatter().ToTerminal(rev.Diffs, terminalOptions()), "\n")
+	visible := m.height - 6
+	if visible < 5 {
+		visible = 5
+	}
+	start := m.offset
+	if start > len(body)-1 {
+		start = len(body) - 1
+	}
+	end := start + visible
+	if end > len(body) {
+		end = len(body)
+	}
+	return fmt.Sprintf("%s\n%s\n\n%s\n\n%s", header, formatRevisionHeader(rev), strings.Join(body[start:end], "\n"), status)
+}
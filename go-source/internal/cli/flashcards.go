@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+	"difflearn-go/internal/llm"
+)
+
+func flashcardsCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
+	var commit, out, deck string
+	var count int
+	cmd := &cobra.Command{
+		Use:   "flashcards",
+		Short: "Export flashcards on the concepts a diff teaches as an Anki-ready CSV file",
+		Long:  "Asks the model to turn the most instructive hunks of the local changes (or --commit) into question-and-answer cards and writes them as CSV that Anki imports with File > Import: the header lines pick the Basic note type and --deck, and each card is tagged difflearn, the repository name, its concept and file.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFlashcards(*repoPath, opts, commit, out, deck, count)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Use only staged changes")
+	cmd.Flags().StringVar(&commit, "commit", "", "Make cards from a commit instead of local changes")
+	cmd.Flags().IntVarP(&count, "count", "n", 10, "Most cards to make")
+	cmd.Flags().StringVar(&deck, "deck", "DiffLearn", "Anki deck the cards go into")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "CSV file to write (default: stdout)")
+	return cmd
+}
+
+func runFlashcards(repoPath string, opts llmCommandOptions, commit, out, deck string, count int) error {
+	g := git.NewGitExtractor(repoPath)
+	formatter := git.NewDiffFormatter()
+	var diffs []git.ParsedDiff
+	var err error
+	if commit != "" {
+		diffs, err = g.GetCommitDiff(commit, "")
+	} else {
+		opts.Smart = true
+		diffs, err = loadSmartDiffs(g, &opts)
+	}
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+		return nil
+	}
+	cfg := config.LoadConfig()
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		fmt.Println(llm.CreateFlashcardPrompt(formatter, diffs, count))
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.flashcards.generating")))
+	cards, report, err := llm.GenerateFlashcards(llm.NewClient(cfg).For("", "flashcards"), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), count)
+	if err != nil {
+		return err
+	}
+	if notice := report.Notice(); notice != "" {
+		fmt.Fprintln(os.Stderr, color.YellowString(notice))
+	}
+	if len(cards) == 0 {
+		fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.flashcards.none")))
+		return nil
+	}
+	ref := commit
+	if ref == "" {
+		ref = describeRef(opts)
+	}
+	learning.Record(g.RepoPath(), "flashcards", ref)
+
+	csv := llm.FormatAnkiCSV(cards, deck, filepath.Base(g.RepoPath()))
+	if out == "" {
+		fmt.Print(csv)
+		return nil
+	}
+	if err := os.WriteFile(out, []byte(csv), 0o644); err != nil {
+		return err
+	}
+	fmt.Println(color.GreenString(i18n.T("cli.flashcards.written", len(cards), out)))
+	return nil
+}
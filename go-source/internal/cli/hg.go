@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/hg"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/jj"
+)
+
+// openRepository returns the repository at path for the dashboard and the
+// AI commands: git, which covers colocated jj workspaces, or else the jj
+// workspace or Mercurial repository containing path. When there is none, the
+// git extractor is returned and reports the error.
+func openRepository(path string) git.Repository {
+	g := git.NewGitExtractor(path)
+	if g.IsRepo() {
+		return g
+	}
+	if root, ok := jj.Find(path); ok {
+		return jj.NewRepo(root, jj.ExecRunner{})
+	}
+	if root, ok := hg.Find(path); ok {
+		return hg.NewRepo(root, hg.ExecRunner{})
+	}
+	return g
+}
+
+// gitOnly returns repo as a git repository, for what only git has: the
+// index, stashes, discarding and switching branches.
+func gitOnly(repo git.Repository) (*git.GitExtractor, error) {
+	if g, ok := repo.(*git.GitExtractor); ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("%s", i18n.T("cli.gitOnly"))
+}
+
+func hgCmd(repoPath *string) *cobra.Command {
+	var explain, review, summary, story, log, branches, jsonOut, noAI bool
+	var from, to, branch, ticketArg string
+	var limit int
+	var opts llmCommandOptions
+	cmd := &cobra.Command{
+		Use:   "hg [rev]",
+		Short: "View, explain or review Mercurial changes, changesets and branches",
+		Long:  "Reads a Mercurial repository through the hg command: the uncommitted changes by default, a changeset with a revision argument, the difference between two revisions with --from and --to, or what a branch or bookmark changed since it diverged with --branch. --log lists the changesets of a revset, --branches the bookmarks and named branches, and --story steps through the changesets of a revset oldest first, like difflearn story. The dashboard and explain, review, summary and ask read Mercurial repositories too.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			repo, err := hg.Open(*repoPath)
+			if err != nil {
+				return err
+			}
+			rev := ""
+			if len(args) == 1 {
+				rev = args[0]
+			}
+			if branches {
+				list, err := repo.Branches()
+				if err != nil {
+					return err
+				}
+				if jsonOut {
+					return printJSON(list)
+				}
+				for _, b := range list {
+					marker := "  "
+					if b.Current {
+						marker = color.GreenString("* ")
+					}
+					fmt.Printf("%s%s %s\n", marker, b.Name, color.HiBlackString(short(b.Commit, 12)))
+				}
+				return nil
+			}
+			if log || story {
+				if rev == "" {
+					rev = "reverse(::.)"
+				}
+				changesets, err := repo.Log(rev, limit)
+				if err != nil {
+					return err
+				}
+				if len(changesets) == 0 {
+					fmt.Println(color.YellowString(i18n.T("cli.hg.noChangesets", rev)))
+					return nil
+				}
+				if story {
+					return runHgStory(repo, rev, changesets, noAI)
+				}
+				if jsonOut {
+					return printJSON(changesets)
+				}
+				for _, c := range changesets {
+					fmt.Printf("%s %s %s %s (%s)\n", color.YellowString("%d:%s", c.Rev, short(c.Node, 12)), color.MagentaString(c.Branch),
+						color.HiBlackString(c.Time.Local().Format("2006-01-02")), c.Subject, color.HiBlackString(c.Author))
+				}
+				return nil
+			}
+
+			var diffs []git.ParsedDiff
+			ref := rev
+			switch {
+			case branch != "":
+				diffs, err = repo.BranchDiff(branch)
+				ref = branch
+			case from != "" || to != "":
+				diffs, err = repo.DiffRange(from, to)
+				ref = from + ".." + to
+			default:
+				diffs, err = repo.Diff(rev)
+				if ref == "" {
+					ref = "working directory"
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if len(diffs) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+				return nil
+			}
+			if err := loadTicket(ticketArg, &opts); err != nil {
+				return err
+			}
+			opts.Preloaded = diffs
+			opts.Ref = "hg " + ref
+			switch {
+			case explain:
+				return runLLMCommand(repo.Root(), "explain", opts)
+			case review:
+				return runLLMCommand(repo.Root(), "review", opts)
+			case summary:
+				return runLLMCommand(repo.Root(), "summary", opts)
+			}
+			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "Show the difference from this revision (default .)")
+	cmd.Flags().StringVar(&to, "to", "", "Show the difference to this revision (default the working directory)")
+	cmd.Flags().StringVar(&branch, "branch", "", "Show what a branch or bookmark changed since it diverged from the working directory's parent")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Get an AI explanation of the changes")
+	cmd.Flags().BoolVar(&review, "review", false, "Get an AI review of the changes")
+	cmd.Flags().BoolVar(&summary, "summary", false, "Get a quick summary of the changes")
+	cmd.Flags().BoolVar(&opts.Narrative, "narrative", false, "With --explain, tell the changes as their author would, step by step")
+	cmd.Flags().BoolVar(&opts.Structured, "structured", false, "Ask for a JSON review with severities")
+	cmd.Flags().BoolVar(&log, "log", false, "List the changesets of the revset (default reverse(::.))")
+	cmd.Flags().BoolVar(&branches, "branches", false, "List bookmarks and open named branches")
+	cmd.Flags().BoolVar(&story, "story", false, "Step through the changesets of the revset oldest first, with explanations and questions")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "With --story, use offline explanations and skip the questions")
+	cmd.Flags().IntVarP(&limit, "number", "n", 30, "With --log or --story, the most changesets to read (0 for all)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "With --log or --branches, print JSON")
+	addTicketFlag(cmd, &ticketArg)
+	addOutputFlags(cmd, &opts)
+	return cmd
+}
+
+// runHgStory opens story mode on changesets, newest first, reading their
+// diffs with hg.
+func runHgStory(repo *hg.Repo, revset string, changesets []hg.Changeset, noAI bool) error {
+	commits := make([]git.CommitInfo, len(changesets))
+	for i, c := range changesets {
+		commits[len(commits)-1-i] = c.Commit()
+	}
+	m := newStoryModel(repo.Root(), revset, commits, 3, noAI)
+	m.commitDiff = repo.Diff
+	_, err := tea.NewProgram(m, programOptions()...).Run()
+	return err
+}
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/histindex"
+	"difflearn-go/internal/i18n"
+)
+
+// indexedHistory brings the history index of repoPath up to date and
+// returns it for querying; the caller closes it.
+func indexedHistory(repoPath string) (*histindex.Index, error) {
+	g := git.NewGitExtractor(repoPath)
+	if !g.IsRepo() {
+		return nil, fmt.Errorf("not a git repository")
+	}
+	ix := histindex.Open(g)
+	added, err := ix.Update()
+	if err != nil {
+		ix.Close()
+		return nil, err
+	}
+	if added > 0 {
+		fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.historyIndex.added", added)))
+	}
+	return ix, nil
+}
+
+// parseSince reads a start date (2024-01-31) or an age in days, weeks or
+// Go duration syntax (30d, 12w, 36h).
+func parseSince(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(value, suffix)); err == nil && strings.HasSuffix(value, suffix) && n >= 0 {
+			return time.Now().Add(-time.Duration(n) * unit), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: want a date (2024-01-31) or an age (30d, 12w, 36h)", value)
+}
+
+type historyQueryFlags struct {
+	author, path, since string
+	limit               int
+	jsonOut             bool
+}
+
+func (f *historyQueryFlags) register(cmd *cobra.Command, limit int) {
+	cmd.Flags().StringVar(&f.author, "author", "", "Only commits whose author name or email contains this text")
+	cmd.Flags().StringVar(&f.path, "path", "", "Only commits touching this file or directory")
+	cmd.Flags().StringVar(&f.since, "since", "", "Only commits since a date (2024-01-31) or an age (30d, 12w)")
+	cmd.Flags().IntVarP(&f.limit, "number", "n", limit, "Maximum number of results (0 for all)")
+	cmd.Flags().BoolVar(&f.jsonOut, "json", false, "Print the results as JSON")
+}
+
+func (f historyQueryFlags) query(text string) (histindex.Query, error) {
+	since, err := parseSince(f.since)
+	if err != nil {
+		return histindex.Query{}, err
+	}
+	return histindex.Query{Text: text, Author: f.author, Path: f.path, Since: since, Limit: f.limit}, nil
+}
+
+func printJSON(v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func historyIndexCmd(repoPath *string) *cobra.Command {
+	var rebuild bool
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Bring the history index up to date",
+		Long:  "The history index keeps the metadata and line counts of every commit on every branch in the DiffLearn data directory. history search, hotspots and contributors update it with just the new commits before they run; index does only that, and --rebuild reads the whole history again, dropping commits of rewritten branches.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			g := git.NewGitExtractor(*repoPath)
+			if !g.IsRepo() {
+				return fmt.Errorf("not a git repository")
+			}
+			ix := histindex.Open(g)
+			defer ix.Close()
+			start := time.Now()
+			var added int
+			var err error
+			if rebuild {
+				added, err = ix.Rebuild()
+			} else {
+				added, err = ix.Update()
+			}
+			if err != nil {
+				return err
+			}
+			_, total := ix.Updated()
+			fmt.Println(color.GreenString(i18n.T("cli.historyIndex.updated", added, total, time.Since(start).Round(time.Millisecond))))
+			fmt.Println(color.HiBlackString(ix.Path()))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&rebuild, "rebuild", false, "Discard the index and read the whole history again")
+	return cmd
+}
+
+func historySearchCmd(repoPath *string) *cobra.Command {
+	var f historyQueryFlags
+	cmd := &cobra.Command{
+		Use:   "search [text]",
+		Short: "Search commit subjects, authors and touched paths of the whole history",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			q, err := f.query(strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			ix, err := indexedHistory(*repoPath)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			found, err := ix.Search(q)
+			if err != nil {
+				return err
+			}
+			if f.jsonOut {
+				return printJSON(found)
+			}
+			if len(found) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.historyIndex.noMatches")))
+				return nil
+			}
+			for _, c := range found {
+				adds, dels := 0, 0
+				for _, file := range c.Files {
+					adds += file.Additions
+					dels += file.Deletions
+				}
+				fmt.Printf("%s %s %s (%s) %s\n", color.YellowString(short(c.Hash, 7)), color.HiBlackString(c.Time.Local().Format("2006-01-02")),
+					c.Subject, color.HiBlackString(c.Author), color.HiBlackString("+%d -%d", adds, dels))
+			}
+			return nil
+		},
+	}
+	f.register(cmd, 20)
+	return cmd
+}
+
+func historyHotspotsCmd(repoPath *string) *cobra.Command {
+	var f historyQueryFlags
+	cmd := &cobra.Command{
+		Use:   "hotspots",
+		Short: "List the files that change most often",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			q, err := f.query("")
+			if err != nil {
+				return err
+			}
+			ix, err := indexedHistory(*repoPath)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			spots, err := ix.Hotspots(q)
+			if err != nil {
+				return err
+			}
+			if f.jsonOut {
+				return printJSON(spots)
+			}
+			if len(spots) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.historyIndex.noMatches")))
+				return nil
+			}
+			for _, h := range spots {
+				fmt.Printf("%5d  %s  %s\n", h.Commits, color.CyanString(h.Path),
+					color.HiBlackString(i18n.T("cli.historyIndex.hotspot", h.Additions, h.Deletions, h.Authors, h.Last.Local().Format("2006-01-02"))))
+			}
+			return nil
+		},
+	}
+	f.register(cmd, 20)
+	return cmd
+}
+
+func historyContributorsCmd(repoPath *string) *cobra.Command {
+	var f historyQueryFlags
+	cmd := &cobra.Command{
+		Use:   "contributors",
+		Short: "Rank authors by commits and lines changed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			q, err := f.query("")
+			if err != nil {
+				return err
+			}
+			ix, err := indexedHistory(*repoPath)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			people, err := ix.Contributors(q)
+			if err != nil {
+				return err
+			}
+			if f.jsonOut {
+				return printJSON(people)
+			}
+			if len(people) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.historyIndex.noMatches")))
+				return nil
+			}
+			for _, p := range people {
+				fmt.Printf("%5d  %s %s  %s\n", p.Commits, p.Name, color.HiBlackString("<"+p.Email+">"),
+					color.HiBlackString(i18n.T("cli.historyIndex.contributor", p.Additions, p.Deletions, p.First.Local().Format("2006-01-02"), p.Last.Local().Format("2006-01-02"))))
+			}
+			return nil
+		},
+	}
+	f.register(cmd, 0)
+	return cmd
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+var hookNames = []string{"pre-commit", "pre-push"}
+
+func hooksCmd(repoPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Install or remove git hooks that run an AI review before commit or push",
+	}
+	cmd.AddCommand(hooksInstallCmd(repoPath), hooksUninstallCmd(repoPath))
+	return cmd
+}
+
+func hooksInstallCmd(repoPath *string) *cobra.Command {
+	var prePush, precommit, force bool
+	var failOn string
+	var maxUnstaged int
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install a pre-commit hook (or pre-push with --pre-push) running review --fail-on",
+		Long:  "The pre-commit hook reviews staged changes; the pre-push hook reviews the branch against its upstream. The hook blocks when pre-check findings or review issues reach --fail-on; DIFFLEARN_FAIL_ON overrides it per run, and git's --no-verify skips the hook.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := analysis.ParseSeverity(failOn); !ok {
+				return fmt.Errorf("invalid --fail-on %q (use critical, important or minor)", failOn)
+			}
+			if prePush && precommit {
+				return fmt.Errorf("--precommit installs a pre-commit hook; drop --pre-push")
+			}
+			name := "pre-commit"
+			if prePush {
+				name = "pre-push"
+			}
+			script := hookScript(name, failOn)
+			if precommit {
+				script = precommitHookScript(maxUnstaged)
+			}
+			path, err := git.NewGitExtractor(*repoPath).InstallHook(name, script, force)
+			if err != nil {
+				return err
+			}
+			if precommit {
+				fmt.Println(color.GreenString(i18n.T("cli.hookInstalledPrecommit", path, maxUnstaged)))
+				return nil
+			}
+			fmt.Println(color.GreenString(i18n.T("cli.hookInstalled", name, path, failOn)))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&prePush, "pre-push", false, "Install the pre-push hook instead of pre-commit")
+	cmd.Flags().BoolVar(&precommit, "precommit", false, "Run precommit --hook instead of a review: summarize what is staged and block when staged files were edited a lot after staging")
+	cmd.Flags().IntVar(&maxUnstaged, "max-unstaged", 10, "With --precommit, unstaged changed lines a staged file may have")
+	cmd.Flags().StringVar(&failOn, "fail-on", string(analysis.SeverityCritical), "Lowest severity that blocks: critical, important or minor")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing hook, keeping it as <hook>.difflearn-backup")
+	return cmd
+}
+
+func hooksUninstallCmd(repoPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove DiffLearn's hooks and restore any hooks they replaced",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := git.NewGitExtractor(*repoPath)
+			removed := 0
+			for _, name := range hookNames {
+				ok, err := g.UninstallHook(name)
+				if err != nil {
+					return err
+				}
+				if ok {
+					removed++
+					fmt.Println(i18n.T("cli.hookRemoved", name))
+				}
+			}
+			if removed == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.noHooks")))
+			}
+			return nil
+		},
+	}
+}
+
+// hookBinary is this binary when difflearn is not on PATH, so hooks keep
+// working from GUIs that do not load the shell's PATH.
+func hookBinary() string {
+	bin := "difflearn"
+	if _, err := exec.LookPath(bin); err != nil {
+		if exe, err := os.Executable(); err == nil {
+			bin = exe
+		}
+	}
+	return bin
+}
+
+func hookScript(name, failOn string) string {
+	bin := hookBinary()
+	review := fmt.Sprintf("exec %q review --staged --fail-on \"${DIFFLEARN_FAIL_ON:-%s}\"\n", bin, failOn)
+	if name == "pre-push" {
+		review = "upstream=$(git rev-parse --abbrev-ref --symbolic-full-name '@{upstream}' 2>/dev/null) || exit 0\n" +
+			fmt.Sprintf("exec %q review --against \"$upstream\" --fail-on \"${DIFFLEARN_FAIL_ON:-%s}\"\n", bin, failOn)
+	}
+	return "#!/bin/sh\n" + git.HookMarker + "\n# Installed by `difflearn hooks install`; remove with `difflearn hooks uninstall`.\n" + review
+}
+
+// precommitHookScript runs precommit --hook; DIFFLEARN_MAX_UNSTAGED
+// overrides the limit per run.
+func precommitHookScript(maxUnstaged int) string {
+	return "#!/bin/sh\n" + git.HookMarker + "\n# Installed by `difflearn hooks install --precommit`; remove with `difflearn hooks uninstall`.\n" +
+		fmt.Sprintf("exec %q precommit --hook --max-unstaged \"${DIFFLEARN_MAX_UNSTAGED:-%d}\"\n", hookBinary(), maxUnstaged)
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+func importCmd(repoPath *string) *cobra.Command {
+	var explain, review, summary bool
+	var ticketArg string
+	var opts llmCommandOptions
+	cmd := &cobra.Command{
+		Use:   "import <file.patch|->",
+		Short: "View, explain or review a patch file from email, a CI artifact or another tool",
+		Long:  "Reads git diff or format-patch output (the patches of an mbox file are combined) or a plain unified diff such as diff -u output. The patch does not need to belong to the repository and is never applied. Pass - to read it from stdin.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			raw, err := readPatch(args[0])
+			if err != nil {
+				return err
+			}
+			diffs := git.NewDiffParser().ParsePatch(raw)
+			if len(diffs) == 0 {
+				return fmt.Errorf("%s contains no diff", args[0])
+			}
+			if subjects := git.PatchSubjects(raw); len(subjects) > 0 {
+				fmt.Println(color.CyanString(strings.Join(subjects, "\n")))
+			}
+			stats := git.NewDiffParser().GetStats(diffs)
+			fmt.Println(color.HiBlackString(i18n.T("cli.importHeader", args[0], stats.Files, stats.Additions, stats.Deletions)) + "\n")
+
+			if err := loadTicket(ticketArg, &opts); err != nil {
+				return err
+			}
+			opts.Preloaded = diffs
+			opts.Ref = args[0]
+			switch {
+			case explain:
+				return runLLMCommand(*repoPath, "explain", opts)
+			case review:
+				return runLLMCommand(*repoPath, "review", opts)
+			case summary:
+				return runLLMCommand(*repoPath, "summary", opts)
+			}
+			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&explain, "explain", false, "Get an AI explanation of the patch")
+	cmd.Flags().BoolVar(&review, "review", false, "Get an AI review of the patch")
+	cmd.Flags().BoolVar(&summary, "summary", false, "Get a quick summary of the patch")
+	cmd.Flags().BoolVar(&opts.Structured, "structured", false, "Ask for a JSON review with severities")
+	addTicketFlag(cmd, &ticketArg)
+	addOutputFlags(cmd, &opts)
+	return cmd
+}
+
+// readPatch reads a patch file, or stdin for "-".
+func readPatch(path string) (string, error) {
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	return string(b), err
+}
+
+func addStdinFlag(cmd *cobra.Command, stdin *bool) {
+	cmd.Flags().BoolVar(stdin, "stdin", false, "Read a unified diff from stdin instead of the repository (same as a - argument)")
+}
+
+// loadStdinDiff puts the diff piped to stdin into opts.Preloaded when
+// --stdin or a lone - argument asks for it, keeping only --file's changes
+// when it is set.
+func loadStdinDiff(stdin bool, args []string, opts *llmCommandOptions) error {
+	if len(args) > 0 && (len(args) > 1 || args[0] != "-") {
+		return fmt.Errorf("unexpected argument %q (use - to read a diff from stdin)", args[0])
+	}
+	if !stdin && len(args) == 0 {
+		return nil
+	}
+	raw, err := readPatch("-")
+	if err != nil {
+		return err
+	}
+	diffs := git.NewDiffParser().ParsePatch(raw)
+	if opts.File != "" {
+		diffs = slices.DeleteFunc(diffs, func(d git.ParsedDiff) bool { return d.NewFile != opts.File && d.OldFile != opts.File })
+	}
+	if len(diffs) == 0 {
+		return fmt.Errorf("no diff found on stdin")
+	}
+	opts.Preloaded = diffs
+	return nil
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+// issueEntry is one issue in an `issues` report.
+type issueEntry struct {
+	Issue   string           `json:"issue"`
+	Summary string           `json:"summary,omitempty"`
+	Stats   git.DiffStats    `json:"stats"`
+	Commits []git.CommitInfo `json:"commits"`
+}
+
+type issueReport struct {
+	Range    string           `json:"range"`
+	Issues   []issueEntry     `json:"issues"`
+	Unlinked []git.CommitInfo `json:"unlinked"`
+}
+
+func issuesCmd(repoPath *string) *cobra.Command {
+	var format, out string
+	var noAI bool
+	cmd := &cobra.Command{
+		Use:   "issues <ref1>..<ref2>",
+		Short: "Group the commits in a range by the issues they reference and summarize each issue",
+		Long:  "Reads #12, owner/repo#12, GH-12, issue URLs and tracker keys such as PROJ-123 from commit subjects and bodies, groups the commits by issue and, when an LLM is configured, writes a release-notes entry for each issue from its commits' diffs. Commits without a reference are listed at the end. Use --format markdown for release notes.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "markdown" && format != "json" {
+				return fmt.Errorf("invalid --format %q (use text, markdown or json)", format)
+			}
+			report, err := buildIssueReport(*repoPath, args[0], noAI)
+			if err != nil {
+				return err
+			}
+			switch format {
+			case "json":
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				return writeReport(b, out)
+			case "markdown":
+				return writeReport([]byte(issueReportMarkdown(report)), out)
+			}
+			printIssueReport(report)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text, markdown or json")
+	cmd.Flags().StringVarP(&out, "output", "o", "", "Write the markdown or json report to a file instead of stdout")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Only group the commits, without summaries")
+	return cmd
+}
+
+// buildIssueReport groups the range's commits by issue and, unless noAI or
+// no LLM is configured, summarizes each issue. Progress goes to stderr so
+// stdout stays the report.
+func buildIssueReport(repoPath, rangeSpec string, noAI bool) (issueReport, error) {
+	if !strings.Contains(rangeSpec, "..") {
+		return issueReport{}, fmt.Errorf("expected a range like v1.0..HEAD, got %q", rangeSpec)
+	}
+	g := git.NewGitExtractor(repoPath)
+	commits, err := g.GetCommitsWithBodies(rangeSpec)
+	if err != nil {
+		return issueReport{}, err
+	}
+	groups, unlinked := git.GroupCommitsByIssue(commits)
+	report := issueReport{Range: rangeSpec, Issues: make([]issueEntry, 0, len(groups)), Unlinked: unlinked}
+	if report.Unlinked == nil {
+		report.Unlinked = []git.CommitInfo{}
+	}
+
+	cfg := config.LoadConfig()
+	online := !noAI && config.IsLLMAvailable(cfg)
+	if !noAI && !online && len(groups) > 0 {
+		fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.noLLMOffline")))
+	}
+	formatter := git.NewDiffFormatter()
+	parser := git.NewDiffParser()
+	for _, group := range groups {
+		var diffs []git.ParsedDiff
+		// Oldest first, so later commits read as edits of earlier ones.
+		for i := len(group.Commits) - 1; i >= 0; i-- {
+			d, err := g.GetCommitDiff(group.Commits[i].Hash, "")
+			if err != nil {
+				return issueReport{}, err
+			}
+			diffs = append(diffs, d...)
+		}
+		entry := issueEntry{Issue: group.Issue, Stats: parser.GetStats(diffs), Commits: group.Commits}
+		if online && len(diffs) > 0 {
+			fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.exportGenerating", group.Issue)))
+			build := func(d []git.ParsedDiff) string {
+				return llm.CreateIssueSummaryPrompt(formatter, group.Issue, group.Commits, d)
+			}
+			resp, budget, err := llm.RunBudgeted(llm.NewClient(cfg), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), build)
+			if err != nil {
+				return issueReport{}, err
+			}
+			if notice := budget.Notice(); notice != "" {
+				fmt.Fprintln(os.Stderr, color.YellowString(notice))
+			}
+			entry.Summary = strings.TrimSpace(resp.Content)
+		}
+		report.Issues = append(report.Issues, entry)
+	}
+	return report, nil
+}
+
+func issueReportMarkdown(report issueReport) string {
+	var sb strings.Builder
+	for _, e := range report.Issues {
+		sb.WriteString(fmt.Sprintf("## %s\n\n", e.Issue))
+		if e.Summary != "" {
+			sb.WriteString(e.Summary + "\n\n")
+		}
+		for _, c := range e.Commits {
+			sb.WriteString(fmt.Sprintf("- %s %s (%s)\n", short(c.Hash, 7), c.Message, c.Author))
+		}
+		sb.WriteString("\n")
+	}
+	if len(report.Unlinked) > 0 {
+		sb.WriteString("## " + i18n.T("cli.issues.unlinked") + "\n\n")
+		for _, c := range report.Unlinked {
+			sb.WriteString(fmt.Sprintf("- %s %s (%s)\n", short(c.Hash, 7), c.Message, c.Author))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func printIssueReport(report issueReport) {
+	if len(report.Issues) == 0 && len(report.Unlinked) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noCommitsInRange", report.Range)))
+		return
+	}
+	for _, e := range report.Issues {
+		fmt.Printf("%s %s\n", color.New(color.Bold).Sprint(e.Issue), color.HiBlackString(i18n.T("cli.issues.stats", len(e.Commits), e.Stats.Files, e.Stats.Additions, e.Stats.Deletions)))
+		if e.Summary != "" {
+			fmt.Println(renderMarkdown(e.Summary))
+		}
+		for _, c := range e.Commits {
+			fmt.Printf("  %s %s\n", color.YellowString(short(c.Hash, 7)), c.Message)
+		}
+		fmt.Println()
+	}
+	if len(report.Unlinked) > 0 {
+		fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.issues.unlinked")))
+		for _, c := range report.Unlinked {
+			fmt.Printf("  %s %s\n", color.YellowString(short(c.Hash, 7)), c.Message)
+		}
+	}
+}
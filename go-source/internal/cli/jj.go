@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/jj"
+)
+
+func jjCmd(repoPath *string) *cobra.Command {
+	var explain, review, summary, story, log, jsonOut, noAI bool
+	var from, to, ticketArg string
+	var limit int
+	var opts llmCommandOptions
+	cmd := &cobra.Command{
+		Use:   "jj [revset]",
+		Short: "View, explain or review Jujutsu (jj) changes and revsets",
+		Long:  "Reads changes from a jj repository through the jj command: the working-copy change @ by default, any revset such as @- or 'trunk()..@', or the difference between two revisions with --from and --to. --log lists the changes of a revset and --story steps through them oldest first, like difflearn story. The dashboard and explain, review, summary and ask read jj workspaces too, with @ as the uncommitted changes and @- as HEAD.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			repo, err := jj.Open(*repoPath)
+			if err != nil {
+				return err
+			}
+			revset := ""
+			if len(args) == 1 {
+				revset = args[0]
+			}
+			if log || story {
+				if revset == "" {
+					revset = "trunk()..@"
+				}
+				changes, err := repo.Changes(revset, limit)
+				if err != nil {
+					return err
+				}
+				if len(changes) == 0 {
+					fmt.Println(color.YellowString(i18n.T("cli.jj.noChanges", revset)))
+					return nil
+				}
+				if story {
+					return runJJStory(repo, revset, changes, noAI)
+				}
+				if jsonOut {
+					return printJSON(changes)
+				}
+				for _, c := range changes {
+					subject := c.Subject
+					if subject == "" {
+						subject = color.HiBlackString(i18n.T("cli.jj.noDescription"))
+					}
+					flags := ""
+					if c.Empty {
+						flags += " " + color.HiBlackString(i18n.T("cli.jj.empty"))
+					}
+					if c.Conflict {
+						flags += " " + color.RedString(i18n.T("cli.jj.conflict"))
+					}
+					fmt.Printf("%s %s %s %s (%s)%s\n", color.MagentaString(c.ChangeID), color.YellowString(short(c.CommitID, 7)),
+						color.HiBlackString(c.Time.Local().Format("2006-01-02")), subject, color.HiBlackString(c.Author), flags)
+				}
+				return nil
+			}
+
+			var diffs []git.ParsedDiff
+			ref := revset
+			if from != "" || to != "" {
+				diffs, err = repo.DiffRange(from, to)
+				ref = from + ".." + to
+			} else {
+				diffs, err = repo.Diff(revset)
+				if ref == "" {
+					ref = "@"
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if len(diffs) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+				return nil
+			}
+			if err := loadTicket(ticketArg, &opts); err != nil {
+				return err
+			}
+			opts.Preloaded = diffs
+			opts.Ref = "jj " + ref
+			switch {
+			case explain:
+				return runLLMCommand(repo.Root(), "explain", opts)
+			case review:
+				return runLLMCommand(repo.Root(), "review", opts)
+			case summary:
+				return runLLMCommand(repo.Root(), "summary", opts)
+			}
+			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "Show the difference from this revision (default @-)")
+	cmd.Flags().StringVar(&to, "to", "", "Show the difference to this revision (default @)")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Get an AI explanation of the changes")
+	cmd.Flags().BoolVar(&review, "review", false, "Get an AI review of the changes")
+	cmd.Flags().BoolVar(&summary, "summary", false, "Get a quick summary of the changes")
+	cmd.Flags().BoolVar(&opts.Narrative, "narrative", false, "With --explain, tell the changes as their author would, step by step")
+	cmd.Flags().BoolVar(&opts.Structured, "structured", false, "Ask for a JSON review with severities")
+	cmd.Flags().BoolVar(&log, "log", false, "List the changes of the revset (default trunk()..@)")
+	cmd.Flags().BoolVar(&story, "story", false, "Step through the changes of the revset oldest first, with explanations and questions")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "With --story, use offline explanations and skip the questions")
+	cmd.Flags().IntVarP(&limit, "number", "n", 0, "With --log or --story, the most changes to read (0 for all)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "With --log, print the changes as JSON")
+	addTicketFlag(cmd, &ticketArg)
+	addOutputFlags(cmd, &opts)
+	return cmd
+}
+
+// runJJStory opens story mode on jj changes, newest first, reading their
+// commits from the repository's git store.
+func runJJStory(repo *jj.Repo, revset string, changes []jj.Change, noAI bool) error {
+	commits := make([]git.CommitInfo, len(changes))
+	for i, c := range changes {
+		commits[len(commits)-1-i] = c.Commit()
+	}
+	_, err := tea.NewProgram(newStoryModel(repo.GitDir(), revset, commits, 3, noAI), programOptions()...).Run()
+	return err
+}
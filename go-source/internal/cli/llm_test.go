@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/aihistory"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/llm"
+)
+
+// fakeLLM serves answer to every chat request and returns a config that
+// talks to it.
+func fakeLLM(t *testing.T, answer string) config.Config {
+	t.Helper()
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	t.Setenv("DIFFLEARN_RETRIES", "0")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": answer}}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return config.Config{Provider: config.ProviderOpenAICompatible, BaseURL: srv.URL, Model: "test", MaxTokens: 1000, ContextTokens: 100000}
+}
+
+func TestRubricScorecardReadsWholeAnswer(t *testing.T) {
+	review := "### Correctness\nScore: 2\nVerdict: fail\n- critical: the loop never ends\n\n### Overall\nNeeds work."
+	cfg := fakeLLM(t, review)
+	content, err := streamLLMResponse(llm.NewClient(cfg), cfg, git.NewDiffFormatter(), nil, "Review", func([]git.ParsedDiff) string { return "review" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != review {
+		t.Fatalf("the answer lost its layout: %q", content)
+	}
+	results := llm.ParseRubricReview(content, []config.RubricCriterion{{Name: "Correctness", Weight: 1, Gate: true}})
+	if !results[0].Found || results[0].Score != 2 || len(llm.FailedGates(results)) != 1 {
+		t.Fatalf("scorecard = %+v", results)
+	}
+}
+
+func TestOutKeepsAnswerLayout(t *testing.T) {
+	answer := "## Summary\n\n- adds a parser\n- drops the old one\n\n```go\nfunc main() {}\n```"
+	cfg := fakeLLM(t, answer)
+	repo := t.TempDir()
+	if err := exec.Command("git", "init", "-q", repo).Run(); err != nil {
+		t.Skip("git is not available")
+	}
+	content, err := streamLLMResponse(llm.NewClient(cfg), cfg, git.NewDiffFormatter(), nil, "Explanation", func([]git.ParsedDiff) string { return "explain" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "notes", "explain.md")
+	if err := deliverResponse(git.NewGitExtractor(repo), cfg, "explain", llmCommandOptions{Out: out}, content); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(b), "\n"+answer+"\n") {
+		t.Fatalf("the saved answer lost its newlines:\n%s", b)
+	}
+	if last, err := aihistory.Open().Last(repo); err != nil || last.Response != answer {
+		t.Fatalf("the session history lost its newlines: %q, %v", last.Response, err)
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/fatih/color"
+
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+// rawOutput is set by --raw: LLM answers are printed as the markdown the
+// model returned instead of being rendered for the terminal.
+var rawOutput bool
+
+// renderMarkdownEnabled reports whether answers should be rendered. Piped
+// output (where color is off) and accessible mode always get the raw text.
+func renderMarkdownEnabled() bool {
+	return !rawOutput && !accessibleMode && !color.NoColor
+}
+
+// renderMarkdown renders text with headings, emphasis, lists and
+// highlighted code blocks, falling back to the raw text on any error.
+func renderMarkdown(text string) string {
+	if !renderMarkdownEnabled() {
+		return text
+	}
+	r, err := glamour.NewTermRenderer(glamour.WithAutoStyle(), glamour.WithWordWrap(min(terminalWidth(), 100)))
+	if err != nil {
+		return text
+	}
+	out, err := r.Render(text)
+	if err != nil {
+		return text
+	}
+	return strings.TrimRight(out, "\n")
+}
+
+// chatWithProgress waits for the answer to messages, showing a progress
+// line meanwhile when the answer will be rendered.
+func chatWithProgress(client *llm.Client, messages []llm.ChatMessage) (llm.LLMResponse, error) {
+	if !renderMarkdownEnabled() {
+		return client.Chat(messages)
+	}
+	fmt.Print(color.HiBlackString(i18n.T("cli.generating")))
+	resp, err := client.Chat(messages)
+	fmt.Print("\r\x1b[K")
+	return resp, err
+}
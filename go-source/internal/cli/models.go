@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func modelsCmd() *cobra.Command {
+	var provider string
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "models [model]",
+		Short: "List the models of the provider and pick the one to use",
+		Long:  "Asks the active provider, or --provider, which models it offers: the /models list of OpenAI, LM Studio and openai-compatible servers, the installed models of Ollama, and the model lists of Anthropic and Google. In a terminal it then asks which one to use and saves it to ~/.difflearn, or to the profile given with --profile; naming a model saves it without asking. A model is only saved when the provider lists it. CLI providers cannot list their models.",
+		Example: "  difflearn models\n" +
+			"  difflearn models --provider ollama qwen2.5-coder:7b\n" +
+			"  difflearn models --json",
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{"newProfile": "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			active := config.LoadConfig()
+			cfg := active
+			if provider != "" {
+				var err error
+				if cfg, err = config.ProviderConfig(active, config.LLMProvider(provider)); err != nil {
+					return err
+				}
+			}
+			models, err := llm.NewClient(cfg).ListModels()
+			if err != nil {
+				return fmt.Errorf("cannot list the models of %s: %w", cfg.Provider, err)
+			}
+			if jsonOut {
+				return printJSON(models)
+			}
+
+			var chosen string
+			if len(args) == 1 {
+				if chosen = findModel(models, args[0]); chosen == "" {
+					return fmt.Errorf("%s does not offer a model named %s; run difflearn models to list them", cfg.Provider, args[0])
+				}
+			} else {
+				if len(models) == 0 {
+					fmt.Println(color.YellowString(i18n.T("cli.models.none", cfg.Provider)))
+					return nil
+				}
+				fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.models.header", cfg.Provider)))
+				width := len(strconv.Itoa(len(models)))
+				for i, m := range models {
+					mark := "  "
+					if cfg.Provider == active.Provider && sameModel(m, active.Model) {
+						mark = color.GreenString("* ")
+					}
+					fmt.Printf("%s%*d) %s\n", mark, width, i+1, m)
+				}
+				if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+					return nil
+				}
+				fmt.Println()
+				fmt.Print(i18n.T("cli.models.pick"))
+				answer, _ := stdin.ReadString('\n')
+				answer = strings.TrimSpace(answer)
+				if answer == "" {
+					return nil
+				}
+				if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(models) {
+					chosen = models[n-1]
+				} else if chosen = findModel(models, answer); chosen == "" {
+					return fmt.Errorf("%s does not offer a model named %s", cfg.Provider, answer)
+				}
+			}
+			return saveModel(cmd, cfg.Provider, active.Provider, chosen)
+		},
+	}
+	cmd.Flags().StringVar(&provider, "provider", "", "Provider to list the models of (default the active one)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the model names as JSON")
+	return cmd
+}
+
+// findModel returns how the list spells name, allowing Ollama's implied
+// :latest tag, or "".
+func findModel(models []string, name string) string {
+	name = strings.TrimSpace(name)
+	for _, m := range models {
+		if sameModel(m, name) {
+			return m
+		}
+	}
+	return ""
+}
+
+func sameModel(listed, name string) bool {
+	return listed == name || listed == name+":latest"
+}
+
+// saveModel persists model, and provider when it is not the active one, to
+// ~/.difflearn or the profile being edited.
+func saveModel(cmd *cobra.Command, provider, active config.LLMProvider, model string) error {
+	profile := profileToEdit(cmd)
+	before := config.FileSettings()
+	if provider != active {
+		if _, err := config.SetSetting(profile, "provider", string(provider)); err != nil {
+			return err
+		}
+	}
+	k, err := config.SetSetting(profile, "model", model)
+	if err != nil {
+		return err
+	}
+	message := i18n.T("cli.models.saved", provider, model, config.FilePath())
+	if profile != "" {
+		message = i18n.T("cli.models.savedProfile", provider, model, profile, config.FilePath())
+	}
+	was := before[k.Name]
+	if os.Getenv(k.Name) == model {
+		was = model
+	}
+	settingSaved(k, profile, message, was)
+	return nil
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+)
+
+func addOutputFlags(cmd *cobra.Command, opts *llmCommandOptions) {
+	cmd.Flags().BoolVar(&opts.Copy, "copy", false, "Copy the response to the clipboard")
+	cmd.Flags().StringVar(&opts.Out, "out", "", "Write the response to a file with front-matter metadata (repo, ref, model, date)")
+}
+
+// deliverResponse records an AI answer for `again` and learning progress,
+// then copies and/or saves it after it was printed.
+func deliverResponse(g git.Repository, cfg config.Config, kind string, opts llmCommandOptions, content string) error {
+	recordAIRequest(g, cfg, kind, opts, content)
+	learning.Record(g.RepoPath(), kind, opts.Ref)
+	return deliverOutput(g, cfg, kind, opts, content)
+}
+
+// deliverOutput saves, copies or hands on an answer as --out, --copy and
+// OnResponse ask, without recording it.
+func deliverOutput(g git.Repository, cfg config.Config, kind string, opts llmCommandOptions, content string) error {
+	if opts.Out != "" {
+		doc := responseFrontMatter(g, cfg, kind, opts) + strings.TrimSpace(content) + "\n"
+		if dir := filepath.Dir(opts.Out); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(opts.Out, []byte(doc), 0o644); err != nil {
+			return err
+		}
+		fmt.Println(color.HiBlackString(i18n.T("cli.savedTo", opts.Out)))
+	}
+	if opts.Copy {
+		if err := copyToClipboard(content); err != nil {
+			return err
+		}
+		fmt.Println(color.HiBlackString(i18n.T("cli.copied")))
+	}
+	if opts.OnResponse != nil {
+		return opts.OnResponse(content)
+	}
+	return nil
+}
+
+// responseFrontMatter records where an answer came from so archived files
+// stay meaningful once the working tree has moved on.
+func responseFrontMatter(g git.Repository, cfg config.Config, kind string, opts llmCommandOptions) string {
+	fields := [][2]string{
+		{"command", kind},
+		{"repo", g.RepoPath()},
+		{"ref", describeRef(opts)},
+	}
+	if head, err := g.ResolveRef("HEAD"); err == nil {
+		fields = append(fields, [2]string{"commit", head})
+	}
+	if opts.File != "" {
+		fields = append(fields, [2]string{"file", opts.File})
+	}
+	if opts.Question != "" {
+		fields = append(fields, [2]string{"question", opts.Question})
+	}
+	fields = append(fields,
+		[2]string{"provider", string(cfg.Provider)},
+		[2]string{"model", cfg.Model},
+		[2]string{"date", time.Now().Format(time.RFC3339)},
+	)
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, f := range fields {
+		sb.WriteString(fmt.Sprintf("%s: %q\n", f[0], f[1]))
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
+
+// describeRef names the changes an LLM command looked at.
+func describeRef(opts llmCommandOptions) string {
+	switch {
+	case opts.Ref != "":
+		return opts.Ref
+	case opts.Stash != nil:
+		return fmt.Sprintf("stash@{%d}", *opts.Stash)
+	case opts.Against != "":
+		return opts.Against
+	case opts.Staged:
+		return "staged"
+	default:
+		return "working tree"
+	}
+}
+
+func copyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard tool found (install xclip, xsel or wl-copy)")
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+	"difflearn-go/internal/owners"
+)
+
+// ownerSection is one owner's part of an `owners` report. Owner is empty for
+// files CODEOWNERS assigns to nobody.
+type ownerSection struct {
+	Owner   string        `json:"owner"`
+	Files   []string      `json:"files"`
+	Stats   git.DiffStats `json:"stats"`
+	Content string        `json:"content,omitempty"`
+	Posted  bool          `json:"posted,omitempty"`
+}
+
+func ownersCmd(repoPath *string) *cobra.Command {
+	var base, kind, format, out string
+	var noAI, post bool
+	cmd := &cobra.Command{
+		Use:   "owners [<branch>|<ref1>..<ref2>]",
+		Short: "Split a branch diff by CODEOWNERS and summarize or review each owner's part",
+		Long:  "Compares a branch (HEAD by default) with --base from their merge base, or takes a range, assigns each changed file to its owners from CODEOWNERS (in .github/, the root, docs/ or .gitlab/) and writes a summary or review section per owner. A file with several owners is in each of their sections. With --post every section is sent to its owner's chat webhook, configured in .difflearn.yaml under owners.webhooks.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if kind != "summary" && kind != "review" {
+				return fmt.Errorf("invalid --kind %q (use summary or review)", kind)
+			}
+			if format != "text" && format != "markdown" && format != "json" {
+				return fmt.Errorf("invalid --format %q (use text, markdown or json)", format)
+			}
+			if post && noAI {
+				return fmt.Errorf("--post needs the AI sections; drop --no-ai")
+			}
+			target := "HEAD"
+			if len(args) == 1 {
+				target = args[0]
+			}
+			rangeSpec := target
+			if !strings.Contains(target, "..") {
+				base, err := resolveBase(git.NewGitExtractor(*repoPath), base)
+				if err != nil {
+					return err
+				}
+				rangeSpec = base + "..." + target
+			}
+			sections, err := runOwners(*repoPath, rangeSpec, kind, noAI, post)
+			if err != nil {
+				return err
+			}
+			switch format {
+			case "json":
+				b, err := json.MarshalIndent(sections, "", "  ")
+				if err != nil {
+					return err
+				}
+				return writeReport(b, out)
+			case "markdown":
+				return writeReport([]byte(ownerSectionsMarkdown(sections, rangeSpec)), out)
+			}
+			printOwnerSections(sections)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&base, "base", "", "Branch a branch argument is compared with (default: the repository's default branch)")
+	cmd.Flags().StringVar(&kind, "kind", "review", "Section to write per owner: summary or review")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text, markdown or json")
+	cmd.Flags().StringVarP(&out, "output", "o", "", "Write the markdown or json report to a file instead of stdout")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Only list each owner's files")
+	cmd.Flags().BoolVar(&post, "post", false, "Send each section to its owner's webhook from owners.webhooks in .difflearn.yaml")
+	return cmd
+}
+
+// runOwners writes a section per owner of the range's changes, printing
+// progress to stderr so stdout stays the report.
+func runOwners(repoPath, rangeSpec, kind string, noAI, post bool) ([]ownerSection, error) {
+	codeowners, err := owners.Load(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := git.NewGitExtractor(repoPath).GetRangeDiff(rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+	repoCfg, err := config.LoadRepoConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.LoadConfig()
+	online := !noAI && config.IsLLMAvailable(cfg)
+	if !noAI && !online {
+		fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.noLLMOffline")))
+	}
+	formatter := git.NewDiffFormatter()
+	parser := git.NewDiffParser()
+	groups := owners.Split(codeowners, diffs)
+	sections := make([]ownerSection, 0, len(groups))
+	for _, group := range groups {
+		s := ownerSection{Owner: group.Owner, Stats: parser.GetStats(group.Diffs)}
+		for _, d := range group.Diffs {
+			s.Files = append(s.Files, d.NewFile)
+		}
+		switch {
+		case noAI:
+		case !online:
+			s.Content = offlineSection(kind, group.Diffs)
+		default:
+			build, _, _, err := promptFor(repoPath, kind, llmCommandOptions{}, formatter, group.Diffs)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.exportGenerating", ownerLabel(group.Owner))))
+			resp, budget, err := llm.RunBudgeted(llm.NewClient(cfg), formatter, group.Diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), build)
+			if err != nil {
+				return nil, err
+			}
+			if notice := budget.Notice(); notice != "" {
+				fmt.Fprintln(os.Stderr, color.YellowString(notice))
+			}
+			s.Content = strings.TrimSpace(resp.Content)
+		}
+		if post && group.Owner != "" {
+			url := repoCfg.OwnerWebhook(group.Owner)
+			if url == "" {
+				fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.owners.noWebhook", group.Owner)))
+			} else if err := owners.PostWebhook(url, ownerSectionMarkdown(s, rangeSpec)); err != nil {
+				return nil, fmt.Errorf("posting to %s: %w", group.Owner, err)
+			} else {
+				s.Posted = true
+				fmt.Fprintln(os.Stderr, color.GreenString(i18n.T("cli.owners.posted", group.Owner)))
+			}
+		}
+		sections = append(sections, s)
+	}
+	return sections, nil
+}
+
+func ownerLabel(owner string) string {
+	if owner == "" {
+		return i18n.T("cli.owners.unowned")
+	}
+	return owner
+}
+
+func ownerSectionMarkdown(s ownerSection, rangeSpec string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## %s — %s\n\n", ownerLabel(s.Owner), rangeSpec))
+	if s.Content != "" {
+		sb.WriteString(s.Content + "\n\n")
+	}
+	for _, f := range s.Files {
+		sb.WriteString("- `" + f + "`\n")
+	}
+	return sb.String()
+}
+
+func ownerSectionsMarkdown(sections []ownerSection, rangeSpec string) string {
+	parts := make([]string, 0, len(sections))
+	for _, s := range sections {
+		parts = append(parts, ownerSectionMarkdown(s, rangeSpec))
+	}
+	return strings.TrimRight(strings.Join(parts, "\n"), "\n")
+}
+
+func printOwnerSections(sections []ownerSection) {
+	if len(sections) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+		return
+	}
+	for _, s := range sections {
+		fmt.Printf("%s %s\n", color.New(color.Bold).Sprint(ownerLabel(s.Owner)), color.HiBlackString("%d file(s), +%d -%d", s.Stats.Files, s.Stats.Additions, s.Stats.Deletions))
+		for _, f := range s.Files {
+			fmt.Printf("  %s\n", f)
+		}
+		if s.Content != "" {
+			fmt.Println()
+			fmt.Println(renderMarkdown(s.Content))
+		}
+		fmt.Println()
+	}
+}
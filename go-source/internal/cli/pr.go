@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/forge"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+func prCmd(repoPath *string) *cobra.Command {
+	var explain, review, summary, post bool
+	var opts llmCommandOptions
+	cmd := &cobra.Command{
+		Use:     "pr <number|url>",
+		Aliases: []string{"mr"},
+		Short:   "View, explain or review a GitHub/Bitbucket pull request or GitLab merge request",
+		Long:    "Fetch a pull or merge request's diff from the forge API. Accepts a number, #number or !number (resolved against the origin remote), owner/repo#number, owner/repo!number, or a URL. The forge is detected from the URL or remote host; set DIFFLEARN_FORGE to github, gitlab or bitbucket to override it. Tokens: GITHUB_TOKEN, GITLAB_TOKEN, BITBUCKET_TOKEN (needed for private repositories and --post).",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if post && !review {
+				return fmt.Errorf("--post requires --review")
+			}
+			g := git.NewGitExtractor(*repoPath)
+			remote, _ := g.GetRemoteURL("origin")
+			ref, err := forge.ParseRef(args[0], remote)
+			if err != nil {
+				return err
+			}
+			client, err := forge.New(ref.Kind)
+			if err != nil {
+				return err
+			}
+			pr, err := client.GetChange(ref)
+			if err != nil {
+				return err
+			}
+			raw, err := client.GetDiff(ref)
+			if err != nil {
+				return err
+			}
+			diffs := git.NewDiffParser().Parse(raw)
+			fmt.Println(color.CyanString("%s %s", ref, pr.Title))
+			fmt.Println(color.HiBlackString(i18n.T("cli.prHeader", pr.Author, pr.BaseRef, pr.HeadRef, pr.State)) + "\n")
+
+			opts.Preloaded = diffs
+			opts.Ref = ref.String()
+			if post {
+				opts.OnResponse = func(content string) error {
+					link, err := client.PostComment(ref, "## DiffLearn review\n\n"+strings.TrimSpace(content))
+					if err != nil {
+						return err
+					}
+					fmt.Println(color.GreenString(i18n.T("cli.prPosted", link)))
+					return nil
+				}
+			}
+			switch {
+			case explain:
+				return runLLMCommand(*repoPath, "explain", opts)
+			case review:
+				return runLLMCommand(*repoPath, "review", opts)
+			case summary:
+				return runLLMCommand(*repoPath, "summary", opts)
+			}
+			if len(diffs) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+				return nil
+			}
+			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&explain, "explain", false, "Get an AI explanation of the change")
+	cmd.Flags().BoolVar(&review, "review", false, "Get an AI review of the change")
+	cmd.Flags().BoolVar(&summary, "summary", false, "Get a quick summary of the change")
+	cmd.Flags().BoolVar(&post, "post", false, "Post the AI review as a comment on the pull or merge request (needs a token)")
+	cmd.Flags().BoolVar(&opts.Structured, "structured", false, "Ask for a JSON review with severities")
+	addOutputFlags(cmd, &opts)
+	return cmd
+}
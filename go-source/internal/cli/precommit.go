@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+func precommitCmd(repoPath *string) *cobra.Command {
+	var hook, noAI bool
+	var maxUnstaged int
+	cmd := &cobra.Command{
+		Use:   "precommit",
+		Short: "Show what you are about to commit: the staged changes, a summary, and files edited after staging",
+		Long:  "Lists the staged files and summarizes only the staged changes. Files that were edited again after staging get a warning, since the commit takes the staged version. With --hook the command fails when a staged file has more than --max-unstaged unstaged changed lines, so a pre-commit hook can stop the commit; `hooks install --precommit` installs one.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// A blocked commit is an expected outcome, not a usage error.
+			cmd.SilenceUsage = true
+			return runPrecommit(*repoPath, hook, noAI, maxUnstaged)
+		},
+	}
+	cmd.Flags().BoolVar(&hook, "hook", false, "Fail when staged and unstaged versions of a file diverge by more than --max-unstaged lines")
+	cmd.Flags().IntVar(&maxUnstaged, "max-unstaged", 10, "Unstaged changed lines a staged file may have before --hook fails")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Use the offline summary instead of asking the LLM")
+	return cmd
+}
+
+func runPrecommit(repoPath string, hook, noAI bool, maxUnstaged int) error {
+	g := git.NewGitExtractor(repoPath)
+	views, err := g.GetStageViews()
+	if err != nil {
+		return err
+	}
+	staged := make([]git.ParsedDiff, 0, len(views))
+	var edited []git.StageView
+	for _, v := range views {
+		if v.Staged == nil {
+			continue
+		}
+		staged = append(staged, *v.Staged)
+		if v.Unstaged != nil {
+			edited = append(edited, v)
+		}
+	}
+	if len(staged) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.precommit.nothingStaged")))
+		return nil
+	}
+
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.precommit.header", len(staged))))
+	for _, d := range staged {
+		fmt.Printf("  %s %s %s\n", d.NewFile, color.GreenString("+%d", d.Additions), color.RedString("-%d", d.Deletions))
+	}
+	fmt.Println()
+	diverged := 0
+	for _, v := range edited {
+		fmt.Println(color.YellowString("⚠ " + i18n.T("cli.precommit.edited", v.Path, v.Unstaged.Additions, v.Unstaged.Deletions)))
+		if v.Unstaged.Additions+v.Unstaged.Deletions > maxUnstaged {
+			diverged++
+		}
+	}
+	if len(edited) > 0 {
+		fmt.Println(color.HiBlackString(i18n.T("cli.precommit.editedHint")) + "\n")
+	}
+
+	if noAI {
+		fmt.Println(renderMarkdown(analysis.OfflineSummary(staged)))
+	} else if err := runLLMCommand(repoPath, "summary", llmCommandOptions{Staged: true}); err != nil {
+		return err
+	}
+	if hook && diverged > 0 {
+		return fmt.Errorf("%s", i18n.T("cli.precommit.blocked", diverged, maxUnstaged))
+	}
+	return nil
+}
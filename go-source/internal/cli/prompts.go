@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func promptsCmd(repoPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompts",
+		Short: "List or edit the prompt templates that replace the built-in prompts",
+		Long:  "The system prompt and the explain, review, summary and question prompts can be replaced by Go templates named <prompt>.tmpl. Templates in the repository's .difflearn/prompts directory win over the user's, which live in the prompts directory of the DiffLearn data directory (DIFFLEARN_DATA_DIR). Templates can use {{.Diff}}, {{.Stats}}, {{.Files}}, {{.Additions}}, {{.Deletions}}, {{.Question}}, {{.Repo}}, {{.Branch}}, {{.DefaultBranch}} and {{.Default}}, the built-in prompt. A template that fails to render falls back to the built-in prompt.",
+	}
+	cmd.AddCommand(promptsListCmd(repoPath), promptsEditCmd(repoPath))
+	return cmd
+}
+
+func promptsListCmd(repoPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Show which template each prompt uses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, t := range llm.ListPromptTemplates(*repoPath) {
+				switch {
+				case t.Err != nil:
+					fmt.Printf("%-9s %s  %s\n", t.Name, t.Path, color.RedString(i18n.T("cli.prompts.invalid", t.Err)))
+				case t.Path != "":
+					fmt.Printf("%-9s %s  %s\n", t.Name, t.Path, color.HiBlackString(t.Source))
+				default:
+					fmt.Printf("%-9s %s\n", t.Name, color.HiBlackString(i18n.T("cli.prompts.builtin")))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func promptsEditCmd(repoPath *string) *cobra.Command {
+	var shared bool
+	cmd := &cobra.Command{
+		Use:       "edit <" + strings.Join(llm.PromptNames, "|") + ">",
+		Short:     "Open a prompt template in $EDITOR, creating it from the built-in prompt",
+		Long:      "Opens the user's template for the prompt, or the repository's with --shared, in $VISUAL or $EDITOR. A missing template is first created with the built-in prompt written as a template.",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: llm.PromptNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if !llm.IsPromptName(name) {
+				return fmt.Errorf("unknown prompt %q (want one of %s)", name, strings.Join(llm.PromptNames, ", "))
+			}
+			cmd.SilenceUsage = true
+			dir := llm.UserPromptDir()
+			if shared {
+				dir = llm.RepoPromptDir(*repoPath)
+			}
+			path, err := llm.CreatePromptTemplate(dir, name)
+			if err != nil && !errors.Is(err, llm.ErrPromptExists) {
+				return err
+			}
+			if err == nil {
+				fmt.Println(color.GreenString(i18n.T("cli.prompts.created", path)))
+			}
+			if err := runEditor(path); err != nil {
+				return err
+			}
+			if t := llm.FindPromptTemplate(*repoPath, name); t.Err != nil && t.Path == path {
+				fmt.Println(color.RedString(i18n.T("cli.prompts.invalid", t.Err)))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&shared, "shared", false, "Edit the repository's template in .difflearn/prompts instead of your own")
+	return cmd
+}
+
+// runEditor opens path in $VISUAL or $EDITOR and waits for it to close.
+func runEditor(path string) error {
+	editor := config.Setting("VISUAL")
+	if editor == "" {
+		editor = config.Setting("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// Like git, let the shell split the editor so it can carry arguments.
+	c := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	if runtime.GOOS == "windows" {
+		fields := strings.Fields(editor)
+		c = exec.Command(fields[0], append(fields[1:], path)...)
+	}
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+// promptSizeFiles caps the per-file lines of the report.
+const promptSizeFiles = 20
+
+func promptSizeCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
+	var kind string
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "prompt-size",
+		Short: "Estimate the tokens an AI request on local changes would send, file by file",
+		Long:  "Builds the prompt an AI command would send without sending it and reports its estimated size: the total, each file's share (largest first), whether DiffLearn would split or trim it under DIFFLEARN_CONTEXT_TOKENS, and which providers' context windows it fits. Trim the request with --path, e.g. --path internal/ or --path '!*.lock'. explain, review, summary and ask take --dry-run for the same report.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch kind {
+			case "explain", "review", "summary", "ask":
+			default:
+				return fmt.Errorf("unknown --kind %q (use explain, review, summary or ask)", kind)
+			}
+			g := git.NewGitExtractor(*repoPath)
+			formatter := git.NewDiffFormatter()
+			diffs, err := loadCommandDiffs(g, opts)
+			if err != nil {
+				return err
+			}
+			if len(diffs) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+				return nil
+			}
+			build, _, _, err := promptFor(*repoPath, kind, opts, formatter, diffs)
+			if err != nil {
+				return err
+			}
+			return printPromptSize(kind, llm.MeasurePrompt(config.LoadConfig(), formatter, diffs, build), jsonOut)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Measure only staged changes")
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&opts.Untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().StringSliceVar(&opts.Paths, "path", nil, "Only measure matching files (glob or directory; prefix with ! to exclude; repeatable)")
+	cmd.Flags().StringVar(&kind, "kind", "explain", "Prompt to measure: explain, review, summary or ask")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the report as JSON")
+	return cmd
+}
+
+func addPromptSizeFlags(cmd *cobra.Command, opts *llmCommandOptions) {
+	cmd.Flags().StringSliceVar(&opts.Paths, "path", nil, "Only include matching files (glob or directory; prefix with ! to exclude; repeatable)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Report the estimated prompt size instead of sending the request")
+}
+
+func printPromptSize(kind string, size llm.PromptSize, jsonOut bool) error {
+	if jsonOut {
+		b, err := json.MarshalIndent(size, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.promptSize.header", kind)))
+	fmt.Println(i18n.T("cli.promptSize.total", size.PromptTokens, size.DiffTokens, size.ResponseTokens))
+	fmt.Println()
+
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.promptSize.files")))
+	for i, f := range size.Files {
+		if i == promptSizeFiles {
+			fmt.Println(color.HiBlackString("  " + i18n.T("cli.promptSize.moreFiles", len(size.Files)-i)))
+			break
+		}
+		fmt.Printf("  %8d  %s %s %s\n", f.Tokens, f.File, color.GreenString("+%d", f.Additions), color.RedString("-%d", f.Deletions))
+	}
+	fmt.Println()
+
+	trim := false
+	if notice := size.Budget.Notice(); notice != "" {
+		fmt.Println(color.YellowString(notice))
+		trim = true
+	} else {
+		fmt.Println(color.GreenString(i18n.T("cli.promptSize.fitsBudget", size.Budget.BudgetTokens)))
+	}
+	fmt.Println()
+
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.promptSize.providers")))
+	for _, p := range size.Providers {
+		mark := color.GreenString("✔")
+		if !p.Fits {
+			mark = color.RedString("✘")
+			trim = trim || p.Active
+		}
+		active := ""
+		if p.Active {
+			active = color.CyanString(" (" + i18n.T("cli.promptSize.active") + ")")
+		}
+		fmt.Printf("  %s %-12s %-26s %9d%s\n", mark, p.Provider, p.Model, p.ContextWindow, active)
+	}
+	if trim {
+		fmt.Println("\n" + color.HiBlackString(i18n.T("cli.promptSize.trimHint")))
+	}
+	return nil
+}
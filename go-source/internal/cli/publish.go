@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+	"difflearn-go/internal/publish"
+)
+
+func publishCmd(repoPath *string) *cobra.Command {
+	var out, ticketArg string
+	var withAI []string
+	var noAI, noComments bool
+	cmd := &cobra.Command{
+		Use:   "publish <base> <target>",
+		Short: "Write a branch comparison with its AI sections as a static site",
+		Long:  "Renders what target changed since it forked from base, the commits, the stats, AI summary, explanation and review sections and AI line comments into --out: an index.html styled like the web UI, its styles.css and comparison.json with the same data. Upload the directory to any static host to share the comparison for review. Without an LLM the offline analysis fills the sections and the line comments are left out.",
+		Example: "  difflearn publish main feature --out review/\n" +
+			"  difflearn publish main HEAD --with-ai=summary --no-comments",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			base, target := args[0], args[1]
+			g := git.NewGitExtractor(*repoPath)
+			diffs, err := g.GetBranchDiff(base, target)
+			if err != nil {
+				return err
+			}
+			commits, err := g.GetCommitsInRange(base + ".." + target)
+			if err != nil {
+				return err
+			}
+			site := publish.Site{Base: base, Target: target, Commits: commits, Diffs: diffs, Generated: time.Now()}
+			if !noAI && len(diffs) > 0 {
+				opts := llmCommandOptions{Preloaded: diffs, Ref: base + "..." + target}
+				if err := loadTicket(ticketArg, &opts); err != nil {
+					return err
+				}
+				formatter := git.NewDiffFormatter()
+				sections, err := generateReportSections(*repoPath, withAI, opts, formatter, diffs)
+				if err != nil {
+					return err
+				}
+				for _, s := range sections {
+					site.Sections = append(site.Sections, publish.Section{Kind: s.Kind, Title: s.Title, Content: s.Content})
+				}
+				cfg := config.LoadConfig()
+				if !noComments && config.IsLLMAvailable(cfg) {
+					fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("tui.status.annotating")))
+					result, err := llm.Annotate(llm.NewClient(cfg).For("", "annotate"), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens))
+					// The sections are already paid for; publish them
+					// without comments rather than not at all.
+					if err != nil {
+						fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.publish.noComments", err)))
+					} else {
+						site.Annotations = result.Annotations
+					}
+				}
+			}
+			if err := publish.Write(out, site); err != nil {
+				return err
+			}
+			fmt.Println(color.GreenString(i18n.T("cli.publish.done", site.Title(), filepath.Join(out, "index.html"), len(diffs), len(commits))))
+			fmt.Println(color.HiBlackString(i18n.T("cli.publish.hint", out)))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&out, "out", "o", "difflearn-site", "Directory to write the site to")
+	cmd.Flags().StringSliceVar(&withAI, "with-ai", aiSections, "AI sections to include: summary, explain, review")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Leave out the AI sections and line comments")
+	cmd.Flags().BoolVar(&noComments, "no-comments", false, "Leave out the AI line comments")
+	addTicketFlag(cmd, &ticketArg)
+	return cmd
+}
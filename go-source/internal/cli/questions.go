@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func questionsCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
+	var base string
+	var count int
+	cmd := &cobra.Command{
+		Use:   "questions <sha|branch|ref1..ref2>",
+		Short: "Generate open-ended discussion questions about a change for code-reading sessions",
+		Long:  "Asks for questions about design trade-offs, alternative approaches, risks and testing, each with notes on what a good discussion should reach, instead of answers. A branch is compared with --base from their merge base. Use --out to save the questions as Markdown.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQuestions(*repoPath, args[0], base, count, opts)
+		},
+	}
+	cmd.Flags().StringVar(&base, "base", "", "Branch a branch argument is compared with (default: the repository's default branch)")
+	cmd.Flags().IntVarP(&count, "count", "n", 8, "Roughly how many questions to ask")
+	addOutputFlags(cmd, &opts)
+	return cmd
+}
+
+func runQuestions(repoPath, target, base string, count int, opts llmCommandOptions) error {
+	g := git.NewGitExtractor(repoPath)
+	formatter := git.NewDiffFormatter()
+	subject, logSpec, diffSpec, err := questionSubject(g, target, base)
+	if err != nil {
+		return err
+	}
+	commits, err := g.GetCommitsInRange(logSpec)
+	if err != nil {
+		return err
+	}
+	diffs, err := g.GetRangeDiff(diffSpec)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+		return nil
+	}
+	build := func(d []git.ParsedDiff) string {
+		return llm.CreateDiscussionPrompt(formatter, subject, commits, d, count)
+	}
+	cfg := config.LoadConfig()
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		fmt.Println(build(diffs))
+		return nil
+	}
+	content, err := streamLLMResponse(llm.NewClient(cfg), cfg, formatter, diffs, i18n.T("cli.label.questions", target), build)
+	if err != nil {
+		return err
+	}
+	opts.Preloaded = diffs
+	opts.Ref = target
+	return deliverResponse(g, cfg, "questions", opts, content)
+}
+
+// questionSubject describes target for the prompt and returns the revisions
+// to list commits and take the diff from: the commit itself, or a branch's
+// commits since it left base.
+func questionSubject(g *git.GitExtractor, target, base string) (string, string, string, error) {
+	if strings.Contains(target, "..") {
+		return "the revision range " + target, target, target, nil
+	}
+	branches, err := g.GetBranchesDetailed()
+	if err != nil {
+		return "", "", "", err
+	}
+	for _, b := range branches {
+		if b.Name == target {
+			base, err := resolveBase(g, base)
+			if err != nil {
+				return "", "", "", err
+			}
+			if target == base {
+				return "", "", "", fmt.Errorf("%s is the --base branch; pass another --base", target)
+			}
+			return fmt.Sprintf("the branch %s (compared with %s)", target, base), base + ".." + target, base + "..." + target, nil
+		}
+	}
+	hash, err := g.ResolveRef(target)
+	if err != nil {
+		return "", "", "", err
+	}
+	return "commit " + short(hash, 7), hash + "^!", hash, nil
+}
@@ -0,0 +1,358 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+	"difflearn-go/internal/llm"
+)
+
+func quizCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
+	var commit string
+	var count int
+	var history bool
+	cmd := &cobra.Command{
+		Use:   "quiz",
+		Short: "Quiz yourself on a diff with AI-written questions and track your scores",
+		Long:  "Asks the model for multiple-choice and short-answer questions about the local changes (or --commit), then asks them one at a time. Multiple-choice answers are checked right away; short answers are graded by the model. Each finished quiz's score is saved, and --history lists the scores for this repository.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if history {
+				return printQuizHistory(*repoPath)
+			}
+			return runQuiz(*repoPath, opts, commit, count)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Use only staged changes")
+	cmd.Flags().StringVar(&commit, "commit", "", "Quiz on a commit instead of local changes")
+	cmd.Flags().IntVarP(&count, "questions", "n", 5, "Number of questions")
+	cmd.Flags().BoolVar(&history, "history", false, "Show past quiz scores for this repository")
+	return cmd
+}
+
+func runQuiz(repoPath string, opts llmCommandOptions, commit string, count int) error {
+	g := git.NewGitExtractor(repoPath)
+	formatter := git.NewDiffFormatter()
+	var diffs []git.ParsedDiff
+	var err error
+	if commit != "" {
+		diffs, err = g.GetCommitDiff(commit, "")
+		opts.Ref = commit
+	} else {
+		opts.Smart = true
+		diffs, err = loadSmartDiffs(g, &opts)
+	}
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+		return nil
+	}
+	cfg := config.LoadConfig()
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		fmt.Println(llm.CreateQuizPrompt(formatter, diffs, count))
+		return nil
+	}
+
+	client := llm.NewClient(cfg).For("", "quiz")
+	fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.quiz.generating")))
+	questions, report, err := llm.GenerateQuiz(client, formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), count)
+	if err != nil {
+		return err
+	}
+	if notice := report.Notice(); notice != "" {
+		fmt.Fprintln(os.Stderr, color.YellowString(notice))
+	}
+
+	var correct, answered int
+	if isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		final, err := tea.NewProgram(newQuizModel(client, questions), programOptions()...).Run()
+		if err != nil {
+			return err
+		}
+		m := final.(quizModel)
+		correct, answered = m.score()
+	} else if correct, answered, err = runQuizLines(os.Stdin, os.Stdout, client, questions); err != nil {
+		return err
+	}
+	if answered == 0 {
+		return nil
+	}
+
+	score := learning.QuizScore{Repo: g.RepoPath(), Ref: describeRef(opts), Correct: correct, Total: answered}
+	store := learning.OpenQuizzes()
+	if err := store.Append(score); err != nil {
+		return err
+	}
+	learning.Record(g.RepoPath(), learning.KindQuiz, score.Ref)
+	scores, _ := store.Scores(g.RepoPath())
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.quiz.score", correct, answered, score.Percent())))
+	if len(scores) > 1 {
+		fmt.Println(color.HiBlackString(i18n.T("cli.quiz.average", len(scores), learning.QuizAverage(scores))))
+	}
+	return nil
+}
+
+func printQuizHistory(repoPath string) error {
+	repo := git.NewGitExtractor(repoPath).RepoPath()
+	scores, err := learning.OpenQuizzes().Scores(repo)
+	if err != nil {
+		return err
+	}
+	if len(scores) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.quiz.noHistory")))
+		return nil
+	}
+	for _, s := range scores {
+		fmt.Printf("%s  %-14s %2d/%-2d %3d%%\n", s.Time.Local().Format("2006-01-02 15:04"), s.Ref, s.Correct, s.Total, s.Percent())
+	}
+	fmt.Println(color.HiBlackString(i18n.T("cli.quiz.average", len(scores), learning.QuizAverage(scores))))
+	return nil
+}
+
+// gradeQuizAnswer checks answer, a choice number for multiple-choice
+// questions, and returns whether it is right and what to tell the learner.
+func gradeQuizAnswer(client llm.Chatter, q llm.QuizQuestion, answer string) (bool, string, error) {
+	if q.Type == llm.QuizChoice {
+		n, err := strconv.Atoi(strings.TrimSpace(answer))
+		right := err == nil && n-1 == q.Correct
+		feedback := q.Explanation
+		if !right {
+			feedback = i18n.T("cli.quiz.rightChoice", q.Correct+1, q.Choices[q.Correct]) + " " + q.Explanation
+		}
+		return right, feedback, nil
+	}
+	grade, err := llm.GradeShortAnswer(client, q, answer)
+	if err != nil {
+		return false, "", err
+	}
+	feedback := grade.Feedback
+	if !grade.Correct && q.Answer != "" && feedback != q.Answer {
+		feedback += "\n" + i18n.T("cli.quiz.modelAnswer", q.Answer)
+	}
+	return grade.Correct, feedback, nil
+}
+
+// runQuizLines asks the questions on plain input and output, for pipes and
+// screen readers that do without the TUI.
+func runQuizLines(in io.Reader, out io.Writer, client llm.Chatter, questions []llm.QuizQuestion) (int, int, error) {
+	reader := bufio.NewReader(in)
+	correct, answered := 0, 0
+	for i, q := range questions {
+		fmt.Fprintf(out, "\n%s\n%s\n", i18n.T("cli.quiz.question", i+1, len(questions)), q.Question)
+		for j, choice := range q.Choices {
+			fmt.Fprintf(out, "  %d) %s\n", j+1, choice)
+		}
+		fmt.Fprint(out, "> ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			break
+		}
+		right, feedback, err := gradeQuizAnswer(client, q, strings.TrimSpace(line))
+		if err != nil {
+			return correct, answered, err
+		}
+		answered++
+		if right {
+			correct++
+			fmt.Fprintln(out, color.GreenString("✔ "+i18n.T("cli.quiz.correct")))
+		} else {
+			fmt.Fprintln(out, color.RedString("✘ "+i18n.T("cli.quiz.wrong")))
+		}
+		if feedback != "" {
+			fmt.Fprintln(out, feedback)
+		}
+	}
+	fmt.Fprintln(out)
+	return correct, answered, nil
+}
+
+type quizGradeMsg struct {
+	right    bool
+	feedback string
+	err      error
+}
+
+type quizModel struct {
+	client    llm.Chatter
+	questions []llm.QuizQuestion
+	index     int
+	cursor    int
+	input     string
+	grading   bool
+	answered  bool
+	right     []bool
+	feedback  string
+	status    string
+	done      bool
+}
+
+func newQuizModel(client llm.Chatter, questions []llm.QuizQuestion) quizModel {
+	return quizModel{client: client, questions: questions}
+}
+
+func (m quizModel) Init() tea.Cmd { return nil }
+
+func (m quizModel) score() (int, int) {
+	correct := 0
+	for _, r := range m.right {
+		if r {
+			correct++
+		}
+	}
+	return correct, len(m.right)
+}
+
+func (m quizModel) submit() tea.Cmd {
+	q := m.questions[m.index]
+	answer := m.input
+	if q.Type == llm.QuizChoice {
+		answer = strconv.Itoa(m.cursor + 1)
+	}
+	return func() tea.Msg {
+		right, feedback, err := gradeQuizAnswer(m.client, q, answer)
+		return quizGradeMsg{right: right, feedback: feedback, err: err}
+	}
+}
+
+func (m quizModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case quizGradeMsg:
+		m.grading = false
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.status = ""
+		m.answered = true
+		m.right = append(m.right, msg.right)
+		m.feedback = msg.feedback
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc {
+			return m, tea.Quit
+		}
+		if m.grading {
+			return m, nil
+		}
+		if m.done || m.answered {
+			switch msg.String() {
+			case "q":
+				return m, tea.Quit
+			case "enter", "n", " ":
+				if m.done {
+					return m, tea.Quit
+				}
+				if m.index == len(m.questions)-1 {
+					m.done = true
+					return m, nil
+				}
+				m.index++
+				m.cursor, m.input, m.answered, m.feedback = 0, "", false, ""
+			}
+			return m, nil
+		}
+		q := m.questions[m.index]
+		if q.Type == llm.QuizChoice {
+			switch key := msg.String(); key {
+			case "q":
+				return m, tea.Quit
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < len(q.Choices)-1 {
+					m.cursor++
+				}
+			case "enter":
+				m.grading = true
+				return m, m.submit()
+			default:
+				if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= len(q.Choices) {
+					m.cursor = n - 1
+				}
+			}
+			return m, nil
+		}
+		switch msg.Type {
+		case tea.KeyRunes, tea.KeySpace:
+			m.input += string(msg.Runes)
+		case tea.KeyBackspace:
+			if r := []rune(m.input); len(r) > 0 {
+				m.input = string(r[:len(r)-1])
+			}
+		case tea.KeyEnter:
+			m.grading = true
+			m.status = i18n.T("cli.quiz.grading")
+			return m, m.submit()
+		}
+	}
+	return m, nil
+}
+
+func (m quizModel) View() string {
+	header := styled(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("13")), "🧠 DiffLearn • "+i18n.T("cli.quiz.title"))
+	if accessibleMode {
+		header = "DiffLearn, " + i18n.T("cli.quiz.title")
+	}
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	if m.done {
+		correct, total := m.score()
+		return fmt.Sprintf("%s\n\n%s\n\n%s", header, i18n.T("cli.quiz.finished", correct, total), styled(dim, i18n.T("cli.quiz.helpDone")))
+	}
+
+	q := m.questions[m.index]
+	var b strings.Builder
+	b.WriteString(header + "\n" + i18n.T("cli.quiz.question", m.index+1, len(m.questions)) + "\n\n")
+	b.WriteString(styled(lipgloss.NewStyle().Bold(true), q.Question) + "\n\n")
+	for i, choice := range q.Choices {
+		marker := "  "
+		if i == m.cursor {
+			marker = "› "
+			if accessibleMode {
+				marker = i18n.T("a11y.current") + " "
+			}
+		}
+		fmt.Fprintf(&b, "%s%d) %s\n", marker, i+1, choice)
+	}
+	if q.Type == llm.QuizShort {
+		b.WriteString("> " + m.input)
+		if !m.answered {
+			b.WriteString("█")
+		}
+		b.WriteString("\n")
+	}
+	help := i18n.T("cli.quiz.helpChoice")
+	if q.Type == llm.QuizShort {
+		help = i18n.T("cli.quiz.helpShort")
+	}
+	if m.answered {
+		verdict := styled(lipgloss.NewStyle().Foreground(lipgloss.Color("10")), "✔ "+i18n.T("cli.quiz.correct"))
+		if !m.right[len(m.right)-1] {
+			verdict = styled(lipgloss.NewStyle().Foreground(lipgloss.Color("9")), "✘ "+i18n.T("cli.quiz.wrong"))
+		}
+		b.WriteString("\n" + verdict + "\n" + m.feedback + "\n")
+		help = i18n.T("cli.quiz.helpNext")
+	}
+	if m.status != "" {
+		help = m.status + " • " + help
+	}
+	b.WriteString("\n" + styled(dim, help))
+	return b.String()
+}
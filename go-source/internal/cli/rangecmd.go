@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func rangeCmd(repoPath *string) *cobra.Command {
+	var perCommit bool
+	var noAI bool
+	cmd := &cobra.Command{
+		Use:   "range <ref1>..<ref2>",
+		Short: "Review an arbitrary revision range (branches, tags or SHAs)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRangeReview(*repoPath, args[0], perCommit, noAI)
+		},
+	}
+	cmd.Flags().BoolVar(&perCommit, "per-commit", false, "Review each commit separately instead of the combined diff")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Only list the commits and combined stats")
+	return cmd
+}
+
+func runRangeReview(repoPath, rangeSpec string, perCommit, noAI bool) error {
+	if !strings.Contains(rangeSpec, "..") {
+		return fmt.Errorf("expected a range like main..HEAD, got %q", rangeSpec)
+	}
+	g := git.NewGitExtractor(repoPath)
+	formatter := git.NewDiffFormatter()
+
+	commits, err := g.GetCommitsInRange(rangeSpec)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noCommitsInRange", rangeSpec)))
+		return nil
+	}
+
+	fmt.Println(color.New(color.Bold).Sprintf("%d commit(s) in %s:", len(commits), rangeSpec))
+	for _, c := range commits {
+		t, _ := time.Parse(time.RFC3339, c.Date)
+		fmt.Printf("  %s %s %s (%s)\n", color.YellowString(short(c.Hash, 7)), color.HiBlackString(t.Format("2006-01-02")), c.Message, color.HiBlackString(c.Author))
+	}
+	fmt.Println()
+
+	diffs, err := g.GetRangeDiff(rangeSpec)
+	if err != nil {
+		return err
+	}
+	stats := git.NewDiffParser().GetStats(diffs)
+	fmt.Printf("%d file(s) changed, %s %s\n\n", stats.Files, color.GreenString("+%d", stats.Additions), color.RedString("-%d", stats.Deletions))
+	if noAI {
+		return nil
+	}
+
+	cfg := config.LoadConfig()
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		fmt.Println(llm.CreateRangeReviewPrompt(formatter, rangeSpec, commits, diffs))
+		return nil
+	}
+	client := llm.NewClient(cfg)
+
+	if !perCommit {
+		build := func(d []git.ParsedDiff) string { return llm.CreateRangeReviewPrompt(formatter, rangeSpec, commits, d) }
+		_, err := streamLLMResponse(client, cfg, formatter, diffs, i18n.T("cli.label.rangeReview"), build)
+		return err
+	}
+
+	// Oldest first reads as a story of how the range evolved.
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		commitDiffs, err := g.GetCommitDiff(c.Hash, "")
+		if err != nil {
+			return err
+		}
+		if len(commitDiffs) == 0 {
+			continue
+		}
+		build := func(d []git.ParsedDiff) string { return llm.CreateReviewPrompt(formatter, d) }
+		label := i18n.T("cli.label.commitReview", short(c.Hash, 7), c.Message)
+		if _, err := streamLLMResponse(client, cfg, formatter, commitDiffs, label, build); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	return nil
+}
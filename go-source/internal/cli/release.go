@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func releaseCmd(repoPath *string) *cobra.Command {
+	var noAI bool
+	cmd := &cobra.Command{
+		Use:   "release [<tagA> [<tagB>]]",
+		Short: "Summarize the changes between two releases",
+		Long:  "Lists the commits between two tags (or any refs) with the combined stats and, when an LLM is configured, writes release notes from the diff. Without arguments it compares the two newest tags; with one it compares that tag with HEAD.",
+		Args:  cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRelease(*repoPath, args, noAI)
+		},
+	}
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Use the offline summary instead of asking the LLM")
+	return cmd
+}
+
+func runRelease(repoPath string, args []string, noAI bool) error {
+	g := git.NewGitExtractor(repoPath)
+	from, to, err := releaseRefs(g, args)
+	if err != nil {
+		return err
+	}
+	rangeSpec := from + ".." + to
+	commits, err := g.GetCommitsInRange(rangeSpec)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noCommitsInRange", rangeSpec)))
+		return nil
+	}
+	diffs, err := g.GetRangeDiff(rangeSpec)
+	if err != nil {
+		return err
+	}
+
+	authors := map[string]bool{}
+	for _, c := range commits {
+		authors[c.Author] = true
+	}
+	stats := git.NewDiffParser().GetStats(diffs)
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.release.header", from, to)))
+	fmt.Println(color.HiBlackString(i18n.T("cli.release.stats", len(commits), len(authors), stats.Files, stats.Additions, stats.Deletions)))
+	fmt.Println()
+	for _, c := range commits {
+		t, _ := time.Parse(time.RFC3339, c.Date)
+		fmt.Printf("  %s %s %s (%s)\n", color.YellowString(short(c.Hash, 7)), color.HiBlackString(t.Format("2006-01-02")), c.Message, color.HiBlackString(c.Author))
+	}
+	fmt.Println()
+
+	cfg := config.LoadConfig()
+	if noAI || !config.IsLLMAvailable(cfg) {
+		if !noAI {
+			fmt.Println(color.YellowString(i18n.T("cli.noLLMOffline")) + "\n")
+		}
+		fmt.Println(renderMarkdown(analysis.OfflineSummary(diffs)))
+		return nil
+	}
+	formatter := git.NewDiffFormatter()
+	build := func(d []git.ParsedDiff) string {
+		return llm.CreateReleaseSummaryPrompt(formatter, from, to, commits, d)
+	}
+	_, err = streamLLMResponse(llm.NewClient(cfg).For("", "summary"), cfg, formatter, diffs, i18n.T("cli.label.releaseNotes"), build)
+	return err
+}
+
+// releaseRefs picks the two releases to compare: the arguments, the given
+// tag and HEAD, or the two newest tags.
+func releaseRefs(g *git.GitExtractor, args []string) (string, string, error) {
+	switch len(args) {
+	case 2:
+		return args[0], args[1], nil
+	case 1:
+		return args[0], "HEAD", nil
+	}
+	tags, err := g.GetTags()
+	if err != nil {
+		return "", "", err
+	}
+	if len(tags) < 2 {
+		return "", "", fmt.Errorf("%s", i18n.T("cli.release.needTags", len(tags)))
+	}
+	return tags[1].Name, tags[0].Name, nil
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+// releaseNotes is a release described for its users: the changelog entries
+// that break, add or fix something, the public API the release changes and
+// the dependencies it changes.
+type releaseNotes struct {
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Title    string           `json:"title"`
+	Date     string           `json:"date"`
+	Breaking []changelogEntry `json:"breaking"`
+	Features []changelogEntry `json:"features"`
+	Fixes    []changelogEntry `json:"fixes"`
+	// API lists every public declaration the release adds, removes or
+	// changes; the removed and changed ones are the breaking ones.
+	API          []analysis.APIChange        `json:"api"`
+	Dependencies []analysis.DependencyChange `json:"dependencies"`
+}
+
+func releaseNotesCmd(repoPath *string) *cobra.Command {
+	var format, out, title string
+	var noAI bool
+	var notable int
+	cmd := &cobra.Command{
+		Use:   "release-notes [<tagA> [<tagB>]]",
+		Short: "Write release notes with breaking changes, features, fixes and dependency updates",
+		Long:  "Writes release notes for the changes between two tags (or any refs): the conventional-commit changelog of the range sorted into Breaking, Features and Fixes, the public API the release removes, changes or adds (exported Go names, JavaScript and TypeScript exports, Python top-level names, Rust pub items, public Java members), and the dependencies it adds, removes or updates. API removals and signature changes are listed as breaking even when no commit says so. Without arguments it compares the two newest tags; with one it compares that tag with HEAD. When an LLM is configured, the largest changes get a short description written from their diff.",
+		Example: "  difflearn release-notes v1.2.0 v1.3.0\n" +
+			"  difflearn release-notes v1.2.0 --format json -o notes.json",
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("invalid --format %q (use markdown or json)", format)
+			}
+			cmd.SilenceUsage = true
+			notes, err := buildReleaseNotes(*repoPath, args, title, notable, noAI)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				b, err := json.MarshalIndent(notes, "", "  ")
+				if err != nil {
+					return err
+				}
+				return writeReport(b, out)
+			}
+			return writeReport([]byte(releaseNotesMarkdown(notes)), out)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown or json")
+	cmd.Flags().StringVarP(&out, "output", "o", "", "Write the release notes to a file instead of stdout")
+	cmd.Flags().StringVar(&title, "title", "", "Heading of the release (default: <tagB>, or Unreleased for HEAD)")
+	cmd.Flags().IntVar(&notable, "notable", 5, "How many of the largest changes get an AI description")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Leave out the AI descriptions")
+	return cmd
+}
+
+func buildReleaseNotes(repoPath string, args []string, title string, notable int, noAI bool) (releaseNotes, error) {
+	g := git.NewGitExtractor(repoPath)
+	from, to, err := releaseRefs(g, args)
+	if err != nil {
+		return releaseNotes{}, err
+	}
+	report, err := buildChangelog(repoPath, from, to, title, notable, noAI)
+	if err != nil {
+		return releaseNotes{}, err
+	}
+	diffs, err := g.GetRangeDiff(from + ".." + to)
+	if err != nil {
+		return releaseNotes{}, err
+	}
+	notes := releaseNotes{
+		From: report.From, To: report.To, Title: report.Title, Date: report.Date,
+		Breaking: []changelogEntry{}, Features: []changelogEntry{}, Fixes: []changelogEntry{},
+		API:          analysis.DetectAPIChanges(diffs),
+		Dependencies: analysis.DetectDependencyChanges(diffs),
+	}
+	for _, s := range report.Sections {
+		switch s.Type {
+		case "breaking":
+			notes.Breaking = append(notes.Breaking, s.Entries...)
+		case "feat":
+			notes.Features = append(notes.Features, s.Entries...)
+		case "fix":
+			notes.Fixes = append(notes.Fixes, s.Entries...)
+		}
+	}
+	return notes, nil
+}
+
+func releaseNotesMarkdown(notes releaseNotes) string {
+	var breakingAPI, newAPI []analysis.APIChange
+	for _, c := range notes.API {
+		if c.Breaking() {
+			breakingAPI = append(breakingAPI, c)
+		} else {
+			newAPI = append(newAPI, c)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## " + notes.Title)
+	if notes.Date != "" {
+		sb.WriteString(" (" + notes.Date + ")")
+	}
+	sb.WriteString("\n\n")
+	empty := true
+	section := func(title string, entries []changelogEntry, extra []string) {
+		if len(entries) == 0 && len(extra) == 0 {
+			return
+		}
+		empty = false
+		sb.WriteString("### " + title + "\n\n")
+		for _, e := range entries {
+			writeChangelogEntry(&sb, e)
+		}
+		for _, line := range extra {
+			sb.WriteString("- " + line + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	var apiLines []string
+	for _, c := range breakingAPI {
+		if c.Kind == analysis.ChangeRemoved {
+			apiLines = append(apiLines, i18n.T("cli.releaseNotes.apiRemoved", c.Name, c.File))
+		} else {
+			apiLines = append(apiLines, i18n.T("cli.releaseNotes.apiChanged", c.Name, c.File, c.Old, c.New))
+		}
+	}
+	section(i18n.T("cli.changelog.section.breaking"), notes.Breaking, apiLines)
+
+	var newLines []string
+	for i := 0; i < len(newAPI); {
+		j, names := i, []string{}
+		for ; j < len(newAPI) && newAPI[j].File == newAPI[i].File; j++ {
+			names = append(names, "`"+newAPI[j].Name+"`")
+		}
+		newLines = append(newLines, i18n.T("cli.releaseNotes.apiAdded", newAPI[i].File, strings.Join(names, ", ")))
+		i = j
+	}
+	section(i18n.T("cli.changelog.section.feat"), notes.Features, newLines)
+	section(i18n.T("cli.changelog.section.fix"), notes.Fixes, nil)
+
+	var depLines []string
+	for _, d := range notes.Dependencies {
+		switch d.Kind {
+		case analysis.ChangeUpdated:
+			depLines = append(depLines, i18n.T("cli.releaseNotes.depUpdated", d.Name, d.OldVersion, d.NewVersion, d.Manifest))
+		case analysis.ChangeAdded:
+			depLines = append(depLines, i18n.T("cli.releaseNotes.depAdded", d.Name, d.NewVersion, d.Manifest))
+		default:
+			depLines = append(depLines, i18n.T("cli.releaseNotes.depRemoved", d.Name, d.OldVersion, d.Manifest))
+		}
+	}
+	section(i18n.T("cli.releaseNotes.dependencies"), nil, depLines)
+
+	if empty {
+		sb.WriteString(i18n.T("cli.releaseNotes.nothing", notes.From+".."+notes.To) + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func historyRevertsCmd(repoPath *string) *cobra.Command {
+	var number int
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "reverts [sha]",
+		Short: "List reverted changes and explain why one was reverted and how it came back",
+		Long:  "Finds commits that were reverted in the last --number commits, from the messages git revert writes and from patches that undo an older commit, together with the commit that applied the change again when there is one. Given a commit of such a pair, it shows the pair and the model explains why the change was probably reverted and what differed when it came back (offline, the git range-diff between the original and the reapplied commit).",
+		Example: "  difflearn history reverts\n" +
+			"  difflearn history reverts 3f2a9c1",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			g := git.NewGitExtractor(*repoPath)
+			pairs, err := g.FindRevertPairs(fmt.Sprintf("--max-count=%d", number))
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				if jsonOut {
+					return printJSON(pairs)
+				}
+				if len(pairs) == 0 {
+					fmt.Println(color.YellowString(i18n.T("cli.reverts.none", number)))
+					return nil
+				}
+				for i, p := range pairs {
+					if i > 0 {
+						fmt.Println()
+					}
+					printRevertPair(p)
+				}
+				return nil
+			}
+
+			sha, err := g.ResolveRef(args[0])
+			if err != nil {
+				return err
+			}
+			var pair *git.RevertPair
+			for i := range pairs {
+				if pairs[i].Role(sha) != "" {
+					pair = &pairs[i]
+					break
+				}
+			}
+			if pair == nil {
+				return fmt.Errorf("%s is not part of a revert in the last %d commits", short(sha, 7), number)
+			}
+			if jsonOut {
+				return printJSON(pair)
+			}
+			return explainRevertPair(g, *pair)
+		},
+	}
+	cmd.Flags().IntVarP(&number, "number", "n", 500, "Number of commits to search")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the pairs as JSON")
+	return cmd
+}
+
+// printRevertPair shows the commits of a revert pair under each other,
+// oldest first.
+func printRevertPair(p git.RevertPair) {
+	line := func(mark string, c git.CommitInfo, note string) {
+		if note != "" {
+			note = " " + color.HiBlackString(note)
+		}
+		fmt.Printf("%s%s %s%s\n", mark, color.YellowString(short(c.Hash, 7)), c.Message, note)
+	}
+	line("", p.Original, "")
+	line("  ↩ ", p.Revert, i18n.T("cli.reverts.match."+p.Match))
+	if p.Reapply != nil {
+		line("  ↪ ", *p.Reapply, "")
+	}
+}
+
+// revertNote is how the history listing marks a commit that is part of a
+// revert pair, or "".
+func revertNote(pairs []git.RevertPair, hash string) string {
+	for _, p := range pairs {
+		switch p.Role(hash) {
+		case "original":
+			if p.Reapply != nil {
+				return i18n.T("cli.reverts.noteReapplied", short(p.Revert.Hash, 7), short(p.Reapply.Hash, 7))
+			}
+			return i18n.T("cli.reverts.noteReverted", short(p.Revert.Hash, 7))
+		case "revert":
+			return i18n.T("cli.reverts.noteRevert", short(p.Original.Hash, 7))
+		case "reapply":
+			return i18n.T("cli.reverts.noteReapply", short(p.Original.Hash, 7))
+		}
+	}
+	return ""
+}
+
+func explainRevertPair(g *git.GitExtractor, p git.RevertPair) error {
+	printRevertPair(p)
+	fmt.Println()
+	original, err := g.GetCommitDiff(p.Original.Hash, "")
+	if err != nil {
+		return err
+	}
+	var rangeDiff string
+	if p.Reapply != nil {
+		if rangeDiff, err = g.RangeDiff(p.Original.Hash, p.Reapply.Hash); err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString(err.Error()))
+		}
+	}
+	cfg := config.LoadConfig()
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		if p.Reapply != nil {
+			fmt.Println()
+			if strings.TrimSpace(rangeDiff) == "" {
+				fmt.Println(i18n.T("cli.reverts.samePatch"))
+			} else {
+				fmt.Println(rangeDiff)
+			}
+		}
+		return nil
+	}
+	formatter := git.NewDiffFormatter()
+	build := func(d []git.ParsedDiff) string {
+		return llm.CreateRevertPrompt(formatter, p, d, rangeDiff)
+	}
+	_, err = streamLLMResponse(llm.NewClient(cfg).For("", "reverts"), cfg, formatter, original, i18n.T("cli.label.revert"), build)
+	return err
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+	"difflearn-go/internal/llm"
+)
+
+// runStructuredReview asks for the review as JSON and prints it as a table.
+// With --raw the parsed result is printed as JSON for scripts. It returns
+// what was printed, in a form suitable for --out and --copy, and the parsed
+// result, which is nil when the answer was not valid JSON.
+func runStructuredReview(client *llm.Client, cfg config.Config, formatter *git.DiffFormatter, diffs []git.ParsedDiff, label string, build func([]git.ParsedDiff) string) (string, *llm.ReviewResult, error) {
+	fmt.Println(color.HiBlackString(i18n.T("cli.generating")))
+	resp, report, err := llm.RunBudgeted(client, formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), build)
+	if err != nil {
+		return "", nil, err
+	}
+	if notice := report.Notice(); notice != "" {
+		fmt.Println(color.YellowString(notice) + "\n")
+	}
+	result, err := llm.ParseReviewResult(resp.Content)
+	if err != nil {
+		fmt.Println(color.YellowString(i18n.T("cli.structuredFallback")) + "\n")
+		fmt.Println(renderMarkdown(resp.Content))
+		return resp.Content, nil, nil
+	}
+	if rawOutput {
+		b, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(b))
+		return string(b), &result, nil
+	}
+	fmt.Printf("%s\n\n", color.GreenString("📝 "+label+":"))
+	printReviewResult(result)
+	return llm.FormatReviewMarkdown(result), &result, nil
+}
+
+// runMachineReview prints the pre-check findings and a structured review as
+// JSON or SARIF and nothing else, so the output can be piped or uploaded.
+// --out writes the file as is. Without an LLM only the findings are
+// reported.
+func runMachineReview(repoPath, format string, opts llmCommandOptions, version string) error {
+	cfg, err := withCommandOverrides(config.LoadConfig(), opts)
+	if err != nil {
+		return err
+	}
+	g := git.NewGitExtractor(repoPath)
+	diffs, err := loadCommandDiffs(g, opts)
+	if err != nil {
+		return err
+	}
+	findings := analysis.RunRules(diffs)
+	var result *llm.ReviewResult
+	gates := []string{}
+	if len(diffs) > 0 {
+		if !config.IsLLMAvailable(cfg) {
+			fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.noLLMOffline")))
+		} else {
+			review, failed, notice, err := quietStructuredReview(repoPath, cfg, diffs, findings, opts.Ticket)
+			if err != nil {
+				return err
+			}
+			if notice != "" {
+				fmt.Fprintln(os.Stderr, color.YellowString(notice))
+			}
+			result, gates = &review, failed
+			learning.Record(g.RepoPath(), "review", opts.Ref)
+		}
+	}
+	var b []byte
+	if format == "sarif" {
+		b, err = llm.ToSARIF(findings, result, version)
+	} else {
+		b, err = json.MarshalIndent(map[string]any{"findings": findings, "review": result, "failedGates": gates}, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeReport(b, opts.Out); err != nil {
+		return err
+	}
+	return checkFailOn(findings, result, opts.FailOn)
+}
+
+// checkFailOn returns an error when pre-check findings or review issues reach
+// threshold, so `review --fail-on` can gate commits and CI. An empty
+// threshold never fails.
+func checkFailOn(findings []analysis.Finding, result *llm.ReviewResult, threshold analysis.Severity) error {
+	if threshold == "" {
+		return nil
+	}
+	if blocking := countBlocking(findings, result, threshold); blocking > 0 {
+		return fmt.Errorf("%s", i18n.T("cli.reviewBlocked", blocking, threshold))
+	}
+	return nil
+}
+
+func countBlocking(findings []analysis.Finding, result *llm.ReviewResult, threshold analysis.Severity) int {
+	blocking := 0
+	for _, f := range findings {
+		if analysis.SeverityAtLeast(f.Severity, threshold) {
+			blocking++
+		}
+	}
+	if result != nil {
+		for _, issue := range result.Issues {
+			if analysis.SeverityAtLeast(issue.Severity, threshold) {
+				blocking++
+			}
+		}
+	}
+	return blocking
+}
+
+func printReviewResult(result llm.ReviewResult) {
+	if result.Summary != "" {
+		fmt.Println(result.Summary + "\n")
+	}
+	if len(result.Issues) == 0 {
+		fmt.Println(color.GreenString("✅ " + i18n.T("cli.noIssues")))
+		return
+	}
+	width := len("location")
+	for _, issue := range result.Issues {
+		width = max(width, len(issue.Location()))
+	}
+	fmt.Println(color.New(color.Bold).Sprintf("  %-11s %-*s %s", "SEVERITY", width, "LOCATION", "ISSUE"))
+	for _, issue := range result.Issues {
+		fmt.Printf("  %s %s %s\n", severityLabel(issue.Severity, 11), color.CyanString("%-*s", width, issue.Location()), issue.Message)
+		if issue.Suggestion != "" {
+			fmt.Printf("  %s %s\n", strings.Repeat(" ", 11+1+width), color.HiBlackString("↳ "+issue.Suggestion))
+		}
+	}
+	counts := result.CountBySeverity()
+	fmt.Printf("\n%s\n", i18n.T("cli.issueCounts", counts[analysis.SeverityCritical], counts[analysis.SeverityImportant], counts[analysis.SeverityMinor]))
+}
+
+// severityLabel colors a severity, padding it to width before coloring so
+// columns stay aligned.
+func severityLabel(s analysis.Severity, width int) string {
+	label := fmt.Sprintf("%-*s", width, "["+string(s)+"]")
+	switch s {
+	case analysis.SeverityCritical:
+		return color.New(color.FgRed, color.Bold).Sprint(label)
+	case analysis.SeverityImportant:
+		return color.YellowString(label)
+	default:
+		return color.HiBlackString(label)
+	}
+}
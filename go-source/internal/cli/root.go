@@ -1,19 +1,24 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"difflearn-go/internal/aihistory"
+	"difflearn-go/internal/analysis"
 	"difflearn-go/internal/api"
 	"difflearn-go/internal/config"
 	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
 	"difflearn-go/internal/llm"
 	"difflearn-go/internal/mcp"
 	"difflearn-go/internal/update"
@@ -21,28 +26,143 @@ import (
 
 func NewRootCmd() *cobra.Command {
 	var repoPath string
+	var uiLang string
+	var accessible bool
+	var diffAlgorithm string
+	var noSyntax bool
+	var raw bool
+	var level string
+	var language string
+	var profile string
+	var verbose bool
 	root := &cobra.Command{
 		Use:     "difflearn",
 		Short:   "Interactive git diff learning tool with LLM-powered explanations",
 		Version: "0.3.0-go",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.UseProfile(config.ChooseProfile(profile, repoPath)); err != nil && cmd.Annotations["newProfile"] == "" {
+				cmd.SilenceUsage = true
+				return err
+			}
+			// Flag defaults were read before the profile applied.
+			flags := cmd.Flags()
+			if !flags.Changed("ui-lang") {
+				uiLang = config.UILanguage()
+			}
+			if !flags.Changed("accessible") {
+				accessible = config.Accessible()
+			}
+			if !flags.Changed("no-syntax") {
+				noSyntax = !config.SyntaxHighlight()
+			}
+			if !flags.Changed("level") {
+				level = config.Level()
+			}
+			if !flags.Changed("lang") {
+				language = config.Language()
+			}
+			if !flags.Changed("diff-algorithm") {
+				diffAlgorithm = config.DiffAlgorithm()
+			}
+			i18n.SetLocale(i18n.Detect(uiLang))
+			llm.OnFallback = func(failed, answered config.Config, err error) {
+				reason, _, _ := strings.Cut(err.Error(), "\n")
+				fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.fallback", failed.Provider, answered.Provider, answered.Model, short(reason, 200))))
+			}
+			if verbose {
+				retries := config.Retries()
+				llm.OnRetry = func(cfg config.Config, retry int, wait time.Duration, err error) {
+					reason, _, _ := strings.Cut(err.Error(), "\n")
+					fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.retry", cfg.Provider, short(reason, 200), wait.Round(100*time.Millisecond), retry, retries)))
+				}
+			}
+			setAccessible(accessible)
+			syntaxHighlight = !noSyntax
+			rawOutput = raw
+			algorithm, err := git.ParseDiffAlgorithm(diffAlgorithm)
+			if err != nil {
+				return err
+			}
+			git.SetDefaultDiffAlgorithm(algorithm)
+			git.SetCacheDir(config.GitCacheDir())
+			audience, err := llm.ParseLevel(level)
+			if err != nil {
+				return err
+			}
+			llm.SetDefaultLevel(audience)
+			llm.SetDefaultLanguage(language)
+			llm.UseRepository(repoPath)
+			return firstRunSetup(cmd)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return RunDashboard(repoPath)
 		},
 	}
 	root.PersistentFlags().StringVar(&repoPath, "repo", ".", "Repository path")
+	root.PersistentFlags().StringVar(&uiLang, "ui-lang", config.UILanguage(), "Interface language ("+strings.Join(i18n.Supported(), ", ")+"); defaults to the system locale")
+	root.PersistentFlags().BoolVar(&accessible, "accessible", config.Accessible(), "Screen-reader friendly output: no color or box drawing, spelled-out changes")
+	root.PersistentFlags().BoolVar(&noSyntax, "no-syntax", !config.SyntaxHighlight(), "Disable syntax highlighting of diff content")
+	root.PersistentFlags().BoolVar(&raw, "raw", false, "Print AI answers as raw markdown instead of rendering them")
+	root.PersistentFlags().StringVar(&level, "level", config.Level(), "Audience for AI answers: beginner, intermediate or expert (default from DIFFLEARN_LEVEL)")
+	root.PersistentFlags().StringVar(&language, "lang", config.Language(), "Language AI answers are written in, a name or a code such as de (default from DIFFLEARN_LANGUAGE)")
+	root.PersistentFlags().BoolVar(&noServer, "no-server", false, "Run AI commands in this process even when a DiffLearn web server is running for the repository")
+	root.PersistentFlags().StringVar(&profile, "profile", "", "Settings profile of ~/.difflearn to use (default from DIFFLEARN_PROFILE or the repository's .difflearn.yaml)")
+	root.PersistentFlags().BoolVar(&verbose, "verbose", false, "Report retries of rate-limited or failed AI requests on stderr")
+	root.PersistentFlags().StringVar(&diffAlgorithm, "diff-algorithm", config.DiffAlgorithm(), "Diff algorithm: myers, minimal, patience or histogram")
 
 	root.AddCommand(localCmd(&repoPath))
 	root.AddCommand(commitCmd(&repoPath))
 	root.AddCommand(branchCmd(&repoPath))
+	root.AddCommand(rangeCmd(&repoPath))
 	root.AddCommand(explainCmd(&repoPath))
 	root.AddCommand(reviewCmd(&repoPath))
 	root.AddCommand(summaryCmd(&repoPath))
+	root.AddCommand(askCmd(&repoPath))
+	root.AddCommand(chatCmd(&repoPath))
+	root.AddCommand(annotateCmd(&repoPath))
+	root.AddCommand(againCmd(&repoPath))
+	root.AddCommand(sessionsCmd(&repoPath))
+	root.AddCommand(teachCmd(&repoPath))
+	root.AddCommand(questionsCmd(&repoPath))
+	root.AddCommand(issuesCmd(&repoPath))
+	root.AddCommand(conflictsCmd(&repoPath))
+	root.AddCommand(hooksCmd(&repoPath))
+	root.AddCommand(ciCmd(&repoPath))
+	root.AddCommand(ownersCmd(&repoPath))
+	root.AddCommand(streakCmd())
+	root.AddCommand(usageCmd())
+	root.AddCommand(precommitCmd(&repoPath))
+	root.AddCommand(releaseCmd(&repoPath))
+	root.AddCommand(changelogCmd(&repoPath))
+	root.AddCommand(releaseNotesCmd(&repoPath))
+	root.AddCommand(storyCmd(&repoPath))
+	root.AddCommand(quizCmd(&repoPath))
+	root.AddCommand(flashcardsCmd(&repoPath))
+	root.AddCommand(promptSizeCmd(&repoPath))
+	root.AddCommand(teamCmd(&repoPath))
+	root.AddCommand(checkCmd(&repoPath))
 	root.AddCommand(exportCmd(&repoPath))
+	root.AddCommand(importCmd(&repoPath))
+	root.AddCommand(compareCmd(&repoPath))
 	root.AddCommand(historyCmd(&repoPath))
+	root.AddCommand(fileCmd(&repoPath))
+	root.AddCommand(stashCmd(&repoPath))
+	root.AddCommand(prCmd(&repoPath))
 	root.AddCommand(webCmd(&repoPath))
 	root.AddCommand(configCmd())
+	root.AddCommand(modelsCmd())
+	root.AddCommand(setupCmd())
+	root.AddCommand(authCmd())
 	root.AddCommand(mcpCmd(&repoPath))
 	root.AddCommand(updateCmd())
+	root.AddCommand(benchCmd(&repoPath))
+	root.AddCommand(promptsCmd(&repoPath))
+	root.AddCommand(backupCmd())
+	root.AddCommand(warmupCmd(&repoPath))
+	root.AddCommand(jjCmd(&repoPath))
+	root.AddCommand(hgCmd(&repoPath))
+	root.AddCommand(publishCmd(&repoPath))
+	root.AddCommand(exerciseCmd(&repoPath))
 
 	return root
 }
@@ -52,144 +172,331 @@ func Execute() error { return NewRootCmd().Execute() }
 func localCmd(repoPath *string) *cobra.Command {
 	var staged bool
 	var noInteractive bool
+	var against string
+	var untracked bool
+	var recurse bool
+	var all bool
+	var renames git.RenameDetection
+	var whitespace git.WhitespaceOptions
 	cmd := &cobra.Command{
 		Use:   "local",
 		Short: "View local uncommitted changes interactively",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !noInteractive {
+			if all && (staged || against != "") {
+				return fmt.Errorf("--all already includes staged changes and compares with HEAD; drop --staged and --against")
+			}
+			if !noInteractive && !all && against == "" && !untracked && !recurse && renames == (git.RenameDetection{}) && whitespace == (git.WhitespaceOptions{}) {
 				return RunDashboard(*repoPath)
 			}
 			g := git.NewGitExtractor(*repoPath)
 			formatter := git.NewDiffFormatter()
-			diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: staged})
+			diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: staged, Against: against, IncludeUntracked: untracked, RecurseSubmodules: recurse, Renames: renames, Whitespace: whitespace, All: all})
 			if err != nil {
 				return err
 			}
-			fmt.Println(formatter.ToTerminal(diffs, git.FormatterOptions{}))
+			fmt.Println(formatter.ToTerminal(diffs, terminalOptions()))
 			return nil
 		},
 	}
 	cmd.Flags().BoolVarP(&staged, "staged", "s", false, "View only staged changes")
 	cmd.Flags().BoolVar(&noInteractive, "no-interactive", false, "Print diff without interactive mode")
+	cmd.Flags().StringVar(&against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().BoolVar(&recurse, "recurse-submodules", false, "Include the changes inside modified submodules")
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Show staged, unstaged and untracked changes together, each file labeled with where it lives")
+	addRenameFlags(cmd, &renames)
+	addWhitespaceFlags(cmd, &whitespace)
 	return cmd
 }
 
 func commitCmd(repoPath *string) *cobra.Command {
 	var compare string
 	var noInteractive bool
+	var recurse bool
+	var renames git.RenameDetection
+	var whitespace git.WhitespaceOptions
 	cmd := &cobra.Command{
 		Use:   "commit <sha>",
 		Short: "View changes in a specific commit",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !noInteractive {
+			if !noInteractive && !recurse && renames == (git.RenameDetection{}) && whitespace == (git.WhitespaceOptions{}) {
 				return RunCommitView(*repoPath, args[0], compare)
 			}
 			g := git.NewGitExtractor(*repoPath)
-			diffs, err := g.GetCommitDiff(args[0], compare)
+			diffs, err := g.GetCommitDiffWithOptions(args[0], compare, git.DiffOptions{Renames: renames, Whitespace: whitespace})
 			if err != nil {
 				return err
 			}
-			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, git.FormatterOptions{}))
+			recordCommitStudied(g, args[0])
+			if recurse {
+				diffs = g.ExpandSubmodules(diffs)
+			}
+			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
 			return nil
 		},
 	}
 	cmd.Flags().StringVarP(&compare, "compare", "c", "", "Compare with another commit")
 	cmd.Flags().BoolVar(&noInteractive, "no-interactive", false, "Print diff without interactive mode")
+	cmd.Flags().BoolVar(&recurse, "recurse-submodules", false, "Include the changes inside modified submodules")
+	addRenameFlags(cmd, &renames)
+	addWhitespaceFlags(cmd, &whitespace)
 	return cmd
 }
 
 func branchCmd(repoPath *string) *cobra.Command {
 	var noInteractive bool
+	var recurse bool
+	var againstDefault bool
+	var renames git.RenameDetection
+	var whitespace git.WhitespaceOptions
 	cmd := &cobra.Command{
 		Use:   "branch <branch1> <branch2>",
-		Short: "Compare two branches",
-		Args:  cobra.ExactArgs(2),
+		Short: "Compare two branches (or tags)",
+		Long:  "Shows the changes on branch2 since it forked from branch1. Either side may also be a tag or commit. With --against-default, branch1 is the repository's default branch and branch2, if given at all, is the only argument (HEAD otherwise).",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if againstDefault {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !noInteractive {
+			if againstDefault {
+				base, err := resolveBase(git.NewGitExtractor(*repoPath), "")
+				if err != nil {
+					return err
+				}
+				target := "HEAD"
+				if len(args) == 1 {
+					target = args[0]
+				}
+				args = []string{base, target}
+			}
+			if !noInteractive && !recurse && renames == (git.RenameDetection{}) && whitespace == (git.WhitespaceOptions{}) {
 				return RunBranchView(*repoPath, args[0], args[1])
 			}
 			g := git.NewGitExtractor(*repoPath)
-			diffs, err := g.GetBranchDiff(args[0], args[1])
+			diffs, err := g.GetBranchDiffWithOptions(args[0], args[1], git.BranchModeTriple, git.DiffOptions{Renames: renames, Whitespace: whitespace})
 			if err != nil {
 				return err
 			}
-			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, git.FormatterOptions{}))
+			if recurse {
+				diffs = g.ExpandSubmodules(diffs)
+			}
+			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&noInteractive, "no-interactive", false, "Print diff without interactive mode")
+	cmd.Flags().BoolVar(&recurse, "recurse-submodules", false, "Include the changes inside modified submodules")
+	cmd.Flags().BoolVar(&againstDefault, "against-default", false, "Compare with the repository's default branch (origin/HEAD or init.defaultBranch)")
+	addRenameFlags(cmd, &renames)
+	addWhitespaceFlags(cmd, &whitespace)
 	return cmd
 }
 
+// resolveBase returns base, or when it is empty the repository's default
+// branch, as origin/<name> when there is no local branch of that name.
+func resolveBase(g *git.GitExtractor, base string) (string, error) {
+	if base != "" {
+		return base, nil
+	}
+	name := g.DefaultBranch()
+	if name == "" {
+		return "", fmt.Errorf("cannot detect the default branch (no origin/HEAD, init.defaultBranch, main or master); name the base branch explicitly")
+	}
+	if _, err := g.ResolveRef(name); err != nil {
+		return "origin/" + name, nil
+	}
+	return name, nil
+}
+
 func explainCmd(repoPath *string) *cobra.Command {
-	var staged bool
+	var opts llmCommandOptions
+	var ticket string
+	var stdin bool
 	cmd := &cobra.Command{
-		Use:   "explain",
+		Use:   "explain [-]",
 		Short: "Get an AI explanation of local changes",
+		Long:  "Explains the working tree changes, or with - or --stdin a unified diff piped in, e.g. git diff main... | difflearn explain -",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runLLMCommand(*repoPath, staged, "explain")
+			if err := loadStdinDiff(stdin, args, &opts); err != nil {
+				return err
+			}
+			if err := loadTicket(ticket, &opts); err != nil {
+				return err
+			}
+			return runLLMCommand(*repoPath, "explain", opts)
 		},
 	}
-	cmd.Flags().BoolVarP(&staged, "staged", "s", false, "Explain only staged changes")
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Explain only staged changes")
+	addSmartFlag(cmd, &opts, true)
+	addPromptSizeFlags(cmd, &opts)
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&opts.Untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().BoolVar(&opts.RecurseSubmodules, "recurse-submodules", false, "Include the changes inside modified submodules")
+	addRenameFlags(cmd, &opts.Renames)
+	addWhitespaceFlags(cmd, &opts.Whitespace)
+	cmd.Flags().StringVar(&opts.File, "file", "", "Explain changes to a single file")
+	cmd.Flags().BoolVar(&opts.Narrative, "narrative", false, "Tell the change as its author would, step by step in the order it was likely written")
+	cmd.Flags().StringSliceVar(&opts.CompareModels, "compare-models", nil, "Run the same prompt against several models side by side (e.g. gpt-4o,claude-sonnet)")
+	addStdinFlag(cmd, &stdin)
+	addTicketFlag(cmd, &ticket)
+	addOutputFlags(cmd, &opts)
 	return cmd
 }
 
 func reviewCmd(repoPath *string) *cobra.Command {
-	var staged bool
+	var opts llmCommandOptions
+	var failOn, format, ticket string
+	var stdin, refine bool
 	cmd := &cobra.Command{
-		Use:   "review",
+		Use:   "review [-]",
 		Short: "Get an AI code review of local changes",
+		Long:  "Reviews the working tree changes, or with - or --stdin a unified diff piped in, e.g. git diff main... | difflearn review -",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runLLMCommand(*repoPath, staged, "review")
+			if err := loadStdinDiff(stdin, args, &opts); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("refine") {
+				opts.Refine = &refine
+			}
+			if failOn != "" {
+				threshold, ok := analysis.ParseSeverity(failOn)
+				if !ok {
+					return fmt.Errorf("invalid --fail-on %q (use critical, important or minor)", failOn)
+				}
+				opts.FailOn = threshold
+				opts.Structured = true
+			}
+			// A blocked review is an expected outcome, not a usage error.
+			cmd.SilenceUsage = true
+			if err := loadTicket(ticket, &opts); err != nil {
+				return err
+			}
+			switch format {
+			case "text":
+				return runLLMCommand(*repoPath, "review", opts)
+			case "json", "sarif":
+				return runMachineReview(*repoPath, format, opts, cmd.Root().Version)
+			}
+			return fmt.Errorf("invalid --format %q (use text, json or sarif)", format)
 		},
 	}
-	cmd.Flags().BoolVarP(&staged, "staged", "s", false, "Review only staged changes")
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Review only staged changes")
+	addSmartFlag(cmd, &opts, false)
+	addPromptSizeFlags(cmd, &opts)
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&opts.Untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().BoolVar(&opts.RecurseSubmodules, "recurse-submodules", false, "Include the changes inside modified submodules")
+	addRenameFlags(cmd, &opts.Renames)
+	addWhitespaceFlags(cmd, &opts.Whitespace)
+	cmd.Flags().BoolVar(&refine, "refine", false, "Run a second pass that checks the review against the diff (default from DIFFLEARN_REFINE_REVIEW)")
+	cmd.Flags().BoolVar(&opts.ShowPrompt, "show-prompt", false, "Without an LLM, also print the review prompt to paste into one")
+	cmd.Flags().BoolVar(&opts.Structured, "structured", false, "Ask for issues with file, line, severity and suggestion and print them as a table (JSON with --raw)")
+	cmd.Flags().StringVar(&failOn, "fail-on", "", "Exit with an error when findings or issues reach this severity (critical, important, minor); implies --structured")
+	addStdinFlag(cmd, &stdin)
+	addTicketFlag(cmd, &ticket)
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text, json or sarif (for code scanning uploads); json and sarif imply --structured and print nothing else")
+	addOutputFlags(cmd, &opts)
 	return cmd
 }
 
 func summaryCmd(repoPath *string) *cobra.Command {
-	var staged bool
+	var opts llmCommandOptions
+	var stdin bool
 	cmd := &cobra.Command{
-		Use:   "summary",
+		Use:   "summary [-]",
 		Short: "Get a quick summary of changes",
+		Long:  "Summarizes the working tree changes, or with - or --stdin a unified diff piped in.",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runLLMCommand(*repoPath, staged, "summary")
+			if err := loadStdinDiff(stdin, args, &opts); err != nil {
+				return err
+			}
+			return runLLMCommand(*repoPath, "summary", opts)
 		},
 	}
-	cmd.Flags().BoolVarP(&staged, "staged", "s", false, "Summarize only staged changes")
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Summarize only staged changes")
+	addSmartFlag(cmd, &opts, true)
+	addPromptSizeFlags(cmd, &opts)
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&opts.Untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().BoolVar(&opts.RecurseSubmodules, "recurse-submodules", false, "Include the changes inside modified submodules")
+	addRenameFlags(cmd, &opts.Renames)
+	addWhitespaceFlags(cmd, &opts.Whitespace)
+	addStdinFlag(cmd, &stdin)
+	addOutputFlags(cmd, &opts)
 	return cmd
 }
 
-func exportCmd(repoPath *string) *cobra.Command {
-	var staged bool
-	var format string
+func askCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
 	cmd := &cobra.Command{
-		Use:   "export",
-		Short: "Export diff in various formats",
+		Use:   "ask <question>",
+		Short: "Ask a question about local changes",
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			g := git.NewGitExtractor(*repoPath)
-			formatter := git.NewDiffFormatter()
-			diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: staged})
+			opts.Question = strings.Join(args, " ")
+			return runLLMCommand(*repoPath, "ask", opts)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Ask about staged changes only")
+	addSmartFlag(cmd, &opts, false)
+	addPromptSizeFlags(cmd, &opts)
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&opts.Untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().StringVar(&opts.File, "file", "", "Limit the question to changes in a single file")
+	addOutputFlags(cmd, &opts)
+	return cmd
+}
+
+func checkCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Run offline static pre-checks on local changes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			diffs, err := loadCommandDiffs(git.NewGitExtractor(*repoPath), opts)
 			if err != nil {
 				return err
 			}
-			switch format {
-			case "json":
-				fmt.Println(formatter.ToJSON(diffs))
-			case "terminal":
-				fmt.Println(formatter.ToTerminal(diffs, git.FormatterOptions{}))
-			default:
-				fmt.Println(formatter.ToMarkdown(diffs))
+			findings := analysis.RunRules(diffs)
+			if len(findings) == 0 {
+				fmt.Println(color.GreenString("✅ " + i18n.T("cli.noFindings")))
+				return nil
 			}
+			printFindings(findings)
 			return nil
 		},
 	}
-	cmd.Flags().StringVarP(&format, "format", "f", "markdown", "Output format: json, markdown, terminal")
-	cmd.Flags().BoolVarP(&staged, "staged", "s", false, "Export only staged changes")
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Check only staged changes")
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Compare the working tree against any ref (e.g. origin/main)")
+	cmd.Flags().BoolVarP(&opts.Untracked, "untracked", "u", false, "Include untracked files as new files")
+	cmd.Flags().BoolVar(&opts.RecurseSubmodules, "recurse-submodules", false, "Include the changes inside modified submodules")
+	addRenameFlags(cmd, &opts.Renames)
+	addWhitespaceFlags(cmd, &opts.Whitespace)
 	return cmd
 }
 
+func printFindings(findings []analysis.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Printf("%s\n\n", color.GreenString("🔎 "+i18n.T("cli.findings")))
+	for _, f := range findings {
+		label := severityLabel(f.Severity, 0)
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Printf("  %s %s %s %s\n", label, color.CyanString(location), f.Message, color.HiBlackString("("+f.Rule+")"))
+	}
+	fmt.Println()
+}
+
 func historyCmd(repoPath *string) *cobra.Command {
 	var number int
 	cmd := &cobra.Command{
@@ -201,45 +508,141 @@ func historyCmd(repoPath *string) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			// Marking reverts is a bonus; the listing stands without it.
+			pairs, _ := g.FindRevertPairs(fmt.Sprintf("--max-count=%d", number))
 			for _, c := range commits {
 				t, _ := time.Parse(time.RFC3339, c.Date)
-				fmt.Printf("%s %s %s (%s)\n", color.YellowString(short(c.Hash, 7)), color.HiBlackString(t.Format("2006-01-02")), c.Message, color.HiBlackString(c.Author))
+				note := ""
+				if n := revertNote(pairs, c.Hash); n != "" {
+					note = " " + color.CyanString(n)
+				}
+				fmt.Printf("%s %s %s %s (%s)%s\n", color.YellowString(short(c.Hash, 7)), color.HiBlackString(t.Format("2006-01-02")), color.MagentaString(commitLabel(c)), c.Message, color.HiBlackString(c.Author), note)
 			}
 			return nil
 		},
 	}
 	cmd.Flags().IntVarP(&number, "number", "n", 10, "Number of commits to show")
+	cmd.AddCommand(historyIndexCmd(repoPath), historySearchCmd(repoPath), historyHotspotsCmd(repoPath), historyContributorsCmd(repoPath), historyRevertsCmd(repoPath))
+	return cmd
+}
+
+// commitLabel is the size and type guess of c, e.g. "[M feat]".
+func commitLabel(c git.CommitInfo) string {
+	if c.Type == "" {
+		return "[" + c.Size + "]"
+	}
+	return "[" + c.Size + " " + c.Type + "]"
+}
+
+func stashCmd(repoPath *string) *cobra.Command {
+	var explain, review, summary bool
+	cmd := &cobra.Command{
+		Use:   "stash [n]",
+		Short: "List stashes or view and explain a stashed change",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := git.NewGitExtractor(*repoPath)
+			if len(args) == 0 {
+				stashes, err := g.GetStashList()
+				if err != nil {
+					return err
+				}
+				if len(stashes) == 0 {
+					fmt.Println(color.YellowString(i18n.T("cli.noStashes")))
+					return nil
+				}
+				for _, s := range stashes {
+					t, _ := time.Parse(time.RFC3339, s.Date)
+					fmt.Printf("%s %s %s\n", color.YellowString(s.Ref), color.HiBlackString(t.Format("2006-01-02")), s.Message)
+				}
+				return nil
+			}
+
+			index, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid stash index: %s", args[0])
+			}
+			opts := llmCommandOptions{Stash: &index}
+			switch {
+			case explain:
+				return runLLMCommand(*repoPath, "explain", opts)
+			case review:
+				return runLLMCommand(*repoPath, "review", opts)
+			case summary:
+				return runLLMCommand(*repoPath, "summary", opts)
+			}
+			diffs, err := g.GetStashDiff(index)
+			if err != nil {
+				return err
+			}
+			fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&explain, "explain", false, "Get an AI explanation of the stash")
+	cmd.Flags().BoolVar(&review, "review", false, "Get an AI review of the stash")
+	cmd.Flags().BoolVar(&summary, "summary", false, "Get a quick summary of the stash")
 	return cmd
 }
 
 func webCmd(repoPath *string) *cobra.Command {
 	var port int
+	var noBrowser bool
 	cmd := &cobra.Command{
 		Use:   "web",
 		Short: "Launch the web UI in your browser",
+		Long:  "Runs the web UI in the foreground until interrupted. `web start` runs it in the background instead, `web status` shows whether it is running and `web stop` shuts it down; `web list` shows every running server.",
+		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			go func() { _ = openBrowser(fmt.Sprintf("http://localhost:%d", port)) }()
+			cmd.SilenceUsage = true
+			if !noBrowser {
+				go func() { _ = openBrowser(fmt.Sprintf("http://localhost:%d", port)) }()
+			}
 			return api.StartAPIServer(port, *repoPath)
 		},
 	}
 	cmd.Flags().IntVarP(&port, "port", "p", 3000, "Port for web server")
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Do not open the browser")
+	cmd.AddCommand(webStartCmd(repoPath), webStopCmd(), webStatusCmd(), webListCmd())
 	return cmd
 }
 
 func configCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "config",
-		Short: "Show LLM configuration status",
+		Short: "Show LLM configuration status, or change settings in ~/.difflearn",
+		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			cfg := config.LoadConfig()
-			fmt.Printf("Provider: %s\n", cfg.Provider)
-			fmt.Printf("Model: %s\n", cfg.Model)
-			fmt.Printf("LLM Available: %t\n", config.IsLLMAvailable(cfg))
+			if p := config.ActiveProfile(); p != "" {
+				fmt.Println(i18n.T("cli.config.profile", p))
+			}
+			fmt.Println(i18n.T("cli.config.provider", cfg.Provider))
+			fmt.Println(i18n.T("cli.config.model", cfg.Model))
+			fmt.Println(i18n.T("cli.config.available", config.IsLLMAvailable(cfg)))
 			if cfg.BaseURL != "" {
-				fmt.Printf("Base URL: %s\n", cfg.BaseURL)
+				fmt.Println(i18n.T("cli.config.baseURL", cfg.BaseURL))
+			}
+			if chain := config.Fallbacks(cfg); len(chain) > 0 {
+				names := make([]string, len(chain))
+				for i, fb := range chain {
+					names[i] = string(fb.Provider) + ":" + fb.Model
+				}
+				fmt.Println(i18n.T("cli.config.fallback", strings.Join(names, ", ")))
+			}
+			fmt.Println(i18n.T("cli.config.uiLanguage", i18n.Locale()))
+			if a := config.DiffAlgorithm(); a != "" {
+				fmt.Println(i18n.T("cli.config.diffAlgorithm", a))
+			}
+			if level := llm.DefaultLevel(); level != llm.LevelDefault {
+				fmt.Println(i18n.T("cli.config.level", level))
+			}
+			if language := llm.DefaultLanguage(); language != "" {
+				fmt.Println(i18n.T("cli.config.language", language))
 			}
 		},
 	}
+	cmd.AddCommand(configSetCmd(), configGetCmd(), configUnsetCmd(), configKeysCmd(), configProfilesCmd())
 	return cmd
 }
 
@@ -264,66 +667,324 @@ func updateCmd() *cobra.Command {
 				return err
 			}
 			if info == nil || !info.UpdateAvailable {
-				fmt.Println("✅ You're on the latest version")
+				fmt.Println("✅ " + i18n.T("cli.update.latest"))
 				return nil
 			}
-			fmt.Printf("🆕 Update available: v%s -> v%s\n", info.CurrentVersion, info.LatestVersion)
-			fmt.Printf("Run: %s\n", update.GetUpdateCommand())
-			fmt.Printf("Release: %s\n", info.ReleaseURL)
+			fmt.Println("🆕 " + i18n.T("cli.update.available", info.CurrentVersion, info.LatestVersion))
+			fmt.Println(i18n.T("cli.update.run", update.GetUpdateCommand()))
+			fmt.Println(i18n.T("cli.update.release", info.ReleaseURL))
 			return nil
 		},
 	}
 	return cmd
 }
 
-func runLLMCommand(repoPath string, staged bool, kind string) error {
-	cfg := config.LoadConfig()
-	g := git.NewGitExtractor(repoPath)
+func addRenameFlags(cmd *cobra.Command, r *git.RenameDetection) {
+	cmd.Flags().IntVar(&r.Threshold, "find-renames", 0, "Minimum similarity percentage for rename detection (default 50)")
+	cmd.Flags().BoolVar(&r.Copies, "find-copies", false, "Also detect copied files")
+	cmd.Flags().BoolVar(&r.Disable, "no-renames", false, "Show renames as a delete plus an add")
+}
+
+func addWhitespaceFlags(cmd *cobra.Command, w *git.WhitespaceOptions) {
+	cmd.Flags().BoolVarP(&w.IgnoreWhitespace, "ignore-all-space", "w", false, "Ignore whitespace when comparing lines")
+	cmd.Flags().BoolVarP(&w.IgnoreSpaceChange, "ignore-space-change", "b", false, "Ignore changes in the amount of whitespace")
+	cmd.Flags().BoolVar(&w.IgnoreBlankLines, "ignore-blank-lines", false, "Ignore changes whose lines are all blank")
+}
+
+func addSmartFlag(cmd *cobra.Command, opts *llmCommandOptions, enabled bool) {
+	cmd.Flags().BoolVar(&opts.Smart, "smart", enabled, "Use the unstaged changes when nothing is staged with --staged, and the staged ones when there are no unstaged changes")
+}
+
+type llmCommandOptions struct {
+	Staged        bool
+	File          string
+	CompareModels []string
+	// ShowPrompt prints the review prompt when no LLM is configured, for
+	// pasting into one.
+	ShowPrompt bool
+	// Refine overrides DIFFLEARN_REFINE_REVIEW when set.
+	Refine            *bool
+	Stash             *int
+	Against           string
+	Untracked         bool
+	RecurseSubmodules bool
+	Renames           git.RenameDetection
+	Whitespace        git.WhitespaceOptions
+	Structured        bool
+	Question          string
+	Copy              bool
+	Out               string
+	// Preloaded replaces the repository diff, e.g. with a fetched pull
+	// request; Ref then names where it came from.
+	Preloaded  []git.ParsedDiff
+	Ref        string
+	OnResponse func(content string) error
+	// FailOn makes review return an error when findings or issues reach
+	// this severity.
+	FailOn analysis.Severity
+	// Model and Temperature override the configured provider settings.
+	Model       string
+	Temperature *float64
+	// Ticket is the text of the issue the change should address.
+	Ticket string
+	// Smart falls back to the other side of the index when the requested
+	// staged or unstaged changes are empty.
+	Smart bool
+	// Paths limits the diff to matching files; see git.FilterDiffsByPath.
+	Paths []string
+	// DryRun reports the prompt size instead of sending the request.
+	DryRun bool
+	// Narrative tells an explanation as the author's step-by-step story.
+	Narrative bool
+	// Prompt and DiffHash describe the sent request in the session history.
+	Prompt   string
+	DiffHash string
+}
+
+func loadCommandDiffs(g git.Repository, opts llmCommandOptions) ([]git.ParsedDiff, error) {
+	if len(opts.Paths) > 0 {
+		all := opts
+		all.Paths = nil
+		diffs, err := loadCommandDiffs(g, all)
+		if err != nil {
+			return nil, err
+		}
+		return git.FilterDiffsByPath(diffs, opts.Paths), nil
+	}
+	if opts.Preloaded != nil {
+		return opts.Preloaded, nil
+	}
+	if opts.Stash != nil {
+		repo, err := gitOnly(g)
+		if err != nil {
+			return nil, err
+		}
+		return repo.GetStashDiff(*opts.Stash)
+	}
+	if opts.File == "" {
+		return g.GetLocalDiff(git.DiffOptions{Staged: opts.Staged, Against: opts.Against, IncludeUntracked: opts.Untracked, RecurseSubmodules: opts.RecurseSubmodules, Renames: opts.Renames, Whitespace: opts.Whitespace})
+	}
+	if !opts.Staged {
+		return g.GetFileDiff(opts.File, "")
+	}
+	staged, err := g.GetLocalDiff(git.DiffOptions{Staged: true})
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]git.ParsedDiff, 0)
+	for _, d := range staged {
+		if d.NewFile == opts.File || d.OldFile == opts.File {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered, nil
+}
+
+// loadSmartDiffs is loadCommandDiffs for the smart local mode: when the
+// requested side of the index has no changes it takes the other side,
+// says so, and sets opts.Staged to the side used.
+func loadSmartDiffs(g git.Repository, opts *llmCommandOptions) ([]git.ParsedDiff, error) {
+	diffs, err := loadCommandDiffs(g, *opts)
+	if err != nil || len(diffs) > 0 || !opts.Smart || opts.Preloaded != nil || opts.Stash != nil || opts.Against != "" {
+		return diffs, err
+	}
+	other := *opts
+	other.Staged = !opts.Staged
+	fallback, err := loadCommandDiffs(g, other)
+	if err != nil || len(fallback) == 0 {
+		return diffs, err
+	}
+	opts.Staged = other.Staged
+	// stderr keeps --format json and sarif output parseable.
+	if opts.Staged {
+		fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.smart.usedStaged")))
+	} else {
+		fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.smart.usedUnstaged")))
+	}
+	return fallback, nil
+}
+
+func runLLMCommand(repoPath string, kind string, opts llmCommandOptions) error {
+	cfg, err := withCommandOverrides(config.LoadConfig(), opts)
+	if err != nil {
+		return err
+	}
+	g := openRepository(repoPath)
 	formatter := git.NewDiffFormatter()
-	diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: staged})
+	diffs, err := loadSmartDiffs(g, &opts)
 	if err != nil {
 		return err
 	}
 	if len(diffs) == 0 {
-		fmt.Println(color.YellowString("No changes found."))
+		fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
 		return nil
 	}
+	if kind == "explain" && opts.Narrative {
+		kind = "explain-narrative"
+	}
+	if kind == "explain" && opts.File != "" {
+		kind = "explain-file"
+	}
+	if opts.DryRun {
+		build, _, _, err := promptFor(repoPath, kind, opts, formatter, diffs)
+		if err != nil {
+			return err
+		}
+		return printPromptSize(kind, llm.MeasurePrompt(cfg, formatter, diffs, build), false)
+	}
+	if handled, err := runOnServer(g, cfg, kind, opts); handled {
+		return err
+	}
 	if !config.IsLLMAvailable(cfg) {
-		fmt.Println(color.YellowString("No LLM API key configured."))
+		fmt.Println(color.YellowString(i18n.T("cli.noLLMOffline")) + "\n")
 		switch kind {
-		case "explain":
-			fmt.Println(llm.CreateExplainPrompt(formatter, diffs))
+		case "explain", "explain-file", "explain-narrative":
+			fmt.Println(renderMarkdown(analysis.OfflineExplanation(diffs)))
 		case "review":
-			fmt.Println(llm.CreateReviewPrompt(formatter, diffs))
+			findings := analysis.RunRules(diffs)
+			printFindings(findings)
+			fmt.Println(analysis.OfflineSummary(diffs))
+			if opts.ShowPrompt {
+				fmt.Println("\n" + llm.CreateReviewPrompt(formatter, diffs))
+			}
+			return checkFailOn(findings, nil, opts.FailOn)
 		case "summary":
+			fmt.Println(analysis.OfflineSummary(diffs) + "\n")
 			fmt.Println(formatter.ToSummary(diffs))
+		case "ask":
+			fmt.Println(llm.CreateQuestionPrompt(formatter, diffs, opts.Question))
 		}
 		return nil
 	}
-	client := llm.NewClient(cfg)
-	prompt := ""
-	label := ""
+	client := llm.NewClient(cfg).For("", kind)
+	build, label, rc, err := promptFor(repoPath, kind, opts, formatter, diffs)
+	if err != nil {
+		return err
+	}
+	opts.Prompt, opts.DiffHash = build(diffs), aihistory.HashDiffs(diffs)
+	if kind == "review" {
+		printFindings(rc.Findings)
+	}
+	if len(opts.CompareModels) > 0 {
+		cfgs, err := resolveCompareModels(cfg, opts.CompareModels)
+		if err != nil {
+			return err
+		}
+		fitted, report := llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens).Fit(formatter, diffs)
+		if notice := report.Notice(); notice != "" {
+			fmt.Println(color.YellowString(notice) + "\n")
+		}
+		return runModelComparison(cfgs, label, build(fitted))
+	}
+
+	if rc.Structured {
+		content, result, err := runStructuredReview(client, cfg, formatter, diffs, label, build)
+		if err != nil {
+			return err
+		}
+		if err := deliverResponse(g, cfg, kind, opts, content); err != nil {
+			return err
+		}
+		return checkFailOn(rc.Findings, result, opts.FailOn)
+	}
+
+	refine := cfg.RefineReview
+	if opts.Refine != nil {
+		refine = *opts.Refine
+	}
+	if kind == "review" && refine {
+		fmt.Println(color.HiBlackString(i18n.T("cli.refining")))
+		result, err := llm.ReviewWithRefinement(client, formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), rc)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n\n", color.GreenString("📝 "+i18n.T("cli.label.refined", label)+":"))
+		if notice := result.Budget.Notice(); notice != "" {
+			fmt.Println(color.YellowString(notice) + "\n")
+		}
+		fmt.Println(renderMarkdown(result.Final))
+		printRubricScorecard(result.Final, rc.Rubric)
+		return deliverResponse(g, cfg, kind, opts, result.Final)
+	}
+
+	content, err := streamLLMResponse(client, cfg, formatter, diffs, label, build)
+	if err != nil {
+		return err
+	}
+	printRubricScorecard(content, rc.Rubric)
+	return deliverResponse(g, cfg, kind, opts, content)
+}
+
+// promptFor returns the prompt builder, output heading and review context
+// for an AI command kind.
+func promptFor(repoPath, kind string, opts llmCommandOptions, formatter *git.DiffFormatter, diffs []git.ParsedDiff) (func([]git.ParsedDiff) string, string, llm.ReviewContext, error) {
+	var rc llm.ReviewContext
 	switch kind {
 	case "explain":
-		prompt = llm.CreateExplainPrompt(formatter, diffs)
-		label = "Explanation"
+		return func(d []git.ParsedDiff) string {
+			return llm.WithTicket(llm.CreateExplainPrompt(formatter, d), opts.Ticket)
+		}, i18n.T("cli.label.explanation"), rc, nil
+	case "explain-file":
+		return func(d []git.ParsedDiff) string {
+			return llm.WithTicket(llm.CreateFileExplainPrompt(formatter, d, opts.File), opts.Ticket)
+		}, i18n.T("cli.label.explanationOf", opts.File), rc, nil
+	case "explain-narrative":
+		return func(d []git.ParsedDiff) string {
+			return llm.WithTicket(llm.CreateNarrativePrompt(formatter, d), opts.Ticket)
+		}, i18n.T("cli.label.narrative"), rc, nil
 	case "review":
-		prompt = llm.CreateReviewPrompt(formatter, diffs)
-		label = "Code Review"
+		repoCfg, err := config.LoadRepoConfig(repoPath)
+		if err != nil {
+			return nil, "", rc, err
+		}
+		rc = llm.ReviewContext{Findings: analysis.RunRules(diffs), Rubric: repoCfg.Rubric, Structured: opts.Structured, Ticket: opts.Ticket}
+		return func(d []git.ParsedDiff) string { return llm.CreateReviewPromptWithContext(formatter, d, rc) }, i18n.T("cli.label.review"), rc, nil
 	case "summary":
-		prompt = llm.CreateSummaryPrompt(formatter, diffs)
-		label = "Summary"
+		return func(d []git.ParsedDiff) string { return llm.CreateSummaryPrompt(formatter, d) }, i18n.T("cli.label.summary"), rc, nil
+	case "ask":
+		return func(d []git.ParsedDiff) string { return llm.CreateQuestionPrompt(formatter, d, opts.Question) }, i18n.T("cli.label.answer"), rc, nil
+	}
+	return nil, "", rc, fmt.Errorf("unknown AI command: %s", kind)
+}
+
+func printRubricScorecard(review string, rubric []config.RubricCriterion) {
+	if len(rubric) == 0 {
+		return
 	}
+	results := llm.ParseRubricReview(review, rubric)
+	fmt.Printf("\n%s\n\n%s\n", color.GreenString("📊 "+i18n.T("cli.scorecard")), llm.FormatRubricScorecard(results))
+	for _, r := range llm.FailedGates(results) {
+		fmt.Println(color.RedString("✗ " + i18n.T("cli.gateFailed", r.Name)))
+	}
+}
+
+// streamLLMResponse prints the answer for build(diffs), switching to the
+// map-reduce pipeline when the diff does not fit the context budget, and
+// returns the full answer.
+func streamLLMResponse(client *llm.Client, cfg config.Config, formatter *git.DiffFormatter, diffs []git.ParsedDiff, label string, build func([]git.ParsedDiff) string) (string, error) {
 	fmt.Printf("%s\n\n", color.GreenString("📝 "+label+":"))
-	chunks, errs := client.StreamChat([]llm.ChatMessage{{Role: "system", Content: llm.SystemPrompt}, {Role: "user", Content: prompt}})
-	for c := range chunks {
-		fmt.Print(c)
+
+	budget := llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens)
+	if llm.EstimateDiffTokens(formatter, diffs) > budget.Available() {
+		resp, report, err := llm.RunBudgeted(client, formatter, diffs, budget, build)
+		if err != nil {
+			return "", err
+		}
+		if notice := report.Notice(); notice != "" {
+			fmt.Println(color.YellowString(notice) + "\n")
+		}
+		fmt.Println(renderMarkdown(resp.Content))
+		return resp.Content, nil
 	}
-	if err := <-errs; err != nil {
-		return err
+
+	// The whole reply, not a token stream: its newlines carry the markdown
+	// the renderer, the scorecard and --out rely on.
+	resp, err := chatWithProgress(client, []llm.ChatMessage{{Role: "system", Content: llm.SystemPrompt}, {Role: "user", Content: build(diffs)}})
+	if err != nil {
+		return "", err
 	}
-	fmt.Println()
-	return nil
+	fmt.Println(renderMarkdown(resp.Content))
+	return resp.Content, nil
 }
 
 func openBrowser(url string) error {
@@ -354,7 +1015,22 @@ func atoiOrDefault(s string, d int) int {
 	return v
 }
 
+// exitError makes PrintErrAndExit exit with a specific code, so scripts can
+// tell a blocked `ci` run from a failure.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
 func PrintErrAndExit(err error) {
 	fmt.Fprintln(os.Stderr, err)
+	var ee *exitError
+	if errors.As(err, &ee) {
+		os.Exit(ee.code)
+	}
 	os.Exit(1)
 }
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/aihistory"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+// sessionFilter holds the flags list and export share.
+type sessionFilter struct {
+	all   bool
+	kind  string
+	text  string
+	limit int
+}
+
+func (f *sessionFilter) addFlags(cmd *cobra.Command, limit int) {
+	cmd.Flags().BoolVar(&f.all, "all", false, "Include sessions from every repository")
+	cmd.Flags().StringVar(&f.kind, "kind", "", "Only sessions of this kind: explain, review, summary, ask, ...")
+	cmd.Flags().StringVarP(&f.text, "search", "q", "", "Only sessions whose question, ref, prompt or answer contains this text")
+	cmd.Flags().IntVarP(&f.limit, "limit", "n", limit, "Most sessions to include (0 for all)")
+}
+
+func (f sessionFilter) entries(repoPath string) ([]aihistory.Entry, error) {
+	q := aihistory.Query{Kind: f.kind, Text: f.text, Limit: f.limit}
+	if !f.all {
+		q.Repo = git.NewGitExtractor(repoPath).RepoPath()
+	}
+	return aihistory.Open().Search(q)
+}
+
+func sessionsCmd(repoPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Browse, search and export past AI explanations, reviews and answers",
+		Long:  "Every answered explain, review, summary and ask request is kept with its diff hash, prompt and answer, from the CLI and the web UI alike. sessions list and export take --search to find past learnings, and show prints one session in full.",
+	}
+	cmd.AddCommand(sessionsListCmd(repoPath), sessionsShowCmd(), sessionsExportCmd(repoPath))
+	return cmd
+}
+
+func sessionsListCmd(repoPath *string) *cobra.Command {
+	var filter sessionFilter
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List past AI sessions for this repository, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := filter.entries(*repoPath)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.sessions.none")))
+				return nil
+			}
+			width := terminalWidth()
+			for _, e := range entries {
+				prefix := fmt.Sprintf("%s  %s  %-12s %-18s ", e.ID, e.Time.Local().Format("2006-01-02 15:04"), e.Kind, truncateRunes(e.Ref, 18))
+				fmt.Println(color.YellowString(e.ID) + prefix[len(e.ID):] + truncateRunes(sessionExcerpt(e), width-len([]rune(prefix))))
+			}
+			return nil
+		},
+	}
+	filter.addFlags(cmd, 20)
+	return cmd
+}
+
+func sessionsShowCmd() *cobra.Command {
+	var prompt bool
+	cmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show one past AI session in full",
+		Long:  "Prints a session's answer as rendered Markdown. The ID can be shortened to any unique prefix; --prompt also prints the prompt that was sent.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, err := aihistory.Open().Find(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(color.New(color.Bold).Sprint(sessionTitle(e)))
+			fmt.Println(color.HiBlackString(i18n.T("cli.sessions.meta", e.Time.Local().Format("2006-01-02 15:04"), e.Provider, e.Model, e.Repo)))
+			if e.Selection.Question != "" {
+				fmt.Println("\n" + color.CyanString("❓ "+e.Selection.Question))
+			}
+			if prompt && e.Prompt != "" {
+				fmt.Printf("\n%s\n\n%s\n", color.GreenString(i18n.T("cli.sessions.prompt")), e.Prompt)
+			}
+			fmt.Println("\n" + renderMarkdown(e.Response))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&prompt, "prompt", false, "Also print the prompt that was sent")
+	return cmd
+}
+
+func sessionsExportCmd(repoPath *string) *cobra.Command {
+	var filter sessionFilter
+	var format, out string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export past AI sessions as a Markdown notebook or JSON",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := filter.entries(*repoPath)
+			if err != nil {
+				return err
+			}
+			var doc string
+			switch format {
+			case "markdown":
+				doc = sessionsMarkdown(entries)
+			case "json":
+				b, err := json.MarshalIndent(entries, "", "  ")
+				if err != nil {
+					return err
+				}
+				doc = string(b) + "\n"
+			default:
+				return fmt.Errorf("unknown --format %q (use markdown or json)", format)
+			}
+			if out == "" {
+				fmt.Print(doc)
+				return nil
+			}
+			if err := os.WriteFile(out, []byte(doc), 0o644); err != nil {
+				return err
+			}
+			fmt.Println(color.GreenString(i18n.T("cli.sessions.exported", len(entries), out)))
+			return nil
+		},
+	}
+	filter.addFlags(cmd, 0)
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown or json")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "File to write (default: stdout)")
+	return cmd
+}
+
+func sessionTitle(e aihistory.Entry) string {
+	if e.Ref == "" {
+		return e.Kind
+	}
+	return e.Kind + " · " + e.Ref
+}
+
+// sessionExcerpt is the question asked, or the first line of the answer.
+func sessionExcerpt(e aihistory.Entry) string {
+	if e.Selection.Question != "" {
+		return e.Selection.Question
+	}
+	for _, line := range strings.Split(e.Response, "\n") {
+		if line = strings.TrimSpace(strings.TrimLeft(line, "#*->_ ")); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func sessionsMarkdown(entries []aihistory.Entry) string {
+	var b strings.Builder
+	b.WriteString("# DiffLearn notebook\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "\n## %s\n\n", sessionTitle(e))
+		fmt.Fprintf(&b, "_%s · %s/%s · session %s_\n\n", e.Time.Local().Format("2006-01-02 15:04"), e.Provider, e.Model, e.ID)
+		if e.Selection.Question != "" {
+			fmt.Fprintf(&b, "> %s\n\n", e.Selection.Question)
+		}
+		b.WriteString(strings.TrimSpace(e.Response) + "\n")
+	}
+	return b.String()
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(strings.Join(strings.Fields(s), " "))
+	if n <= 1 || len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n-1]) + "…"
+}
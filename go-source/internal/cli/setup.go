@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/i18n"
+)
+
+// keyProviders are the providers setup can configure from a pasted API key.
+var keyProviders = []config.LLMProvider{config.ProviderOpenAI, config.ProviderAnthropic, config.ProviderGoogle}
+
+func setupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "setup",
+		Short: "Choose the LLM provider and save it to ~/.difflearn",
+		Long:  "Detects API keys in the environment, installed CLI agents and a running Ollama server, asks which one to use and saves the choice to ~/.difflearn. It runs by itself the first time DiffLearn starts in a terminal without any configuration; set DIFFLEARN_NO_SETUP=1 to prevent that.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSetup(os.Stdin, os.Stdout)
+		},
+	}
+}
+
+// firstRunSetup starts setup before cmd runs when DiffLearn has never been
+// configured and a person is at the terminal to answer.
+func firstRunSetup(cmd *cobra.Command) error {
+	switch cmd.Name() {
+	case "setup", "serve-mcp", "help", "completion", "version", "update":
+		return nil
+	}
+	if !config.NeedsSetup() || !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return nil
+	}
+	return runSetup(os.Stdin, os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func runSetup(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	ask := func(prompt string) string {
+		fmt.Fprint(out, prompt)
+		line, _ := reader.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+
+	fmt.Fprintln(out, color.New(color.Bold).Sprint(i18n.T("cli.setup.welcome")))
+	options := config.DetectSetupOptions()
+	if len(options) == 0 {
+		fmt.Fprintln(out, i18n.T("cli.setup.noneFound"))
+	} else {
+		fmt.Fprintln(out, i18n.T("cli.setup.found"))
+	}
+	for i, o := range options {
+		fmt.Fprintf(out, "  %d) %s %s\n", i+1, o.Provider, color.HiBlackString("— "+setupSource(o)))
+	}
+	enterKey, skip := len(options)+1, len(options)+2
+	fmt.Fprintf(out, "  %d) %s\n", enterKey, i18n.T("cli.setup.enterKey"))
+	fmt.Fprintf(out, "  %d) %s\n", skip, i18n.T("cli.setup.skip"))
+
+	choice := 1
+	for {
+		answer := ask(i18n.T("cli.setup.choose", 1))
+		if answer == "" {
+			break
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= skip {
+			choice = n
+			break
+		}
+		fmt.Fprintln(out, i18n.T("cli.setup.invalid", skip))
+	}
+
+	values := map[string]string{}
+	var chosen config.SetupOption
+	switch {
+	case choice == skip:
+		if err := config.SaveSettings(values); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, i18n.T("cli.setup.skipped"))
+		return nil
+	case choice == enterKey:
+		for i, p := range keyProviders {
+			fmt.Fprintf(out, "  %d) %s\n", i+1, p)
+		}
+		n, _ := strconv.Atoi(ask(i18n.T("cli.setup.choose", 1)))
+		if n < 1 || n > len(keyProviders) {
+			n = 1
+		}
+		chosen.Provider = keyProviders[n-1]
+		key := ask(i18n.T("cli.setup.pasteKey", config.APIKeyEnv(chosen.Provider)))
+		if key == "" {
+			return fmt.Errorf("%s", i18n.T("cli.setup.noKey"))
+		}
+		values[config.APIKeyEnv(chosen.Provider)] = key
+	default:
+		chosen = options[choice-1]
+		if chosen.Provider == config.ProviderOllama {
+			values["DIFFLEARN_MODEL"] = chosen.Model
+		}
+	}
+	values["DIFFLEARN_LLM_PROVIDER"] = string(chosen.Provider)
+	if err := config.SaveSettings(values); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, color.GreenString("✅ "+i18n.T("cli.setup.saved", chosen.Provider, config.FilePath())))
+	if len(config.GetCLIAuthCommand(chosen.Provider)) > 0 {
+		fmt.Fprintln(out, i18n.T("cli.setup.cliAuth", authAlias(chosen.Provider)))
+	}
+	return nil
+}
+
+func setupSource(o config.SetupOption) string {
+	switch {
+	case config.APIKeyEnv(o.Provider) != "":
+		return i18n.T("cli.setup.fromEnv", o.Source)
+	case o.Provider == config.ProviderOllama:
+		return i18n.T("cli.setup.fromOllama", o.Source, o.Model)
+	default:
+		return i18n.T("cli.setup.fromCLI", o.Source)
+	}
+}
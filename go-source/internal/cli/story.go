@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+	"difflearn-go/internal/llm"
+)
+
+// Story panes, cycled with tab.
+const (
+	paneDiff = iota
+	paneExplanation
+	paneQuestions
+)
+
+func storyCmd(repoPath *string) *cobra.Command {
+	var base string
+	var count int
+	var noAI, noInteractive bool
+	cmd := &cobra.Command{
+		Use:   "story <branch|ref1..ref2>",
+		Short: "Walk through the commits of a branch or range one at a time, with explanations and questions",
+		Long:  "Story mode turns a feature branch into a guided reading: the commits are shown oldest first, each with its diff, an AI explanation of how it fits the whole and comprehension questions whose answers stay hidden until you ask. A branch is read from where it left --base.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := git.NewGitExtractor(*repoPath)
+			subject, logSpec, _, err := questionSubject(g, args[0], base)
+			if err != nil {
+				return err
+			}
+			newestFirst, err := g.GetCommitsInRange(logSpec)
+			if err != nil {
+				return err
+			}
+			if len(newestFirst) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.noCommitsInRange", logSpec)))
+				return nil
+			}
+			commits := make([]git.CommitInfo, len(newestFirst))
+			for i, c := range newestFirst {
+				commits[len(commits)-1-i] = c
+			}
+			m := newStoryModel(*repoPath, subject, commits, count, noAI)
+			if noInteractive {
+				return m.print()
+			}
+			_, err = tea.NewProgram(m, programOptions()...).Run()
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&base, "base", "", "Branch a branch argument is compared with (default: the repository's default branch)")
+	cmd.Flags().IntVarP(&count, "questions", "n", 3, "Comprehension questions per commit")
+	cmd.Flags().BoolVar(&noAI, "no-ai", false, "Use offline explanations and skip the questions")
+	cmd.Flags().BoolVar(&noInteractive, "no-interactive", false, "Print the whole story instead of stepping through it")
+	return cmd
+}
+
+type storyDiffMsg struct {
+	step  int
+	diffs []git.ParsedDiff
+	err   error
+}
+
+type storyAIMsg struct {
+	step    int
+	pane    int
+	content string
+	err     error
+}
+
+type storyModel struct {
+	repoPath string
+	subject  string
+	commits  []git.CommitInfo
+	count    int
+	online   bool
+	step     int
+	pane     int
+	offset   int
+	height   int
+	answers  bool
+	diffs    map[int][]git.ParsedDiff
+	// ai holds the explanation and questions per step, keyed by step*3+pane.
+	ai      map[int]string
+	pending map[int]bool
+	status  string
+	// commitDiff reads a commit's changes; nil reads them with git.
+	commitDiff func(hash string) ([]git.ParsedDiff, error)
+}
+
+func newStoryModel(repoPath, subject string, commits []git.CommitInfo, count int, noAI bool) storyModel {
+	return storyModel{
+		repoPath: repoPath,
+		subject:  subject,
+		commits:  commits,
+		count:    count,
+		online:   !noAI && config.IsLLMAvailable(config.LoadConfig()),
+		height:   30,
+		diffs:    map[int][]git.ParsedDiff{},
+		ai:       map[int]string{},
+		pending:  map[int]bool{},
+	}
+}
+
+func (m storyModel) Init() tea.Cmd { return m.loadDiffCmd(0) }
+
+func (m storyModel) diffOf(hash string) ([]git.ParsedDiff, error) {
+	if m.commitDiff != nil {
+		return m.commitDiff(hash)
+	}
+	return git.NewGitExtractor(m.repoPath).GetCommitDiff(hash, "")
+}
+
+func (m storyModel) loadDiffCmd(step int) tea.Cmd {
+	return func() tea.Msg {
+		diffs, err := m.diffOf(m.commits[step].Hash)
+		if err == nil {
+			learning.Record(git.NewGitExtractor(m.repoPath).RepoPath(), learning.KindCommit, m.commits[step].Hash)
+		}
+		return storyDiffMsg{step: step, diffs: diffs, err: err}
+	}
+}
+
+func (m storyModel) aiCmd(step, pane int) tea.Cmd {
+	diffs := m.diffs[step]
+	return func() tea.Msg {
+		content, err := m.generate(step, pane, diffs)
+		return storyAIMsg{step: step, pane: pane, content: content, err: err}
+	}
+}
+
+// generate writes the explanation or questions for a step.
+func (m storyModel) generate(step, pane int, diffs []git.ParsedDiff) (string, error) {
+	formatter := git.NewDiffFormatter()
+	build := func(d []git.ParsedDiff) string {
+		return llm.CreateStoryStepPrompt(formatter, m.subject, m.commits, step, d)
+	}
+	if pane == paneQuestions {
+		build = func(d []git.ParsedDiff) string {
+			return llm.CreateComprehensionPrompt(formatter, m.commits[step], d, m.count)
+		}
+	}
+	cfg := config.LoadConfig()
+	resp, _, err := llm.RunBudgeted(llm.NewClient(cfg).For("", "story"), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), build)
+	if err != nil {
+		return "", err
+	}
+	learning.Record(git.NewGitExtractor(m.repoPath).RepoPath(), "story", m.commits[step].Hash)
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// ensure starts whatever the current pane still needs.
+func (m storyModel) ensure() (storyModel, tea.Cmd) {
+	if _, ok := m.diffs[m.step]; !ok {
+		return m, m.loadDiffCmd(m.step)
+	}
+	if m.pane == paneDiff || !m.online {
+		return m, nil
+	}
+	key := m.step*3 + m.pane
+	if _, done := m.ai[key]; done || m.pending[key] {
+		return m, nil
+	}
+	m.pending[key] = true
+	m.status = i18n.T("story.generating")
+	return m, m.aiCmd(m.step, m.pane)
+}
+
+func (m storyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case storyDiffMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.diffs[msg.step] = msg.diffs
+		if msg.step == m.step {
+			return m.ensure()
+		}
+	case storyAIMsg:
+		key := msg.step*3 + msg.pane
+		delete(m.pending, key)
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.ai[key] = msg.content
+		m.status = ""
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "right", "l", "n", " ":
+			if m.step < len(m.commits)-1 {
+				m.step++
+				m.offset, m.answers = 0, false
+				return m.ensure()
+			}
+		case "left", "h", "p":
+			if m.step > 0 {
+				m.step--
+				m.offset, m.answers = 0, false
+				return m.ensure()
+			}
+		case "tab":
+			m.pane = (m.pane + 1) % 3
+			m.offset = 0
+			return m.ensure()
+		case "1", "2", "3":
+			m.pane = int(msg.String()[0] - '1')
+			m.offset = 0
+			return m.ensure()
+		case "a":
+			m.answers = !m.answers
+		case "down", "j":
+			m.offset++
+		case "up", "k":
+			if m.offset > 0 {
+				m.offset--
+			}
+		}
+	}
+	return m, nil
+}
+
+// paneBody is the full text of the current pane.
+func (m storyModel) paneBody() string {
+	diffs, loaded := m.diffs[m.step]
+	if !loaded {
+		return i18n.T("tui.loading")
+	}
+	switch m.pane {
+	case paneExplanation:
+		if !m.online {
+			return renderMarkdown(analysis.OfflineExplanation(diffs))
+		}
+		if text, ok := m.ai[m.step*3+paneExplanation]; ok {
+			return renderMarkdown(text)
+		}
+		return i18n.T("story.generating")
+	case paneQuestions:
+		if !m.online {
+			return i18n.T("story.questionsNeedAI")
+		}
+		text, ok := m.ai[m.step*3+paneQuestions]
+		if !ok {
+			return i18n.T("story.generating")
+		}
+		if !m.answers {
+			text = hideAnswers(text)
+		}
+		return renderMarkdown(text)
+	}
+	if len(diffs) == 0 {
+		return i18n.T("cli.noChanges")
+	}
+	return git.NewDiffFormatter().ToTerminal(diffs, terminalOptions())
+}
+
+// hideAnswers drops the "Answer:" lines of comprehension questions.
+func hideAnswers(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(strings.TrimSpace(line), "*_ ")
+		if !strings.HasPrefix(trimmed, "Answer:") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func (m storyModel) View() string {
+	c := m.commits[m.step]
+	header := styled(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("13")), "📖 DiffLearn • "+m.subject)
+	step := i18n.T("story.step", m.step+1, len(m.commits), short(c.Hash, 7), c.Message)
+	panes := []string{i18n.T("story.pane.diff"), i18n.T("story.pane.explanation"), i18n.T("story.pane.questions")}
+	for i, name := range panes {
+		if i == m.pane {
+			panes[i] = styled(lipgloss.NewStyle().Bold(true).Underline(true), name)
+			if accessibleMode {
+				panes[i] = i18n.T("a11y.current") + " " + name
+			}
+		}
+	}
+	help := i18n.T("story.help")
+	if m.status != "" {
+		help = m.status + " • " + help
+	}
+	status := styled(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), help)
+	if accessibleMode {
+		header = "DiffLearn, " + m.subject
+	}
+
+	body := strings.Split(m.paneBody(), "\n")
+	visible := max(5, m.height-8)
+	start := min(m.offset, len(body)-1)
+	end := min(start+visible, len(body))
+	return fmt.Sprintf("%s\n%s\n%s\n\n%s\n\n%s", header, step, strings.Join(panes, " │ "), strings.Join(body[start:end], "\n"), status)
+}
+
+// print writes the whole story to stdout, generating every step in turn.
+func (m storyModel) print() error {
+	for step, c := range m.commits {
+		diffs, err := m.diffOf(c.Hash)
+		if err != nil {
+			return err
+		}
+		fmt.Println(color.New(color.Bold).Sprint(i18n.T("story.step", step+1, len(m.commits), short(c.Hash, 7), c.Message)))
+		fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
+		if !m.online {
+			fmt.Println(renderMarkdown(analysis.OfflineExplanation(diffs)) + "\n")
+			continue
+		}
+		for _, pane := range []int{paneExplanation, paneQuestions} {
+			text, err := m.generate(step, pane, diffs)
+			if err != nil {
+				return err
+			}
+			fmt.Println(renderMarkdown(text) + "\n")
+		}
+	}
+	return nil
+}
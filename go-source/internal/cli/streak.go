@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+)
+
+func streakCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "streak",
+		Short: "Show your study streak and progress toward the weekly goal",
+		Long:  "Studying counts when you open a commit (commit command, dashboard history, web UI) or get an AI explanation, review, summary or answer. Set the weekly goal with DIFFLEARN_GOAL_COMMITS (default 5) and DIFFLEARN_GOAL_EXPLANATIONS (default 0).",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			events, err := learning.Open().Events()
+			if err != nil {
+				return err
+			}
+			printProgress(learning.Compute(events, learning.GoalFromConfig(), time.Now()))
+			return nil
+		},
+	}
+}
+
+func printProgress(p learning.Progress) {
+	fmt.Println(color.New(color.Bold).Sprint("🔥 " + i18n.T("cli.streak.current", p.CurrentStreak, p.LongestStreak)))
+	if p.Goal.CommitsPerWeek > 0 {
+		fmt.Printf("📅 %s %s\n", i18n.T("cli.streak.commits", p.CommitsThisWeek, p.Goal.CommitsPerWeek), progressBar(p.CommitsThisWeek, p.Goal.CommitsPerWeek))
+	} else {
+		fmt.Println("📅 " + i18n.T("cli.streak.commitsNoGoal", p.CommitsThisWeek))
+	}
+	if p.Goal.ExplanationsPerWeek > 0 {
+		fmt.Printf("💡 %s %s\n", i18n.T("cli.streak.explanations", p.ExplanationsThisWeek, p.Goal.ExplanationsPerWeek), progressBar(p.ExplanationsThisWeek, p.Goal.ExplanationsPerWeek))
+	} else {
+		fmt.Println("💡 " + i18n.T("cli.streak.explanationsNoGoal", p.ExplanationsThisWeek))
+	}
+	if p.GoalMet {
+		fmt.Println(color.GreenString("✅ " + i18n.T("cli.streak.goalMet")))
+	}
+	fmt.Println("🏆 " + i18n.T("cli.streak.weekly", p.WeeklyStreak))
+
+	days := make([]string, 0, len(p.LastSevenDays))
+	for _, d := range p.LastSevenDays {
+		t, _ := time.Parse("2006-01-02", d.Date)
+		if accessibleMode {
+			days = append(days, fmt.Sprintf("%s %d", t.Format("Mon"), d.Events))
+			continue
+		}
+		mark := color.HiBlackString("·")
+		if d.Events > 0 {
+			mark = color.GreenString("■")
+		}
+		days = append(days, t.Format("Mon")+" "+mark)
+	}
+	fmt.Println(i18n.T("cli.streak.lastWeek") + " " + strings.Join(days, "  "))
+}
+
+func progressBar(done, goal int) string {
+	if accessibleMode || goal <= 0 {
+		return ""
+	}
+	const width = 10
+	filled := min(width, done*width/goal)
+	return color.GreenString(strings.Repeat("█", filled)) + color.HiBlackString(strings.Repeat("░", width-filled))
+}
+
+// recordCommitStudied counts a commit toward learning progress under its
+// full hash, so short and long forms are not counted twice.
+func recordCommitStudied(g *git.GitExtractor, ref string) {
+	if hash, err := g.ResolveRef(ref); err == nil {
+		ref = hash
+	}
+	learning.Record(g.RepoPath(), learning.KindCommit, ref)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+func teachCmd(repoPath *string) *cobra.Command {
+	var opts llmCommandOptions
+	var commit, format, out string
+	cmd := &cobra.Command{
+		Use:   "teach",
+		Short: "Export AI teaching comments as NOTE comments in a patch or annotated file copies",
+		Long:  "Ask the model for teaching comments on each hunk and embed them as NOTE comments in the code. --format patch (default) prints the change as a patch with the comments added as extra lines; --format files writes annotated copies of the changed files to --out (default difflearn-notes) for studying in an editor.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "patch" && format != "files" {
+				return fmt.Errorf("unknown format %q (use patch or files)", format)
+			}
+			return runTeach(*repoPath, opts, commit, format, out)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "s", false, "Use only staged changes")
+	cmd.Flags().StringVar(&commit, "commit", "", "Annotate a commit instead of local changes")
+	cmd.Flags().StringVar(&opts.File, "file", "", "Annotate changes to a single file")
+	cmd.Flags().StringVar(&format, "format", "patch", "Output format: patch or files")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Patch file or directory for annotated copies")
+	return cmd
+}
+
+func runTeach(repoPath string, opts llmCommandOptions, commit, format, out string) error {
+	cfg := config.LoadConfig()
+	g := git.NewGitExtractor(repoPath)
+	formatter := git.NewDiffFormatter()
+	var diffs []git.ParsedDiff
+	var err error
+	switch {
+	case commit != "" && opts.File != "":
+		diffs, err = g.GetFileDiff(opts.File, commit)
+	case commit != "":
+		diffs, err = g.GetCommitDiff(commit, "")
+	default:
+		diffs, err = loadCommandDiffs(g, opts)
+	}
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println(color.YellowString(i18n.T("cli.noChanges")))
+		return nil
+	}
+	if !config.IsLLMAvailable(cfg) {
+		fmt.Println(color.YellowString(i18n.T("cli.noLLM")))
+		fmt.Println(llm.CreateTeachingPrompt(diffs))
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.teaching")))
+	result, err := llm.TeachingNotes(llm.NewClient(cfg), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens))
+	if err != nil {
+		return err
+	}
+	if notice := result.Budget.Notice(); notice != "" {
+		fmt.Fprintln(os.Stderr, color.YellowString(notice))
+	}
+	if len(result.Dropped) > 0 {
+		fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.annotationsDropped", len(result.Dropped))))
+	}
+
+	if format == "patch" {
+		patch := formatter.ToUnified(git.WithTeachingComments(diffs, result.Annotations))
+		if out == "" {
+			fmt.Print(patch)
+			return nil
+		}
+		if err := os.WriteFile(out, []byte(patch), 0o644); err != nil {
+			return err
+		}
+		fmt.Println(color.HiBlackString(i18n.T("cli.savedTo", out)))
+		return nil
+	}
+
+	if out == "" {
+		out = "difflearn-notes"
+	}
+	rev := commit
+	if rev == "" && opts.Staged {
+		rev = ":"
+	}
+	written := 0
+	for _, d := range diffs {
+		if d.IsDeleted || d.IsBinary || d.Submodule != nil {
+			continue
+		}
+		content, err := g.GetFileContent(rev, d.NewFile)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(out, filepath.FromSlash(d.NewFile))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(git.InsertTeachingComments(d.NewFile, content, result.Annotations)), 0o644); err != nil {
+			return err
+		}
+		written++
+	}
+	fmt.Println(color.GreenString(i18n.T("cli.teachWritten", written, len(result.Annotations), out)))
+	return nil
+}
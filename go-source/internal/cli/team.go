@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+	"difflearn-go/internal/team"
+)
+
+func teamCmd(repoPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "team",
+		Short: "Share learning progress with a team server and view the team's progress",
+		Long:  "A team server is `difflearn web` started with DIFFLEARN_TEAM_TOKEN set. Members set DIFFLEARN_TEAM_URL and the same DIFFLEARN_TEAM_TOKEN, and are identified by DIFFLEARN_TEAM_MEMBER or git's user.name.",
+	}
+	cmd.AddCommand(teamSyncCmd(repoPath), teamStatusCmd(repoPath))
+	return cmd
+}
+
+func teamSyncCmd(repoPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Push your study activity to the team server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := team.ClientFromConfig(git.NewGitExtractor(*repoPath).GetConfigValue("user.name"))
+			if err != nil {
+				return err
+			}
+			events, err := learning.Open().Events()
+			if err != nil {
+				return err
+			}
+			added, err := client.Push(events)
+			if err != nil {
+				return err
+			}
+			fmt.Println(color.GreenString(i18n.T("cli.team.synced", added, client.Member)))
+			return nil
+		},
+	}
+}
+
+func teamStatusCmd(repoPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show every member's streak, weekly progress and the commits they struggled with",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := team.ClientFromConfig(git.NewGitExtractor(*repoPath).GetConfigValue("user.name"))
+			if err != nil {
+				return err
+			}
+			summaries, err := client.Summaries()
+			if err != nil {
+				return err
+			}
+			if len(summaries) == 0 {
+				fmt.Println(color.YellowString(i18n.T("cli.team.empty")))
+				return nil
+			}
+			for _, s := range summaries {
+				printMemberSummary(s)
+			}
+			return nil
+		},
+	}
+}
+
+func printMemberSummary(s team.MemberSummary) {
+	p := s.Progress
+	goal := ""
+	if p.GoalMet {
+		goal = " " + color.GreenString("✅")
+	}
+	fmt.Printf("%s %s\n", color.New(color.Bold).Sprint(s.Member), color.HiBlackString(i18n.T("cli.team.lastActive", s.LastActive.Local().Format("2006-01-02 15:04"))))
+	fmt.Printf("  🔥 %s\n", i18n.T("cli.streak.current", p.CurrentStreak, p.LongestStreak))
+	if p.Goal.CommitsPerWeek > 0 {
+		fmt.Printf("  📅 %s%s\n", i18n.T("cli.streak.commits", p.CommitsThisWeek, p.Goal.CommitsPerWeek), goal)
+	} else {
+		fmt.Printf("  📅 %s%s\n", i18n.T("cli.streak.commitsNoGoal", p.CommitsThisWeek), goal)
+	}
+	fmt.Printf("  💡 %s\n", i18n.T("cli.team.questions", p.ExplanationsThisWeek, s.Questions))
+	if len(s.RecentCommits) > 0 {
+		recent := make([]string, 0, len(s.RecentCommits))
+		for _, ref := range s.RecentCommits {
+			recent = append(recent, short(ref, 7))
+		}
+		fmt.Printf("  %s %s\n", i18n.T("cli.team.recent"), color.YellowString(strings.Join(recent, " ")))
+	}
+	for _, st := range s.Struggles {
+		fmt.Printf("  %s %s\n", color.RedString("⚠"), i18n.T("cli.team.struggle", st.Repo, short(st.Ref, 7), st.Requests, st.Questions))
+	}
+	fmt.Println()
+}
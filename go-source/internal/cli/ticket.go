@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/ticket"
+)
+
+func addTicketFlag(cmd *cobra.Command, arg *string) {
+	cmd.Flags().StringVar(arg, "ticket", "", "Issue the change should address (text, file, URL or a Jira/Linear key such as PROJ-123); the answer then judges whether the diff delivers it")
+}
+
+// loadTicket resolves a --ticket argument into opts.Ticket.
+func loadTicket(arg string, opts *llmCommandOptions) error {
+	if arg == "" {
+		return nil
+	}
+	t, err := ticket.Load(arg)
+	if err != nil {
+		return err
+	}
+	opts.Ticket = t.Text()
+	return nil
+}
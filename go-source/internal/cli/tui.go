@@ -2,24 +2,35 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"difflearn-go/internal/aihistory"
+	"difflearn-go/internal/config"
 	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/learning"
+	"difflearn-go/internal/llm"
 )
 
 type section string
 
 const (
-	secLocal   section = "local"
-	secStaged  section = "staged"
-	secHistory section = "history"
+	secLocal    section = "local"
+	secStaged   section = "staged"
+	secHistory  section = "history"
+	secBranches section = "branches"
+	secGraph    section = "graph"
 )
 
 type dashboardModel struct {
-	repoPath      string
+	repoPath string
+	// repo is git, or a Mercurial repository, which has no staged changes
+	// and cannot discard or switch branches from here.
+	repo          git.Repository
 	section       section
 	localDiffs    []git.ParsedDiff
 	stagedDiffs   []git.ParsedDiff
@@ -28,23 +39,98 @@ type dashboardModel struct {
 	status        string
 	loading       bool
 	selectedDiffs []git.ParsedDiff
+	// annotations are AI line comments for selectedDiffs; cleared whenever
+	// the selection changes.
+	annotations []git.Annotation
+	// answer replaces the diff view after replaying the last AI request.
+	answerLabel string
+	answer      string
+	// hunkIndex is the cursor over the Local tab's hunks, counted across
+	// files.
+	hunkIndex int
+	// confirm is a discard waiting for y.
+	confirm *discardRequest
+	// branches feeds the Branches tab; branchIndex points into the entries
+	// matching branchFilter, which is being typed while filtering is set.
+	branches     []git.BranchEntry
+	branchIndex  int
+	branchFilter string
+	filtering    bool
+	// comparedBranch is the branch whose diff against the current one the
+	// Branches tab shows instead of the list.
+	comparedBranch string
+	// graph feeds the Graph tab; graphIndex points into graph.Commits.
+	graph      git.CommitGraph
+	graphIndex int
+	// shownCommit is the commit whose diff the History or Graph tab shows
+	// instead of its list.
+	shownCommit string
+	// stages, when set, replaces the Local or Staged tab's diff with each
+	// file's staged, unstaged and combined changes side by side.
+	stages []git.StageView
+}
+
+// discardRequest drops a hunk (header set) or a whole file from the working
+// tree.
+type discardRequest struct {
+	path   string
+	header string
+	label  string
+}
+
+type discardedMsg struct {
+	loaded loadedMsg
+	label  string
+	stash  string
+	err    error
 }
 
 type loadedMsg struct {
-	local   []git.ParsedDiff
-	staged  []git.ParsedDiff
-	commits []git.CommitInfo
-	err     error
+	local    []git.ParsedDiff
+	staged   []git.ParsedDiff
+	commits  []git.CommitInfo
+	branches []git.BranchEntry
+	graph    git.CommitGraph
+	err      error
+}
+
+type branchDiffMsg struct {
+	branch string
+	diffs  []git.ParsedDiff
+	err    error
+}
+
+type switchedMsg struct {
+	loaded loadedMsg
+	result git.SwitchBranchResult
+	err    error
+}
+
+type stagesMsg struct {
+	views []git.StageView
+	err   error
 }
 
 type commitDiffMsg struct {
+	hash  string
 	diffs []git.ParsedDiff
 	err   error
 }
 
+type againMsg struct {
+	label   string
+	content string
+	err     error
+}
+
+type annotationsMsg struct {
+	result llm.AnnotationResult
+	err    error
+}
+
 func RunDashboard(repoPath string) error {
-	m := dashboardModel{repoPath: repoPath, section: secLocal, loading: true, status: "Loading..."}
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	m := dashboardModel{repoPath: repoPath, repo: openRepository(repoPath), section: secLocal, loading: true, status: i18n.T("tui.loading")}
+	p := tea.NewProgram(m, programOptions()...)
 	_, err := p.Run()
 	return err
 }
@@ -55,7 +141,8 @@ func RunCommitView(repoPath, c1, c2 string) error {
 	if err != nil {
 		return err
 	}
-	fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, git.FormatterOptions{}))
+	recordCommitStudied(g, c1)
+	fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
 	return nil
 }
 
@@ -65,7 +152,7 @@ func RunBranchView(repoPath, b1, b2 string) error {
 	if err != nil {
 		return err
 	}
-	fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, git.FormatterOptions{}))
+	fmt.Println(git.NewDiffFormatter().ToTerminal(diffs, terminalOptions()))
 	return nil
 }
 
@@ -75,134 +162,900 @@ func (m dashboardModel) Init() tea.Cmd {
 
 func (m dashboardModel) loadAllCmd() tea.Cmd {
 	return func() tea.Msg {
-		g := git.NewGitExtractor(m.repoPath)
-		if !g.IsRepo() {
-			return loadedMsg{err: fmt.Errorf("not a git repository")}
-		}
-		local, err := g.GetLocalDiff(git.DiffOptions{})
+		return m.loadAll()
+	}
+}
+
+func (m dashboardModel) loadAll() loadedMsg {
+	repo := m.repo
+	if !repo.IsRepo() {
+		return loadedMsg{err: fmt.Errorf("not a git repository")}
+	}
+	local, err := repo.GetLocalDiff(git.DiffOptions{})
+	if err != nil {
+		return loadedMsg{err: err}
+	}
+	staged, err := repo.GetLocalDiff(git.DiffOptions{Staged: true})
+	if err != nil {
+		return loadedMsg{err: err}
+	}
+	var commits []git.CommitInfo
+	if g, ok := repo.(*git.GitExtractor); ok {
+		// A page is pinned to HEAD's hash, so the git cache can answer it.
+		history, err := g.GetCommitHistoryPage("", 0, 50, 0)
 		if err != nil {
 			return loadedMsg{err: err}
 		}
-		staged, err := g.GetLocalDiff(git.DiffOptions{Staged: true})
+		commits = history.Commits
+	} else if commits, err = repo.GetCommitHistory(50); err != nil {
+		return loadedMsg{err: err}
+	}
+	branches, err := repo.GetBranchesDetailed()
+	if err != nil {
+		return loadedMsg{err: err}
+	}
+	graph, err := repo.GetCommitGraph(50, true)
+	if err != nil {
+		return loadedMsg{err: err}
+	}
+	return loadedMsg{local: local, staged: staged, commits: commits, branches: branches, graph: graph}
+}
+
+// discardCmd saves a safety stash, discards the change and reloads.
+func (m dashboardModel) discardCmd(req discardRequest) tea.Cmd {
+	return func() tea.Msg {
+		g, err := gitOnly(m.repo)
 		if err != nil {
-			return loadedMsg{err: err}
+			return discardedMsg{label: req.label, err: err}
 		}
-		commits, err := g.GetCommitHistory(50)
+		stash, err := g.SafetyStash("difflearn: before discarding " + req.label)
 		if err != nil {
-			return loadedMsg{err: err}
+			return discardedMsg{label: req.label, err: err}
+		}
+		if req.header != "" {
+			err = g.DiscardHunk(req.path, req.header)
+		} else {
+			err = g.DiscardFile(req.path)
+		}
+		if err != nil {
+			return discardedMsg{label: req.label, stash: stash, err: err}
+		}
+		return discardedMsg{loaded: m.loadAll(), label: req.label, stash: stash}
+	}
+}
+
+// hunkRefs lists the hunks the Local tab's cursor moves over.
+func (m dashboardModel) hunkRefs() []git.HunkRef {
+	if m.section != secLocal {
+		return nil
+	}
+	refs := make([]git.HunkRef, 0)
+	for i, d := range m.selectedDiffs {
+		for j := range d.Hunks {
+			refs = append(refs, git.HunkRef{File: i, Hunk: j})
+		}
+	}
+	return refs
+}
+
+func (m dashboardModel) selectedHunk() *git.HunkRef {
+	refs := m.hunkRefs()
+	if len(refs) == 0 {
+		return nil
+	}
+	ref := refs[min(m.hunkIndex, len(refs)-1)]
+	return &ref
+}
+
+func (m dashboardModel) hunkAnnouncement() string {
+	ref := m.selectedHunk()
+	if ref == nil {
+		return m.status
+	}
+	d := m.selectedDiffs[ref.File]
+	return i18n.T("tui.status.hunk", ref.Hunk+1, len(d.Hunks), d.NewFile)
+}
+
+// requestDiscard asks for confirmation before discarding the selected hunk,
+// or its whole file.
+func (m dashboardModel) requestDiscard(wholeFile bool) dashboardModel {
+	if m.section != secLocal {
+		m.status = i18n.T("tui.status.discardLocalOnly")
+		return m
+	}
+	ref := m.selectedHunk()
+	if ref == nil {
+		return m
+	}
+	d := m.selectedDiffs[ref.File]
+	if wholeFile {
+		m.confirm = &discardRequest{path: d.NewFile, label: d.NewFile}
+		m.status = i18n.T("tui.confirm.discardFile", d.NewFile)
+	} else {
+		label := i18n.T("tui.hunkLabel", ref.Hunk+1, len(d.Hunks), d.NewFile)
+		m.confirm = &discardRequest{path: d.NewFile, header: d.Hunks[ref.Hunk].Header, label: label}
+		m.status = i18n.T("tui.confirm.discardHunk", label)
+	}
+	return m
+}
+
+// currentBranch names the checked-out branch, or HEAD when detached.
+func (m dashboardModel) currentBranch() string {
+	for _, b := range m.branches {
+		if b.Current {
+			return b.Name
+		}
+	}
+	return "HEAD"
+}
+
+// visibleBranches lists the branches matching branchFilter, best match
+// first.
+func (m dashboardModel) visibleBranches() []git.BranchEntry {
+	if m.branchFilter == "" {
+		return m.branches
+	}
+	type match struct {
+		entry git.BranchEntry
+		score int
+	}
+	matches := make([]match, 0, len(m.branches))
+	for _, b := range m.branches {
+		if score, ok := fuzzyScore(m.branchFilter, b.Name); ok {
+			matches = append(matches, match{b, score})
 		}
-		return loadedMsg{local: local, staged: staged, commits: commits}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score < matches[j].score })
+	out := make([]git.BranchEntry, len(matches))
+	for i, mt := range matches {
+		out[i] = mt.entry
+	}
+	return out
+}
+
+// fuzzyScore reports whether the letters of pattern appear in order in s,
+// ignoring case, and scores the match by how spread out they are and how
+// late it starts; lower is better.
+func fuzzyScore(pattern, s string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	score, first, last := 0, -1, -1
+	i := 0
+	for pos, r := range []rune(strings.ToLower(s)) {
+		if i == len(p) {
+			break
+		}
+		if r != p[i] {
+			continue
+		}
+		if first < 0 {
+			first = pos
+		} else {
+			score += pos - last - 1
+		}
+		last = pos
+		i++
+	}
+	if i < len(p) {
+		return 0, false
+	}
+	return score*2 + first, true
+}
+
+func (m dashboardModel) selectedBranch() (git.BranchEntry, bool) {
+	visible := m.visibleBranches()
+	if len(visible) == 0 {
+		return git.BranchEntry{}, false
+	}
+	return visible[min(m.branchIndex, len(visible)-1)], true
+}
+
+func (m dashboardModel) branchAnnouncement() string {
+	b, ok := m.selectedBranch()
+	if !ok {
+		return i18n.T("tui.noBranches", m.branchFilter)
+	}
+	return i18n.T("a11y.branch", min(m.branchIndex, len(m.visibleBranches())-1)+1, len(m.visibleBranches()), b.Name, string(b.Kind))
+}
+
+func (m dashboardModel) compareBranchCmd(branch string) tea.Cmd {
+	return func() tea.Msg {
+		diffs, err := m.repo.GetBranchDiff(m.currentBranch(), branch)
+		return branchDiffMsg{branch: branch, diffs: diffs, err: err}
+	}
+}
+
+// switchBranchCmd checks out branch, stashing uncommitted changes first like
+// the web UI, and reloads.
+func (m dashboardModel) switchBranchCmd(branch string) tea.Cmd {
+	return func() tea.Msg {
+		g, err := gitOnly(m.repo)
+		if err != nil {
+			return switchedMsg{err: err}
+		}
+		result, err := g.SwitchBranch(branch, git.SwitchBranchOptions{AutoStash: true})
+		if err != nil {
+			return switchedMsg{err: err}
+		}
+		return switchedMsg{loaded: m.loadAll(), result: result}
+	}
+}
+
+// updateBranches handles the Branches tab's own keys and reports whether it
+// used the key.
+func (m dashboardModel) updateBranches(msg tea.KeyMsg) (dashboardModel, tea.Cmd, bool) {
+	if m.filtering {
+		switch msg.Type {
+		case tea.KeyRunes, tea.KeySpace:
+			m.branchFilter += string(msg.Runes)
+		case tea.KeyBackspace:
+			if r := []rune(m.branchFilter); len(r) > 0 {
+				m.branchFilter = string(r[:len(r)-1])
+			}
+		case tea.KeyEsc:
+			m.filtering = false
+			m.branchFilter = ""
+		case tea.KeyEnter:
+			m.filtering = false
+		default:
+			return m, nil, false
+		}
+		m.branchIndex = 0
+		m.status = i18n.T("tui.status.branchFilter", m.branchFilter)
+		if accessibleMode || len(m.visibleBranches()) == 0 {
+			m.status += ". " + m.branchAnnouncement()
+		}
+		return m, nil, true
+	}
+	switch msg.String() {
+	case "/":
+		if m.comparedBranch != "" {
+			return m, nil, false
+		}
+		m.filtering = true
+		m.status = i18n.T("tui.status.branchFilter", m.branchFilter)
+		return m, nil, true
+	case "esc":
+		if m.comparedBranch != "" {
+			m.comparedBranch = ""
+			m.selectedDiffs = nil
+			m.annotations = nil
+		} else {
+			m.branchFilter = ""
+			m.branchIndex = 0
+		}
+		m.status = i18n.T("tui.status.branches", m.currentBranch())
+		return m, nil, true
+	case "enter", "s":
+		b, ok := m.selectedBranch()
+		if m.loading || m.comparedBranch != "" || !ok {
+			return m, nil, msg.String() == "s"
+		}
+		m.loading = true
+		if msg.String() == "s" {
+			m.status = i18n.T("tui.status.switching", b.Name)
+			return m, m.switchBranchCmd(b.Name), true
+		}
+		m.status = i18n.T("tui.status.comparing", b.Name, m.currentBranch())
+		return m, m.compareBranchCmd(b.Name), true
+	}
+	return m, nil, false
+}
+
+// stagesCmd loads the staging view for the files of the current tab.
+func (m dashboardModel) stagesCmd() tea.Cmd {
+	paths := make([]string, 0, len(m.selectedDiffs))
+	for _, d := range m.selectedDiffs {
+		paths = append(paths, d.NewFile)
+		if d.OldFile != d.NewFile && d.OldFile != "" {
+			paths = append(paths, d.OldFile)
+		}
+	}
+	return func() tea.Msg {
+		g, err := gitOnly(m.repo)
+		if err != nil {
+			return stagesMsg{err: err}
+		}
+		views, err := g.GetStageViews(paths...)
+		return stagesMsg{views: views, err: err}
 	}
 }
 
 func (m dashboardModel) loadCommitDiffCmd(hash string) tea.Cmd {
 	return func() tea.Msg {
-		g := git.NewGitExtractor(m.repoPath)
-		diffs, err := g.GetCommitDiff(hash, "")
-		return commitDiffMsg{diffs: diffs, err: err}
+		diffs, err := m.repo.GetCommitDiff(hash, "")
+		if err == nil {
+			learning.Record(m.repo.RepoPath(), learning.KindCommit, hash)
+		}
+		return commitDiffMsg{hash: hash, diffs: diffs, err: err}
+	}
+}
+
+func (m dashboardModel) annotateCmd(diffs []git.ParsedDiff) tea.Cmd {
+	return func() tea.Msg {
+		cfg := config.LoadConfig()
+		result, err := llm.Annotate(llm.NewClient(cfg), git.NewDiffFormatter(), diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens))
+		return annotationsMsg{result: result, err: err}
+	}
+}
+
+// againCmd replays the most recent AI request for the repository, like the
+// again command, without streaming.
+func (m dashboardModel) againCmd() tea.Cmd {
+	return func() tea.Msg {
+		g := m.repo
+		last, err := aihistory.Open().Last(g.RepoPath())
+		if err != nil {
+			return againMsg{err: err}
+		}
+		opts := optionsFromSelection(last.Selection)
+		diffs, err := loadCommandDiffs(g, opts)
+		if err != nil {
+			return againMsg{err: err}
+		}
+		if len(diffs) == 0 {
+			return againMsg{err: fmt.Errorf("%s", i18n.T("cli.noChanges"))}
+		}
+		cfg := config.LoadConfig()
+		formatter := git.NewDiffFormatter()
+		build, label, _, err := promptFor(m.repoPath, last.Kind, opts, formatter, diffs)
+		if err != nil {
+			return againMsg{err: err}
+		}
+		resp, _, err := llm.RunBudgeted(llm.NewClient(cfg), formatter, diffs, llm.NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens), build)
+		if err == nil {
+			recordAIRequest(g, cfg, last.Kind, opts, resp.Content)
+			learning.Record(g.RepoPath(), last.Kind, "")
+		}
+		return againMsg{label: label, content: resp.Content, err: err}
 	}
 }
 
 func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.confirm != nil {
+			req := *m.confirm
+			m.confirm = nil
+			if msg.String() != "y" && msg.String() != "Y" {
+				m.status = i18n.T("tui.status.discardCancelled")
+				return m, nil
+			}
+			m.loading = true
+			m.status = i18n.T("tui.status.discarding")
+			return m, m.discardCmd(req)
+		}
+		if m.section == secBranches {
+			if next, cmd, ok := m.updateBranches(msg); ok {
+				return next, cmd
+			}
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		case "tab":
+			m.annotations = nil
+			m.answer = ""
+			m.filtering = false
+			m.comparedBranch = ""
+			m.shownCommit = ""
+			m.stages = nil
 			if m.section == secLocal {
 				m.section = secStaged
 				m.selectedDiffs = m.stagedDiffs
-				m.status = "Staged changes"
+				m.status = m.announce(i18n.T("tui.status.staged"), m.stagedDiffs)
 			} else if m.section == secStaged {
 				m.section = secHistory
 				m.selectedDiffs = nil
-				m.status = "History view"
+				m.status = i18n.T("tui.status.history")
+				if accessibleMode && len(m.commits) > 0 {
+					m.status += ". " + m.commitAnnouncement()
+				}
+			} else if m.section == secHistory {
+				m.section = secBranches
+				m.selectedDiffs = nil
+				m.status = i18n.T("tui.status.branches", m.currentBranch())
+				if accessibleMode {
+					m.status += ". " + m.branchAnnouncement()
+				}
+			} else if m.section == secBranches {
+				m.section = secGraph
+				m.selectedDiffs = nil
+				m.status = i18n.T("tui.status.graph")
+				if accessibleMode && len(m.graph.Commits) > 0 {
+					m.status += ". " + m.graphAnnouncement()
+				}
 			} else {
 				m.section = secLocal
 				m.selectedDiffs = m.localDiffs
-				m.status = "Local changes"
+				m.status = m.announce(i18n.T("tui.status.local"), m.localDiffs)
 			}
 		case "r":
 			m.loading = true
-			m.status = "Refreshing..."
+			m.status = i18n.T("tui.status.refreshing")
 			return m, m.loadAllCmd()
 		case "up", "k", "w":
-			if m.section == secHistory && m.historyIndex > 0 {
+			if m.section == secGraph && m.shownCommit == "" && m.graphIndex > 0 {
+				m.graphIndex--
+				if accessibleMode {
+					m.status = m.graphAnnouncement()
+				}
+			}
+			if m.section == secHistory && m.shownCommit == "" && m.historyIndex > 0 {
 				m.historyIndex--
+				if accessibleMode {
+					m.status = m.commitAnnouncement()
+				}
+			}
+			if m.section == secLocal && m.answer == "" && m.hunkIndex > 0 {
+				m.hunkIndex = min(m.hunkIndex, len(m.hunkRefs())) - 1
+				m.status = m.hunkAnnouncement()
+			}
+			if m.section == secBranches && m.comparedBranch == "" && m.branchIndex > 0 {
+				m.branchIndex = min(m.branchIndex, len(m.visibleBranches())) - 1
+				if accessibleMode {
+					m.status = m.branchAnnouncement()
+				}
 			}
 		case "down", "j", "s":
-			if m.section == secHistory && m.historyIndex < len(m.commits)-1 {
+			if m.section == secGraph && m.shownCommit == "" && m.graphIndex < len(m.graph.Commits)-1 {
+				m.graphIndex++
+				if accessibleMode {
+					m.status = m.graphAnnouncement()
+				}
+			}
+			if m.section == secHistory && m.shownCommit == "" && m.historyIndex < len(m.commits)-1 {
 				m.historyIndex++
+				if accessibleMode {
+					m.status = m.commitAnnouncement()
+				}
+			}
+			if m.section == secLocal && m.answer == "" && m.hunkIndex < len(m.hunkRefs())-1 {
+				m.hunkIndex++
+				m.status = m.hunkAnnouncement()
+			}
+			if m.section == secBranches && m.comparedBranch == "" && m.branchIndex < len(m.visibleBranches())-1 {
+				m.branchIndex++
+				if accessibleMode {
+					m.status = m.branchAnnouncement()
+				}
+			}
+		case "v":
+			if m.loading || m.answer != "" || (m.section != secLocal && m.section != secStaged) {
+				break
+			}
+			if m.stages != nil {
+				m.stages = nil
+				m.status = m.announce(i18n.T("tui.status."+string(m.section)), m.selectedDiffs)
+				break
+			}
+			if len(m.selectedDiffs) == 0 {
+				break
+			}
+			m.loading = true
+			m.status = i18n.T("tui.status.loadingStages")
+			return m, m.stagesCmd()
+		case "x", "X":
+			if m.loading || m.answer != "" || m.stages != nil {
+				break
+			}
+			m = m.requestDiscard(msg.String() == "X")
+		case "a":
+			if m.loading || m.section == secHistory || len(m.selectedDiffs) == 0 {
+				break
+			}
+			if !config.IsLLMAvailable(config.LoadConfig()) {
+				m.status = i18n.T("tui.status.noLLM")
+				break
+			}
+			m.loading = true
+			m.status = i18n.T("tui.status.annotating")
+			return m, m.annotateCmd(m.selectedDiffs)
+		case ".":
+			if m.loading {
+				break
+			}
+			if !config.IsLLMAvailable(config.LoadConfig()) {
+				m.status = i18n.T("tui.status.noLLM")
+				break
 			}
+			m.loading = true
+			m.status = i18n.T("tui.status.again")
+			return m, m.againCmd()
 		case "enter":
+			if m.loading || m.shownCommit != "" {
+				break
+			}
 			if m.section == secHistory && len(m.commits) > 0 {
 				m.loading = true
-				m.status = "Loading commit diff..."
+				m.status = i18n.T("tui.status.loadingCommit")
 				return m, m.loadCommitDiffCmd(m.commits[m.historyIndex].Hash)
 			}
+			if m.section == secGraph && len(m.graph.Commits) > 0 {
+				m.loading = true
+				m.status = i18n.T("tui.status.loadingCommit")
+				return m, m.loadCommitDiffCmd(m.graph.Commits[m.graphIndex].Hash)
+			}
+		case "esc":
+			if m.shownCommit != "" {
+				m.shownCommit = ""
+				m.selectedDiffs = nil
+				m.annotations = nil
+				if m.section == secGraph {
+					m.status = i18n.T("tui.status.graph")
+				} else {
+					m.status = i18n.T("tui.status.history")
+				}
+			}
 		}
 	case loadedMsg:
 		m.loading = false
 		if msg.err != nil {
-			m.status = "Error: " + msg.err.Error()
+			m.status = i18n.T("tui.status.error", msg.err.Error())
+			return m, nil
+		}
+		m = m.withLoaded(msg)
+		m.status = m.announce(i18n.T("tui.status.loaded"), msg.local)
+	case discardedMsg:
+		m.loading = false
+		if msg.err == nil {
+			msg.err = msg.loaded.err
+		}
+		if msg.err != nil {
+			m.status = i18n.T("tui.status.error", msg.err.Error())
+			return m, nil
+		}
+		m = m.withLoaded(msg.loaded)
+		if msg.stash != "" {
+			m.status = i18n.T("tui.status.discarded", msg.label, short(msg.stash, 7))
+		} else {
+			m.status = i18n.T("tui.status.discardedNoStash", msg.label)
+		}
+	case stagesMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = i18n.T("tui.status.error", msg.err.Error())
+			return m, nil
+		}
+		m.stages = msg.views
+		m.status = i18n.T("tui.status.stages")
+	case branchDiffMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = i18n.T("tui.status.error", msg.err.Error())
+			return m, nil
+		}
+		m.comparedBranch = msg.branch
+		m.selectedDiffs = msg.diffs
+		m.annotations = nil
+		m.answer = ""
+		m.status = m.announce(i18n.T("tui.status.compared", msg.branch, m.currentBranch()), msg.diffs)
+	case switchedMsg:
+		m.loading = false
+		if msg.err == nil {
+			msg.err = msg.loaded.err
+		}
+		if msg.err != nil {
+			m.status = i18n.T("tui.status.error", msg.err.Error())
 			return m, nil
 		}
-		m.localDiffs = msg.local
-		m.stagedDiffs = msg.staged
-		m.commits = msg.commits
-		m.selectedDiffs = msg.local
-		m.status = "Loaded"
+		m = m.withLoaded(msg.loaded)
+		m.selectedDiffs = nil
+		m.branchFilter = ""
+		m.branchIndex = 0
+		if msg.result.StashCreated {
+			m.status = i18n.T("tui.status.switchedStashed", msg.result.CurrentBranch)
+		} else {
+			m.status = i18n.T("tui.status.switched", msg.result.CurrentBranch)
+		}
 	case commitDiffMsg:
 		m.loading = false
 		if msg.err != nil {
-			m.status = "Error: " + msg.err.Error()
+			m.status = i18n.T("tui.status.error", msg.err.Error())
 			return m, nil
 		}
 		m.selectedDiffs = msg.diffs
-		m.section = secHistory
-		m.status = "Showing selected commit diff"
+		m.annotations = nil
+		m.answer = ""
+		m.shownCommit = msg.hash
+		m.status = m.announce(i18n.T("tui.status.commitShown"), msg.diffs)
+	case againMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = i18n.T("tui.status.error", msg.err.Error())
+			return m, nil
+		}
+		m.answerLabel = msg.label
+		m.answer = msg.content
+		m.status = i18n.T("tui.status.againDone")
+	case annotationsMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = i18n.T("tui.status.error", msg.err.Error())
+			return m, nil
+		}
+		m.annotations = msg.result.Annotations
+		m.status = i18n.T("tui.status.annotated", len(msg.result.Annotations), len(msg.result.Dropped))
 	}
 	return m, nil
 }
 
+// withLoaded shows freshly loaded changes on the Local tab.
+func (m dashboardModel) withLoaded(msg loadedMsg) dashboardModel {
+	m.localDiffs = msg.local
+	m.stagedDiffs = msg.staged
+	m.commits = msg.commits
+	m.branches = msg.branches
+	m.graph = msg.graph
+	m.selectedDiffs = msg.local
+	m.annotations = nil
+	m.answer = ""
+	m.comparedBranch = ""
+	m.shownCommit = ""
+	m.stages = nil
+	m.hunkIndex = max(0, min(m.hunkIndex, len(m.hunkRefs())-1))
+	m.graphIndex = max(0, min(m.graphIndex, len(m.graph.Commits)-1))
+	m.branchIndex = max(0, min(m.branchIndex, len(m.visibleBranches())-1))
+	return m
+}
+
 func (m dashboardModel) View() string {
-	header := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("13")).Render("🔍 DiffLearn")
-	tabs := []string{"Local", "Staged", "History"}
-	active := map[section]int{secLocal: 0, secStaged: 1, secHistory: 2}[m.section]
+	header := styled(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("13")), "🔍 DiffLearn")
+	tabs := []string{i18n.T("tui.tab.local"), i18n.T("tui.tab.staged"), i18n.T("tui.tab.history"), i18n.T("tui.tab.branches"), i18n.T("tui.tab.graph")}
+	active := map[section]int{secLocal: 0, secStaged: 1, secHistory: 2, secBranches: 3, secGraph: 4}[m.section]
 	for i := range tabs {
 		if i == active {
-			tabs[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true).Render(tabs[i])
+			if accessibleMode {
+				tabs[i] = i18n.T("a11y.selected", tabs[i])
+			} else {
+				tabs[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true).Render(tabs[i])
+			}
 		}
 	}
 	line := strings.Join(tabs, " | ")
-	status := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(m.status + " • q quit • Tab switch • Enter select • r refresh")
+	if accessibleMode {
+		header = "DiffLearn"
+		line = i18n.T("a11y.tabs", strings.Join(tabs, ", "))
+	}
+	status := styled(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), m.status+" • "+i18n.T("tui.help"))
+	if m.confirm != nil {
+		status = styled(lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true), m.status+" [y/N]")
+	}
 
 	if m.loading {
-		return fmt.Sprintf("%s\n%s\n\nLoading...\n\n%s", header, line, status)
+		return fmt.Sprintf("%s\n%s\n\n%s\n\n%s", header, line, i18n.T("tui.loading"), status)
 	}
 
 	body := ""
-	if m.section == secHistory {
+	if m.answer != "" {
+		body = m.answerLabel + ":\n\n" + renderMarkdown(m.answer)
+	} else if m.section == secHistory && m.shownCommit == "" {
 		if len(m.commits) == 0 {
-			body = "No commits found"
+			body = i18n.T("tui.noCommits")
 		} else {
 			rows := make([]string, 0, len(m.commits))
 			for i, c := range m.commits {
 				prefix := "  "
 				if i == m.historyIndex {
 					prefix = "> "
+					if accessibleMode {
+						prefix = i18n.T("a11y.current") + " "
+					}
 				}
-				rows = append(rows, fmt.Sprintf("%s%s %s (%s)", prefix, short(c.Hash, 7), c.Message, c.Author))
+				rows = append(rows, fmt.Sprintf("%s%s %s %s (%s)", prefix, short(c.Hash, 7), commitLabel(c), c.Message, c.Author))
 			}
 			body = strings.Join(rows, "\n")
 		}
+	} else if m.section == secBranches && m.comparedBranch == "" {
+		body = m.branchList()
+	} else if m.section == secGraph && m.shownCommit == "" {
+		body = m.graphView()
+	} else if m.stages != nil {
+		body = renderStageViews(m.stages, terminalWidth())
 	} else {
 		if len(m.selectedDiffs) == 0 {
-			body = "No changes found"
+			body = i18n.T("tui.noChanges")
 		} else {
-			body = git.NewDiffFormatter().ToTerminal(m.selectedDiffs, git.FormatterOptions{})
+			opts := terminalOptions()
+			opts.Annotations = m.annotations
+			opts.Selected = m.selectedHunk()
+			body = git.NewDiffFormatter().ToTerminal(m.selectedDiffs, opts)
 		}
 	}
 	return fmt.Sprintf("%s\n%s\n\n%s\n\n%s", header, line, body, status)
 }
+
+// branchList renders the Branches tab: the filter being typed, then the
+// matching branches with the current one starred.
+func (m dashboardModel) branchList() string {
+	rows := make([]string, 0, len(m.branches)+1)
+	if m.filtering || m.branchFilter != "" {
+		filter := i18n.T("tui.status.branchFilter", m.branchFilter)
+		if m.filtering && !accessibleMode {
+			filter += "▏"
+		}
+		rows = append(rows, filter, "")
+	}
+	visible := m.visibleBranches()
+	if len(visible) == 0 {
+		return strings.Join(append(rows, i18n.T("tui.noBranches", m.branchFilter)), "\n")
+	}
+	selected := min(m.branchIndex, len(visible)-1)
+	for i, b := range visible {
+		prefix := "  "
+		if i == selected {
+			prefix = "> "
+			if accessibleMode {
+				prefix = i18n.T("a11y.current") + " "
+			}
+		}
+		name := b.Name
+		if b.Current {
+			name = "* " + name
+		}
+		if b.Kind == git.BranchKindRemote {
+			name = styled(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), name+" ("+string(b.Kind)+")")
+		}
+		rows = append(rows, fmt.Sprintf("%s%s %s", prefix, short(b.Commit, 7), name))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// graphView draws the Graph tab: git's ASCII graph with each commit's short
+// hash, refs and subject. In accessible mode the graph characters are left
+// out and each commit names its parents instead.
+func (m dashboardModel) graphView() string {
+	if len(m.graph.Commits) == 0 {
+		return i18n.T("tui.noCommits")
+	}
+	byHash := make(map[string]git.GraphCommit, len(m.graph.Commits))
+	for _, c := range m.graph.Commits {
+		byHash[c.Hash] = c
+	}
+	selected := m.graph.Commits[min(m.graphIndex, len(m.graph.Commits)-1)].Hash
+	refStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true)
+	rows := make([]string, 0, len(m.graph.Rows))
+	for _, r := range m.graph.Rows {
+		if r.Commit == "" {
+			if !accessibleMode {
+				rows = append(rows, "  "+r.Graph)
+			}
+			continue
+		}
+		c := byHash[r.Commit]
+		prefix := "  "
+		if c.Hash == selected {
+			prefix = "> "
+			if accessibleMode {
+				prefix = i18n.T("a11y.current") + " "
+			}
+		}
+		line := short(c.Hash, 7)
+		if len(c.Refs) > 0 {
+			line += " " + styled(refStyle, "("+strings.Join(c.Refs, ", ")+")")
+		}
+		line += " " + c.Message
+		if accessibleMode {
+			if details := graphDetails(c); details != "" {
+				line += ". " + details
+			}
+			rows = append(rows, prefix+line)
+			continue
+		}
+		rows = append(rows, prefix+r.Graph+" "+line)
+	}
+	return strings.Join(rows, "\n")
+}
+
+// graphDetails describes a commit's place in the graph in words: merges name
+// their parents.
+func graphDetails(c git.GraphCommit) string {
+	if len(c.Parents) < 2 {
+		return ""
+	}
+	parents := make([]string, len(c.Parents))
+	for i, p := range c.Parents {
+		parents[i] = short(p, 7)
+	}
+	return i18n.T("tui.graph.merge", strings.Join(parents, ", "))
+}
+
+func (m dashboardModel) graphAnnouncement() string {
+	c := m.graph.Commits[m.graphIndex]
+	text := i18n.T("a11y.commit", m.graphIndex+1, len(m.graph.Commits), short(c.Hash, 7), c.Message, c.Author)
+	if len(c.Refs) > 0 {
+		text += ". " + i18n.T("tui.graph.refs", strings.Join(c.Refs, ", "))
+	}
+	if details := graphDetails(c); details != "" {
+		text += ". " + details
+	}
+	return text
+}
+
+// renderStageViews shows, per file, what staging does: HEAD → index,
+// index → worktree and the two together, side by side, or one after another
+// in accessible mode.
+func renderStageViews(views []git.StageView, width int) string {
+	if len(views) == 0 {
+		return i18n.T("tui.noChanges")
+	}
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	out := make([]string, 0, len(views))
+	for _, v := range views {
+		sides := []struct {
+			title string
+			diff  *git.ParsedDiff
+		}{
+			{i18n.T("tui.stages.staged"), v.Staged},
+			{i18n.T("tui.stages.unstaged"), v.Unstaged},
+			{i18n.T("tui.stages.combined"), v.Combined},
+		}
+		file := styled(lipgloss.NewStyle().Bold(true), v.Path) + " [" + v.Scope.Label() + "]"
+		if accessibleMode {
+			parts := []string{file}
+			for _, side := range sides {
+				parts = append(parts, side.title+":\n"+stageColumn(side.diff))
+			}
+			out = append(out, strings.Join(parts, "\n\n"))
+			continue
+		}
+		gap := 2
+		colWidth := max(20, (width-gap*(len(sides)-1))/len(sides))
+		column := lipgloss.NewStyle().Width(colWidth)
+		columns := make([]string, 0, len(sides)*2)
+		for i, side := range sides {
+			header := titleStyle.Render(side.title) + "\n" + strings.Repeat("─", colWidth)
+			columns = append(columns, column.Render(header+"\n"+stageColumn(side.diff)))
+			if i < len(sides)-1 {
+				columns = append(columns, strings.Repeat(" ", gap))
+			}
+		}
+		out = append(out, file+"\n"+lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// stageColumn lists a diff's hunks as +/- lines for one column of the
+// staging view.
+func stageColumn(d *git.ParsedDiff) string {
+	if d == nil {
+		return i18n.T("tui.stages.none")
+	}
+	if d.IsBinary {
+		return i18n.T("tui.stages.binary")
+	}
+	add := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	del := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	hunk := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	lines := make([]string, 0)
+	for _, h := range d.Hunks {
+		lines = append(lines, styled(hunk, h.Header))
+		for _, l := range h.Lines {
+			switch l.Type {
+			case git.LineAdd:
+				lines = append(lines, styled(add, "+"+l.Content))
+			case git.LineDelete:
+				lines = append(lines, styled(del, "-"+l.Content))
+			default:
+				lines = append(lines, " "+l.Content)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// announce appends a spoken-style summary of diffs to status in accessible
+// mode, so navigating tabs is not signaled by color alone.
+func (m dashboardModel) announce(status string, diffs []git.ParsedDiff) string {
+	if !accessibleMode {
+		return status
+	}
+	if len(diffs) == 0 {
+		return status + ". " + i18n.T("tui.noChanges")
+	}
+	return status + ". " + i18n.T("a11y.filesChanged", len(diffs))
+}
+
+func (m dashboardModel) commitAnnouncement() string {
+	c := m.commits[m.historyIndex]
+	return i18n.T("a11y.commit", m.historyIndex+1, len(m.commits), short(c.Hash, 7), c.Message, c.Author)
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/usage"
+)
+
+func usageCmd() *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show LLM usage of today and this month against the configured budgets",
+		Long:  "Every LLM call of the CLI and the web server is recorded in the usage ledger. DIFFLEARN_LLM_BUDGET sets daily or monthly caps as comma-separated \"[scope:]<n> tokens|requests/day|month\" entries: no scope caps everything, token: caps each API token of the server, and an endpoint name such as review: caps that endpoint. Calls over a cap fail with a budget exceeded error until the period resets.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			budget, err := usage.LoadBudget()
+			if err != nil {
+				return err
+			}
+			ledger, now := usage.Open(), time.Now()
+			caps, err := ledger.Status(budget, "", now)
+			if err != nil {
+				return err
+			}
+			summary, err := ledger.Summary("", now)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				b, err := json.MarshalIndent(map[string]any{"budget": caps, "usage": summary}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+			printUsage(caps, summary)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the budgets and usage as JSON")
+	return cmd
+}
+
+func printUsage(caps []usage.CapStatus, s usage.Summary) {
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.usage.totals")))
+	fmt.Println("  " + i18n.T("cli.usage.day", s.Day.Requests, s.Day.Tokens))
+	fmt.Println("  " + i18n.T("cli.usage.month", s.Month.Requests, s.Month.Tokens))
+	for _, endpoint := range s.Endpoints() {
+		t := s.MonthByEndpoint[endpoint]
+		fmt.Println(color.HiBlackString("    %s", i18n.T("cli.usage.endpoint", endpoint, t.Requests, t.Tokens)))
+	}
+	fmt.Println()
+	if len(caps) == 0 {
+		fmt.Println(color.HiBlackString(i18n.T("cli.usage.noBudget")))
+		return
+	}
+	fmt.Println(color.New(color.Bold).Sprint(i18n.T("cli.usage.budget")))
+	for _, c := range caps {
+		line := fmt.Sprintf("  %-28s %d/%d %s", c.Cap.String(), c.Used, c.Limit, progressBar(c.Used, c.Limit))
+		if c.Used >= c.Limit {
+			line = color.RedString(line) + " " + i18n.T("cli.usage.resets", c.Resets.Format("2006-01-02 15:04"))
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+
+	"difflearn-go/internal/api"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/discovery"
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+	"difflearn-go/internal/llm"
+)
+
+// noServer is set by --no-server: AI commands run in-process even when a
+// web server is running for the repository.
+var noServer bool
+
+// serverRequest turns opts into a request for the server's AI endpoints.
+// It reports false for what only the local pipeline can do: single files,
+// path filters, piped or fetched diffs, tickets, structured reviews, model
+// comparisons and temperature overrides.
+func serverRequest(kind string, cfg config.Config, opts llmCommandOptions) (api.AIRequest, bool) {
+	switch kind {
+	case "explain", "review", "summary", "ask":
+	default:
+		return api.AIRequest{}, false
+	}
+	if opts.File != "" || len(opts.Paths) > 0 || opts.Preloaded != nil || opts.Ticket != "" ||
+		opts.Structured || len(opts.CompareModels) > 0 || opts.Temperature != nil {
+		return api.AIRequest{}, false
+	}
+	req := api.AIRequest{
+		Question:          opts.Question,
+		Staged:            opts.Staged,
+		Stash:             opts.Stash,
+		Against:           opts.Against,
+		Untracked:         opts.Untracked,
+		RecurseSubmodules: opts.RecurseSubmodules,
+		Similarity:        opts.Renames.Threshold,
+		FindCopies:        opts.Renames.Copies,
+		NoRenames:         opts.Renames.Disable,
+		IgnoreWhitespace:  opts.Whitespace.IgnoreWhitespace,
+		IgnoreSpaceChange: opts.Whitespace.IgnoreSpaceChange,
+		IgnoreBlankLines:  opts.Whitespace.IgnoreBlankLines,
+		Algorithm:         string(git.DefaultDiffAlgorithm()),
+		Level:             string(llm.DefaultLevel()),
+		Language:          llm.DefaultLanguage(),
+		Client:            "cli",
+	}
+	if opts.Model != "" {
+		// Resolved here, so prefixes and provider:model work as they do
+		// locally.
+		req.Provider, req.Model = string(cfg.Provider), cfg.Model
+	}
+	req.Refine = opts.Refine
+	return req, true
+}
+
+// runOnServer answers an AI command through the running server for the
+// repository. handled is false when there is no server or it cannot take
+// the request, and the caller then runs the command itself.
+func runOnServer(g git.Repository, cfg config.Config, kind string, opts llmCommandOptions) (handled bool, err error) {
+	// The server reads git repositories only.
+	if _, isGit := g.(*git.GitExtractor); noServer || !isGit {
+		return false, nil
+	}
+	req, ok := serverRequest(kind, cfg, opts)
+	if !ok {
+		return false, nil
+	}
+	server, ok := discovery.ForRepo(g.RepoPath())
+	if !ok {
+		return false, nil
+	}
+
+	fmt.Fprintln(os.Stderr, color.HiBlackString(i18n.T("cli.server.using", server.URL)))
+	resp, err := api.Client{BaseURL: server.URL, Token: api.ClientToken()}.AI(kind, req)
+	if errors.Is(err, api.ErrServerUnauthorized) || errors.Is(err, api.ErrServerOffline) || errors.Is(err, api.ErrServerUnreachable) {
+		fmt.Fprintln(os.Stderr, color.YellowString(i18n.T("cli.server.fallback", err)))
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+
+	if kind == "review" {
+		printFindings(resp.Findings)
+	}
+	labels := map[string]string{
+		"explain": i18n.T("cli.label.explanation"),
+		"review":  i18n.T("cli.label.review"),
+		"summary": i18n.T("cli.label.summary"),
+		"ask":     i18n.T("cli.label.answer"),
+	}
+	label := labels[kind]
+	if resp.Refined {
+		label = i18n.T("cli.label.refined", label)
+	}
+	fmt.Printf("%s\n\n", color.GreenString("📝 "+label+":"))
+	if resp.Budget != nil {
+		if notice := resp.Budget.Notice(); notice != "" {
+			fmt.Println(color.YellowString(notice) + "\n")
+		}
+	}
+	fmt.Println(renderMarkdown(resp.Content))
+	if kind == "review" {
+		if repoCfg, err := config.LoadRepoConfig(g.RepoPath()); err == nil {
+			printRubricScorecard(resp.Content, repoCfg.Rubric)
+		}
+	}
+	// The server keeps the session and progress; only the output is left.
+	served := cfg
+	served.Provider, served.Model = config.LLMProvider(resp.Provider), resp.Model
+	return true, deliverOutput(g, served, kind, opts, resp.Content)
+}
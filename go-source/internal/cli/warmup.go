@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/git"
+	"difflearn-go/internal/i18n"
+)
+
+// Page sizes of the history the TUI and the web UI load first; warmup
+// requests the same pages so their cache entries match.
+const (
+	tuiHistoryPage = 50
+	webHistoryPage = 30
+)
+
+func warmupCmd(repoPath *string) *cobra.Command {
+	var commits, branches int
+	var clear bool
+	cmd := &cobra.Command{
+		Use:   "warmup",
+		Short: "Pre-compute the git data the TUI and web UI load first",
+		Long:  "Runs the slow git commands of a first session ahead of time: the repository's file tree, the recent history and how each branch differs from the default branch. Their output is kept in the git cache (DIFFLEARN_GIT_CACHE) and, being tied to commit hashes, stays valid until the commits are gone.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			dir := git.CacheDir()
+			if dir == "" {
+				return fmt.Errorf("%s", i18n.T("cli.warmup.off"))
+			}
+			if clear {
+				if err := git.ClearCache(dir); err != nil {
+					return err
+				}
+				fmt.Println(color.GreenString(i18n.T("cli.warmup.cleared", dir)))
+				return nil
+			}
+			runner := git.NewCachingRunner(git.ExecRunner{}, dir)
+			g := git.NewGitExtractorWithRunner(*repoPath, runner)
+			if !g.IsRepo() {
+				return fmt.Errorf("not a git repository")
+			}
+
+			step := func(label string, run func() error) error {
+				start := time.Now()
+				if err := run(); err != nil {
+					return fmt.Errorf("%s: %w", label, err)
+				}
+				fmt.Printf("%s %s %s\n", color.GreenString("✓"), label, color.HiBlackString(time.Since(start).Round(time.Millisecond).String()))
+				return nil
+			}
+			if err := step(i18n.T("cli.warmup.tree"), func() error {
+				if _, err := g.GetRepoInfo(); err != nil {
+					return err
+				}
+				_, err := g.GetRepoProfile()
+				return err
+			}); err != nil {
+				return err
+			}
+			if err := step(i18n.T("cli.warmup.history", commits), func() error {
+				if _, err := g.GetCommitHistoryPage("", 0, tuiHistoryPage, 0); err != nil {
+					return err
+				}
+				cursor := ""
+				for loaded := 0; loaded < commits; {
+					page, err := g.GetCommitHistoryPage(cursor, 0, webHistoryPage, 0)
+					if err != nil {
+						return err
+					}
+					loaded += len(page.Commits)
+					if !page.HasMore {
+						break
+					}
+					cursor = page.NextCursor
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			if base := g.DefaultBranch(); base != "" && branches > 0 {
+				list, err := g.GetBranchesDetailed()
+				if err != nil {
+					return err
+				}
+				warmed := 0
+				if err := step(i18n.T("cli.warmup.branches", base), func() error {
+					for _, b := range list {
+						if warmed == branches {
+							break
+						}
+						if b.Name == base || b.LocalName == base && b.Kind == git.BranchKindRemote {
+							continue
+						}
+						if _, err := g.GetBranchNumstat(base, b.Name); err != nil {
+							return err
+						}
+						warmed++
+					}
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
+
+			stats := runner.Stats()
+			fmt.Println(i18n.T("cli.warmup.done", stats.Misses, stats.Hits, dir))
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&commits, "commits", 300, "Commits of history to load")
+	cmd.Flags().IntVar(&branches, "branches", 50, "Branches to compare with the default branch (local ones first)")
+	cmd.Flags().BoolVar(&clear, "clear", false, "Delete the git cache instead of filling it")
+	return cmd
+}
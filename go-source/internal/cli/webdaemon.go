@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"difflearn-go/internal/daemon"
+	"difflearn-go/internal/discovery"
+	"difflearn-go/internal/i18n"
+)
+
+func webStartCmd(repoPath *string) *cobra.Command {
+	var port int
+	var open bool
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Run the web UI in the background",
+		Long:  "Starts the web server detached from the terminal and returns once it listens. Its pid file and log live in the DiffLearn data directory (DIFFLEARN_DATA_DIR); only one background server runs at a time.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			repo, err := filepath.Abs(*repoPath)
+			if err != nil {
+				return err
+			}
+			if server, ok := discovery.ForRepo(repo); ok {
+				fmt.Println(color.YellowString(i18n.T("cli.web.alreadyRunning", server.URL, server.PID)))
+				return nil
+			}
+			info, err := daemon.Start(port, repo, "--repo", repo, "web", "--port", strconv.Itoa(port), "--no-browser")
+			if errors.Is(err, daemon.ErrRunning) {
+				fmt.Println(color.YellowString(i18n.T("cli.web.alreadyRunning", info.URL(), info.PID)))
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Println(color.GreenString(i18n.T("cli.web.started", info.URL(), info.PID)))
+			fmt.Println(color.HiBlackString(i18n.T("cli.web.log", daemon.LogFile())))
+			if open {
+				return openBrowser(info.URL())
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&port, "port", "p", 3000, "Port for web server")
+	cmd.Flags().BoolVar(&open, "open", false, "Open the web UI in the browser once it runs")
+	return cmd
+}
+
+func webStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the background web UI",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := daemon.Stop()
+			if errors.Is(err, daemon.ErrNotRunning) {
+				fmt.Println(color.YellowString(i18n.T("cli.web.notRunning")))
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Println(i18n.T("cli.web.stopped", info.PID))
+			return nil
+		},
+	}
+}
+
+func webStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the background web UI is running",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, running, err := daemon.Status()
+			if err != nil {
+				return err
+			}
+			if !running {
+				fmt.Println(i18n.T("cli.web.notRunning"))
+				return nil
+			}
+			fmt.Println(color.GreenString(i18n.T("cli.web.running", info.URL(), info.PID)))
+			fmt.Println(i18n.T("cli.web.repo", info.Repo))
+			fmt.Println(color.HiBlackString(i18n.T("cli.web.log", daemon.LogFile())))
+			return nil
+		},
+	}
+}
+
+func webListCmd() *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the running DiffLearn web servers",
+		Long:  "Lists every running web server, in the background or in a terminal, from the discovery files they keep in the data directory.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			servers, err := discovery.List()
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				if servers == nil {
+					servers = []discovery.Server{}
+				}
+				b, err := json.MarshalIndent(servers, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+			if len(servers) == 0 {
+				fmt.Println(i18n.T("cli.web.none"))
+				return nil
+			}
+			for _, s := range servers {
+				fmt.Printf("%s  %s  %s\n", color.CyanString(s.URL), s.Repo, color.HiBlackString("pid %d, %s", s.PID, s.Version))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the servers as JSON")
+	return cmd
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// AuthState is what `difflearn auth` found out about a CLI provider.
+type AuthState string
+
+const (
+	AuthNotInstalled AuthState = "not-installed"
+	AuthSignedIn     AuthState = "signed-in"
+	AuthSignedOut    AuthState = "signed-out"
+	AuthUnknown      AuthState = "unknown"
+)
+
+const (
+	authCheckTimeout = 15 * time.Second
+	// maxAuthDetail caps how much of a status command's output is kept.
+	maxAuthDetail = 300
+)
+
+// CLIAuthStatus reports whether a CLI provider is installed and signed in.
+// Detail is the check command's own output.
+type CLIAuthStatus struct {
+	Provider LLMProvider `json:"provider"`
+	Command  string      `json:"command"`
+	State    AuthState   `json:"state"`
+	Detail   string      `json:"detail,omitempty"`
+}
+
+// CLIProviders lists the providers that run through a local CLI agent.
+func CLIProviders() []LLMProvider {
+	return []LLMProvider{ProviderGeminiCLI, ProviderClaude, ProviderCodex, ProviderCursor}
+}
+
+// ParseCLIProvider accepts a CLI provider by name or by command, e.g.
+// "claude-code", "claude", "cursor-cli", "cursor" or "agent".
+func ParseCLIProvider(name string) (LLMProvider, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, p := range CLIProviders() {
+		if name == string(p) || name == providerDefaultsMap[p].command || name == strings.TrimSuffix(strings.TrimSuffix(string(p), "-cli"), "-code") {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// CheckCLIAuth runs the provider's status command when it has one. A CLI
+// without such a command is reported as AuthUnknown once it is installed.
+func CheckCLIAuth(provider LLMProvider) CLIAuthStatus {
+	d := providerDefaultsMap[provider]
+	status := CLIAuthStatus{Provider: provider, Command: d.command, State: AuthUnknown}
+	installed := IsCLIAvailable(d.command)
+	if provider == ProviderCursor {
+		installed = IsCursorAgentAvailable()
+	}
+	if !installed {
+		status.State = AuthNotInstalled
+		return status
+	}
+	if len(d.authCheck) == 0 {
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), authCheckTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, d.authCheck[0], d.authCheck[1:]...).CombinedOutput()
+	status.Detail = strings.TrimSpace(string(out))
+	if len(status.Detail) > maxAuthDetail {
+		status.Detail = status.Detail[:maxAuthDetail] + "…"
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		status.State = AuthUnknown
+	case errors.As(err, &exitErr) || signedOutOutput(status.Detail):
+		status.State = AuthSignedOut
+	case err != nil:
+		status.State = AuthUnknown
+	default:
+		status.State = AuthSignedIn
+	}
+	return status
+}
+
+// signedOutOutput catches status commands that exit 0 while saying the
+// user is not signed in.
+func signedOutOutput(out string) bool {
+	out = strings.ToLower(out)
+	for _, phrase := range []string{"not logged in", "not signed in", "not authenticated", "logged out", "unauthenticated"} {
+		if strings.Contains(out, phrase) {
+			return true
+		}
+	}
+	return false
+}
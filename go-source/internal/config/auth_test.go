@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeCLI puts an executable shell script named name on a fresh PATH.
+func fakeCLI(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckCLIAuth(t *testing.T) {
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+
+	if s := CheckCLIAuth(ProviderCodex); s.State != AuthNotInstalled {
+		t.Fatalf("missing codex: %+v", s)
+	}
+
+	fakeCLI(t, bin, "codex", `echo "Logged in using ChatGPT"`)
+	if s := CheckCLIAuth(ProviderCodex); s.State != AuthSignedIn || s.Detail != "Logged in using ChatGPT" {
+		t.Fatalf("signed-in codex: %+v", s)
+	}
+	fakeCLI(t, bin, "codex", `echo "Not logged in"; exit 1`)
+	if s := CheckCLIAuth(ProviderCodex); s.State != AuthSignedOut {
+		t.Fatalf("signed-out codex: %+v", s)
+	}
+
+	fakeCLI(t, bin, "agent", `case "$1" in --version) echo "Cursor Agent 1.0";; status) echo "Not logged in";; esac`)
+	if s := CheckCLIAuth(ProviderCursor); s.State != AuthSignedOut {
+		t.Fatalf("cursor agent exiting 0 while signed out: %+v", s)
+	}
+
+	fakeCLI(t, bin, "claude", "exit 0")
+	if s := CheckCLIAuth(ProviderClaude); s.State != AuthUnknown {
+		t.Fatalf("claude has no status command: %+v", s)
+	}
+}
+
+func TestParseCLIProvider(t *testing.T) {
+	for name, want := range map[string]LLMProvider{
+		"claude": ProviderClaude, "claude-code": ProviderClaude, "gemini": ProviderGeminiCLI,
+		"cursor": ProviderCursor, "agent": ProviderCursor, "Codex": ProviderCodex,
+	} {
+		if got, ok := ParseCLIProvider(name); !ok || got != want {
+			t.Errorf("ParseCLIProvider(%q) = %q, %v", name, got, ok)
+		}
+	}
+	if _, ok := ParseCLIProvider("openai"); ok {
+		t.Error("openai is not a CLI provider")
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type LLMProvider string
@@ -21,16 +23,26 @@ const (
 	ProviderClaude    LLMProvider = "claude-code"
 	ProviderCodex     LLMProvider = "codex"
 	ProviderCursor    LLMProvider = "cursor-cli"
+	// ProviderOpenAICompatible is any other server speaking the OpenAI chat
+	// completions API, such as OpenRouter, Groq or vLLM, at
+	// DIFFLEARN_BASE_URL.
+	ProviderOpenAICompatible LLMProvider = "openai-compatible"
 )
 
 type Config struct {
-	Provider    LLMProvider
-	Model       string
-	APIKey      string
-	BaseURL     string
-	Temperature float64
-	MaxTokens   int
-	UseCLI      bool
+	Provider      LLMProvider
+	Model         string
+	APIKey        string
+	BaseURL       string
+	Temperature   float64
+	MaxTokens     int
+	ContextTokens int
+	UseCLI        bool
+	RefineReview  bool
+	// Level overrides the audience level for one request; see llm.Level.
+	Level string
+	// Language overrides the language answers are written in.
+	Language string
 }
 
 type providerDefaults struct {
@@ -43,45 +55,91 @@ type providerDefaults struct {
 	authCmd   []string
 	authHint  []string
 	authCheck []string
+	// contextWindow is the default model's context size in tokens.
+	contextWindow int
 }
 
 var providerDefaultsMap = map[LLMProvider]providerDefaults{
-	ProviderOpenAI:    {model: "gpt-4o", envKey: "OPENAI_API_KEY"},
-	ProviderAnthropic: {model: "claude-sonnet-4-20250514", envKey: "ANTHROPIC_API_KEY"},
-	ProviderGoogle:    {model: "gemini-2.0-flash", envKey: "GOOGLE_AI_API_KEY"},
-	ProviderOllama:    {model: "llama3.2", noAPIKey: true, baseURL: "http://localhost:11434/v1"},
-	ProviderLMStudio:  {model: "local-model", noAPIKey: true, baseURL: "http://localhost:1234/v1"},
-	ProviderGeminiCLI: {model: "gemini", cli: true, command: "gemini", authCmd: []string{"gemini"}},
-	ProviderClaude:    {model: "claude", cli: true, command: "claude", authCmd: []string{"claude"}},
-	ProviderCodex:     {model: "codex", cli: true, command: "codex", authCmd: []string{"codex", "login"}, authCheck: []string{"codex", "login", "status"}},
-	ProviderCursor:    {model: "cursor", cli: true, command: "agent", authCmd: []string{"agent", "login"}, authCheck: []string{"agent", "status"}},
+	ProviderOpenAI:    {model: "gpt-4o", envKey: "OPENAI_API_KEY", contextWindow: 128000},
+	ProviderAnthropic: {model: "claude-sonnet-4-20250514", envKey: "ANTHROPIC_API_KEY", contextWindow: 200000},
+	ProviderGoogle:    {model: "gemini-2.0-flash", envKey: "GOOGLE_AI_API_KEY", contextWindow: 1048576},
+	ProviderOllama:    {model: "llama3.2", noAPIKey: true, baseURL: "http://localhost:11434/v1", contextWindow: 4096},
+	ProviderLMStudio:  {model: "local-model", noAPIKey: true, baseURL: "http://localhost:1234/v1", contextWindow: 4096},
+	ProviderGeminiCLI: {model: "gemini", cli: true, command: "gemini", authCmd: []string{"gemini"}, contextWindow: 1048576},
+	ProviderClaude:    {model: "claude", cli: true, command: "claude", authCmd: []string{"claude"}, authHint: []string{"/login"}, contextWindow: 200000},
+	ProviderCodex:     {model: "codex", cli: true, command: "codex", authCmd: []string{"codex", "login"}, authCheck: []string{"codex", "login", "status"}, contextWindow: 200000},
+	ProviderCursor:    {model: "cursor", cli: true, command: "agent", authCmd: []string{"agent", "login"}, authCheck: []string{"agent", "status"}, contextWindow: 200000},
+	// The endpoint and model are the user's, and a key is optional since
+	// self-hosted servers often take none.
+	ProviderOpenAICompatible: {envKey: "DIFFLEARN_API_KEY"},
 }
 
-func loadConfigFromFile() map[string]string {
+// FilePath is the user's settings file, ~/.difflearn. It returns "" when
+// the home directory is unknown.
+func FilePath() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return map[string]string{}
+		return ""
+	}
+	return filepath.Join(home, ".difflearn")
+}
+
+// FileSettings returns the keys set in ~/.difflearn.
+func FileSettings() map[string]string {
+	return loadConfigFromFile()
+}
+
+// IsSecretSetting reports whether key holds a credential: API keys,
+// tokens, passwords and webhook URLs.
+func IsSecretSetting(key string) bool {
+	key = strings.ToUpper(key)
+	for _, marker := range []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "WEBHOOK"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func loadConfigFromFile() map[string]string {
+	global, _ := loadSettingsFile()
+	return global
+}
+
+// loadSettingsFile reads ~/.difflearn: the keys before the first
+// [profile <name>] line, and the keys of each profile.
+func loadSettingsFile() (map[string]string, map[string]map[string]string) {
+	global, profiles := map[string]string{}, map[string]map[string]string{}
+	p := FilePath()
+	if p == "" {
+		return global, profiles
 	}
-	p := filepath.Join(home, ".difflearn")
 	f, err := os.Open(p)
 	if err != nil {
-		return map[string]string{}
+		return global, profiles
 	}
 	defer f.Close()
 
-	out := map[string]string{}
+	out := global
 	s := bufio.NewScanner(f)
 	for s.Scan() {
 		line := strings.TrimSpace(s.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
+		if name, ok := profileHeader(line); ok {
+			if profiles[name] == nil {
+				profiles[name] = map[string]string{}
+			}
+			out = profiles[name]
+			continue
+		}
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) == 2 {
 			out[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
 		}
 	}
-	return out
+	return global, profiles
 }
 
 func LoadConfig() Config {
@@ -96,10 +154,20 @@ func LoadConfig() Config {
 	if provider == "" {
 		provider = DetectProvider()
 	}
+	// Without API keys, an installed CLI agent brings its own sign-in.
+	if provider == "" {
+		provider = DetectCLIProvider()
+	}
 	if provider == "" {
 		provider = ProviderOpenAI
 	}
 
+	cfg := configForProvider(provider, os.Getenv("DIFFLEARN_MODEL"))
+	cfg.RefineReview = parseBool(os.Getenv("DIFFLEARN_REFINE_REVIEW"))
+	return cfg
+}
+
+func configForProvider(provider LLMProvider, model string) Config {
 	d, ok := providerDefaultsMap[provider]
 	if !ok {
 		provider = ProviderOpenAI
@@ -114,26 +182,155 @@ func LoadConfig() Config {
 
 	temp, _ := strconv.ParseFloat(defaultStr(os.Getenv("DIFFLEARN_TEMPERATURE"), "0.3"), 64)
 	maxTokens, _ := strconv.Atoi(defaultStr(os.Getenv("DIFFLEARN_MAX_TOKENS"), "4096"))
+	contextTokens, _ := strconv.Atoi(defaultStr(os.Getenv("DIFFLEARN_CONTEXT_TOKENS"), "100000"))
 	baseURL := os.Getenv("DIFFLEARN_BASE_URL")
 	if baseURL == "" {
 		baseURL = d.baseURL
 	}
 
 	return Config{
-		Provider:    provider,
-		Model:       defaultStr(os.Getenv("DIFFLEARN_MODEL"), d.model),
-		APIKey:      apiKey,
-		BaseURL:     baseURL,
-		Temperature: temp,
-		MaxTokens:   maxTokens,
-		UseCLI:      d.cli,
+		Provider:      provider,
+		Model:         defaultStr(model, d.model),
+		APIKey:        apiKey,
+		BaseURL:       baseURL,
+		Temperature:   temp,
+		MaxTokens:     maxTokens,
+		ContextTokens: contextTokens,
+		UseCLI:        d.cli,
+	}
+}
+
+// Retries returns how many times an API request that is rate limited or
+// hits a server error is retried (DIFFLEARN_RETRIES, default 3).
+func Retries() int {
+	n, err := strconv.Atoi(Setting("DIFFLEARN_RETRIES"))
+	if err != nil || n < 0 {
+		return 3
+	}
+	return n
+}
+
+// UILanguage returns the configured interface language (DIFFLEARN_UI_LANG).
+// It is separate from the language the LLM answers in.
+func UILanguage() string {
+	return Setting("DIFFLEARN_UI_LANG")
+}
+
+// Accessible reports whether screen-reader friendly output is enabled
+// (DIFFLEARN_ACCESSIBLE).
+func Accessible() bool {
+	v, _ := strconv.ParseBool(Setting("DIFFLEARN_ACCESSIBLE"))
+	return v
+}
+
+// DiffAlgorithm returns the configured git diff algorithm
+// (DIFFLEARN_DIFF_ALGORITHM); empty means git's default.
+func DiffAlgorithm() string {
+	return Setting("DIFFLEARN_DIFF_ALGORITHM")
+}
+
+// Level returns the configured audience level (DIFFLEARN_LEVEL): beginner,
+// intermediate or expert. Empty leaves the prompts as they are.
+func Level() string {
+	return Setting("DIFFLEARN_LEVEL")
+}
+
+// Language returns the natural language AI answers are written in
+// (DIFFLEARN_LANGUAGE), a name or a code such as de; empty lets the model
+// choose. The interface language is set separately by DIFFLEARN_UI_LANG.
+func Language() string {
+	return Setting("DIFFLEARN_LANGUAGE")
+}
+
+// SyntaxHighlight reports whether terminal diffs color code by language
+// (DIFFLEARN_SYNTAX_HIGHLIGHT, on unless set to false).
+func SyntaxHighlight() bool {
+	v, err := strconv.ParseBool(Setting("DIFFLEARN_SYNTAX_HIGHLIGHT"))
+	return err != nil || v
+}
+
+// RepoContext reports whether AI requests tell the model about the
+// repository's languages and frameworks (DIFFLEARN_REPO_CONTEXT, on unless
+// set to false).
+func RepoContext() bool {
+	v, err := strconv.ParseBool(Setting("DIFFLEARN_REPO_CONTEXT"))
+	return err != nil || v
+}
+
+// MDNS reports whether the web server advertises itself over multicast DNS
+// (DIFFLEARN_MDNS, off unless set to true).
+func MDNS() bool {
+	v, _ := strconv.ParseBool(Setting("DIFFLEARN_MDNS"))
+	return v
+}
+
+// GitWorkers is how many git commands the web server runs at once
+// (DIFFLEARN_GIT_WORKERS, default the number of CPUs, at least 2).
+func GitWorkers() int {
+	if n, err := strconv.Atoi(Setting("DIFFLEARN_GIT_WORKERS")); err == nil && n > 0 {
+		return n
+	}
+	return max(2, runtime.NumCPU())
+}
+
+// GitTimeout bounds the git work of one web server request, queueing
+// included (DIFFLEARN_GIT_TIMEOUT, e.g. 30s; default 60s).
+func GitTimeout() time.Duration {
+	v := Setting("DIFFLEARN_GIT_TIMEOUT")
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return d
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
 	}
+	return 60 * time.Second
+}
+
+// DataDir is where DiffLearn keeps its own data, such as the AI history
+// (DIFFLEARN_DATA_DIR, default <user config dir>/difflearn).
+func DataDir() string {
+	if dir := Setting("DIFFLEARN_DATA_DIR"); dir != "" {
+		return dir
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "difflearn")
+}
+
+// GitCacheDir is where the output of git commands pinned to commit hashes
+// is kept between runs (DIFFLEARN_GIT_CACHE, on unless set to false; a path
+// moves it out of the data directory). It is empty when the cache is off.
+func GitCacheDir() string {
+	v := Setting("DIFFLEARN_GIT_CACHE")
+	if on, err := strconv.ParseBool(v); err == nil {
+		if !on {
+			return ""
+		}
+		v = ""
+	}
+	if v != "" {
+		return v
+	}
+	return filepath.Join(DataDir(), "git-cache")
+}
+
+// Setting reads key from the environment, falling back to ~/.difflearn.
+func Setting(key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return loadConfigFromFile()[key]
 }
 
 func IsLLMAvailable(c Config) bool {
 	if c.UseCLI || c.Provider == ProviderOllama || c.Provider == ProviderLMStudio {
 		return true
 	}
+	if c.Provider == ProviderOpenAICompatible {
+		return strings.TrimSpace(c.BaseURL) != "" && strings.TrimSpace(c.Model) != ""
+	}
 	return strings.TrimSpace(c.APIKey) != ""
 }
 
@@ -187,10 +384,23 @@ func GetCLIAuthCommand(provider LLMProvider) []string {
 	return providerDefaultsMap[provider].authCmd
 }
 
+// GetCLIAuthHint returns what to type inside the provider's interactive
+// session to sign in, when its auth command does not do it by itself.
 func GetCLIAuthHint(provider LLMProvider) []string {
 	return providerDefaultsMap[provider].authHint
 }
 
+// GetCLIAuthCheck returns the command that reports whether the provider's
+// CLI is signed in, or nil when it has none.
+func GetCLIAuthCheck(provider LLMProvider) []string {
+	return providerDefaultsMap[provider].authCheck
+}
+
+func parseBool(v string) bool {
+	b, _ := strconv.ParseBool(strings.TrimSpace(v))
+	return b
+}
+
 func defaultStr(v, d string) string {
 	if strings.TrimSpace(v) == "" {
 		return d
@@ -54,3 +54,48 @@ func TestLoadConfigFromDotfile(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFallsBackToCLIProvider(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("DIFFLEARN_LLM_PROVIDER", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+	t.Setenv("DIFFLEARN_MODEL", "")
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+
+	if cfg := LoadConfig(); cfg.Provider != ProviderOpenAI || IsLLMAvailable(cfg) {
+		t.Fatalf("no keys or CLIs: %+v", cfg)
+	}
+	fakeCLI(t, bin, "claude", "exit 0")
+	cfg := LoadConfig()
+	if cfg.Provider != ProviderClaude || !IsLLMAvailable(cfg) {
+		t.Fatalf("expected claude CLI, got %+v", cfg)
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "key")
+	if cfg := LoadConfig(); cfg.Provider != ProviderAnthropic {
+		t.Fatalf("API key should win over the CLI, got %s", cfg.Provider)
+	}
+}
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("DIFFLEARN_LLM_PROVIDER", "openai-compatible")
+	t.Setenv("DIFFLEARN_BASE_URL", "")
+	t.Setenv("DIFFLEARN_API_KEY", "")
+	t.Setenv("DIFFLEARN_MODEL", "")
+
+	if cfg := LoadConfig(); cfg.Provider != ProviderOpenAICompatible || IsLLMAvailable(cfg) {
+		t.Fatalf("without an endpoint the provider is not configured: %+v", cfg)
+	}
+	t.Setenv("DIFFLEARN_BASE_URL", "https://openrouter.ai/api/v1")
+	t.Setenv("DIFFLEARN_MODEL", "deepseek/deepseek-chat")
+	cfg := LoadConfig()
+	if !IsLLMAvailable(cfg) || cfg.APIKey != "" || cfg.BaseURL != "https://openrouter.ai/api/v1" {
+		t.Fatalf("a keyless endpoint should be usable: %+v", cfg)
+	}
+	t.Setenv("DIFFLEARN_API_KEY", "or-key")
+	if cfg := LoadConfig(); cfg.APIKey != "or-key" {
+		t.Fatalf("expected the key from DIFFLEARN_API_KEY, got %q", cfg.APIKey)
+	}
+}
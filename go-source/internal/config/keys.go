@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettingKey is a setting `difflearn config set` knows: the variable it is
+// stored as, a short alias and how values are checked.
+type SettingKey struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias"`
+	Help  string `json:"help"`
+	// Values lists the accepted values when there are only a few.
+	Values   []string           `json:"values,omitempty"`
+	validate func(string) error `json:"-"`
+}
+
+// Secret reports whether the setting holds a credential.
+func (k SettingKey) Secret() bool {
+	return IsSecretSetting(k.Name)
+}
+
+// Validate checks value for the setting.
+func (k SettingKey) Validate(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("%s: values cannot span lines", k.Alias)
+	}
+	if len(k.Values) > 0 && !containsFold(k.Values, value) {
+		return fmt.Errorf("%s: %q is not one of %s", k.Alias, value, strings.Join(k.Values, ", "))
+	}
+	if k.validate != nil {
+		if err := k.validate(value); err != nil {
+			return fmt.Errorf("%s: %w", k.Alias, err)
+		}
+	}
+	return nil
+}
+
+func containsFold(values []string, v string) bool {
+	for _, x := range values {
+		if strings.EqualFold(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBool(v string) error {
+	if _, err := strconv.ParseBool(v); err != nil {
+		return fmt.Errorf("%q is not true or false", v)
+	}
+	return nil
+}
+
+func isPositiveInt(v string) error {
+	if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+		return fmt.Errorf("%q is not a positive whole number", v)
+	}
+	return nil
+}
+
+func isNonNegativeInt(v string) error {
+	if n, err := strconv.Atoi(v); err != nil || n < 0 {
+		return fmt.Errorf("%q is not a whole number of 0 or more", v)
+	}
+	return nil
+}
+
+func isTemperature(v string) error {
+	if t, err := strconv.ParseFloat(v, 64); err != nil || t < 0 || t > 2 {
+		return fmt.Errorf("%q is not a number between 0 and 2", v)
+	}
+	return nil
+}
+
+func isTimeout(v string) error {
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return nil
+	}
+	if isPositiveInt(v) == nil {
+		return nil
+	}
+	return fmt.Errorf("%q is not a duration such as 30s or 2m", v)
+}
+
+func isURL(v string) error {
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", v)
+	}
+	return nil
+}
+
+func isFallback(v string) error {
+	for _, entry := range strings.Split(v, ",") {
+		provider, _, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if _, ok := providerDefaultsMap[LLMProvider(provider)]; !ok {
+			return fmt.Errorf("%q is not a provider; use one of %s", provider, strings.Join(providerNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// isHost accepts OLLAMA_HOST's host:port as well as a URL.
+func isHost(v string) error {
+	if strings.Contains(v, "://") {
+		return isURL(v)
+	}
+	if strings.ContainsAny(v, " /") {
+		return fmt.Errorf("%q is not a host or host:port", v)
+	}
+	return nil
+}
+
+func isGitCache(v string) error {
+	if strings.EqualFold(v, "true") || strings.EqualFold(v, "false") {
+		return nil
+	}
+	if _, err := strconv.ParseBool(v); err == nil {
+		return nil
+	}
+	if !strings.ContainsAny(v, `/\`) {
+		return fmt.Errorf("%q is neither true, false nor a directory", v)
+	}
+	return nil
+}
+
+func providerNames() []string {
+	names := make([]string, 0, len(KnownProviders()))
+	for _, p := range KnownProviders() {
+		names = append(names, string(p))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SettingKeys are the settings `config set` accepts.
+var SettingKeys = []SettingKey{
+	{Name: "DIFFLEARN_LLM_PROVIDER", Alias: "provider", Help: "LLM provider", Values: providerNames()},
+	{Name: "DIFFLEARN_MODEL", Alias: "model", Help: "Model of the provider"},
+	{Name: "DIFFLEARN_TEMPERATURE", Alias: "temperature", Help: "Sampling temperature, 0 to 2", validate: isTemperature},
+	{Name: "DIFFLEARN_MAX_TOKENS", Alias: "max-tokens", Help: "Most tokens an answer may use", validate: isPositiveInt},
+	{Name: "DIFFLEARN_CONTEXT_TOKENS", Alias: "context-tokens", Help: "Context window the prompt must fit in", validate: isPositiveInt},
+	{Name: "DIFFLEARN_BASE_URL", Alias: "base-url", Help: "API base URL of an OpenAI-style endpoint", validate: isURL},
+	{Name: "DIFFLEARN_RETRIES", Alias: "retries", Help: "How often a rate-limited or failed API request is retried, 0 for never", validate: isNonNegativeInt},
+	{Name: "DIFFLEARN_FALLBACK", Alias: "fallback", Help: "Providers to try in order when the active one fails, comma-separated provider or provider:model", validate: isFallback},
+	{Name: "DIFFLEARN_ALLOWED_MODELS", Alias: "allowed-models", Help: "Models requests may switch to, comma-separated provider:model"},
+	{Name: "DIFFLEARN_REFINE_REVIEW", Alias: "refine-review", Help: "Run a second pass over reviews", validate: isBool},
+	{Name: "DIFFLEARN_REPO_CONTEXT", Alias: "repo-context", Help: "Tell the model about the repository's languages and frameworks", validate: isBool},
+	{Name: "DIFFLEARN_LLM_BUDGET", Alias: "llm-budget", Help: "Daily and monthly token or request caps"},
+	{Name: "DIFFLEARN_LEVEL", Alias: "level", Help: "Audience of AI answers", Values: []string{"beginner", "intermediate", "expert"}},
+	{Name: "DIFFLEARN_LANGUAGE", Alias: "language", Help: "Language AI answers are written in"},
+	{Name: "DIFFLEARN_UI_LANG", Alias: "ui-lang", Help: "Language of DiffLearn's own messages"},
+	{Name: "DIFFLEARN_ACCESSIBLE", Alias: "accessible", Help: "Screen-reader friendly output", validate: isBool},
+	{Name: "DIFFLEARN_SYNTAX_HIGHLIGHT", Alias: "syntax-highlight", Help: "Color diffs by language", validate: isBool},
+	{Name: "DIFFLEARN_DIFF_ALGORITHM", Alias: "diff-algorithm", Help: "Diff algorithm", Values: []string{"myers", "minimal", "patience", "histogram"}},
+	{Name: "DIFFLEARN_DATA_DIR", Alias: "data-dir", Help: "Where progress, history and caches are kept"},
+	{Name: "DIFFLEARN_GIT_WORKERS", Alias: "git-workers", Help: "Git commands the web server runs at once", validate: isPositiveInt},
+	{Name: "DIFFLEARN_GIT_TIMEOUT", Alias: "git-timeout", Help: "Git time limit of a web request", validate: isTimeout},
+	{Name: "DIFFLEARN_GIT_CACHE", Alias: "git-cache", Help: "Keep commit-pinned git output: true, false or a directory", validate: isGitCache},
+	{Name: "DIFFLEARN_MDNS", Alias: "mdns", Help: "Announce web servers on the local network", validate: isBool},
+	{Name: "DIFFLEARN_API_TOKENS", Alias: "api-tokens", Help: "Tokens the web server accepts"},
+	{Name: "DIFFLEARN_SERVER_TOKEN", Alias: "server-token", Help: "Token the CLI sends to a running web server"},
+	{Name: "DIFFLEARN_FORGE", Alias: "forge", Help: "Forge of pull requests", Values: []string{"github", "gitlab", "bitbucket"}},
+	{Name: "DIFFLEARN_TICKET_TRACKER", Alias: "ticket-tracker", Help: "Tracker tickets are read from", Values: []string{"jira", "linear"}},
+	{Name: "DIFFLEARN_TEAM_URL", Alias: "team-url", Help: "Team server URL", validate: isURL},
+	{Name: "DIFFLEARN_TEAM_TOKEN", Alias: "team-token", Help: "Team server token"},
+	{Name: "DIFFLEARN_TEAM_MEMBER", Alias: "team-member", Help: "Your name on the team server"},
+	{Name: "DIFFLEARN_GOAL_COMMITS", Alias: "goal-commits", Help: "Commits to study per week", validate: isPositiveInt},
+	{Name: "DIFFLEARN_GOAL_EXPLANATIONS", Alias: "goal-explanations", Help: "Explanations to read per week", validate: isPositiveInt},
+	{Name: "DIFFLEARN_PROFILE", Alias: "profile", Help: "Profile of ~/.difflearn to use by default"},
+	{Name: "DIFFLEARN_NO_SETUP", Alias: "no-setup", Help: "Skip the first-run setup", validate: isBool},
+	{Name: "OPENAI_API_KEY", Alias: "openai-api-key", Help: "OpenAI API key"},
+	{Name: "ANTHROPIC_API_KEY", Alias: "anthropic-api-key", Help: "Anthropic API key"},
+	{Name: "GOOGLE_AI_API_KEY", Alias: "google-api-key", Help: "Google AI API key"},
+	{Name: "DIFFLEARN_API_KEY", Alias: "api-key", Help: "API key of the openai-compatible endpoint"},
+	{Name: "OLLAMA_HOST", Alias: "ollama-host", Help: "Ollama server", validate: isHost},
+	{Name: "GITHUB_TOKEN", Alias: "github-token", Help: "GitHub token"},
+	{Name: "GITHUB_API_URL", Alias: "github-api-url", Help: "GitHub Enterprise API URL", validate: isURL},
+	{Name: "GITLAB_TOKEN", Alias: "gitlab-token", Help: "GitLab token"},
+	{Name: "GITLAB_URL", Alias: "gitlab-url", Help: "Self-hosted GitLab URL", validate: isURL},
+	{Name: "BITBUCKET_TOKEN", Alias: "bitbucket-token", Help: "Bitbucket token"},
+	{Name: "BITBUCKET_USERNAME", Alias: "bitbucket-username", Help: "Bitbucket user for app passwords"},
+	{Name: "BITBUCKET_APP_PASSWORD", Alias: "bitbucket-app-password", Help: "Bitbucket app password"},
+	{Name: "JIRA_URL", Alias: "jira-url", Help: "Jira site", validate: isURL},
+	{Name: "JIRA_EMAIL", Alias: "jira-email", Help: "Jira account email"},
+	{Name: "JIRA_API_TOKEN", Alias: "jira-api-token", Help: "Jira API token"},
+	{Name: "LINEAR_API_KEY", Alias: "linear-api-key", Help: "Linear API key"},
+	{Name: "LINEAR_API_URL", Alias: "linear-api-url", Help: "Linear API URL", validate: isURL},
+}
+
+// LookupSettingKey finds a setting by alias (provider) or variable name
+// (DIFFLEARN_LLM_PROVIDER), ignoring case and accepting _ for -.
+func LookupSettingKey(name string) (SettingKey, error) {
+	want := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", "-"))
+	for _, k := range SettingKeys {
+		if want == k.Alias || want == strings.ToLower(strings.ReplaceAll(k.Name, "_", "-")) {
+			return k, nil
+		}
+	}
+	return SettingKey{}, fmt.Errorf("unknown setting %q; `difflearn config keys` lists them", name)
+}
+
+// SetSetting validates value and writes it to ~/.difflearn, into a
+// profile's section unless profile is "". Values of settings with a fixed
+// list are stored as listed, so Provider=OpenAI is saved as openai.
+func SetSetting(profile, name, value string) (SettingKey, error) {
+	k, err := LookupSettingKey(name)
+	if err != nil {
+		return k, err
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return k, fmt.Errorf("%s: empty value; use `difflearn config unset %s` to remove it", k.Alias, k.Alias)
+	}
+	if err := k.Validate(value); err != nil {
+		return k, err
+	}
+	for _, v := range k.Values {
+		if strings.EqualFold(v, value) {
+			value = v
+		}
+	}
+	return k, SaveProfileSettings(profile, map[string]string{k.Name: value})
+}
+
+// UnsetSetting removes a setting from ~/.difflearn, or from a profile's
+// section, and reports whether it was there.
+func UnsetSetting(profile, name string) (SettingKey, bool, error) {
+	k, err := LookupSettingKey(name)
+	if err != nil {
+		return k, false, err
+	}
+	settings := FileSettings()
+	if profile != "" {
+		settings = Profiles()[profile]
+	}
+	if _, ok := settings[k.Name]; !ok {
+		return k, false, nil
+	}
+	return k, true, SaveProfileSettings(profile, map[string]string{k.Name: ""})
+}
+
+// MaskSecret hides all but the last four characters of a credential.
+func MaskSecret(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", 8) + value[len(value)-4:]
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLookupSettingKey(t *testing.T) {
+	for _, name := range []string{"provider", "Provider", "DIFFLEARN_LLM_PROVIDER", "difflearn-llm-provider"} {
+		k, err := LookupSettingKey(name)
+		if err != nil || k.Name != "DIFFLEARN_LLM_PROVIDER" {
+			t.Fatalf("%s: got %+v, %v", name, k, err)
+		}
+	}
+	if _, err := LookupSettingKey("colour"); err == nil {
+		t.Fatal("expected an unknown key to fail")
+	}
+	seen := map[string]bool{}
+	for _, k := range SettingKeys {
+		if seen[k.Alias] || seen[k.Name] {
+			t.Fatalf("duplicate setting %s", k.Alias)
+		}
+		seen[k.Alias], seen[k.Name] = true, true
+	}
+}
+
+func TestSettingKeyValidate(t *testing.T) {
+	cases := []struct {
+		key, value string
+		ok         bool
+	}{
+		{"provider", "anthropic", true},
+		{"provider", "OpenAI", true},
+		{"provider", "skynet", false},
+		{"temperature", "0.3", true},
+		{"temperature", "3", false},
+		{"max-tokens", "2048", true},
+		{"max-tokens", "-1", false},
+		{"refine-review", "yes", false},
+		{"git-timeout", "45s", true},
+		{"git-timeout", "soon", false},
+		{"git-cache", "/var/cache/difflearn", true},
+		{"git-cache", "maybe", false},
+		{"base-url", "https://api.example.com/v1", true},
+		{"base-url", "api.example.com", false},
+		{"ollama-host", "127.0.0.1:11434", true},
+		{"model", "gpt-4o\nOPENAI_API_KEY=x", false},
+	}
+	for _, c := range cases {
+		k, err := LookupSettingKey(c.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := k.Validate(c.value); (err == nil) != c.ok {
+			t.Errorf("%s=%q: got %v", c.key, c.value, err)
+		}
+	}
+}
+
+func TestSetAndUnsetSetting(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".difflearn")
+	start := "# keys\nDIFFLEARN_LLM_PROVIDER=openai\n\n# tuning\nDIFFLEARN_TEMPERATURE=0.5\nDIFFLEARN_LLM_PROVIDER=ollama\n"
+	if err := os.WriteFile(path, []byte(start), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetSetting("", "provider", "Anthropic"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetSetting("", "temperature", "9"); err == nil {
+		t.Fatal("expected an invalid value to be refused")
+	}
+	b, _ := os.ReadFile(path)
+	want := "# keys\nDIFFLEARN_LLM_PROVIDER=anthropic\n\n# tuning\nDIFFLEARN_TEMPERATURE=0.5\n"
+	if string(b) != want {
+		t.Fatalf("got %q, want %q", b, want)
+	}
+
+	if _, removed, err := UnsetSetting("", "temperature"); err != nil || !removed {
+		t.Fatalf("unset: %v %v", removed, err)
+	}
+	if _, removed, _ := UnsetSetting("", "temperature"); removed {
+		t.Fatal("a second unset should find nothing")
+	}
+	b, _ = os.ReadFile(path)
+	if strings.Contains(string(b), "TEMPERATURE") || !strings.Contains(string(b), "# tuning") {
+		t.Fatalf("unexpected file: %q", b)
+	}
+	entries, _ := os.ReadDir(home)
+	if len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	if got := MaskSecret("sk-abcdefghijkl"); got != "********ijkl" {
+		t.Fatalf("got %q", got)
+	}
+	if got := MaskSecret("short"); got != "*****" {
+		t.Fatalf("got %q", got)
+	}
+}
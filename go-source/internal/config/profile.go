@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	profileMu     sync.RWMutex
+	activeProfile string
+)
+
+// profileHeader reads a "[profile <name>]" line of ~/.difflearn.
+func profileHeader(line string) (string, bool) {
+	inner, ok := strings.CutPrefix(line, "[")
+	if !ok {
+		return "", false
+	}
+	inner, ok = strings.CutSuffix(inner, "]")
+	if !ok {
+		return "", false
+	}
+	name, ok := strings.CutPrefix(strings.TrimSpace(inner), "profile ")
+	name = strings.TrimSpace(name)
+	return name, ok && name != ""
+}
+
+// Profiles returns the settings of every profile in ~/.difflearn.
+func Profiles() map[string]map[string]string {
+	_, profiles := loadSettingsFile()
+	return profiles
+}
+
+// ProfileNames lists the profiles in ~/.difflearn, sorted.
+func ProfileNames() []string {
+	names := make([]string, 0)
+	for name := range Profiles() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ChooseProfile picks the profile to use: flag (--profile), then
+// DIFFLEARN_PROFILE from the environment, then the repository's profile in
+// .difflearn.yaml, then DIFFLEARN_PROFILE in ~/.difflearn. "" means none.
+func ChooseProfile(flag, repoPath string) string {
+	if flag != "" {
+		return flag
+	}
+	if name := os.Getenv("DIFFLEARN_PROFILE"); name != "" {
+		return name
+	}
+	if repoCfg, err := LoadRepoConfig(repoPath); err == nil && repoCfg.Profile != "" {
+		return repoCfg.Profile
+	}
+	return loadConfigFromFile()["DIFFLEARN_PROFILE"]
+}
+
+// UseProfile applies the settings of a profile in ~/.difflearn for the rest
+// of the process. They win over the environment and the rest of the file,
+// since picking a profile is an explicit choice. An empty name does nothing.
+func UseProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	settings, ok := Profiles()[name]
+	if !ok {
+		names := ProfileNames()
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q: ~/.difflearn defines none", name)
+		}
+		return fmt.Errorf("unknown profile %q (want one of %s)", name, strings.Join(names, ", "))
+	}
+	for k, v := range settings {
+		_ = os.Setenv(k, v)
+	}
+	profileMu.Lock()
+	activeProfile = name
+	profileMu.Unlock()
+	return nil
+}
+
+// ActiveProfile is the profile UseProfile applied, or "".
+func ActiveProfile() string {
+	profileMu.RLock()
+	defer profileMu.RUnlock()
+	return activeProfile
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const profileFile = `# mine
+DIFFLEARN_LLM_PROVIDER=openai
+DIFFLEARN_MODEL=gpt-4o
+
+# office
+[profile work]
+DIFFLEARN_BASE_URL=https://example.openai.azure.com/v1
+DIFFLEARN_MODEL=gpt-4o-mini
+
+[profile home]
+DIFFLEARN_LLM_PROVIDER=ollama
+DIFFLEARN_MODEL=llama3.2
+`
+
+func writeProfileFile(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".difflearn")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProfilesAreKeptApart(t *testing.T) {
+	writeProfileFile(t, profileFile)
+	if got := FileSettings()["DIFFLEARN_MODEL"]; got != "gpt-4o" {
+		t.Fatalf("top-level model = %q", got)
+	}
+	profiles := Profiles()
+	if len(profiles) != 2 || profiles["home"]["DIFFLEARN_LLM_PROVIDER"] != "ollama" || profiles["work"]["DIFFLEARN_MODEL"] != "gpt-4o-mini" {
+		t.Fatalf("unexpected profiles: %v", profiles)
+	}
+	if names := ProfileNames(); len(names) != 2 || names[0] != "home" {
+		t.Fatalf("names %v", names)
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	writeProfileFile(t, profileFile)
+	t.Setenv("DIFFLEARN_LLM_PROVIDER", "anthropic")
+	t.Setenv("DIFFLEARN_MODEL", "")
+	t.Cleanup(func() { activeProfile = "" })
+	if err := UseProfile("nope"); err == nil {
+		t.Fatal("expected an unknown profile to fail")
+	}
+	if err := UseProfile("home"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := LoadConfig()
+	if cfg.Provider != ProviderOllama || cfg.Model != "llama3.2" || ActiveProfile() != "home" {
+		t.Fatalf("profile not applied: %+v (%s)", cfg, ActiveProfile())
+	}
+}
+
+func TestChooseProfile(t *testing.T) {
+	writeProfileFile(t, "DIFFLEARN_PROFILE=home\n[profile home]\n[profile work]\n")
+	repo := t.TempDir()
+	t.Setenv("DIFFLEARN_PROFILE", "")
+	if got := ChooseProfile("", repo); got != "home" {
+		t.Fatalf("file default: %q", got)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".difflearn.yaml"), []byte("profile: work\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := ChooseProfile("", repo); got != "work" {
+		t.Fatalf("repository default: %q", got)
+	}
+	t.Setenv("DIFFLEARN_PROFILE", "ci")
+	if got := ChooseProfile("", repo); got != "ci" {
+		t.Fatalf("environment: %q", got)
+	}
+	if got := ChooseProfile("home", repo); got != "home" {
+		t.Fatalf("flag: %q", got)
+	}
+}
+
+func TestSaveProfileSettings(t *testing.T) {
+	path := writeProfileFile(t, profileFile)
+	if _, err := SetSetting("work", "model", "gpt-4.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetSetting("work", "temperature", "0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetSetting("", "level", "expert"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetSetting("lab", "provider", "lmstudio"); err != nil {
+		t.Fatal(err)
+	}
+	if _, removed, err := UnsetSetting("home", "model"); err != nil || !removed {
+		t.Fatalf("unset: %v %v", removed, err)
+	}
+	b, _ := os.ReadFile(path)
+	want := `# mine
+DIFFLEARN_LLM_PROVIDER=openai
+DIFFLEARN_MODEL=gpt-4o
+DIFFLEARN_LEVEL=expert
+
+# office
+[profile work]
+DIFFLEARN_BASE_URL=https://example.openai.azure.com/v1
+DIFFLEARN_MODEL=gpt-4.1
+DIFFLEARN_TEMPERATURE=0.1
+
+[profile home]
+DIFFLEARN_LLM_PROVIDER=ollama
+
+[profile lab]
+DIFFLEARN_LLM_PROVIDER=lmstudio
+`
+	if string(b) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", b, want)
+	}
+
+	path = writeProfileFile(t, "# only comments\n\n[profile work]\nDIFFLEARN_MODEL=x\n")
+	if _, err := SetSetting("", "model", "y"); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = os.ReadFile(path)
+	if want := "# only comments\nDIFFLEARN_MODEL=y\n\n[profile work]\nDIFFLEARN_MODEL=x\n"; string(b) != want {
+		t.Fatalf("got %q, want %q", b, want)
+	}
+}
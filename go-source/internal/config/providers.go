@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type ProviderOption struct {
+	Provider  LLMProvider `json:"provider"`
+	Models    []string    `json:"models"`
+	Available bool        `json:"available"`
+	Active    bool        `json:"active"`
+}
+
+func KnownProviders() []LLMProvider {
+	providers := make([]LLMProvider, 0, len(providerDefaultsMap))
+	for p := range providerDefaultsMap {
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+// DefaultModel is the model provider uses when none is configured. For CLI
+// providers it is a placeholder meaning the CLI's own default.
+func DefaultModel(provider LLMProvider) string {
+	return providerDefaultsMap[provider].model
+}
+
+// ContextWindow is how many tokens the provider's default model accepts.
+// Local servers report their usual default context, which is far below
+// what the models support unless raised in the server's settings.
+func ContextWindow(provider LLMProvider) int {
+	return providerDefaultsMap[provider].contextWindow
+}
+
+// ProviderConfig is the configuration provider would get if it were the
+// active one, keeping DIFFLEARN_MODEL only when it is.
+func ProviderConfig(active Config, provider LLMProvider) (Config, error) {
+	if provider == active.Provider {
+		return active, nil
+	}
+	d, ok := providerDefaultsMap[provider]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown provider: %s", provider)
+	}
+	cfg := configForProvider(provider, "")
+	// DIFFLEARN_BASE_URL belongs to the active provider; a local server
+	// switched to keeps its usual address.
+	if d.baseURL != "" {
+		cfg.BaseURL = d.baseURL
+	}
+	return cfg, nil
+}
+
+// Fallbacks are the providers to try, in order, when the active one fails
+// (DIFFLEARN_FALLBACK, comma-separated provider or provider:model entries
+// such as "openai:gpt-4o-mini,claude-code"). Entries that are unknown, not
+// configured or the same as active are left out; the rest keep active's
+// generation settings.
+func Fallbacks(active Config) []Config {
+	var chain []Config
+	for _, entry := range strings.Split(Setting("DIFFLEARN_FALLBACK"), ",") {
+		provider, model, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if provider == "" {
+			continue
+		}
+		cfg, err := ProviderConfig(active, LLMProvider(provider))
+		if err != nil {
+			continue
+		}
+		if model = strings.TrimSpace(model); model != "" {
+			cfg.Model = model
+		}
+		if (cfg.Provider == active.Provider && cfg.Model == active.Model) || !IsLLMAvailable(cfg) {
+			continue
+		}
+		cfg.Temperature = active.Temperature
+		cfg.MaxTokens = active.MaxTokens
+		cfg.ContextTokens = active.ContextTokens
+		cfg.Level = active.Level
+		cfg.Language = active.Language
+		chain = append(chain, cfg)
+	}
+	return chain
+}
+
+// ModelAllowlist returns the provider -> models map that per-request overrides
+// are validated against. DIFFLEARN_ALLOWED_MODELS takes entries such as
+// "openai:gpt-4o,anthropic:*"; without it each provider allows its default
+// model, plus the active model for the active provider.
+func ModelAllowlist(active Config) map[LLMProvider][]string {
+	allow := map[LLMProvider][]string{}
+	raw := strings.TrimSpace(os.Getenv("DIFFLEARN_ALLOWED_MODELS"))
+	if raw == "" {
+		for p, d := range providerDefaultsMap {
+			allow[p] = []string{d.model}
+		}
+		allow[active.Provider] = appendUnique(allow[active.Provider], active.Model)
+		return allow
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		provider, model, found := strings.Cut(entry, ":")
+		p := LLMProvider(strings.TrimSpace(provider))
+		if _, ok := providerDefaultsMap[p]; !ok {
+			continue
+		}
+		if !found || strings.TrimSpace(model) == "" {
+			model = providerDefaultsMap[p].model
+		}
+		allow[p] = appendUnique(allow[p], strings.TrimSpace(model))
+	}
+	return allow
+}
+
+func ListProviderOptions(active Config) []ProviderOption {
+	allow := ModelAllowlist(active)
+	options := make([]ProviderOption, 0, len(allow))
+	for _, p := range KnownProviders() {
+		models, ok := allow[p]
+		if !ok {
+			continue
+		}
+		options = append(options, ProviderOption{
+			Provider:  p,
+			Models:    models,
+			Available: IsLLMAvailable(configForProvider(p, "")),
+			Active:    p == active.Provider,
+		})
+	}
+	return options
+}
+
+// WithOverrides returns a copy of base configured for the requested provider
+// and model. Empty values keep the base setting.
+func WithOverrides(base Config, provider, model string) (Config, error) {
+	provider = strings.TrimSpace(provider)
+	model = strings.TrimSpace(model)
+	if provider == "" && model == "" {
+		return base, nil
+	}
+
+	target := base
+	if provider != "" && LLMProvider(provider) != base.Provider {
+		var err error
+		if target, err = ProviderConfig(base, LLMProvider(provider)); err != nil {
+			return Config{}, err
+		}
+		target.Temperature = base.Temperature
+		target.MaxTokens = base.MaxTokens
+		target.ContextTokens = base.ContextTokens
+		target.RefineReview = base.RefineReview
+		target.Level = base.Level
+		target.Language = base.Language
+	}
+	if model != "" {
+		target.Model = model
+	}
+
+	allowed := ModelAllowlist(base)[target.Provider]
+	if !containsModel(allowed, target.Model) {
+		return Config{}, fmt.Errorf("model %s is not allowed for provider %s", target.Model, target.Provider)
+	}
+	if !IsLLMAvailable(target) {
+		return Config{}, fmt.Errorf("provider %s is not configured", target.Provider)
+	}
+	return target, nil
+}
+
+func containsModel(models []string, model string) bool {
+	for _, m := range models {
+		if m == "*" || m == model {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, v string) []string {
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
+
+// ResolveModelSpec turns a user-supplied model reference into a validated
+// config. Specs may be "provider:model", a provider name, a full model name
+// or a prefix of an allowed model (e.g. "claude-sonnet").
+func ResolveModelSpec(active Config, spec string) (Config, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return Config{}, fmt.Errorf("empty model spec")
+	}
+	if provider, model, found := strings.Cut(spec, ":"); found {
+		if _, ok := providerDefaultsMap[LLMProvider(provider)]; ok {
+			return WithOverrides(active, provider, model)
+		}
+	}
+	if _, ok := providerDefaultsMap[LLMProvider(spec)]; ok {
+		return WithOverrides(active, spec, "")
+	}
+
+	allow := ModelAllowlist(active)
+	for _, exact := range []bool{true, false} {
+		for _, p := range KnownProviders() {
+			for _, m := range allow[p] {
+				if m == spec || (!exact && m != "*" && strings.HasPrefix(m, spec)) {
+					return WithOverrides(active, string(p), m)
+				}
+			}
+		}
+	}
+	return Config{}, fmt.Errorf("model %s is not in the allowed model list", spec)
+}
@@ -0,0 +1,89 @@
+package config
+
+import "testing"
+
+func TestWithOverridesValidatesAllowlist(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("ANTHROPIC_API_KEY", "other-key")
+	t.Setenv("DIFFLEARN_ALLOWED_MODELS", "openai:gpt-4o,openai:gpt-4o-mini,anthropic")
+
+	base := Config{Provider: ProviderOpenAI, Model: "gpt-4o", APIKey: "test-key", MaxTokens: 1000}
+
+	cfg, err := WithOverrides(base, "", "gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("WithOverrides() error = %v", err)
+	}
+	if cfg.Model != "gpt-4o-mini" || cfg.Provider != ProviderOpenAI {
+		t.Fatalf("unexpected override result: %+v", cfg)
+	}
+
+	cfg, err = WithOverrides(base, "anthropic", "")
+	if err != nil {
+		t.Fatalf("WithOverrides() provider error = %v", err)
+	}
+	if cfg.Provider != ProviderAnthropic || cfg.APIKey != "other-key" || cfg.MaxTokens != 1000 {
+		t.Fatalf("unexpected provider override: %+v", cfg)
+	}
+
+	if _, err := WithOverrides(base, "", "gpt-3.5-turbo"); err == nil {
+		t.Fatalf("expected disallowed model error")
+	}
+	if _, err := WithOverrides(base, "nope", ""); err == nil {
+		t.Fatalf("expected unknown provider error")
+	}
+}
+
+func TestListProviderOptionsDefaults(t *testing.T) {
+	t.Setenv("DIFFLEARN_ALLOWED_MODELS", "")
+	options := ListProviderOptions(Config{Provider: ProviderOllama, Model: "qwen2.5"})
+	for _, o := range options {
+		if o.Provider == ProviderOllama {
+			if !o.Active || len(o.Models) != 2 {
+				t.Fatalf("expected active ollama with default and active model, got %+v", o)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected ollama option")
+}
+
+func TestResolveModelSpec(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("ANTHROPIC_API_KEY", "other-key")
+	t.Setenv("DIFFLEARN_ALLOWED_MODELS", "")
+
+	base := Config{Provider: ProviderOpenAI, Model: "gpt-4o", APIKey: "test-key"}
+	cfg, err := ResolveModelSpec(base, "claude-sonnet")
+	if err != nil {
+		t.Fatalf("ResolveModelSpec() error = %v", err)
+	}
+	if cfg.Provider != ProviderAnthropic || cfg.Model != "claude-sonnet-4-20250514" {
+		t.Fatalf("unexpected resolution: %+v", cfg)
+	}
+	cfg, err = ResolveModelSpec(base, "gpt-4o")
+	if err != nil || cfg.Provider != ProviderOpenAI {
+		t.Fatalf("expected openai resolution, got %+v (%v)", cfg, err)
+	}
+	if _, err := ResolveModelSpec(base, "mystery-model"); err == nil {
+		t.Fatalf("expected unknown model error")
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("DIFFLEARN_FALLBACK", "ollama:qwen2.5, nope, openai, anthropic")
+	active := Config{Provider: ProviderOpenAI, Model: "gpt-4o", APIKey: "test-key", Temperature: 0.1, MaxTokens: 900}
+
+	chain := Fallbacks(active)
+	if len(chain) != 1 {
+		t.Fatalf("only ollama is usable and different from the active provider: %+v", chain)
+	}
+	if fb := chain[0]; fb.Provider != ProviderOllama || fb.Model != "qwen2.5" || fb.MaxTokens != 900 || fb.Temperature != 0.1 {
+		t.Fatalf("fallback = %+v", fb)
+	}
+	if _, err := SetSetting("", "fallback", "ollama,nope"); err == nil {
+		t.Fatal("unknown providers must be rejected")
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFiles are the per-repository settings files, checked in order.
+var RepoConfigFiles = []string{".difflearn.yaml", ".difflearn.yml"}
+
+type RubricCriterion struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Weight      float64 `json:"weight"`
+	// Gate makes a failing verdict on this criterion fail CI runs.
+	Gate bool `json:"gate,omitempty"`
+}
+
+// RepoConfig holds team settings committed to the repository in .difflearn.yaml.
+type RepoConfig struct {
+	Path   string            `json:"path,omitempty"`
+	Rubric []RubricCriterion `json:"rubric,omitempty"`
+	// OwnerWebhooks maps CODEOWNERS owners to chat webhook URLs, read from
+	// owners.webhooks.
+	OwnerWebhooks map[string]string `json:"ownerWebhooks,omitempty"`
+	// Profile is the ~/.difflearn profile used in this repository unless
+	// --profile or DIFFLEARN_PROFILE picks another.
+	Profile string `json:"profile,omitempty"`
+}
+
+// OwnerWebhook returns owner's webhook URL. A value such as $PAYMENTS_HOOK
+// is read from that environment variable, so secrets stay out of the file.
+func (c RepoConfig) OwnerWebhook(owner string) string {
+	url := c.OwnerWebhooks[owner]
+	if name, ok := strings.CutPrefix(url, "$"); ok {
+		return os.Getenv(name)
+	}
+	return url
+}
+
+// LoadRepoConfig reads .difflearn.yaml from repoPath. A missing file is not an
+// error and yields an empty RepoConfig.
+func LoadRepoConfig(repoPath string) (RepoConfig, error) {
+	if repoPath == "" {
+		repoPath = "."
+	}
+	for _, name := range RepoConfigFiles {
+		p := filepath.Join(repoPath, name)
+		b, err := os.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return RepoConfig{}, err
+		}
+		cfg, err := ParseRepoConfig(string(b))
+		if err != nil {
+			return RepoConfig{}, fmt.Errorf("%s: %w", name, err)
+		}
+		cfg.Path = p
+		return cfg, nil
+	}
+	return RepoConfig{}, nil
+}
+
+// repoConfigFile is the layout of .difflearn.yaml.
+type repoConfigFile struct {
+	Rubric []struct {
+		Name        string   `yaml:"name"`
+		Description string   `yaml:"description"`
+		Weight      *float64 `yaml:"weight"`
+		Gate        bool     `yaml:"gate"`
+	} `yaml:"rubric"`
+	Owners struct {
+		Webhooks map[string]string `yaml:"webhooks"`
+	} `yaml:"owners"`
+	Profile string `yaml:"profile"`
+}
+
+func ParseRepoConfig(src string) (RepoConfig, error) {
+	var file repoConfigFile
+	if err := yaml.Unmarshal([]byte(src), &file); err != nil {
+		return RepoConfig{}, err
+	}
+	cfg := RepoConfig{Profile: strings.TrimSpace(file.Profile)}
+	seen := map[string]bool{}
+	for i, item := range file.Rubric {
+		c := RubricCriterion{
+			Name:        strings.TrimSpace(item.Name),
+			Description: strings.TrimSpace(item.Description),
+			Weight:      1,
+			Gate:        item.Gate,
+		}
+		if c.Name == "" {
+			return RepoConfig{}, fmt.Errorf("rubric item %d is missing a name", i+1)
+		}
+		if seen[strings.ToLower(c.Name)] {
+			return RepoConfig{}, fmt.Errorf("rubric criterion %q is defined twice", c.Name)
+		}
+		seen[strings.ToLower(c.Name)] = true
+		if item.Weight != nil {
+			if *item.Weight < 0 {
+				return RepoConfig{}, fmt.Errorf("rubric criterion %q has a negative weight", c.Name)
+			}
+			c.Weight = *item.Weight
+		}
+		cfg.Rubric = append(cfg.Rubric, c)
+	}
+	if file.Owners.Webhooks != nil {
+		cfg.OwnerWebhooks = make(map[string]string, len(file.Owners.Webhooks))
+		for owner, url := range file.Owners.Webhooks {
+			cfg.OwnerWebhooks[owner] = strings.TrimSpace(url)
+		}
+	}
+	return cfg, nil
+}
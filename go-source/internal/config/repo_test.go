@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRepoConfigRubric(t *testing.T) {
+	src := `# team review rubric
+rubric:
+  - name: Correctness
+    description: "Logic errors, edge cases: nil, empty input"
+    weight: 3
+    gate: true
+  - name: Readability
+    description: >
+      Naming, structure and
+      comments.
+  - name: Tests # inline comment
+    weight: 0.5
+`
+	cfg, err := ParseRepoConfig(src)
+	if err != nil {
+		t.Fatalf("ParseRepoConfig() error = %v", err)
+	}
+	if len(cfg.Rubric) != 3 {
+		t.Fatalf("expected 3 criteria, got %+v", cfg.Rubric)
+	}
+	first := cfg.Rubric[0]
+	if first.Name != "Correctness" || first.Weight != 3 || !first.Gate || first.Description != "Logic errors, edge cases: nil, empty input" {
+		t.Fatalf("unexpected first criterion: %+v", first)
+	}
+	if cfg.Rubric[1].Weight != 1 || cfg.Rubric[1].Description != "Naming, structure and comments." {
+		t.Fatalf("unexpected default weight or folded description: %+v", cfg.Rubric[1])
+	}
+	if cfg.Rubric[2].Name != "Tests" || cfg.Rubric[2].Weight != 0.5 {
+		t.Fatalf("unexpected third criterion: %+v", cfg.Rubric[2])
+	}
+}
+
+func TestParseRepoConfigErrors(t *testing.T) {
+	cases := []string{
+		"rubric:\n  - description: missing name\n",
+		"rubric:\n  - name: A\n    weight: heavy\n",
+		"rubric:\n  - name: A\n  - name: a\n",
+	}
+	for _, src := range cases {
+		if _, err := ParseRepoConfig(src); err == nil {
+			t.Fatalf("expected error for %q", src)
+		}
+	}
+}
+
+func TestLoadRepoConfigMissingFile(t *testing.T) {
+	cfg, err := LoadRepoConfig(t.TempDir())
+	if err != nil || len(cfg.Rubric) != 0 {
+		t.Fatalf("expected empty config, got %+v, %v", cfg, err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".difflearn.yml"), []byte("rubric:\n- name: Security\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadRepoConfig(dir)
+	if err != nil || len(cfg.Rubric) != 1 || cfg.Rubric[0].Name != "Security" {
+		t.Fatalf("expected rubric from .difflearn.yml, got %+v, %v", cfg, err)
+	}
+}
+
+func TestParseRepoConfigOwnerWebhooks(t *testing.T) {
+	src := `owners:
+  webhooks:
+    "@acme/web": https://hooks.example.com/web
+    "@acme/data": $DATA_TEAM_HOOK
+`
+	cfg, err := ParseRepoConfig(src)
+	if err != nil {
+		t.Fatalf("ParseRepoConfig() error = %v", err)
+	}
+	t.Setenv("DATA_TEAM_HOOK", "https://hooks.example.com/data")
+	if got := cfg.OwnerWebhook("@acme/web"); got != "https://hooks.example.com/web" {
+		t.Fatalf("OwnerWebhook(@acme/web) = %q", got)
+	}
+	if got := cfg.OwnerWebhook("@acme/data"); got != "https://hooks.example.com/data" {
+		t.Fatalf("OwnerWebhook(@acme/data) = %q", got)
+	}
+	if got := cfg.OwnerWebhook("@acme/other"); got != "" {
+		t.Fatalf("OwnerWebhook(@acme/other) = %q", got)
+	}
+}
+
+func TestParseRepoConfigFullYAML(t *testing.T) {
+	src := `defaults: &strict
+  weight: 2
+  gate: true
+rubric:
+  - {name: Security, <<: *strict}
+  - name: Style
+    description: |
+      Naming and
+      formatting.
+owners: {webhooks: {"@acme/web": 'https://hooks.example.com/web'}}
+`
+	cfg, err := ParseRepoConfig(src)
+	if err != nil {
+		t.Fatalf("ParseRepoConfig() error = %v", err)
+	}
+	if len(cfg.Rubric) != 2 || cfg.Rubric[0].Weight != 2 || !cfg.Rubric[0].Gate || cfg.Rubric[1].Description != "Naming and\nformatting." {
+		t.Fatalf("unexpected rubric: %+v", cfg.Rubric)
+	}
+	if cfg.OwnerWebhook("@acme/web") != "https://hooks.example.com/web" {
+		t.Fatalf("unexpected webhooks: %+v", cfg.OwnerWebhooks)
+	}
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SetupOption is a provider the first-run setup can offer, with the reason
+// it was found and the model to configure.
+type SetupOption struct {
+	Provider LLMProvider
+	Model    string
+	Source   string
+}
+
+// NeedsSetup reports whether DiffLearn has never been configured: there is
+// no ~/.difflearn and no provider chosen in the environment.
+func NeedsSetup() bool {
+	if os.Getenv("DIFFLEARN_LLM_PROVIDER") != "" || parseBool(os.Getenv("DIFFLEARN_NO_SETUP")) {
+		return false
+	}
+	p := FilePath()
+	if p == "" {
+		return false
+	}
+	_, err := os.Stat(p)
+	return os.IsNotExist(err)
+}
+
+// DetectSetupOptions lists the providers that would work right away: API
+// keys in the environment, installed CLI agents (DetectCLIProvider's pick
+// first) and a running Ollama server.
+func DetectSetupOptions() []SetupOption {
+	var options []SetupOption
+	for _, p := range []LLMProvider{ProviderOpenAI, ProviderAnthropic, ProviderGoogle} {
+		d := providerDefaultsMap[p]
+		if os.Getenv(d.envKey) != "" {
+			options = append(options, SetupOption{Provider: p, Model: d.model, Source: d.envKey})
+		}
+	}
+	if preferred := DetectCLIProvider(); preferred != "" {
+		options = append(options, SetupOption{Provider: preferred, Model: providerDefaultsMap[preferred].model, Source: providerDefaultsMap[preferred].command})
+		for _, p := range []LLMProvider{ProviderGeminiCLI, ProviderClaude, ProviderCodex} {
+			if p != preferred && IsCLIAvailable(providerDefaultsMap[p].command) {
+				options = append(options, SetupOption{Provider: p, Model: providerDefaultsMap[p].model, Source: providerDefaultsMap[p].command})
+			}
+		}
+	}
+	if models, ok := OllamaModels(); ok {
+		model := providerDefaultsMap[ProviderOllama].model
+		if len(models) > 0 && !containsModel(models, model) && !containsModel(models, model+":latest") {
+			model = models[0]
+		}
+		options = append(options, SetupOption{Provider: ProviderOllama, Model: model, Source: ollamaHost()})
+	}
+	return options
+}
+
+// OllamaModels asks a local Ollama server for its installed models. ok is
+// false when no server answers.
+func OllamaModels() ([]string, bool) {
+	client := http.Client{Timeout: 700 * time.Millisecond}
+	resp, err := client.Get(ollamaHost() + "/api/tags")
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, true
+	}
+	models := make([]string, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		models = append(models, m.Name)
+	}
+	return models, true
+}
+
+func ollamaHost() string {
+	host := strings.TrimSpace(os.Getenv("OLLAMA_HOST"))
+	if host == "" {
+		return "http://localhost:11434"
+	}
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return strings.TrimRight(host, "/")
+}
+
+// APIKeyEnv is the environment variable holding provider's API key, or ""
+// for providers that need none.
+func APIKeyEnv(provider LLMProvider) string {
+	return providerDefaultsMap[provider].envKey
+}
+
+// SaveSettings writes values into ~/.difflearn, replacing existing keys in
+// place and appending new ones; an empty value removes the key. Comments and
+// other lines are kept. The file may hold API keys, so it is only readable by
+// the user, and it is replaced in one step so a failed write cannot cut it
+// short.
+func SaveSettings(values map[string]string) error {
+	return SaveProfileSettings("", values)
+}
+
+// SaveProfileSettings is SaveSettings for the [profile <name>] section of
+// ~/.difflearn, which is added when missing; "" is the keys before the
+// first profile.
+func SaveProfileSettings(profile string, values map[string]string) error {
+	p := FilePath()
+	if p == "" {
+		return fmt.Errorf("cannot locate the home directory")
+	}
+	existing, err := os.ReadFile(p)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	pending := make(map[string]string, len(values))
+	for k, v := range values {
+		pending[k] = v
+	}
+	var lines []string
+	if len(existing) == 0 {
+		lines = append(lines, "# DiffLearn settings; environment variables take precedence.")
+	}
+	// New keys go after the last line of the section, before the blank
+	// lines and comments leading into the next one.
+	section, found, insertAt, firstHeader := "", profile == "", -1, -1
+	for _, line := range strings.Split(strings.TrimRight(string(existing), "\n"), "\n") {
+		if len(existing) == 0 {
+			break
+		}
+		if name, ok := profileHeader(strings.TrimSpace(line)); ok {
+			if firstHeader < 0 {
+				firstHeader = len(lines)
+			}
+			section = name
+			if section == profile {
+				found, insertAt = true, len(lines)+1
+			}
+			lines = append(lines, line)
+			continue
+		}
+		if section != profile {
+			lines = append(lines, line)
+			continue
+		}
+		key, _, isKey := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if _, ok := values[key]; ok && isKey && !strings.HasPrefix(key, "#") {
+			// Later copies of a key would win over the replaced one.
+			v, first := pending[key]
+			delete(pending, key)
+			if !first || v == "" {
+				continue
+			}
+			line = key + "=" + v
+		}
+		lines = append(lines, line)
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			insertAt = len(lines)
+		}
+	}
+	keys := make([]string, 0, len(pending))
+	for k := range pending {
+		if pending[k] != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	added := make([]string, 0, len(keys)+2)
+	if insertAt < 0 {
+		// Keys before the first profile when there are none yet: after the
+		// comments the file starts with.
+		insertAt = len(lines)
+		if firstHeader >= 0 {
+			insertAt = firstHeader
+			for insertAt > 0 && strings.TrimSpace(lines[insertAt-1]) == "" {
+				insertAt--
+			}
+		}
+	}
+	if !found && len(keys) > 0 {
+		insertAt = len(lines)
+		if insertAt > 0 && strings.TrimSpace(lines[insertAt-1]) != "" {
+			added = append(added, "")
+		}
+		added = append(added, "[profile "+profile+"]")
+	}
+	for _, k := range keys {
+		added = append(added, k+"="+pending[k])
+	}
+	if insertAt < len(lines) && len(keys) > 0 {
+		if _, header := profileHeader(strings.TrimSpace(lines[insertAt])); header {
+			added = append(added, "")
+		}
+	}
+	lines = append(lines[:insertAt], append(added, lines[insertAt:]...)...)
+	return writeFileAtomic(p, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
+
+// writeFileAtomic replaces path through a temporary file; a symlinked
+// dotfile is written where the link points.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
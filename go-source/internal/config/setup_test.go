@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNeedsSetup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("DIFFLEARN_LLM_PROVIDER", "")
+	t.Setenv("DIFFLEARN_NO_SETUP", "")
+	if !NeedsSetup() {
+		t.Fatal("expected setup without ~/.difflearn")
+	}
+	t.Setenv("DIFFLEARN_LLM_PROVIDER", "ollama")
+	if NeedsSetup() {
+		t.Fatal("a provider in the environment counts as configured")
+	}
+	t.Setenv("DIFFLEARN_LLM_PROVIDER", "")
+	if err := SaveSettings(nil); err != nil {
+		t.Fatal(err)
+	}
+	if NeedsSetup() {
+		t.Fatal("an existing ~/.difflearn counts as configured")
+	}
+}
+
+func TestSaveSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".difflearn")
+	if err := os.WriteFile(path, []byte("# mine\nDIFFLEARN_MODEL=old\nDIFFLEARN_UI_LANG=de\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := SaveSettings(map[string]string{
+		"DIFFLEARN_MODEL":        "new",
+		"DIFFLEARN_UI_LANG":      "",
+		"DIFFLEARN_LLM_PROVIDER": "ollama",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# mine\nDIFFLEARN_MODEL=new\nDIFFLEARN_LLM_PROVIDER=ollama\n"
+	if string(b) != want {
+		t.Fatalf("got %q, want %q", b, want)
+	}
+
+	fresh := t.TempDir()
+	t.Setenv("HOME", fresh)
+	if err := SaveSettings(map[string]string{"OPENAI_API_KEY": "sk-test"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(fresh, ".difflearn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("settings file mode %v", info.Mode().Perm())
+	}
+	b, _ = os.ReadFile(filepath.Join(fresh, ".difflearn"))
+	if !strings.HasSuffix(string(b), "OPENAI_API_KEY=sk-test\n") {
+		t.Fatalf("unexpected file: %q", b)
+	}
+}
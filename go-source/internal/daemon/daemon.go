@@ -0,0 +1,206 @@
+// Package daemon runs the web server in the background and keeps track of
+// it with a pid file and a log file in the DiffLearn data directory.
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/discovery"
+)
+
+var (
+	// ErrRunning is returned by Start when a server is already running.
+	ErrRunning = errors.New("the web server is already running")
+	// ErrNotRunning is returned by Stop when no server is running.
+	ErrNotRunning = errors.New("the web server is not running")
+)
+
+// startTimeout is how long Start waits for the server to listen.
+const startTimeout = 10 * time.Second
+
+// Info describes a background server.
+type Info struct {
+	PID  int
+	Port int
+	Repo string
+}
+
+func (i Info) URL() string {
+	return fmt.Sprintf("http://localhost:%d", i.Port)
+}
+
+// PIDFile holds the pid, port and repository of the background server.
+func PIDFile() string {
+	return filepath.Join(config.DataDir(), "web.pid")
+}
+
+// LogFile collects the background server's output.
+func LogFile() string {
+	return filepath.Join(config.DataDir(), "web.log")
+}
+
+// Start runs the current executable with args detached from the terminal,
+// appending its output to LogFile, and waits until it listens on port. A
+// server that exits before that is reported with the end of its log.
+func Start(port int, repo string, args ...string) (Info, error) {
+	if info, running, err := Status(); err != nil {
+		return Info{}, err
+	} else if running {
+		return info, ErrRunning
+	}
+	// Something else on the port would answer the readiness check below.
+	if listening(port) {
+		return Info{}, fmt.Errorf("port %d is already in use", port)
+	}
+	if err := os.MkdirAll(config.DataDir(), 0o755); err != nil {
+		return Info{}, err
+	}
+	logFile, err := os.OpenFile(LogFile(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return Info{}, err
+	}
+	defer logFile.Close()
+	exe, err := os.Executable()
+	if err != nil {
+		return Info{}, err
+	}
+	fmt.Fprintf(logFile, "--- %s starting on port %d for %s\n", time.Now().Format(time.RFC3339), port, repo)
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return Info{}, err
+	}
+	info := Info{PID: cmd.Process.Pid, Port: port, Repo: repo}
+	if err := writePIDFile(info); err != nil {
+		_ = cmd.Process.Kill()
+		return Info{}, err
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	deadline := time.Now().Add(startTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-exited:
+			_ = os.Remove(PIDFile())
+			return Info{}, fmt.Errorf("the web server exited while starting:\n%s", tailLog(10))
+		case <-time.After(100 * time.Millisecond):
+		}
+		if listening(port) {
+			return info, nil
+		}
+	}
+	return info, fmt.Errorf("the web server did not listen on port %d within %s; see %s", port, startTimeout, LogFile())
+}
+
+// Status reads the pid file and reports whether its server is still
+// running. A pid file left behind by a server that died is removed, also
+// when its pid now belongs to another process.
+func Status() (Info, bool, error) {
+	info, err := readPIDFile()
+	if errors.Is(err, os.ErrNotExist) {
+		return Info{}, false, nil
+	}
+	if err != nil {
+		return Info{}, false, err
+	}
+	if !isServer(info) {
+		_ = os.Remove(PIDFile())
+		return info, false, nil
+	}
+	return info, true, nil
+}
+
+// isServer reports whether info's process is the server that wrote the pid
+// file: it is alive and its discovery file, which only a listening server
+// keeps, names the same pid. After a crash or a reboot the pid can belong to
+// any process, and Stop must not signal it.
+func isServer(info Info) bool {
+	if !alive(info.PID) {
+		return false
+	}
+	s, ok := discovery.ForPort(info.Port)
+	return ok && s.PID == info.PID
+}
+
+// Stop asks the background server to shut down, kills it when it is still
+// running after a few seconds, and removes the pid file.
+func Stop() (Info, error) {
+	info, running, err := Status()
+	if err != nil {
+		return Info{}, err
+	}
+	if !running {
+		return Info{}, ErrNotRunning
+	}
+	if err := terminate(info.PID); err != nil {
+		return info, err
+	}
+	for i := 0; i < 50 && alive(info.PID); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if alive(info.PID) {
+		if p, err := os.FindProcess(info.PID); err == nil {
+			_ = p.Kill()
+		}
+	}
+	return info, os.Remove(PIDFile())
+}
+
+func listening(port int) bool {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func writePIDFile(info Info) error {
+	return os.WriteFile(PIDFile(), []byte(fmt.Sprintf("%d\n%d\n%s\n", info.PID, info.Port, info.Repo)), 0o644)
+}
+
+func readPIDFile() (Info, error) {
+	data, err := os.ReadFile(PIDFile())
+	if err != nil {
+		return Info{}, err
+	}
+	lines := strings.SplitN(strings.TrimRight(string(data), "\n"), "\n", 3)
+	var info Info
+	if info.PID, err = strconv.Atoi(strings.TrimSpace(lines[0])); err != nil {
+		return Info{}, fmt.Errorf("%s: bad pid %q", PIDFile(), lines[0])
+	}
+	if len(lines) > 1 {
+		info.Port, _ = strconv.Atoi(strings.TrimSpace(lines[1]))
+	}
+	if len(lines) > 2 {
+		info.Repo = lines[2]
+	}
+	return info, nil
+}
+
+// tailLog returns the last n lines of the log file.
+func tailLog(n int) string {
+	data, err := os.ReadFile(LogFile())
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+
+	"difflearn-go/internal/discovery"
+)
+
+// listen stands in for a running server: a listening port with a discovery
+// file naming this process.
+func listen(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	port := l.Addr().(*net.TCPAddr).Port
+	if _, _, err := discovery.Register(discovery.Server{Port: port}); err != nil {
+		t.Fatal(err)
+	}
+	return port
+}
+
+func TestPIDFileRoundTrip(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	port := listen(t)
+	want := Info{PID: os.Getpid(), Port: port, Repo: "/work/my repo"}
+	if err := writePIDFile(want); err != nil {
+		t.Fatal(err)
+	}
+	got, running, err := Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !running || got != want {
+		t.Fatalf("got %+v (running %v), want %+v", got, running, want)
+	}
+	if got.URL() != fmt.Sprintf("http://localhost:%d", port) {
+		t.Fatalf("unexpected URL %q", got.URL())
+	}
+}
+
+func TestStatusRemovesStalePIDFile(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePIDFile(Info{PID: cmd.Process.Pid, Port: 3000}); err != nil {
+		t.Fatal(err)
+	}
+	if _, running, err := Status(); err != nil || running {
+		t.Fatalf("exited process reported running=%v err=%v", running, err)
+	}
+	if _, err := os.Stat(PIDFile()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("stale pid file should be removed, stat err=%v", err)
+	}
+	if _, err := Stop(); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestStatusIgnoresReusedPID(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	// This process is alive but is not the server on the port: the pid was
+	// reused after the server died.
+	if err := writePIDFile(Info{PID: os.Getpid(), Port: 3100}); err != nil {
+		t.Fatal(err)
+	}
+	if _, running, err := Status(); err != nil || running {
+		t.Fatalf("a foreign process reported running=%v err=%v", running, err)
+	}
+	if _, err := os.Stat(PIDFile()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("stale pid file should be removed, stat err=%v", err)
+	}
+
+	other := listen(t)
+	if err := writePIDFile(Info{PID: os.Getpid(), Port: other}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := discovery.Register(discovery.Server{PID: os.Getpid() + 1, Port: other}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Stop(); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Stop should not signal a process the discovery file does not name, got %v", err)
+	}
+}
+
+func TestStatusRejectsBadPIDFile(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	if err := os.WriteFile(PIDFile(), []byte("not-a-pid\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Status(); err == nil {
+		t.Fatal("expected an error for a malformed pid file")
+	}
+}
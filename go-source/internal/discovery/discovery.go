@@ -0,0 +1,156 @@
+// Package discovery lets running DiffLearn servers be found by editor
+// extensions, the MCP bridge and other DiffLearn commands: every server
+// writes a small JSON file into the data directory while it runs and can
+// also advertise itself over mDNS.
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+// Server is the content of a discovery file.
+type Server struct {
+	PID     int       `json:"pid"`
+	Port    int       `json:"port"`
+	URL     string    `json:"url"`
+	Repo    string    `json:"repo"`
+	Version string    `json:"version"`
+	Started time.Time `json:"started"`
+}
+
+// Dir holds one <port>.json file per running server.
+func Dir() string {
+	return filepath.Join(config.DataDir(), "servers")
+}
+
+func file(port int) string {
+	return filepath.Join(Dir(), fmt.Sprintf("%d.json", port))
+}
+
+// Register writes the discovery file of s, filling in PID, URL and Started
+// when they are empty, and returns a function that removes it again.
+func Register(s Server) (Server, func(), error) {
+	if s.PID == 0 {
+		s.PID = os.Getpid()
+	}
+	if s.URL == "" {
+		s.URL = fmt.Sprintf("http://localhost:%d", s.Port)
+	}
+	s.Repo = canonical(s.Repo)
+	if s.Started.IsZero() {
+		s.Started = time.Now().UTC()
+	}
+	if err := os.MkdirAll(Dir(), 0o755); err != nil {
+		return s, func() {}, err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return s, func() {}, err
+	}
+	path := file(s.Port)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return s, func() {}, err
+	}
+	return s, func() {
+		// A newer server on the same port owns the file by now.
+		if current, err := read(path); err == nil && current.PID == s.PID {
+			_ = os.Remove(path)
+		}
+	}, nil
+}
+
+// List returns the servers that are still listening, oldest first. Files of
+// servers that went away without cleaning up are removed.
+func List() ([]Server, error) {
+	entries, err := os.ReadDir(Dir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var servers []Server
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(Dir(), e.Name())
+		s, err := read(path)
+		if err != nil || !listening(s.Port) {
+			_ = os.Remove(path)
+			continue
+		}
+		servers = append(servers, s)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Started.Before(servers[j].Started) })
+	return servers, nil
+}
+
+// ForRepo returns the newest running server for the repository at path.
+func ForRepo(path string) (Server, bool) {
+	servers, err := List()
+	if err != nil {
+		return Server{}, false
+	}
+	want := canonical(path)
+	for i := len(servers) - 1; i >= 0; i-- {
+		if canonical(servers[i].Repo) == want {
+			return servers[i], true
+		}
+	}
+	return Server{}, false
+}
+
+// ForPort returns the server registered on port, while it still listens.
+func ForPort(port int) (Server, bool) {
+	s, err := read(file(port))
+	if err != nil || !listening(s.Port) {
+		return Server{}, false
+	}
+	return s, true
+}
+
+func read(path string) (Server, error) {
+	var s Server
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, err
+	}
+	if s.Port <= 0 {
+		return s, fmt.Errorf("%s: no port", path)
+	}
+	return s, nil
+}
+
+func listening(port int) bool {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// canonical makes repository paths comparable across symlinks and
+// trailing separators.
+func canonical(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	return strings.TrimRight(filepath.Clean(path), string(filepath.Separator))
+}
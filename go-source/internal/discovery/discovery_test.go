@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func listen(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestRegisterListAndForRepo(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	repo := t.TempDir()
+	port := listen(t)
+
+	s, remove, err := Register(Server{Port: port, Repo: repo, Version: "1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PID != os.Getpid() || s.URL == "" || s.Started.IsZero() {
+		t.Fatalf("Register should fill in pid, url and start time: %+v", s)
+	}
+	servers, err := List()
+	if err != nil || len(servers) != 1 || servers[0].Version != "1.2.3" {
+		t.Fatalf("List() = %+v, %v", servers, err)
+	}
+	if found, ok := ForRepo(filepath.Join(repo, ".")); !ok || found.Port != port {
+		t.Fatalf("ForRepo did not find the server: %+v %v", found, ok)
+	}
+	if _, ok := ForRepo(t.TempDir()); ok {
+		t.Fatal("ForRepo matched another repository")
+	}
+
+	remove()
+	if servers, _ := List(); len(servers) != 0 {
+		t.Fatalf("expected no servers after removal, got %+v", servers)
+	}
+}
+
+func TestListDropsServersThatStoppedListening(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	if _, _, err := Register(Server{Port: port, Repo: t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+	if servers, _ := List(); len(servers) != 0 {
+		t.Fatalf("expected the dead server to be dropped, got %+v", servers)
+	}
+	if _, err := os.Stat(file(port)); !os.IsNotExist(err) {
+		t.Fatalf("stale discovery file should be removed, stat err=%v", err)
+	}
+}
+
+func TestAdvertisementPacket(t *testing.T) {
+	ad := newAdvertisement(Server{PID: 42, Port: 3000, Repo: "/work/my.repo", Version: "1.0.0"})
+	ad.ips = []net.IP{net.ParseIP("192.168.1.20")}
+	packet, err := ad.packet(mdnsTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p dnsmessage.Parser
+	if _, err := p.Start(packet); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		t.Fatal(err)
+	}
+	answers, err := p.AllAnswers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var srv *dnsmessage.SRVResource
+	var txt *dnsmessage.TXTResource
+	for _, a := range answers {
+		switch body := a.Body.(type) {
+		case *dnsmessage.SRVResource:
+			srv = body
+		case *dnsmessage.TXTResource:
+			txt = body
+		}
+	}
+	if srv == nil || srv.Port != 3000 {
+		t.Fatalf("expected an SRV record for port 3000, got %+v", srv)
+	}
+	if txt == nil || txt.TXT[0] != "repo=/work/my.repo" {
+		t.Fatalf("expected the repository in TXT, got %+v", txt)
+	}
+	if ad.instance != "my-repo-3000."+ServiceType {
+		t.Fatalf("unexpected instance name %q", ad.instance)
+	}
+
+	query := dnsmessage.Question{Name: dnsmessage.MustNewName(ServiceType), Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET}
+	if !ad.answers(query) {
+		t.Fatal("a PTR query for the service type should be answered")
+	}
+	query.Name = dnsmessage.MustNewName("_http._tcp.local.")
+	if ad.answers(query) {
+		t.Fatal("queries for other services must be ignored")
+	}
+}
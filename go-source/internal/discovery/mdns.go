@@ -0,0 +1,198 @@
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ServiceType is the DNS-SD service DiffLearn servers advertise.
+const ServiceType = "_difflearn._tcp.local."
+
+const (
+	mdnsAddr = "224.0.0.251:5353"
+	mdnsTTL  = 120
+	// dnssdServices is the name DNS-SD browsers query to list service types.
+	dnssdServices = "_services._dns-sd._udp.local."
+)
+
+// advertisement holds the records of one server.
+type advertisement struct {
+	instance string
+	host     string
+	port     uint16
+	txt      []string
+	ips      []net.IP
+}
+
+func newAdvertisement(s Server) advertisement {
+	hostname, _ := os.Hostname()
+	if hostname = strings.Split(hostname, ".")[0]; hostname == "" {
+		hostname = "localhost"
+	}
+	// DNS labels hold at most 63 bytes and TXT strings 255.
+	label := strings.NewReplacer(".", "-", " ", "-").Replace(filepath.Base(s.Repo))
+	if len(label) > 50 {
+		label = label[:50]
+	}
+	repo := "repo=" + s.Repo
+	if len(repo) > 255 {
+		repo = repo[:255]
+	}
+	return advertisement{
+		instance: fmt.Sprintf("%s-%d.%s", label, s.Port, ServiceType),
+		host:     hostname + ".local.",
+		port:     uint16(s.Port),
+		txt:      []string{repo, "version=" + s.Version, fmt.Sprintf("pid=%d", s.PID), "path=/"},
+		ips:      localIPv4s(),
+	}
+}
+
+// answers reports whether a query for name and type is about this server.
+func (a advertisement) answers(q dnsmessage.Question) bool {
+	name := strings.ToLower(q.Name.String())
+	switch {
+	case name == ServiceType || name == dnssdServices:
+		return q.Type == dnsmessage.TypePTR || q.Type == dnsmessage.TypeALL
+	case name == strings.ToLower(a.instance):
+		return q.Type == dnsmessage.TypeSRV || q.Type == dnsmessage.TypeTXT || q.Type == dnsmessage.TypeALL
+	case name == strings.ToLower(a.host):
+		return q.Type == dnsmessage.TypeA || q.Type == dnsmessage.TypeALL
+	}
+	return false
+}
+
+// packet builds an unsolicited response with all records of the server;
+// ttl 0 withdraws them.
+func (a advertisement) packet(ttl uint32) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true, Authoritative: true})
+	b.EnableCompression()
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+	service := dnsmessage.MustNewName(ServiceType)
+	instance, err := dnsmessage.NewName(a.instance)
+	if err != nil {
+		return nil, err
+	}
+	host, err := dnsmessage.NewName(a.host)
+	if err != nil {
+		return nil, err
+	}
+	hdr := func(name dnsmessage.Name) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: ttl}
+	}
+	if err := b.PTRResource(hdr(dnsmessage.MustNewName(dnssdServices)), dnsmessage.PTRResource{PTR: service}); err != nil {
+		return nil, err
+	}
+	if err := b.PTRResource(hdr(service), dnsmessage.PTRResource{PTR: instance}); err != nil {
+		return nil, err
+	}
+	if err := b.SRVResource(hdr(instance), dnsmessage.SRVResource{Target: host, Port: a.port}); err != nil {
+		return nil, err
+	}
+	if err := b.TXTResource(hdr(instance), dnsmessage.TXTResource{TXT: a.txt}); err != nil {
+		return nil, err
+	}
+	for _, ip := range a.ips {
+		var addr [4]byte
+		copy(addr[:], ip.To4())
+		if err := b.AResource(hdr(host), dnsmessage.AResource{A: addr}); err != nil {
+			return nil, err
+		}
+	}
+	return b.Finish()
+}
+
+// Advertise announces s over multicast DNS as a ServiceType instance and
+// answers queries for it until the returned function is called, which also
+// withdraws the announcement.
+func Advertise(s Server) (func(), error) {
+	group, err := net.ResolveUDPAddr("udp4", mdnsAddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp4", nil, group)
+	if err != nil {
+		return nil, err
+	}
+	ad := newAdvertisement(s)
+	announcement, err := ad.packet(mdnsTTL)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		// RFC 6762 asks for at least two announcements a second apart.
+		for i := 0; i < 3; i++ {
+			_, _ = conn.WriteToUDP(announcement, group)
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Second << i):
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		buf := make([]byte, 9000)
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			h, err := p.Start(buf[:n])
+			if err != nil || h.Response {
+				continue
+			}
+			questions, err := p.AllQuestions()
+			if err != nil {
+				continue
+			}
+			for _, q := range questions {
+				if ad.answers(q) {
+					_, _ = conn.WriteToUDP(announcement, group)
+					break
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			if goodbye, err := ad.packet(0); err == nil {
+				_, _ = conn.WriteToUDP(goodbye, group)
+			}
+			conn.Close()
+			wg.Wait()
+		})
+	}, nil
+}
+
+func localIPv4s() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			ips = append(ips, ipnet.IP)
+		}
+	}
+	return ips
+}
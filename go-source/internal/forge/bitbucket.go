@@ -0,0 +1,128 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"difflearn-go/internal/config"
+)
+
+const defaultBitbucketAPI = "https://api.bitbucket.org/2.0"
+
+type Bitbucket struct {
+	httpClient
+	Token       string
+	Username    string
+	AppPassword string
+}
+
+// NewBitbucket authenticates with BITBUCKET_TOKEN (an access token) or with
+// BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD.
+func NewBitbucket() *Bitbucket {
+	return newBitbucket(defaultBitbucketAPI, config.Setting("BITBUCKET_TOKEN"), config.Setting("BITBUCKET_USERNAME"), config.Setting("BITBUCKET_APP_PASSWORD"))
+}
+
+func newBitbucket(base, token, username, appPassword string) *Bitbucket {
+	b := &Bitbucket{Token: token, Username: username, AppPassword: appPassword}
+	b.httpClient = newHTTPClient("bitbucket", base, "BITBUCKET_TOKEN", func(r *http.Request) {
+		switch {
+		case b.Token != "":
+			r.Header.Set("Authorization", "Bearer "+b.Token)
+		case b.Username != "" && b.AppPassword != "":
+			r.SetBasicAuth(b.Username, b.AppPassword)
+		}
+	})
+	return b
+}
+
+func (c *Bitbucket) GetChange(ref Ref) (Change, error) {
+	var pr struct {
+		ID          int    `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		State       string `json:"state"`
+		Author      struct {
+			DisplayName string `json:"display_name"`
+		} `json:"author"`
+		Source struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"source"`
+		Destination struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"destination"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	body, err := c.do(http.MethodGet, c.prPath(ref), "application/json", nil)
+	if err != nil {
+		return Change{}, err
+	}
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return Change{}, err
+	}
+	return Change{Number: pr.ID, Title: pr.Title, Body: pr.Description, State: pr.State, Author: pr.Author.DisplayName, BaseRef: pr.Destination.Branch.Name, HeadRef: pr.Source.Branch.Name, URL: pr.Links.HTML.Href}, nil
+}
+
+// GetIssue reads an issue from the repository's built-in issue tracker.
+func (c *Bitbucket) GetIssue(ref Ref) (Issue, error) {
+	var i struct {
+		ID      int    `json:"id"`
+		Title   string `json:"title"`
+		State   string `json:"state"`
+		Content struct {
+			Raw string `json:"raw"`
+		} `json:"content"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	body, err := c.do(http.MethodGet, fmt.Sprintf("/repositories/%s/%s/issues/%d", url.PathEscape(ref.Owner), url.PathEscape(ref.Repo), ref.Number), "application/json", nil)
+	if err != nil {
+		return Issue{}, err
+	}
+	if err := json.Unmarshal(body, &i); err != nil {
+		return Issue{}, err
+	}
+	return Issue{Number: i.ID, Title: i.Title, Body: i.Content.Raw, State: i.State, URL: i.Links.HTML.Href}, nil
+}
+
+// GetDiff returns the pull request as a unified diff.
+func (c *Bitbucket) GetDiff(ref Ref) (string, error) {
+	body, err := c.do(http.MethodGet, c.prPath(ref)+"/diff", "text/plain", nil)
+	return string(body), err
+}
+
+// PostComment adds a general comment to the pull request.
+func (c *Bitbucket) PostComment(ref Ref, text string) (string, error) {
+	if c.Token == "" && (c.Username == "" || c.AppPassword == "") {
+		return "", fmt.Errorf("posting to Bitbucket needs credentials: set BITBUCKET_TOKEN or BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD")
+	}
+	body, err := c.do(http.MethodPost, c.prPath(ref)+"/comments", "application/json", map[string]any{"content": map[string]string{"raw": text}})
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	_ = json.Unmarshal(body, &created)
+	return created.Links.HTML.Href, nil
+}
+
+func (c *Bitbucket) prPath(ref Ref) string {
+	return fmt.Sprintf("/repositories/%s/%s/pullrequests/%d", url.PathEscape(ref.Owner), url.PathEscape(ref.Repo), ref.Number)
+}
@@ -0,0 +1,262 @@
+// Package forge fetches pull and merge requests from code hosting services
+// (GitHub, GitLab, Bitbucket) and posts review comments back to them.
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+type Kind string
+
+const (
+	KindGitHub    Kind = "github"
+	KindGitLab    Kind = "gitlab"
+	KindBitbucket Kind = "bitbucket"
+)
+
+// Ref identifies a pull or merge request. Owner is the namespace, which may
+// contain slashes for GitLab subgroups.
+type Ref struct {
+	Kind   Kind   `json:"kind"`
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+}
+
+func (r Ref) String() string {
+	if r.Kind == KindGitLab {
+		return fmt.Sprintf("%s/%s!%d", r.Owner, r.Repo, r.Number)
+	}
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// Change is the metadata of a pull or merge request.
+type Change struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	Author  string `json:"author"`
+	BaseRef string `json:"baseRef"`
+	HeadRef string `json:"headRef"`
+	URL     string `json:"url"`
+}
+
+// Issue is an issue or ticket on the forge; Ref.Number is its number.
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+	URL    string `json:"url"`
+}
+
+// Forge is a code host that serves change requests as unified diffs.
+type Forge interface {
+	GetChange(ref Ref) (Change, error)
+	GetIssue(ref Ref) (Issue, error)
+	GetDiff(ref Ref) (string, error)
+	// PostComment adds a comment to the change and returns its URL.
+	PostComment(ref Ref, body string) (string, error)
+}
+
+// New returns the client for kind, configured from the environment and
+// ~/.difflearn.
+func New(kind Kind) (Forge, error) {
+	switch kind {
+	case KindGitHub:
+		return NewGitHub(), nil
+	case KindGitLab:
+		return NewGitLab(), nil
+	case KindBitbucket:
+		return NewBitbucket(), nil
+	}
+	return nil, fmt.Errorf("unknown forge: %s", kind)
+}
+
+var (
+	githubURLRe    = regexp.MustCompile(`^https?://[^/]+/([^/]+)/([^/]+)/pull/(\d+)`)
+	gitlabURLRe    = regexp.MustCompile(`^https?://[^/]+/(.+)/([^/]+)/-/merge_requests/(\d+)`)
+	bitbucketURLRe = regexp.MustCompile(`^https?://[^/]+/([^/]+)/([^/]+)/pull-requests/(\d+)`)
+	gitlabIssueRe  = regexp.MustCompile(`^https?://[^/]+/(.+)/([^/]+)/-/(?:issues|work_items)/(\d+)`)
+	issueURLRe     = regexp.MustCompile(`^https?://([^/]+)/([^/]+)/([^/]+)/issues/(\d+)`)
+	shortRefRe     = regexp.MustCompile(`^([\w.-]+(?:/[\w.-]+)*)/([\w.-]+)([#!])(\d+)$`)
+	remoteURLRe    = regexp.MustCompile(`^(?:[a-z+]+://)?(?:[^@/]+@)?([^/:]+)(?::\d+)?[/:](.+)/([^/]+?)(?:\.git)?/?$`)
+)
+
+// ParseRef accepts a pull/merge request URL, owner/repo#123 (owner/repo!123
+// for GitLab), #123, !123 or 123. The short forms take the repository and,
+// unless DIFFLEARN_FORGE says otherwise, the forge from remote, the URL of a
+// git remote.
+func ParseRef(arg, remote string) (Ref, error) {
+	arg = strings.TrimSpace(arg)
+	for kind, re := range map[Kind]*regexp.Regexp{KindGitHub: githubURLRe, KindGitLab: gitlabURLRe, KindBitbucket: bitbucketURLRe} {
+		if m := re.FindStringSubmatch(arg); m != nil {
+			n, _ := strconv.Atoi(m[3])
+			return Ref{Kind: kind, Owner: m[1], Repo: m[2], Number: n}, nil
+		}
+	}
+
+	host, owner, repo, hasRemote := parseRemote(remote)
+	kind := Kind(config.Setting("DIFFLEARN_FORGE"))
+	if kind == "" {
+		kind = kindForHost(host)
+	}
+	if m := shortRefRe.FindStringSubmatch(arg); m != nil {
+		n, _ := strconv.Atoi(m[4])
+		if m[3] == "!" {
+			kind = KindGitLab
+		}
+		return Ref{Kind: kind, Owner: m[1], Repo: m[2], Number: n}, nil
+	}
+	if strings.HasPrefix(arg, "!") {
+		kind = KindGitLab
+	}
+	n, err := strconv.Atoi(strings.TrimLeft(arg, "#!"))
+	if err != nil || n <= 0 {
+		return Ref{}, fmt.Errorf("invalid pull request %q (use a number, owner/repo#number or a URL)", arg)
+	}
+	if !hasRemote {
+		return Ref{}, fmt.Errorf("cannot tell which repository %q refers to; pass owner/repo#%d or a URL", arg, n)
+	}
+	return Ref{Kind: kind, Owner: owner, Repo: repo, Number: n}, nil
+}
+
+// ParseIssueURL recognizes an issue URL on GitHub, GitLab or Bitbucket.
+// GitHub and Bitbucket share a URL shape, so the host decides between them.
+func ParseIssueURL(arg string) (Ref, bool) {
+	arg = strings.TrimSpace(arg)
+	if m := gitlabIssueRe.FindStringSubmatch(arg); m != nil {
+		n, _ := strconv.Atoi(m[3])
+		return Ref{Kind: KindGitLab, Owner: m[1], Repo: m[2], Number: n}, true
+	}
+	if m := issueURLRe.FindStringSubmatch(arg); m != nil {
+		n, _ := strconv.Atoi(m[4])
+		kind := kindForHost(m[1])
+		if kind == KindGitLab {
+			return Ref{}, false
+		}
+		return Ref{Kind: kind, Owner: m[2], Repo: m[3], Number: n}, true
+	}
+	return Ref{}, false
+}
+
+// kindForHost guesses the forge from a remote host name; self-hosted
+// instances are recognized by name or by GITLAB_URL.
+func kindForHost(host string) Kind {
+	host = strings.ToLower(host)
+	if base := config.Setting("GITLAB_URL"); base != "" {
+		if u, err := url.Parse(base); err == nil && strings.EqualFold(u.Hostname(), host) {
+			return KindGitLab
+		}
+	}
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return KindGitLab
+	case strings.Contains(host, "bitbucket"):
+		return KindBitbucket
+	}
+	return KindGitHub
+}
+
+func parseRemote(remote string) (host, owner, repo string, ok bool) {
+	m := remoteURLRe.FindStringSubmatch(strings.TrimSpace(remote))
+	if m == nil {
+		return "", "", "", false
+	}
+	return m[1], m[2], m[3], true
+}
+
+// httpClient holds what the forge clients share: a base URL, headers and
+// error decoding.
+type httpClient struct {
+	name    string
+	baseURL string
+	auth    func(*http.Request)
+	http    *http.Client
+	// tokenHint names the setting that fixes a 401 or 404 on private data.
+	tokenHint string
+}
+
+func newHTTPClient(name, baseURL, tokenHint string, auth func(*http.Request)) httpClient {
+	return httpClient{name: name, baseURL: strings.TrimRight(baseURL, "/"), auth: auth, http: &http.Client{Timeout: 60 * time.Second}, tokenHint: tokenHint}
+}
+
+func (c httpClient) do(method, path, accept string, payload any) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "DiffLearn-Go")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	authenticated := false
+	if c.auth != nil {
+		c.auth(req)
+		authenticated = req.Header.Get("Authorization") != "" || req.Header.Get("PRIVATE-TOKEN") != ""
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		msg := errorMessage(body)
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		if !authenticated && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized) {
+			msg += fmt.Sprintf(" (private repositories need %s)", c.tokenHint)
+		}
+		return nil, fmt.Errorf("%s %s %s: %d %s", c.name, method, path, resp.StatusCode, msg)
+	}
+	return body, nil
+}
+
+// errorMessage extracts the message from the error bodies of the supported
+// APIs.
+func errorMessage(body []byte) string {
+	var e struct {
+		Message any `json:"message"`
+		Error   any `json:"error"`
+	}
+	if json.Unmarshal(body, &e) != nil {
+		return ""
+	}
+	for _, v := range []any{e.Message, e.Error} {
+		switch v := v.(type) {
+		case string:
+			return v
+		case map[string]any:
+			if s, ok := v["message"].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package forge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+func TestParseRef(t *testing.T) {
+	t.Setenv("DIFFLEARN_FORGE", "")
+	t.Setenv("GITLAB_URL", "https://code.example.com")
+	cases := []struct {
+		arg, remote string
+		want        Ref
+	}{
+		{"https://github.com/acme/tool/pull/42/files", "", Ref{KindGitHub, "acme", "tool", 42}},
+		{"https://gitlab.com/acme/sub/tool/-/merge_requests/8", "", Ref{KindGitLab, "acme/sub", "tool", 8}},
+		{"https://bitbucket.org/acme/tool/pull-requests/3", "", Ref{KindBitbucket, "acme", "tool", 3}},
+		{"acme/tool#7", "", Ref{KindGitHub, "acme", "tool", 7}},
+		{"acme/sub/tool!7", "", Ref{KindGitLab, "acme/sub", "tool", 7}},
+		{"#12", "git@github.com:acme/tool.git", Ref{KindGitHub, "acme", "tool", 12}},
+		{"5", "https://gitlab.com/acme/sub/tool.git", Ref{KindGitLab, "acme/sub", "tool", 5}},
+		{"5", "git@bitbucket.org:acme/tool.git", Ref{KindBitbucket, "acme", "tool", 5}},
+		{"5", "ssh://git@code.example.com:2222/acme/tool.git", Ref{KindGitLab, "acme", "tool", 5}},
+	}
+	for _, c := range cases {
+		got, err := ParseRef(c.arg, c.remote)
+		if err != nil || got != c.want {
+			t.Errorf("ParseRef(%q, %q) = %+v, %v; want %+v", c.arg, c.remote, got, err, c.want)
+		}
+	}
+	if _, err := ParseRef("5", ""); err == nil {
+		t.Error("expected an error without a remote")
+	}
+	if _, err := ParseRef("abc", "git@github.com:acme/tool.git"); err == nil {
+		t.Error("expected an error for a non-numeric reference")
+	}
+}
+
+func TestParseIssueURL(t *testing.T) {
+	t.Setenv("GITLAB_URL", "")
+	cases := map[string]Ref{
+		"https://github.com/acme/tool/issues/12":              {KindGitHub, "acme", "tool", 12},
+		"https://gitlab.com/acme/sub/tool/-/issues/4":         {KindGitLab, "acme/sub", "tool", 4},
+		"https://bitbucket.org/acme/tool/issues/2/a-title":    {KindBitbucket, "acme", "tool", 2},
+		"https://gitlab.example.com/acme/tool/-/work_items/7": {KindGitLab, "acme", "tool", 7},
+	}
+	for arg, want := range cases {
+		if got, ok := ParseIssueURL(arg); !ok || got != want {
+			t.Errorf("ParseIssueURL(%q) = %+v, %v; want %+v", arg, got, ok, want)
+		}
+	}
+	if _, ok := ParseIssueURL("https://github.com/acme/tool/pull/3"); ok {
+		t.Error("a pull request URL is not an issue")
+	}
+}
+
+// fakeForge serves canned responses keyed by "METHOD path" and records
+// posted comment bodies.
+func fakeForge(t *testing.T, authHeader, authValue string, routes map[string]string) (*httptest.Server, *[]string) {
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(authHeader) != authValue {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		key := r.Method + " " + r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			key += "?" + r.URL.RawQuery
+		}
+		if r.Method == http.MethodPost {
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			data, _ := json.Marshal(body)
+			posted = append(posted, string(data))
+		}
+		if r.Header.Get("Accept") == "application/vnd.github.v3.diff" {
+			key += " diff"
+		}
+		resp, ok := routes[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &posted
+}
+
+func TestGitHub(t *testing.T) {
+	srv, posted := fakeForge(t, "Authorization", "Bearer secret", map[string]string{
+		"GET /repos/acme/tool/pulls/3":            `{"number":3,"title":"Fix","user":{"login":"dev"},"base":{"ref":"main"},"head":{"ref":"fix"}}`,
+		"GET /repos/acme/tool/pulls/3 diff":       "diff --git a/x b/x\n",
+		"POST /repos/acme/tool/issues/3/comments": `{"html_url":"https://github.com/acme/tool/pull/3#issuecomment-1"}`,
+		"GET /repos/acme/tool/issues/9":           `{"number":9,"title":"Login fails","body":"Steps...","state":"open"}`,
+	})
+	c := newGitHub(srv.URL, "secret")
+	ref := Ref{Kind: KindGitHub, Owner: "acme", Repo: "tool", Number: 3}
+
+	pr, err := c.GetChange(ref)
+	if err != nil || pr.Author != "dev" || pr.BaseRef != "main" || pr.HeadRef != "fix" {
+		t.Fatalf("GetChange = %+v, %v", pr, err)
+	}
+	if diff, err := c.GetDiff(ref); err != nil || diff != "diff --git a/x b/x\n" {
+		t.Fatalf("GetDiff = %q, %v", diff, err)
+	}
+	link, err := c.PostComment(ref, "looks good")
+	if err != nil || link == "" || len(*posted) != 1 || !strings.Contains((*posted)[0], "looks good") {
+		t.Fatalf("PostComment = %q, %v (posted %v)", link, err, *posted)
+	}
+	if issue, err := c.GetIssue(Ref{Kind: KindGitHub, Owner: "acme", Repo: "tool", Number: 9}); err != nil || issue.Title != "Login fails" || issue.Body != "Steps..." {
+		t.Fatalf("GetIssue = %+v, %v", issue, err)
+	}
+	if _, err := c.GetChange(Ref{Kind: KindGitHub, Owner: "acme", Repo: "tool", Number: 4}); err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Fatalf("expected a Not Found error, got %v", err)
+	}
+}
+
+func TestGitLabRebuildsUnifiedDiff(t *testing.T) {
+	srv, posted := fakeForge(t, "PRIVATE-TOKEN", "secret", map[string]string{
+		"GET /projects/acme%2Ftool/merge_requests/8": `{"iid":8,"title":"Add","author":{"username":"dev"},"source_branch":"feat","target_branch":"main","web_url":"https://gitlab.com/acme/tool/-/merge_requests/8"}`,
+		"GET /projects/acme%2Ftool/merge_requests/8/diffs?per_page=100&page=1": `[
+			{"old_path":"a.go","new_path":"a.go","a_mode":"100644","b_mode":"100644","diff":"@@ -1 +1 @@\n-old\n+new\n"},
+			{"old_path":"b.go","new_path":"b.go","b_mode":"100644","new_file":true,"diff":"@@ -0,0 +1 @@\n+package b\n"}
+		]`,
+		"POST /projects/acme%2Ftool/merge_requests/8/notes": `{"id":99}`,
+	})
+	c := newGitLab(srv.URL, "secret")
+	ref := Ref{Kind: KindGitLab, Owner: "acme", Repo: "tool", Number: 8}
+
+	raw, err := c.GetDiff(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs := git.NewDiffParser().Parse(raw)
+	if len(diffs) != 2 || diffs[0].Additions != 1 || diffs[0].Deletions != 1 || !diffs[1].IsNew {
+		t.Fatalf("unexpected parse of rebuilt diff:\n%s\n%+v", raw, diffs)
+	}
+	link, err := c.PostComment(ref, "review")
+	if err != nil || link != "https://gitlab.com/acme/tool/-/merge_requests/8#note_99" || len(*posted) != 1 {
+		t.Fatalf("PostComment = %q, %v", link, err)
+	}
+}
+
+func TestBitbucket(t *testing.T) {
+	srv, posted := fakeForge(t, "Authorization", "Bearer secret", map[string]string{
+		"GET /repositories/acme/tool/pullrequests/3":           `{"id":3,"title":"Fix","author":{"display_name":"Dev"},"source":{"branch":{"name":"fix"}},"destination":{"branch":{"name":"main"}}}`,
+		"GET /repositories/acme/tool/pullrequests/3/diff":      "diff --git a/x b/x\n",
+		"POST /repositories/acme/tool/pullrequests/3/comments": `{"links":{"html":{"href":"https://bitbucket.org/acme/tool/pull-requests/3#comment-1"}}}`,
+	})
+	c := newBitbucket(srv.URL, "secret", "", "")
+	ref := Ref{Kind: KindBitbucket, Owner: "acme", Repo: "tool", Number: 3}
+
+	pr, err := c.GetChange(ref)
+	if err != nil || pr.Author != "Dev" || pr.BaseRef != "main" || pr.HeadRef != "fix" {
+		t.Fatalf("GetChange = %+v, %v", pr, err)
+	}
+	if diff, err := c.GetDiff(ref); err != nil || diff != "diff --git a/x b/x\n" {
+		t.Fatalf("GetDiff = %q, %v", diff, err)
+	}
+	if _, err := c.PostComment(ref, "ok"); err != nil || !strings.Contains((*posted)[0], `"raw":"ok"`) {
+		t.Fatalf("PostComment: %v (posted %v)", err, *posted)
+	}
+}
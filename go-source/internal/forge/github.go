@@ -0,0 +1,113 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"difflearn-go/internal/config"
+)
+
+const defaultGitHubAPI = "https://api.github.com"
+
+type GitHub struct {
+	httpClient
+	Token string
+}
+
+// NewGitHub uses GITHUB_TOKEN (or GH_TOKEN) for authentication and
+// GITHUB_API_URL for GitHub Enterprise.
+func NewGitHub() *GitHub {
+	token := config.Setting("GITHUB_TOKEN")
+	if token == "" {
+		token = config.Setting("GH_TOKEN")
+	}
+	base := config.Setting("GITHUB_API_URL")
+	if base == "" {
+		base = defaultGitHubAPI
+	}
+	return newGitHub(base, token)
+}
+
+func newGitHub(base, token string) *GitHub {
+	g := &GitHub{Token: token}
+	g.httpClient = newHTTPClient("github", base, "GITHUB_TOKEN", func(r *http.Request) {
+		r.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		if g.Token != "" {
+			r.Header.Set("Authorization", "Bearer "+g.Token)
+		}
+	})
+	return g
+}
+
+func (c *GitHub) GetChange(ref Ref) (Change, error) {
+	var p struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		State   string `json:"state"`
+		HTMLURL string `json:"html_url"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+		Head struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+	}
+	body, err := c.do(http.MethodGet, c.pullPath(ref), "application/vnd.github+json", nil)
+	if err != nil {
+		return Change{}, err
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return Change{}, err
+	}
+	return Change{Number: p.Number, Title: p.Title, Body: p.Body, State: p.State, Author: p.User.Login, BaseRef: p.Base.Ref, HeadRef: p.Head.Ref, URL: p.HTMLURL}, nil
+}
+
+func (c *GitHub) GetIssue(ref Ref) (Issue, error) {
+	var i struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		State   string `json:"state"`
+		HTMLURL string `json:"html_url"`
+	}
+	body, err := c.do(http.MethodGet, fmt.Sprintf("/repos/%s/%s/issues/%d", url.PathEscape(ref.Owner), url.PathEscape(ref.Repo), ref.Number), "application/vnd.github+json", nil)
+	if err != nil {
+		return Issue{}, err
+	}
+	if err := json.Unmarshal(body, &i); err != nil {
+		return Issue{}, err
+	}
+	return Issue{Number: i.Number, Title: i.Title, Body: i.Body, State: i.State, URL: i.HTMLURL}, nil
+}
+
+// GetDiff returns the pull request as a unified diff.
+func (c *GitHub) GetDiff(ref Ref) (string, error) {
+	body, err := c.do(http.MethodGet, c.pullPath(ref), "application/vnd.github.v3.diff", nil)
+	return string(body), err
+}
+
+// PostComment adds a conversation comment to the pull request.
+func (c *GitHub) PostComment(ref Ref, text string) (string, error) {
+	if c.Token == "" {
+		return "", fmt.Errorf("posting to GitHub needs a token: set GITHUB_TOKEN")
+	}
+	body, err := c.do(http.MethodPost, fmt.Sprintf("/repos/%s/%s/issues/%d/comments", url.PathEscape(ref.Owner), url.PathEscape(ref.Repo), ref.Number), "application/vnd.github+json", map[string]string{"body": text})
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	_ = json.Unmarshal(body, &created)
+	return created.HTMLURL, nil
+}
+
+func (c *GitHub) pullPath(ref Ref) string {
+	return fmt.Sprintf("/repos/%s/%s/pulls/%d", url.PathEscape(ref.Owner), url.PathEscape(ref.Repo), ref.Number)
+}
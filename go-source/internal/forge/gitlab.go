@@ -0,0 +1,160 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"difflearn-go/internal/config"
+)
+
+const defaultGitLabURL = "https://gitlab.com"
+
+type GitLab struct {
+	httpClient
+	Token string
+}
+
+// NewGitLab uses GITLAB_TOKEN for authentication and GITLAB_URL for
+// self-managed instances.
+func NewGitLab() *GitLab {
+	base := config.Setting("GITLAB_URL")
+	if base == "" {
+		base = defaultGitLabURL
+	}
+	return newGitLab(strings.TrimRight(base, "/")+"/api/v4", config.Setting("GITLAB_TOKEN"))
+}
+
+func newGitLab(apiBase, token string) *GitLab {
+	g := &GitLab{Token: token}
+	g.httpClient = newHTTPClient("gitlab", apiBase, "GITLAB_TOKEN", func(r *http.Request) {
+		if g.Token != "" {
+			r.Header.Set("PRIVATE-TOKEN", g.Token)
+		}
+	})
+	return g
+}
+
+func (c *GitLab) GetChange(ref Ref) (Change, error) {
+	var mr struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		State        string `json:"state"`
+		WebURL       string `json:"web_url"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		Author       struct {
+			Username string `json:"username"`
+		} `json:"author"`
+	}
+	body, err := c.do(http.MethodGet, c.mrPath(ref), "application/json", nil)
+	if err != nil {
+		return Change{}, err
+	}
+	if err := json.Unmarshal(body, &mr); err != nil {
+		return Change{}, err
+	}
+	return Change{Number: mr.IID, Title: mr.Title, Body: mr.Description, State: mr.State, Author: mr.Author.Username, BaseRef: mr.TargetBranch, HeadRef: mr.SourceBranch, URL: mr.WebURL}, nil
+}
+
+func (c *GitLab) GetIssue(ref Ref) (Issue, error) {
+	var i struct {
+		IID         int    `json:"iid"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		State       string `json:"state"`
+		WebURL      string `json:"web_url"`
+	}
+	body, err := c.do(http.MethodGet, fmt.Sprintf("/projects/%s/issues/%d", url.PathEscape(ref.Owner+"/"+ref.Repo), ref.Number), "application/json", nil)
+	if err != nil {
+		return Issue{}, err
+	}
+	if err := json.Unmarshal(body, &i); err != nil {
+		return Issue{}, err
+	}
+	return Issue{Number: i.IID, Title: i.Title, Body: i.Description, State: i.State, URL: i.WebURL}, nil
+}
+
+// GetDiff rebuilds a git-style unified diff from the per-file diffs GitLab
+// returns, paging through large merge requests.
+func (c *GitLab) GetDiff(ref Ref) (string, error) {
+	type fileDiff struct {
+		OldPath     string `json:"old_path"`
+		NewPath     string `json:"new_path"`
+		AMode       string `json:"a_mode"`
+		BMode       string `json:"b_mode"`
+		Diff        string `json:"diff"`
+		NewFile     bool   `json:"new_file"`
+		RenamedFile bool   `json:"renamed_file"`
+		DeletedFile bool   `json:"deleted_file"`
+	}
+	var sb strings.Builder
+	for page := 1; ; page++ {
+		body, err := c.do(http.MethodGet, fmt.Sprintf("%s/diffs?per_page=100&page=%d", c.mrPath(ref), page), "application/json", nil)
+		if err != nil {
+			return "", err
+		}
+		var files []fileDiff
+		if err := json.Unmarshal(body, &files); err != nil {
+			return "", err
+		}
+		for _, f := range files {
+			fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", f.OldPath, f.NewPath)
+			oldPath, newPath := "a/"+f.OldPath, "b/"+f.NewPath
+			switch {
+			case f.NewFile:
+				fmt.Fprintf(&sb, "new file mode %s\n", defaultMode(f.BMode))
+				oldPath = "/dev/null"
+			case f.DeletedFile:
+				fmt.Fprintf(&sb, "deleted file mode %s\n", defaultMode(f.AMode))
+				newPath = "/dev/null"
+			case f.RenamedFile:
+				fmt.Fprintf(&sb, "rename from %s\nrename to %s\n", f.OldPath, f.NewPath)
+			}
+			if f.Diff == "" {
+				continue
+			}
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n%s", oldPath, newPath, f.Diff)
+			if !strings.HasSuffix(f.Diff, "\n") {
+				sb.WriteString("\n")
+			}
+		}
+		if len(files) < 100 {
+			return sb.String(), nil
+		}
+	}
+}
+
+func defaultMode(mode string) string {
+	if mode == "" || mode == "0" {
+		return "100644"
+	}
+	return mode
+}
+
+// PostComment adds a note to the merge request.
+func (c *GitLab) PostComment(ref Ref, text string) (string, error) {
+	if c.Token == "" {
+		return "", fmt.Errorf("posting to GitLab needs a token: set GITLAB_TOKEN")
+	}
+	body, err := c.do(http.MethodPost, c.mrPath(ref)+"/notes", "application/json", map[string]string{"body": text})
+	if err != nil {
+		return "", err
+	}
+	var note struct {
+		ID int `json:"id"`
+	}
+	_ = json.Unmarshal(body, &note)
+	change, err := c.GetChange(ref)
+	if err != nil || note.ID == 0 {
+		return change.URL, nil
+	}
+	return fmt.Sprintf("%s#note_%d", change.URL, note.ID), nil
+}
+
+func (c *GitLab) mrPath(ref Ref) string {
+	return fmt.Sprintf("/projects/%s/merge_requests/%d", url.PathEscape(ref.Owner+"/"+ref.Repo), ref.Number)
+}
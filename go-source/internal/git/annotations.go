@@ -0,0 +1,108 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SideNew = "new"
+	SideOld = "old"
+)
+
+// Annotation is a comment attached to one line of a diff. Side selects which
+// numbering Line uses: SideNew for added and context lines, SideOld for
+// deleted lines.
+type Annotation struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Side     string `json:"side,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (a Annotation) key() string {
+	return fmt.Sprintf("%s\x00%s\x00%d", a.File, a.Side, a.Line)
+}
+
+// ValidateAnnotations keeps the annotations that point at a line present in
+// the hunks of diffs and returns the rest as dropped. An empty side defaults
+// to SideNew, paths may carry git's a/ and b/ prefixes, and context lines
+// addressed by their old number are moved to the new side, where they are
+// rendered.
+func ValidateAnnotations(diffs []ParsedDiff, annotations []Annotation) (valid, dropped []Annotation) {
+	// Maps every addressable line to its canonical annotation position.
+	lines := map[string]Annotation{}
+	for _, d := range diffs {
+		for _, h := range d.Hunks {
+			for _, l := range h.Lines {
+				var target Annotation
+				if l.Type == LineDelete {
+					if l.OldLineNumber == nil {
+						continue
+					}
+					target = Annotation{File: d.OldFile, Side: SideOld, Line: *l.OldLineNumber}
+				} else {
+					if l.NewLineNumber == nil {
+						continue
+					}
+					target = Annotation{File: d.NewFile, Side: SideNew, Line: *l.NewLineNumber}
+				}
+				lines[target.key()] = target
+				if l.Type == LineContext && l.OldLineNumber != nil {
+					lines[Annotation{File: d.OldFile, Side: SideOld, Line: *l.OldLineNumber}.key()] = target
+				}
+			}
+		}
+	}
+	valid, dropped = []Annotation{}, []Annotation{}
+	for _, a := range annotations {
+		a.File = strings.TrimPrefix(strings.TrimSpace(a.File), "./")
+		a.Side = strings.ToLower(strings.TrimSpace(a.Side))
+		if a.Side != SideOld {
+			a.Side = SideNew
+		}
+		target, ok := lines[a.key()]
+		if !ok && (strings.HasPrefix(a.File, "a/") || strings.HasPrefix(a.File, "b/")) {
+			stripped := a
+			stripped.File = a.File[2:]
+			target, ok = lines[stripped.key()]
+		}
+		if !ok || strings.TrimSpace(a.Message) == "" {
+			dropped = append(dropped, a)
+			continue
+		}
+		a.File, a.Side, a.Line = target.File, target.Side, target.Line
+		valid = append(valid, a)
+	}
+	return valid, dropped
+}
+
+// annotationIndex groups annotations by line for the formatters.
+func annotationIndex(annotations []Annotation) map[string][]Annotation {
+	if len(annotations) == 0 {
+		return nil
+	}
+	index := map[string][]Annotation{}
+	for _, a := range annotations {
+		index[a.key()] = append(index[a.key()], a)
+	}
+	return index
+}
+
+// lineAnnotations returns the annotations attached to line of diff d.
+func lineAnnotations(index map[string][]Annotation, d ParsedDiff, line ParsedLine) []Annotation {
+	if index == nil {
+		return nil
+	}
+	if line.Type == LineDelete {
+		if line.OldLineNumber == nil {
+			return nil
+		}
+		return index[Annotation{File: d.OldFile, Side: SideOld, Line: *line.OldLineNumber}.key()]
+	}
+	if line.NewLineNumber == nil {
+		return nil
+	}
+	return index[Annotation{File: d.NewFile, Side: SideNew, Line: *line.NewLineNumber}.key()]
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var oidRe = regexp.MustCompile(`^[0-9a-f]{4,64}$`)
+
+// parse parses raw and annotates any binary files it contains.
+func (g *GitExtractor) parse(raw string) []ParsedDiff {
+	diffs := g.parser.Parse(raw)
+	g.DescribeBinaries(diffs)
+	return diffs
+}
+
+// DescribeBinaries fills in sizes and the mime type of binary diffs. Object
+// ids that are not in the object database (unstaged working tree content)
+// are measured from the file on disk.
+func (g *GitExtractor) DescribeBinaries(diffs []ParsedDiff) {
+	for i := range diffs {
+		b := diffs[i].Binary
+		if b == nil {
+			continue
+		}
+		b.OldSize = g.blobSize(b.OldBlob, "")
+		b.NewSize = g.blobSize(b.NewBlob, diffs[i].NewFile)
+
+		path, oid := diffs[i].NewFile, b.NewBlob
+		if diffs[i].IsDeleted {
+			path, oid = diffs[i].OldFile, b.OldBlob
+		}
+		var head []byte
+		if mime.TypeByExtension(filepath.Ext(path)) == "" && oid != "" {
+			if data, err := g.ReadBlob(oid, path); err == nil {
+				head = data
+			}
+		}
+		b.MimeType = DetectMimeType(path, head)
+		b.IsImage = strings.HasPrefix(b.MimeType, "image/")
+	}
+}
+
+func (g *GitExtractor) blobSize(oid, path string) int64 {
+	if oid == "" {
+		return 0
+	}
+	if out, err := g.runGit("cat-file", "-s", oid); err == nil {
+		n, _ := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+		return n
+	}
+	if full, ok := g.worktreePath(path); ok {
+		if info, err := os.Stat(full); err == nil {
+			return info.Size()
+		}
+	}
+	return 0
+}
+
+// ReadBlob returns the content of object oid. When the object only exists in
+// the working tree, path is read instead, provided it hashes to oid.
+func (g *GitExtractor) ReadBlob(oid, path string) ([]byte, error) {
+	if !oidRe.MatchString(oid) {
+		return nil, fmt.Errorf("invalid object id %q", oid)
+	}
+	if out, err := g.runGit("cat-file", "blob", oid); err == nil {
+		return []byte(out), nil
+	}
+	full, ok := g.worktreePath(path)
+	if !ok {
+		return nil, fmt.Errorf("object %s not found", oid)
+	}
+	hash, err := g.runGit("hash-object", "--", full)
+	if err != nil || !strings.HasPrefix(strings.TrimSpace(hash), oid) {
+		return nil, fmt.Errorf("object %s not found", oid)
+	}
+	return os.ReadFile(full)
+}
+
+// worktreePath resolves a repository-relative path, refusing anything that
+// escapes the repository root.
+func (g *GitExtractor) worktreePath(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	root := g.RepoPath()
+	full := filepath.Join(root, filepath.Clean("/"+path))
+	if full != root && !strings.HasPrefix(full, root+string(filepath.Separator)) {
+		return "", false
+	}
+	return full, true
+}
+
+// DetectMimeType guesses a mime type from the file extension, falling back to
+// sniffing head when the extension is unknown.
+func DetectMimeType(path string, head []byte) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(path))); t != "" {
+		t, _, _ = strings.Cut(t, ";")
+		return t
+	}
+	if len(head) == 0 {
+		return "application/octet-stream"
+	}
+	t, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return t
+}
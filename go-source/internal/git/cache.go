@@ -0,0 +1,188 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedOutput keeps huge patches out of the cache; they are cheap to
+// recompute compared to how much disk they would take.
+const maxCachedOutput = 16 << 20
+
+var defaultCache struct {
+	sync.Mutex
+	dir string
+}
+
+// SetCacheDir sets where extractors created by NewGitExtractor keep the
+// output of commands pinned to commit hashes (DIFFLEARN_GIT_CACHE); empty
+// turns the cache off.
+func SetCacheDir(dir string) {
+	defaultCache.Lock()
+	defer defaultCache.Unlock()
+	defaultCache.dir = dir
+}
+
+// CacheDir is the directory set by SetCacheDir.
+func CacheDir() string {
+	defaultCache.Lock()
+	defer defaultCache.Unlock()
+	return defaultCache.dir
+}
+
+// CacheStats counts the commands a CachingRunner answered from disk and
+// the ones it ran.
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// CachingRunner keeps the output of git commands whose result cannot change
+// on disk: logs, tree listings and diffs of full commit hashes. Commands
+// that read refs, the index or the working tree always run. Warmup fills
+// the cache ahead of the first TUI or web session on a large repository.
+type CachingRunner struct {
+	Inner GitRunner
+	Dir   string
+
+	hits, misses atomic.Int64
+}
+
+// NewCachingRunner wraps inner (ExecRunner when nil) in a cache kept in dir.
+func NewCachingRunner(inner GitRunner, dir string) *CachingRunner {
+	if inner == nil {
+		inner = ExecRunner{}
+	}
+	return &CachingRunner{Inner: inner, Dir: dir}
+}
+
+func (c *CachingRunner) Run(dir string, args ...string) (string, error) {
+	return c.RunContext(context.Background(), dir, args...)
+}
+
+func (c *CachingRunner) RunContext(ctx context.Context, dir string, args ...string) (string, error) {
+	run := func() (string, error) {
+		if cr, ok := c.Inner.(ContextRunner); ok {
+			return cr.RunContext(ctx, dir, args...)
+		}
+		return c.Inner.Run(dir, args...)
+	}
+	if !Cacheable(args) {
+		return run()
+	}
+	path := c.entryPath(dir, args)
+	if b, err := os.ReadFile(path); err == nil {
+		c.hits.Add(1)
+		return string(b), nil
+	}
+	c.misses.Add(1)
+	out, err := run()
+	if err == nil && len(out) <= maxCachedOutput {
+		c.store(path, out)
+	}
+	return out, err
+}
+
+// Stats returns the hits and misses so far.
+func (c *CachingRunner) Stats() CacheStats {
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+func (c *CachingRunner) entryPath(dir string, args []string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	sum := sha256.Sum256([]byte(dir + "\x00" + strings.Join(args, "\x00")))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.Dir, key[:2], key)
+}
+
+// store writes an entry through a temporary file, so a concurrent reader
+// never sees half of it. Failures only cost the next run a cache miss.
+func (c *CachingRunner) store(path, out string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.WriteString(out)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// ClearCache removes the cache kept in dir.
+func ClearCache(dir string) error {
+	return os.RemoveAll(dir)
+}
+
+var (
+	// pinnedRevision is a full commit hash with an optional parent or
+	// ancestor suffix (abc^, abc~3) or a path in its tree (abc:go.mod).
+	pinnedRevision = regexp.MustCompile(`^(?:[0-9a-f]{40}|[0-9a-f]{64})(?:[~^][0-9]*)*(?::.*)?$`)
+	// cacheableCommands are the commands whose output follows from their
+	// revisions alone.
+	cacheableCommands = map[string]bool{"log": true, "diff": true, "ls-tree": true, "rev-list": true, "show": true}
+)
+
+// Cacheable reports whether the output of `git args...` is fixed by the
+// commit hashes it names, so it can be kept across runs: every revision is
+// a full hash, and a diff compares two commits rather than a commit and the
+// working tree. Flags that add refs, read the index or stdin, or print
+// relative dates disqualify a command.
+func Cacheable(args []string) bool {
+	if len(args) == 0 || !cacheableCommands[args[0]] {
+		return false
+	}
+	revisions := 0
+	for _, a := range args[1:] {
+		if a == "--" {
+			break
+		}
+		if strings.HasPrefix(a, "-") {
+			switch {
+			case a == "--all", a == "--branches", a == "--remotes", a == "--tags", a == "--stdin",
+				a == "--cached", a == "--staged", a == "--no-index", a == "--reflog", a == "--walk-reflogs", a == "-g",
+				strings.HasPrefix(a, "--branches="), strings.HasPrefix(a, "--remotes="), strings.HasPrefix(a, "--tags="),
+				strings.HasPrefix(a, "--glob="), strings.HasPrefix(a, "--exclude"), strings.HasPrefix(a, "--decorate"),
+				strings.Contains(a, "relative"), strings.Contains(a, "%ar"), strings.Contains(a, "%cr"),
+				strings.Contains(a, "%d"), strings.Contains(a, "%D"):
+				return false
+			}
+			continue
+		}
+		for _, rev := range splitRange(a) {
+			if !pinnedRevision.MatchString(rev) {
+				return false
+			}
+			revisions++
+		}
+	}
+	if args[0] == "diff" {
+		return revisions >= 2
+	}
+	return revisions > 0
+}
+
+// splitRange splits a..b and a...b into their endpoints.
+func splitRange(arg string) []string {
+	if a, b, ok := strings.Cut(arg, "..."); ok {
+		return []string{a, b}
+	}
+	if a, b, ok := strings.Cut(arg, ".."); ok {
+		return []string{a, b}
+	}
+	return []string{arg}
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCacheable(t *testing.T) {
+	a := strings.Repeat("a", 40)
+	b := strings.Repeat("b", 40)
+	cases := map[string]bool{
+		"log --name-only --max-count=31 " + a:   true,
+		"log --skip=30 --max-count=31 " + a:     true,
+		"ls-tree -r -l --full-tree " + a:        true,
+		"rev-list --count " + a:                 true,
+		"diff --numstat -z " + a + "..." + b:    true,
+		"diff " + a + "^.." + a:                 true,
+		"show " + a + ":go.mod":                 true,
+		"log --max-count=50":                    false,
+		"log HEAD":                              false,
+		"log --max-count=5 main":                false,
+		"log " + a[:12]:                         false,
+		"log --branches --max-count=5 " + a:     false,
+		"log --format=%H%x1f%ar " + a:           false,
+		"diff " + a:                             false,
+		"diff " + a + " -- README.md":           false,
+		"diff --cached " + a + " " + b:          false,
+		"for-each-ref refs/heads":               false,
+		"status --porcelain":                    false,
+		"log --follow -p " + a + " -- src/x.go": true,
+		"log --graph --decorate=short " + a:     false,
+	}
+	for cmd, want := range cases {
+		if got := Cacheable(strings.Fields(cmd)); got != want {
+			t.Errorf("Cacheable(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+}
+
+func TestCachingRunnerKeepsPinnedOutput(t *testing.T) {
+	pinned := []string{"rev-list", "--count", strings.Repeat("c", 40)}
+	fake := NewFakeRunner().On("42\n", pinned...).On("main\n", "rev-parse", "--abbrev-ref", "HEAD")
+	dir := t.TempDir()
+
+	for i := 0; i < 2; i++ {
+		c := NewCachingRunner(fake, dir)
+		for j := 0; j < 2; j++ {
+			if out, err := c.Run(".", pinned...); err != nil || out != "42\n" {
+				t.Fatalf("Run = %q, %v", out, err)
+			}
+			if out, err := c.Run(".", "rev-parse", "--abbrev-ref", "HEAD"); err != nil || out != "main\n" {
+				t.Fatalf("Run = %q, %v", out, err)
+			}
+		}
+	}
+	// Only the first pinned command reached git; a new runner on the same
+	// directory reads what the first one stored.
+	if calls := fake.Calls(); len(calls) != 5 {
+		t.Fatalf("expected 1 pinned and 4 unpinned commands, got %v", calls)
+	}
+	c := NewCachingRunner(fake, dir)
+	c.Run(".", pinned...)
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestCachingRunnerSkipsFailures(t *testing.T) {
+	pinned := []string{"rev-list", "--count", strings.Repeat("d", 40)}
+	fake := NewFakeRunner().Respond(FakeResponse{ExitCode: 128, Stderr: "bad object"}, pinned...)
+	c := NewCachingRunner(fake, t.TempDir())
+	for i := 0; i < 2; i++ {
+		if _, err := c.Run(".", pinned...); err == nil {
+			t.Fatal("expected the failure to be passed on")
+		}
+	}
+	if calls := fake.Calls(); len(calls) != 2 {
+		t.Fatalf("failures must not be cached, got %v", calls)
+	}
+}
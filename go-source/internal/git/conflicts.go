@@ -0,0 +1,215 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Conflict is one region between conflict markers. Ours, Base and Theirs
+// keep their trailing newlines.
+type Conflict struct {
+	// Line is the 1-based line of the <<<<<<< marker.
+	Line        int    `json:"line"`
+	OursLabel   string `json:"oursLabel"`
+	BaseLabel   string `json:"baseLabel,omitempty"`
+	TheirsLabel string `json:"theirsLabel"`
+	Ours        string `json:"ours"`
+	// HasBase is set when the base version is known, from diff3 markers or
+	// the index.
+	HasBase bool   `json:"hasBase"`
+	Base    string `json:"base,omitempty"`
+	Theirs  string `json:"theirs"`
+}
+
+// ConflictedFile is a file with conflict markers split into the text around
+// the conflicts and the conflicts themselves.
+type ConflictedFile struct {
+	Path      string     `json:"path"`
+	Conflicts []Conflict `json:"conflicts"`
+	// segments[i] is the text before Conflicts[i]; the last one follows the
+	// last conflict.
+	segments []string
+}
+
+// ParseConflictMarkers reads merge and diff3 style conflict markers. An
+// unterminated conflict is kept as plain text.
+func ParseConflictMarkers(path, content string) ConflictedFile {
+	f := ConflictedFile{Path: path, Conflicts: []Conflict{}}
+	var text strings.Builder
+	lines := strings.SplitAfter(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		label, ok := conflictMarker(lines[i], '<')
+		if !ok {
+			text.WriteString(lines[i])
+			continue
+		}
+		c, next, ok := parseConflict(lines, i, label)
+		if !ok {
+			text.WriteString(lines[i])
+			continue
+		}
+		f.segments = append(f.segments, text.String())
+		text.Reset()
+		f.Conflicts = append(f.Conflicts, c)
+		i = next
+	}
+	f.segments = append(f.segments, text.String())
+	return f
+}
+
+// parseConflict reads the conflict starting at lines[start] and returns it
+// with the index of its >>>>>>> line.
+func parseConflict(lines []string, start int, oursLabel string) (Conflict, int, bool) {
+	c := Conflict{Line: start + 1, OursLabel: oursLabel}
+	var ours, base, theirs strings.Builder
+	section := &ours
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		if label, ok := conflictMarker(line, '|'); ok && section == &ours {
+			c.HasBase, c.BaseLabel, section = true, label, &base
+			continue
+		}
+		if _, ok := conflictMarker(line, '='); ok && section != &theirs {
+			section = &theirs
+			continue
+		}
+		if label, ok := conflictMarker(line, '>'); ok && section == &theirs {
+			c.TheirsLabel = label
+			c.Ours, c.Base, c.Theirs = ours.String(), base.String(), theirs.String()
+			return c, i, true
+		}
+		section.WriteString(line)
+	}
+	return Conflict{}, 0, false
+}
+
+// conflictMarker reports whether line is a seven-character conflict marker
+// of ch and returns its label.
+func conflictMarker(line string, ch byte) (string, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) < 7 || strings.Count(line[:7], string(ch)) != 7 {
+		return "", false
+	}
+	rest := line[7:]
+	if ch == '=' {
+		return "", rest == ""
+	}
+	if rest != "" && rest[0] != ' ' {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// Resolve returns the file with each conflict replaced by the matching
+// resolution.
+func (f ConflictedFile) Resolve(resolutions []string) (string, error) {
+	if len(resolutions) != len(f.Conflicts) {
+		return "", fmt.Errorf("%s has %d conflict(s) but %d resolution(s) were given", f.Path, len(f.Conflicts), len(resolutions))
+	}
+	var sb strings.Builder
+	for i, r := range resolutions {
+		sb.WriteString(f.segments[i])
+		if r != "" && !strings.HasSuffix(r, "\n") {
+			r += "\n"
+		}
+		sb.WriteString(r)
+	}
+	sb.WriteString(f.segments[len(f.segments)-1])
+	return sb.String(), nil
+}
+
+// Context returns up to n lines of the text before and after conflict i.
+func (f ConflictedFile) Context(i, n int) (before, after string) {
+	b := strings.SplitAfter(f.segments[i], "\n")
+	if b[len(b)-1] == "" {
+		b = b[:len(b)-1]
+	}
+	if len(b) > n {
+		b = b[len(b)-n:]
+	}
+	a := strings.SplitAfter(f.segments[i+1], "\n")
+	if len(a) > n {
+		a = a[:n]
+	}
+	return strings.Join(b, ""), strings.Join(a, "")
+}
+
+// GetConflictedFiles lists the paths with unresolved merge conflicts.
+func (g *GitExtractor) GetConflictedFiles() ([]string, error) {
+	out, err := g.runGit("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// LoadConflictedFile parses path's conflict markers. When the file was
+// written without diff3 markers, the base of each conflict is recovered by
+// re-merging the index stages, so callers can show all three versions.
+func (g *GitExtractor) LoadConflictedFile(path string) (ConflictedFile, error) {
+	b, err := os.ReadFile(g.resolvePath(path))
+	if err != nil {
+		return ConflictedFile{}, err
+	}
+	f := ParseConflictMarkers(path, string(b))
+	missing := false
+	for _, c := range f.Conflicts {
+		missing = missing || !c.HasBase
+	}
+	if !missing {
+		return f, nil
+	}
+	merged, err := g.mergeStages(path)
+	if err != nil {
+		// Without stages (e.g. after git add) the two sides still help.
+		return f, nil
+	}
+	d := ParseConflictMarkers(path, merged)
+	if len(d.Conflicts) != len(f.Conflicts) {
+		return f, nil
+	}
+	for i, c := range d.Conflicts {
+		if c.HasBase && c.Ours == f.Conflicts[i].Ours && c.Theirs == f.Conflicts[i].Theirs {
+			f.Conflicts[i].HasBase, f.Conflicts[i].Base, f.Conflicts[i].BaseLabel = true, c.Base, "base"
+		}
+	}
+	return f, nil
+}
+
+// mergeStages redoes the merge of path's index stages (1 base, 2 ours,
+// 3 theirs) with diff3 markers, without touching the working tree.
+func (g *GitExtractor) mergeStages(path string) (string, error) {
+	dir, err := os.MkdirTemp("", "difflearn-merge-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	files := make([]string, 0, 3)
+	for _, stage := range []string{"2", "1", "3"} {
+		content, err := g.runGit("show", ":"+stage+":"+path)
+		if err != nil {
+			return "", err
+		}
+		name := filepath.Join(dir, stage)
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			return "", err
+		}
+		files = append(files, name)
+	}
+	out, err := g.runGit(append([]string{"merge-file", "-p", "--diff3"}, files...)...)
+	// merge-file exits with the number of conflicts.
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && gitErr.ExitCode > 0 && gitErr.ExitCode < 128 {
+		return gitErr.Stdout, nil
+	}
+	return out, err
+}
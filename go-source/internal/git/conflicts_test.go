@@ -0,0 +1,98 @@
+package git
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+const conflictedSource = `package main
+
+<<<<<<< HEAD
+const greeting = "hello"
+||||||| base
+const greeting = "hi"
+=======
+const greeting = "hey"
+>>>>>>> feature
+
+func main() {
+<<<<<<< HEAD
+	println(greeting)
+=======
+	fmt.Println(greeting)
+>>>>>>> feature
+}
+`
+
+func TestParseConflictMarkers(t *testing.T) {
+	f := ParseConflictMarkers("main.go", conflictedSource)
+	if len(f.Conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", f.Conflicts)
+	}
+	first := f.Conflicts[0]
+	want := Conflict{Line: 3, OursLabel: "HEAD", BaseLabel: "base", TheirsLabel: "feature", Ours: "const greeting = \"hello\"\n", HasBase: true, Base: "const greeting = \"hi\"\n", Theirs: "const greeting = \"hey\"\n"}
+	if !reflect.DeepEqual(first, want) {
+		t.Fatalf("first conflict = %+v", first)
+	}
+	if second := f.Conflicts[1]; second.HasBase || second.Line != 12 || second.Theirs != "\tfmt.Println(greeting)\n" {
+		t.Fatalf("second conflict = %+v", second)
+	}
+
+	resolved, err := f.Resolve([]string{"const greeting = \"hey\"", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != "package main\n\nconst greeting = \"hey\"\n\nfunc main() {\n}\n" {
+		t.Fatalf("unexpected resolution:\n%s", resolved)
+	}
+	if _, err := f.Resolve([]string{"x"}); err == nil {
+		t.Fatal("expected an error for a missing resolution")
+	}
+	before, after := f.Context(1, 1)
+	if before != "func main() {\n" || after != "}\n" {
+		t.Fatalf("Context = %q, %q", before, after)
+	}
+}
+
+func TestParseConflictMarkersUnterminated(t *testing.T) {
+	src := "a\n<<<<<<< HEAD\nb\n=======\nc\n"
+	f := ParseConflictMarkers("x", src)
+	if len(f.Conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", f.Conflicts)
+	}
+	if out, _ := f.Resolve(nil); out != src {
+		t.Fatalf("text changed: %q", out)
+	}
+}
+
+func TestLoadConflictedFileRecoversBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := initTempRepo(t)
+	writeFile(t, dir, "main.go", "package main\n\nconst v = 1\n")
+	runIn(t, dir, "commit", "-qam", "base")
+	runIn(t, dir, "checkout", "-qb", "feature")
+	writeFile(t, dir, "main.go", "package main\n\nconst v = 3\n")
+	runIn(t, dir, "commit", "-qam", "theirs")
+	runIn(t, dir, "checkout", "-q", "-")
+	writeFile(t, dir, "main.go", "package main\n\nconst v = 2\n")
+	runIn(t, dir, "commit", "-qam", "ours")
+	cmd := exec.Command("git", "-c", "merge.conflictStyle=merge", "merge", "feature")
+	cmd.Dir = dir
+	_ = cmd.Run() // exits 1 on conflict
+
+	g := NewGitExtractor(dir)
+	files, err := g.GetConflictedFiles()
+	if err != nil || !reflect.DeepEqual(files, []string{"main.go"}) {
+		t.Fatalf("GetConflictedFiles = %v, %v", files, err)
+	}
+	f, err := g.LoadConflictedFile("main.go")
+	if err != nil || len(f.Conflicts) != 1 {
+		t.Fatalf("LoadConflictedFile = %+v, %v", f, err)
+	}
+	if c := f.Conflicts[0]; !c.HasBase || c.Base != "const v = 1\n" || c.Ours != "const v = 2\n" || c.Theirs != "const v = 3\n" {
+		t.Fatalf("unexpected conflict: %+v", c)
+	}
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConventionalCommit is a commit subject read as a Conventional Commit,
+// "type(scope)!: description". Commits that do not follow the format get
+// the type "other" and their whole subject as Description.
+type ConventionalCommit struct {
+	Type        string `json:"type"`
+	Scope       string `json:"scope,omitempty"`
+	Description string `json:"description"`
+	Breaking    bool   `json:"breaking"`
+}
+
+// ChangelogGroup is the commits of one conventional-commit type.
+type ChangelogGroup struct {
+	Type    string       `json:"type"`
+	Commits []CommitInfo `json:"commits"`
+}
+
+var conventionalRe = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+// ChangelogTypeOrder is the order changelog sections appear in; "breaking"
+// collects breaking changes of every type.
+var ChangelogTypeOrder = []string{"breaking", "feat", "fix", "perf", "refactor", "docs", "test", "build", "ci", "style", "chore", "revert", "other"}
+
+// ParseConventionalCommit reads subject, and body for a BREAKING CHANGE
+// footer.
+func ParseConventionalCommit(subject, body string) ConventionalCommit {
+	breakingFooter := strings.Contains(body, "BREAKING CHANGE:") || strings.Contains(body, "BREAKING-CHANGE:")
+	m := conventionalRe.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil || !knownChangelogType(strings.ToLower(m[1])) {
+		return ConventionalCommit{Type: "other", Description: strings.TrimSpace(subject), Breaking: breakingFooter}
+	}
+	return ConventionalCommit{
+		Type:        strings.ToLower(m[1]),
+		Scope:       strings.TrimSpace(m[2]),
+		Description: strings.TrimSpace(m[4]),
+		Breaking:    m[3] == "!" || breakingFooter,
+	}
+}
+
+// GroupCommitsByType sorts commits into ChangelogTypeOrder sections, keeping
+// their order within a section. Breaking changes are listed only under
+// "breaking", and empty sections are left out.
+func GroupCommitsByType(commits []CommitInfo) []ChangelogGroup {
+	byType := map[string][]CommitInfo{}
+	for _, c := range commits {
+		cc := ParseConventionalCommit(c.Message, c.Body)
+		t := cc.Type
+		if cc.Breaking {
+			t = "breaking"
+		}
+		byType[t] = append(byType[t], c)
+	}
+	groups := make([]ChangelogGroup, 0, len(byType))
+	for _, t := range ChangelogTypeOrder {
+		if len(byType[t]) > 0 {
+			groups = append(groups, ChangelogGroup{Type: t, Commits: byType[t]})
+		}
+	}
+	return groups
+}
+
+func knownChangelogType(t string) bool {
+	for _, known := range ChangelogTypeOrder {
+		if t == known && t != "breaking" && t != "other" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	cases := []struct {
+		subject, body string
+		want          ConventionalCommit
+	}{
+		{"feat(api): add /tags", "", ConventionalCommit{Type: "feat", Scope: "api", Description: "add /tags"}},
+		{"Fix: handle empty repos", "", ConventionalCommit{Type: "fix", Description: "handle empty repos"}},
+		{"refactor!: drop the v1 config", "", ConventionalCommit{Type: "refactor", Description: "drop the v1 config", Breaking: true}},
+		{"chore: bump deps", "BREAKING CHANGE: needs Go 1.23", ConventionalCommit{Type: "chore", Description: "bump deps", Breaking: true}},
+		{"Update README", "", ConventionalCommit{Type: "other", Description: "Update README"}},
+		{"wip: something", "", ConventionalCommit{Type: "other", Description: "wip: something"}},
+	}
+	for _, c := range cases {
+		if got := ParseConventionalCommit(c.subject, c.body); got != c.want {
+			t.Errorf("ParseConventionalCommit(%q) = %+v, want %+v", c.subject, got, c.want)
+		}
+	}
+}
+
+func TestGroupCommitsByType(t *testing.T) {
+	commits := []CommitInfo{
+		{Hash: "1", Message: "fix: a"},
+		{Hash: "2", Message: "feat: b"},
+		{Hash: "3", Message: "tidy up"},
+		{Hash: "4", Message: "feat!: c"},
+		{Hash: "5", Message: "fix: d"},
+	}
+	groups := GroupCommitsByType(commits)
+	var order []string
+	for _, g := range groups {
+		order = append(order, g.Type)
+	}
+	if want := "breaking feat fix other"; strings.Join(order, " ") != want {
+		t.Fatalf("sections %v, want %s", order, want)
+	}
+	if fixes := groups[2].Commits; len(fixes) != 2 || fixes[0].Hash != "1" || fixes[1].Hash != "5" {
+		t.Fatalf("fix section: %+v", fixes)
+	}
+}
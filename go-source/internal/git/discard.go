@@ -0,0 +1,86 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SafetyStash records the working tree and index in the stash list without
+// changing them, so discarded changes can be brought back with git stash
+// apply. It returns the stash commit, or "" when there was nothing to save.
+func (g *GitExtractor) SafetyStash(message string) (string, error) {
+	out, err := g.runGit("stash", "create", message)
+	if err != nil {
+		return "", err
+	}
+	sha := strings.TrimSpace(out)
+	if sha == "" {
+		return "", nil
+	}
+	if _, err := g.runGit("stash", "store", "-m", message, sha); err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// DiscardFile restores path in the working tree from the index, dropping its
+// unstaged changes.
+func (g *GitExtractor) DiscardFile(path string) error {
+	_, err := g.runGit("restore", "--worktree", "--", path)
+	return err
+}
+
+// DiscardHunk drops one unstaged hunk of path, identified by its @@ header,
+// by reverse-applying it to the working tree.
+func (g *GitExtractor) DiscardHunk(path, header string) error {
+	raw, err := g.runGit("diff", "--", path)
+	if err != nil {
+		return err
+	}
+	patch, ok := hunkPatch(raw, header)
+	if !ok {
+		return fmt.Errorf("the hunk %q of %s changed since it was shown; refresh and try again", header, path)
+	}
+	f, err := os.CreateTemp("", "difflearn-discard-*.patch")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(patch); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	_, err = g.runGit("apply", "-R", f.Name())
+	return err
+}
+
+// hunkPatch cuts the patch of a single-file diff down to its file header and
+// the hunk whose header line is header.
+func hunkPatch(raw, header string) (string, bool) {
+	lines := strings.SplitAfter(raw, "\n")
+	var head, hunk strings.Builder
+	inHunks, found := false, false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "@@") {
+			inHunks = true
+			if found {
+				break
+			}
+			found = strings.TrimRight(line, "\n") == header
+		}
+		switch {
+		case !inHunks:
+			head.WriteString(line)
+		case found:
+			hunk.WriteString(line)
+		}
+	}
+	if !found {
+		return "", false
+	}
+	return head.String() + hunk.String(), true
+}
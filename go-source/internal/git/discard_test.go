@@ -0,0 +1,73 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiscardHunkAndFile(t *testing.T) {
+	dir := initTempRepo(t)
+	lines := make([]string, 30)
+	for i := range lines {
+		lines[i] = "line " + string(rune('a'+i%26))
+	}
+	original := strings.Join(lines, "\n") + "\n"
+	writeFile(t, dir, "list.txt", original)
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "list")
+
+	edited := append([]string(nil), lines...)
+	edited[1] = "first change"
+	edited[27] = "second change"
+	writeFile(t, dir, "list.txt", strings.Join(edited, "\n")+"\n")
+	writeFile(t, dir, "main.go", "package main // edited\n")
+
+	g := NewGitExtractor(dir)
+	diffs, err := g.GetLocalDiff(DiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list ParsedDiff
+	for _, d := range diffs {
+		if d.NewFile == "list.txt" {
+			list = d
+		}
+	}
+	if len(list.Hunks) != 2 {
+		t.Fatalf("expected two hunks, got %+v", list.Hunks)
+	}
+
+	sha, err := g.SafetyStash("difflearn: before discarding")
+	if err != nil || sha == "" {
+		t.Fatalf("SafetyStash() = %q, %v", sha, err)
+	}
+	if err := g.DiscardHunk("list.txt", list.Hunks[0].Header); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "list.txt"))
+	if strings.Contains(string(b), "first change") || !strings.Contains(string(b), "second change") {
+		t.Fatalf("only the first hunk should be discarded:\n%s", b)
+	}
+	if err := g.DiscardHunk("list.txt", list.Hunks[0].Header); err == nil {
+		t.Fatal("expected an error for a hunk that no longer exists")
+	}
+
+	if err := g.DiscardFile("main.go"); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "main.go")); string(b) != "package main\n" {
+		t.Fatalf("main.go = %q", b)
+	}
+
+	stashes, err := g.GetStashList()
+	if err != nil || len(stashes) != 1 {
+		t.Fatalf("expected the safety stash in the list, got %+v, %v", stashes, err)
+	}
+	runIn(t, dir, "checkout", "--", ".")
+	runIn(t, dir, "stash", "apply", "-q")
+	if b, _ := os.ReadFile(filepath.Join(dir, "list.txt")); !strings.Contains(string(b), "first change") {
+		t.Fatalf("the safety stash should bring the hunk back:\n%s", b)
+	}
+}
@@ -1,12 +1,16 @@
 package git
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os/exec"
+	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,35 +18,204 @@ import (
 type DiffOptions struct {
 	Staged  bool
 	Context int
+	// Against compares the working tree (or the index when Staged is set)
+	// with an arbitrary ref instead of the index/HEAD.
+	Against string
+	// IncludeUntracked appends synthesized new-file diffs for untracked files
+	// (ignored files excluded). It has no effect on staged diffs.
+	IncludeUntracked bool
+	// RecurseSubmodules appends the diff inside each changed submodule.
+	RecurseSubmodules bool
+	Renames           RenameDetection
+	Whitespace        WhitespaceOptions
+	// Algorithm overrides the extractor's diff algorithm for this call.
+	Algorithm DiffAlgorithm
+	// All compares the working tree and untracked files with HEAD, so
+	// staged and unstaged changes show as one, and labels each file with
+	// its Scope. Staged, Against and IncludeUntracked are ignored.
+	All bool
+}
+
+// DiffAlgorithm is a value for git's --diff-algorithm.
+type DiffAlgorithm string
+
+const (
+	AlgorithmDefault   DiffAlgorithm = ""
+	AlgorithmMyers     DiffAlgorithm = "myers"
+	AlgorithmMinimal   DiffAlgorithm = "minimal"
+	AlgorithmPatience  DiffAlgorithm = "patience"
+	AlgorithmHistogram DiffAlgorithm = "histogram"
+)
+
+// ParseDiffAlgorithm validates a user-supplied algorithm name. The empty
+// string and "default" leave the choice to git.
+func ParseDiffAlgorithm(value string) (DiffAlgorithm, error) {
+	switch a := DiffAlgorithm(strings.ToLower(strings.TrimSpace(value))); a {
+	case AlgorithmDefault, "default":
+		return AlgorithmDefault, nil
+	case AlgorithmMyers, AlgorithmMinimal, AlgorithmPatience, AlgorithmHistogram:
+		return a, nil
+	default:
+		return "", fmt.Errorf("unknown diff algorithm %q (expected myers, minimal, patience or histogram)", value)
+	}
+}
+
+var defaultAlgorithm DiffAlgorithm
+
+// SetDefaultDiffAlgorithm sets the algorithm used by extractors created
+// afterwards (DIFFLEARN_DIFF_ALGORITHM).
+func SetDefaultDiffAlgorithm(a DiffAlgorithm) {
+	defaultAlgorithm = a
+}
+
+// DefaultDiffAlgorithm returns the algorithm set by SetDefaultDiffAlgorithm.
+func DefaultDiffAlgorithm() DiffAlgorithm {
+	return defaultAlgorithm
+}
+
+// WhitespaceOptions hides formatting-only changes from the diff.
+type WhitespaceOptions struct {
+	// IgnoreWhitespace ignores all whitespace (-w).
+	IgnoreWhitespace bool
+	// IgnoreSpaceChange ignores changes in the amount of whitespace (-b).
+	IgnoreSpaceChange bool
+	IgnoreBlankLines  bool
+}
+
+// WhitespaceFromOptions reads the ignoreWhitespace, ignoreSpaceChange and
+// ignoreBlankLines keys used by GetRawDiff.
+func WhitespaceFromOptions(options map[string]string) WhitespaceOptions {
+	return WhitespaceOptions{
+		IgnoreWhitespace:  options["ignoreWhitespace"] == "true",
+		IgnoreSpaceChange: options["ignoreSpaceChange"] == "true",
+		IgnoreBlankLines:  options["ignoreBlankLines"] == "true",
+	}
+}
+
+func (w WhitespaceOptions) args() []string {
+	var args []string
+	if w.IgnoreWhitespace {
+		args = append(args, "--ignore-all-space")
+	}
+	if w.IgnoreSpaceChange {
+		args = append(args, "--ignore-space-change")
+	}
+	if w.IgnoreBlankLines {
+		args = append(args, "--ignore-blank-lines")
+	}
+	return args
+}
+
+func (o DiffOptions) args() []string {
+	args := append(o.Renames.args(), o.Whitespace.args()...)
+	if o.Algorithm != AlgorithmDefault {
+		args = append(args, "--diff-algorithm="+string(o.Algorithm))
+	}
+	return args
+}
+
+// RenameDetection controls -M/-C. The zero value detects renames at git's
+// default 50% similarity and leaves copy detection off.
+type RenameDetection struct {
+	Disable bool
+	Copies  bool
+	// Threshold is the minimum similarity percentage (1-100); 0 uses git's default.
+	Threshold int
+}
+
+func (r RenameDetection) args() []string {
+	if r.Disable {
+		return []string{"--no-renames"}
+	}
+	suffix := ""
+	if r.Threshold > 0 && r.Threshold <= 100 {
+		suffix = fmt.Sprintf("%d%%", r.Threshold)
+	}
+	args := []string{"-M" + suffix}
+	if r.Copies {
+		args = append(args, "-C"+suffix)
+	}
+	return args
 }
 
 type GitExtractor struct {
-	repoPath string
-	parser   *DiffParser
+	repoPath  string
+	parser    *DiffParser
+	algorithm DiffAlgorithm
+	runner    GitRunner
+	// ctx bounds the commands of a WithContext copy.
+	ctx context.Context
 }
 
 func NewGitExtractor(repoPath string) *GitExtractor {
+	if dir := CacheDir(); dir != "" {
+		return NewGitExtractorWithRunner(repoPath, NewCachingRunner(ExecRunner{}, dir))
+	}
+	return NewGitExtractorWithRunner(repoPath, ExecRunner{})
+}
+
+// NewGitExtractorWithRunner is NewGitExtractor with a custom GitRunner, such
+// as a FakeRunner in tests.
+func NewGitExtractorWithRunner(repoPath string, runner GitRunner) *GitExtractor {
 	if repoPath == "" {
 		repoPath = "."
 	}
-	return &GitExtractor{repoPath: repoPath, parser: NewDiffParser()}
+	return &GitExtractor{repoPath: repoPath, parser: NewDiffParser(), algorithm: defaultAlgorithm, runner: runner}
+}
+
+// WithContext returns a copy of the extractor whose git commands are
+// cancelled with ctx, when its runner is a ContextRunner. The server uses it
+// to tie commands to their request.
+func (g *GitExtractor) WithContext(ctx context.Context) *GitExtractor {
+	cp := *g
+	cp.ctx = ctx
+	return &cp
+}
+
+// SetDiffAlgorithm changes the algorithm used for every patch this extractor
+// produces, unless a call passes its own DiffOptions.Algorithm.
+func (g *GitExtractor) SetDiffAlgorithm(a DiffAlgorithm) {
+	g.algorithm = a
+}
+
+// withAlgorithm inserts --diff-algorithm into patch-producing commands.
+func (g *GitExtractor) withAlgorithm(args []string) []string {
+	if g.algorithm == AlgorithmDefault || len(args) == 0 {
+		return args
+	}
+	at := 0
+	switch {
+	case args[0] == "diff":
+		at = 1
+	case args[0] == "log" && slices.Contains(args, "-p"):
+		at = 1
+	case len(args) > 1 && args[0] == "stash" && args[1] == "show":
+		at = 2
+	default:
+		return args
+	}
+	for _, a := range args {
+		if a == "--" {
+			break
+		}
+		if strings.HasPrefix(a, "--diff-algorithm") {
+			return args
+		}
+	}
+	out := append([]string{}, args[:at]...)
+	out = append(out, "--diff-algorithm="+string(g.algorithm))
+	return append(out, args[at:]...)
 }
 
 func (g *GitExtractor) runGit(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = g.repoPath
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		msg := strings.TrimSpace(stderr.String())
-		if msg == "" {
-			msg = err.Error()
-		}
-		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), msg)
+	return g.run(g.withAlgorithm(args)...)
+}
+
+func (g *GitExtractor) run(args ...string) (string, error) {
+	if cr, ok := g.runner.(ContextRunner); ok && g.ctx != nil {
+		return cr.RunContext(g.ctx, g.repoPath, args...)
 	}
-	return out.String(), nil
+	return g.runner.Run(g.repoPath, args...)
 }
 
 func normalizeBranchDiffMode(mode BranchDiffMode) BranchDiffMode {
@@ -86,15 +259,110 @@ func (g *GitExtractor) GetLocalDiff(options DiffOptions) ([]ParsedDiff, error) {
 	if ctx == 0 {
 		ctx = 3
 	}
+	if options.All {
+		return g.allLocalDiff(options, ctx)
+	}
 	args := []string{"diff", fmt.Sprintf("-U%d", ctx)}
 	if options.Staged {
 		args = []string{"diff", "--cached", fmt.Sprintf("-U%d", ctx)}
 	}
+	args = append(args, options.args()...)
+	if options.Against != "" {
+		args = append(args, options.Against, "--")
+	}
 	raw, err := g.runGit(args...)
 	if err != nil {
 		return nil, err
 	}
-	return g.parser.Parse(raw), nil
+	if options.IncludeUntracked && !options.Staged {
+		untracked, err := g.untrackedDiff(ctx)
+		if err != nil {
+			return nil, err
+		}
+		raw += untracked
+	}
+	diffs := g.parse(raw)
+	if options.RecurseSubmodules {
+		return g.ExpandSubmodules(diffs), nil
+	}
+	return diffs, nil
+}
+
+// ExpandSubmodules follows each submodule pointer change into the submodule
+// checkout and inserts its diff, with paths prefixed by the submodule path,
+// right after the pointer entry. Submodules that are not checked out or do not
+// contain the referenced commits are left as pointer-only entries.
+func (g *GitExtractor) ExpandSubmodules(diffs []ParsedDiff) []ParsedDiff {
+	out := make([]ParsedDiff, 0, len(diffs))
+	for _, d := range diffs {
+		out = append(out, d)
+		s := d.Submodule
+		if s == nil || s.OldCommit == "" || (s.NewCommit == "" && !s.Dirty) {
+			continue
+		}
+		args := []string{"diff", s.OldCommit}
+		if s.NewCommit != "" && !s.Dirty {
+			args = append(args, s.NewCommit)
+		}
+		subPath := filepath.Join(g.repoPath, d.NewFile)
+		// Without its own .git, git would resolve the parent repository instead.
+		if _, err := os.Stat(filepath.Join(subPath, ".git")); err != nil {
+			continue
+		}
+		sub := NewGitExtractorWithRunner(subPath, g.runner)
+		sub.ctx = g.ctx
+		raw, err := sub.runGit(args...)
+		if err != nil {
+			continue
+		}
+		for _, nested := range sub.ExpandSubmodules(sub.parse(raw)) {
+			nested.OldFile = d.NewFile + "/" + nested.OldFile
+			nested.NewFile = d.NewFile + "/" + nested.NewFile
+			out = append(out, nested)
+		}
+	}
+	return out
+}
+
+// GetUntrackedFiles lists files that are neither tracked nor ignored.
+func (g *GitExtractor) GetUntrackedFiles() ([]string, error) {
+	raw, err := g.runGit("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, f := range strings.Split(raw, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// untrackedDiff renders every untracked file as a new-file diff using
+// `git diff --no-index /dev/null <file>`.
+func (g *GitExtractor) untrackedDiff(ctx int) (string, error) {
+	files, err := g.GetUntrackedFiles()
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for _, f := range files {
+		chunk, err := g.run("diff", "--no-index", fmt.Sprintf("-U%d", ctx), "--", os.DevNull, f)
+		// --no-index exits 1 when the files differ, which is always the case here.
+		if err != nil {
+			var gitErr *GitError
+			if !errors.As(err, &gitErr) || gitErr.ExitCode != 1 {
+				return "", err
+			}
+			chunk = gitErr.Stdout
+		}
+		if chunk != "" && !strings.HasSuffix(chunk, "\n") {
+			chunk += "\n"
+		}
+		out.WriteString(chunk)
+	}
+	return out.String(), nil
 }
 
 func (g *GitExtractor) GetAllLocalChanges() (staged, unstaged []ParsedDiff, err error) {
@@ -107,15 +375,21 @@ func (g *GitExtractor) GetAllLocalChanges() (staged, unstaged []ParsedDiff, err
 }
 
 func (g *GitExtractor) GetCommitDiff(commit1 string, commit2 string) ([]ParsedDiff, error) {
+	return g.GetCommitDiffWithOptions(commit1, commit2, DiffOptions{})
+}
+
+// GetCommitDiffWithOptions is GetCommitDiff with rename/copy detection settings.
+func (g *GitExtractor) GetCommitDiffWithOptions(commit1, commit2 string, options DiffOptions) ([]ParsedDiff, error) {
 	rangeArg := commit1 + "^.." + commit1
 	if commit2 != "" {
 		rangeArg = commit1 + ".." + commit2
 	}
-	raw, err := g.runGit("diff", rangeArg)
+	args := append([]string{"diff"}, options.args()...)
+	raw, err := g.runGit(append(args, rangeArg)...)
 	if err != nil {
 		return nil, err
 	}
-	return g.parser.Parse(raw), nil
+	return g.parse(raw), nil
 }
 
 func (g *GitExtractor) GetBranchDiff(branch1, branch2 string, mode ...BranchDiffMode) ([]ParsedDiff, error) {
@@ -123,11 +397,112 @@ func (g *GitExtractor) GetBranchDiff(branch1, branch2 string, mode ...BranchDiff
 	if len(mode) > 0 {
 		effectiveMode = normalizeBranchDiffMode(mode[0])
 	}
-	raw, err := g.runGit("diff", branchRange(branch1, branch2, effectiveMode))
+	return g.GetBranchDiffWithOptions(branch1, branch2, effectiveMode, DiffOptions{})
+}
+
+// GetBranchDiffWithOptions is GetBranchDiff with rename/copy detection settings.
+func (g *GitExtractor) GetBranchDiffWithOptions(branch1, branch2 string, mode BranchDiffMode, options DiffOptions) ([]ParsedDiff, error) {
+	args := append([]string{"diff"}, options.args()...)
+	raw, err := g.runGit(append(args, branchRange(branch1, branch2, normalizeBranchDiffMode(mode)))...)
+	if err != nil {
+		return nil, err
+	}
+	return g.parse(raw), nil
+}
+
+func (g *GitExtractor) GetBranchNumstat(branch1, branch2 string, mode ...BranchDiffMode) ([]FileStat, error) {
+	effectiveMode := BranchModeTriple
+	if len(mode) > 0 {
+		effectiveMode = normalizeBranchDiffMode(mode[0])
+	}
+	// Commit hashes rather than names let a CachingRunner keep the result.
+	if hash, err := g.ResolveRef(branch1); err == nil {
+		branch1 = hash
+	}
+	if hash, err := g.ResolveRef(branch2); err == nil {
+		branch2 = hash
+	}
+	raw, err := g.runGit("diff", "--numstat", "-z", branchRange(branch1, branch2, effectiveMode))
+	if err != nil {
+		return nil, err
+	}
+	return g.parser.ParseNumstat(raw), nil
+}
+
+func (g *GitExtractor) GetBranchFileDiff(branch1, branch2 string, paths []string, mode ...BranchDiffMode) ([]ParsedDiff, error) {
+	effectiveMode := BranchModeTriple
+	if len(mode) > 0 {
+		effectiveMode = normalizeBranchDiffMode(mode[0])
+	}
+	args := append([]string{"diff", branchRange(branch1, branch2, effectiveMode), "--"}, paths...)
+	raw, err := g.runGit(args...)
+	if err != nil {
+		return nil, err
+	}
+	return g.parse(raw), nil
+}
+
+// DiffPaths compares two files or two directories with git diff --no-index,
+// so neither has to be in a repository. Directory diffs report paths
+// relative to the compared directories, so a file present on both sides is
+// a modification rather than a rename.
+func (g *GitExtractor) DiffPaths(pathA, pathB string, options DiffOptions) ([]ParsedDiff, error) {
+	// git diff --no-index exits 1 for a missing path too, like for differences.
+	infoA, err := os.Stat(g.resolvePath(pathA))
+	if err != nil {
+		return nil, err
+	}
+	infoB, err := os.Stat(g.resolvePath(pathB))
 	if err != nil {
 		return nil, err
 	}
-	return g.parser.Parse(raw), nil
+	ctx := options.Context
+	if ctx == 0 {
+		ctx = 3
+	}
+	args := append([]string{"diff", "--no-index", fmt.Sprintf("-U%d", ctx)}, options.args()...)
+	raw, err := g.runGit(append(args, "--", pathA, pathB)...)
+	// --no-index exits 1 when the paths differ.
+	if err != nil {
+		var gitErr *GitError
+		if !errors.As(err, &gitErr) || gitErr.ExitCode != 1 {
+			return nil, err
+		}
+		raw = gitErr.Stdout
+	}
+	diffs := g.parse(raw)
+	if !infoA.IsDir() || !infoB.IsDir() {
+		return diffs, nil
+	}
+	prefixes := []string{noIndexPrefix(pathA), noIndexPrefix(pathB)}
+	trim := func(p string) string {
+		for _, prefix := range prefixes {
+			if rest, ok := strings.CutPrefix(p, prefix); ok {
+				return rest
+			}
+		}
+		return p
+	}
+	for i := range diffs {
+		diffs[i].OldFile, diffs[i].NewFile = trim(diffs[i].OldFile), trim(diffs[i].NewFile)
+		diffs[i].IsRenamed = !diffs[i].IsCopied && diffs[i].OldFile != diffs[i].NewFile
+	}
+	return diffs, nil
+}
+
+// noIndexPrefix is how git diff --no-index prints a directory argument at
+// the start of the paths under it.
+func noIndexPrefix(dir string) string {
+	return strings.TrimPrefix(strings.TrimRight(dir, "/"), "/") + "/"
+}
+
+// resolvePath makes a relative path relative to the repository, where git
+// commands run.
+func (g *GitExtractor) resolvePath(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(g.repoPath, p)
 }
 
 func (g *GitExtractor) GetFileDiff(filePath, commit string) ([]ParsedDiff, error) {
@@ -136,27 +511,67 @@ func (g *GitExtractor) GetFileDiff(filePath, commit string) ([]ParsedDiff, error
 		if err != nil {
 			return nil, err
 		}
-		return g.parser.Parse(raw), nil
+		return g.parse(raw), nil
 	}
 	raw, err := g.runGit("diff", "--", filePath)
 	if err != nil {
 		return nil, err
 	}
-	return g.parser.Parse(raw), nil
+	return g.parse(raw), nil
 }
 
 func (g *GitExtractor) GetCommitHistory(limit int) ([]CommitInfo, error) {
 	if limit <= 0 {
 		limit = 20
 	}
-	format := `%H%x1f%aI%x1f%s%x1f%an`
-	out, err := g.runGit("log", fmt.Sprintf("--max-count=%d", limit), "--name-only", "--pretty=format:"+format)
+	return g.logCommits(fmt.Sprintf("--max-count=%d", limit))
+}
+
+// GetCommitsInRange lists the commits in a revision range such as main..HEAD,
+// v1.0...v1.1 or a single ref, newest first.
+func (g *GitExtractor) GetCommitsInRange(rangeSpec string) ([]CommitInfo, error) {
+	if strings.TrimSpace(rangeSpec) == "" {
+		return nil, fmt.Errorf("revision range is required")
+	}
+	return g.logCommits(rangeSpec)
+}
+
+// GetCommitsWithBodies is GetCommitsInRange with each commit's message body,
+// for reading trailers and references that do not fit in the subject.
+func (g *GitExtractor) GetCommitsWithBodies(rangeSpec string) ([]CommitInfo, error) {
+	commits, err := g.GetCommitsInRange(rangeSpec)
+	if err != nil || len(commits) == 0 {
+		return commits, err
+	}
+	out, err := g.runGit("log", "--pretty=format:%H%x1f%b%x1e", rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+	bodies := map[string]string{}
+	for _, rec := range strings.Split(out, "\x1e") {
+		hash, body, ok := strings.Cut(strings.TrimLeft(rec, "\n"), "\x1f")
+		if ok {
+			bodies[hash] = strings.TrimSpace(body)
+		}
+	}
+	for i := range commits {
+		commits[i].Body = bodies[commits[i].Hash]
+	}
+	return commits, nil
+}
+
+func (g *GitExtractor) logCommits(args ...string) ([]CommitInfo, error) {
+	// Records start with \x1e: merges list no files, so blank lines cannot
+	// separate commits reliably.
+	format := `%x1e%H%x1f%aI%x1f%s%x1f%an`
+	logArgs := append([]string{"log", "--numstat", "--pretty=format:" + format}, args...)
+	out, err := g.runGit(logArgs...)
 	if err != nil {
 		return nil, err
 	}
 
 	commits := make([]CommitInfo, 0)
-	blocks := strings.Split(out, "\n\n")
+	blocks := strings.Split(out, "\x1e")
 	for _, b := range blocks {
 		lines := strings.Split(strings.TrimSpace(b), "\n")
 		if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
@@ -166,24 +581,67 @@ func (g *GitExtractor) GetCommitHistory(limit int) ([]CommitInfo, error) {
 		if len(parts) < 4 {
 			continue
 		}
-		files := make([]string, 0)
-		for _, f := range lines[1:] {
-			f = strings.TrimSpace(f)
-			if f != "" {
-				files = append(files, f)
+		c := CommitInfo{Hash: parts[0], Date: parts[1], Message: parts[2], Author: parts[3], Files: make([]string, 0)}
+		for _, line := range lines[1:] {
+			stat := strings.SplitN(strings.TrimSpace(line), "\t", 3)
+			if len(stat) < 3 {
+				continue
 			}
+			// Binary files count "-" lines.
+			adds, _ := strconv.Atoi(stat[0])
+			dels, _ := strconv.Atoi(stat[1])
+			c.Additions += adds
+			c.Deletions += dels
+			c.Files = append(c.Files, numstatPath(stat[2]))
 		}
-		commits = append(commits, CommitInfo{
-			Hash:    parts[0],
-			Date:    parts[1],
-			Message: parts[2],
-			Author:  parts[3],
-			Files:   files,
-		})
+		labelCommit(&c)
+		commits = append(commits, c)
 	}
 	return commits, nil
 }
 
+// numstatPath returns the new path of a --numstat entry, which spells a
+// rename as "old => new" or "dir/{old => new}/rest".
+func numstatPath(p string) string {
+	if open := strings.Index(p, "{"); open >= 0 {
+		if end := strings.Index(p[open:], "}"); end >= 0 {
+			end += open
+			if _, to, ok := strings.Cut(p[open+1:end], " => "); ok {
+				return path.Clean(p[:open] + to + p[end+1:])
+			}
+		}
+	}
+	if _, to, ok := strings.Cut(p, " => "); ok {
+		return to
+	}
+	return p
+}
+
+// GetRangeDiff returns the combined diff for a revision range. Two-dot ranges
+// compare the endpoints directly; three-dot ranges compare against the merge base.
+func (g *GitExtractor) GetRangeDiff(rangeSpec string) ([]ParsedDiff, error) {
+	if !strings.Contains(rangeSpec, "..") {
+		return g.GetCommitDiff(rangeSpec, "")
+	}
+	raw, err := g.runGit("diff", rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+	return g.parse(raw), nil
+}
+
+func (g *GitExtractor) GetFileHistory(filePath string, limit int) ([]FileRevision, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	format := `%x1e%H%x1f%aI%x1f%s%x1f%an`
+	out, err := g.runGit("log", fmt.Sprintf("--max-count=%d", limit), "--follow", "-p", "--pretty=format:"+format, "--", filePath)
+	if err != nil {
+		return nil, err
+	}
+	return g.parser.ParseLogPatches(out), nil
+}
+
 func (g *GitExtractor) GetBranchesDetailed() ([]BranchEntry, error) {
 	currentBranch, _ := g.GetCurrentBranch()
 	out, err := g.runGit("for-each-ref", "--format=%(refname)%09%(refname:short)%09%(objectname)", "refs/heads", "refs/remotes")
@@ -281,7 +739,12 @@ func (g *GitExtractor) EnsureLocalBranch(branchRef string) (EnsureBranchResult,
 	}
 	selected := g.findBranchEntry(branchRef, branches)
 	if selected == nil {
-		return EnsureBranchResult{}, fmt.Errorf("branch not found: %s", branchRef)
+		// Tags and other revisions compare as they are; there is nothing to
+		// fetch or localize.
+		if kind, ok := g.resolveNonBranchRef(branchRef); ok {
+			return EnsureBranchResult{Input: branchRef, ResolvedLocalBranch: strings.TrimSpace(branchRef), RefKind: kind}, nil
+		}
+		return EnsureBranchResult{}, fmt.Errorf("branch, tag or commit not found: %s", branchRef)
 	}
 
 	if selected.Kind == BranchKindLocal {
@@ -350,6 +813,9 @@ func (g *GitExtractor) SwitchBranch(branchRef string, options SwitchBranchOption
 	if err != nil {
 		return SwitchBranchResult{}, err
 	}
+	if ensured.RefKind != "" {
+		return SwitchBranchResult{}, fmt.Errorf("cannot switch to %s %s: only branches can be checked out", ensured.RefKind, branchRef)
+	}
 
 	messages := make([]string, 0)
 	if ensured.Message != "" {
@@ -404,6 +870,35 @@ func (g *GitExtractor) SwitchBranch(branchRef string, options SwitchBranchOption
 	}, nil
 }
 
+func (g *GitExtractor) GetStashList() ([]StashEntry, error) {
+	out, err := g.runGit("stash", "list", "--format=%gd%x1f%H%x1f%aI%x1f%gs")
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StashEntry, 0)
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "\x1f")
+		if len(parts) < 4 {
+			continue
+		}
+		index := -1
+		fmt.Sscanf(parts[0], "stash@{%d}", &index)
+		entries = append(entries, StashEntry{Index: index, Ref: parts[0], Commit: parts[1], Date: parts[2], Message: parts[3]})
+	}
+	return entries, nil
+}
+
+func (g *GitExtractor) GetStashDiff(index int) ([]ParsedDiff, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("invalid stash index: %d", index)
+	}
+	raw, err := g.runGit("stash", "show", "-p", fmt.Sprintf("stash@{%d}", index))
+	if err != nil {
+		return nil, err
+	}
+	return g.parse(raw), nil
+}
+
 func (g *GitExtractor) GetBranches() ([]BranchInfo, error) {
 	out, err := g.runGit("branch", "-vv", "--no-abbrev")
 	if err != nil {
@@ -436,18 +931,86 @@ func (g *GitExtractor) GetCurrentBranch() (string, error) {
 	return strings.TrimSpace(out), nil
 }
 
+// GetConfigValue returns a git config value such as user.name, or "" when
+// it is unset.
+func (g *GitExtractor) GetConfigValue(key string) string {
+	out, err := g.runGit("config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// GetRemoteURL returns the fetch URL of the named remote.
+func (g *GitExtractor) GetRemoteURL(name string) (string, error) {
+	out, err := g.runGit("remote", "get-url", name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// GetFileContent returns path (relative to the repository root) as of rev:
+// "" reads the working tree, ":" the index, anything else a commit.
+func (g *GitExtractor) GetFileContent(rev, path string) (string, error) {
+	if rev != "" {
+		spec := rev + ":" + path
+		if rev == ":" {
+			spec = ":" + path
+		}
+		return g.runGit("show", spec)
+	}
+	top, err := g.runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(strings.TrimSpace(top), filepath.FromSlash(path)))
+	return string(data), err
+}
+
+// ResolveRef returns the full commit hash ref points to.
+func (g *GitExtractor) ResolveRef(ref string) (string, error) {
+	out, err := g.runGit("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %q", ref)
+	}
+	return strings.TrimSpace(out), nil
+}
+
 func (g *GitExtractor) IsRepo() bool {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	cmd.Dir = g.repoPath
-	return cmd.Run() == nil
+	_, err := g.runGit("rev-parse", "--is-inside-work-tree")
+	return err == nil
 }
 
 func (g *GitExtractor) GetRawDiff(kind string, options map[string]string) (string, error) {
+	ws := WhitespaceFromOptions(options).args()
+	if a, err := ParseDiffAlgorithm(options["algorithm"]); err == nil && a != AlgorithmDefault {
+		ws = append(ws, "--diff-algorithm="+string(a))
+	}
+	if options["binary"] == "true" {
+		// Binary deltas make the output apply with git apply.
+		ws = append(ws, "--binary")
+	}
 	switch kind {
 	case "local":
-		return g.runGit("diff")
+		args := append([]string{"diff"}, ws...)
+		if against := options["against"]; against != "" {
+			args = append(args, against, "--")
+		}
+		raw, err := g.runGit(args...)
+		if err != nil || options["untracked"] != "true" {
+			return raw, err
+		}
+		untracked, err := g.untrackedDiff(3)
+		if err != nil {
+			return "", err
+		}
+		return raw + untracked, nil
 	case "staged":
-		return g.runGit("diff", "--cached")
+		if against := options["against"]; against != "" {
+			return g.runGit(append(append([]string{"diff", "--cached"}, ws...), against, "--")...)
+		}
+		return g.runGit(append([]string{"diff", "--cached"}, ws...)...)
 	case "commit":
 		c1 := options["commit1"]
 		if c1 == "" {
@@ -457,7 +1020,7 @@ func (g *GitExtractor) GetRawDiff(kind string, options map[string]string) (strin
 		if c2 := options["commit2"]; c2 != "" {
 			r = c1 + ".." + c2
 		}
-		return g.runGit("diff", r)
+		return g.runGit(append(append([]string{"diff"}, ws...), r)...)
 	case "branch":
 		b1, b2 := options["branch1"], options["branch2"]
 		if b1 == "" || b2 == "" {
@@ -467,7 +1030,7 @@ func (g *GitExtractor) GetRawDiff(kind string, options map[string]string) (strin
 		if options["branchMode"] == "double" {
 			mode = BranchModeDouble
 		}
-		return g.runGit("diff", branchRange(b1, b2, mode))
+		return g.runGit(append(append([]string{"diff"}, ws...), branchRange(b1, b2, mode))...)
 	default:
 		return "", fmt.Errorf("unknown diff type: %s", kind)
 	}
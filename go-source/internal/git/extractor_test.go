@@ -1,16 +1,57 @@
 package git
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
-func testExtractor() *GitExtractor {
-	return NewGitExtractor("../../..")
+// testExtractor returns an extractor over a fixture repository with a second
+// branch, so tests never depend on the state of the developer's checkout.
+func testExtractor(t *testing.T) *GitExtractor {
+	t.Helper()
+	dir := initTempRepo(t)
+	runIn(t, dir, "branch", "feature")
+	return NewGitExtractor(dir)
+}
+
+// initTempRepo creates a throwaway repository with one committed file.
+func initTempRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runIn(t, dir, "init", "-q")
+	runIn(t, dir, "config", "user.email", "test@example.com")
+	runIn(t, dir, "config", "user.name", "Test")
+	writeFile(t, dir, "main.go", "package main\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func runIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
 }
 
 func TestGetBranchesDetailed(t *testing.T) {
-	g := testExtractor()
+	g := testExtractor(t)
 	branches, err := g.GetBranchesDetailed()
 	if err != nil {
 		t.Fatalf("GetBranchesDetailed() error = %v", err)
@@ -24,8 +65,22 @@ func TestGetBranchesDetailed(t *testing.T) {
 	}
 }
 
+func TestResolveRef(t *testing.T) {
+	g := testExtractor(t)
+	head, err := g.ResolveRef("HEAD")
+	if err != nil || len(head) != 40 {
+		t.Fatalf("expected a full hash for HEAD, got %q (%v)", head, err)
+	}
+	if feature, _ := g.ResolveRef("feature"); feature != head {
+		t.Fatalf("expected feature to point at HEAD, got %q", feature)
+	}
+	if _, err := g.ResolveRef("no-such-branch"); err == nil {
+		t.Fatalf("expected an error for an unknown ref")
+	}
+}
+
 func TestEnsureLocalBranchOnCurrentBranch(t *testing.T) {
-	g := testExtractor()
+	g := testExtractor(t)
 	current, err := g.GetCurrentBranch()
 	if err != nil {
 		t.Fatalf("GetCurrentBranch() error = %v", err)
@@ -44,7 +99,7 @@ func TestEnsureLocalBranchOnCurrentBranch(t *testing.T) {
 }
 
 func TestGetBranchDiffSupportsModes(t *testing.T) {
-	g := testExtractor()
+	g := testExtractor(t)
 	current, err := g.GetCurrentBranch()
 	if err != nil {
 		t.Fatalf("GetCurrentBranch() error = %v", err)
@@ -65,7 +120,7 @@ func TestGetBranchDiffSupportsModes(t *testing.T) {
 }
 
 func TestSwitchBranchReturnsMetadata(t *testing.T) {
-	g := testExtractor()
+	g := testExtractor(t)
 	current, err := g.GetCurrentBranch()
 	if err != nil {
 		t.Fatalf("GetCurrentBranch() error = %v", err)
@@ -86,3 +141,368 @@ func TestSwitchBranchReturnsMetadata(t *testing.T) {
 		t.Fatalf("expected switch messages")
 	}
 }
+
+func TestStashListAndDiff(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	runIn(t, dir, "stash", "push", "-q", "-m", "wip main")
+
+	g := NewGitExtractor(dir)
+	stashes, err := g.GetStashList()
+	if err != nil {
+		t.Fatalf("GetStashList() error = %v", err)
+	}
+	if len(stashes) != 1 || stashes[0].Index != 0 || !strings.Contains(stashes[0].Message, "wip main") {
+		t.Fatalf("unexpected stashes: %+v", stashes)
+	}
+
+	diffs, err := g.GetStashDiff(0)
+	if err != nil {
+		t.Fatalf("GetStashDiff() error = %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].NewFile != "main.go" || diffs[0].Additions != 2 {
+		t.Fatalf("unexpected stash diff: %+v", diffs)
+	}
+}
+
+func TestGetLocalDiffAgainstRef(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "a.txt", "one\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add a")
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+
+	g := NewGitExtractor(dir)
+	plain, err := g.GetLocalDiff(DiffOptions{})
+	if err != nil {
+		t.Fatalf("GetLocalDiff() error = %v", err)
+	}
+	against, err := g.GetLocalDiff(DiffOptions{Against: "HEAD~1"})
+	if err != nil {
+		t.Fatalf("GetLocalDiff(against) error = %v", err)
+	}
+	if len(plain) != 1 || len(against) != 2 {
+		t.Fatalf("expected 1 plain and 2 against-ref files, got %d and %d", len(plain), len(against))
+	}
+}
+
+func TestGetLocalDiffIncludeUntracked(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "notes.txt", "first\nsecond\n")
+	writeFile(t, dir, ".gitignore", "*.log\n")
+	writeFile(t, dir, "debug.log", "ignored\n")
+
+	g := NewGitExtractor(dir)
+	plain, err := g.GetLocalDiff(DiffOptions{})
+	if err != nil {
+		t.Fatalf("GetLocalDiff() error = %v", err)
+	}
+	if len(plain) != 0 {
+		t.Fatalf("expected no tracked changes, got %+v", plain)
+	}
+
+	diffs, err := g.GetLocalDiff(DiffOptions{IncludeUntracked: true})
+	if err != nil {
+		t.Fatalf("GetLocalDiff(untracked) error = %v", err)
+	}
+	byName := map[string]ParsedDiff{}
+	for _, d := range diffs {
+		byName[d.NewFile] = d
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("expected notes.txt and .gitignore, got %+v", diffs)
+	}
+	notes, ok := byName["notes.txt"]
+	if !ok || !notes.IsNew || notes.Additions != 2 {
+		t.Fatalf("unexpected untracked diff: %+v", notes)
+	}
+}
+
+func TestSubmodulePointerAndRecursion(t *testing.T) {
+	lib := initTempRepo(t)
+	dir := initTempRepo(t)
+	runIn(t, dir, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "vendor/lib")
+	runIn(t, dir, "commit", "-q", "-m", "add submodule")
+
+	sub := filepath.Join(dir, "vendor/lib")
+	runIn(t, sub, "config", "user.email", "test@example.com")
+	runIn(t, sub, "config", "user.name", "Test")
+	writeFile(t, sub, "lib.go", "package lib\n")
+	runIn(t, sub, "add", ".")
+	runIn(t, sub, "commit", "-q", "-m", "lib change")
+
+	g := NewGitExtractor(dir)
+	diffs, err := g.GetLocalDiff(DiffOptions{})
+	if err != nil {
+		t.Fatalf("GetLocalDiff() error = %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].Submodule == nil || diffs[0].Submodule.OldCommit == "" || diffs[0].Submodule.NewCommit == "" {
+		t.Fatalf("expected a submodule pointer change, got %+v", diffs)
+	}
+
+	recursed, err := g.GetLocalDiff(DiffOptions{RecurseSubmodules: true})
+	if err != nil {
+		t.Fatalf("GetLocalDiff(recurse) error = %v", err)
+	}
+	if len(recursed) != 2 || recursed[1].NewFile != "vendor/lib/lib.go" || !recursed[1].IsNew {
+		t.Fatalf("expected nested submodule diff, got %+v", recursed)
+	}
+}
+
+func TestGetLocalDiffRenameDetection(t *testing.T) {
+	dir := initTempRepo(t)
+	body := "package main\n\n" + strings.Repeat("// shared line\n", 20)
+	writeFile(t, dir, "a.go", body)
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add a")
+	runIn(t, dir, "mv", "a.go", "b.go")
+	writeFile(t, dir, "c.go", body)
+	runIn(t, dir, "add", ".")
+
+	g := NewGitExtractor(dir)
+	renamed, err := g.GetLocalDiff(DiffOptions{Staged: true})
+	if err != nil {
+		t.Fatalf("GetLocalDiff() error = %v", err)
+	}
+	if len(renamed) != 2 || !renamed[0].IsRenamed || renamed[0].NewFile != "b.go" {
+		t.Fatalf("expected rename of a.go to b.go, got %+v", renamed)
+	}
+
+	copies, err := g.GetLocalDiff(DiffOptions{Staged: true, Renames: RenameDetection{Copies: true}})
+	if err != nil {
+		t.Fatalf("GetLocalDiff(copies) error = %v", err)
+	}
+	copied := 0
+	for _, d := range copies {
+		if d.IsCopied {
+			copied++
+		}
+	}
+	if copied != 1 {
+		t.Fatalf("expected one copy, got %+v", copies)
+	}
+
+	plain, err := g.GetLocalDiff(DiffOptions{Staged: true, Renames: RenameDetection{Disable: true}})
+	if err != nil {
+		t.Fatalf("GetLocalDiff(no renames) error = %v", err)
+	}
+	if len(plain) != 3 {
+		t.Fatalf("expected delete plus two adds without rename detection, got %d", len(plain))
+	}
+}
+
+func TestGetLocalDiffIgnoreWhitespace(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "a.go", "func a() {\n\treturn\n}\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add a")
+	writeFile(t, dir, "a.go", "func a() {\n    return\n\n}\n")
+
+	g := NewGitExtractor(dir)
+	all, err := g.GetLocalDiff(DiffOptions{})
+	if err != nil {
+		t.Fatalf("GetLocalDiff() error = %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("expected the reindent to show up, got %d diffs", len(all))
+	}
+
+	quiet, err := g.GetLocalDiff(DiffOptions{Whitespace: WhitespaceOptions{IgnoreWhitespace: true, IgnoreBlankLines: true}})
+	if err != nil {
+		t.Fatalf("GetLocalDiff(ignore whitespace) error = %v", err)
+	}
+	for _, d := range quiet {
+		if d.Additions+d.Deletions != 0 {
+			t.Fatalf("expected formatting-only changes to be hidden, got %+v", d)
+		}
+	}
+}
+
+func TestDiffAlgorithm(t *testing.T) {
+	if _, err := ParseDiffAlgorithm("quantum"); err == nil {
+		t.Fatal("expected an error for an unknown algorithm")
+	}
+	if a, err := ParseDiffAlgorithm(" Histogram "); err != nil || a != AlgorithmHistogram {
+		t.Fatalf("ParseDiffAlgorithm(Histogram) = %q, %v", a, err)
+	}
+
+	g := NewGitExtractor(".")
+	g.SetDiffAlgorithm(AlgorithmPatience)
+	got := strings.Join(g.withAlgorithm([]string{"stash", "show", "-p", "stash@{0}"}), " ")
+	if got != "stash show --diff-algorithm=patience -p stash@{0}" {
+		t.Fatalf("unexpected stash args: %s", got)
+	}
+	got = strings.Join(g.withAlgorithm([]string{"diff", "--diff-algorithm=minimal", "HEAD"}), " ")
+	if got != "diff --diff-algorithm=minimal HEAD" {
+		t.Fatalf("per-call algorithm should win: %s", got)
+	}
+	if got := g.withAlgorithm([]string{"log", "--oneline"}); len(got) != 2 {
+		t.Fatalf("log without -p should be untouched: %v", got)
+	}
+
+	dir := initTempRepo(t)
+	writeFile(t, dir, "a.go", "a\nb\nc\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add a")
+	writeFile(t, dir, "a.go", "a\nc\nb\n")
+	repo := NewGitExtractor(dir)
+	repo.SetDiffAlgorithm(AlgorithmHistogram)
+	diffs, err := repo.GetLocalDiff(DiffOptions{})
+	if err != nil || len(diffs) != 1 {
+		t.Fatalf("GetLocalDiff(histogram) = %d diffs, %v", len(diffs), err)
+	}
+}
+
+func TestDescribeBinaries(t *testing.T) {
+	dir := initTempRepo(t)
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	writeFile(t, dir, "logo.png", png)
+	writeFile(t, dir, "blob.dat", "\x00\x01\x02")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add binaries")
+	writeFile(t, dir, "logo.png", png+"\x00\x00\x00\x00")
+
+	g := NewGitExtractor(dir)
+	diffs, err := g.GetLocalDiff(DiffOptions{})
+	if err != nil {
+		t.Fatalf("GetLocalDiff() error = %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].Binary == nil {
+		t.Fatalf("expected one binary diff, got %+v", diffs)
+	}
+	b := diffs[0].Binary
+	if b.OldSize != int64(len(png)) || b.NewSize != int64(len(png)+4) {
+		t.Fatalf("unexpected sizes %d -> %d", b.OldSize, b.NewSize)
+	}
+	if !b.IsImage || b.MimeType != "image/png" {
+		t.Fatalf("expected a png image, got %q", b.MimeType)
+	}
+	data, err := g.ReadBlob(b.NewBlob, "logo.png")
+	if err != nil || len(data) != len(png)+4 {
+		t.Fatalf("ReadBlob(working tree) = %d bytes, %v", len(data), err)
+	}
+	if _, err := g.ReadBlob(b.NewBlob, "../outside.png"); err == nil {
+		t.Fatal("expected paths outside the repository to be rejected")
+	}
+
+	committed, err := g.GetCommitDiff("HEAD", "")
+	if err != nil {
+		t.Fatalf("GetCommitDiff() error = %v", err)
+	}
+	for _, d := range committed {
+		if d.NewFile == "blob.dat" && (d.Binary == nil || d.Binary.IsImage || d.Binary.NewSize != 3 || d.Binary.OldBlob != "") {
+			t.Fatalf("unexpected metadata for blob.dat: %+v", d.Binary)
+		}
+	}
+}
+
+func TestFakeRunner(t *testing.T) {
+	fake := NewFakeRunner().
+		On("feature\n", "rev-parse", "--abbrev-ref", "HEAD").
+		Respond(FakeResponse{ExitCode: 128, Stderr: "not a git repository"}, "rev-parse", "--is-inside-work-tree")
+	g := NewGitExtractorWithRunner("/nonexistent", fake)
+
+	branch, err := g.GetCurrentBranch()
+	if err != nil || branch != "feature" {
+		t.Fatalf("GetCurrentBranch() = %q, %v", branch, err)
+	}
+	if g.IsRepo() {
+		t.Fatal("expected IsRepo to report the canned failure")
+	}
+	if _, err := g.GetCommitHistory(5); err == nil {
+		t.Fatal("expected an error for an unregistered command")
+	}
+	if calls := fake.Calls(); len(calls) != 3 || calls[0][0] != "rev-parse" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestRecordingRunnerReplay(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, dir, "notes.txt", "untracked\n")
+
+	recorder := NewRecordingRunner(nil)
+	live, err := NewGitExtractorWithRunner(dir, recorder).GetLocalDiff(DiffOptions{IncludeUntracked: true})
+	if err != nil {
+		t.Fatalf("GetLocalDiff() error = %v", err)
+	}
+
+	fixture := filepath.Join(t.TempDir(), "local.json")
+	if err := recorder.Save(fixture); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	fake, err := LoadFakeRunner(fixture)
+	if err != nil {
+		t.Fatalf("LoadFakeRunner() error = %v", err)
+	}
+	replayed, err := NewGitExtractorWithRunner(dir, fake).GetLocalDiff(DiffOptions{IncludeUntracked: true})
+	if err != nil {
+		t.Fatalf("replayed GetLocalDiff() error = %v", err)
+	}
+	if len(replayed) != 2 || len(live) != 2 || replayed[1].NewFile != "notes.txt" {
+		t.Fatalf("replay differs from the live run: %+v vs %+v", replayed, live)
+	}
+}
+
+func TestInstallAndUninstallHook(t *testing.T) {
+	g := testExtractor(t)
+	dir, err := g.HooksDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "pre-commit", "#!/bin/sh\nmake lint\n")
+	script := "#!/bin/sh\n" + HookMarker + "\nexec difflearn review --staged\n"
+
+	if _, err := g.InstallHook("pre-commit", script, false); err == nil {
+		t.Fatal("expected a foreign hook to block installation without force")
+	}
+	path, err := g.InstallHook("pre-commit", script, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode()&0o100 == 0 {
+		t.Fatalf("expected an executable hook at %s: %v", path, err)
+	}
+	if _, err := g.InstallHook("pre-commit", script, false); err != nil {
+		t.Fatalf("reinstalling over our own hook should work: %v", err)
+	}
+
+	removed, err := g.UninstallHook("pre-commit")
+	if err != nil || !removed {
+		t.Fatalf("UninstallHook = %v, %v", removed, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "#!/bin/sh\nmake lint\n" {
+		t.Fatalf("expected the original hook to be restored, got %q", data)
+	}
+	if removed, _ := g.UninstallHook("pre-commit"); removed {
+		t.Fatal("a foreign hook must not be removed")
+	}
+}
+
+func TestDiffPathsDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "old/src/main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, dir, "old/gone.txt", "bye\n")
+	writeFile(t, dir, "new/src/main.go", "package main\n\nfunc main() { run() }\n")
+	writeFile(t, dir, "new/added.txt", "hi\n")
+	diffs, err := NewGitExtractor(dir).DiffPaths("old", "new/", DiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffPaths: %v", err)
+	}
+	byPath := map[string]ParsedDiff{}
+	for _, d := range diffs {
+		byPath[d.NewFile] = d
+	}
+	if d, ok := byPath["src/main.go"]; !ok || d.IsRenamed || d.OldFile != "src/main.go" || d.Additions != 1 || d.Deletions != 1 {
+		t.Fatalf("expected src/main.go modified, got %+v", diffs)
+	}
+	if !byPath["added.txt"].IsNew || !byPath["gone.txt"].IsDeleted {
+		t.Fatalf("expected added.txt new and gone.txt deleted, got %+v", diffs)
+	}
+
+	same, err := NewGitExtractor(dir).DiffPaths("old/gone.txt", "old/gone.txt", DiffOptions{})
+	if err != nil || len(same) != 0 {
+		t.Fatalf("identical files: %+v, %v", same, err)
+	}
+}
@@ -3,14 +3,42 @@ package git
 import (
 	"encoding/json"
 	"fmt"
+	"html"
+	"slices"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/fatih/color"
 )
 
 type FormatterOptions struct {
 	ShowLineNumbers bool
 	ShowStats       bool
+	// Accessible renders plain text for screen readers: no color, no box
+	// drawing and words instead of +/- markers.
+	Accessible bool
+	// SyntaxHighlight colors line content by language, detected from the
+	// file extension, on top of the add/delete colors.
+	SyntaxHighlight bool
+	// Annotations are printed below the lines they refer to. Validate them
+	// with ValidateAnnotations first; unmatched ones are not shown.
+	Annotations []Annotation
+	// Selected marks one hunk, such as the dashboard's cursor.
+	Selected *HunkRef
+}
+
+// HunkRef points at diffs[File].Hunks[Hunk].
+type HunkRef struct {
+	File int
+	Hunk int
+}
+
+func (r *HunkRef) is(file, hunk int) bool {
+	return r != nil && r.File == file && r.Hunk == hunk
 }
 
 type DiffFormatter struct{}
@@ -18,6 +46,10 @@ type DiffFormatter struct{}
 func NewDiffFormatter() *DiffFormatter { return &DiffFormatter{} }
 
 func (f *DiffFormatter) ToTerminal(diffs []ParsedDiff, options FormatterOptions) string {
+	notes := annotationIndex(options.Annotations)
+	if options.Accessible {
+		return f.accessibleText(diffs, notes, options.Selected)
+	}
 	showLineNumbers := true
 	showStats := true
 	if options.ShowLineNumbers == false {
@@ -28,17 +60,35 @@ func (f *DiffFormatter) ToTerminal(diffs []ParsedDiff, options FormatterOptions)
 	}
 
 	out := make([]string, 0)
-	for _, diff := range diffs {
+	for fi, diff := range diffs {
 		out = append(out, color.New(color.Bold).Sprint(strings.Repeat("─", 60)))
-		out = append(out, f.formatFileHeader(diff))
+		out = append(out, f.formatFileHeader(diff)+color.HiBlackString(scopeNote(diff)))
 		if showStats {
 			out = append(out, fmt.Sprintf("  %s %s", color.GreenString("+%d", diff.Additions), color.RedString("-%d", diff.Deletions)))
 		}
+		if diff.Binary != nil {
+			out = append(out, color.MagentaString("  Binary: %s", BinarySummary(diff.Binary)))
+		}
 		out = append(out, "")
-		for _, h := range diff.Hunks {
-			out = append(out, color.CyanString(h.Header))
-			for _, line := range h.Lines {
-				out = append(out, f.formatLine(line, showLineNumbers))
+		for hi, h := range diff.Hunks {
+			if options.Selected.is(fi, hi) {
+				out = append(out, color.New(color.FgYellow, color.Bold).Sprint("▶ "+h.Header))
+			} else {
+				out = append(out, color.CyanString(h.Header))
+			}
+			var tokens [][]chroma.Token
+			if options.SyntaxHighlight && !color.NoColor {
+				tokens = highlightHunk(diff.NewFile, h.Lines)
+			}
+			for i, line := range h.Lines {
+				if tokens != nil {
+					out = append(out, f.formatHighlightedLine(line, tokens[i], showLineNumbers))
+				} else {
+					out = append(out, f.formatLine(line, showLineNumbers))
+				}
+				for _, a := range lineAnnotations(notes, diff, line) {
+					out = append(out, formatAnnotation(a, showLineNumbers))
+				}
 			}
 			out = append(out, "")
 		}
@@ -46,32 +96,110 @@ func (f *DiffFormatter) ToTerminal(diffs []ParsedDiff, options FormatterOptions)
 	return strings.Join(out, "\n")
 }
 
+// ToAccessibleText describes diffs linearly in plain words so that nothing is
+// conveyed by color or symbols alone.
+func (f *DiffFormatter) ToAccessibleText(diffs []ParsedDiff) string {
+	return f.accessibleText(diffs, nil, nil)
+}
+
+func (f *DiffFormatter) accessibleText(diffs []ParsedDiff, notes map[string][]Annotation, selected *HunkRef) string {
+	if len(diffs) == 0 {
+		return "No changes."
+	}
+	out := []string{fmt.Sprintf("%s changed: %s added, %s removed.", plural(len(diffs), "file"), plural(sumAdds(diffs), "line"), plural(sumDels(diffs), "line"))}
+	for i, d := range diffs {
+		file := describeFile(d)
+		if d.Scope != "" {
+			file += ", " + strings.Trim(scopeNote(d), " []")
+		}
+		out = append(out, "", fmt.Sprintf("File %d of %d: %s.", i+1, len(diffs), file))
+		if d.Submodule != nil {
+			continue
+		}
+		if d.IsBinary {
+			if d.Binary != nil {
+				out = append(out, fmt.Sprintf("Binary file, %s. Contents not shown.", strings.Replace(BinarySummary(d.Binary), "→", "to", 1)))
+			} else {
+				out = append(out, "Binary file, contents not shown.")
+			}
+			continue
+		}
+		out = append(out, fmt.Sprintf("%s added, %s removed.", plural(d.Additions, "line"), plural(d.Deletions, "line")))
+		for j, h := range d.Hunks {
+			change := fmt.Sprintf("Change %d of %d, starting at line %d.", j+1, len(d.Hunks), h.NewStart)
+			if selected.is(i, j) {
+				change += " Selected."
+			}
+			out = append(out, change)
+			for _, line := range h.Lines {
+				switch line.Type {
+				case LineAdd:
+					out = append(out, fmt.Sprintf("Added line %d: %s", derefLine(line.NewLineNumber), line.Content))
+				case LineDelete:
+					out = append(out, fmt.Sprintf("Removed line %d: %s", derefLine(line.OldLineNumber), line.Content))
+				default:
+					out = append(out, fmt.Sprintf("Unchanged line %d: %s", derefLine(line.NewLineNumber), line.Content))
+				}
+				for _, a := range lineAnnotations(notes, d, line) {
+					out = append(out, fmt.Sprintf("Comment, %s: %s", defaultString(a.Severity, "note"), a.Message))
+				}
+			}
+		}
+		out = append(out, fmt.Sprintf("End of %s.", d.NewFile))
+	}
+	return strings.Join(out, "\n")
+}
+
+func describeFile(d ParsedDiff) string {
+	switch {
+	case d.Submodule != nil:
+		return fmt.Sprintf("submodule %s moved from commit %s", d.NewFile, strings.Replace(SubmoduleRange(d.Submodule), "→", "to", 1))
+	case d.IsNew:
+		return "new file " + d.NewFile
+	case d.IsDeleted:
+		return "deleted file " + d.OldFile
+	case d.IsCopied:
+		return fmt.Sprintf("%s copied from %s", d.NewFile, d.OldFile)
+	case d.IsRenamed:
+		return fmt.Sprintf("%s renamed from %s", d.NewFile, d.OldFile)
+	default:
+		return "modified file " + d.NewFile
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func derefLine(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
 func (f *DiffFormatter) formatFileHeader(diff ParsedDiff) string {
 	switch {
+	case diff.Submodule != nil:
+		return color.New(color.FgMagenta, color.Bold).Sprintf("⎘ Submodule: %s %s", diff.NewFile, SubmoduleRange(diff.Submodule))
 	case diff.IsNew:
 		return color.New(color.FgGreen, color.Bold).Sprintf("+ New: %s", diff.NewFile)
 	case diff.IsDeleted:
 		return color.New(color.FgRed, color.Bold).Sprintf("- Deleted: %s", diff.OldFile)
+	case diff.IsCopied:
+		return color.New(color.FgYellow, color.Bold).Sprintf("⧉ Copied: %s → %s%s", diff.OldFile, diff.NewFile, similarityNote(diff))
 	case diff.IsRenamed:
-		return color.New(color.FgYellow, color.Bold).Sprintf("→ Renamed: %s → %s", diff.OldFile, diff.NewFile)
+		return color.New(color.FgYellow, color.Bold).Sprintf("→ Renamed: %s → %s%s", diff.OldFile, diff.NewFile, similarityNote(diff))
 	default:
 		return color.New(color.FgBlue, color.Bold).Sprintf("Modified: %s", diff.NewFile)
 	}
 }
 
 func (f *DiffFormatter) formatLine(line ParsedLine, showLineNumbers bool) string {
-	lineNum := ""
-	if showLineNumbers {
-		oldNum := "    "
-		newNum := "    "
-		if line.OldLineNumber != nil {
-			oldNum = fmt.Sprintf("%4d", *line.OldLineNumber)
-		}
-		if line.NewLineNumber != nil {
-			newNum = fmt.Sprintf("%4d", *line.NewLineNumber)
-		}
-		lineNum = color.HiBlackString("%s %s │ ", oldNum, newNum)
-	}
+	lineNum := formatLineNumbers(line, showLineNumbers)
 	prefix := " "
 	if line.Type == LineAdd {
 		prefix = "+"
@@ -90,6 +218,55 @@ func (f *DiffFormatter) formatLine(line ParsedLine, showLineNumbers bool) string
 	}
 }
 
+// formatHighlightedLine keeps the colored +/- marker and shows additions and
+// deletions as a dark background so the token colors stay readable.
+func (f *DiffFormatter) formatHighlightedLine(line ParsedLine, tokens []chroma.Token, showLineNumbers bool) string {
+	lineNum := formatLineNumbers(line, showLineNumbers)
+	switch line.Type {
+	case LineAdd:
+		return lineNum + color.GreenString("+") + "\x1b[48;5;22m" + ansiTokens(tokens) + "\x1b[0m"
+	case LineDelete:
+		return lineNum + color.RedString("-") + "\x1b[48;5;52m" + ansiTokens(tokens) + "\x1b[0m"
+	default:
+		return lineNum + " " + ansiTokens(tokens)
+	}
+}
+
+// formatAnnotation renders a comment indented under the line it belongs to.
+func formatAnnotation(a Annotation, showLineNumbers bool) string {
+	indent := "  "
+	if showLineNumbers {
+		indent = strings.Repeat(" ", 12)
+	}
+	label := "note"
+	c := color.New(color.FgCyan)
+	switch a.Severity {
+	case "critical":
+		label, c = a.Severity, color.New(color.FgRed, color.Bold)
+	case "important":
+		label, c = a.Severity, color.New(color.FgYellow)
+	case "minor":
+		label = a.Severity
+	}
+	return indent + c.Sprintf("💬 [%s] %s", label, a.Message)
+}
+
+func formatLineNumbers(line ParsedLine, showLineNumbers bool) string {
+	lineNum := ""
+	if showLineNumbers {
+		oldNum := "    "
+		newNum := "    "
+		if line.OldLineNumber != nil {
+			oldNum = fmt.Sprintf("%4d", *line.OldLineNumber)
+		}
+		if line.NewLineNumber != nil {
+			newNum = fmt.Sprintf("%4d", *line.NewLineNumber)
+		}
+		lineNum = color.HiBlackString("%s %s │ ", oldNum, newNum)
+	}
+	return lineNum
+}
+
 func (f *DiffFormatter) ToMarkdown(diffs []ParsedDiff) string {
 	out := make([]string, 0)
 	out = append(out, "# Git Diff Summary", "")
@@ -100,6 +277,9 @@ func (f *DiffFormatter) ToMarkdown(diffs []ParsedDiff) string {
 	}
 	out = append(out, fmt.Sprintf("**Files changed:** %d", len(diffs)))
 	out = append(out, fmt.Sprintf("**Additions:** +%d | **Deletions:** -%d", adds, dels), "")
+	if refactor := BuildRefactorMap(diffs).Markdown(); refactor != "" {
+		out = append(out, refactor)
+	}
 
 	for _, d := range diffs {
 		status := ""
@@ -107,10 +287,20 @@ func (f *DiffFormatter) ToMarkdown(diffs []ParsedDiff) string {
 			status = "(new)"
 		} else if d.IsDeleted {
 			status = "(deleted)"
+		} else if d.IsCopied {
+			status = fmt.Sprintf("(copied from %s%s)", d.OldFile, similarityNote(d))
 		} else if d.IsRenamed {
-			status = "(renamed)"
+			status = fmt.Sprintf("(renamed from %s%s)", d.OldFile, similarityNote(d))
+		}
+		out = append(out, fmt.Sprintf("## %s %s%s", d.NewFile, status, scopeNote(d)))
+		if d.Submodule != nil {
+			out = append(out, fmt.Sprintf("Submodule pointer: `%s`", SubmoduleRange(d.Submodule)), "")
+			continue
+		}
+		if d.Binary != nil {
+			out = append(out, fmt.Sprintf("Binary file: %s", BinarySummary(d.Binary)), "")
+			continue
 		}
-		out = append(out, fmt.Sprintf("## %s %s", d.NewFile, status))
 		if d.Additions > 0 || d.Deletions > 0 {
 			out = append(out, fmt.Sprintf("*+%d -%d*", d.Additions, d.Deletions), "")
 		}
@@ -133,6 +323,207 @@ func (f *DiffFormatter) ToMarkdown(diffs []ParsedDiff) string {
 	return strings.Join(out, "\n")
 }
 
+// ToHTML renders diffs as a standalone HTML page with syntax-highlighted
+// code, using chroma's CSS classes so the stylesheet can be swapped.
+func (f *DiffFormatter) ToHTML(diffs []ParsedDiff) string {
+	return f.ToHTMLReport(diffs, "")
+}
+
+// ToHTMLReport is ToHTML with prelude, trusted HTML such as rendered AI
+// sections, placed between the summary line and the files.
+func (f *DiffFormatter) ToHTMLReport(diffs []ParsedDiff, prelude string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>DiffLearn diff</title>\n<style>\n")
+	b.WriteString(htmlDiffCSS)
+	_ = chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&b, styles.Get("github"))
+	b.WriteString("</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<p class=\"summary\">%d files changed, <span class=\"adds\">+%d</span> <span class=\"dels\">-%d</span></p>\n", len(diffs), sumAdds(diffs), sumDels(diffs))
+	if prelude != "" {
+		b.WriteString("<div class=\"report\">\n" + prelude + "</div>\n")
+	}
+	for _, d := range diffs {
+		name := d.NewFile
+		if d.IsDeleted {
+			name = d.OldFile
+		}
+		b.WriteString("<section class=\"file\">\n")
+		fmt.Fprintf(&b, "<h2>%s <span class=\"adds\">+%d</span> <span class=\"dels\">-%d</span></h2>\n", html.EscapeString(describeFile(d)), d.Additions, d.Deletions)
+		if d.Binary != nil {
+			fmt.Fprintf(&b, "<p class=\"binary\">Binary file: %s</p>\n", html.EscapeString(BinarySummary(d.Binary)))
+		}
+		if len(d.Hunks) > 0 {
+			b.WriteString("<table class=\"chroma\">\n")
+		}
+		for _, h := range d.Hunks {
+			fmt.Fprintf(&b, "<tr class=\"hunk\"><td colspan=\"3\">%s</td></tr>\n", html.EscapeString(h.Header))
+			tokens := highlightHunk(name, h.Lines)
+			for i, line := range h.Lines {
+				class, marker := "ctx", " "
+				switch line.Type {
+				case LineAdd:
+					class, marker = "add", "+"
+				case LineDelete:
+					class, marker = "del", "-"
+				}
+				code := html.EscapeString(line.Content)
+				if tokens != nil {
+					code = htmlTokens(tokens[i])
+				}
+				fmt.Fprintf(&b, "<tr class=\"%s\"><td class=\"ln\">%s</td><td class=\"ln\">%s</td><td><pre>%s%s</pre></td></tr>\n",
+					class, lineNumber(line.OldLineNumber), lineNumber(line.NewLineNumber), marker, code)
+			}
+		}
+		if len(d.Hunks) > 0 {
+			b.WriteString("</table>\n")
+		}
+		b.WriteString("</section>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+func lineNumber(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return fmt.Sprint(*n)
+}
+
+const htmlDiffCSS = `body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 2em; }
+.file { margin-bottom: 2em; }
+.file h2 { font-size: 1em; font-family: monospace; }
+.adds { color: #1a7f37; }
+.dels { color: #cf222e; }
+table.chroma { border-collapse: collapse; width: 100%; font-family: monospace; font-size: 13px; }
+table.chroma td { padding: 0 0.5em; vertical-align: top; }
+table.chroma pre { margin: 0; white-space: pre-wrap; }
+td.ln { color: #8c959f; text-align: right; user-select: none; width: 3em; }
+tr.hunk td { background: #ddf4ff; color: #57606a; }
+tr.add { background: #e6ffec; }
+tr.del { background: #ffebe9; }
+.report { max-width: 60em; margin-bottom: 2em; line-height: 1.5; }
+.report pre { background: #f6f8fa; padding: 0.5em; overflow-x: auto; }
+`
+
+// ToUnified re-serializes parsed diffs as a git-style unified diff that
+// DiffParser reads back to the same structure.
+func (f *DiffFormatter) ToUnified(diffs []ParsedDiff) string {
+	var b strings.Builder
+	for _, d := range diffs {
+		fmt.Fprintf(&b, "diff --git %s %s\n", quotePath("a/"+d.OldFile), quotePath("b/"+d.NewFile))
+		switch {
+		case d.IsNew || d.IsDeleted:
+			if d.IsDeleted {
+				fmt.Fprintf(&b, "deleted file mode %s\n", defaultString(d.OldMode, "100644"))
+			}
+			if d.IsNew {
+				fmt.Fprintf(&b, "new file mode %s\n", defaultString(d.NewMode, "100644"))
+			}
+		case d.OldMode != "" && d.NewMode != "" && d.OldMode != d.NewMode:
+			fmt.Fprintf(&b, "old mode %s\nnew mode %s\n", d.OldMode, d.NewMode)
+		}
+		if d.IsCopied || d.IsRenamed {
+			verb := "rename"
+			if d.IsCopied {
+				verb = "copy"
+			}
+			if d.Similarity > 0 {
+				fmt.Fprintf(&b, "similarity index %d%%\n", d.Similarity)
+			}
+			fmt.Fprintf(&b, "%s from %s\n%s to %s\n", verb, quotePath(d.OldFile), verb, quotePath(d.NewFile))
+		}
+		if d.Binary != nil {
+			fmt.Fprintf(&b, "index %s..%s\n", defaultString(d.Binary.OldBlob, "0000000"), defaultString(d.Binary.NewBlob, "0000000"))
+		}
+		oldPath, newPath := quotePath("a/"+d.OldFile), quotePath("b/"+d.NewFile)
+		if d.IsNew {
+			oldPath = "/dev/null"
+		}
+		if d.IsDeleted {
+			newPath = "/dev/null"
+		}
+		if d.IsBinary {
+			fmt.Fprintf(&b, "Binary files %s and %s differ\n", oldPath, newPath)
+			continue
+		}
+		if len(d.Hunks) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "--- %s%s\n+++ %s%s\n", oldPath, pathTerminator(oldPath), newPath, pathTerminator(newPath))
+		for _, h := range d.Hunks {
+			header := h.Header
+			if !hunkRe.MatchString(header) {
+				header = fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+			}
+			b.WriteString(header + "\n")
+			for _, line := range h.Lines {
+				switch line.Type {
+				case LineAdd:
+					b.WriteByte('+')
+				case LineDelete:
+					b.WriteByte('-')
+				default:
+					b.WriteByte(' ')
+				}
+				b.WriteString(line.Content + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// quotePath applies git's C-style quoting when a path contains quotes,
+// backslashes, control characters or non-ASCII bytes.
+func quotePath(path string) string {
+	needs := false
+	for i := 0; i < len(path); i++ {
+		if c := path[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' {
+			needs = true
+			break
+		}
+	}
+	if !needs {
+		return path
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// pathTerminator mirrors git, which ends ---/+++ names containing spaces
+// with a tab.
+func pathTerminator(path string) string {
+	if strings.Contains(path, " ") && !strings.HasPrefix(path, "\"") {
+		return "\t"
+	}
+	return ""
+}
+
+func defaultString(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
+
 func (f *DiffFormatter) ToJSON(diffs []ParsedDiff) string {
 	payload := map[string]any{
 		"summary": map[string]any{
@@ -142,6 +533,9 @@ func (f *DiffFormatter) ToJSON(diffs []ParsedDiff) string {
 		},
 		"files": diffs,
 	}
+	if refactor := BuildRefactorMap(diffs); !refactor.Empty() {
+		payload["refactor"] = refactor
+	}
 	b, _ := json.MarshalIndent(payload, "", "  ")
 	return string(b)
 }
@@ -152,10 +546,17 @@ func (f *DiffFormatter) ToSummary(diffs []ParsedDiff) string {
 	list := make([]string, 0, len(diffs))
 	for _, d := range diffs {
 		status := "M "
+		if d.Submodule != nil {
+			list = append(list, "S "+d.NewFile+" "+SubmoduleRange(d.Submodule))
+			continue
+		}
 		if d.IsNew {
 			status = "+ "
 		} else if d.IsDeleted {
 			status = "- "
+		} else if d.IsCopied {
+			list = append(list, "C "+d.NewFile+" (copy of "+d.OldFile+")")
+			continue
 		} else if d.IsRenamed {
 			status = "→ "
 		}
@@ -164,6 +565,101 @@ func (f *DiffFormatter) ToSummary(diffs []ParsedDiff) string {
 	return fmt.Sprintf("%d file(s) changed, +%d -%d\n\n%s", files, adds, dels, strings.Join(list, "\n"))
 }
 
+// ToSummaryCard is a short colored overview of a change for sharing: a
+// title, the totals and the most changed files with git --stat style bars.
+func (f *DiffFormatter) ToSummaryCard(title string, diffs []ParsedDiff) string {
+	const maxFiles, barWidth = 12, 24
+	files := slices.Clone(diffs)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Additions+files[i].Deletions > files[j].Additions+files[j].Deletions
+	})
+	shown := files[:min(len(files), maxFiles)]
+	nameWidth, most := 0, 0
+	for _, d := range shown {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(d.NewFile))
+		most = max(most, d.Additions+d.Deletions)
+	}
+	nameWidth = min(nameWidth, 50)
+
+	var b strings.Builder
+	b.WriteString(color.New(color.Bold).Sprint(title) + "\n")
+	fmt.Fprintf(&b, "%d file(s) changed  %s  %s\n\n", len(diffs), color.GreenString("+%d", sumAdds(diffs)), color.RedString("-%d", sumDels(diffs)))
+	for _, d := range shown {
+		name := d.NewFile
+		if d.IsDeleted {
+			name = d.OldFile
+		}
+		if n := utf8.RuneCountInString(name); n > nameWidth {
+			name = "…" + string([]rune(name)[n-nameWidth+1:])
+		}
+		adds, dels := d.Additions, d.Deletions
+		if most > barWidth {
+			adds = (adds*barWidth + most - 1) / most
+			dels = (dels*barWidth + most - 1) / most
+		}
+		fmt.Fprintf(&b, "  %-*s %s %s  %s%s\n", nameWidth, name, color.GreenString("%5s", fmt.Sprintf("+%d", d.Additions)), color.RedString("%-5s", fmt.Sprintf("-%d", d.Deletions)),
+			color.GreenString(strings.Repeat("█", adds)), color.RedString(strings.Repeat("█", dels)))
+	}
+	if len(files) > len(shown) {
+		b.WriteString(color.HiBlackString("  … %d more file(s)", len(files)-len(shown)) + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func similarityNote(d ParsedDiff) string {
+	if d.Similarity == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %d%% similar", d.Similarity)
+}
+
+// BinarySummary describes a binary change as "image/png, 1.2 KB → 3.4 KB".
+func BinarySummary(b *BinaryInfo) string {
+	var size string
+	switch {
+	case b.OldBlob == "":
+		size = humanSize(b.NewSize)
+	case b.NewBlob == "":
+		size = humanSize(b.OldSize) + " removed"
+	default:
+		size = humanSize(b.OldSize) + " → " + humanSize(b.NewSize)
+	}
+	if b.MimeType == "" {
+		return size
+	}
+	return b.MimeType + ", " + size
+}
+
+func humanSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// SubmoduleRange renders a submodule pointer change as "old → new" using
+// abbreviated SHAs.
+func SubmoduleRange(s *SubmoduleChange) string {
+	abbrev := func(sha string) string {
+		if sha == "" {
+			return "(none)"
+		}
+		if len(sha) > 7 {
+			return sha[:7]
+		}
+		return sha
+	}
+	out := abbrev(s.OldCommit) + " → " + abbrev(s.NewCommit)
+	if s.Dirty {
+		out += " (dirty)"
+	}
+	return out
+}
+
 func sumAdds(diffs []ParsedDiff) int {
 	t := 0
 	for _, d := range diffs {
@@ -3,6 +3,8 @@ package git
 import (
 	"strings"
 	"testing"
+
+	"github.com/fatih/color"
 )
 
 func TestFormatterMarkdownAndSummary(t *testing.T) {
@@ -44,3 +46,101 @@ func TestFormatterMarkdownAndSummary(t *testing.T) {
 	}
 }
 
+func TestToTerminalAccessible(t *testing.T) {
+	add, del := 2, 1
+	diffs := []ParsedDiff{{
+		OldFile:   "a.txt",
+		NewFile:   "a.txt",
+		Additions: 1,
+		Deletions: 1,
+		Hunks: []ParsedHunk{{
+			NewStart: 1,
+			Lines: []ParsedLine{
+				{Type: LineDelete, Content: "old", OldLineNumber: &del},
+				{Type: LineAdd, Content: "new", NewLineNumber: &add},
+			},
+		}},
+	}}
+
+	out := NewDiffFormatter().ToTerminal(diffs, FormatterOptions{Accessible: true})
+	for _, want := range []string{"1 file changed: 1 line added, 1 line removed.", "File 1 of 1: modified file a.txt.", "Removed line 1: old", "Added line 2: new"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") || strings.Contains(out, "─") {
+		t.Fatalf("accessible output must not contain color codes or box drawing:\n%s", out)
+	}
+}
+
+func TestSyntaxHighlight(t *testing.T) {
+	prev := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = prev }()
+
+	hunk := ParsedHunk{
+		Header: "@@ -1,2 +1,2 @@",
+		Lines: []ParsedLine{
+			{Type: LineContext, Content: "func main() {"},
+			{Type: LineDelete, Content: "\treturn \"old\" // <b>"},
+			{Type: LineAdd, Content: "\treturn \"new\""},
+		},
+	}
+	goDiff := []ParsedDiff{{OldFile: "main.go", NewFile: "main.go", Hunks: []ParsedHunk{hunk}}}
+	txtDiff := []ParsedDiff{{OldFile: "notes.unknownext", NewFile: "notes.unknownext", Hunks: []ParsedHunk{hunk}}}
+
+	f := NewDiffFormatter()
+	opts := FormatterOptions{ShowLineNumbers: true, SyntaxHighlight: true}
+	out := f.ToTerminal(goDiff, opts)
+	if !strings.Contains(out, "\x1b[35mfunc\x1b[39m") || !strings.Contains(out, "\x1b[48;5;22m") {
+		t.Fatalf("expected keyword colors and add background, got %q", out)
+	}
+	if strings.Contains(f.ToTerminal(txtDiff, opts), "\x1b[35m") {
+		t.Fatalf("unknown extensions should not be highlighted")
+	}
+
+	page := f.ToHTML(goDiff)
+	for _, want := range []string{`<span class="kd">func</span>`, `.chroma .k {`, `<tr class="del">`, `&lt;b&gt;`} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("expected %q in html output:\n%s", want, page)
+		}
+	}
+}
+
+func BenchmarkFormatTerminal(b *testing.B) {
+	diffs := NewDiffParser().Parse(SyntheticDiff(200, 4, 40))
+	f := NewDiffFormatter()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.ToTerminal(diffs, FormatterOptions{})
+	}
+}
+
+func BenchmarkFormatMarkdown(b *testing.B) {
+	diffs := NewDiffParser().Parse(SyntheticDiff(200, 4, 40))
+	f := NewDiffFormatter()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.ToMarkdown(diffs)
+	}
+}
+
+func TestToSummaryCard(t *testing.T) {
+	diffs := []ParsedDiff{
+		{OldFile: "small.go", NewFile: "small.go", Additions: 1},
+		{OldFile: "big.go", NewFile: "big.go", Additions: 30, Deletions: 18},
+		{OldFile: "gone.go", NewFile: "gone.go", IsDeleted: true, Deletions: 4},
+	}
+	card := NewDiffFormatter().ToSummaryCard("main...feature", diffs)
+	lines := strings.Split(card, "\n")
+	if lines[0] != "main...feature" || lines[1] != "3 file(s) changed  +31  -22" {
+		t.Fatalf("unexpected card header:\n%s", card)
+	}
+	// Files come most changed first; bars are scaled to 24 cells.
+	if !strings.HasPrefix(lines[3], "  big.go     +30 -18    █") || strings.Count(lines[3], "█") != 24 {
+		t.Errorf("unexpected first file line %q", lines[3])
+	}
+	if !strings.Contains(lines[5], "small.go") || strings.Count(lines[5], "█") != 1 {
+		t.Errorf("a one-line change should keep one cell: %q", lines[5])
+	}
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GraphCommit is a commit in a CommitGraph with its parents and the refs
+// pointing at it ("HEAD -> main", "origin/main", "tag: v1.0").
+type GraphCommit struct {
+	Hash    string   `json:"hash"`
+	Parents []string `json:"parents"`
+	Refs    []string `json:"refs"`
+	Date    string   `json:"date"`
+	Author  string   `json:"author"`
+	Message string   `json:"message"`
+}
+
+// GraphRow is one line of git's ASCII graph; Commit is set on the lines that
+// carry a commit and empty on connector lines.
+type GraphRow struct {
+	Graph  string `json:"graph"`
+	Commit string `json:"commit,omitempty"`
+}
+
+type CommitGraph struct {
+	Commits []GraphCommit `json:"commits"`
+	Rows    []GraphRow    `json:"rows"`
+}
+
+// GetCommitGraph returns the newest limit commits reachable from HEAD, or
+// with allBranches from every local and remote branch and tag, in
+// topological order, together with the rows of git log --graph to draw them.
+func (g *GitExtractor) GetCommitGraph(limit int, allBranches bool) (CommitGraph, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	args := []string{"log", "--graph", "--topo-order", "--color=never", "--decorate=short",
+		fmt.Sprintf("--max-count=%d", limit), "--format=%x1e%H%x1f%P%x1f%D%x1f%aI%x1f%an%x1f%s"}
+	if allBranches {
+		args = append(args, "--branches", "--remotes", "--tags")
+	}
+	out, err := g.runGit(args...)
+	if err != nil {
+		return CommitGraph{}, err
+	}
+	return ParseCommitGraph(out), nil
+}
+
+// ParseCommitGraph reads a graph log whose commit lines carry, after the
+// graph, \x1e and the hash, parents, refs, date, author and subject
+// separated by \x1f. Mercurial and jj templates write the same layout.
+func ParseCommitGraph(out string) CommitGraph {
+	graph := CommitGraph{Commits: []GraphCommit{}, Rows: []GraphRow{}}
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		prefix, payload, ok := strings.Cut(line, "\x1e")
+		row := GraphRow{Graph: strings.TrimRight(prefix, " ")}
+		if ok {
+			parts := strings.SplitN(payload, "\x1f", 6)
+			if len(parts) < 6 {
+				continue
+			}
+			c := GraphCommit{
+				Hash:    parts[0],
+				Parents: strings.Fields(parts[1]),
+				Refs:    []string{},
+				Date:    parts[3],
+				Author:  parts[4],
+				Message: parts[5],
+			}
+			for _, ref := range strings.Split(parts[2], ", ") {
+				if ref = strings.TrimSpace(ref); ref != "" {
+					c.Refs = append(c.Refs, ref)
+				}
+			}
+			graph.Commits = append(graph.Commits, c)
+			row.Commit = c.Hash
+		} else if row.Graph == "" {
+			continue
+		}
+		graph.Rows = append(graph.Rows, row)
+	}
+	return graph
+}
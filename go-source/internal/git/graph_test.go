@@ -0,0 +1,58 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetCommitGraph(t *testing.T) {
+	dir := initTempRepo(t)
+	runIn(t, dir, "branch", "-M", "main")
+	runIn(t, dir, "checkout", "-q", "-b", "feature")
+	writeFile(t, dir, "feature.txt", "feature\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "feature work")
+	runIn(t, dir, "checkout", "-q", "main")
+	writeFile(t, dir, "main.txt", "main\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "main work")
+	runIn(t, dir, "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+	runIn(t, dir, "tag", "v1")
+
+	graph, err := NewGitExtractor(dir).GetCommitGraph(10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Commits) != 4 {
+		t.Fatalf("expected 4 commits, got %+v", graph.Commits)
+	}
+	merge := graph.Commits[0]
+	if merge.Message != "merge feature" || len(merge.Parents) != 2 {
+		t.Fatalf("merge commit: %+v", merge)
+	}
+	refs := strings.Join(merge.Refs, ",")
+	if !strings.Contains(refs, "HEAD -> main") || !strings.Contains(refs, "tag: v1") {
+		t.Fatalf("refs: %v", merge.Refs)
+	}
+	if root := graph.Commits[3]; root.Message != "initial" || len(root.Parents) != 0 {
+		t.Fatalf("root commit: %+v", root)
+	}
+
+	commitRows, connectors := 0, 0
+	for _, r := range graph.Rows {
+		if r.Commit != "" {
+			commitRows++
+			if !strings.Contains(r.Graph, "*") {
+				t.Fatalf("commit row without a node: %q", r.Graph)
+			}
+		} else {
+			connectors++
+		}
+	}
+	if commitRows != 4 || connectors == 0 {
+		t.Fatalf("rows: %+v", graph.Rows)
+	}
+	if graph.Rows[0].Commit != merge.Hash {
+		t.Fatalf("first row should be the merge: %+v", graph.Rows[0])
+	}
+}
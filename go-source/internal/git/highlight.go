@@ -0,0 +1,97 @@
+package git
+
+import (
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// highlightHunk tokenizes the lines of a hunk with the lexer for path and
+// returns one token slice per line, or nil when the language is unknown.
+// Old and new lines are lexed together, which is close enough for display.
+func highlightHunk(path string, lines []ParsedLine) [][]chroma.Token {
+	lexer := lexers.Match(filepath.Base(path))
+	if lexer == nil || len(lines) == 0 {
+		return nil
+	}
+	var text strings.Builder
+	for _, l := range lines {
+		text.WriteString(l.Content)
+		text.WriteByte('\n')
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, text.String())
+	if err != nil {
+		return nil
+	}
+	split := chroma.SplitTokensIntoLines(it.Tokens())
+	if len(split) != len(lines) {
+		return nil
+	}
+	for i, toks := range split {
+		if n := len(toks); n > 0 {
+			toks[n-1].Value = strings.TrimSuffix(toks[n-1].Value, "\n")
+		}
+		split[i] = toks
+	}
+	return split
+}
+
+// ansiColor maps token categories to basic ANSI foreground colors so the
+// output works on 16-color terminals and leaves room for add/delete
+// backgrounds.
+func ansiColor(t chroma.TokenType) string {
+	switch {
+	case t.InCategory(chroma.Comment):
+		return "90"
+	case t == chroma.KeywordType || t == chroma.NameClass || t == chroma.NameBuiltin:
+		return "36"
+	case t.InCategory(chroma.Keyword):
+		return "35"
+	case t.InSubCategory(chroma.LiteralString):
+		return "33"
+	case t.InSubCategory(chroma.LiteralNumber):
+		return "96"
+	case t == chroma.NameFunction:
+		return "34"
+	default:
+		return ""
+	}
+}
+
+func ansiTokens(tokens []chroma.Token) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		if c := ansiColor(tok.Type); c != "" {
+			b.WriteString("\x1b[" + c + "m" + tok.Value + "\x1b[39m")
+		} else {
+			b.WriteString(tok.Value)
+		}
+	}
+	return b.String()
+}
+
+// htmlTokens renders tokens as spans using chroma's short class names.
+func htmlTokens(tokens []chroma.Token) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		if cls := tokenClass(tok.Type); cls != "" {
+			b.WriteString(`<span class="` + cls + `">` + html.EscapeString(tok.Value) + `</span>`)
+		} else {
+			b.WriteString(html.EscapeString(tok.Value))
+		}
+	}
+	return b.String()
+}
+
+func tokenClass(t chroma.TokenType) string {
+	for t != 0 {
+		if cls, ok := chroma.StandardTypes[t]; ok {
+			return cls
+		}
+		t = t.Parent()
+	}
+	return ""
+}
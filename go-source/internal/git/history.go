@@ -0,0 +1,175 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitPage is one page of commit history. NextCursor continues the same
+// listing even after new commits land on the branch and is empty on the
+// last page.
+type CommitPage struct {
+	Commits    []CommitInfo `json:"commits"`
+	Head       string       `json:"head"`
+	Offset     int          `json:"offset"`
+	Limit      int          `json:"limit"`
+	HasMore    bool         `json:"hasMore"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+// GetCommitHistoryPage returns limit commits starting offset commits below
+// HEAD. A cursor from a previous page replaces offset and pins the listing
+// to the HEAD that page was read from. With maxFiles > 0 each commit lists at
+// most that many files and reports the full count in FileCount.
+func (g *GitExtractor) GetCommitHistoryPage(cursor string, offset, limit, maxFiles int) (CommitPage, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	page := CommitPage{Commits: []CommitInfo{}, Limit: limit}
+	if cursor != "" {
+		head, skip, err := parseHistoryCursor(cursor)
+		if err != nil {
+			return page, err
+		}
+		page.Head, offset = head, skip
+	} else {
+		head, err := g.ResolveRef("HEAD")
+		if err != nil {
+			// No commits yet: an empty first page.
+			return page, nil
+		}
+		page.Head = head
+	}
+	page.Offset = offset
+
+	// One extra commit tells whether another page follows.
+	commits, err := g.logCommits(fmt.Sprintf("--skip=%d", offset), fmt.Sprintf("--max-count=%d", limit+1), page.Head)
+	if err != nil {
+		return page, err
+	}
+	if len(commits) > limit {
+		commits = commits[:limit]
+		page.HasMore = true
+		page.NextCursor = historyCursor(page.Head, offset+limit)
+	}
+	if maxFiles > 0 {
+		for i := range commits {
+			if len(commits[i].Files) > maxFiles {
+				commits[i].FileCount = len(commits[i].Files)
+				commits[i].Files = commits[i].Files[:maxFiles]
+			}
+		}
+	}
+	page.Commits = commits
+	return page, nil
+}
+
+// CommitStats is a commit with the lines it changed in each file. Merge
+// commits list no files.
+type CommitStats struct {
+	Hash    string     `json:"hash"`
+	Parents []string   `json:"parents,omitempty"`
+	Time    time.Time  `json:"time"`
+	Author  string     `json:"author"`
+	Email   string     `json:"email"`
+	Subject string     `json:"subject"`
+	Files   []FileStat `json:"files,omitempty"`
+}
+
+// GetCommitStats lists the commits reachable from include but not from
+// exclude, newest first. Renames count as a deletion and an addition, so a
+// file's statistics follow its path.
+func (g *GitExtractor) GetCommitStats(include, exclude []string) ([]CommitStats, error) {
+	args := []string{"-c", "core.quotePath=false", "log", "--no-renames", "--numstat", "-z",
+		"--format=%x1e%H%x1f%P%x1f%at%x1f%an%x1f%ae%x1f%s"}
+	args = append(args, include...)
+	if len(exclude) > 0 {
+		args = append(append(args, "--not"), exclude...)
+	}
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]CommitStats, 0)
+	for _, block := range strings.Split(out, "\x1e") {
+		header, stats, _ := strings.Cut(block, "\x00")
+		parts := strings.Split(header, "\x1f")
+		if len(parts) < 6 {
+			continue
+		}
+		secs, _ := strconv.ParseInt(parts[2], 10, 64)
+		commits = append(commits, CommitStats{
+			Hash:    parts[0],
+			Parents: strings.Fields(parts[1]),
+			Time:    time.Unix(secs, 0).UTC(),
+			Author:  parts[3],
+			Email:   parts[4],
+			Subject: parts[5],
+			Files:   g.parser.ParseNumstat(stats),
+		})
+	}
+	return commits, nil
+}
+
+// HasCommitsOutside reports whether some commit reachable from from is not
+// reachable from tips, as after a rebase, a force push or a deleted branch.
+func (g *GitExtractor) HasCommitsOutside(from, tips []string) (bool, error) {
+	args := append(append([]string{"rev-list", "--max-count=1"}, from...), "--not")
+	out, err := g.run(append(args, tips...)...)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// GetRefTips returns the distinct commits HEAD, the local branches and the
+// remote-tracking branches point at.
+func (g *GitExtractor) GetRefTips() ([]string, error) {
+	out, err := g.run("for-each-ref", "--format=%(objectname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	tips := strings.Fields(out)
+	if head, err := g.ResolveRef("HEAD"); err == nil {
+		tips = append(tips, head)
+	}
+	seen := map[string]bool{}
+	unique := tips[:0]
+	for _, t := range tips {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	return unique, nil
+}
+
+func historyCursor(head string, offset int) string {
+	return head + "." + strconv.Itoa(offset)
+}
+
+func parseHistoryCursor(cursor string) (string, int, error) {
+	head, skip, ok := strings.Cut(cursor, ".")
+	offset, err := strconv.Atoi(skip)
+	if !ok || err != nil || offset < 0 || !isHexHash(head) {
+		return "", 0, fmt.Errorf("invalid history cursor %q", cursor)
+	}
+	return head, offset, nil
+}
+
+func isHexHash(s string) bool {
+	if len(s) < 7 || len(s) > 64 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
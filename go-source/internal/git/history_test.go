@@ -0,0 +1,117 @@
+package git
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetCommitHistoryPage(t *testing.T) {
+	dir := initTempRepo(t)
+	for i := 1; i <= 4; i++ {
+		writeFile(t, dir, fmt.Sprintf("f%d.txt", i), "x\n")
+		writeFile(t, dir, fmt.Sprintf("g%d.txt", i), "y\n")
+		runIn(t, dir, "add", ".")
+		runIn(t, dir, "commit", "-q", "-m", fmt.Sprintf("commit %d", i))
+	}
+	g := NewGitExtractor(dir)
+
+	first, err := g.GetCommitHistoryPage("", 0, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Commits) != 2 || !first.HasMore || first.NextCursor == "" {
+		t.Fatalf("first page: %+v", first)
+	}
+	if c := first.Commits[0]; c.Message != "commit 4" || len(c.Files) != 1 || c.FileCount != 2 {
+		t.Fatalf("newest commit: %+v", c)
+	}
+	if c := first.Commits[0]; c.Additions != 2 || c.Size != "XS" {
+		t.Fatalf("line counts must cover every file: %+v", c)
+	}
+
+	// New commits must not shift the pages that follow a cursor.
+	writeFile(t, dir, "late.txt", "z\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "late")
+
+	second, err := g.GetCommitHistoryPage(first.NextCursor, 0, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Commits[0].Message != "commit 2" || second.Offset != 2 || !second.HasMore {
+		t.Fatalf("second page: %+v", second)
+	}
+	last, err := g.GetCommitHistoryPage(second.NextCursor, 0, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(last.Commits) != 1 || last.Commits[0].Message != "initial" || last.HasMore || last.NextCursor != "" {
+		t.Fatalf("last page: %+v", last)
+	}
+
+	byOffset, err := g.GetCommitHistoryPage("", 1, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byOffset.Commits[0].Message != "commit 4" {
+		t.Fatalf("offset page: %+v", byOffset)
+	}
+
+	if _, err := g.GetCommitHistoryPage("not-a-cursor", 0, 2, 0); err == nil {
+		t.Fatal("expected an error for a malformed cursor")
+	}
+}
+
+func TestGetCommitHistoryPageCountsMerges(t *testing.T) {
+	dir := initTempRepo(t)
+	runIn(t, dir, "checkout", "-q", "-b", "feature")
+	writeFile(t, dir, "feature.txt", "feature\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "feature work")
+	runIn(t, dir, "checkout", "-q", "-")
+	writeFile(t, dir, "main.txt", "main\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "main work")
+	runIn(t, dir, "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+
+	page, err := NewGitExtractor(dir).GetCommitHistoryPage("", 0, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Commits) != 2 || !page.HasMore {
+		t.Fatalf("page: %+v", page)
+	}
+	if merge := page.Commits[0]; merge.Message != "merge feature" || len(merge.Files) != 0 {
+		t.Fatalf("merge commit: %+v", merge)
+	}
+}
+
+func TestGetCommitStats(t *testing.T) {
+	dir := initTempRepo(t)
+	first, _ := NewGitExtractor(dir).ResolveRef("HEAD")
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, dir, "docs/ünïcode.md", "# Hi\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add main")
+	g := NewGitExtractor(dir)
+
+	all, err := g.GetCommitStats([]string{"HEAD"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Subject != "add main" || all[1].Hash != first {
+		t.Fatalf("unexpected commits: %+v", all)
+	}
+	c := all[0]
+	if c.Author != "Test" || c.Email != "test@example.com" || c.Time.IsZero() || len(c.Parents) != 1 || c.Parents[0] != first {
+		t.Fatalf("unexpected metadata: %+v", c)
+	}
+	if len(c.Files) != 2 || c.Files[0].Path != "docs/ünïcode.md" || c.Files[1].Path != "main.go" || c.Files[1].Additions != 2 {
+		t.Fatalf("unexpected files: %+v", c.Files)
+	}
+
+	since, err := g.GetCommitStats([]string{"HEAD"}, []string{first})
+	if err != nil || len(since) != 1 || since[0].Hash != c.Hash {
+		t.Fatalf("expected only the new commit, got %+v, %v", since, err)
+	}
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HookMarker identifies hooks written by DiffLearn, so uninstalling never
+// removes a hook someone else wrote.
+const HookMarker = "# difflearn-hook"
+
+const hookBackupSuffix = ".difflearn-backup"
+
+// HooksDir returns the directory git runs hooks from, honoring
+// core.hooksPath.
+func (g *GitExtractor) HooksDir() (string, error) {
+	out, err := g.runGit("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(out)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.repoPath, dir)
+	}
+	return dir, nil
+}
+
+// InstallHook writes script as the named hook. An existing hook that was not
+// written by DiffLearn is kept as <name>.difflearn-backup when force is set
+// and is otherwise an error.
+func (g *GitExtractor) InstallHook(name, script string, force bool) (string, error) {
+	dir, err := g.HooksDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), HookMarker) {
+		if !force {
+			return "", fmt.Errorf("%s already exists and was not installed by DiffLearn (use --force to back it up and replace it)", path)
+		}
+		if err := os.Rename(path, path+hookBackupSuffix); err != nil {
+			return "", err
+		}
+	}
+	if !strings.Contains(script, HookMarker) {
+		return "", fmt.Errorf("hook script must contain %q", HookMarker)
+	}
+	return path, os.WriteFile(path, []byte(script), 0o755)
+}
+
+// UninstallHook removes the named hook if DiffLearn wrote it and restores a
+// backed-up hook. It reports whether anything was removed.
+func (g *GitExtractor) UninstallHook(name string) (bool, error) {
+	dir, err := g.HooksDir()
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(dir, name)
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !strings.Contains(string(existing), HookMarker)) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := os.Remove(path); err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path + hookBackupSuffix); err == nil {
+		if err := os.Rename(path+hookBackupSuffix, path); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IssueGroup is the commits in a range that reference one issue.
+type IssueGroup struct {
+	// Issue is "#12", "owner/repo#12" or a tracker key such as "PROJ-123".
+	Issue   string       `json:"issue"`
+	Commits []CommitInfo `json:"commits"`
+}
+
+var (
+	issueURLRe   = regexp.MustCompile(`https?://[^\s/]+/([\w.-]+)/([\w.-]+)/(?:-/)?issues/(\d+)`)
+	hashRefRe    = regexp.MustCompile(`(?:^|[^\w&/])((?:[\w.-]+/[\w.-]+)?#\d+)\b`)
+	ghRefRe      = regexp.MustCompile(`\bGH-(\d+)\b`)
+	trackerKeyRe = regexp.MustCompile(`\b([A-Z][A-Z0-9_]+-\d+)\b`)
+)
+
+// notIssueKeys are uppercase prefixes that look like tracker keys in commit
+// messages but name standards and encodings.
+var notIssueKeys = map[string]bool{
+	"UTF": true, "SHA": true, "ISO": true, "RFC": true, "CVE": true, "GH": true,
+	"AES": true, "MD": true, "HTTP": true, "TLS": true, "SSL": true, "PEP": true,
+}
+
+// ParseIssueRefs finds the issues a commit message refers to: #12,
+// owner/repo#12, GH-12, forge issue URLs and tracker keys such as PROJ-123,
+// in order of first mention and without duplicates.
+func ParseIssueRefs(message string) []string {
+	type hit struct {
+		pos int
+		ref string
+	}
+	var hits []hit
+	for _, m := range issueURLRe.FindAllStringSubmatchIndex(message, -1) {
+		hits = append(hits, hit{m[0], message[m[2]:m[3]] + "/" + message[m[4]:m[5]] + "#" + message[m[6]:m[7]]})
+	}
+	// Blank out URLs so their path segments are not read again below.
+	rest := issueURLRe.ReplaceAllStringFunc(message, func(s string) string { return strings.Repeat(" ", len(s)) })
+	for _, m := range hashRefRe.FindAllStringSubmatchIndex(rest, -1) {
+		hits = append(hits, hit{m[2], rest[m[2]:m[3]]})
+	}
+	for _, m := range ghRefRe.FindAllStringSubmatchIndex(rest, -1) {
+		hits = append(hits, hit{m[0], "#" + rest[m[2]:m[3]]})
+	}
+	for _, m := range trackerKeyRe.FindAllStringSubmatchIndex(rest, -1) {
+		key := rest[m[2]:m[3]]
+		if !notIssueKeys[key[:strings.IndexByte(key, '-')]] {
+			hits = append(hits, hit{m[2], key})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].pos < hits[j].pos })
+	refs := make([]string, 0, len(hits))
+	seen := map[string]bool{}
+	for _, h := range hits {
+		if !seen[h.ref] {
+			seen[h.ref] = true
+			refs = append(refs, h.ref)
+		}
+	}
+	return refs
+}
+
+// GroupCommitsByIssue groups commits by the issues their subject and body
+// reference, ordered by how many commits each issue has and then by first
+// appearance. A commit referencing two issues is in both groups; commits
+// referencing none are returned separately.
+func GroupCommitsByIssue(commits []CommitInfo) (groups []IssueGroup, unlinked []CommitInfo) {
+	index := map[string]int{}
+	for _, c := range commits {
+		refs := ParseIssueRefs(c.Message + "\n" + c.Body)
+		if len(refs) == 0 {
+			unlinked = append(unlinked, c)
+			continue
+		}
+		for _, ref := range refs {
+			i, ok := index[ref]
+			if !ok {
+				i = len(groups)
+				index[ref] = i
+				groups = append(groups, IssueGroup{Issue: ref})
+			}
+			groups[i].Commits = append(groups[i].Commits, c)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i].Commits) > len(groups[j].Commits) })
+	return groups, unlinked
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIssueRefs(t *testing.T) {
+	cases := map[string][]string{
+		"Fix crash on empty diff (#12)":                            {"#12"},
+		"Closes acme/api#7 and GH-9":                               {"acme/api#7", "#9"},
+		"PROJ-123: support UTF-8 names, see SHA-256 notes":         {"PROJ-123"},
+		"See https://github.com/acme/api/issues/44 and ENG-3, #44": {"acme/api#44", "ENG-3", "#44"},
+		"Use https://gitlab.com/g/p/-/issues/5":                    {"g/p#5"},
+		"Bump color to #fff and entity &#123;":                     {},
+		"Refactor parser":                                          {},
+	}
+	for msg, want := range cases {
+		if got := ParseIssueRefs(msg); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseIssueRefs(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestGroupCommitsByIssue(t *testing.T) {
+	commits := []CommitInfo{
+		{Hash: "c3", Message: "Polish export (#2)"},
+		{Hash: "c2", Message: "Add CSV export", Body: "Refs #2\nAlso fixes PROJ-9"},
+		{Hash: "c1", Message: "Fix typo in PROJ-9 handler"},
+		{Hash: "c0", Message: "Tidy imports"},
+	}
+	groups, unlinked := GroupCommitsByIssue(commits)
+	if len(groups) != 2 || groups[0].Issue != "#2" || groups[1].Issue != "PROJ-9" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	if len(groups[0].Commits) != 2 || groups[1].Commits[0].Hash != "c2" || groups[1].Commits[1].Hash != "c1" {
+		t.Fatalf("unexpected group commits: %+v", groups)
+	}
+	if len(unlinked) != 1 || unlinked[0].Hash != "c0" {
+		t.Fatalf("unexpected unlinked commits: %+v", unlinked)
+	}
+}
+
+func TestGetCommitsWithBodies(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "a.go", "package main\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "Add a", "-m", "Fixes #4\n\nWith a second paragraph.")
+	commits, err := NewGitExtractor(dir).GetCommitsWithBodies("HEAD~1..HEAD")
+	if err != nil || len(commits) != 1 {
+		t.Fatalf("GetCommitsWithBodies = %+v, %v", commits, err)
+	}
+	if commits[0].Message != "Add a" || commits[0].Body != "Fixes #4\n\nWith a second paragraph." {
+		t.Fatalf("unexpected commit: %+v", commits[0])
+	}
+}
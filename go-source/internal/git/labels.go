@@ -0,0 +1,92 @@
+package git
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// CommitSizes are the size labels of CommitSize, smallest first.
+var CommitSizes = []string{"XS", "S", "M", "L", "XL"}
+
+// commitSizeLimits are the most lines changed each size label allows; XL
+// has no limit.
+var commitSizeLimits = []int{10, 50, 250, 1000}
+
+// CommitSize labels a commit by the lines it added and deleted: XS up to
+// 10, S up to 50, M up to 250, L up to 1000 and XL beyond.
+func CommitSize(additions, deletions int) string {
+	lines := additions + deletions
+	for i, limit := range commitSizeLimits {
+		if lines <= limit {
+			return CommitSizes[i]
+		}
+	}
+	return CommitSizes[len(CommitSizes)-1]
+}
+
+// Message words hinting at a commit type, checked in this order so "fix
+// the failing test" is a fix rather than a test.
+var commitTypeHints = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"fix", regexp.MustCompile(`(?i)\b(fix(es|ed)?|bug|crash|regression|resolve[sd]?|hotfix|patch(es|ed)?)\b`)},
+	{"refactor", regexp.MustCompile(`(?i)\b(refactor\w*|clean ?up|simplif(y|ies|ied)|rename[sd]?|extract(s|ed)?|restructure[sd]?|tidy)\b`)},
+	{"test", regexp.MustCompile(`(?i)\b(tests?|specs?|coverage)\b`)},
+	{"docs", regexp.MustCompile(`(?i)\b(docs?|documentation|readme|typos?|comments?)\b`)},
+	{"feat", regexp.MustCompile(`(?i)\b(add(s|ed)?|implement(s|ed)?|introduce[sd]?|support(s|ed)?|new|allow(s|ed)?|enable[sd]?)\b`)},
+}
+
+// GuessCommitType says what kind of change a commit is: the type of a
+// Conventional Commit subject, or else a guess from its files and message
+// among feat, fix, refactor, test and docs. It returns "" when nothing
+// points anywhere.
+func GuessCommitType(subject string, files []string) string {
+	if cc := ParseConventionalCommit(subject, ""); cc.Type != "other" {
+		return cc.Type
+	}
+	if len(files) > 0 {
+		tests, docs := 0, 0
+		for _, f := range files {
+			switch {
+			case isTestPath(f):
+				tests++
+			case isDocPath(f):
+				docs++
+			}
+		}
+		switch {
+		case tests == len(files):
+			return "test"
+		case docs == len(files):
+			return "docs"
+		}
+	}
+	for _, h := range commitTypeHints {
+		if h.re.MatchString(subject) {
+			return h.kind
+		}
+	}
+	return ""
+}
+
+func isTestPath(file string) bool {
+	base := path.Base(file)
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.Contains("/"+file, "/testdata/") || strings.Contains("/"+file, "/__tests__/")
+}
+
+func isDocPath(file string) bool {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".md", ".mdx", ".rst", ".adoc", ".txt":
+		return true
+	}
+	return strings.HasPrefix(file, "docs/") || strings.HasPrefix(file, "doc/")
+}
+
+// labelCommit fills in the size and type of c.
+func labelCommit(c *CommitInfo) {
+	c.Size = CommitSize(c.Additions, c.Deletions)
+	c.Type = GuessCommitType(c.Message, c.Files)
+}
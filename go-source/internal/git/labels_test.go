@@ -0,0 +1,51 @@
+package git
+
+import "testing"
+
+func TestCommitSize(t *testing.T) {
+	for _, c := range []struct {
+		adds, dels int
+		want       string
+	}{{0, 0, "XS"}, {6, 4, "XS"}, {30, 21, "M"}, {50, 0, "S"}, {200, 50, "M"}, {900, 101, "XL"}, {1000, 0, "L"}} {
+		if got := CommitSize(c.adds, c.dels); got != c.want {
+			t.Errorf("CommitSize(%d, %d) = %s, want %s", c.adds, c.dels, got, c.want)
+		}
+	}
+}
+
+func TestGuessCommitType(t *testing.T) {
+	cases := []struct {
+		subject string
+		files   []string
+		want    string
+	}{
+		{"perf(git): pool commands", []string{"git/pool.go"}, "perf"},
+		{"Fix the failing test", []string{"a_test.go"}, "test"},
+		{"Fix crash when the repo is empty", []string{"main.go"}, "fix"},
+		{"Update wording", []string{"README.md", "docs/setup.md"}, "docs"},
+		{"Simplify the parser", []string{"parser.go"}, "refactor"},
+		{"Add dark mode", []string{"ui.css", "ui.js"}, "feat"},
+		{"More coverage for the parser", []string{"parser.go", "parser_test.go"}, "test"},
+		{"WIP", []string{"main.go"}, ""},
+	}
+	for _, c := range cases {
+		if got := GuessCommitType(c.subject, c.files); got != c.want {
+			t.Errorf("GuessCommitType(%q) = %q, want %q", c.subject, got, c.want)
+		}
+	}
+}
+
+func TestNumstatPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"a/b.go":                "a/b.go",
+		"old.go => new.go":      "new.go",
+		"src/{old => new}/x.go": "src/new/x.go",
+		"src/{ => sub}/x.go":    "src/sub/x.go",
+		"src/{sub => }/x.go":    "src/x.go",
+		"{a.txt => docs/a.txt}": "docs/a.txt",
+	} {
+		if got := numstatPath(in); got != want {
+			t.Errorf("numstatPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
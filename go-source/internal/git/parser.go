@@ -49,22 +49,63 @@ func (p *DiffParser) parseFileDiff(fileDiff string) (ParsedDiff, bool) {
 		return ParsedDiff{}, false
 	}
 
-	headerRe := regexp.MustCompile(`^diff --git a/(.+?) b/(.+)$`)
-	hm := headerRe.FindStringSubmatch(lines[0])
-	if len(hm) != 3 {
+	oldFile, newFile, ok := parseGitHeader(lines[0])
+	if !ok {
 		return ParsedDiff{}, false
 	}
 
-	oldFile, newFile := hm[1], hm[2]
-	isBinary := strings.Contains(fileDiff, "Binary files")
-	isNew := strings.Contains(fileDiff, "new file mode")
-	isDeleted := strings.Contains(fileDiff, "deleted file mode")
-	isRenamed := strings.Contains(fileDiff, "rename from") || oldFile != newFile
+	// Metadata is only read from the extended header, before the first hunk,
+	// so content lines can never be mistaken for it.
+	var isBinary, isNew, isDeleted, isCopied, renameSeen bool
+	var oldMode, newMode, oldBlob, newBlob string
+	similarity := 0
+	for _, line := range lines[1:] {
+		if hunkRe.MatchString(line) {
+			break
+		}
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "new file mode "):
+			isNew, newMode = true, strings.TrimPrefix(line, "new file mode ")
+		case strings.HasPrefix(line, "deleted file mode "):
+			isDeleted, oldMode = true, strings.TrimPrefix(line, "deleted file mode ")
+		case strings.HasPrefix(line, "old mode "):
+			oldMode = strings.TrimPrefix(line, "old mode ")
+		case strings.HasPrefix(line, "new mode "):
+			newMode = strings.TrimPrefix(line, "new mode ")
+		case strings.HasPrefix(line, "rename from "):
+			renameSeen, oldFile = true, unquotePath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			renameSeen, newFile = true, unquotePath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy from "):
+			isCopied, oldFile = true, unquotePath(strings.TrimPrefix(line, "copy from "))
+		case strings.HasPrefix(line, "copy to "):
+			isCopied, newFile = true, unquotePath(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "similarity index "):
+			if m := similarityRe.FindStringSubmatch(line); m != nil {
+				similarity, _ = strconv.Atoi(m[1])
+			}
+		case strings.HasPrefix(line, "index "):
+			if m := indexRe.FindStringSubmatch(line); m != nil {
+				oldBlob, newBlob = nonZeroOID(m[1]), nonZeroOID(m[2])
+			}
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			isBinary = true
+		case strings.HasPrefix(line, "--- "):
+			if path, ok := strings.CutPrefix(unquotePath(strings.TrimSuffix(strings.TrimPrefix(line, "--- "), "\t")), "a/"); ok {
+				oldFile = path
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if path, ok := strings.CutPrefix(unquotePath(strings.TrimSuffix(strings.TrimPrefix(line, "+++ "), "\t")), "b/"); ok {
+				newFile = path
+			}
+		}
+	}
+	isRenamed := !isCopied && (renameSeen || oldFile != newFile)
 
 	hunks := make([]ParsedHunk, 0)
 	var current *ParsedHunk
 	oldLineNum, newLineNum := 0, 0
-	hunkRe := regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
 
 	for _, line := range lines {
 		if m := hunkRe.FindStringSubmatch(line); len(m) > 0 {
@@ -98,11 +139,11 @@ func (p *DiffParser) parseFileDiff(fileDiff string) (ParsedDiff, bool) {
 		}
 
 		switch {
-		case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+		case strings.HasPrefix(line, "+"):
 			n := newLineNum
 			current.Lines = append(current.Lines, ParsedLine{Type: LineAdd, Content: strings.TrimPrefix(line, "+"), NewLineNumber: &n})
 			newLineNum++
-		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "-"):
 			n := oldLineNum
 			current.Lines = append(current.Lines, ParsedLine{Type: LineDelete, Content: strings.TrimPrefix(line, "-"), OldLineNumber: &n})
 			oldLineNum++
@@ -116,6 +157,11 @@ func (p *DiffParser) parseFileDiff(fileDiff string) (ParsedDiff, bool) {
 	if current != nil {
 		hunks = append(hunks, *current)
 	}
+	submodule := parseSubmoduleChange(hunks)
+	var binary *BinaryInfo
+	if isBinary {
+		binary = &BinaryInfo{OldBlob: oldBlob, NewBlob: newBlob}
+	}
 
 	adds, dels := 0, 0
 	for _, h := range hunks {
@@ -130,18 +176,130 @@ func (p *DiffParser) parseFileDiff(fileDiff string) (ParsedDiff, bool) {
 	}
 
 	return ParsedDiff{
-		OldFile:   oldFile,
-		NewFile:   newFile,
-		Hunks:     hunks,
-		IsBinary:  isBinary,
-		IsNew:     isNew,
-		IsDeleted: isDeleted,
-		IsRenamed: isRenamed,
-		Additions: adds,
-		Deletions: dels,
+		OldFile:    oldFile,
+		NewFile:    newFile,
+		Hunks:      hunks,
+		IsBinary:   isBinary,
+		IsNew:      isNew,
+		IsDeleted:  isDeleted,
+		IsRenamed:  isRenamed,
+		IsCopied:   isCopied,
+		Similarity: similarity,
+		OldMode:    oldMode,
+		NewMode:    newMode,
+		Additions:  adds,
+		Deletions:  dels,
+		Submodule:  submodule,
+		Binary:     binary,
 	}, true
 }
 
+var (
+	hunkRe       = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+	similarityRe = regexp.MustCompile(`^similarity index (\d+)%$`)
+	indexRe      = regexp.MustCompile(`^index ([0-9a-f]+)\.\.([0-9a-f]+)`)
+)
+
+// parseGitHeader extracts both paths from a "diff --git" line. Paths may be
+// C-quoted, and unquoted paths may contain spaces (and even " b/"), so for
+// the common case of identical paths the line is split in the middle.
+func parseGitHeader(line string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSuffix(line, "\r"), "diff --git ")
+	if !ok {
+		return "", "", false
+	}
+	if strings.HasPrefix(rest, "\"") {
+		oldPath, tail, ok := cutQuoted(rest)
+		if !ok {
+			return "", "", false
+		}
+		newPath := unquotePath(strings.TrimPrefix(tail, " "))
+		return strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(newPath, "b/"), true
+	}
+	if n := len(rest); n >= 5 && n%2 == 1 {
+		half := (n - 1) / 2
+		if rest[half] == ' ' && strings.HasPrefix(rest, "a/") && rest[half+1:half+3] == "b/" && rest[2:half] == rest[half+3:] {
+			return rest[2:half], rest[half+3:], true
+		}
+	}
+	for i := strings.Index(rest, " \""); i > 0; {
+		if newPath, err := strconv.Unquote(rest[i+1:]); err == nil {
+			return strings.TrimPrefix(rest[:i], "a/"), strings.TrimPrefix(newPath, "b/"), true
+		}
+		next := strings.Index(rest[i+1:], " \"")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	oldPath, newPath, ok := strings.Cut(rest, " b/")
+	if !ok || !strings.HasPrefix(oldPath, "a/") {
+		return "", "", false
+	}
+	return oldPath[2:], newPath, true
+}
+
+// cutQuoted splits a leading C-quoted string off s.
+func cutQuoted(s string) (string, string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			v, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", false
+			}
+			return v, s[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// unquotePath undoes git's C-style quoting of unusual file names.
+func unquotePath(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if v, err := strconv.Unquote(s); err == nil {
+			return v
+		}
+	}
+	return s
+}
+
+func nonZeroOID(oid string) string {
+	if strings.Trim(oid, "0") == "" {
+		return ""
+	}
+	return oid
+}
+
+var subprojectRe = regexp.MustCompile(`^Subproject commit ([0-9a-f]+)(-dirty)?$`)
+
+// parseSubmoduleChange recognizes the "Subproject commit" lines git emits for
+// gitlink entries.
+func parseSubmoduleChange(hunks []ParsedHunk) *SubmoduleChange {
+	var change *SubmoduleChange
+	for _, h := range hunks {
+		for _, l := range h.Lines {
+			m := subprojectRe.FindStringSubmatch(l.Content)
+			if m == nil {
+				return nil
+			}
+			if change == nil {
+				change = &SubmoduleChange{}
+			}
+			switch l.Type {
+			case LineDelete:
+				change.OldCommit = m[1]
+			case LineAdd:
+				change.NewCommit = m[1]
+				change.Dirty = m[2] != ""
+			}
+		}
+	}
+	return change
+}
+
 func (p *DiffParser) GetStats(diffs []ParsedDiff) DiffStats {
 	stats := DiffStats{Files: len(diffs)}
 	for _, d := range diffs {
@@ -150,3 +308,61 @@ func (p *DiffParser) GetStats(diffs []ParsedDiff) DiffStats {
 	}
 	return stats
 }
+
+// ParseNumstat parses `git diff --numstat -z` output. Renamed entries carry an
+// empty path field followed by the old and new paths as separate records.
+func (p *DiffParser) ParseNumstat(raw string) []FileStat {
+	stats := make([]FileStat, 0)
+	fields := strings.Split(raw, "\x00")
+	for i := 0; i < len(fields); i++ {
+		record := strings.TrimLeft(fields[i], "\n")
+		if record == "" {
+			continue
+		}
+		parts := strings.SplitN(record, "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		stat := FileStat{Path: parts[2]}
+		if parts[0] == "-" && parts[1] == "-" {
+			stat.IsBinary = true
+		} else {
+			stat.Additions, _ = strconv.Atoi(parts[0])
+			stat.Deletions, _ = strconv.Atoi(parts[1])
+		}
+		if stat.Path == "" && i+2 < len(fields) {
+			stat.OldPath = fields[i+1]
+			stat.Path = fields[i+2]
+			i += 2
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// ParseLogPatches parses `git log -p` output whose pretty format starts each
+// commit with a record separator followed by unit-separated hash, date,
+// subject and author.
+func (p *DiffParser) ParseLogPatches(raw string) []FileRevision {
+	revisions := make([]FileRevision, 0)
+	for _, block := range strings.Split(raw, "\x1e") {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		header, patch, _ := strings.Cut(block, "\n")
+		parts := strings.Split(header, "\x1f")
+		if len(parts) < 4 {
+			continue
+		}
+		diffs := p.Parse(patch)
+		files := make([]string, 0, len(diffs))
+		for _, d := range diffs {
+			files = append(files, d.NewFile)
+		}
+		revisions = append(revisions, FileRevision{
+			Commit: CommitInfo{Hash: parts[0], Date: parts[1], Message: parts[2], Author: parts[3], Files: files},
+			Diffs:  diffs,
+		})
+	}
+	return revisions
+}
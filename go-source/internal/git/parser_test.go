@@ -1,6 +1,12 @@
 package git
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestParseSingleFileDiff(t *testing.T) {
 	raw := `diff --git a/main.go b/main.go
@@ -62,3 +68,218 @@ func TestGetStats(t *testing.T) {
 	}
 }
 
+func TestParseNumstat(t *testing.T) {
+	raw := "3\t1\tmain.go\x00-\t-\tlogo.png\x002\t0\t\x00old.go\x00new.go\x00"
+
+	stats := NewDiffParser().ParseNumstat(raw)
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 stats, got %d", len(stats))
+	}
+	if stats[0].Path != "main.go" || stats[0].Additions != 3 || stats[0].Deletions != 1 {
+		t.Fatalf("unexpected first stat: %+v", stats[0])
+	}
+	if !stats[1].IsBinary {
+		t.Fatalf("expected binary stat: %+v", stats[1])
+	}
+	if stats[2].OldPath != "old.go" || stats[2].Path != "new.go" {
+		t.Fatalf("expected rename stat: %+v", stats[2])
+	}
+}
+
+func TestParseLogPatches(t *testing.T) {
+	raw := "\x1eabc123\x1f2024-01-02T00:00:00Z\x1fsecond\x1fAda\n\ndiff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n" +
+		"\x1edef456\x1f2024-01-01T00:00:00Z\x1ffirst\x1fAda\n\ndiff --git a/main.go b/main.go\nnew file mode 100644\n--- /dev/null\n+++ b/main.go\n@@ -0,0 +1 @@\n+old\n"
+
+	revisions := NewDiffParser().ParseLogPatches(raw)
+	if len(revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(revisions))
+	}
+	if revisions[0].Commit.Hash != "abc123" || revisions[0].Commit.Message != "second" {
+		t.Fatalf("unexpected commit metadata: %+v", revisions[0].Commit)
+	}
+	if len(revisions[1].Diffs) != 1 || !revisions[1].Diffs[0].IsNew {
+		t.Fatalf("expected new file diff in first revision: %+v", revisions[1].Diffs)
+	}
+}
+
+func TestParseSubmodulePointer(t *testing.T) {
+	raw := "diff --git a/vendor/lib b/vendor/lib\nindex 1111111..2222222 160000\n--- a/vendor/lib\n+++ b/vendor/lib\n@@ -1 +1 @@\n-Subproject commit 1111111111111111111111111111111111111111\n+Subproject commit 2222222222222222222222222222222222222222-dirty\n"
+	diffs := NewDiffParser().Parse(raw)
+	if len(diffs) != 1 || diffs[0].Submodule == nil {
+		t.Fatalf("expected submodule change, got %+v", diffs)
+	}
+	s := diffs[0].Submodule
+	if !strings.HasPrefix(s.OldCommit, "1111") || !strings.HasPrefix(s.NewCommit, "2222") || !s.Dirty {
+		t.Fatalf("unexpected submodule change: %+v", s)
+	}
+	if got := NewDiffFormatter().ToSummary(diffs); !strings.Contains(got, "S vendor/lib 1111111 → 2222222 (dirty)") {
+		t.Fatalf("unexpected summary: %s", got)
+	}
+}
+
+func TestParseCopyAndRenameHeaders(t *testing.T) {
+	raw := "diff --git a/util.go b/util_copy.go\nsimilarity index 92%\ncopy from util.go\ncopy to util_copy.go\n--- a/util.go\n+++ b/util_copy.go\n@@ -1 +1 @@\n-package util\n+package utilcopy\n" +
+		"diff --git a/old.go b/new.go\nsimilarity index 100%\nrename from old.go\nrename to new.go\n"
+	diffs := NewDiffParser().Parse(raw)
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d", len(diffs))
+	}
+	if !diffs[0].IsCopied || diffs[0].IsRenamed || diffs[0].Similarity != 92 {
+		t.Fatalf("unexpected copy diff: %+v", diffs[0])
+	}
+	if !diffs[1].IsRenamed || diffs[1].IsCopied || diffs[1].Similarity != 100 {
+		t.Fatalf("unexpected rename diff: %+v", diffs[1])
+	}
+}
+
+func TestSyntheticDiffParses(t *testing.T) {
+	diffs := NewDiffParser().Parse(SyntheticDiff(3, 2, 4))
+	if len(diffs) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(diffs))
+	}
+	if diffs[0].Additions != 4 || diffs[0].Deletions != 4 || len(diffs[0].Hunks) != 2 {
+		t.Fatalf("unexpected synthetic file: +%d -%d, %d hunks", diffs[0].Additions, diffs[0].Deletions, len(diffs[0].Hunks))
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	raw := SyntheticDiff(200, 4, 40)
+	p := NewDiffParser()
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(raw)
+	}
+}
+
+func readCorpus(t testing.TB) map[string]string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "diffs", "*.diff"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no diff corpus found: %v", err)
+	}
+	corpus := make(map[string]string, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		corpus[filepath.Base(path)] = string(b)
+	}
+	return corpus
+}
+
+func TestParseCorpus(t *testing.T) {
+	corpus := readCorpus(t)
+	p := NewDiffParser()
+
+	cases := map[string]func(t *testing.T, diffs []ParsedDiff){
+		"rename_with_spaces.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 2 || !diffs[0].IsRenamed || diffs[0].OldFile != "sp ace.txt" || diffs[0].NewFile != "new b/sp ace.txt" || diffs[0].Similarity != 91 {
+				t.Fatalf("unexpected rename: %+v", diffs[0])
+			}
+			if diffs[1].IsRenamed || diffs[1].NewFile != "new b/sp ace.txt" || diffs[1].Additions != 1 {
+				t.Fatalf("spaced path with \" b/\" parsed wrongly: %+v", diffs[1])
+			}
+		},
+		"quoted_path.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 1 || diffs[0].NewFile != "é.txt" || diffs[0].IsRenamed {
+				t.Fatalf("unexpected quoted path diff: %+v", diffs)
+			}
+		},
+		"binary.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 1 || !diffs[0].IsBinary || diffs[0].Binary == nil || diffs[0].Binary.OldBlob != "45a21f1" {
+				t.Fatalf("unexpected binary diff: %+v", diffs)
+			}
+		},
+		"mode_only.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 1 || diffs[0].OldMode != "100644" || diffs[0].NewMode != "100755" || len(diffs[0].Hunks) != 0 {
+				t.Fatalf("unexpected mode change: %+v", diffs)
+			}
+		},
+		"crlf.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 1 || diffs[0].Hunks[0].Lines[1].Content != "w\r" {
+				t.Fatalf("expected carriage returns to be kept in content: %+v", diffs)
+			}
+		},
+		"dash_lines.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 1 || diffs[0].Additions != 2 || diffs[0].Deletions != 2 {
+				t.Fatalf("lines starting with --/++ must count as changes: %+v", diffs)
+			}
+		},
+		"copy.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 1 || !diffs[0].IsCopied || diffs[0].OldFile != "src.txt" {
+				t.Fatalf("unexpected copy: %+v", diffs)
+			}
+		},
+		"new_empty_file.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 1 || !diffs[0].IsNew || diffs[0].IsBinary {
+				t.Fatalf("unexpected empty file: %+v", diffs)
+			}
+		},
+		"no_newline.diff": func(t *testing.T, diffs []ParsedDiff) {
+			if len(diffs) != 1 || diffs[0].Additions != 1 || diffs[0].Deletions != 1 {
+				t.Fatalf("unexpected no-newline diff: %+v", diffs)
+			}
+		},
+	}
+
+	for name, raw := range corpus {
+		t.Run(name, func(t *testing.T) {
+			diffs := p.Parse(raw)
+			if check, ok := cases[name]; ok {
+				check(t, diffs)
+			}
+			again := p.Parse(NewDiffFormatter().ToUnified(diffs))
+			if !reflect.DeepEqual(diffs, again) {
+				t.Fatalf("round trip changed the diff:\nbefore %+v\nafter  %+v", diffs, again)
+			}
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, raw := range readCorpus(f) {
+		f.Add(raw)
+	}
+	p := NewDiffParser()
+	f.Fuzz(func(t *testing.T, raw string) {
+		for _, d := range p.Parse(raw) {
+			adds, dels := 0, 0
+			for _, h := range d.Hunks {
+				for _, l := range h.Lines {
+					switch l.Type {
+					case LineAdd:
+						adds++
+					case LineDelete:
+						dels++
+					}
+				}
+			}
+			if adds != d.Additions || dels != d.Deletions {
+				t.Fatalf("stats +%d -%d do not match lines +%d -%d", d.Additions, d.Deletions, adds, dels)
+			}
+		}
+	})
+}
+
+// FuzzUnifiedRoundTrip checks that re-serializing a parsed diff is stable:
+// parsing the output of ToUnified yields the same diff again.
+func FuzzUnifiedRoundTrip(f *testing.F) {
+	for _, raw := range readCorpus(f) {
+		f.Add(raw)
+	}
+	p := NewDiffParser()
+	formatter := NewDiffFormatter()
+	f.Fuzz(func(t *testing.T, raw string) {
+		first := formatter.ToUnified(p.Parse(raw))
+		reparsed := p.Parse(first)
+		second := formatter.ToUnified(reparsed)
+		if first != second {
+			t.Fatalf("unified output not stable:\nfirst:\n%s\nsecond:\n%s", first, second)
+		}
+		if !reflect.DeepEqual(reparsed, p.Parse(second)) {
+			t.Fatalf("reparsing changed the diff for:\n%s", first)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var patchSubjectRe = regexp.MustCompile(`(?m)^Subject: (?:\[[^\]]*\] )?(.+)$`)
+
+// ParsePatch parses a patch file from outside the repository: git diff or
+// format-patch output (including several patches in one mbox), or a plain
+// unified diff from diff -u or another tool. Text around the diffs, such as
+// mail headers, diffstats and signatures, is dropped.
+func (p *DiffParser) ParsePatch(raw string) []ParsedDiff {
+	return p.Parse(normalizePatch(raw))
+}
+
+// PatchSubjects returns the subjects of the mails in format-patch output,
+// without their [PATCH n/m] prefixes.
+func PatchSubjects(raw string) []string {
+	var subjects []string
+	for _, m := range patchSubjectRe.FindAllStringSubmatch(raw, -1) {
+		subjects = append(subjects, strings.TrimSpace(m[1]))
+	}
+	return subjects
+}
+
+// normalizePatch keeps only file headers and hunks, cutting each hunk at the
+// line counts in its header so trailing text is not read as changes, and
+// gives plain unified diffs the diff --git header Parse expects.
+func normalizePatch(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	var out []string
+	inHeader := false // after diff --git, before the first hunk
+	oldLeft, newLeft := 0, 0
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, " "), line == "":
+				// Some mailers strip the space from blank context lines.
+				oldLeft--
+				newLeft--
+				line = " "
+			case strings.HasPrefix(line, `\`):
+			default:
+				oldLeft, newLeft = 0, 0
+				i--
+				continue
+			}
+			out = append(out, line)
+			continue
+		}
+		if m := hunkRe.FindStringSubmatch(line); m != nil && (inHeader || len(out) > 0) {
+			inHeader = false
+			oldLeft, newLeft = hunkCount(m[2]), hunkCount(m[4])
+			out = append(out, line)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHeader = true
+			out = append(out, line)
+		case inHeader:
+			out = append(out, line)
+		case strings.HasPrefix(line, `\`) && len(out) > 0:
+			out = append(out, line)
+		case strings.HasPrefix(line, "--- ") && i+2 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") && hunkRe.MatchString(lines[i+2]):
+			out = append(out, plainDiffHeader(line, lines[i+1])...)
+			i++
+			inHeader = true
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// plainDiffHeader turns the ---/+++ lines of a non-git diff into a git file
+// header. Without a/ and b/ prefixes the names are usually a backup and the
+// file itself (file.orig, file), so the new name is used for both sides.
+func plainDiffHeader(minus, plus string) []string {
+	oldPath := patchPath(strings.TrimPrefix(minus, "--- "))
+	newPath := patchPath(strings.TrimPrefix(plus, "+++ "))
+	oldPrefixed := strings.HasPrefix(oldPath, "a/") || oldPath == "/dev/null"
+	newPrefixed := strings.HasPrefix(newPath, "b/") || newPath == "/dev/null"
+	if oldPrefixed && newPrefixed {
+		oldPath, newPath = strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(newPath, "b/")
+	} else if oldPath != "/dev/null" && newPath != "/dev/null" {
+		oldPath = newPath
+	}
+	from, to := quotePath("a/"+oldPath), quotePath("b/"+newPath)
+	var mode string
+	switch {
+	case oldPath == "/dev/null":
+		oldPath, from, mode = newPath, "/dev/null", "new file mode 100644"
+	case newPath == "/dev/null":
+		newPath, to, mode = oldPath, "/dev/null", "deleted file mode 100644"
+	}
+	header := []string{"diff --git " + quotePath("a/"+oldPath) + " " + quotePath("b/"+newPath)}
+	if mode != "" {
+		header = append(header, mode)
+	}
+	return append(header, "--- "+from, "+++ "+to)
+}
+
+// patchPath strips the timestamp diff -u appends after a tab.
+func patchPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	return unquotePath(strings.TrimSpace(s))
+}
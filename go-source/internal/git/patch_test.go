@@ -0,0 +1,75 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+const mboxPatch = `From c551a6c3974aeab494aaed3189417dc128a88ede Mon Sep 17 00:00:00 2001
+From: T <t@example.com>
+Subject: [PATCH 1/2] Add b
+
+Fixes #3
+---
+ f.txt | 1 +
+ 1 file changed, 1 insertion(+)
+
+diff --git a/f.txt b/f.txt
+index 7898192..422c2b7 100644
+--- a/f.txt
++++ b/f.txt
+@@ -1 +1,2 @@
+ a
++b
+-- 
+2.39.5
+
+From 0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e Mon Sep 17 00:00:00 2001
+From: T <t@example.com>
+Subject: [PATCH 2/2] Drop old notes
+---
+ notes.txt | 1 -
+ 1 file changed, 1 deletion(-)
+
+diff --git a/notes.txt b/notes.txt
+deleted file mode 100644
+index 1111111..0000000
+--- a/notes.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-old
+-- 
+2.39.5
+`
+
+func TestParsePatchMbox(t *testing.T) {
+	diffs := NewDiffParser().ParsePatch(mboxPatch)
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d: %+v", len(diffs), diffs)
+	}
+	if d := diffs[0]; d.NewFile != "f.txt" || d.Additions != 1 || d.Deletions != 0 {
+		t.Fatalf("signature or mail text leaked into the first diff: %+v", d)
+	}
+	if d := diffs[1]; !d.IsDeleted || d.Deletions != 1 || d.Additions != 0 {
+		t.Fatalf("unexpected second diff: %+v", d)
+	}
+	if got := PatchSubjects(mboxPatch); !reflect.DeepEqual(got, []string{"Add b", "Drop old notes"}) {
+		t.Fatalf("PatchSubjects = %v", got)
+	}
+}
+
+func TestParsePatchPlainUnifiedDiff(t *testing.T) {
+	raw := "Only in new: extra\n" +
+		"--- src/app.py.orig\t2024-01-01 10:00:00\n+++ src/app.py\t2024-01-02 10:00:00\n@@ -1,3 +1,3 @@\n import os\n-x = 1\n+x = 2\n\n" +
+		"--- /dev/null\n+++ b/README\n@@ -0,0 +1 @@\n+hello\n"
+	diffs := NewDiffParser().ParsePatch(raw)
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d: %+v", len(diffs), diffs)
+	}
+	if d := diffs[0]; d.OldFile != "src/app.py" || d.NewFile != "src/app.py" || d.IsRenamed || d.Additions != 1 || d.Deletions != 1 || len(d.Hunks[0].Lines) != 4 {
+		t.Fatalf("unexpected first diff: %+v", d)
+	}
+	if d := diffs[1]; !d.IsNew || d.NewFile != "README" || d.Additions != 1 {
+		t.Fatalf("unexpected second diff: %+v", d)
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"path"
+	"strings"
+)
+
+// FilterDiffsByPath keeps the diffs whose path matches patterns. A pattern
+// is a glob matched against the whole path, or against the file name when
+// it has no slash, and a directory matches everything below it. Patterns
+// starting with ! exclude; with only exclusions, every other file is kept.
+func FilterDiffsByPath(diffs []ParsedDiff, patterns []string) []ParsedDiff {
+	var include, exclude []string
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if rest, ok := strings.CutPrefix(p, "!"); ok {
+			exclude = append(exclude, rest)
+		} else if p != "" {
+			include = append(include, p)
+		}
+	}
+	if len(include) == 0 && len(exclude) == 0 {
+		return diffs
+	}
+	kept := make([]ParsedDiff, 0, len(diffs))
+	for _, d := range diffs {
+		name := d.NewFile
+		if d.IsDeleted {
+			name = d.OldFile
+		}
+		if (len(include) == 0 || matchesAnyPath(name, include)) && !matchesAnyPath(name, exclude) {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+func matchesAnyPath(name string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.TrimPrefix(p, "./"), "/")
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+		target := name
+		if !strings.Contains(p, "/") {
+			target = path.Base(name)
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+		// A glob matching a directory covers the files below it.
+		for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if ok, _ := path.Match(p, dir); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterDiffsByPath(t *testing.T) {
+	diffs := []ParsedDiff{
+		{NewFile: "cmd/app/main.go"},
+		{NewFile: "internal/api/server.go"},
+		{NewFile: "internal/api/server_test.go"},
+		{NewFile: "web/dist/app.min.js"},
+		{OldFile: "docs/old.md", NewFile: "/dev/null", IsDeleted: true},
+	}
+	names := func(ds []ParsedDiff) []string {
+		out := []string{}
+		for _, d := range ds {
+			if d.IsDeleted {
+				out = append(out, d.OldFile)
+			} else {
+				out = append(out, d.NewFile)
+			}
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		patterns []string
+		want     []string
+	}{
+		{nil, []string{"cmd/app/main.go", "internal/api/server.go", "internal/api/server_test.go", "web/dist/app.min.js", "docs/old.md"}},
+		{[]string{"internal/"}, []string{"internal/api/server.go", "internal/api/server_test.go"}},
+		{[]string{"internal", "!*_test.go"}, []string{"internal/api/server.go"}},
+		{[]string{"!web/dist", "!docs"}, []string{"cmd/app/main.go", "internal/api/server.go", "internal/api/server_test.go"}},
+		{[]string{"*.go"}, []string{"cmd/app/main.go", "internal/api/server.go", "internal/api/server_test.go"}},
+		{[]string{"cmd/*"}, []string{"cmd/app/main.go"}},
+	} {
+		if got := names(FilterDiffsByPath(diffs, tc.patterns)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("FilterDiffsByPath(%v) = %v, want %v", tc.patterns, got, tc.want)
+		}
+	}
+}
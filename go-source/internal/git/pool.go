@@ -0,0 +1,157 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrQueueTimeout is returned when a command's context ends while it waits
+// for a free worker.
+var ErrQueueTimeout = errors.New("git is busy; timed out waiting for a free worker")
+
+// PoolStats is a snapshot of a PooledRunner.
+type PoolStats struct {
+	Workers int `json:"workers"`
+	Busy    int `json:"busy"`
+	Queued  int `json:"queued"`
+}
+
+// PooledRunner runs at most Workers git commands of Inner at a time. Waiting
+// commands are queued per context and the queues are served round-robin, so
+// a request that needs many commands (a diff with hundreds of untracked
+// files) cannot starve the others. A command whose context ends while it is
+// queued fails with ErrQueueTimeout.
+type PooledRunner struct {
+	Inner   GitRunner
+	Workers int
+
+	mu     sync.Mutex
+	busy   int
+	queues map[context.Context][]chan struct{}
+	// order lists the contexts with waiting commands, next to be served
+	// first.
+	order []context.Context
+}
+
+// NewPooledRunner wraps inner (ExecRunner when nil) in a pool of workers.
+func NewPooledRunner(inner GitRunner, workers int) *PooledRunner {
+	if inner == nil {
+		inner = ExecRunner{}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &PooledRunner{Inner: inner, Workers: workers, queues: map[context.Context][]chan struct{}{}}
+}
+
+func (p *PooledRunner) Run(dir string, args ...string) (string, error) {
+	return p.RunContext(context.Background(), dir, args...)
+}
+
+func (p *PooledRunner) RunContext(ctx context.Context, dir string, args ...string) (string, error) {
+	if err := p.acquire(ctx); err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	defer p.release()
+	if cr, ok := p.Inner.(ContextRunner); ok {
+		return cr.RunContext(ctx, dir, args...)
+	}
+	return p.Inner.Run(dir, args...)
+}
+
+// Stats reports how many workers are busy and how many commands wait.
+func (p *PooledRunner) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	queued := 0
+	for _, q := range p.queues {
+		queued += len(q)
+	}
+	return PoolStats{Workers: p.Workers, Busy: p.busy, Queued: queued}
+}
+
+func (p *PooledRunner) acquire(ctx context.Context) error {
+	p.mu.Lock()
+	if p.busy < p.Workers && len(p.order) == 0 {
+		p.busy++
+		p.mu.Unlock()
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		p.mu.Unlock()
+		return errors.Join(ErrQueueTimeout, err)
+	}
+	ready := make(chan struct{})
+	if len(p.queues[ctx]) == 0 {
+		p.order = append(p.order, ctx)
+	}
+	p.queues[ctx] = append(p.queues[ctx], ready)
+	p.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+	p.mu.Lock()
+	if !p.dequeue(ctx, ready) {
+		// release handed us a worker just as ctx ended; pass it on.
+		p.mu.Unlock()
+		p.release()
+		return errors.Join(ErrQueueTimeout, ctx.Err())
+	}
+	p.mu.Unlock()
+	return errors.Join(ErrQueueTimeout, ctx.Err())
+}
+
+// dequeue removes a waiting command and reports whether it was still queued.
+func (p *PooledRunner) dequeue(ctx context.Context, ready chan struct{}) bool {
+	q := p.queues[ctx]
+	for i, c := range q {
+		if c != ready {
+			continue
+		}
+		q = append(q[:i], q[i+1:]...)
+		if len(q) == 0 {
+			delete(p.queues, ctx)
+			p.order = removeContext(p.order, ctx)
+		} else {
+			p.queues[ctx] = q
+		}
+		return true
+	}
+	return false
+}
+
+// release frees a worker or hands it to the next queue in turn.
+func (p *PooledRunner) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.order) == 0 {
+		p.busy--
+		return
+	}
+	ctx := p.order[0]
+	p.order = p.order[1:]
+	q := p.queues[ctx]
+	next := q[0]
+	if len(q) > 1 {
+		p.queues[ctx] = q[1:]
+		p.order = append(p.order, ctx)
+	} else {
+		delete(p.queues, ctx)
+	}
+	close(next)
+}
+
+func removeContext(order []context.Context, ctx context.Context) []context.Context {
+	for i, c := range order {
+		if c == ctx {
+			return append(order[:i], order[i+1:]...)
+		}
+	}
+	return order
+}
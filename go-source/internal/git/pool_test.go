@@ -0,0 +1,117 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateRunner blocks every command until release is closed and records the
+// order commands started in.
+type gateRunner struct {
+	release chan struct{}
+	mu      sync.Mutex
+	started []string
+}
+
+func (r *gateRunner) Run(dir string, args ...string) (string, error) {
+	r.mu.Lock()
+	r.started = append(r.started, args[0])
+	r.mu.Unlock()
+	<-r.release
+	return args[0], nil
+}
+
+func (r *gateRunner) order() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.started...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPooledRunnerLimitsAndRoundRobin(t *testing.T) {
+	inner := &gateRunner{release: make(chan struct{})}
+	pool := NewPooledRunner(inner, 1)
+	var wg sync.WaitGroup
+	run := func(ctx context.Context, name string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pool.RunContext(ctx, ".", name); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	run(context.Background(), "first")
+	waitFor(t, func() bool { return pool.Stats().Busy == 1 })
+	// One request queues three commands before another queues one; the
+	// second request must not wait for all three.
+	heavy, cancelHeavy := context.WithCancel(context.Background())
+	defer cancelHeavy()
+	light, cancelLight := context.WithCancel(context.Background())
+	defer cancelLight()
+	for i, name := range []string{"heavy1", "heavy2", "heavy3"} {
+		run(heavy, name)
+		waitFor(t, func() bool { return pool.Stats().Queued == i+1 })
+	}
+	run(light, "light")
+	waitFor(t, func() bool { return pool.Stats().Queued == 4 })
+	if got := pool.Stats(); got.Busy != 1 || got.Workers != 1 {
+		t.Fatalf("got %+v", got)
+	}
+
+	close(inner.release)
+	wg.Wait()
+	got := inner.order()
+	want := []string{"first", "heavy1", "light", "heavy2", "heavy3"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got order %v, want %v", got, want)
+		}
+	}
+	if s := pool.Stats(); s.Busy != 0 || s.Queued != 0 {
+		t.Fatalf("pool not drained: %+v", s)
+	}
+}
+
+func TestPooledRunnerQueueTimeout(t *testing.T) {
+	inner := &gateRunner{release: make(chan struct{})}
+	pool := NewPooledRunner(inner, 1)
+	done := make(chan struct{})
+	go func() {
+		pool.Run(".", "busy")
+		close(done)
+	}()
+	waitFor(t, func() bool { return pool.Stats().Busy == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := pool.RunContext(ctx, ".", "waiting")
+	if !errors.Is(err, ErrQueueTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a queue timeout, got %v", err)
+	}
+	if s := pool.Stats(); s.Queued != 0 {
+		t.Fatalf("timed out command still queued: %+v", s)
+	}
+	close(inner.release)
+	<-done
+	if s := pool.Stats(); s.Busy != 0 {
+		t.Fatalf("worker not released: %+v", s)
+	}
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RepoProfile is what an LLM should know about a repository to use the
+// right terminology: its name, branch, main languages and the frameworks
+// its manifests mention.
+type RepoProfile struct {
+	Name       string   `json:"name"`
+	Branch     string   `json:"branch,omitempty"`
+	Languages  []string `json:"languages"`
+	Frameworks []string `json:"frameworks"`
+	Manifests  []string `json:"manifests"`
+}
+
+// manifestHint names a framework when needle appears in a manifest.
+type manifestHint struct {
+	needle, framework string
+}
+
+// manifestHints lists, per manifest at the repository root, the
+// dependencies worth telling the model about. The first manifest that
+// declares a name also names the project.
+var manifestHints = []struct {
+	file  string
+	name  *regexp.Regexp
+	hints []manifestHint
+}{
+	{"go.mod", regexp.MustCompile(`(?m)^module\s+(\S+)`), []manifestHint{
+		{"github.com/gin-gonic/gin", "Gin"}, {"github.com/labstack/echo", "Echo"}, {"github.com/gofiber/fiber", "Fiber"},
+		{"github.com/go-chi/chi", "chi"}, {"github.com/spf13/cobra", "Cobra"}, {"github.com/charmbracelet/bubbletea", "Bubble Tea"},
+		{"google.golang.org/grpc", "gRPC"}, {"gorm.io/gorm", "GORM"}, {"k8s.io/client-go", "Kubernetes client-go"},
+	}},
+	{"package.json", regexp.MustCompile(`"name"\s*:\s*"([^"]+)"`), []manifestHint{
+		{`"next"`, "Next.js"}, {`"react"`, "React"}, {`"vue"`, "Vue"}, {`"svelte"`, "Svelte"}, {`"@angular/core"`, "Angular"},
+		{`"express"`, "Express"}, {`"@nestjs/core"`, "NestJS"}, {`"electron"`, "Electron"}, {`"typescript"`, "TypeScript"},
+		{`"jest"`, "Jest"}, {`"vitest"`, "Vitest"},
+	}},
+	{"Cargo.toml", regexp.MustCompile(`(?m)^name\s*=\s*"([^"]+)"`), []manifestHint{
+		{"tokio", "Tokio"}, {"actix-web", "Actix Web"}, {"axum", "Axum"}, {"serde", "Serde"}, {"bevy", "Bevy"},
+	}},
+	{"pyproject.toml", regexp.MustCompile(`(?m)^name\s*=\s*"([^"]+)"`), []manifestHint{
+		{"django", "Django"}, {"flask", "Flask"}, {"fastapi", "FastAPI"}, {"pytest", "pytest"}, {"pandas", "pandas"}, {"torch", "PyTorch"},
+	}},
+	{"requirements.txt", nil, []manifestHint{
+		{"django", "Django"}, {"flask", "Flask"}, {"fastapi", "FastAPI"}, {"pytest", "pytest"}, {"pandas", "pandas"}, {"torch", "PyTorch"},
+	}},
+	{"Gemfile", nil, []manifestHint{{"rails", "Ruby on Rails"}, {"sinatra", "Sinatra"}, {"rspec", "RSpec"}}},
+	{"pom.xml", nil, []manifestHint{{"spring-boot", "Spring Boot"}, {"quarkus", "Quarkus"}, {"junit", "JUnit"}}},
+	{"build.gradle", nil, []manifestHint{{"spring-boot", "Spring Boot"}, {"com.android", "Android"}, {"junit", "JUnit"}}},
+	{"build.gradle.kts", nil, []manifestHint{{"spring-boot", "Spring Boot"}, {"com.android", "Android"}, {"junit", "JUnit"}}},
+	{"composer.json", regexp.MustCompile(`"name"\s*:\s*"([^"]+)"`), []manifestHint{{"laravel/framework", "Laravel"}, {"symfony/", "Symfony"}}},
+	{"pubspec.yaml", regexp.MustCompile(`(?m)^name:\s*(\S+)`), []manifestHint{{"flutter:", "Flutter"}}},
+}
+
+// GetRepoProfile gathers a RepoProfile from the repository's root manifests,
+// its tracked files and HEAD. It reads no history, so it stays cheap enough
+// to run before every AI request.
+func (g *GitExtractor) GetRepoProfile() (RepoProfile, error) {
+	p := RepoProfile{Languages: []string{}, Frameworks: []string{}, Manifests: []string{}}
+	out, err := g.runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return p, err
+	}
+	root := strings.TrimSpace(out)
+	p.Name = filepath.Base(root)
+	if branch, err := g.runGit("symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		p.Branch = strings.TrimSpace(branch)
+	}
+	if head, err := g.ResolveRef("HEAD"); err == nil {
+		if tree, err := g.runGit("ls-tree", "-r", "-l", "--full-tree", head); err == nil {
+			for _, l := range languageShares(tree, 3) {
+				p.Languages = append(p.Languages, l.Name)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	named := false
+	for _, m := range manifestHints {
+		content, err := os.ReadFile(filepath.Join(root, m.file))
+		if err != nil {
+			continue
+		}
+		p.Manifests = append(p.Manifests, m.file)
+		text := string(content)
+		if !named && m.name != nil {
+			if match := m.name.FindStringSubmatch(text); match != nil {
+				p.Name = match[1]
+				named = true
+			}
+		}
+		lower := strings.ToLower(text)
+		for _, h := range m.hints {
+			if !seen[h.framework] && strings.Contains(lower, strings.ToLower(h.needle)) {
+				seen[h.framework] = true
+				p.Frameworks = append(p.Frameworks, h.framework)
+			}
+		}
+	}
+	return p, nil
+}
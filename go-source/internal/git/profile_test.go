@@ -0,0 +1,30 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetRepoProfile(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "main.go", "package main\n\nimport \"github.com/gin-gonic/gin\"\n\nfunc main() {\n\tr := gin.Default()\n\tr.GET(\"/cart\", listCart)\n\t_ = r.Run()\n}\n")
+	writeFile(t, dir, "go.mod", "module example.com/shop\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.0\n\tgorm.io/gorm v1.25.0\n)\n")
+	writeFile(t, dir, "web/package.json", `{"name": "ignored", "dependencies": {"react": "18"}}`)
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "manifests")
+	runIn(t, dir, "checkout", "-q", "-b", "feature/cart")
+
+	p, err := NewGitExtractor(dir).GetRepoProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "example.com/shop" || p.Branch != "feature/cart" {
+		t.Fatalf("name/branch: %+v", p)
+	}
+	if !reflect.DeepEqual(p.Frameworks, []string{"Gin", "GORM"}) || !reflect.DeepEqual(p.Manifests, []string{"go.mod"}) {
+		t.Fatalf("only root manifests count: %+v", p)
+	}
+	if len(p.Languages) == 0 || p.Languages[0] != "Go" {
+		t.Fatalf("languages: %+v", p.Languages)
+	}
+}
@@ -0,0 +1,177 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RefactorMap says where renamed and moved things went, for reviewers and
+// for tools rebasing patches across the refactor: Paths lists renamed
+// files, Symbols identifiers replaced consistently across changed lines.
+type RefactorMap struct {
+	Paths   []PathRename   `json:"paths,omitempty"`
+	Symbols []SymbolRename `json:"symbols,omitempty"`
+}
+
+type PathRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Similarity is git's similarity index; 100 is a pure move.
+	Similarity int `json:"similarity"`
+}
+
+type SymbolRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Lines counts the changed lines that differ only by this rename.
+	Lines int      `json:"lines"`
+	Files []string `json:"files"`
+}
+
+// minSymbolLines is how many changed lines must show the same replacement
+// before it counts as a rename rather than an edit.
+const minSymbolLines = 2
+
+// Empty reports whether the map has nothing in it.
+func (m RefactorMap) Empty() bool {
+	return len(m.Paths) == 0 && len(m.Symbols) == 0
+}
+
+var refactorToken = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*|[0-9][A-Za-z0-9_.]*|\S`)
+
+// BuildRefactorMap finds the renamed files of diffs and the identifiers
+// they rename. A removed line and the added line in its place, paired in
+// order within a block of changes, show a rename when they are the same
+// tokens except for identifiers replaced one for one; replacements seen on
+// at least two lines are reported, most frequent first.
+func BuildRefactorMap(diffs []ParsedDiff) RefactorMap {
+	var m RefactorMap
+	type key struct{ from, to string }
+	lines := map[key]int{}
+	files := map[key][]string{}
+	for _, d := range diffs {
+		if d.IsRenamed && !d.IsCopied {
+			m.Paths = append(m.Paths, PathRename{From: d.OldFile, To: d.NewFile, Similarity: d.Similarity})
+		}
+		for _, h := range d.Hunks {
+			for _, block := range changeBlocks(h.Lines) {
+				if len(block.removed) != len(block.added) {
+					continue
+				}
+				for i := range block.removed {
+					for from, to := range replacedIdentifiers(block.removed[i], block.added[i]) {
+						k := key{from, to}
+						lines[k]++
+						if f := files[k]; len(f) == 0 || f[len(f)-1] != d.NewFile {
+							files[k] = append(f, d.NewFile)
+						}
+					}
+				}
+			}
+		}
+	}
+	for k, n := range lines {
+		if n >= minSymbolLines {
+			m.Symbols = append(m.Symbols, SymbolRename{From: k.from, To: k.to, Lines: n, Files: files[k]})
+		}
+	}
+	sort.Slice(m.Symbols, func(i, j int) bool {
+		if m.Symbols[i].Lines != m.Symbols[j].Lines {
+			return m.Symbols[i].Lines > m.Symbols[j].Lines
+		}
+		return m.Symbols[i].From < m.Symbols[j].From
+	})
+	return m
+}
+
+type changeBlock struct {
+	removed, added []string
+}
+
+// changeBlocks splits hunk lines into runs of removed lines followed by the
+// added lines that replace them.
+func changeBlocks(lines []ParsedLine) []changeBlock {
+	var blocks []changeBlock
+	var cur changeBlock
+	flush := func() {
+		if len(cur.removed) > 0 && len(cur.added) > 0 {
+			blocks = append(blocks, cur)
+		}
+		cur = changeBlock{}
+	}
+	for _, l := range lines {
+		switch l.Type {
+		case LineDelete:
+			if len(cur.added) > 0 {
+				flush()
+			}
+			cur.removed = append(cur.removed, l.Content)
+		case LineAdd:
+			cur.added = append(cur.added, l.Content)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return blocks
+}
+
+// replacedIdentifiers returns the identifiers of old that new replaces,
+// when that is the only difference between the lines; nil otherwise.
+func replacedIdentifiers(old, new string) map[string]string {
+	a, b := refactorToken.FindAllString(old, -1), refactorToken.FindAllString(new, -1)
+	if len(a) != len(b) {
+		return nil
+	}
+	var replaced map[string]string
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+		if !isIdentifier(a[i]) || !isIdentifier(b[i]) {
+			return nil
+		}
+		if replaced == nil {
+			replaced = map[string]string{}
+		}
+		if to, ok := replaced[a[i]]; ok && to != b[i] {
+			return nil
+		}
+		replaced[a[i]] = b[i]
+	}
+	return replaced
+}
+
+func isIdentifier(tok string) bool {
+	c := tok[0]
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Markdown renders the map as tables, or "" when it is empty.
+func (m RefactorMap) Markdown() string {
+	if m.Empty() {
+		return ""
+	}
+	out := []string{"## Refactor map", ""}
+	if len(m.Paths) > 0 {
+		out = append(out, "| Old path | New path | Similarity |", "| --- | --- | --- |")
+		for _, p := range m.Paths {
+			similarity := "-"
+			if p.Similarity > 0 {
+				similarity = fmt.Sprintf("%d%%", p.Similarity)
+			}
+			out = append(out, fmt.Sprintf("| `%s` | `%s` | %s |", p.From, p.To, similarity))
+		}
+		out = append(out, "")
+	}
+	if len(m.Symbols) > 0 {
+		out = append(out, "| Old symbol | New symbol | Lines | Files |", "| --- | --- | --- | --- |")
+		for _, s := range m.Symbols {
+			out = append(out, fmt.Sprintf("| `%s` | `%s` | %d | %s |", s.From, s.To, s.Lines, strings.Join(s.Files, ", ")))
+		}
+		out = append(out, "")
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildRefactorMap(t *testing.T) {
+	dir := initTempRepo(t)
+	before := "package store\n\n// loadUser reads one user.\nfunc loadUser(id int) User {\n\treturn cache[id]\n}\n\nfunc all(ids []int) []User {\n\tvar out []User\n\tfor _, id := range ids {\n\t\tout = append(out, loadUser(id))\n\t}\n\treturn out\n}\n\nfunc first(ids []int) User {\n\treturn loadUser(ids[0])\n}\n"
+	writeFile(t, dir, "store/user.go", before)
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add store")
+	runIn(t, dir, "mv", "store", "users")
+	writeFile(t, dir, "users/user.go", strings.ReplaceAll(before, "loadUser", "fetchUser"))
+	runIn(t, dir, "add", "-A")
+	runIn(t, dir, "commit", "-q", "-m", "rename store to users")
+
+	g := NewGitExtractor(dir)
+	diffs, err := g.GetCommitDiff("HEAD", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := BuildRefactorMap(diffs)
+	if len(m.Paths) != 1 || m.Paths[0].From != "store/user.go" || m.Paths[0].To != "users/user.go" {
+		t.Fatalf("paths = %+v", m.Paths)
+	}
+	if len(m.Symbols) != 1 {
+		t.Fatalf("symbols = %+v", m.Symbols)
+	}
+	if s := m.Symbols[0]; s.From != "loadUser" || s.To != "fetchUser" || s.Lines != 4 || len(s.Files) != 1 || s.Files[0] != "users/user.go" {
+		t.Errorf("symbol = %+v", s)
+	}
+
+	f := NewDiffFormatter()
+	md := f.ToMarkdown(diffs)
+	for _, want := range []string{"## Refactor map", "| `store/user.go` | `users/user.go` |", "| `loadUser` | `fetchUser` | 4 | users/user.go |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md)
+		}
+	}
+	var payload struct {
+		Refactor RefactorMap `json:"refactor"`
+	}
+	if err := json.Unmarshal([]byte(f.ToJSON(diffs)), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Refactor.Symbols) != 1 || len(payload.Refactor.Paths) != 1 {
+		t.Errorf("json refactor = %+v", payload.Refactor)
+	}
+}
+
+func TestRefactorMapIgnoresEdits(t *testing.T) {
+	lines := func(kind ParsedLineType, contents ...string) []ParsedLine {
+		var out []ParsedLine
+		for _, c := range contents {
+			out = append(out, ParsedLine{Type: kind, Content: c})
+		}
+		return out
+	}
+	hunk := ParsedHunk{Lines: append(
+		lines(LineDelete, "x := limit + 1", "y := limit * 2", "rename(a)"),
+		lines(LineAdd, "x := max + 2", "y := max * 2", "rename(b)")...,
+	)}
+	m := BuildRefactorMap([]ParsedDiff{{NewFile: "a.go", Hunks: []ParsedHunk{hunk}}})
+	// limit→max changes the literal on one line too, and a→b is seen once.
+	if !m.Empty() {
+		t.Errorf("map = %+v", m)
+	}
+	if strings.Contains(NewDiffFormatter().ToJSON(nil), "refactor") {
+		t.Error("an empty map should not be in the JSON")
+	}
+}
@@ -0,0 +1,198 @@
+package git
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// maxRepoLanguages caps how many languages RepoInfo reports.
+const maxRepoLanguages = 5
+
+type RemoteInfo struct {
+	Name     string `json:"name"`
+	FetchURL string `json:"fetchUrl"`
+	PushURL  string `json:"pushUrl,omitempty"`
+}
+
+type LanguageShare struct {
+	Name    string  `json:"name"`
+	Files   int     `json:"files"`
+	Bytes   int64   `json:"bytes"`
+	Percent float64 `json:"percent"`
+}
+
+// RepoInfo describes a repository as a whole.
+type RepoInfo struct {
+	Root          string          `json:"root"`
+	CurrentBranch string          `json:"currentBranch"`
+	Head          string          `json:"head"`
+	Detached      bool            `json:"detached"`
+	DefaultBranch string          `json:"defaultBranch"`
+	Remotes       []RemoteInfo    `json:"remotes"`
+	Dirty         bool            `json:"dirty"`
+	TotalCommits  int             `json:"totalCommits"`
+	Languages     []LanguageShare `json:"languages"`
+}
+
+// GetRepoInfo gathers the repository's root, HEAD, remotes, working tree
+// state, commit count and primary languages. A repository without commits
+// reports an empty Head and zero commits rather than an error.
+func (g *GitExtractor) GetRepoInfo() (RepoInfo, error) {
+	var info RepoInfo
+	root, err := g.runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return info, err
+	}
+	info.Root = strings.TrimSpace(root)
+	if branch, err := g.runGit("symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		info.CurrentBranch = strings.TrimSpace(branch)
+	} else {
+		info.Detached = true
+	}
+	if head, err := g.ResolveRef("HEAD"); err == nil {
+		info.Head = head
+		if out, err := g.runGit("rev-list", "--count", head); err == nil {
+			info.TotalCommits, _ = strconv.Atoi(strings.TrimSpace(out))
+		}
+	}
+	info.DefaultBranch = g.DefaultBranch()
+
+	remotes, err := g.runGit("remote", "-v")
+	if err != nil {
+		return info, err
+	}
+	info.Remotes = parseRemotes(remotes)
+
+	status, err := g.runGit("status", "--porcelain", "--untracked-files=normal")
+	if err != nil {
+		return info, err
+	}
+	info.Dirty = strings.TrimSpace(status) != ""
+
+	if info.Head != "" {
+		tree, err := g.runGit("ls-tree", "-r", "-l", "--full-tree", info.Head)
+		if err != nil {
+			return info, err
+		}
+		info.Languages = languageShares(tree, maxRepoLanguages)
+	}
+	return info, nil
+}
+
+// DefaultBranch returns the branch the repository treats as its mainline:
+// the target of origin/HEAD, then init.defaultBranch, main or master when
+// such a branch exists. It returns "" when none can be found.
+func (g *GitExtractor) DefaultBranch() string {
+	if out, err := g.runGit("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if name := strings.TrimPrefix(strings.TrimSpace(out), "origin/"); name != "" {
+			return name
+		}
+	}
+	candidates := []string{g.GetConfigValue("init.defaultBranch"), "main", "master"}
+	for _, name := range candidates {
+		if name == "" {
+			continue
+		}
+		if _, err := g.runGit("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+			return name
+		}
+		if _, err := g.runGit("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseRemotes reads `git remote -v` output.
+func parseRemotes(out string) []RemoteInfo {
+	remotes := []RemoteInfo{}
+	index := map[string]int{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		i, ok := index[fields[0]]
+		if !ok {
+			i = len(remotes)
+			index[fields[0]] = i
+			remotes = append(remotes, RemoteInfo{Name: fields[0]})
+		}
+		switch fields[2] {
+		case "(fetch)":
+			remotes[i].FetchURL = fields[1]
+		case "(push)":
+			if fields[1] != remotes[i].FetchURL {
+				remotes[i].PushURL = fields[1]
+			}
+		}
+	}
+	return remotes
+}
+
+// languageShares totals `git ls-tree -r -l` output by the language chroma
+// recognizes for each path and returns the largest limit languages by size.
+// Files no lexer matches are left out of the percentages.
+func languageShares(tree string, limit int) []LanguageShare {
+	byExt := map[string]string{}
+	totals := map[string]*LanguageShare{}
+	var all int64
+	for _, line := range strings.Split(tree, "\n") {
+		meta, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) < 4 || fields[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		base := filepath.Base(path)
+		key := strings.ToLower(filepath.Ext(base))
+		if key == "" {
+			key = base
+		}
+		name, seen := byExt[key]
+		if !seen {
+			if lexer := lexers.Match(base); lexer != nil {
+				name = lexer.Config().Name
+			}
+			byExt[key] = name
+		}
+		if name == "" || name == "plaintext" {
+			continue
+		}
+		share := totals[name]
+		if share == nil {
+			share = &LanguageShare{Name: name}
+			totals[name] = share
+		}
+		share.Files++
+		share.Bytes += size
+		all += size
+	}
+	shares := make([]LanguageShare, 0, len(totals))
+	for _, s := range totals {
+		if all > 0 {
+			s.Percent = float64(int64(float64(s.Bytes)*1000/float64(all))) / 10
+		}
+		shares = append(shares, *s)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Bytes != shares[j].Bytes {
+			return shares[i].Bytes > shares[j].Bytes
+		}
+		return shares[i].Name < shares[j].Name
+	})
+	if len(shares) > limit {
+		shares = shares[:limit]
+	}
+	return shares
+}
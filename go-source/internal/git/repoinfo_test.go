@@ -0,0 +1,41 @@
+package git
+
+import "testing"
+
+func TestGetRepoInfo(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "lib/util.py", "def f():\n    return 1\n\n\n\n\n")
+	writeFile(t, dir, "README", "notes\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "more")
+	runIn(t, dir, "branch", "-M", "main")
+	runIn(t, dir, "remote", "add", "origin", "https://example.com/repo.git")
+	writeFile(t, dir, "main.go", "package main // edited\n")
+
+	info, err := NewGitExtractor(dir).GetRepoInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CurrentBranch != "main" || info.Detached || info.DefaultBranch != "main" {
+		t.Fatalf("branches: %+v", info)
+	}
+	if len(info.Head) != 40 || info.TotalCommits != 2 || !info.Dirty {
+		t.Fatalf("head/commits/dirty: %+v", info)
+	}
+	if len(info.Remotes) != 1 || info.Remotes[0].Name != "origin" || info.Remotes[0].FetchURL != "https://example.com/repo.git" || info.Remotes[0].PushURL != "" {
+		t.Fatalf("remotes: %+v", info.Remotes)
+	}
+	if len(info.Languages) != 2 || info.Languages[0].Name != "Python" || info.Languages[1].Name != "Go" {
+		t.Fatalf("languages: %+v", info.Languages)
+	}
+	if sum := info.Languages[0].Percent + info.Languages[1].Percent; sum < 99.8 || sum > 100 {
+		t.Fatalf("percentages add up to %v", sum)
+	}
+}
+
+func TestParseRemotes(t *testing.T) {
+	got := parseRemotes("origin\tgit@a:x.git (fetch)\norigin\tgit@b:x.git (push)\nfork\thttps://f (fetch)\nfork\thttps://f (push)\n")
+	if len(got) != 2 || got[0].PushURL != "git@b:x.git" || got[1].Name != "fork" || got[1].PushURL != "" {
+		t.Fatalf("got %+v", got)
+	}
+}
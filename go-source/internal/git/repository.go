@@ -0,0 +1,27 @@
+package git
+
+// Repository is what the dashboard and the AI commands read from a
+// repository. GitExtractor implements it, and the hg and jj packages adapt
+// Mercurial and Jujutsu repositories to it, so those commands work there
+// too. What only git has, such as the index, stashes and switching
+// branches, stays on GitExtractor.
+type Repository interface {
+	// RepoPath is the absolute path of the repository.
+	RepoPath() string
+	IsRepo() bool
+	// GetLocalDiff returns the uncommitted changes. Repositories without
+	// an index have no staged changes and honor only Against and
+	// Whitespace.
+	GetLocalDiff(options DiffOptions) ([]ParsedDiff, error)
+	GetFileDiff(filePath, commit string) ([]ParsedDiff, error)
+	GetCommitDiff(commit1, commit2 string) ([]ParsedDiff, error)
+	GetBranchDiff(branch1, branch2 string, mode ...BranchDiffMode) ([]ParsedDiff, error)
+	GetCommitHistory(limit int) ([]CommitInfo, error)
+	GetBranchesDetailed() ([]BranchEntry, error)
+	GetCommitGraph(limit int, allBranches bool) (CommitGraph, error)
+	// ResolveRef returns the full hash of a revision; "HEAD" is the parent
+	// of the working directory in every kind of repository.
+	ResolveRef(ref string) (string, error)
+}
+
+var _ Repository = (*GitExtractor)(nil)
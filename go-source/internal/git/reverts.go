@@ -0,0 +1,240 @@
+package git
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RevertPair is a commit and the commit that reverted it, with the commit
+// that brought the change back when there is one.
+type RevertPair struct {
+	Original CommitInfo  `json:"original"`
+	Revert   CommitInfo  `json:"revert"`
+	Reapply  *CommitInfo `json:"reapply,omitempty"`
+	// Match says how the revert was found: "message" when its message
+	// names the original, "patch" when its patch undoes the original's.
+	Match string `json:"match"`
+}
+
+// Role returns how hash takes part in the pair, "original", "revert" or
+// "reapply", or "" when it does not.
+func (p RevertPair) Role(hash string) string {
+	switch {
+	case p.Original.Hash == hash:
+		return "original"
+	case p.Revert.Hash == hash:
+		return "revert"
+	case p.Reapply != nil && p.Reapply.Hash == hash:
+		return "reapply"
+	}
+	return ""
+}
+
+var (
+	revertsCommitRe  = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,64})`)
+	revertSubjectRe  = regexp.MustCompile(`^Revert "(.*)"$`)
+	reapplySubjectRe = regexp.MustCompile(`^Reapply "(.*)"$`)
+)
+
+// revertCandidate is a commit of the searched history with what pairing
+// needs to know about it.
+type revertCandidate struct {
+	info CommitInfo
+	// patch and reverse identify the commit's change and its inverse like
+	// git patch-id, so a commit undoing another has a reverse equal to the
+	// other's patch whatever the line numbers.
+	patch, reverse string
+}
+
+// FindRevertPairs looks for reverted commits in the history git log selects
+// with args, such as "--max-count=200" and a revision. A commit is a revert
+// when its message says "This reverts commit <hash>" or `Revert "<subject>"`
+// as git revert writes it, or when its patch undoes an older commit's. An
+// original whose revert was reverted in turn, reapplied under the same
+// subject or re-committed with the same patch gets that commit as Reapply.
+// Pairs are listed newest revert first.
+func (g *GitExtractor) FindRevertPairs(args ...string) ([]RevertPair, error) {
+	commits, err := g.logCommits(args...)
+	if err != nil {
+		return nil, err
+	}
+	logArgs := append([]string{"log", "-p", "--no-color", "--no-ext-diff", "--no-renames", "--format=%x1e%H%x1f%b%x1f"}, args...)
+	out, err := g.runGit(logArgs...)
+	if err != nil {
+		return nil, err
+	}
+	bodies := map[string]string{}
+	patches := map[string][]ParsedDiff{}
+	for _, rec := range strings.Split(out, "\x1e") {
+		parts := strings.SplitN(rec, "\x1f", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		hash := strings.TrimSpace(parts[0])
+		bodies[hash] = strings.TrimSpace(parts[1])
+		patches[hash] = g.parser.Parse(parts[2])
+	}
+
+	// Newest first, as git log lists them: older commits have larger indexes.
+	cands := make([]revertCandidate, len(commits))
+	index := map[string]int{}
+	for i, c := range commits {
+		c.Body = bodies[c.Hash]
+		cands[i] = revertCandidate{info: c, patch: patchID(patches[c.Hash], false), reverse: patchID(patches[c.Hash], true)}
+		index[c.Hash] = i
+	}
+
+	// reverted maps a revert to the commit it undoes, which may be older
+	// than the searched history when the message names it.
+	type undo struct {
+		original CommitInfo
+		match    string
+	}
+	reverted := map[string]undo{}
+	for i := len(cands) - 1; i >= 0; i-- {
+		c := cands[i]
+		if m := revertsCommitRe.FindStringSubmatch(c.info.Body); m != nil {
+			if o, ok := g.findCommit(cands, index, m[1]); ok {
+				reverted[c.info.Hash] = undo{o, "message"}
+				continue
+			}
+		}
+		if m := revertSubjectRe.FindStringSubmatch(c.info.Message); m != nil {
+			if j := olderWith(cands, i, func(o revertCandidate) bool { return o.info.Message == m[1] }); j >= 0 {
+				reverted[c.info.Hash] = undo{cands[j].info, "message"}
+				continue
+			}
+		}
+		if c.reverse != "" {
+			if j := olderWith(cands, i, func(o revertCandidate) bool { return o.patch == c.reverse }); j >= 0 {
+				reverted[c.info.Hash] = undo{cands[j].info, "patch"}
+			}
+		}
+	}
+
+	var pairs []RevertPair
+	for i, c := range cands {
+		u, ok := reverted[c.info.Hash]
+		if !ok {
+			continue
+		}
+		// A revert of a revert is the original coming back.
+		if _, isRevert := reverted[u.original.Hash]; isRevert {
+			continue
+		}
+		pair := RevertPair{Original: u.original, Revert: c.info, Match: u.match}
+		var originalPatch string
+		if j, ok := index[u.original.Hash]; ok {
+			originalPatch = cands[j].patch
+		}
+		if j := newerWith(cands, i, func(n revertCandidate) bool {
+			if r, ok := reverted[n.info.Hash]; ok && r.original.Hash == c.info.Hash {
+				return true
+			}
+			if m := reapplySubjectRe.FindStringSubmatch(n.info.Message); m != nil && m[1] == u.original.Message {
+				return true
+			}
+			return originalPatch != "" && n.patch == originalPatch
+		}); j >= 0 {
+			reapply := cands[j].info
+			pair.Reapply = &reapply
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// findCommit returns the commit a hash or hash prefix names, looking it up
+// in git when it is older than the searched history.
+func (g *GitExtractor) findCommit(cands []revertCandidate, index map[string]int, hash string) (CommitInfo, bool) {
+	if i, ok := index[hash]; ok {
+		return cands[i].info, true
+	}
+	for _, c := range cands {
+		if strings.HasPrefix(c.info.Hash, hash) {
+			return c.info, true
+		}
+	}
+	commits, err := g.GetCommitsWithBodies(hash + "^!")
+	if err != nil || len(commits) == 0 {
+		return CommitInfo{}, false
+	}
+	return commits[0], true
+}
+
+// olderWith returns the newest commit older than cands[i] that match
+// accepts, or -1.
+func olderWith(cands []revertCandidate, i int, match func(revertCandidate) bool) int {
+	for j := i + 1; j < len(cands); j++ {
+		if match(cands[j]) {
+			return j
+		}
+	}
+	return -1
+}
+
+// newerWith returns the oldest commit newer than cands[i] that match
+// accepts, or -1.
+func newerWith(cands []revertCandidate, i int, match func(revertCandidate) bool) int {
+	for j := i - 1; j >= 0; j-- {
+		if match(cands[j]) {
+			return j
+		}
+	}
+	return -1
+}
+
+// patchID hashes the changed lines of each file with whitespace removed,
+// ignoring line numbers and the order of the lines, as in git patch-id
+// --stable. With reverse it identifies the patch that undoes diffs. It is
+// empty for a commit that changes no text, such as a merge.
+func patchID(diffs []ParsedDiff, reverse bool) string {
+	files := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		var lines []string
+		for _, h := range d.Hunks {
+			for _, l := range h.Lines {
+				if l.Type != LineAdd && l.Type != LineDelete {
+					continue
+				}
+				sign := "-"
+				if (l.Type == LineAdd) != reverse {
+					sign = "+"
+				}
+				lines = append(lines, sign+strings.Join(strings.Fields(l.Content), ""))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		sort.Strings(lines)
+		files = append(files, d.NewFile+"\x00"+strings.Join(lines, "\n"))
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	sort.Strings(files)
+	sum := sha1.Sum([]byte(strings.Join(files, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// RangeDiff compares the change of commit a with the change of commit b,
+// as git range-diff does, for seeing what differed when a reverted change
+// came back. Differences in the commit messages are included; it is empty
+// when the commits are the same.
+func (g *GitExtractor) RangeDiff(a, b string) (string, error) {
+	// The highest creation factor pairs the two commits however much the
+	// patch changed, instead of listing them as unrelated.
+	out, err := g.run("range-diff", "--no-color", "--creation-factor=100", a+"^.."+a, b+"^.."+b)
+	if err != nil {
+		return "", err
+	}
+	// One line such as "1:  abc = 1:  def" means nothing changed.
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) == 1 && strings.Contains(lines[0], " = ") {
+		return "", nil
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
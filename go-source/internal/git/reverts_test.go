@@ -0,0 +1,56 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindRevertPairs(t *testing.T) {
+	dir := initTempRepo(t)
+	commit := func(file, content, message string) {
+		writeFile(t, dir, file, content)
+		runIn(t, dir, "add", ".")
+		runIn(t, dir, "commit", "-q", "-m", message)
+	}
+	commit("cache.go", "package main\n\nvar cache = map[string]int{}\n", "add cache")
+	commit("other.go", "package main\n", "unrelated")
+	runIn(t, dir, "revert", "--no-edit", "HEAD~1")
+	runIn(t, dir, "revert", "--no-edit", "HEAD")
+	commit("main.go", "package main\n\nconst limit = 10\n", "tune limit")
+	// Undone by hand: only the patch gives it away.
+	commit("main.go", "package main\n", "go back to no limit")
+
+	g := NewGitExtractor(dir)
+	pairs, err := g.FindRevertPairs("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("pairs = %+v", pairs)
+	}
+	byPatch, byMessage := pairs[0], pairs[1]
+	if byPatch.Original.Message != "tune limit" || byPatch.Revert.Message != "go back to no limit" || byPatch.Match != "patch" || byPatch.Reapply != nil {
+		t.Errorf("patch pair = %+v", byPatch)
+	}
+	if byMessage.Original.Message != "add cache" || byMessage.Match != "message" || byMessage.Reapply == nil {
+		t.Fatalf("message pair = %+v", byMessage)
+	}
+	if byMessage.Role(byMessage.Reapply.Hash) != "reapply" || byMessage.Role(byPatch.Revert.Hash) != "" {
+		t.Errorf("roles are wrong for %+v", byMessage)
+	}
+	// Only the messages differ.
+	diff, err := g.RangeDiff(byMessage.Original.Hash, byMessage.Reapply.Hash)
+	if err != nil || !strings.Contains(diff, "## Commit message ##") || strings.Contains(diff, "var cache") {
+		t.Errorf("range-diff = %q, %v", diff, err)
+	}
+
+	// With the original outside the searched history the message still
+	// names it.
+	pairs, err = g.FindRevertPairs("--max-count=4", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 2 || pairs[1].Original.Message != "add cache" {
+		t.Errorf("pairs within four commits = %+v", pairs)
+	}
+}
@@ -0,0 +1,193 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// GitRunner executes git commands in dir. ExecRunner runs the real binary;
+// FakeRunner and RecordingRunner let tests replay canned output instead of
+// depending on the state of a checkout.
+type GitRunner interface {
+	Run(dir string, args ...string) (string, error)
+}
+
+// ContextRunner is a GitRunner that can stop a command when ctx is done.
+type ContextRunner interface {
+	RunContext(ctx context.Context, dir string, args ...string) (string, error)
+}
+
+// GitError is returned when git exits non-zero. Stdout is kept because some
+// commands (diff --no-index) signal differences through the exit code.
+type GitError struct {
+	Args     []string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+func (e *GitError) Error() string {
+	return fmt.Sprintf("git %s failed: %s", strings.Join(e.Args, " "), e.Stderr)
+}
+
+type ExecRunner struct{}
+
+func (r ExecRunner) Run(dir string, args ...string) (string, error) {
+	return r.RunContext(context.Background(), dir, args...)
+}
+
+// RunContext kills git when ctx is done.
+func (ExecRunner) RunContext(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), ctx.Err())
+		}
+		gitErr := &GitError{Args: args, ExitCode: -1, Stdout: out.String(), Stderr: strings.TrimSpace(stderr.String())}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			gitErr.ExitCode = exitErr.ExitCode()
+		}
+		if gitErr.Stderr == "" {
+			gitErr.Stderr = err.Error()
+		}
+		return "", gitErr
+	}
+	return out.String(), nil
+}
+
+// FakeResponse is one canned git invocation result.
+type FakeResponse struct {
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exitCode,omitempty"`
+}
+
+// FakeRunner answers git commands from a table keyed by the space-joined
+// arguments and records every call.
+type FakeRunner struct {
+	mu        sync.Mutex
+	responses map[string]FakeResponse
+	calls     [][]string
+}
+
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{responses: map[string]FakeResponse{}}
+}
+
+// LoadFakeRunner reads responses saved by RecordingRunner.Save.
+func LoadFakeRunner(path string) (*FakeRunner, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFakeRunner()
+	if err := json.Unmarshal(b, &f.responses); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// On registers stdout as the successful output of `git args...`.
+func (f *FakeRunner) On(stdout string, args ...string) *FakeRunner {
+	return f.Respond(FakeResponse{Stdout: stdout}, args...)
+}
+
+func (f *FakeRunner) Respond(resp FakeResponse, args ...string) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[strings.Join(args, " ")] = resp
+	return f
+}
+
+func (f *FakeRunner) Run(dir string, args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, append([]string(nil), args...))
+	resp, ok := f.responses[strings.Join(args, " ")]
+	if !ok {
+		return "", &GitError{Args: args, ExitCode: -1, Stderr: "fake git: no response registered"}
+	}
+	if resp.ExitCode != 0 {
+		return "", &GitError{Args: args, ExitCode: resp.ExitCode, Stdout: resp.Stdout, Stderr: resp.Stderr}
+	}
+	return resp.Stdout, nil
+}
+
+// Calls returns the argument lists of every command run so far.
+func (f *FakeRunner) Calls() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]string(nil), f.calls...)
+}
+
+// RecordingRunner passes commands through to Inner and remembers the results
+// so a test fixture can be captured once and replayed with a FakeRunner.
+type RecordingRunner struct {
+	Inner GitRunner
+
+	mu        sync.Mutex
+	responses map[string]FakeResponse
+	calls     [][]string
+}
+
+func NewRecordingRunner(inner GitRunner) *RecordingRunner {
+	if inner == nil {
+		inner = ExecRunner{}
+	}
+	return &RecordingRunner{Inner: inner, responses: map[string]FakeResponse{}}
+}
+
+func (r *RecordingRunner) Run(dir string, args ...string) (string, error) {
+	out, err := r.Inner.Run(dir, args...)
+	resp := FakeResponse{Stdout: out}
+	var gitErr *GitError
+	if errors.As(err, &gitErr) {
+		resp = FakeResponse{Stdout: gitErr.Stdout, Stderr: gitErr.Stderr, ExitCode: gitErr.ExitCode}
+	}
+	r.mu.Lock()
+	r.responses[strings.Join(args, " ")] = resp
+	r.calls = append(r.calls, append([]string(nil), args...))
+	r.mu.Unlock()
+	return out, err
+}
+
+func (r *RecordingRunner) Calls() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]string(nil), r.calls...)
+}
+
+// Replay returns a FakeRunner that answers with everything recorded so far.
+func (r *RecordingRunner) Replay() *FakeRunner {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := NewFakeRunner()
+	for k, v := range r.responses {
+		f.responses[k] = v
+	}
+	return f
+}
+
+// Save writes the recorded responses as a JSON fixture for LoadFakeRunner.
+func (r *RecordingRunner) Save(path string) error {
+	r.mu.Lock()
+	b, err := json.MarshalIndent(r.responses, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
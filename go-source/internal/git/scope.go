@@ -0,0 +1,115 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangeScope is where a local change lives relative to the index.
+type ChangeScope string
+
+const (
+	ScopeStaged   ChangeScope = "staged"
+	ScopeUnstaged ChangeScope = "unstaged"
+	// ScopePartial is a file with both staged and unstaged changes.
+	ScopePartial   ChangeScope = "partial"
+	ScopeUntracked ChangeScope = "untracked"
+)
+
+// emptyTree is git's well-known empty tree, the base for a repository
+// without commits.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// allLocalDiff diffs the working tree against HEAD, adds the untracked
+// files, and sets each file's Scope from the staged and unstaged file lists.
+func (g *GitExtractor) allLocalDiff(options DiffOptions, ctx int) ([]ParsedDiff, error) {
+	base := "HEAD"
+	if _, err := g.ResolveRef("HEAD"); err != nil {
+		base = emptyTree
+	}
+	args := append([]string{"diff", fmt.Sprintf("-U%d", ctx)}, options.args()...)
+	raw, err := g.runGit(append(args, base, "--")...)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := g.untrackedDiff(ctx)
+	if err != nil {
+		return nil, err
+	}
+	staged, err := g.changedPaths("--cached")
+	if err != nil {
+		return nil, err
+	}
+	unstaged, err := g.changedPaths()
+	if err != nil {
+		return nil, err
+	}
+	tracked := g.parse(raw)
+	for i := range tracked {
+		tracked[i].Scope = scopeOf(tracked[i], staged, unstaged)
+	}
+	added := g.parse(untracked)
+	for i := range added {
+		added[i].Scope = ScopeUntracked
+	}
+	diffs := append(tracked, added...)
+	if options.RecurseSubmodules {
+		return g.ExpandSubmodules(diffs), nil
+	}
+	return diffs, nil
+}
+
+// changedPaths lists the old and new paths git diff reports with extra.
+func (g *GitExtractor) changedPaths(extra ...string) (map[string]bool, error) {
+	out, err := g.runGit(append(append([]string{"diff", "--name-status", "-z"}, extra...), "--")...)
+	if err != nil {
+		return nil, err
+	}
+	paths := map[string]bool{}
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+		// Renames and copies are followed by the old and the new path.
+		n := 1
+		if status[0] == 'R' || status[0] == 'C' {
+			n = 2
+		}
+		for ; n > 0 && i+1 < len(fields); n-- {
+			i++
+			paths[fields[i]] = true
+		}
+	}
+	return paths, nil
+}
+
+func scopeOf(d ParsedDiff, staged, unstaged map[string]bool) ChangeScope {
+	inStaged := staged[d.NewFile] || staged[d.OldFile]
+	inUnstaged := unstaged[d.NewFile] || unstaged[d.OldFile]
+	switch {
+	case inStaged && inUnstaged:
+		return ScopePartial
+	case inStaged:
+		return ScopeStaged
+	default:
+		return ScopeUnstaged
+	}
+}
+
+// Label is the scope as formatters show it.
+func (s ChangeScope) Label() string {
+	if s == ScopePartial {
+		return "partly staged"
+	}
+	return string(s)
+}
+
+// scopeNote is the label formatters append to a file header, or "".
+func scopeNote(d ParsedDiff) string {
+	if d.Scope == "" {
+		return ""
+	}
+	return " [" + d.Scope.Label() + "]"
+}
@@ -0,0 +1,61 @@
+package git
+
+import "testing"
+
+func TestGetLocalDiffAllScopes(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "partial.txt", "one\ntwo\n")
+	writeFile(t, dir, "old name.txt", "moved\ncontent\nstays\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "more")
+
+	writeFile(t, dir, "main.go", "package main // staged\n")
+	writeFile(t, dir, "partial.txt", "one changed\ntwo\n")
+	runIn(t, dir, "add", "main.go", "partial.txt")
+	runIn(t, dir, "mv", "old name.txt", "new name.txt")
+	writeFile(t, dir, "partial.txt", "one changed\ntwo changed\n")
+	writeFile(t, dir, "notes.txt", "untracked\n")
+
+	diffs, err := NewGitExtractor(dir).GetLocalDiff(DiffOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]ChangeScope{}
+	for _, d := range diffs {
+		got[d.NewFile] = d.Scope
+	}
+	want := map[string]ChangeScope{
+		"main.go":      ScopeStaged,
+		"partial.txt":  ScopePartial,
+		"new name.txt": ScopeStaged,
+		"notes.txt":    ScopeUntracked,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for file, scope := range want {
+		if got[file] != scope {
+			t.Errorf("%s: scope %q, want %q", file, got[file], scope)
+		}
+	}
+	for _, d := range diffs {
+		if d.NewFile == "partial.txt" && (d.Additions != 2 || d.Deletions != 2) {
+			t.Errorf("partial.txt should combine both sides against HEAD: %+v", d)
+		}
+	}
+}
+
+func TestGetLocalDiffAllWithoutCommits(t *testing.T) {
+	dir := t.TempDir()
+	runIn(t, dir, "init", "-q")
+	writeFile(t, dir, "a.txt", "a\n")
+	runIn(t, dir, "add", ".")
+	writeFile(t, dir, "b.txt", "b\n")
+	diffs, err := NewGitExtractor(dir).GetLocalDiff(DiffOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 || diffs[0].Scope != ScopeStaged || diffs[1].Scope != ScopeUntracked {
+		t.Fatalf("got %+v", diffs)
+	}
+}
@@ -0,0 +1,78 @@
+package git
+
+import "fmt"
+
+// StageView shows what staging does to one file: Staged is HEAD → index,
+// Unstaged is index → worktree and Combined is HEAD → worktree, the sum of
+// the two. A side without changes is nil.
+type StageView struct {
+	Path     string      `json:"path"`
+	Scope    ChangeScope `json:"scope"`
+	Staged   *ParsedDiff `json:"staged"`
+	Unstaged *ParsedDiff `json:"unstaged"`
+	Combined *ParsedDiff `json:"combined"`
+}
+
+// GetStageViews returns a StageView for every tracked file with staged or
+// unstaged changes, limited to paths when any are given, in the order of
+// the combined diff.
+func (g *GitExtractor) GetStageViews(paths ...string) ([]StageView, error) {
+	base := "HEAD"
+	if _, err := g.ResolveRef("HEAD"); err != nil {
+		base = emptyTree
+	}
+	load := func(args ...string) (map[string]*ParsedDiff, []ParsedDiff, error) {
+		raw, err := g.runGit(append(append([]string{"diff", "-U3"}, args...), append([]string{"--"}, paths...)...)...)
+		if err != nil {
+			return nil, nil, err
+		}
+		diffs := g.parse(raw)
+		byPath := make(map[string]*ParsedDiff, len(diffs))
+		for i := range diffs {
+			byPath[diffs[i].NewFile] = &diffs[i]
+			if diffs[i].IsDeleted {
+				byPath[diffs[i].OldFile] = &diffs[i]
+			}
+		}
+		return byPath, diffs, nil
+	}
+	staged, stagedList, err := load("--cached", base)
+	if err != nil {
+		return nil, fmt.Errorf("staged diff: %w", err)
+	}
+	unstaged, unstagedList, err := load()
+	if err != nil {
+		return nil, fmt.Errorf("unstaged diff: %w", err)
+	}
+	combined, combinedList, err := load(base)
+	if err != nil {
+		return nil, fmt.Errorf("combined diff: %w", err)
+	}
+	// A change staged and then undone in the worktree has no combined diff,
+	// so the other lists can add files.
+	views := make([]StageView, 0, len(combinedList))
+	seen := map[string]bool{}
+	for _, list := range [][]ParsedDiff{combinedList, stagedList, unstagedList} {
+		for _, d := range list {
+			path := d.NewFile
+			if d.IsDeleted {
+				path = d.OldFile
+			}
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			v := StageView{Path: path, Staged: staged[path], Unstaged: unstaged[path], Combined: combined[path]}
+			switch {
+			case v.Staged != nil && v.Unstaged != nil:
+				v.Scope = ScopePartial
+			case v.Staged != nil:
+				v.Scope = ScopeStaged
+			default:
+				v.Scope = ScopeUnstaged
+			}
+			views = append(views, v)
+		}
+	}
+	return views, nil
+}
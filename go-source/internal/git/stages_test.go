@@ -0,0 +1,39 @@
+package git
+
+import "testing"
+
+func TestGetStageViews(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "partial.txt", "one\ntwo\nthree\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "more")
+
+	writeFile(t, dir, "partial.txt", "one staged\ntwo\nthree\n")
+	writeFile(t, dir, "main.go", "package main // reverted\n")
+	runIn(t, dir, "add", ".")
+	writeFile(t, dir, "partial.txt", "one staged\ntwo\nthree unstaged\n")
+	writeFile(t, dir, "main.go", "package main\n")
+
+	views, err := NewGitExtractor(dir).GetStageViews()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 2 {
+		t.Fatalf("expected two files, got %+v", views)
+	}
+	p := views[0]
+	if p.Path != "partial.txt" || p.Scope != ScopePartial || p.Staged == nil || p.Unstaged == nil || p.Combined == nil {
+		t.Fatalf("partial.txt: %+v", p)
+	}
+	if p.Staged.Additions != 1 || p.Unstaged.Additions != 1 || p.Combined.Additions != 2 {
+		t.Fatalf("staged %d, unstaged %d, combined %d additions", p.Staged.Additions, p.Unstaged.Additions, p.Combined.Additions)
+	}
+	if r := views[1]; r.Path != "main.go" || r.Combined != nil || r.Scope != ScopePartial {
+		t.Fatalf("a staged change undone in the worktree: %+v", r)
+	}
+
+	only, err := NewGitExtractor(dir).GetStageViews("main.go")
+	if err != nil || len(only) != 1 || only[0].Path != "main.go" {
+		t.Fatalf("path filter: %+v, %v", only, err)
+	}
+}
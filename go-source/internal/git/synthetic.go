@@ -0,0 +1,34 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntheticDiff builds a deterministic unified diff with the given number of
+// files, hunks per file and changed lines per hunk. It is used to benchmark
+// the parser and formatters independently of any repository.
+func SyntheticDiff(files, hunks, lines int) string {
+	var b strings.Builder
+	for f := 0; f < files; f++ {
+		path := fmt.Sprintf("pkg%d/file%d.go", f%17, f)
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n", path, path)
+		fmt.Fprintf(&b, "index %07x..%07x 100644\n", f, f+1)
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+		for h := 0; h < hunks; h++ {
+			start := 1 + h*(lines*2+10)
+			removed, added := (lines+1)/2, lines/2
+			fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@ func f%d() {\n", start, removed+2, start, added+2, h)
+			b.WriteString(" \tctx := context.Background()\n")
+			for l := 0; l < lines; l++ {
+				if l%2 == 0 {
+					fmt.Fprintf(&b, "-\tvalue%d := compute(ctx, %d)\n", l, l)
+				} else {
+					fmt.Fprintf(&b, "+\tvalue%d, err := computeChecked(ctx, %d)\n", l, l)
+				}
+			}
+			b.WriteString(" \treturn nil\n")
+		}
+	}
+	return b.String()
+}
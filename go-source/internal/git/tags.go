@@ -0,0 +1,55 @@
+package git
+
+import (
+	"strings"
+)
+
+// TagInfo is a tag with the commit it points at. Date is the tag's own date
+// for annotated tags and the commit date otherwise.
+type TagInfo struct {
+	Name      string `json:"name"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	Subject   string `json:"subject"`
+	Annotated bool   `json:"annotated"`
+}
+
+// GetTags lists the repository's tags, newest first.
+func (g *GitExtractor) GetTags() ([]TagInfo, error) {
+	// The last --sort is the primary key; tags made in the same second fall
+	// back to version order.
+	out, err := g.runGit("for-each-ref", "refs/tags", "--sort=-v:refname", "--sort=-creatordate",
+		"--format=%(refname:short)%1f%(objecttype)%1f%(objectname)%1f%(*objectname)%1f%(creatordate:iso-strict)%1f%(contents:subject)")
+	if err != nil {
+		return nil, err
+	}
+	tags := []TagInfo{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\x1f", 6)
+		if len(parts) < 6 {
+			continue
+		}
+		t := TagInfo{Name: parts[0], Commit: parts[2], Date: parts[4], Subject: parts[5], Annotated: parts[1] == "tag"}
+		if t.Annotated {
+			t.Commit = parts[3]
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// resolveNonBranchRef accepts a tag or any other revision naming a commit,
+// for places that take a branch. kind is "tag" or "commit".
+func (g *GitExtractor) resolveNonBranchRef(ref string) (kind string, ok bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", false
+	}
+	if _, err := g.runGit("rev-parse", "--verify", "--quiet", "refs/tags/"+strings.TrimPrefix(ref, "refs/tags/")); err == nil {
+		return "tag", true
+	}
+	if _, err := g.runGit("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err == nil {
+		return "commit", true
+	}
+	return "", false
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestGetTagsAndTagComparison(t *testing.T) {
+	dir := initTempRepo(t)
+	runIn(t, dir, "tag", "v1.0")
+	writeFile(t, dir, "feature.txt", "feature\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add feature")
+	runIn(t, dir, "tag", "-a", "v1.1", "-m", "Release 1.1")
+
+	g := NewGitExtractor(dir)
+	tags, err := g.GetTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags, got %+v", tags)
+	}
+	byName := map[string]TagInfo{}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+	head, _ := g.ResolveRef("HEAD")
+	if v := byName["v1.1"]; !v.Annotated || v.Commit != head || v.Subject != "Release 1.1" {
+		t.Fatalf("annotated tag: %+v", v)
+	}
+	if v := byName["v1.0"]; v.Annotated || v.Commit == head || v.Commit == "" {
+		t.Fatalf("lightweight tag: %+v", v)
+	}
+
+	res, err := g.EnsureLocalBranch("v1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.RefKind != "tag" || res.ResolvedLocalBranch != "v1.0" || res.Localized {
+		t.Fatalf("tag resolution: %+v", res)
+	}
+	if res, err := g.EnsureLocalBranch(head[:10]); err != nil || res.RefKind != "commit" {
+		t.Fatalf("commit resolution: %+v, %v", res, err)
+	}
+	if _, err := g.EnsureLocalBranch("no-such-ref"); err == nil {
+		t.Fatal("expected an error for an unknown ref")
+	}
+
+	diffs, err := g.GetBranchDiffWithOptions("v1.0", "v1.1", BranchModeTriple, DiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].NewFile != "feature.txt" {
+		t.Fatalf("tag diff: %+v", diffs)
+	}
+
+	if _, err := g.SwitchBranch("v1.0", SwitchBranchOptions{}); err == nil {
+		t.Fatal("switching to a tag should fail")
+	}
+}
@@ -0,0 +1,136 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// CommentSyntax returns the comment opener and closer for path's language,
+// falling back to "//" for unknown extensions.
+func CommentSyntax(path string) (open, close string) {
+	base := strings.ToLower(filepath.Base(path))
+	switch base {
+	case "makefile", "dockerfile", "gemfile", "rakefile":
+		return "#", ""
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".py", ".rb", ".sh", ".bash", ".zsh", ".yaml", ".yml", ".toml", ".r", ".pl", ".ex", ".exs", ".tf", ".ps1", ".nix", ".conf", ".ini", ".mk":
+		return "#", ""
+	case ".sql", ".lua", ".hs", ".elm", ".ada":
+		return "--", ""
+	case ".html", ".htm", ".xml", ".md", ".vue", ".svelte", ".svg":
+		return "<!--", " -->"
+	case ".css":
+		return "/*", " */"
+	case ".lisp", ".clj", ".el", ".scm":
+		return ";;", ""
+	case ".erl", ".tex":
+		return "%", ""
+	case ".vim":
+		return "\"", ""
+	}
+	return "//", ""
+}
+
+// TeachingComment renders message as a NOTE comment for path, indented like
+// the code line it explains.
+func TeachingComment(path, codeLine, message string) string {
+	open, close := CommentSyntax(path)
+	indent := codeLine[:len(codeLine)-len(strings.TrimLeft(codeLine, " \t"))]
+	message = strings.Join(strings.Fields(message), " ")
+	return indent + open + " NOTE: " + message + close
+}
+
+// WithTeachingComments returns a copy of diffs with each annotation inserted
+// as an added NOTE comment line directly above the line it refers to. Hunk
+// ranges and new line numbers are renumbered, so ToUnified produces a patch
+// that applies like the original change.
+func WithTeachingComments(diffs []ParsedDiff, notes []Annotation) []ParsedDiff {
+	index := annotationIndex(notes)
+	out := make([]ParsedDiff, len(diffs))
+	for i, d := range diffs {
+		if d.IsDeleted {
+			out[i] = d
+			continue
+		}
+		shift := 0
+		hunks := make([]ParsedHunk, len(d.Hunks))
+		for j, h := range d.Hunks {
+			// An empty new side starts at the line before the hunk.
+			first := h.NewStart
+			if h.NewLines == 0 {
+				first++
+			}
+			lines := make([]ParsedLine, 0, len(h.Lines))
+			inserted := 0
+			for _, l := range h.Lines {
+				for _, a := range lineAnnotations(index, d, l) {
+					n := first + shift + countNewLines(lines)
+					lines = append(lines, ParsedLine{Type: LineAdd, Content: TeachingComment(d.NewFile, l.Content, a.Message), NewLineNumber: &n})
+					inserted++
+				}
+				if l.NewLineNumber != nil {
+					n := *l.NewLineNumber + shift + inserted
+					l.NewLineNumber = &n
+				}
+				lines = append(lines, l)
+			}
+			if inserted > 0 {
+				h.NewStart = first
+			}
+			h.NewStart += shift
+			h.NewLines += inserted
+			h.Header = renumberHunkHeader(h)
+			h.Lines = lines
+			hunks[j] = h
+			shift += inserted
+			d.Additions += inserted
+		}
+		d.Hunks = hunks
+		out[i] = d
+	}
+	return out
+}
+
+// countNewLines counts the lines that exist on the new side.
+func countNewLines(lines []ParsedLine) int {
+	n := 0
+	for _, l := range lines {
+		if l.Type != LineDelete {
+			n++
+		}
+	}
+	return n
+}
+
+func renumberHunkHeader(h ParsedHunk) string {
+	section := ""
+	if m := hunkRe.FindStringSubmatch(h.Header); m != nil {
+		section = m[5]
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", h.OldStart, h.OldLines, h.NewStart, h.NewLines, section)
+}
+
+// InsertTeachingComments adds NOTE comments for the new-side annotations of
+// path above the lines of content they refer to.
+func InsertTeachingComments(path, content string, notes []Annotation) string {
+	byLine := map[int][]string{}
+	for _, a := range notes {
+		if a.File == path && a.Side != SideOld {
+			byLine[a.Line] = append(byLine[a.Line], a.Message)
+		}
+	}
+	if len(byLine) == 0 {
+		return content
+	}
+	lines := strings.SplitAfter(content, "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		for _, msg := range byLine[i+1] {
+			b.WriteString(TeachingComment(path, line, msg) + "\n")
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
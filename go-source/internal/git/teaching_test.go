@@ -0,0 +1,53 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+const teachingDiff = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@ package main
+ func main() {
+-	run()
++	if err := run(); err != nil {
++		panic(err)
++	}
+@@ -10,2 +11,2 @@ func run() error {
+-	return nil
++	return errDone
+ }
+`
+
+func TestWithTeachingComments(t *testing.T) {
+	diffs := NewDiffParser().Parse(teachingDiff)
+	notes := []Annotation{
+		{File: "main.go", Side: SideNew, Line: 2, Message: "Checking the error\nkeeps failures visible."},
+		{File: "main.go", Side: SideNew, Line: 11, Message: "A sentinel error lets callers compare."},
+	}
+	patch := NewDiffFormatter().ToUnified(WithTeachingComments(diffs, notes))
+	for _, want := range []string{
+		"@@ -1,3 +1,5 @@ package main",
+		"+\t// NOTE: Checking the error keeps failures visible.\n+\tif err := run(); err != nil {",
+		"@@ -10,2 +12,3 @@ func run() error {",
+		"+\t// NOTE: A sentinel error lets callers compare.\n+\treturn errDone",
+	} {
+		if !strings.Contains(patch, want) {
+			t.Fatalf("expected %q in patch:\n%s", want, patch)
+		}
+	}
+	reparsed := NewDiffParser().Parse(patch)
+	if got := reparsed[0].Hunks[1].Lines[2]; got.NewLineNumber == nil || *got.NewLineNumber != 13 || got.Content != "\treturn errDone" {
+		t.Fatalf("expected renumbered lines after the notes, got %+v", got)
+	}
+}
+
+func TestInsertTeachingComments(t *testing.T) {
+	content := "def run():\n    return 1\n"
+	got := InsertTeachingComments("app.py", content, []Annotation{{File: "app.py", Line: 2, Message: "Returns a constant."}, {File: "other.py", Line: 1, Message: "ignored"}})
+	want := "def run():\n    # NOTE: Returns a constant.\n    return 1\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
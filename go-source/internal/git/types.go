@@ -32,8 +32,38 @@ type ParsedDiff struct {
 	IsNew     bool         `json:"isNew"`
 	IsDeleted bool         `json:"isDeleted"`
 	IsRenamed bool         `json:"isRenamed"`
-	Additions int          `json:"additions"`
-	Deletions int          `json:"deletions"`
+	IsCopied  bool         `json:"isCopied"`
+	// Similarity is git's similarity index for renames and copies.
+	Similarity int `json:"similarity,omitempty"`
+	// OldMode and NewMode are set for mode changes and added/deleted files.
+	OldMode   string `json:"oldMode,omitempty"`
+	NewMode   string `json:"newMode,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	// Submodule is set when the entry is a gitlink pointer change.
+	Submodule *SubmoduleChange `json:"submodule,omitempty"`
+	// Binary describes both sides of a binary file change.
+	Binary *BinaryInfo `json:"binary,omitempty"`
+	// Scope says where a local change lives; only DiffOptions.All sets it.
+	Scope ChangeScope `json:"scope,omitempty"`
+}
+
+type BinaryInfo struct {
+	// OldBlob and NewBlob are the (possibly abbreviated) object ids from the
+	// index line; empty for the missing side of an added or deleted file.
+	OldBlob  string `json:"oldBlob,omitempty"`
+	NewBlob  string `json:"newBlob,omitempty"`
+	OldSize  int64  `json:"oldSize"`
+	NewSize  int64  `json:"newSize"`
+	MimeType string `json:"mimeType,omitempty"`
+	IsImage  bool   `json:"isImage"`
+}
+
+type SubmoduleChange struct {
+	OldCommit string `json:"oldCommit,omitempty"`
+	NewCommit string `json:"newCommit,omitempty"`
+	// Dirty means the submodule checkout has uncommitted changes.
+	Dirty bool `json:"dirty,omitempty"`
 }
 
 type DiffStats struct {
@@ -42,12 +72,46 @@ type DiffStats struct {
 	Deletions int `json:"deletions"`
 }
 
+type FileStat struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"oldPath,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	IsBinary  bool   `json:"isBinary"`
+}
+
 type CommitInfo struct {
 	Hash    string   `json:"hash"`
 	Date    string   `json:"date"`
 	Message string   `json:"message"`
 	Author  string   `json:"author"`
 	Files   []string `json:"files"`
+	// FileCount is the number of changed files when a paged listing cut
+	// Files short, and zero otherwise.
+	FileCount int `json:"fileCount,omitempty"`
+	// Additions and Deletions are the lines the commit changed; Size and
+	// Type label it for learners picking commits to study (CommitSize,
+	// GuessCommitType).
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Size      string `json:"size"`
+	Type      string `json:"type,omitempty"`
+	// Body is the message after the subject line; only
+	// GetCommitsWithBodies fills it in.
+	Body string `json:"body,omitempty"`
+}
+
+type FileRevision struct {
+	Commit CommitInfo   `json:"commit"`
+	Diffs  []ParsedDiff `json:"diffs"`
+}
+
+type StashEntry struct {
+	Index   int    `json:"index"`
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+	Date    string `json:"date"`
+	Commit  string `json:"commit"`
 }
 
 type BranchInfo struct {
@@ -88,6 +152,8 @@ type EnsureBranchResult struct {
 	WasRemote           bool    `json:"wasRemote"`
 	RemoteRef           *string `json:"remoteRef"`
 	Message             string  `json:"message,omitempty"`
+	// RefKind is "tag" or "commit" when the input was not a branch.
+	RefKind string `json:"refKind,omitempty"`
 }
 
 type SwitchBranchOptions struct {
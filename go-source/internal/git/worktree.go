@@ -0,0 +1,23 @@
+package git
+
+// AddWorktree checks out ref, detached, into a new linked worktree at dir,
+// which must be missing or empty.
+func (g *GitExtractor) AddWorktree(dir, ref string) error {
+	_, err := g.run("worktree", "add", "--detach", "--quiet", dir, ref)
+	return err
+}
+
+// RemoveWorktree deletes a worktree made by AddWorktree, along with any
+// changes in it.
+func (g *GitExtractor) RemoveWorktree(dir string) error {
+	_, err := g.run("worktree", "remove", "--force", dir)
+	return err
+}
+
+// IntendToAdd marks untracked files with git add --intent-to-add, so that
+// diffs of the working tree against a commit show them as added files, or
+// as changes to the commit's version when it has one.
+func (g *GitExtractor) IntendToAdd() error {
+	_, err := g.run("add", "--intent-to-add", "--", ".")
+	return err
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorktreeAttempt(t *testing.T) {
+	dir := initTempRepo(t)
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, dir, "util.go", "package main\n\nfunc helper() int { return 1 }\n")
+	runIn(t, dir, "add", ".")
+	runIn(t, dir, "commit", "-q", "-m", "add helper")
+
+	g := NewGitExtractor(dir)
+	sha, err := g.ResolveRef("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt := filepath.Join(t.TempDir(), "attempt")
+	if err := g.AddWorktree(wt, sha+"^"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "util.go")); !os.IsNotExist(err) {
+		t.Fatalf("worktree should start at the parent, util.go: %v", err)
+	}
+
+	// The attempt gets main.go right and writes util.go differently.
+	writeFile(t, wt, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, wt, "util.go", "package main\n\nfunc helper() int { return 2 }\n")
+	attempt := NewGitExtractor(wt)
+	if err := attempt.IntendToAdd(); err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := attempt.GetLocalDiff(DiffOptions{Against: sha})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].NewFile != "util.go" || diffs[0].IsNew || diffs[0].Additions != 1 || diffs[0].Deletions != 1 {
+		t.Fatalf("attempt against the commit = %+v", diffs)
+	}
+
+	if err := g.RemoveWorktree(wt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(wt); !os.IsNotExist(err) {
+		t.Fatalf("worktree still there: %v", err)
+	}
+}
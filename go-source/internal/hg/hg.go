@@ -0,0 +1,219 @@
+// Package hg reads changes from Mercurial repositories through the hg
+// command. `hg diff --git` writes the format the git package parses, so
+// the formatter, the prompts and the story view take its diffs unchanged.
+package hg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/git"
+)
+
+// ExecRunner runs the hg binary. It has the signature of git.GitRunner, so
+// a git.FakeRunner can stand in for it in tests.
+type ExecRunner struct{}
+
+func (ExecRunner) Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("hg", args...)
+	cmd.Dir = dir
+	// HGPLAIN turns off the user's aliases, colors, pager and localized
+	// output, which would end up in the parsed text.
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("hg is not installed or not on PATH")
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("hg %s: %s", strings.Join(args, " "), msg)
+	}
+	return out.String(), nil
+}
+
+// Find returns the root of the Mercurial repository containing path: the
+// nearest directory with a .hg directory in it.
+func Find(path string) (string, bool) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ".hg")); err == nil && info.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Repo is a Mercurial repository.
+type Repo struct {
+	root   string
+	runner git.GitRunner
+}
+
+// Open returns the Mercurial repository containing path.
+func Open(path string) (*Repo, error) {
+	root, ok := Find(path)
+	if !ok {
+		return nil, fmt.Errorf("not a Mercurial repository (no .hg directory in %s or above)", path)
+	}
+	return NewRepo(root, ExecRunner{}), nil
+}
+
+// NewRepo is Open for a known root with a custom runner.
+func NewRepo(root string, runner git.GitRunner) *Repo {
+	return &Repo{root: root, runner: runner}
+}
+
+// Root is the working directory root.
+func (r *Repo) Root() string {
+	return r.root
+}
+
+func (r *Repo) diff(args ...string) ([]git.ParsedDiff, error) {
+	out, err := r.runner.Run(r.root, append([]string{"diff", "--git"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return git.NewDiffParser().Parse(out), nil
+}
+
+// Diff returns the uncommitted changes when rev is empty, otherwise the
+// changes of changeset rev.
+func (r *Repo) Diff(rev string) ([]git.ParsedDiff, error) {
+	if rev == "" {
+		return r.diff()
+	}
+	return r.diff("-c", rev)
+}
+
+// DiffRange returns the difference between two revisions; an empty to is
+// the working directory.
+func (r *Repo) DiffRange(from, to string) ([]git.ParsedDiff, error) {
+	if from == "" {
+		from = "."
+	}
+	if to == "" {
+		return r.diff("-r", from)
+	}
+	return r.diff("-r", from, "-r", to)
+}
+
+// BranchDiff returns what branch changed since it diverged from the
+// working directory's parent, like git's main...branch.
+func (r *Repo) BranchDiff(branch string) ([]git.ParsedDiff, error) {
+	return r.diff("-r", "ancestor(., "+quoteRevset(branch)+")", "-r", quoteRevset(branch))
+}
+
+// quoteRevset makes a branch or bookmark name safe inside a revset.
+func quoteRevset(name string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(name, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// Changeset is one commit of a Mercurial repository.
+type Changeset struct {
+	Node    string    `json:"node"`
+	Rev     int       `json:"rev"`
+	Branch  string    `json:"branch"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Time    time.Time `json:"time"`
+	Subject string    `json:"subject"`
+}
+
+// Commit describes the changeset as a git commit, for code that shows
+// commits through the git package's types.
+func (c Changeset) Commit() git.CommitInfo {
+	return git.CommitInfo{Hash: c.Node, Date: c.Time.Format(time.RFC3339), Message: c.Subject, Author: c.Author, Files: []string{}}
+}
+
+// changesetTemplate prints one tab-separated line per changeset; the
+// subject comes last since it is the only field that may hold a tab.
+const changesetTemplate = `{node}\t{rev}\t{branch}\t{author|person}\t{author|email}\t{date|hgdate}\t{desc|firstline}\n`
+
+// Log lists the changesets of revset, newest first, at most limit of them
+// when limit is positive.
+func (r *Repo) Log(revset string, limit int) ([]Changeset, error) {
+	args := []string{"log", "-r", revset, "-T", changesetTemplate}
+	if limit > 0 {
+		args = append(args, "--limit", strconv.Itoa(limit))
+	}
+	out, err := r.runner.Run(r.root, args...)
+	if err != nil {
+		return nil, err
+	}
+	changesets := make([]Changeset, 0)
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.SplitN(line, "\t", 7)
+		if len(f) < 7 {
+			continue
+		}
+		c := Changeset{Node: f[0], Branch: f[2], Author: f[3], Email: f[4], Subject: f[6]}
+		c.Rev, _ = strconv.Atoi(f[1])
+		// hgdate is "<unix seconds> <offset>".
+		if secs, err := strconv.ParseInt(strings.Fields(f[5] + " 0")[0], 10, 64); err == nil {
+			c.Time = time.Unix(secs, 0)
+		}
+		changesets = append(changesets, c)
+	}
+	sort.SliceStable(changesets, func(i, j int) bool { return changesets[i].Rev > changesets[j].Rev })
+	return changesets, nil
+}
+
+// Branches lists bookmarks and open named branches as git branches, so
+// they can be shown and compared the same way. A bookmark hides a named
+// branch of the same name, as it does in revsets.
+func (r *Repo) Branches() ([]git.BranchInfo, error) {
+	bookmarks, err := r.runner.Run(r.root, "bookmarks", "-T", `{bookmark}\t{node}\t{active}\n`)
+	if err != nil {
+		return nil, err
+	}
+	named, err := r.runner.Run(r.root, "branches", "-T", `{branch}\t{node}\n`)
+	if err != nil {
+		return nil, err
+	}
+	current, err := r.runner.Run(r.root, "branch")
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]git.BranchInfo, 0)
+	seen := map[string]bool{}
+	activeBookmark := false
+	for _, line := range strings.Split(bookmarks, "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) < 3 || f[0] == "" {
+			continue
+		}
+		active := f[2] == "True"
+		activeBookmark = activeBookmark || active
+		seen[f[0]] = true
+		branches = append(branches, git.BranchInfo{Name: f[0], Commit: f[1], Current: active})
+	}
+	for _, line := range strings.Split(named, "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) < 2 || f[0] == "" || seen[f[0]] {
+			continue
+		}
+		branches = append(branches, git.BranchInfo{Name: f[0], Commit: f[1], Current: !activeBookmark && f[0] == strings.TrimSpace(current)})
+	}
+	sort.SliceStable(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
@@ -0,0 +1,139 @@
+package hg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"difflearn-go/internal/git"
+)
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	for _, dir := range []string{sub, filepath.Join(root, ".hg")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if found, ok := Find(sub); !ok || found != root {
+		t.Fatalf("Find(%s) = %q, %v", sub, found, ok)
+	}
+	if _, ok := Find(t.TempDir()); ok {
+		t.Fatal("found a Mercurial repository where there is none")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	patch := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old()\n+new()\n"
+	runner := git.NewFakeRunner().
+		On(patch, "diff", "--git").
+		On(patch, "diff", "--git", "-c", "42").
+		On(patch, "diff", "--git", "-r", "1.0").
+		On(patch, "diff", "--git", "-r", `ancestor(., "feature \"x\"")`, "-r", `"feature \"x\""`)
+	r := NewRepo(t.TempDir(), runner)
+	for name, load := range map[string]func() ([]git.ParsedDiff, error){
+		"working directory": func() ([]git.ParsedDiff, error) { return r.Diff("") },
+		"changeset":         func() ([]git.ParsedDiff, error) { return r.Diff("42") },
+		"range":             func() ([]git.ParsedDiff, error) { return r.DiffRange("1.0", "") },
+		"branch":            func() ([]git.ParsedDiff, error) { return r.BranchDiff(`feature "x"`) },
+	} {
+		diffs, err := load()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(diffs) != 1 || diffs[0].NewFile != "main.go" || diffs[0].Additions != 1 {
+			t.Fatalf("%s: unexpected diff %+v", name, diffs)
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+	out := "aaa111\t3\tdefault\tAda\tada@example.com\t1700000000 -3600\tAdd parser\n" +
+		"bbb222\t7\tstable\tBob\tbob@example.com\t1700100000 0\tFix\ttabs\n"
+	runner := git.NewFakeRunner().On(out, "log", "-r", "::.", "-T", changesetTemplate, "--limit", "2")
+	changesets, err := NewRepo(t.TempDir(), runner).Log("::.", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changesets) != 2 || changesets[0].Rev != 7 || changesets[1].Rev != 3 {
+		t.Fatalf("want newest first: %+v", changesets)
+	}
+	if c := changesets[0]; c.Subject != "Fix\ttabs" || c.Branch != "stable" || c.Email != "bob@example.com" {
+		t.Fatalf("unexpected changeset: %+v", c)
+	}
+	if !changesets[1].Time.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("time %v", changesets[1].Time)
+	}
+	if c := changesets[1].Commit(); c.Hash != "aaa111" || c.Message != "Add parser" || c.Author != "Ada" {
+		t.Fatalf("unexpected commit: %+v", c)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	runner := git.NewFakeRunner().
+		On("feature\tccc\tFalse\nstable\tddd\tFalse\n", "bookmarks", "-T", `{bookmark}\t{node}\t{active}\n`).
+		On("default\taaa\nstable\tbbb\n", "branches", "-T", `{branch}\t{node}\n`).
+		On("default\n", "branch")
+	branches, err := NewRepo(t.TempDir(), runner).Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []git.BranchInfo{
+		{Name: "default", Commit: "aaa", Current: true},
+		{Name: "feature", Commit: "ccc"},
+		{Name: "stable", Commit: "ddd"},
+	}
+	if len(branches) != len(want) {
+		t.Fatalf("got %+v", branches)
+	}
+	for i := range want {
+		if branches[i] != want[i] {
+			t.Fatalf("branch %d: got %+v, want %+v", i, branches[i], want[i])
+		}
+	}
+}
+
+func TestRepository(t *testing.T) {
+	patch := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old()\n+new()\n"
+	graph := "@  \x1ebbb\x1faaa " + nullNode + "\x1fmain\x1f2024-01-02T03:04:05+00:00\x1fBob\x1fFix\n" +
+		"|\n" +
+		"o  \x1eaaa\x1f" + nullNode + " " + nullNode + "\x1f\x1f2024-01-01T00:00:00+00:00\x1fAda\x1fStart\n"
+	runner := git.NewFakeRunner().
+		On(patch, "diff", "--git", "-w", "-r", ".").
+		On(patch, "diff", "--git", "-c", "42", "--", "path:src/main.go").
+		On(patch, "diff", "--git", "-r", `ancestor(., "feature")`, "-r", `"feature"`).
+		On(graph, "log", "-G", "-r", "reverse(all())", "-T", graphTemplate, "--limit", "50").
+		On("bbb\n", "log", "-r", ".", "-T", `{node}\n`, "--limit", "1")
+	var r git.Repository = NewRepo(t.TempDir(), runner)
+
+	if diffs, err := r.GetLocalDiff(git.DiffOptions{Staged: true}); err != nil || len(diffs) != 0 {
+		t.Fatalf("Mercurial has no staged changes: %+v, %v", diffs, err)
+	}
+	for name, load := range map[string]func() ([]git.ParsedDiff, error){
+		"local": func() ([]git.ParsedDiff, error) {
+			return r.GetLocalDiff(git.DiffOptions{Against: "HEAD", Whitespace: git.WhitespaceOptions{IgnoreWhitespace: true}})
+		},
+		"file":   func() ([]git.ParsedDiff, error) { return r.GetFileDiff("src/main.go", "42") },
+		"branch": func() ([]git.ParsedDiff, error) { return r.GetBranchDiff("HEAD", "feature") },
+	} {
+		if diffs, err := load(); err != nil || len(diffs) != 1 {
+			t.Fatalf("%s: %+v, %v", name, diffs, err)
+		}
+	}
+
+	g, err := r.GetCommitGraph(0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Commits) != 2 || len(g.Rows) != 3 || g.Commits[0].Refs[0] != "main" {
+		t.Fatalf("unexpected graph %+v", g)
+	}
+	if p := g.Commits[0].Parents; len(p) != 1 || p[0] != "aaa" || len(g.Commits[1].Parents) != 0 {
+		t.Fatalf("null parents should be dropped: %+v", g.Commits)
+	}
+	if node, err := r.ResolveRef("HEAD"); err != nil || node != "bbb" {
+		t.Fatalf("ResolveRef(HEAD) = %q, %v", node, err)
+	}
+}
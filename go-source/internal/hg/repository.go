@@ -0,0 +1,169 @@
+package hg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// Repo implements git.Repository, so the dashboard and the AI commands read
+// a Mercurial repository as they read a git one. Mercurial has no index:
+// every uncommitted change is unstaged.
+var _ git.Repository = (*Repo)(nil)
+
+// nullNode is the parent Mercurial reports for a missing one.
+const nullNode = "0000000000000000000000000000000000000000"
+
+// graphTemplate writes the layout of git.ParseCommitGraph after hg's graph.
+const graphTemplate = "\x1e{node}\x1f{p1node} {p2node}\x1f{join(bookmarks, ', ')}\x1f{date|rfc3339date}\x1f{author|person}\x1f{desc|firstline}\\n"
+
+// revision translates git's HEAD, which callers use for the current
+// commit, to the working directory's parent.
+func revision(ref string) string {
+	if ref == "HEAD" {
+		return "."
+	}
+	return ref
+}
+
+// branchRevset is revision for branch and bookmark names, quoted for a
+// revset.
+func branchRevset(name string) string {
+	if name == "HEAD" {
+		return "."
+	}
+	return quoteRevset(name)
+}
+
+// RepoPath is the repository root.
+func (r *Repo) RepoPath() string {
+	return r.root
+}
+
+// IsRepo reports whether hg can read the repository.
+func (r *Repo) IsRepo() bool {
+	_, err := r.runner.Run(r.root, "root")
+	return err == nil
+}
+
+// GetLocalDiff returns the uncommitted changes, or their difference from
+// options.Against. Staged changes are always empty.
+func (r *Repo) GetLocalDiff(options git.DiffOptions) ([]git.ParsedDiff, error) {
+	if options.Staged && !options.All {
+		return []git.ParsedDiff{}, nil
+	}
+	var args []string
+	if options.Whitespace.IgnoreWhitespace {
+		args = append(args, "-w")
+	}
+	if options.Whitespace.IgnoreSpaceChange {
+		args = append(args, "-b")
+	}
+	if options.Whitespace.IgnoreBlankLines {
+		args = append(args, "-B")
+	}
+	if options.Against != "" && !options.All {
+		args = append(args, "-r", revision(options.Against))
+	}
+	return r.diff(args...)
+}
+
+// GetFileDiff returns the uncommitted changes of one file, or its changes
+// in changeset commit.
+func (r *Repo) GetFileDiff(filePath, commit string) ([]git.ParsedDiff, error) {
+	var args []string
+	if commit != "" {
+		args = append(args, "-c", revision(commit))
+	}
+	// path: patterns are relative to the root, not globs.
+	return r.diff(append(args, "--", "path:"+filePath)...)
+}
+
+// GetCommitDiff returns the changes of commit1 when commit2 is empty,
+// otherwise the difference between the two.
+func (r *Repo) GetCommitDiff(commit1, commit2 string) ([]git.ParsedDiff, error) {
+	if commit2 == "" {
+		return r.Diff(revision(commit1))
+	}
+	return r.diff("-r", revision(commit1), "-r", revision(commit2))
+}
+
+// GetBranchDiff returns what branch2 changed since it diverged from
+// branch1, or with BranchModeDouble the difference between the two.
+func (r *Repo) GetBranchDiff(branch1, branch2 string, mode ...git.BranchDiffMode) ([]git.ParsedDiff, error) {
+	from, to := branchRevset(branch1), branchRevset(branch2)
+	if len(mode) == 0 || mode[0] != git.BranchModeDouble {
+		from = "ancestor(" + from + ", " + to + ")"
+	}
+	return r.diff("-r", from, "-r", to)
+}
+
+// GetCommitHistory lists the newest limit ancestors of the working
+// directory.
+func (r *Repo) GetCommitHistory(limit int) ([]git.CommitInfo, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	changesets, err := r.Log("reverse(::.)", limit)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]git.CommitInfo, len(changesets))
+	for i, c := range changesets {
+		commits[i] = c.Commit()
+	}
+	return commits, nil
+}
+
+// GetBranchesDetailed lists the bookmarks and open named branches.
+func (r *Repo) GetBranchesDetailed() ([]git.BranchEntry, error) {
+	branches, err := r.Branches()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]git.BranchEntry, len(branches))
+	for i, b := range branches {
+		entries[i] = git.BranchEntry{Name: b.Name, Ref: b.Name, Kind: git.BranchKindLocal, Current: b.Current, LocalName: b.Name, Commit: b.Commit}
+	}
+	return entries, nil
+}
+
+// GetCommitGraph returns the newest limit changesets, of every branch with
+// allBranches and otherwise the ancestors of the working directory, with
+// the rows of hg's graph.
+func (r *Repo) GetCommitGraph(limit int, allBranches bool) (git.CommitGraph, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	revset := "reverse(::.)"
+	if allBranches {
+		revset = "reverse(all())"
+	}
+	out, err := r.runner.Run(r.root, "log", "-G", "-r", revset, "-T", graphTemplate, "--limit", strconv.Itoa(limit))
+	if err != nil {
+		return git.CommitGraph{}, err
+	}
+	graph := git.ParseCommitGraph(out)
+	for i, c := range graph.Commits {
+		parents := make([]string, 0, len(c.Parents))
+		for _, p := range c.Parents {
+			if p != nullNode {
+				parents = append(parents, p)
+			}
+		}
+		graph.Commits[i].Parents = parents
+	}
+	return graph, nil
+}
+
+// ResolveRef returns the node of a revision.
+func (r *Repo) ResolveRef(ref string) (string, error) {
+	out, err := r.runner.Run(r.root, "log", "-r", revision(ref), "-T", `{node}\n`, "--limit", "1")
+	node := strings.TrimSpace(out)
+	if err != nil || node == "" {
+		return "", fmt.Errorf("unknown revision %q", ref)
+	}
+	return node, nil
+}
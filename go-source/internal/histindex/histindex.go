@@ -0,0 +1,296 @@
+// Package histindex keeps the commit metadata of a repository in the data
+// directory and brings it up to date incrementally, so history search,
+// hotspots and contributor statistics query a database instead of running
+// git log over the whole history every time.
+package histindex
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+)
+
+// Index is the history index of one repository: an SQLite database of its
+// commits and the files they touched, with the branch tips they were read
+// up to.
+type Index struct {
+	g    *git.GitExtractor
+	path string
+	db   *sql.DB
+}
+
+// schema indexes the columns queries filter on: commit time, author and
+// email, and file paths.
+const schema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS commits (
+	seq     INTEGER PRIMARY KEY,
+	hash    TEXT NOT NULL UNIQUE,
+	parents TEXT NOT NULL,
+	time    INTEGER NOT NULL,
+	author  TEXT NOT NULL COLLATE NOCASE,
+	email   TEXT NOT NULL COLLATE NOCASE,
+	subject TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS commits_time ON commits(time);
+CREATE INDEX IF NOT EXISTS commits_author ON commits(author);
+CREATE INDEX IF NOT EXISTS commits_email ON commits(email);
+CREATE TABLE IF NOT EXISTS files (
+	commit_seq INTEGER NOT NULL REFERENCES commits(seq),
+	path       TEXT NOT NULL,
+	old_path   TEXT NOT NULL,
+	additions  INTEGER NOT NULL,
+	deletions  INTEGER NOT NULL,
+	binary     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS files_path ON files(path);
+CREATE INDEX IF NOT EXISTS files_commit ON files(commit_seq);
+`
+
+// state is what the next Update starts from.
+type state struct {
+	Tips    []string
+	Updated time.Time
+}
+
+// Dir holds the indexes of every repository.
+func Dir() string {
+	return filepath.Join(config.DataDir(), "history-index")
+}
+
+// Open returns the index of g's repository. The database is opened on
+// first use; Close releases it.
+func Open(g *git.GitExtractor) *Index {
+	root := g.RepoPath()
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	sum := sha256.Sum256([]byte(root))
+	name := filepath.Base(root) + "-" + hex.EncodeToString(sum[:4])
+	return &Index{g: g, path: filepath.Join(Dir(), name+".db")}
+}
+
+// Path is the database file.
+func (ix *Index) Path() string {
+	return ix.path
+}
+
+// Close closes the database.
+func (ix *Index) Close() error {
+	if ix.db == nil {
+		return nil
+	}
+	err := ix.db.Close()
+	ix.db = nil
+	return err
+}
+
+// conn opens the database, creating it when needed.
+func (ix *Index) conn() (*sql.DB, error) {
+	if ix.db != nil {
+		return ix.db, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", ix.path)
+	if err != nil {
+		return nil, err
+	}
+	// One connection keeps the pragmas and lets a second process wait for
+	// the lock instead of failing.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA busy_timeout = 5000; PRAGMA journal_mode = WAL;" + schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	// Earlier versions kept the index as JSON Lines next to it.
+	legacy := strings.TrimSuffix(ix.path, ".db")
+	_ = os.Remove(legacy + ".jsonl")
+	_ = os.Remove(legacy + ".json")
+	ix.db = db
+	return db, nil
+}
+
+// Update reads the commits made since the last update, on any branch, and
+// returns how many it added. When commits it holds are no longer reachable
+// from the refs, after a rebase, a force push or a deleted branch, or the
+// tips it started from are gone, the index is rebuilt so rewritten commits
+// are not counted twice.
+func (ix *Index) Update() (int, error) {
+	tips, err := ix.g.GetRefTips()
+	if err != nil {
+		return 0, err
+	}
+	st, err := ix.readState()
+	if err != nil {
+		return 0, err
+	}
+	if len(tips) == 0 || sameTips(tips, st.Tips) {
+		return 0, nil
+	}
+	if len(st.Tips) > 0 {
+		if stale, err := ix.g.HasCommitsOutside(st.Tips, tips); err != nil || stale {
+			return ix.rebuild(tips)
+		}
+	}
+	commits, err := ix.g.GetCommitStats(tips, st.Tips)
+	if err != nil {
+		return 0, err
+	}
+	return len(commits), ix.store(commits, tips, false)
+}
+
+// Rebuild discards the index and reads the whole history again.
+func (ix *Index) Rebuild() (int, error) {
+	tips, err := ix.g.GetRefTips()
+	if err != nil {
+		return 0, err
+	}
+	return ix.rebuild(tips)
+}
+
+func (ix *Index) rebuild(tips []string) (int, error) {
+	commits, err := ix.g.GetCommitStats(tips, nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(commits), ix.store(commits, tips, true)
+}
+
+// Commits returns every indexed commit, newest first.
+func (ix *Index) Commits() ([]git.CommitStats, error) {
+	return ix.Search(Query{})
+}
+
+// Updated returns when the index was last brought up to date and how many
+// commits it holds; the time is zero before the first update.
+func (ix *Index) Updated() (time.Time, int) {
+	st, err := ix.readState()
+	if err != nil {
+		return time.Time{}, 0
+	}
+	var n int
+	_ = ix.db.QueryRow("SELECT COUNT(*) FROM commits").Scan(&n)
+	return st.Updated, n
+}
+
+// store adds commits oldest first, so commits of the same second keep
+// git's order when read back newest first, and records the tips they were
+// read up to, all in one transaction: an interrupted update leaves the
+// index as it was.
+func (ix *Index) store(commits []git.CommitStats, tips []string, replace bool) error {
+	db, err := ix.conn()
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if replace {
+		if _, err := tx.Exec("DELETE FROM files; DELETE FROM commits"); err != nil {
+			return err
+		}
+	}
+	insertCommit, err := tx.Prepare("INSERT OR IGNORE INTO commits (hash, parents, time, author, email, subject) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer insertCommit.Close()
+	insertFile, err := tx.Prepare("INSERT INTO files (commit_seq, path, old_path, additions, deletions, binary) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer insertFile.Close()
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		res, err := insertCommit.Exec(c.Hash, strings.Join(c.Parents, " "), c.Time.Unix(), c.Author, c.Email, c.Subject)
+		if err != nil {
+			return err
+		}
+		// A commit already indexed keeps its files.
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		seq, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, f := range c.Files {
+			if _, err := insertFile.Exec(seq, f.Path, f.OldPath, f.Additions, f.Deletions, f.IsBinary); err != nil {
+				return err
+			}
+		}
+	}
+	tipsJSON, err := json.Marshal(tips)
+	if err != nil {
+		return err
+	}
+	for k, v := range map[string]string{
+		"repo":    ix.g.RepoPath(),
+		"tips":    string(tipsJSON),
+		"updated": time.Now().UTC().Format(time.RFC3339Nano),
+	} {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO meta (key, value) VALUES (?, ?)", k, v); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (ix *Index) readState() (state, error) {
+	var st state
+	db, err := ix.conn()
+	if err != nil {
+		return st, err
+	}
+	rows, err := db.Query("SELECT key, value FROM meta WHERE key IN ('tips', 'updated')")
+	if err != nil {
+		return st, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return st, err
+		}
+		switch k {
+		case "tips":
+			_ = json.Unmarshal([]byte(v), &st.Tips)
+		case "updated":
+			st.Updated, _ = time.Parse(time.RFC3339Nano, v)
+		}
+	}
+	return st, rows.Err()
+}
+
+func sameTips(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := map[string]bool{}
+	for _, t := range b {
+		set[t] = true
+	}
+	for _, t := range a {
+		if !set[t] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package histindex
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"difflearn-go/internal/git"
+)
+
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func commitFile(t *testing.T, dir, name, content, author, msg string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "add", ".")
+	gitIn(t, dir, "-c", "user.name="+author, "-c", "user.email="+strings.ToLower(author)+"@example.com", "commit", "-q", "-m", msg)
+}
+
+func TestUpdateIsIncremental(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q", "-b", "main")
+	commitFile(t, dir, "src/a.go", "a\n", "Ann", "add a")
+	commitFile(t, dir, "src/a.go", "a\nb\n", "Bob", "grow a")
+
+	ix := Open(git.NewGitExtractor(dir))
+	defer ix.Close()
+	if n, err := ix.Update(); err != nil || n != 2 {
+		t.Fatalf("first update: %d, %v", n, err)
+	}
+	if n, err := ix.Update(); err != nil || n != 0 {
+		t.Fatalf("an update without new commits should add none: %d, %v", n, err)
+	}
+
+	gitIn(t, dir, "checkout", "-q", "-b", "feature")
+	commitFile(t, dir, "docs/readme.md", "hi\n", "Ann", "docs: readme")
+	gitIn(t, dir, "checkout", "-q", "main")
+	commitFile(t, dir, "src/a.go", "a\nb\nc\n", "Ann", "fix a")
+	if n, err := ix.Update(); err != nil || n != 2 {
+		t.Fatalf("expected the commits of both branches, got %d, %v", n, err)
+	}
+
+	commits, err := ix.Commits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 4 {
+		t.Fatalf("expected 4 commits, got %d", len(commits))
+	}
+	if _, total := ix.Updated(); total != 4 {
+		t.Fatalf("state counts %d commits", total)
+	}
+
+	if found, err := ix.Search(Query{Text: "README"}); err != nil || len(found) != 1 || found[0].Subject != "docs: readme" {
+		t.Fatalf("search: %+v, %v", found, err)
+	}
+	if found, _ := ix.Search(Query{Author: "bob@"}); len(found) != 1 {
+		t.Fatalf("author search: %+v", found)
+	}
+	if found, _ := ix.Search(Query{Path: "src"}); len(found) != 3 {
+		t.Fatalf("path search: %+v", found)
+	}
+	if found, _ := ix.Search(Query{Path: "sr"}); len(found) != 0 {
+		t.Fatalf("a path prefix is not a directory: %+v", found)
+	}
+	if found, _ := ix.Search(Query{Since: time.Now().Add(time.Hour)}); len(found) != 0 {
+		t.Fatalf("since search: %+v", found)
+	}
+	if found, _ := ix.Search(Query{Text: "fix", Limit: 1}); len(found) != 1 || len(found[0].Files) != 1 || found[0].Files[0].Additions != 1 {
+		t.Fatalf("limited search: %+v", found)
+	}
+	if found, _ := ix.Search(Query{Limit: 2}); len(found) != 2 {
+		t.Fatalf("limited search: %+v", found)
+	}
+
+	spots, err := ix.Hotspots(Query{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spots) != 1 || spots[0].Path != "src/a.go" || spots[0].Commits != 3 || spots[0].Authors != 2 || spots[0].Additions != 3 {
+		t.Fatalf("hotspots: %+v", spots)
+	}
+	if spots, _ := ix.Hotspots(Query{Path: "docs"}); len(spots) != 1 || spots[0].Path != "docs/readme.md" {
+		t.Fatalf("hotspots under docs: %+v", spots)
+	}
+	people, err := ix.Contributors(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 2 || people[0].Name != "Ann" || people[0].Commits != 3 || people[1].Deletions != 0 {
+		t.Fatalf("contributors: %+v", people)
+	}
+}
+
+func TestUpdateRebuildsAfterRewrite(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q", "-b", "main")
+	commitFile(t, dir, "a.txt", "1\n", "Ann", "one")
+	commitFile(t, dir, "a.txt", "2\n", "Ann", "two")
+	ix := Open(git.NewGitExtractor(dir))
+	defer ix.Close()
+	if _, err := ix.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite main and drop the old tip from the object store.
+	gitIn(t, dir, "reset", "-q", "--hard", "HEAD~1")
+	commitFile(t, dir, "a.txt", "3\n", "Ann", "three")
+	gitIn(t, dir, "reflog", "expire", "--expire=now", "--all")
+	gitIn(t, dir, "gc", "-q", "--prune=now")
+
+	if n, err := ix.Update(); err != nil || n != 2 {
+		t.Fatalf("expected a rebuild with 2 commits, got %d, %v", n, err)
+	}
+	commits, _ := ix.Commits()
+	if len(commits) != 2 || commits[0].Subject != "three" {
+		t.Fatalf("unexpected commits after rebuild: %+v", commits)
+	}
+}
+
+func TestUpdateDropsAmendedCommits(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q", "-b", "main")
+	commitFile(t, dir, "a.txt", "1\n", "Ann", "one")
+	commitFile(t, dir, "a.txt", "2\n", "Ann", "two")
+	g := git.NewGitExtractor(dir)
+	old, err := g.ResolveRef("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix := Open(g)
+	defer ix.Close()
+	if _, err := ix.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The old commit stays in the object store, only no ref reaches it.
+	gitIn(t, dir, "-c", "user.name=Ann", "-c", "user.email=ann@example.com", "commit", "-q", "--amend", "-m", "two, amended")
+	if _, err := ix.Update(); err != nil {
+		t.Fatal(err)
+	}
+	commits, err := ix.Commits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "two, amended" {
+		t.Fatalf("unexpected commits after amend: %+v", commits)
+	}
+	for _, c := range commits {
+		if c.Hash == old {
+			t.Fatalf("the amended commit %s is still indexed", old)
+		}
+	}
+	if people, _ := ix.Contributors(Query{}); len(people) != 1 || people[0].Commits != 2 {
+		t.Fatalf("contributors count the amended commit: %+v", people)
+	}
+}
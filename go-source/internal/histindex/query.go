@@ -0,0 +1,252 @@
+package histindex
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/git"
+)
+
+// Query selects commits. Empty fields match everything; Text matches the
+// subject or hash, Author the name or email and Path a file or directory,
+// all case-insensitively except Path.
+type Query struct {
+	Text   string
+	Author string
+	Path   string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// where returns the conditions on the commits table, aliased c, that select
+// the commits of q, leaving out Path.
+func (q Query) where() (string, []any) {
+	conds := []string{"1"}
+	var args []any
+	if !q.Since.IsZero() {
+		conds = append(conds, "c.time >= ?")
+		args = append(args, unixSeconds(q.Since))
+	}
+	if !q.Until.IsZero() {
+		conds = append(conds, "c.time < ?")
+		args = append(args, unixSeconds(q.Until))
+	}
+	// LIKE ignores the case of ASCII letters.
+	if q.Text != "" {
+		conds = append(conds, `(c.subject LIKE ? ESCAPE '\' OR c.hash LIKE ? ESCAPE '\')`)
+		args = append(args, "%"+escapeLike(q.Text)+"%", escapeLike(strings.ToLower(q.Text))+"%")
+	}
+	if q.Author != "" {
+		conds = append(conds, `(c.author LIKE ? ESCAPE '\' OR c.email LIKE ? ESCAPE '\')`)
+		pattern := "%" + escapeLike(q.Author) + "%"
+		args = append(args, pattern, pattern)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// pathCond returns the condition on column that keeps the files under
+// q.Path, as a range the path index can serve, or "" when every file
+// matches.
+func (q Query) pathCond(column string) (string, []any) {
+	if q.Path == "" {
+		return "", nil
+	}
+	dir := strings.TrimSuffix(path.Clean(q.Path), "/")
+	if dir == "." {
+		return "", nil
+	}
+	// "0" follows "/", so the range holds exactly the paths below dir.
+	return "(" + column + " = ? OR (" + column + " > ? AND " + column + " < ?))", []any{dir, dir + "/", dir + "0"}
+}
+
+// commitFilter is where with the Path condition added as a subquery.
+func (q Query) commitFilter() (string, []any) {
+	where, args := q.where()
+	if cond, pathArgs := q.pathCond("f.path"); cond != "" {
+		where += " AND EXISTS (SELECT 1 FROM files f WHERE f.commit_seq = c.seq AND " + cond + ")"
+		args = append(args, pathArgs...)
+	}
+	return where, args
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// limit is q.Limit for SQL, where -1 means none.
+func limit(n int) int {
+	if n <= 0 {
+		return -1
+	}
+	return n
+}
+
+// Search returns the commits matching q, newest first.
+func (ix *Index) Search(q Query) ([]git.CommitStats, error) {
+	db, err := ix.conn()
+	if err != nil {
+		return nil, err
+	}
+	where, args := q.commitFilter()
+	rows, err := db.Query(`SELECT c.hash, c.parents, c.time, c.author, c.email, c.subject,
+		f.path, f.old_path, f.additions, f.deletions, f.binary
+	FROM (SELECT * FROM commits c WHERE `+where+` ORDER BY c.time DESC, c.seq DESC LIMIT ?) c
+	LEFT JOIN files f ON f.commit_seq = c.seq
+	ORDER BY c.time DESC, c.seq DESC, f.rowid`, append(args, limit(q.Limit))...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]git.CommitStats, 0)
+	for rows.Next() {
+		var c git.CommitStats
+		var parents string
+		var unix int64
+		var file struct {
+			path, oldPath        *string
+			additions, deletions *int
+			binary               *bool
+		}
+		if err := rows.Scan(&c.Hash, &parents, &unix, &c.Author, &c.Email, &c.Subject,
+			&file.path, &file.oldPath, &file.additions, &file.deletions, &file.binary); err != nil {
+			return nil, err
+		}
+		if n := len(out); n == 0 || out[n-1].Hash != c.Hash {
+			c.Parents = strings.Fields(parents)
+			c.Time = time.Unix(unix, 0)
+			out = append(out, c)
+		}
+		if file.path != nil {
+			last := &out[len(out)-1]
+			last.Files = append(last.Files, git.FileStat{Path: *file.path, OldPath: *file.oldPath, Additions: *file.additions, Deletions: *file.deletions, IsBinary: *file.binary})
+		}
+	}
+	return out, rows.Err()
+}
+
+// Hotspot is a file that changes often.
+type Hotspot struct {
+	Path      string    `json:"path"`
+	Commits   int       `json:"commits"`
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"`
+	Authors   int       `json:"authors"`
+	Last      time.Time `json:"last"`
+}
+
+// Hotspots ranks the files of the commits matching q by how many commits
+// touched them, then by lines changed. q.Limit caps the files returned.
+func (ix *Index) Hotspots(q Query) ([]Hotspot, error) {
+	db, err := ix.conn()
+	if err != nil {
+		return nil, err
+	}
+	where, args := q.where()
+	if cond, pathArgs := q.pathCond("f.path"); cond != "" {
+		where += " AND " + cond
+		args = append(args, pathArgs...)
+	}
+	rows, err := db.Query(`SELECT f.path, COUNT(*), SUM(f.additions), SUM(f.deletions),
+		COUNT(DISTINCT `+authorKey+`), MAX(c.time)
+	FROM files f JOIN commits c ON c.seq = f.commit_seq
+	WHERE `+where+`
+	GROUP BY f.path
+	ORDER BY 2 DESC, 3 + 4 DESC, f.path
+	LIMIT ?`, append(args, limit(q.Limit))...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]Hotspot, 0)
+	for rows.Next() {
+		var h Hotspot
+		var last int64
+		if err := rows.Scan(&h.Path, &h.Commits, &h.Additions, &h.Deletions, &h.Authors, &last); err != nil {
+			return nil, err
+		}
+		h.Last = time.Unix(last, 0)
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+// Contributor sums up one author's commits.
+type Contributor struct {
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Commits   int       `json:"commits"`
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"`
+	First     time.Time `json:"first"`
+	Last      time.Time `json:"last"`
+}
+
+// authorKey tells authors apart by email, or by name when there is none.
+const authorKey = "CASE WHEN c.email <> '' THEN lower(c.email) ELSE c.author END"
+
+// Contributors ranks the authors of the commits matching q by commit
+// count. Authors are told apart by email, named after their latest commit.
+func (ix *Index) Contributors(q Query) ([]Contributor, error) {
+	db, err := ix.conn()
+	if err != nil {
+		return nil, err
+	}
+	where, args := q.commitFilter()
+	// One row per commit, newest first, with its line counts summed.
+	rows, err := db.Query(`SELECT `+authorKey+`, c.author, c.email, c.time,
+		COALESCE(SUM(f.additions), 0), COALESCE(SUM(f.deletions), 0)
+	FROM commits c LEFT JOIN files f ON f.commit_seq = c.seq
+	WHERE `+where+`
+	GROUP BY c.seq
+	ORDER BY c.time DESC, c.seq DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	byAuthor := map[string]*Contributor{}
+	for rows.Next() {
+		var key, name, email string
+		var unix int64
+		var additions, deletions int
+		if err := rows.Scan(&key, &name, &email, &unix, &additions, &deletions); err != nil {
+			return nil, err
+		}
+		t := time.Unix(unix, 0)
+		a := byAuthor[key]
+		if a == nil {
+			// The first commit is the newest and names the author.
+			a = &Contributor{Name: name, Email: email, First: t, Last: t}
+			byAuthor[key] = a
+		}
+		a.Commits++
+		a.Additions += additions
+		a.Deletions += deletions
+		if t.Before(a.First) {
+			a.First = t
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]Contributor, 0, len(byAuthor))
+	for _, a := range byAuthor {
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Commits != out[j].Commits {
+			return out[i].Commits > out[j].Commits
+		}
+		return out[i].Name < out[j].Name
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
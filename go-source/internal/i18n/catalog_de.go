@@ -0,0 +1,345 @@
+package i18n
+
+var de = map[string]string{
+	"tui.loading":                 "Wird geladen...",
+	"tui.status.local":            "Lokale Änderungen",
+	"tui.status.staged":           "Vorgemerkte Änderungen",
+	"tui.status.history":          "Verlauf",
+	"tui.status.refreshing":       "Wird aktualisiert...",
+	"tui.status.loadingCommit":    "Commit-Diff wird geladen...",
+	"tui.status.loaded":           "Geladen",
+	"tui.status.commitShown":      "Diff des ausgewählten Commits (Esc zurück zur Liste)",
+	"tui.status.error":            "Fehler: %s",
+	"tui.status.annotating":       "KI-Kommentare zu Zeilen werden angefordert...",
+	"tui.status.annotated":        "%d Kommentar(e) hinzugefügt, %d verworfen (Zeile nicht im Diff)",
+	"tui.status.again":            "Letzte KI-Anfrage wird wiederholt...",
+	"tui.status.againDone":        "Letzte KI-Anfrage wiederholt (Tab zurück zum Diff)",
+	"tui.status.noLLM":            "Kein LLM konfiguriert; KI-Kommentare sind nicht verfügbar",
+	"tui.status.hunk":             "Abschnitt %d von %d in %s",
+	"tui.hunkLabel":               "Abschnitt %d von %d in %s",
+	"tui.status.discardLocalOnly": "Verwerfen ist nur im Tab Lokal möglich",
+	"tui.confirm.discardHunk":     "%s aus dem Arbeitsverzeichnis verwerfen? Vorher wird ein Sicherheits-Stash angelegt.",
+	"tui.confirm.discardFile":     "Alle nicht vorgemerkten Änderungen an %s verwerfen? Vorher wird ein Sicherheits-Stash angelegt.",
+	"tui.status.discardCancelled": "Verwerfen abgebrochen",
+	"tui.status.discarding":       "Wird verworfen...",
+	"tui.status.discarded":        "%s verworfen; wiederherstellen mit git stash apply %s",
+	"tui.status.discardedNoStash": "%s verworfen",
+	"tui.status.branches":         "Branches (aktuell: %s) • / filtern • Enter mit aktuellem vergleichen • s wechseln • Esc zurück",
+	"tui.status.branchFilter":     "Filter: %s",
+	"tui.status.comparing":        "%s wird mit %s verglichen...",
+	"tui.status.compared":         "Änderungen auf %s seit der Abzweigung von %s (Esc zurück zur Liste)",
+	"tui.status.switching":        "Wechsel zu %s...",
+	"tui.status.switched":         "Zu %s gewechselt",
+	"tui.status.switchedStashed":  "Zu %s gewechselt; nicht committete Änderungen wurden gestasht (git stash pop stellt sie wieder her)",
+	"tui.status.graph":            "Commit-Graph aller Branches und Tags • Enter zeigt den Diff des Commits",
+	"tui.graph.merge":             "Merge von %s",
+	"tui.graph.refs":              "Refs: %s",
+	"tui.status.loadingStages":    "Vormerk-Ansicht wird geladen...",
+	"tui.status.stages":           "Vormerk-Ansicht: vorgemerkt, nicht vorgemerkt und beides zusammen (v zurück zum Diff)",
+	"tui.stages.staged":           "HEAD → Index (vorgemerkt)",
+	"tui.stages.unstaged":         "Index → Arbeitsverzeichnis (nicht vorgemerkt)",
+	"tui.stages.combined":         "HEAD → Arbeitsverzeichnis (beides)",
+	"tui.stages.none":             "Keine Änderungen",
+	"tui.stages.binary":           "Binärdatei",
+	"tui.tab.local":               "Lokal",
+	"tui.tab.staged":              "Vorgemerkt",
+	"tui.tab.history":             "Verlauf",
+	"tui.tab.branches":            "Branches",
+	"tui.tab.graph":               "Graph",
+	"tui.help":                    "q beenden • Tab wechseln • ↑↓ bewegen • Enter auswählen • r aktualisieren • a kommentieren • . KI wiederholen • v Vormerk-Ansicht • x Abschnitt verwerfen • X Datei verwerfen",
+	"tui.noCommits":               "Keine Commits gefunden",
+	"tui.noChanges":               "Keine Änderungen gefunden",
+	"tui.noBranches":              "Keine Branches passen zu \"%s\"",
+
+	"file.revision":                "Revision %d von %d (neueste zuerst)",
+	"file.help":                    "←/h älter • →/l neuer • ↑/↓ scrollen • q beenden",
+	"file.noHistory":               "Kein Verlauf für %s gefunden.",
+	"cli.web.started":              "Web-UI läuft im Hintergrund unter %s (PID %d).",
+	"cli.web.alreadyRunning":       "Die Web-UI läuft bereits unter %s (PID %d).",
+	"cli.web.running":              "Web-UI läuft unter %s (PID %d).",
+	"cli.web.repo":                 "Repository: %s",
+	"cli.web.log":                  "Log: %s",
+	"cli.web.notRunning":           "Die Web-UI läuft nicht im Hintergrund.",
+	"cli.web.stopped":              "Web-UI beendet (PID %d).",
+	"cli.web.none":                 "Es läuft kein DiffLearn-Webserver.",
+	"cli.server.using":             "Verwende den DiffLearn-Server unter %s (--no-server, um hier auszuführen).",
+	"cli.server.fallback":          "Wird stattdessen hier ausgeführt: %v.",
+	"cli.backup.exported":          "%s geschrieben (%d Dateien).",
+	"cli.backup.secretsExcluded":   "%s ausgelassen; mit --encrypt werden sie passphrasegeschützt mitgenommen.",
+	"cli.backup.imported":          "Sicherung vom %s wiederhergestellt:",
+	"cli.backup.skipped":           "%s beibehalten (existiert bereits; --force ersetzt es)",
+	"cli.backup.secretsMissing":    "Die Sicherung enthält %s nicht; bitte auf diesem Rechner neu setzen.",
+	"cli.backup.noPassphrase":      "Eine Passphrase ist nötig: DIFFLEARN_BACKUP_PASSPHRASE setzen oder im Terminal ausführen.",
+	"cli.backup.passphrase":        "Passphrase: ",
+	"cli.backup.passphraseAgain":   "Passphrase wiederholen: ",
+	"cli.backup.mismatch":          "Die Passphrasen stimmen nicht überein.",
+	"cli.warmup.off":               "der Git-Cache ist ausgeschaltet (DIFFLEARN_GIT_CACHE=false); es gibt nichts vorzubereiten",
+	"cli.warmup.cleared":           "Git-Cache in %s gelöscht",
+	"cli.warmup.tree":              "Dateibaum und Repository-Infos",
+	"cli.warmup.history":           "Verlauf (bis zu %d Commits)",
+	"cli.warmup.branches":          "Branch-Änderungen gegenüber %s",
+	"cli.warmup.done":              "%d Git-Ergebnisse zwischengespeichert (%d waren es schon) in %s",
+	"cli.historyIndex.added":       "%d neue Commits indiziert",
+	"cli.historyIndex.updated":     "%d neue Commits indiziert, insgesamt %d (%s)",
+	"cli.historyIndex.noMatches":   "Keine passenden Commits.",
+	"cli.historyIndex.hotspot":     "+%d -%d, %d Autoren, zuletzt %s",
+	"cli.historyIndex.contributor": "+%d -%d, %s bis %s",
+	"cli.jj.noChanges":             "Keine Änderungen in %s.",
+	"cli.jj.noDescription":         "(keine Beschreibung)",
+	"cli.jj.empty":                 "(leer)",
+	"cli.jj.conflict":              "(Konflikt)",
+	"cli.hg.noChangesets":          "Keine Changesets in %s.",
+	"cli.gitOnly":                  "das geht nur in einem git-Repository",
+	"cli.publish.done":             "%s nach %s veröffentlicht (%d Dateien, %d Commits)",
+	"cli.publish.hint":             "Lade %s auf einen beliebigen statischen Host hoch, um es zu teilen.",
+	"cli.publish.noComments":       "Veröffentlichung ohne Zeilenkommentare: %v",
+	"cli.exercise.header":          "Übung: Commit %s selbst umsetzen",
+	"cli.exercise.offline":         "Kein LLM konfiguriert, daher keine Anforderungen; der Commit ändert diese Dateien:",
+	"cli.exercise.workIn":          "Der Worktree in %s steht auf dem Eltern-Commit. Nimm die Änderung dort vor, im Editor oder wie du möchtest.",
+	"cli.exercise.checkLater":      "Wenn du fertig bist, führe aus: difflearn exercise %s --check %s",
+	"cli.exercise.pressEnter":      "Drücke Enter, wenn dein Versuch verglichen werden kann... ",
+	"cli.exercise.nothingYet":      "Im Worktree hat sich noch nichts geändert; wenn du fertig bist, führe aus: difflearn exercise %s --check %s",
+	"cli.exercise.identical":       "Dein Versuch stimmt genau mit dem Commit überein.",
+	"cli.exercise.differences":     "Dein Versuch weicht in %d Datei(en) vom Commit ab:",
+	"cli.exercise.legend":          "- Zeilen gibt es nur im Commit, + Zeilen nur in deinem Versuch",
+	"cli.exercise.kept":            "Worktree in %s behalten; entferne ihn mit git worktree remove --force %[1]s",
+	"cli.reverts.none":             "Keine zurückgenommenen Commits in den letzten %d Commits.",
+	"cli.reverts.match.message":    "(Rücknahme, in der Nachricht genannt)",
+	"cli.reverts.match.patch":      "(Rücknahme, der Patch macht das Original rückgängig)",
+	"cli.reverts.noteReverted":     "↩ zurückgenommen in %s",
+	"cli.reverts.noteReapplied":    "↩ zurückgenommen in %s, erneut angewendet in %s",
+	"cli.reverts.noteRevert":       "↩ nimmt %s zurück",
+	"cli.reverts.noteReapply":      "↪ wendet %s erneut an",
+	"cli.reverts.samePatch":        "Der erneut angewendete Commit hat denselben Patch wie das Original.",
+	"cli.models.header":            "Modelle von %s:",
+	"cli.models.none":              "%s listet keine Modelle auf.",
+	"cli.models.pick":              "Zu verwendendes Modell (Nummer oder Name, Enter behält das aktuelle): ",
+	"cli.models.saved":             "%s-Modell %s in %s gespeichert",
+	"cli.models.savedProfile":      "%s-Modell %s im Profil %s in %s gespeichert",
+	"cli.fallback":                 "%s ist fehlgeschlagen, daher hat %s (%s) geantwortet: %s",
+	"cli.retry":                    "%s: %s; neuer Versuch in %s (Wiederholung %d von %d)",
+	"cli.prompts.builtin":          "eingebaut",
+	"cli.prompts.invalid":          "ungültige Vorlage, der eingebaute Prompt wird verwendet: %v",
+	"cli.prompts.created":          "%s wurde aus dem eingebauten Prompt erstellt.",
+	"cli.chat.intro":               "Gespräch über %s. Stelle Folgefragen; /help zeigt die Befehle.",
+	"cli.chat.help":                "%s • Enter senden • /help Befehle • Esc beenden",
+	"cli.chat.thinking":            "Denke nach…",
+	"cli.chat.commands":            "/staged          über die gestagten Änderungen sprechen\n/local           über alle lokalen Änderungen sprechen\n/against <ref>   Arbeitsverzeichnis mit einer Referenz vergleichen\n/file <pfad>     Diff auf eine Datei beschränken (/file allein hebt es auf)\n/context         Dateien im Kontext anzeigen\n/reset           bisheriges Gespräch vergessen\n/quit            Gespräch beenden",
+	"cli.chat.reset":               "Gespräch gelöscht; die nächste Frage beginnt neu.",
+	"cli.chat.context":             "Jetzt geht es um %s (%d geänderte Dateien).",
+	"cli.chat.usage":               "Verwendung: %s",
+	"cli.chat.unknown":             "Unbekannter Befehl %s; /help zeigt die Befehle.",
+	"cli.sessions.none":            "Keine KI-Sitzungen gefunden.",
+	"cli.sessions.meta":            "%s · %s/%s · %s",
+	"cli.sessions.prompt":          "Prompt:",
+	"cli.sessions.exported":        "%d Sitzungen nach %s exportiert.",
+	"cli.promptSize.header":        "Geschätzte Prompt-Größe für %s",
+	"cli.promptSize.total":         "~%d Prompt-Tokens (Diff ~%d) + %d für die Antwort reserviert",
+	"cli.promptSize.files":         "Tokens pro Datei (größte zuerst):",
+	"cli.promptSize.moreFiles":     "... und %d weitere Dateien",
+	"cli.promptSize.fitsBudget":    "Passt in eine Anfrage innerhalb des Kontextbudgets von %d Tokens (DIFFLEARN_CONTEXT_TOKENS).",
+	"cli.promptSize.providers":     "Standard-Kontextfenster:",
+	"cli.promptSize.active":        "konfiguriert",
+	"cli.promptSize.trimHint":      "Die Anfrage mit --path <Verz.> oder --path '!<Glob>' kürzen, um generierte oder mitgelieferte Dateien auszulassen.",
+	"cli.flashcards.generating":    "Karteikarten werden erstellt...",
+	"cli.flashcards.none":          "Das Modell fand in diesen Änderungen nichts, was eine Karteikarte lohnt.",
+	"cli.flashcards.written":       "%d Karteikarten nach %s geschrieben. In Anki mit Datei > Importieren laden.",
+	"cli.quiz.title":               "Quiz",
+	"cli.quiz.generating":          "Quiz wird erstellt...",
+	"cli.quiz.grading":             "Wird bewertet...",
+	"cli.quiz.question":            "Frage %d von %d",
+	"cli.quiz.correct":             "Richtig",
+	"cli.quiz.wrong":               "Nicht ganz",
+	"cli.quiz.rightChoice":         "Die Antwort ist %d) %s.",
+	"cli.quiz.modelAnswer":         "Musterantwort: %s",
+	"cli.quiz.finished":            "Fertig: %d von %d richtig.",
+	"cli.quiz.score":               "Ergebnis: %d/%d (%d%%)",
+	"cli.quiz.average":             "Durchschnitt über %d Quiz: %d%%",
+	"cli.quiz.noHistory":           "Noch keine Quiz-Ergebnisse für dieses Repository. Mit difflearn quiz eines starten.",
+	"cli.quiz.helpChoice":          "↑/↓ oder 1-9: wählen • Enter: antworten • q: beenden",
+	"cli.quiz.helpShort":           "Antwort eingeben • Enter: absenden • Esc: beenden",
+	"cli.quiz.helpNext":            "Enter: weiter • q: beenden",
+	"cli.quiz.helpDone":            "Enter oder q: beenden",
+	"story.step":                   "Commit %d von %d · %s %s",
+	"story.pane.diff":              "Diff",
+	"story.pane.explanation":       "Erklärung",
+	"story.pane.questions":         "Fragen",
+	"story.generating":             "LLM wird gefragt...",
+	"story.questionsNeedAI":        "Verständnisfragen brauchen ein LLM. Ohne --no-ai ausführen, sobald eines konfiguriert ist.",
+	"story.help":                   "n/p: nächster/vorheriger Commit • Tab/1-3: Bereich • j/k: scrollen • a: Antworten zeigen • q: beenden",
+
+	"cli.noChanges":                     "Keine Änderungen gefunden.",
+	"cli.smart.usedStaged":              "Keine nicht vorgemerkten Änderungen; stattdessen werden die vorgemerkten verwendet.",
+	"cli.smart.usedUnstaged":            "Nichts ist vorgemerkt; stattdessen werden die nicht vorgemerkten Änderungen verwendet.",
+	"cli.precommit.nothingStaged":       "Nichts ist vorgemerkt. Zuerst Änderungen mit git add vormerken.",
+	"cli.precommit.header":              "Zu committen: %d Datei(en):",
+	"cli.precommit.edited":              "%s wurde nach dem Vormerken bearbeitet (+%d -%d nicht vorgemerkt)",
+	"cli.precommit.editedHint":          "Der Commit nimmt die vorgemerkte Version; git add die Dateien erneut, um die späteren Änderungen aufzunehmen.",
+	"cli.precommit.blocked":             "%d vorgemerkte Datei(en) haben mehr als %d nicht vorgemerkte geänderte Zeilen; diese vormerken oder stashen oder mit --no-verify committen",
+	"cli.release.header":                "Änderungen von %s bis %s",
+	"cli.release.stats":                 "%d Commit(s) von %d Autor(en), %d Datei(en) geändert, +%d -%d",
+	"cli.release.needTags":              "zum Vergleich der neuesten Versionen werden zwei Tags benötigt, das Repository hat %d; gib die Versionen an, z. B. difflearn release v1.0 v1.1",
+	"cli.changelog.unreleased":          "Unveröffentlicht",
+	"cli.changelog.section.breaking":    "⚠ Inkompatible Änderungen",
+	"cli.changelog.section.feat":        "Funktionen",
+	"cli.changelog.section.fix":         "Fehlerbehebungen",
+	"cli.changelog.section.perf":        "Leistung",
+	"cli.changelog.section.refactor":    "Refactoring",
+	"cli.changelog.section.docs":        "Dokumentation",
+	"cli.changelog.section.test":        "Tests",
+	"cli.changelog.section.build":       "Build",
+	"cli.changelog.section.ci":          "Kontinuierliche Integration",
+	"cli.changelog.section.style":       "Stil",
+	"cli.changelog.section.chore":       "Wartung",
+	"cli.changelog.section.revert":      "Rücknahmen",
+	"cli.changelog.section.other":       "Weitere Änderungen",
+	"cli.releaseNotes.dependencies":     "Abhängigkeiten",
+	"cli.releaseNotes.apiRemoved":       "`%s` wurde aus `%s` entfernt",
+	"cli.releaseNotes.apiChanged":       "`%s` in `%s` geändert: `%s` → `%s`",
+	"cli.releaseNotes.apiAdded":         "Neue API in `%s`: %s",
+	"cli.releaseNotes.depUpdated":       "`%s` aktualisiert %s → %s (%s)",
+	"cli.releaseNotes.depAdded":         "`%s` %s hinzugefügt (%s)",
+	"cli.releaseNotes.depRemoved":       "`%s` %s entfernt (%s)",
+	"cli.releaseNotes.nothing":          "Keine inkompatiblen Änderungen, Features, Fehlerbehebungen oder Abhängigkeitsänderungen in %s.",
+	"cli.noCommitsInRange":              "Keine Commits in %s.",
+	"cli.noStashes":                     "Keine Stashes gefunden.",
+	"cli.noLLM":                         "Kein LLM-API-Schlüssel konfiguriert.",
+	"cli.noLLMOffline":                  "Kein LLM-API-Schlüssel konfiguriert. Es wird eine Offline-Analyse angezeigt.",
+	"cli.label.explanation":             "Erklärung",
+	"cli.label.explanationOf":           "Erklärung von %s",
+	"cli.label.narrative":               "Die Geschichte des Autors",
+	"cli.label.requirements":            "Anforderungen",
+	"cli.label.exerciseFeedback":        "Dein Versuch im Vergleich zum Commit",
+	"cli.label.revert":                  "Warum es zurückgenommen wurde",
+	"cli.label.review":                  "Code-Review",
+	"cli.label.summary":                 "Zusammenfassung",
+	"cli.label.rangeReview":             "Review des Bereichs",
+	"cli.label.releaseNotes":            "Versionshinweise",
+	"cli.label.commitReview":            "Review von %s %s",
+	"cli.label.refined":                 "%s (überarbeitet)",
+	"cli.label.comparing":               "%s (Vergleich %s)",
+	"cli.refining":                      "Review wird erstellt und anschließend gegen den Diff geprüft...",
+	"cli.generating":                    "Antwort wird erstellt...",
+	"cli.exportGenerating":              "%s wird erstellt...",
+	"cli.usage.totals":                  "LLM-Nutzung",
+	"cli.usage.day":                     "Heute: %d Anfrage(n), %d Tokens",
+	"cli.usage.month":                   "Diesen Monat: %d Anfrage(n), %d Tokens",
+	"cli.usage.endpoint":                "%s: %d Anfrage(n), %d Tokens",
+	"cli.usage.noBudget":                "Kein Budget festgelegt (DIFFLEARN_LLM_BUDGET).",
+	"cli.usage.budget":                  "Budgets",
+	"cli.usage.resets":                  "wird am %s zurückgesetzt",
+	"cli.conflicts.none":                "Keine Merge-Konflikte.",
+	"cli.conflicts.noMarkers":           "%s hat keine Konfliktmarker.",
+	"cli.conflicts.header":              "%s: %d Konflikt(e)",
+	"cli.conflicts.at":                  "Konflikt %d in Zeile %d",
+	"cli.conflicts.ours":                "Unsere (%s)",
+	"cli.conflicts.base":                "Basis",
+	"cli.conflicts.theirs":              "Ihre (%s)",
+	"cli.conflicts.confirm":             "Vorgeschlagene Auflösung in %s schreiben?",
+	"cli.conflicts.skipped":             "%s bleibt unverändert.",
+	"cli.conflicts.written":             "%s aufgelöst. Prüfen und dann ausführen: git add %s",
+	"cli.compareIdentical":              "%s und %s sind identisch.",
+	"cli.owners.unowned":                "Dateien ohne Zuständige",
+	"cli.owners.noWebhook":              "Kein Webhook für %s in owners.webhooks; nicht gesendet.",
+	"cli.owners.posted":                 "Abschnitt für %s gesendet.",
+	"cli.importHeader":                  "%s: %d Datei(en) geändert, +%d -%d",
+	"cli.issues.stats":                  "%d Commit(s), %d Datei(en), +%d -%d",
+	"cli.issues.unlinked":               "Weitere Änderungen",
+	"cli.structuredFallback":            "Das Modell hat kein strukturiertes Review geliefert; die Antwort wird als Text angezeigt.",
+	"cli.noIssues":                      "Keine Probleme gefunden.",
+	"cli.issueCounts":                   "%d kritisch, %d wichtig, %d geringfügig",
+	"cli.label.answer":                  "Antwort",
+	"cli.label.questions":               "Diskussionsfragen zu %s",
+	"cli.label.conflicts":               "Konflikte in %s",
+	"cli.savedTo":                       "Gespeichert in %s",
+	"cli.copied":                        "In die Zwischenablage kopiert.",
+	"cli.again":                         "Wiederhole %s von %s vom %s",
+	"cli.prHeader":                      "von %s • %s ← %s • %s",
+	"cli.prPosted":                      "Review veröffentlicht: %s",
+	"cli.annotationsDropped":            "%d Kommentar(e) bezogen sich auf Zeilen außerhalb des Diffs und wurden verworfen.",
+	"cli.teaching":                      "Lehrkommentare werden geschrieben...",
+	"cli.teachWritten":                  "%d kommentierte Datei(en) mit %d Notiz(en) nach %s geschrieben",
+	"cli.reviewBlocked":                 "%d Befund(e) oder Problem(e) mit Schweregrad %s oder höher",
+	"cli.ciPassed":                      "Bestanden: keine Befunde oder Probleme mit Schweregrad %s oder höher",
+	"cli.hookInstalled":                 "%s-Hook in %s installiert (blockiert ab %s)",
+	"cli.hookRemoved":                   "%s-Hook entfernt",
+	"cli.hookInstalledPrecommit":        "pre-commit-Hook unter %s installiert (blockiert, wenn eine vorgemerkte Datei mehr als %d nicht vorgemerkte geänderte Zeilen hat)",
+	"cli.noHooks":                       "Keine DiffLearn-Hooks installiert.",
+	"cli.streak.current":                "Aktuelle Serie: %d Tag(e) (längste %d)",
+	"cli.streak.commits":                "Diese Woche studierte Commits: %d/%d",
+	"cli.streak.commitsNoGoal":          "Diese Woche studierte Commits: %d",
+	"cli.streak.explanations":           "KI-Erklärungen diese Woche: %d/%d",
+	"cli.streak.explanationsNoGoal":     "KI-Erklärungen diese Woche: %d",
+	"cli.streak.goalMet":                "Wochenziel erreicht!",
+	"cli.streak.weekly":                 "Wochen in Folge mit erreichtem Ziel: %d",
+	"cli.streak.lastWeek":               "Letzte 7 Tage:",
+	"cli.team.synced":                   "%d neue(s) Ereignis(se) als %s mit dem Team-Server synchronisiert",
+	"cli.team.empty":                    "Noch keine Team-Aktivität. Mitglieder senden ihre mit `difflearn team sync`.",
+	"cli.team.lastActive":               "zuletzt aktiv %s",
+	"cli.team.questions":                "%d KI-Antwort(en) diese Woche, insgesamt %d Frage(n)",
+	"cli.team.recent":                   "Zuletzt gelernt:",
+	"cli.team.struggle":                 "Schwierigkeiten mit %s@%s: %d KI-Anfrage(n), %d Frage(n)",
+	"cli.filesChanged":                  "%d Datei(en) geändert,",
+	"cli.findings":                      "Ergebnisse der Vorabprüfung:",
+	"cli.noFindings":                    "Die statische Vorabprüfung hat keine Probleme gefunden.",
+	"cli.scorecard":                     "Bewertung nach Rubrik:",
+	"cli.gateFailed":                    "Pflichtkriterium nicht erfüllt: %s",
+	"cli.config.provider":               "Anbieter: %s",
+	"cli.config.model":                  "Modell: %s",
+	"cli.config.available":              "LLM verfügbar: %t",
+	"cli.config.baseURL":                "Basis-URL: %s",
+	"cli.config.uiLanguage":             "Sprache der Oberfläche: %s",
+	"cli.config.diffAlgorithm":          "Diff-Algorithmus: %s",
+	"cli.config.level":                  "Zielgruppe: %s",
+	"cli.config.language":               "Antwortsprache: %s",
+	"cli.config.profile":                "Profil: %s",
+	"cli.config.fallback":               "Ausweichanbieter: %s",
+	"cli.configSet.saved":               "%s = %s in %s gespeichert",
+	"cli.configSet.removed":             "%s aus %s entfernt",
+	"cli.configSet.notInFile":           "%s ist in %s nicht gesetzt",
+	"cli.configSet.overridden":          "%s ist in der Umgebung gesetzt und hat Vorrang vor ~/.difflearn",
+	"cli.configSet.fromEnv":             "Umgebung (%s)",
+	"cli.configSet.none":                "Keine Einstellungen gesetzt; `difflearn config keys` listet sie auf.",
+	"cli.configSet.savedProfile":        "%s = %s in Profil %s in %s gespeichert",
+	"cli.configSet.profileIn":           "Profil %s in %s",
+	"cli.configSet.overriddenByProfile": "Das aktive Profil %s setzt %s und hat Vorrang",
+	"cli.configSet.noProfiles":          "~/.difflearn hat keine Profile; lege eines mit difflearn config set --profile <Name> <Schlüssel> <Wert> an.",
+	"cli.configSet.profileSummary":      "%s (%d Einstellungen)",
+	"cli.setup.welcome":                 "Willkommen bei DiffLearn! Wähle den KI-Anbieter, der deine Diffs erklärt.",
+	"cli.setup.found":                   "Sofort nutzbar:",
+	"cli.setup.noneFound":               "Kein API-Schlüssel, CLI-Agent oder Ollama-Server gefunden.",
+	"cli.setup.enterKey":                "API-Schlüssel eingeben (OpenAI, Anthropic oder Google)",
+	"cli.setup.skip":                    "Vorerst überspringen (Diffs funktionieren ohne KI; später difflearn setup ausführen)",
+	"cli.setup.choose":                  "Auswahl [%d]: ",
+	"cli.setup.invalid":                 "Gib eine Zahl von 1 bis %d ein.",
+	"cli.setup.pasteKey":                "API-Schlüssel einfügen (wird als %s in ~/.difflearn gespeichert): ",
+	"cli.setup.noKey":                   "kein API-Schlüssel eingegeben; difflearn setup erneut ausführen",
+	"cli.setup.skipped":                 "Übersprungen. Führe difflearn setup aus, wenn du KI-Erklärungen möchtest.",
+	"cli.setup.saved":                   "Verwende %s; gespeichert in %s",
+	"cli.setup.cliAuth":                 "Prüfe mit difflearn auth %s, ob die CLI angemeldet ist",
+	"cli.setup.fromEnv":                 "%s ist gesetzt",
+	"cli.setup.fromCLI":                 "der Befehl %s ist installiert",
+	"cli.setup.fromOllama":              "läuft unter %s, Modell %s",
+	"cli.auth.active":                   "(aktiv)",
+	"cli.auth.signedIn":                 "angemeldet",
+	"cli.auth.signedOut":                "nicht angemeldet",
+	"cli.auth.notInstalled":             "nicht installiert (kein Befehl %s im PATH)",
+	"cli.auth.unknown":                  "installiert; die Anmeldung kann nicht geprüft werden",
+	"cli.auth.fixLogin":                 "Anmelden mit: difflearn auth %s --login",
+	"cli.auth.confirmLogin":             "Jetzt bei %s anmelden?",
+	"cli.auth.sessionHint":              "Gib nach dem Start der Sitzung %s ein, um dich anzumelden, und beende sie dann.",
+	"cli.auth.notReady":                 "%s ist noch nicht einsatzbereit",
+	"cli.update.latest":                 "Du verwendest die neueste Version",
+	"cli.update.available":              "Update verfügbar: v%s -> v%s",
+	"cli.update.run":                    "Ausführen: %s",
+	"cli.update.release":                "Release: %s",
+
+	"a11y.selected":     "%s (ausgewählt)",
+	"a11y.tabs":         "Reiter: %s",
+	"a11y.current":      "Aktuell:",
+	"a11y.filesChanged": "%d Datei(en) geändert",
+	"a11y.commit":       "Commit %d von %d: %s %s von %s",
+	"a11y.branch":       "Branch %d von %d: %s, %s",
+	"a11y.answer":       "Antwort %d von %d von %s (%s), %.1f Sekunden:",
+	"a11y.answerEnd":    "Ende der Antwort von %s.",
+}
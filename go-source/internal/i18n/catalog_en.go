@@ -0,0 +1,345 @@
+package i18n
+
+var en = map[string]string{
+	"tui.loading":                 "Loading...",
+	"tui.status.local":            "Local changes",
+	"tui.status.staged":           "Staged changes",
+	"tui.status.history":          "History view",
+	"tui.status.refreshing":       "Refreshing...",
+	"tui.status.loadingCommit":    "Loading commit diff...",
+	"tui.status.loaded":           "Loaded",
+	"tui.status.commitShown":      "Showing selected commit diff (Esc returns to the list)",
+	"tui.status.error":            "Error: %s",
+	"tui.status.annotating":       "Asking the AI for line comments...",
+	"tui.status.annotated":        "%d comment(s) added, %d dropped (line not in diff)",
+	"tui.status.again":            "Re-running the last AI request...",
+	"tui.status.againDone":        "Last AI request replayed (Tab returns to the diff)",
+	"tui.status.noLLM":            "No LLM configured; AI comments are unavailable",
+	"tui.status.hunk":             "Hunk %d of %d in %s",
+	"tui.hunkLabel":               "hunk %d of %d in %s",
+	"tui.status.discardLocalOnly": "Discarding works on the Local tab",
+	"tui.confirm.discardHunk":     "Discard %s from the working tree? A safety stash is saved first.",
+	"tui.confirm.discardFile":     "Discard all unstaged changes to %s? A safety stash is saved first.",
+	"tui.status.discardCancelled": "Discard cancelled",
+	"tui.status.discarding":       "Discarding...",
+	"tui.status.discarded":        "Discarded %s; restore it with git stash apply %s",
+	"tui.status.discardedNoStash": "Discarded %s",
+	"tui.status.branches":         "Branches (current: %s) • / filter • Enter compare with current • s switch • Esc back",
+	"tui.status.branchFilter":     "Filter: %s",
+	"tui.status.comparing":        "Comparing %s with %s...",
+	"tui.status.compared":         "Changes on %s since it forked from %s (Esc returns to the list)",
+	"tui.status.switching":        "Switching to %s...",
+	"tui.status.switched":         "Switched to %s",
+	"tui.status.switchedStashed":  "Switched to %s; uncommitted changes were stashed (git stash pop restores them)",
+	"tui.status.graph":            "Commit graph of all branches and tags • Enter show the commit diff",
+	"tui.graph.merge":             "merge of %s",
+	"tui.graph.refs":              "refs: %s",
+	"tui.status.loadingStages":    "Loading the staging view...",
+	"tui.status.stages":           "Staging view: staged, unstaged and both together (v returns to the diff)",
+	"tui.stages.staged":           "HEAD → index (staged)",
+	"tui.stages.unstaged":         "index → worktree (unstaged)",
+	"tui.stages.combined":         "HEAD → worktree (both)",
+	"tui.stages.none":             "No changes",
+	"tui.stages.binary":           "Binary file",
+	"tui.tab.local":               "Local",
+	"tui.tab.staged":              "Staged",
+	"tui.tab.history":             "History",
+	"tui.tab.branches":            "Branches",
+	"tui.tab.graph":               "Graph",
+	"tui.help":                    "q quit • Tab switch • ↑↓ move • Enter select • r refresh • a annotate • . repeat AI • v staging view • x discard hunk • X discard file",
+	"tui.noCommits":               "No commits found",
+	"tui.noChanges":               "No changes found",
+	"tui.noBranches":              "No branches match \"%s\"",
+
+	"file.revision":                "Revision %d of %d (newest first)",
+	"file.help":                    "←/h older • →/l newer • ↑/↓ scroll • q quit",
+	"file.noHistory":               "No history found for %s.",
+	"cli.web.started":              "Web UI running in the background at %s (pid %d).",
+	"cli.web.alreadyRunning":       "The web UI is already running at %s (pid %d).",
+	"cli.web.running":              "Web UI running at %s (pid %d).",
+	"cli.web.repo":                 "Repository: %s",
+	"cli.web.log":                  "Log: %s",
+	"cli.web.notRunning":           "The web UI is not running in the background.",
+	"cli.web.stopped":              "Stopped the web UI (pid %d).",
+	"cli.web.none":                 "No DiffLearn web server is running.",
+	"cli.server.using":             "Using the DiffLearn server at %s (--no-server to run here).",
+	"cli.server.fallback":          "Running here instead: %v.",
+	"cli.backup.exported":          "Wrote %s (%d files).",
+	"cli.backup.secretsExcluded":   "Left out %s; use --encrypt to carry them with a passphrase.",
+	"cli.backup.imported":          "Restored the backup from %s:",
+	"cli.backup.skipped":           "%s kept (already exists; --force replaces it)",
+	"cli.backup.secretsMissing":    "The backup does not include %s; set them again on this machine.",
+	"cli.backup.noPassphrase":      "A passphrase is required: set DIFFLEARN_BACKUP_PASSPHRASE or run in a terminal.",
+	"cli.backup.passphrase":        "Passphrase: ",
+	"cli.backup.passphraseAgain":   "Repeat the passphrase: ",
+	"cli.backup.mismatch":          "The passphrases do not match.",
+	"cli.warmup.off":               "the git cache is off (DIFFLEARN_GIT_CACHE=false); there is nothing to warm up",
+	"cli.warmup.cleared":           "Deleted the git cache in %s",
+	"cli.warmup.tree":              "File tree and repository info",
+	"cli.warmup.history":           "History (up to %d commits)",
+	"cli.warmup.branches":          "Branch changes against %s",
+	"cli.warmup.done":              "Cached %d git results (%d were cached already) in %s",
+	"cli.historyIndex.added":       "Indexed %d new commits",
+	"cli.historyIndex.updated":     "Indexed %d new commits, %d in total (%s)",
+	"cli.historyIndex.noMatches":   "No matching commits.",
+	"cli.historyIndex.hotspot":     "+%d -%d, %d authors, last %s",
+	"cli.historyIndex.contributor": "+%d -%d, %s to %s",
+	"cli.jj.noChanges":             "No changes in %s.",
+	"cli.jj.noDescription":         "(no description)",
+	"cli.jj.empty":                 "(empty)",
+	"cli.jj.conflict":              "(conflict)",
+	"cli.hg.noChangesets":          "No changesets in %s.",
+	"cli.gitOnly":                  "this needs a git repository",
+	"cli.publish.done":             "Published %s to %s (%d files, %d commits)",
+	"cli.publish.hint":             "Upload %s to any static host to share it.",
+	"cli.publish.noComments":       "Publishing without line comments: %v",
+	"cli.exercise.header":          "Exercise: make commit %s yourself",
+	"cli.exercise.offline":         "No LLM is configured, so there are no requirements; the commit changes these files:",
+	"cli.exercise.workIn":          "The worktree at %s is at the commit's parent. Make the change there, in your editor or any way you like.",
+	"cli.exercise.checkLater":      "When you are done, run: difflearn exercise %s --check %s",
+	"cli.exercise.pressEnter":      "Press Enter when your attempt is ready to compare... ",
+	"cli.exercise.nothingYet":      "Nothing has changed in the worktree yet; when you are done, run: difflearn exercise %s --check %s",
+	"cli.exercise.identical":       "Your attempt matches the commit exactly.",
+	"cli.exercise.differences":     "Your attempt differs from the commit in %d file(s):",
+	"cli.exercise.legend":          "- lines are only in the commit, + lines only in your attempt",
+	"cli.exercise.kept":            "Worktree kept at %s; remove it with git worktree remove --force %[1]s",
+	"cli.reverts.none":             "No reverted commits in the last %d commits.",
+	"cli.reverts.match.message":    "(revert, named in its message)",
+	"cli.reverts.match.patch":      "(revert, its patch undoes the original)",
+	"cli.reverts.noteReverted":     "↩ reverted in %s",
+	"cli.reverts.noteReapplied":    "↩ reverted in %s, reapplied in %s",
+	"cli.reverts.noteRevert":       "↩ reverts %s",
+	"cli.reverts.noteReapply":      "↪ reapplies %s",
+	"cli.reverts.samePatch":        "The reapplied commit has the same patch as the original.",
+	"cli.models.header":            "Models of %s:",
+	"cli.models.none":              "%s lists no models.",
+	"cli.models.pick":              "Model to use (number or name, Enter to keep the current one): ",
+	"cli.models.saved":             "Saved %s model %s to %s",
+	"cli.models.savedProfile":      "Saved %s model %s to profile %s in %s",
+	"cli.fallback":                 "%s failed, so %s (%s) answered instead: %s",
+	"cli.retry":                    "%s: %s; retrying in %s (retry %d of %d)",
+	"cli.prompts.builtin":          "built-in",
+	"cli.prompts.invalid":          "invalid template, the built-in prompt is used: %v",
+	"cli.prompts.created":          "Created %s from the built-in prompt.",
+	"cli.chat.intro":               "Chatting about %s. Ask follow-up questions; /help lists the commands.",
+	"cli.chat.help":                "%s • Enter send • /help commands • Esc quit",
+	"cli.chat.thinking":            "Thinking…",
+	"cli.chat.commands":            "/staged          talk about the staged changes\n/local           talk about all local changes\n/against <ref>   compare the working tree against a ref\n/file <path>     limit the diff to one file (/file alone clears it)\n/context         show the files in context\n/reset           forget the conversation so far\n/quit            end the chat",
+	"cli.chat.reset":               "Conversation cleared; the next question starts fresh.",
+	"cli.chat.context":             "Now talking about %s (%d changed files).",
+	"cli.chat.usage":               "Usage: %s",
+	"cli.chat.unknown":             "Unknown command %s; /help lists the commands.",
+	"cli.sessions.none":            "No AI sessions found.",
+	"cli.sessions.meta":            "%s · %s/%s · %s",
+	"cli.sessions.prompt":          "Prompt:",
+	"cli.sessions.exported":        "Exported %d sessions to %s.",
+	"cli.promptSize.header":        "Estimated prompt size for %s",
+	"cli.promptSize.total":         "~%d prompt tokens (diff ~%d) + %d reserved for the answer",
+	"cli.promptSize.files":         "Tokens per file (largest first):",
+	"cli.promptSize.moreFiles":     "... and %d more files",
+	"cli.promptSize.fitsBudget":    "Fits in one request under the context budget of %d tokens (DIFFLEARN_CONTEXT_TOKENS).",
+	"cli.promptSize.providers":     "Default context windows:",
+	"cli.promptSize.active":        "configured",
+	"cli.promptSize.trimHint":      "Trim the request with --path <dir> or --path '!<glob>' to leave out generated or vendored files.",
+	"cli.flashcards.generating":    "Writing flashcards...",
+	"cli.flashcards.none":          "The model found nothing in these changes worth a flashcard.",
+	"cli.flashcards.written":       "Wrote %d flashcards to %s. Import it in Anki with File > Import.",
+	"cli.quiz.title":               "Quiz",
+	"cli.quiz.generating":          "Writing the quiz...",
+	"cli.quiz.grading":             "Grading...",
+	"cli.quiz.question":            "Question %d of %d",
+	"cli.quiz.correct":             "Correct",
+	"cli.quiz.wrong":               "Not quite",
+	"cli.quiz.rightChoice":         "The answer is %d) %s.",
+	"cli.quiz.modelAnswer":         "Model answer: %s",
+	"cli.quiz.finished":            "Finished: %d of %d correct.",
+	"cli.quiz.score":               "Score: %d/%d (%d%%)",
+	"cli.quiz.average":             "Average over %d quizzes: %d%%",
+	"cli.quiz.noHistory":           "No quiz scores for this repository yet. Run difflearn quiz to take one.",
+	"cli.quiz.helpChoice":          "↑/↓ or 1-9: choose • enter: answer • q: quit",
+	"cli.quiz.helpShort":           "type your answer • enter: submit • esc: quit",
+	"cli.quiz.helpNext":            "enter: next • q: quit",
+	"cli.quiz.helpDone":            "enter or q: exit",
+	"story.step":                   "Commit %d of %d · %s %s",
+	"story.pane.diff":              "Diff",
+	"story.pane.explanation":       "Explanation",
+	"story.pane.questions":         "Questions",
+	"story.generating":             "Asking the LLM...",
+	"story.questionsNeedAI":        "Comprehension questions need an LLM. Run without --no-ai once one is configured.",
+	"story.help":                   "n/p: next/previous commit • tab/1-3: pane • j/k: scroll • a: show answers • q: quit",
+
+	"cli.noChanges":                     "No changes found.",
+	"cli.smart.usedStaged":              "No unstaged changes; using the staged changes instead.",
+	"cli.smart.usedUnstaged":            "Nothing is staged; using the unstaged changes instead.",
+	"cli.precommit.nothingStaged":       "Nothing is staged. Stage changes with git add first.",
+	"cli.precommit.header":              "About to commit %d file(s):",
+	"cli.precommit.edited":              "%s was edited after staging (+%d -%d not staged)",
+	"cli.precommit.editedHint":          "The commit takes the staged version; git add those files again to include the later edits.",
+	"cli.precommit.blocked":             "%d staged file(s) have more than %d unstaged changed lines; stage or stash those edits, or commit with --no-verify",
+	"cli.release.header":                "Changes from %s to %s",
+	"cli.release.stats":                 "%d commit(s) by %d author(s), %d file(s) changed, +%d -%d",
+	"cli.release.needTags":              "comparing the newest releases needs two tags but the repository has %d; name the releases, e.g. difflearn release v1.0 v1.1",
+	"cli.changelog.unreleased":          "Unreleased",
+	"cli.changelog.section.breaking":    "⚠ Breaking Changes",
+	"cli.changelog.section.feat":        "Features",
+	"cli.changelog.section.fix":         "Bug Fixes",
+	"cli.changelog.section.perf":        "Performance",
+	"cli.changelog.section.refactor":    "Refactoring",
+	"cli.changelog.section.docs":        "Documentation",
+	"cli.changelog.section.test":        "Tests",
+	"cli.changelog.section.build":       "Build",
+	"cli.changelog.section.ci":          "Continuous Integration",
+	"cli.changelog.section.style":       "Style",
+	"cli.changelog.section.chore":       "Chores",
+	"cli.changelog.section.revert":      "Reverts",
+	"cli.changelog.section.other":       "Other Changes",
+	"cli.releaseNotes.dependencies":     "Dependencies",
+	"cli.releaseNotes.apiRemoved":       "`%s` was removed from `%s`",
+	"cli.releaseNotes.apiChanged":       "`%s` changed in `%s`: `%s` → `%s`",
+	"cli.releaseNotes.apiAdded":         "New API in `%s`: %s",
+	"cli.releaseNotes.depUpdated":       "updated `%s` %s → %s (%s)",
+	"cli.releaseNotes.depAdded":         "added `%s` %s (%s)",
+	"cli.releaseNotes.depRemoved":       "removed `%s` %s (%s)",
+	"cli.releaseNotes.nothing":          "No breaking changes, features, fixes or dependency changes in %s.",
+	"cli.noCommitsInRange":              "No commits in %s.",
+	"cli.noStashes":                     "No stashes found.",
+	"cli.noLLM":                         "No LLM API key configured.",
+	"cli.noLLMOffline":                  "No LLM API key configured. Showing an offline analysis instead.",
+	"cli.label.explanation":             "Explanation",
+	"cli.label.explanationOf":           "Explanation of %s",
+	"cli.label.narrative":               "The author's story",
+	"cli.label.requirements":            "Requirements",
+	"cli.label.exerciseFeedback":        "Your attempt compared with the commit",
+	"cli.label.revert":                  "Why it was reverted",
+	"cli.label.review":                  "Code Review",
+	"cli.label.summary":                 "Summary",
+	"cli.label.rangeReview":             "Range Review",
+	"cli.label.releaseNotes":            "Release Notes",
+	"cli.label.commitReview":            "Review of %s %s",
+	"cli.label.refined":                 "%s (refined)",
+	"cli.label.comparing":               "%s (comparing %s)",
+	"cli.refining":                      "Drafting review, then verifying its findings against the diff...",
+	"cli.generating":                    "Generating the answer...",
+	"cli.exportGenerating":              "Generating %s...",
+	"cli.usage.totals":                  "LLM usage",
+	"cli.usage.day":                     "Today: %d request(s), %d tokens",
+	"cli.usage.month":                   "This month: %d request(s), %d tokens",
+	"cli.usage.endpoint":                "%s: %d request(s), %d tokens",
+	"cli.usage.noBudget":                "No budget set (DIFFLEARN_LLM_BUDGET).",
+	"cli.usage.budget":                  "Budgets",
+	"cli.usage.resets":                  "resets %s",
+	"cli.conflicts.none":                "No merge conflicts.",
+	"cli.conflicts.noMarkers":           "%s has no conflict markers.",
+	"cli.conflicts.header":              "%s: %d conflict(s)",
+	"cli.conflicts.at":                  "Conflict %d at line %d",
+	"cli.conflicts.ours":                "Ours (%s)",
+	"cli.conflicts.base":                "Base",
+	"cli.conflicts.theirs":              "Theirs (%s)",
+	"cli.conflicts.confirm":             "Write the proposed resolution into %s?",
+	"cli.conflicts.skipped":             "Left %s unchanged.",
+	"cli.conflicts.written":             "Resolved %s. Check it, then run: git add %s",
+	"cli.compareIdentical":              "%s and %s are identical.",
+	"cli.owners.unowned":                "Unowned files",
+	"cli.owners.noWebhook":              "No webhook for %s in owners.webhooks; not posted.",
+	"cli.owners.posted":                 "Posted the section for %s.",
+	"cli.importHeader":                  "%s: %d file(s) changed, +%d -%d",
+	"cli.issues.stats":                  "%d commit(s), %d file(s), +%d -%d",
+	"cli.issues.unlinked":               "Other changes",
+	"cli.structuredFallback":            "The model did not return a structured review; showing its answer as text.",
+	"cli.noIssues":                      "No issues found.",
+	"cli.issueCounts":                   "%d critical, %d important, %d minor",
+	"cli.label.answer":                  "Answer",
+	"cli.label.questions":               "Discussion questions for %s",
+	"cli.label.conflicts":               "Conflicts in %s",
+	"cli.savedTo":                       "Saved to %s",
+	"cli.copied":                        "Copied to clipboard.",
+	"cli.again":                         "Replaying %s of %s from %s",
+	"cli.prHeader":                      "by %s • %s ← %s • %s",
+	"cli.prPosted":                      "Review posted: %s",
+	"cli.annotationsDropped":            "%d comment(s) referred to lines outside the diff and were dropped.",
+	"cli.teaching":                      "Writing teaching comments...",
+	"cli.teachWritten":                  "%d annotated file(s) with %d note(s) written to %s",
+	"cli.reviewBlocked":                 "%d finding(s) or issue(s) at or above %s severity",
+	"cli.ciPassed":                      "Passed: no findings or issues at or above %s severity",
+	"cli.hookInstalled":                 "Installed %s hook at %s (blocks on %s)",
+	"cli.hookRemoved":                   "Removed %s hook",
+	"cli.hookInstalledPrecommit":        "Installed pre-commit hook at %s (blocks when a staged file has more than %d unstaged changed lines)",
+	"cli.noHooks":                       "No DiffLearn hooks installed.",
+	"cli.streak.current":                "Current streak: %d day(s) (longest %d)",
+	"cli.streak.commits":                "Commits studied this week: %d/%d",
+	"cli.streak.commitsNoGoal":          "Commits studied this week: %d",
+	"cli.streak.explanations":           "AI explanations this week: %d/%d",
+	"cli.streak.explanationsNoGoal":     "AI explanations this week: %d",
+	"cli.streak.goalMet":                "Weekly goal reached!",
+	"cli.streak.weekly":                 "Weeks in a row with the goal met: %d",
+	"cli.streak.lastWeek":               "Last 7 days:",
+	"cli.team.synced":                   "Synced %d new event(s) to the team server as %s",
+	"cli.team.empty":                    "No team activity yet. Members push theirs with `difflearn team sync`.",
+	"cli.team.lastActive":               "last active %s",
+	"cli.team.questions":                "%d AI answer(s) this week, %d question(s) asked in total",
+	"cli.team.recent":                   "Recently studied:",
+	"cli.team.struggle":                 "Struggled with %s@%s: %d AI request(s), %d question(s)",
+	"cli.filesChanged":                  "%d file(s) changed,",
+	"cli.findings":                      "Pre-check findings:",
+	"cli.noFindings":                    "No issues found by static pre-checks.",
+	"cli.scorecard":                     "Rubric scorecard:",
+	"cli.gateFailed":                    "Gate failed: %s",
+	"cli.config.provider":               "Provider: %s",
+	"cli.config.model":                  "Model: %s",
+	"cli.config.available":              "LLM Available: %t",
+	"cli.config.baseURL":                "Base URL: %s",
+	"cli.config.uiLanguage":             "UI language: %s",
+	"cli.config.diffAlgorithm":          "Diff algorithm: %s",
+	"cli.config.level":                  "Audience level: %s",
+	"cli.config.language":               "Answer language: %s",
+	"cli.config.profile":                "Profile: %s",
+	"cli.config.fallback":               "Fallbacks: %s",
+	"cli.configSet.saved":               "Saved %s = %s to %s",
+	"cli.configSet.removed":             "Removed %s from %s",
+	"cli.configSet.notInFile":           "%s is not set in %s",
+	"cli.configSet.overridden":          "%s is set in the environment, which takes precedence over ~/.difflearn",
+	"cli.configSet.fromEnv":             "environment (%s)",
+	"cli.configSet.none":                "No settings are set; `difflearn config keys` lists them.",
+	"cli.configSet.savedProfile":        "Saved %s = %s to profile %s in %s",
+	"cli.configSet.profileIn":           "profile %s in %s",
+	"cli.configSet.overriddenByProfile": "The active profile %s sets %s, which takes precedence",
+	"cli.configSet.noProfiles":          "~/.difflearn has no profiles; add one with difflearn config set --profile <name> <key> <value>.",
+	"cli.configSet.profileSummary":      "%s (%d settings)",
+	"cli.setup.welcome":                 "Welcome to DiffLearn! Choose the AI provider that explains your diffs.",
+	"cli.setup.found":                   "Ready to use:",
+	"cli.setup.noneFound":               "No API key, CLI agent or Ollama server was found.",
+	"cli.setup.enterKey":                "Enter an API key (OpenAI, Anthropic or Google)",
+	"cli.setup.skip":                    "Skip for now (diffs work without AI; run difflearn setup later)",
+	"cli.setup.choose":                  "Choose [%d]: ",
+	"cli.setup.invalid":                 "Enter a number from 1 to %d.",
+	"cli.setup.pasteKey":                "Paste your API key (saved as %s in ~/.difflearn): ",
+	"cli.setup.noKey":                   "no API key entered; run difflearn setup to try again",
+	"cli.setup.skipped":                 "Skipped. Run difflearn setup whenever you want AI explanations.",
+	"cli.setup.saved":                   "Using %s; saved to %s",
+	"cli.setup.cliAuth":                 "Check that the CLI is signed in with: difflearn auth %s",
+	"cli.setup.fromEnv":                 "%s is set",
+	"cli.setup.fromCLI":                 "the %s command is installed",
+	"cli.setup.fromOllama":              "running at %s, model %s",
+	"cli.auth.active":                   "(active)",
+	"cli.auth.signedIn":                 "signed in",
+	"cli.auth.signedOut":                "not signed in",
+	"cli.auth.notInstalled":             "not installed (no %s command on PATH)",
+	"cli.auth.unknown":                  "installed; its sign-in cannot be checked",
+	"cli.auth.fixLogin":                 "Sign in with: difflearn auth %s --login",
+	"cli.auth.confirmLogin":             "Sign in to %s now?",
+	"cli.auth.sessionHint":              "When the session starts, type %s to sign in, then exit.",
+	"cli.auth.notReady":                 "%s is not ready to use",
+	"cli.update.latest":                 "You're on the latest version",
+	"cli.update.available":              "Update available: v%s -> v%s",
+	"cli.update.run":                    "Run: %s",
+	"cli.update.release":                "Release: %s",
+
+	"a11y.selected":     "%s (selected)",
+	"a11y.tabs":         "Tabs: %s",
+	"a11y.current":      "Current:",
+	"a11y.filesChanged": "%d file(s) changed",
+	"a11y.commit":       "Commit %d of %d: %s %s by %s",
+	"a11y.branch":       "Branch %d of %d: %s, %s",
+	"a11y.answer":       "Answer %d of %d from %s (%s), %.1f seconds:",
+	"a11y.answerEnd":    "End of answer from %s.",
+}
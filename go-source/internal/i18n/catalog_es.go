@@ -0,0 +1,345 @@
+package i18n
+
+var es = map[string]string{
+	"tui.loading":                 "Cargando...",
+	"tui.status.local":            "Cambios locales",
+	"tui.status.staged":           "Cambios preparados",
+	"tui.status.history":          "Vista de historial",
+	"tui.status.refreshing":       "Actualizando...",
+	"tui.status.loadingCommit":    "Cargando diff del commit...",
+	"tui.status.loaded":           "Cargado",
+	"tui.status.commitShown":      "Mostrando el diff del commit seleccionado (Esc vuelve a la lista)",
+	"tui.status.error":            "Error: %s",
+	"tui.status.annotating":       "Pidiendo comentarios por línea a la IA...",
+	"tui.status.annotated":        "%d comentario(s) añadidos, %d descartados (línea fuera del diff)",
+	"tui.status.again":            "Repitiendo la última solicitud de IA...",
+	"tui.status.againDone":        "Última solicitud de IA repetida (Tab vuelve al diff)",
+	"tui.status.noLLM":            "No hay LLM configurado; los comentarios de IA no están disponibles",
+	"tui.status.hunk":             "Bloque %d de %d en %s",
+	"tui.hunkLabel":               "el bloque %d de %d en %s",
+	"tui.status.discardLocalOnly": "Solo se puede descartar en la pestaña Local",
+	"tui.confirm.discardHunk":     "¿Descartar %s del árbol de trabajo? Antes se guarda un stash de seguridad.",
+	"tui.confirm.discardFile":     "¿Descartar todos los cambios sin preparar de %s? Antes se guarda un stash de seguridad.",
+	"tui.status.discardCancelled": "Descarte cancelado",
+	"tui.status.discarding":       "Descartando...",
+	"tui.status.discarded":        "Se descartó %s; recupéralo con git stash apply %s",
+	"tui.status.discardedNoStash": "Se descartó %s",
+	"tui.status.branches":         "Ramas (actual: %s) • / filtrar • Enter comparar con la actual • s cambiar • Esc volver",
+	"tui.status.branchFilter":     "Filtro: %s",
+	"tui.status.comparing":        "Comparando %s con %s...",
+	"tui.status.compared":         "Cambios en %s desde que se separó de %s (Esc vuelve a la lista)",
+	"tui.status.switching":        "Cambiando a %s...",
+	"tui.status.switched":         "Cambiado a %s",
+	"tui.status.switchedStashed":  "Cambiado a %s; los cambios sin confirmar se guardaron en un stash (git stash pop los restaura)",
+	"tui.status.graph":            "Grafo de commits de todas las ramas y etiquetas • Enter muestra el diff del commit",
+	"tui.graph.merge":             "fusión de %s",
+	"tui.graph.refs":              "referencias: %s",
+	"tui.status.loadingStages":    "Cargando la vista de preparación...",
+	"tui.status.stages":           "Vista de preparación: lo preparado, lo no preparado y ambos juntos (v vuelve al diff)",
+	"tui.stages.staged":           "HEAD → índice (preparado)",
+	"tui.stages.unstaged":         "índice → directorio de trabajo (sin preparar)",
+	"tui.stages.combined":         "HEAD → directorio de trabajo (ambos)",
+	"tui.stages.none":             "Sin cambios",
+	"tui.stages.binary":           "Archivo binario",
+	"tui.tab.local":               "Local",
+	"tui.tab.staged":              "Preparados",
+	"tui.tab.history":             "Historial",
+	"tui.tab.branches":            "Ramas",
+	"tui.tab.graph":               "Grafo",
+	"tui.help":                    "q salir • Tab cambiar • ↑↓ mover • Enter seleccionar • r actualizar • a comentar • . repetir IA • v vista de preparación • x descartar bloque • X descartar archivo",
+	"tui.noCommits":               "No se encontraron commits",
+	"tui.noChanges":               "No se encontraron cambios",
+	"tui.noBranches":              "Ninguna rama coincide con \"%s\"",
+
+	"file.revision":                "Revisión %d de %d (la más reciente primero)",
+	"file.help":                    "←/h anterior • →/l siguiente • ↑/↓ desplazar • q salir",
+	"file.noHistory":               "No hay historial para %s.",
+	"cli.web.started":              "Interfaz web en segundo plano en %s (pid %d).",
+	"cli.web.alreadyRunning":       "La interfaz web ya está en marcha en %s (pid %d).",
+	"cli.web.running":              "Interfaz web en marcha en %s (pid %d).",
+	"cli.web.repo":                 "Repositorio: %s",
+	"cli.web.log":                  "Registro: %s",
+	"cli.web.notRunning":           "La interfaz web no está en marcha en segundo plano.",
+	"cli.web.stopped":              "Interfaz web detenida (pid %d).",
+	"cli.web.none":                 "No hay ningún servidor web de DiffLearn en marcha.",
+	"cli.server.using":             "Usando el servidor de DiffLearn en %s (--no-server para ejecutarlo aquí).",
+	"cli.server.fallback":          "Se ejecuta aquí en su lugar: %v.",
+	"cli.backup.exported":          "Se escribió %s (%d archivos).",
+	"cli.backup.secretsExcluded":   "Se omitió %s; usa --encrypt para incluirlos con una frase de contraseña.",
+	"cli.backup.imported":          "Copia de seguridad del %s restaurada:",
+	"cli.backup.skipped":           "%s se mantiene (ya existe; --force lo reemplaza)",
+	"cli.backup.secretsMissing":    "La copia no incluye %s; vuelve a configurarlos en esta máquina.",
+	"cli.backup.noPassphrase":      "Se necesita una frase de contraseña: define DIFFLEARN_BACKUP_PASSPHRASE o ejecútalo en una terminal.",
+	"cli.backup.passphrase":        "Frase de contraseña: ",
+	"cli.backup.passphraseAgain":   "Repite la frase de contraseña: ",
+	"cli.backup.mismatch":          "Las frases de contraseña no coinciden.",
+	"cli.warmup.off":               "la caché de git está desactivada (DIFFLEARN_GIT_CACHE=false); no hay nada que preparar",
+	"cli.warmup.cleared":           "Caché de git eliminada en %s",
+	"cli.warmup.tree":              "Árbol de archivos e información del repositorio",
+	"cli.warmup.history":           "Historial (hasta %d commits)",
+	"cli.warmup.branches":          "Cambios de ramas respecto a %s",
+	"cli.warmup.done":              "%d resultados de git guardados en caché (%d ya lo estaban) en %s",
+	"cli.historyIndex.added":       "%d commits nuevos indexados",
+	"cli.historyIndex.updated":     "%d commits nuevos indexados, %d en total (%s)",
+	"cli.historyIndex.noMatches":   "No hay commits que coincidan.",
+	"cli.historyIndex.hotspot":     "+%d -%d, %d autores, último %s",
+	"cli.historyIndex.contributor": "+%d -%d, de %s a %s",
+	"cli.jj.noChanges":             "No hay cambios en %s.",
+	"cli.jj.noDescription":         "(sin descripción)",
+	"cli.jj.empty":                 "(vacío)",
+	"cli.jj.conflict":              "(conflicto)",
+	"cli.hg.noChangesets":          "No hay changesets en %s.",
+	"cli.gitOnly":                  "esto necesita un repositorio git",
+	"cli.publish.done":             "%s publicado en %s (%d archivos, %d commits)",
+	"cli.publish.hint":             "Sube %s a cualquier alojamiento estático para compartirlo.",
+	"cli.publish.noComments":       "Se publica sin comentarios de línea: %v",
+	"cli.exercise.header":          "Ejercicio: haz tú mismo el commit %s",
+	"cli.exercise.offline":         "No hay un LLM configurado, así que no hay requisitos; el commit cambia estos archivos:",
+	"cli.exercise.workIn":          "El worktree en %s está en el padre del commit. Haz el cambio ahí, con tu editor o como prefieras.",
+	"cli.exercise.checkLater":      "Cuando termines, ejecuta: difflearn exercise %s --check %s",
+	"cli.exercise.pressEnter":      "Pulsa Enter cuando tu intento esté listo para comparar... ",
+	"cli.exercise.nothingYet":      "Aún no ha cambiado nada en el worktree; cuando termines, ejecuta: difflearn exercise %s --check %s",
+	"cli.exercise.identical":       "Tu intento coincide exactamente con el commit.",
+	"cli.exercise.differences":     "Tu intento difiere del commit en %d archivo(s):",
+	"cli.exercise.legend":          "- las líneas solo están en el commit, + solo en tu intento",
+	"cli.exercise.kept":            "Worktree conservado en %s; elimínalo con git worktree remove --force %[1]s",
+	"cli.reverts.none":             "No hay commits revertidos en los últimos %d commits.",
+	"cli.reverts.match.message":    "(reversión, nombrada en su mensaje)",
+	"cli.reverts.match.patch":      "(reversión, su parche deshace el original)",
+	"cli.reverts.noteReverted":     "↩ revertido en %s",
+	"cli.reverts.noteReapplied":    "↩ revertido en %s, reaplicado en %s",
+	"cli.reverts.noteRevert":       "↩ revierte %s",
+	"cli.reverts.noteReapply":      "↪ reaplica %s",
+	"cli.reverts.samePatch":        "El commit reaplicado tiene el mismo parche que el original.",
+	"cli.models.header":            "Modelos de %s:",
+	"cli.models.none":              "%s no lista ningún modelo.",
+	"cli.models.pick":              "Modelo a usar (número o nombre, Enter para mantener el actual): ",
+	"cli.models.saved":             "Modelo de %s %s guardado en %s",
+	"cli.models.savedProfile":      "Modelo de %s %s guardado en el perfil %s de %s",
+	"cli.fallback":                 "%s falló, así que respondió %s (%s): %s",
+	"cli.retry":                    "%s: %s; reintentando en %s (reintento %d de %d)",
+	"cli.prompts.builtin":          "integrado",
+	"cli.prompts.invalid":          "plantilla no válida, se usa el prompt integrado: %v",
+	"cli.prompts.created":          "Se creó %s a partir del prompt integrado.",
+	"cli.chat.intro":               "Conversación sobre %s. Haz preguntas de seguimiento; /help muestra los comandos.",
+	"cli.chat.help":                "%s • Enter envía • /help comandos • Esc sale",
+	"cli.chat.thinking":            "Pensando…",
+	"cli.chat.commands":            "/staged          hablar de los cambios preparados\n/local           hablar de todos los cambios locales\n/against <ref>   comparar el árbol de trabajo con una referencia\n/file <ruta>     limitar el diff a un archivo (/file solo lo quita)\n/context         mostrar los archivos en contexto\n/reset           olvidar la conversación hasta ahora\n/quit            terminar la conversación",
+	"cli.chat.reset":               "Conversación borrada; la próxima pregunta empieza de cero.",
+	"cli.chat.context":             "Ahora se habla de %s (%d archivos cambiados).",
+	"cli.chat.usage":               "Uso: %s",
+	"cli.chat.unknown":             "Comando desconocido %s; /help muestra los comandos.",
+	"cli.sessions.none":            "No se encontraron sesiones de IA.",
+	"cli.sessions.meta":            "%s · %s/%s · %s",
+	"cli.sessions.prompt":          "Prompt:",
+	"cli.sessions.exported":        "Se exportaron %d sesiones a %s.",
+	"cli.promptSize.header":        "Tamaño estimado del prompt para %s",
+	"cli.promptSize.total":         "~%d tokens de prompt (diff ~%d) + %d reservados para la respuesta",
+	"cli.promptSize.files":         "Tokens por archivo (de mayor a menor):",
+	"cli.promptSize.moreFiles":     "... y %d archivos más",
+	"cli.promptSize.fitsBudget":    "Cabe en una sola petición dentro del presupuesto de contexto de %d tokens (DIFFLEARN_CONTEXT_TOKENS).",
+	"cli.promptSize.providers":     "Ventanas de contexto por defecto:",
+	"cli.promptSize.active":        "configurado",
+	"cli.promptSize.trimHint":      "Reduce la petición con --path <dir> o --path '!<glob>' para dejar fuera archivos generados o vendorizados.",
+	"cli.flashcards.generating":    "Creando tarjetas...",
+	"cli.flashcards.none":          "El modelo no encontró nada en estos cambios que merezca una tarjeta.",
+	"cli.flashcards.written":       "Se escribieron %d tarjetas en %s. Impórtalas en Anki con Archivo > Importar.",
+	"cli.quiz.title":               "Cuestionario",
+	"cli.quiz.generating":          "Redactando el cuestionario...",
+	"cli.quiz.grading":             "Corrigiendo...",
+	"cli.quiz.question":            "Pregunta %d de %d",
+	"cli.quiz.correct":             "Correcto",
+	"cli.quiz.wrong":               "No exactamente",
+	"cli.quiz.rightChoice":         "La respuesta es %d) %s.",
+	"cli.quiz.modelAnswer":         "Respuesta modelo: %s",
+	"cli.quiz.finished":            "Terminado: %d de %d correctas.",
+	"cli.quiz.score":               "Puntuación: %d/%d (%d%%)",
+	"cli.quiz.average":             "Media de %d cuestionarios: %d%%",
+	"cli.quiz.noHistory":           "Aún no hay puntuaciones para este repositorio. Ejecuta difflearn quiz para hacer uno.",
+	"cli.quiz.helpChoice":          "↑/↓ o 1-9: elegir • enter: responder • q: salir",
+	"cli.quiz.helpShort":           "escribe tu respuesta • enter: enviar • esc: salir",
+	"cli.quiz.helpNext":            "enter: siguiente • q: salir",
+	"cli.quiz.helpDone":            "enter o q: salir",
+	"story.step":                   "Commit %d de %d · %s %s",
+	"story.pane.diff":              "Diff",
+	"story.pane.explanation":       "Explicación",
+	"story.pane.questions":         "Preguntas",
+	"story.generating":             "Consultando al LLM...",
+	"story.questionsNeedAI":        "Las preguntas de comprensión necesitan un LLM. Ejecuta sin --no-ai cuando haya uno configurado.",
+	"story.help":                   "n/p: commit siguiente/anterior • tab/1-3: panel • j/k: desplazar • a: ver respuestas • q: salir",
+
+	"cli.noChanges":                     "No se encontraron cambios.",
+	"cli.smart.usedStaged":              "No hay cambios sin preparar; se usan los cambios preparados.",
+	"cli.smart.usedUnstaged":            "No hay nada preparado; se usan los cambios sin preparar.",
+	"cli.precommit.nothingStaged":       "No hay nada preparado. Prepara los cambios con git add primero.",
+	"cli.precommit.header":              "Vas a confirmar %d archivo(s):",
+	"cli.precommit.edited":              "%s se editó después de prepararlo (+%d -%d sin preparar)",
+	"cli.precommit.editedHint":          "El commit toma la versión preparada; vuelve a hacer git add de esos archivos para incluir las ediciones posteriores.",
+	"cli.precommit.blocked":             "%d archivo(s) preparado(s) tienen más de %d líneas cambiadas sin preparar; prepáralas o guárdalas en un stash, o confirma con --no-verify",
+	"cli.release.header":                "Cambios de %s a %s",
+	"cli.release.stats":                 "%d commit(s) de %d autor(es), %d archivo(s) modificado(s), +%d -%d",
+	"cli.release.needTags":              "comparar las últimas versiones requiere dos etiquetas y el repositorio tiene %d; indica las versiones, p. ej. difflearn release v1.0 v1.1",
+	"cli.changelog.unreleased":          "Sin publicar",
+	"cli.changelog.section.breaking":    "⚠ Cambios incompatibles",
+	"cli.changelog.section.feat":        "Funcionalidades",
+	"cli.changelog.section.fix":         "Correcciones",
+	"cli.changelog.section.perf":        "Rendimiento",
+	"cli.changelog.section.refactor":    "Refactorización",
+	"cli.changelog.section.docs":        "Documentación",
+	"cli.changelog.section.test":        "Pruebas",
+	"cli.changelog.section.build":       "Compilación",
+	"cli.changelog.section.ci":          "Integración continua",
+	"cli.changelog.section.style":       "Estilo",
+	"cli.changelog.section.chore":       "Mantenimiento",
+	"cli.changelog.section.revert":      "Reversiones",
+	"cli.changelog.section.other":       "Otros cambios",
+	"cli.releaseNotes.dependencies":     "Dependencias",
+	"cli.releaseNotes.apiRemoved":       "`%s` se eliminó de `%s`",
+	"cli.releaseNotes.apiChanged":       "`%s` cambió en `%s`: `%s` → `%s`",
+	"cli.releaseNotes.apiAdded":         "Nueva API en `%s`: %s",
+	"cli.releaseNotes.depUpdated":       "`%s` actualizada %s → %s (%s)",
+	"cli.releaseNotes.depAdded":         "`%s` %s añadida (%s)",
+	"cli.releaseNotes.depRemoved":       "`%s` %s eliminada (%s)",
+	"cli.releaseNotes.nothing":          "Sin cambios incompatibles, funciones, correcciones ni cambios de dependencias en %s.",
+	"cli.noCommitsInRange":              "No hay commits en %s.",
+	"cli.noStashes":                     "No hay stashes.",
+	"cli.noLLM":                         "No hay una clave de API de LLM configurada.",
+	"cli.noLLMOffline":                  "No hay una clave de API de LLM configurada. Se muestra un análisis sin conexión.",
+	"cli.label.explanation":             "Explicación",
+	"cli.label.explanationOf":           "Explicación de %s",
+	"cli.label.narrative":               "La historia del autor",
+	"cli.label.requirements":            "Requisitos",
+	"cli.label.exerciseFeedback":        "Tu intento comparado con el commit",
+	"cli.label.revert":                  "Por qué se revirtió",
+	"cli.label.review":                  "Revisión de código",
+	"cli.label.summary":                 "Resumen",
+	"cli.label.rangeReview":             "Revisión del rango",
+	"cli.label.releaseNotes":            "Notas de la versión",
+	"cli.label.commitReview":            "Revisión de %s %s",
+	"cli.label.refined":                 "%s (refinada)",
+	"cli.label.comparing":               "%s (comparando %s)",
+	"cli.refining":                      "Redactando la revisión y verificando sus hallazgos contra el diff...",
+	"cli.generating":                    "Generando la respuesta...",
+	"cli.exportGenerating":              "Generando %s...",
+	"cli.usage.totals":                  "Uso del LLM",
+	"cli.usage.day":                     "Hoy: %d solicitud(es), %d tokens",
+	"cli.usage.month":                   "Este mes: %d solicitud(es), %d tokens",
+	"cli.usage.endpoint":                "%s: %d solicitud(es), %d tokens",
+	"cli.usage.noBudget":                "No hay presupuesto configurado (DIFFLEARN_LLM_BUDGET).",
+	"cli.usage.budget":                  "Presupuestos",
+	"cli.usage.resets":                  "se reinicia el %s",
+	"cli.conflicts.none":                "No hay conflictos de fusión.",
+	"cli.conflicts.noMarkers":           "%s no tiene marcadores de conflicto.",
+	"cli.conflicts.header":              "%s: %d conflicto(s)",
+	"cli.conflicts.at":                  "Conflicto %d en la línea %d",
+	"cli.conflicts.ours":                "Nuestro (%s)",
+	"cli.conflicts.base":                "Base",
+	"cli.conflicts.theirs":              "Suyo (%s)",
+	"cli.conflicts.confirm":             "¿Escribir la resolución propuesta en %s?",
+	"cli.conflicts.skipped":             "%s no se ha modificado.",
+	"cli.conflicts.written":             "%s resuelto. Revísalo y ejecuta: git add %s",
+	"cli.compareIdentical":              "%s y %s son idénticos.",
+	"cli.owners.unowned":                "Archivos sin responsable",
+	"cli.owners.noWebhook":              "No hay webhook para %s en owners.webhooks; no se publicó.",
+	"cli.owners.posted":                 "Sección de %s publicada.",
+	"cli.importHeader":                  "%s: %d archivo(s) modificado(s), +%d -%d",
+	"cli.issues.stats":                  "%d commit(s), %d archivo(s), +%d -%d",
+	"cli.issues.unlinked":               "Otros cambios",
+	"cli.structuredFallback":            "El modelo no devolvió una revisión estructurada; se muestra su respuesta como texto.",
+	"cli.noIssues":                      "No se encontraron problemas.",
+	"cli.issueCounts":                   "%d críticos, %d importantes, %d menores",
+	"cli.label.answer":                  "Respuesta",
+	"cli.label.questions":               "Preguntas de debate sobre %s",
+	"cli.label.conflicts":               "Conflictos en %s",
+	"cli.savedTo":                       "Guardado en %s",
+	"cli.copied":                        "Copiado al portapapeles.",
+	"cli.again":                         "Repitiendo %s de %s del %s",
+	"cli.prHeader":                      "por %s • %s ← %s • %s",
+	"cli.prPosted":                      "Revisión publicada: %s",
+	"cli.annotationsDropped":            "%d comentario(s) se referían a líneas fuera del diff y se descartaron.",
+	"cli.teaching":                      "Escribiendo comentarios didácticos...",
+	"cli.teachWritten":                  "%d archivo(s) anotados con %d nota(s) escritos en %s",
+	"cli.reviewBlocked":                 "%d hallazgo(s) o problema(s) de severidad %s o mayor",
+	"cli.ciPassed":                      "Aprobado: ningún hallazgo ni problema de severidad %s o mayor",
+	"cli.hookInstalled":                 "Hook %s instalado en %s (bloquea con %s)",
+	"cli.hookRemoved":                   "Hook %s eliminado",
+	"cli.hookInstalledPrecommit":        "Hook pre-commit instalado en %s (bloquea cuando un archivo preparado tiene más de %d líneas cambiadas sin preparar)",
+	"cli.noHooks":                       "No hay hooks de DiffLearn instalados.",
+	"cli.streak.current":                "Racha actual: %d día(s) (la más larga %d)",
+	"cli.streak.commits":                "Commits estudiados esta semana: %d/%d",
+	"cli.streak.commitsNoGoal":          "Commits estudiados esta semana: %d",
+	"cli.streak.explanations":           "Explicaciones de IA esta semana: %d/%d",
+	"cli.streak.explanationsNoGoal":     "Explicaciones de IA esta semana: %d",
+	"cli.streak.goalMet":                "¡Meta semanal alcanzada!",
+	"cli.streak.weekly":                 "Semanas seguidas con la meta cumplida: %d",
+	"cli.streak.lastWeek":               "Últimos 7 días:",
+	"cli.team.synced":                   "Se sincronizaron %d evento(s) nuevo(s) con el servidor del equipo como %s",
+	"cli.team.empty":                    "Aún no hay actividad del equipo. Los miembros envían la suya con `difflearn team sync`.",
+	"cli.team.lastActive":               "última actividad %s",
+	"cli.team.questions":                "%d respuesta(s) de IA esta semana, %d pregunta(s) en total",
+	"cli.team.recent":                   "Estudiado recientemente:",
+	"cli.team.struggle":                 "Dificultades con %s@%s: %d solicitud(es) de IA, %d pregunta(s)",
+	"cli.filesChanged":                  "%d archivo(s) modificado(s),",
+	"cli.findings":                      "Hallazgos de las comprobaciones previas:",
+	"cli.noFindings":                    "Las comprobaciones estáticas no encontraron problemas.",
+	"cli.scorecard":                     "Puntuación según la rúbrica:",
+	"cli.gateFailed":                    "Criterio bloqueante no superado: %s",
+	"cli.config.provider":               "Proveedor: %s",
+	"cli.config.model":                  "Modelo: %s",
+	"cli.config.available":              "LLM disponible: %t",
+	"cli.config.baseURL":                "URL base: %s",
+	"cli.config.uiLanguage":             "Idioma de la interfaz: %s",
+	"cli.config.diffAlgorithm":          "Algoritmo de diff: %s",
+	"cli.config.level":                  "Nivel de la audiencia: %s",
+	"cli.config.language":               "Idioma de las respuestas: %s",
+	"cli.config.profile":                "Perfil: %s",
+	"cli.config.fallback":               "Alternativas: %s",
+	"cli.configSet.saved":               "%s = %s guardado en %s",
+	"cli.configSet.removed":             "%s eliminado de %s",
+	"cli.configSet.notInFile":           "%s no está definido en %s",
+	"cli.configSet.overridden":          "%s está definido en el entorno, que tiene prioridad sobre ~/.difflearn",
+	"cli.configSet.fromEnv":             "entorno (%s)",
+	"cli.configSet.none":                "No hay ajustes definidos; `difflearn config keys` los enumera.",
+	"cli.configSet.savedProfile":        "%s = %s guardado en el perfil %s de %s",
+	"cli.configSet.profileIn":           "perfil %s de %s",
+	"cli.configSet.overriddenByProfile": "El perfil activo %s define %s, que tiene prioridad",
+	"cli.configSet.noProfiles":          "~/.difflearn no tiene perfiles; crea uno con difflearn config set --profile <nombre> <clave> <valor>.",
+	"cli.configSet.profileSummary":      "%s (%d ajustes)",
+	"cli.setup.welcome":                 "¡Bienvenido a DiffLearn! Elige el proveedor de IA que explicará tus diffs.",
+	"cli.setup.found":                   "Listos para usar:",
+	"cli.setup.noneFound":               "No se encontró ninguna clave de API, agente de CLI ni servidor Ollama.",
+	"cli.setup.enterKey":                "Introducir una clave de API (OpenAI, Anthropic o Google)",
+	"cli.setup.skip":                    "Omitir por ahora (los diffs funcionan sin IA; ejecuta difflearn setup más tarde)",
+	"cli.setup.choose":                  "Elige [%d]: ",
+	"cli.setup.invalid":                 "Introduce un número del 1 al %d.",
+	"cli.setup.pasteKey":                "Pega tu clave de API (se guarda como %s en ~/.difflearn): ",
+	"cli.setup.noKey":                   "no se introdujo ninguna clave de API; ejecuta difflearn setup para volver a intentarlo",
+	"cli.setup.skipped":                 "Omitido. Ejecuta difflearn setup cuando quieras explicaciones de IA.",
+	"cli.setup.saved":                   "Usando %s; guardado en %s",
+	"cli.setup.cliAuth":                 "Comprueba que la CLI tiene la sesión iniciada con: difflearn auth %s",
+	"cli.setup.fromEnv":                 "%s está definida",
+	"cli.setup.fromCLI":                 "el comando %s está instalado",
+	"cli.setup.fromOllama":              "en ejecución en %s, modelo %s",
+	"cli.auth.active":                   "(activo)",
+	"cli.auth.signedIn":                 "sesión iniciada",
+	"cli.auth.signedOut":                "sin sesión iniciada",
+	"cli.auth.notInstalled":             "no instalado (no hay ningún comando %s en el PATH)",
+	"cli.auth.unknown":                  "instalado; no se puede comprobar su inicio de sesión",
+	"cli.auth.fixLogin":                 "Inicia sesión con: difflearn auth %s --login",
+	"cli.auth.confirmLogin":             "¿Iniciar sesión en %s ahora?",
+	"cli.auth.sessionHint":              "Cuando empiece la sesión, escribe %s para iniciar sesión y luego sal.",
+	"cli.auth.notReady":                 "%s no está listo para usarse",
+	"cli.update.latest":                 "Tienes la versión más reciente",
+	"cli.update.available":              "Actualización disponible: v%s -> v%s",
+	"cli.update.run":                    "Ejecuta: %s",
+	"cli.update.release":                "Versión: %s",
+
+	"a11y.selected":     "%s (seleccionado)",
+	"a11y.tabs":         "Pestañas: %s",
+	"a11y.current":      "Actual:",
+	"a11y.filesChanged": "%d archivo(s) modificado(s)",
+	"a11y.commit":       "Commit %d de %d: %s %s de %s",
+	"a11y.branch":       "Rama %d de %d: %s, %s",
+	"a11y.answer":       "Respuesta %d de %d de %s (%s), %.1f segundos:",
+	"a11y.answerEnd":    "Fin de la respuesta de %s.",
+}
@@ -0,0 +1,99 @@
+// Package i18n translates DiffLearn's own interface text. It is independent of
+// the language the LLM answers in.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const DefaultLocale = "en"
+
+var catalogs = map[string]map[string]string{
+	"en": en,
+	"es": es,
+	"de": de,
+}
+
+var (
+	mu      sync.RWMutex
+	current = DefaultLocale
+)
+
+// Supported lists the bundled locales.
+func Supported() []string {
+	out := make([]string, 0, len(catalogs))
+	for k := range catalogs {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Normalize maps values such as "de_DE.UTF-8" or "es-MX" to a bundled locale.
+func Normalize(value string) (string, bool) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(v, ".@"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" || v == "c" || v == "posix" {
+		return "", false
+	}
+	if _, ok := catalogs[v]; ok {
+		return v, true
+	}
+	base, _, _ := strings.Cut(strings.ReplaceAll(v, "-", "_"), "_")
+	if _, ok := catalogs[base]; ok {
+		return base, true
+	}
+	return "", false
+}
+
+// Detect picks the locale from preferred (e.g. DIFFLEARN_UI_LANG) and falls
+// back to the usual POSIX locale variables.
+func Detect(preferred string) string {
+	candidates := []string{preferred, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, c := range candidates {
+		if l, ok := Normalize(c); ok {
+			return l
+		}
+	}
+	return DefaultLocale
+}
+
+// SetLocale switches the active locale; unknown values fall back to English.
+func SetLocale(value string) string {
+	l, ok := Normalize(value)
+	if !ok {
+		l = DefaultLocale
+	}
+	mu.Lock()
+	current = l
+	mu.Unlock()
+	return l
+}
+
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T returns the translation of key for the active locale, formatted with args.
+// Missing translations fall back to English and then to the key itself.
+func T(key string, args ...any) string {
+	msg, ok := catalogs[Locale()][key]
+	if !ok {
+		msg, ok = en[key]
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,40 @@
+package i18n
+
+import "testing"
+
+func TestCatalogsCoverEnglishKeys(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for key := range en {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("locale %s is missing %q", locale, key)
+			}
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	if got := Detect(""); got != "de" {
+		t.Fatalf("expected de from LANG, got %s", got)
+	}
+	if got := Detect("es-MX"); got != "es" {
+		t.Fatalf("expected preferred es, got %s", got)
+	}
+	t.Setenv("LANG", "C")
+	if got := Detect("xx"); got != DefaultLocale {
+		t.Fatalf("expected fallback to %s, got %s", DefaultLocale, got)
+	}
+}
+
+func TestTFallsBack(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+	SetLocale("es")
+	if got := T("cli.gateFailed", "Tests"); got != "Criterio bloqueante no superado: Tests" {
+		t.Fatalf("unexpected translation: %s", got)
+	}
+	if got := T("missing.key"); got != "missing.key" {
+		t.Fatalf("expected key fallback, got %s", got)
+	}
+}
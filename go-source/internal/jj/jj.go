@@ -0,0 +1,198 @@
+// Package jj reads changes from Jujutsu (jj) repositories. jj stores its
+// commits in git, so diffs come out of `jj diff --git` in the format the
+// git package parses, and a change's commit ID is a git commit hash the
+// git package can read from GitDir.
+package jj
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/git"
+)
+
+// ExecRunner runs the jj binary. It has the signature of git.GitRunner, so
+// a git.FakeRunner can stand in for it in tests.
+type ExecRunner struct{}
+
+func (ExecRunner) Run(dir string, args ...string) (string, error) {
+	// Colors and the pager would end up in the parsed output.
+	cmd := exec.Command("jj", append([]string{"--no-pager", "--color=never"}, args...)...)
+	cmd.Dir = dir
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("jj is not installed or not on PATH")
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("jj %s: %s", strings.Join(args, " "), msg)
+	}
+	return out.String(), nil
+}
+
+// Find returns the root of the jj repository containing path: the nearest
+// directory with a .jj directory in it.
+func Find(path string) (string, bool) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ".jj")); err == nil && info.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Repo is a jj repository.
+type Repo struct {
+	root   string
+	runner git.GitRunner
+}
+
+// Open returns the jj repository containing path.
+func Open(path string) (*Repo, error) {
+	root, ok := Find(path)
+	if !ok {
+		return nil, fmt.Errorf("not a jj repository (no .jj directory in %s or above)", path)
+	}
+	return NewRepo(root, ExecRunner{}), nil
+}
+
+// NewRepo is Open for a known root with a custom runner.
+func NewRepo(root string, runner git.GitRunner) *Repo {
+	return &Repo{root: root, runner: runner}
+}
+
+// Root is the workspace root.
+func (r *Repo) Root() string {
+	return r.root
+}
+
+// Colocated reports whether the workspace is also a git working tree
+// (jj git init --colocate), where the rest of DiffLearn works as usual: git's
+// HEAD is the working-copy change's parent, so the working tree diff is the
+// working-copy change.
+func (r *Repo) Colocated() bool {
+	_, err := os.Stat(filepath.Join(r.root, ".git"))
+	return err == nil
+}
+
+// GitDir is where git can read the repository's commits: the workspace of a
+// colocated repository, otherwise the git store inside .jj.
+func (r *Repo) GitDir() string {
+	if r.Colocated() {
+		return r.root
+	}
+	store := filepath.Join(r.root, ".jj", "repo", "store")
+	// A secondary workspace keeps the path of the main repository here.
+	if b, err := os.ReadFile(filepath.Join(r.root, ".jj", "repo")); err == nil {
+		store = filepath.Join(resolve(filepath.Join(r.root, ".jj"), strings.TrimSpace(string(b))), "store")
+	}
+	target := "git"
+	if b, err := os.ReadFile(filepath.Join(store, "git_target")); err == nil {
+		target = strings.TrimSpace(string(b))
+	}
+	return resolve(store, target)
+}
+
+func resolve(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// Diff returns the changes of revset, "@" (the working-copy change) when
+// empty. A revset of several changes is diffed as one, from the parents of
+// its roots to its heads, so it must not have gaps.
+func (r *Repo) Diff(revset string) ([]git.ParsedDiff, error) {
+	if revset == "" {
+		revset = "@"
+	}
+	out, err := r.runner.Run(r.root, "diff", "--git", "-r", revset)
+	if err != nil {
+		return nil, err
+	}
+	return git.NewDiffParser().Parse(out), nil
+}
+
+// DiffRange returns the difference between the contents of two revisions.
+func (r *Repo) DiffRange(from, to string) ([]git.ParsedDiff, error) {
+	if from == "" {
+		from = "@-"
+	}
+	if to == "" {
+		to = "@"
+	}
+	out, err := r.runner.Run(r.root, "diff", "--git", "--from", from, "--to", to)
+	if err != nil {
+		return nil, err
+	}
+	return git.NewDiffParser().Parse(out), nil
+}
+
+// Change is one jj change at its current commit.
+type Change struct {
+	ChangeID string    `json:"changeId"`
+	CommitID string    `json:"commitId"`
+	Author   string    `json:"author"`
+	Email    string    `json:"email"`
+	Time     time.Time `json:"time"`
+	Subject  string    `json:"subject"`
+	Empty    bool      `json:"empty,omitempty"`
+	Conflict bool      `json:"conflict,omitempty"`
+}
+
+// Commit describes the change as a git commit, for code that reads commits
+// through the git package.
+func (c Change) Commit() git.CommitInfo {
+	return git.CommitInfo{Hash: c.CommitID, Date: c.Time.Format(time.RFC3339), Message: c.Subject, Author: c.Author, Files: []string{}}
+}
+
+// changeTemplate prints one tab-separated line per change; the description
+// comes last since it is the only field that may hold a tab.
+const changeTemplate = `change_id.short(12) ++ "\t" ++ commit_id ++ "\t" ++ author.name() ++ "\t" ++ author.email() ++ "\t" ++ author.timestamp().format("%s") ++ "\t" ++ if(empty, "1", "0") ++ "\t" ++ if(conflict, "1", "0") ++ "\t" ++ description.first_line() ++ "\n"`
+
+// Changes lists the changes of revset in jj's order, newest first, at most
+// limit of them when limit is positive.
+func (r *Repo) Changes(revset string, limit int) ([]Change, error) {
+	args := []string{"log", "--no-graph", "-r", revset, "-T", changeTemplate}
+	if limit > 0 {
+		args = append(args, "--limit", strconv.Itoa(limit))
+	}
+	out, err := r.runner.Run(r.root, args...)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]Change, 0)
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.SplitN(line, "\t", 8)
+		if len(f) < 8 {
+			continue
+		}
+		c := Change{ChangeID: f[0], CommitID: f[1], Author: f[2], Email: f[3], Empty: f[5] == "1", Conflict: f[6] == "1", Subject: f[7]}
+		if secs, err := strconv.ParseInt(f[4], 10, 64); err == nil {
+			c.Time = time.Unix(secs, 0)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
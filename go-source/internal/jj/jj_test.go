@@ -0,0 +1,138 @@
+package jj
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"difflearn-go/internal/git"
+)
+
+func TestFindAndGitDir(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src", "pkg")
+	store := filepath.Join(root, ".jj", "repo", "store")
+	for _, dir := range []string{sub, filepath.Join(store, "git")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, ok := Find(sub)
+	if !ok || found != root {
+		t.Fatalf("Find(%s) = %q, %v", sub, found, ok)
+	}
+	if _, ok := Find(t.TempDir()); ok {
+		t.Fatal("found a jj repository where there is none")
+	}
+
+	r := NewRepo(root, git.NewFakeRunner())
+	if r.Colocated() {
+		t.Fatal("not colocated without .git")
+	}
+	if got := r.GitDir(); got != filepath.Join(store, "git") {
+		t.Fatalf("GitDir = %q", got)
+	}
+	if err := os.WriteFile(filepath.Join(store, "git_target"), []byte("../../../.git"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.GitDir(); got != filepath.Join(root, ".git") {
+		t.Fatalf("GitDir with git_target = %q", got)
+	}
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Colocated() || r.GitDir() != root {
+		t.Fatalf("colocated repository: %v %q", r.Colocated(), r.GitDir())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	patch := "diff --git a/main.go b/main.go\nindex 1111111..2222222 100644\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old()\n+new()\n"
+	runner := git.NewFakeRunner().
+		On(patch, "diff", "--git", "-r", "@").
+		On(patch, "diff", "--git", "--from", "main", "--to", "@")
+	r := NewRepo(t.TempDir(), runner)
+	diffs, err := r.Diff("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].NewFile != "main.go" || diffs[0].Additions != 1 || diffs[0].Deletions != 1 {
+		t.Fatalf("unexpected diff: %+v", diffs)
+	}
+	if diffs, err := r.DiffRange("main", ""); err != nil || len(diffs) != 1 {
+		t.Fatalf("DiffRange: %v %v", diffs, err)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	out := "kxqpmvrwzsot\tabc123\tAda\tada@example.com\t1700000000\t0\t0\tAdd parser\n" +
+		"yqosqzytrlsw\tdef456\tAda\tada@example.com\t1699990000\t1\t1\t\n"
+	runner := git.NewFakeRunner().On(out, "log", "--no-graph", "-r", "trunk()..@", "-T", changeTemplate, "--limit", "5")
+	changes, err := NewRepo(t.TempDir(), runner).Changes("trunk()..@", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes", len(changes))
+	}
+	first := changes[0]
+	if first.ChangeID != "kxqpmvrwzsot" || first.CommitID != "abc123" || first.Subject != "Add parser" || first.Empty || !first.Time.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected change: %+v", first)
+	}
+	if !changes[1].Empty || !changes[1].Conflict || changes[1].Subject != "" {
+		t.Fatalf("unexpected change: %+v", changes[1])
+	}
+	if c := first.Commit(); c.Hash != "abc123" || c.Message != "Add parser" {
+		t.Fatalf("unexpected commit: %+v", c)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	patch := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old()\n+new()\n"
+	graph := "@  \x1ebbb\x1faaa\x1fmain\x1f2024-01-02T03:04:05+00:00\x1fBob\x1fFix\n" +
+		"○  \x1eaaa\x1f" + rootCommit + "\x1f\x1f2024-01-01T00:00:00+00:00\x1fAda\x1fStart\n" +
+		"~\n"
+	runner := git.NewFakeRunner().
+		On(patch, "diff", "--git", "--ignore-all-space", "--from", "@-", "--to", "@").
+		On(patch, "diff", "--git", "-r", "@", "--", `file:"src/main.go"`).
+		On(patch, "diff", "--git", "--from", `heads(::@- & ::"feature")`, "--to", `"feature"`).
+		On(graph, "log", "-r", "all() ~ root()", "-T", graphTemplate, "--limit", "50").
+		On("bbb\n", "log", "--no-graph", "-r", "@-", "-T", `commit_id ++ "\n"`, "--limit", "1").
+		On("main\tbbb\nold\taaa\n", "bookmark", "list", "-T", bookmarkTemplate)
+	var r git.Repository = NewRepo(t.TempDir(), runner)
+
+	if diffs, err := r.GetLocalDiff(git.DiffOptions{Staged: true}); err != nil || len(diffs) != 0 {
+		t.Fatalf("jj has no staged changes: %+v, %v", diffs, err)
+	}
+	for name, load := range map[string]func() ([]git.ParsedDiff, error){
+		"local": func() ([]git.ParsedDiff, error) {
+			return r.GetLocalDiff(git.DiffOptions{Against: "HEAD", Whitespace: git.WhitespaceOptions{IgnoreWhitespace: true}})
+		},
+		"file":   func() ([]git.ParsedDiff, error) { return r.GetFileDiff("src/main.go", "") },
+		"branch": func() ([]git.ParsedDiff, error) { return r.GetBranchDiff("HEAD", "feature") },
+	} {
+		if diffs, err := load(); err != nil || len(diffs) != 1 {
+			t.Fatalf("%s: %+v, %v", name, diffs, err)
+		}
+	}
+
+	g, err := r.GetCommitGraph(0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Commits) != 2 || len(g.Rows) != 3 || g.Commits[0].Refs[0] != "main" {
+		t.Fatalf("unexpected graph %+v", g)
+	}
+	if len(g.Commits[1].Parents) != 0 {
+		t.Fatalf("the root commit should be dropped: %+v", g.Commits[1])
+	}
+
+	branches, err := r.GetBranchesDetailed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 2 || !branches[0].Current || branches[1].Current || branches[1].Commit != "aaa" {
+		t.Fatalf("unexpected bookmarks %+v", branches)
+	}
+}
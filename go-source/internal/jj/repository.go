@@ -0,0 +1,198 @@
+package jj
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// Repo implements git.Repository, so the dashboard and the AI commands read
+// a jj workspace that is not colocated with git as they read a git
+// repository. The working-copy change @ plays the uncommitted changes and
+// its parent @- plays HEAD; jj has no index, so nothing is ever staged.
+var _ git.Repository = (*Repo)(nil)
+
+// rootCommit is the commit ID of root(), which jj reports as the parent of
+// the first changes.
+const rootCommit = "0000000000000000000000000000000000000000"
+
+// graphTemplate writes the layout of git.ParseCommitGraph after jj's graph.
+const graphTemplate = "\"\x1e\" ++ commit_id ++ \"\x1f\" ++ parents.map(|c| c.commit_id()).join(\" \") ++ \"\x1f\" ++ bookmarks.map(|b| b.name()).join(\", \") ++ \"\x1f\" ++ author.timestamp().format(\"%Y-%m-%dT%H:%M:%S%:z\") ++ \"\x1f\" ++ author.name() ++ \"\x1f\" ++ description.first_line() ++ \"\\n\""
+
+// bookmarkTemplate prints the local bookmarks, one name and commit ID per
+// line; conflicted ones have no single target and print nothing.
+const bookmarkTemplate = `if(remote || !normal_target, "", name ++ "\t" ++ normal_target.commit_id() ++ "\n")`
+
+// revision translates git's HEAD, which callers use for the current
+// commit, to the working-copy change's parent.
+func revision(ref string) string {
+	if ref == "HEAD" {
+		return "@-"
+	}
+	return ref
+}
+
+// bookmarkRevset is revision for bookmark names, quoted for a revset.
+func bookmarkRevset(name string) string {
+	if name == "HEAD" {
+		return "@-"
+	}
+	return quote(name)
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// RepoPath is the workspace root.
+func (r *Repo) RepoPath() string {
+	return r.root
+}
+
+// IsRepo reports whether jj can read the workspace.
+func (r *Repo) IsRepo() bool {
+	_, err := r.runner.Run(r.root, "root")
+	return err == nil
+}
+
+// GetLocalDiff returns the working-copy change, or the difference between
+// options.Against and the working copy. Staged changes are always empty.
+func (r *Repo) GetLocalDiff(options git.DiffOptions) ([]git.ParsedDiff, error) {
+	if options.Staged && !options.All {
+		return []git.ParsedDiff{}, nil
+	}
+	args := whitespaceArgs(options.Whitespace)
+	if options.Against != "" && !options.All {
+		args = append(args, "--from", revision(options.Against), "--to", "@")
+	} else {
+		args = append(args, "-r", "@")
+	}
+	return r.diff(args...)
+}
+
+// whitespaceArgs maps the whitespace options jj diff has; it cannot ignore
+// blank lines.
+func whitespaceArgs(ws git.WhitespaceOptions) []string {
+	var args []string
+	if ws.IgnoreWhitespace {
+		args = append(args, "--ignore-all-space")
+	}
+	if ws.IgnoreSpaceChange {
+		args = append(args, "--ignore-space-change")
+	}
+	return args
+}
+
+// GetFileDiff returns one file's changes in the working-copy change, or in
+// commit.
+func (r *Repo) GetFileDiff(filePath, commit string) ([]git.ParsedDiff, error) {
+	rev := "@"
+	if commit != "" {
+		rev = revision(commit)
+	}
+	// file: patterns are literal paths, relative to the root jj runs in.
+	return r.diff("-r", rev, "--", "file:"+quote(filePath))
+}
+
+// GetCommitDiff returns the changes of commit1 when commit2 is empty,
+// otherwise the difference between the two.
+func (r *Repo) GetCommitDiff(commit1, commit2 string) ([]git.ParsedDiff, error) {
+	if commit2 == "" {
+		return r.Diff(revision(commit1))
+	}
+	return r.DiffRange(revision(commit1), revision(commit2))
+}
+
+// GetBranchDiff returns what bookmark branch2 changed since it diverged
+// from branch1, or with BranchModeDouble the difference between the two.
+func (r *Repo) GetBranchDiff(branch1, branch2 string, mode ...git.BranchDiffMode) ([]git.ParsedDiff, error) {
+	from, to := bookmarkRevset(branch1), bookmarkRevset(branch2)
+	if len(mode) == 0 || mode[0] != git.BranchModeDouble {
+		from = "heads(::" + from + " & ::" + to + ")"
+	}
+	return r.DiffRange(from, to)
+}
+
+// GetCommitHistory lists the newest limit ancestors of the working-copy
+// change's parent.
+func (r *Repo) GetCommitHistory(limit int) ([]git.CommitInfo, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	changes, err := r.Changes("::@- ~ root()", limit)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]git.CommitInfo, len(changes))
+	for i, c := range changes {
+		commits[i] = c.Commit()
+	}
+	return commits, nil
+}
+
+// GetBranchesDetailed lists the local bookmarks; the ones on @- are
+// current.
+func (r *Repo) GetBranchesDetailed() ([]git.BranchEntry, error) {
+	out, err := r.runner.Run(r.root, "bookmark", "list", "-T", bookmarkTemplate)
+	if err != nil {
+		return nil, err
+	}
+	head, _ := r.ResolveRef("HEAD")
+	entries := make([]git.BranchEntry, 0)
+	for _, line := range strings.Split(out, "\n") {
+		name, commit, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		entries = append(entries, git.BranchEntry{Name: name, Ref: name, Kind: git.BranchKindLocal, Current: commit == head, LocalName: name, Commit: commit})
+	}
+	return entries, nil
+}
+
+// GetCommitGraph returns the newest limit commits, all of them with
+// allBranches and otherwise the ancestors of @-, with the rows of jj's
+// graph.
+func (r *Repo) GetCommitGraph(limit int, allBranches bool) (git.CommitGraph, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	revset := "::@- ~ root()"
+	if allBranches {
+		revset = "all() ~ root()"
+	}
+	out, err := r.runner.Run(r.root, "log", "-r", revset, "-T", graphTemplate, "--limit", strconv.Itoa(limit))
+	if err != nil {
+		return git.CommitGraph{}, err
+	}
+	graph := git.ParseCommitGraph(out)
+	for i, c := range graph.Commits {
+		parents := make([]string, 0, len(c.Parents))
+		for _, p := range c.Parents {
+			if p != rootCommit {
+				parents = append(parents, p)
+			}
+		}
+		graph.Commits[i].Parents = parents
+	}
+	return graph, nil
+}
+
+// ResolveRef returns the commit ID of a revision.
+func (r *Repo) ResolveRef(ref string) (string, error) {
+	out, err := r.runner.Run(r.root, "log", "--no-graph", "-r", revision(ref), "-T", `commit_id ++ "\n"`, "--limit", "1")
+	id := strings.TrimSpace(out)
+	if err != nil || id == "" {
+		return "", fmt.Errorf("unknown revision %q", ref)
+	}
+	return id, nil
+}
+
+func (r *Repo) diff(args ...string) ([]git.ParsedDiff, error) {
+	out, err := r.runner.Run(r.root, append([]string{"diff", "--git"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return git.NewDiffParser().Parse(out), nil
+}
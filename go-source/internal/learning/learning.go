@@ -0,0 +1,118 @@
+// Package learning tracks study activity (commits read, AI explanations
+// received) and turns it into weekly goals and streaks.
+package learning
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+const (
+	// KindCommit is recorded when a commit's diff is opened.
+	KindCommit = "commit"
+	// KindExercise is recorded when an attempt at an exercise is checked.
+	KindExercise = "exercise"
+)
+
+// Event is one study activity. Kind is KindCommit or the AI command that
+// answered (explain, review, summary, ask).
+type Event struct {
+	Time time.Time `json:"time"`
+	Repo string    `json:"repo"`
+	Kind string    `json:"kind"`
+	Ref  string    `json:"ref,omitempty"`
+}
+
+// Goal is the weekly target; zero disables a part.
+type Goal struct {
+	CommitsPerWeek      int `json:"commitsPerWeek"`
+	ExplanationsPerWeek int `json:"explanationsPerWeek"`
+}
+
+// GoalFromConfig reads DIFFLEARN_GOAL_COMMITS (default 5) and
+// DIFFLEARN_GOAL_EXPLANATIONS (default 0).
+func GoalFromConfig() Goal {
+	g := Goal{CommitsPerWeek: 5}
+	if n, err := strconv.Atoi(config.Setting("DIFFLEARN_GOAL_COMMITS")); err == nil && n >= 0 {
+		g.CommitsPerWeek = n
+	}
+	if n, err := strconv.Atoi(config.Setting("DIFFLEARN_GOAL_EXPLANATIONS")); err == nil && n >= 0 {
+		g.ExplanationsPerWeek = n
+	}
+	return g
+}
+
+// Met reports whether a week with the given counts reaches the goal. A goal
+// with every part disabled is met by any activity.
+func (g Goal) Met(commits, explanations int) bool {
+	if g.CommitsPerWeek == 0 && g.ExplanationsPerWeek == 0 {
+		return commits+explanations > 0
+	}
+	return commits >= g.CommitsPerWeek && explanations >= g.ExplanationsPerWeek
+}
+
+type Store struct {
+	path string
+}
+
+// Open returns the store in config.DataDir.
+func Open() *Store {
+	return NewStore(filepath.Join(config.DataDir(), "learning.jsonl"))
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+func (s *Store) Append(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func (s *Store) Events() ([]Event, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Event
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Event
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			out = append(out, e)
+		}
+	}
+	return out, sc.Err()
+}
+
+// Record appends an event to the default store. Tracking is best effort and
+// never fails the command that studied something.
+func Record(repo, kind, ref string) {
+	_ = Open().Append(Event{Repo: repo, Kind: kind, Ref: ref})
+}
@@ -0,0 +1,128 @@
+package learning
+
+import (
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+type DayActivity struct {
+	Date   string `json:"date"`
+	Events int    `json:"events"`
+}
+
+// Progress summarizes activity for the current week and over time. Weeks
+// start on Monday in local time.
+type Progress struct {
+	Goal                 Goal   `json:"goal"`
+	WeekStart            string `json:"weekStart"`
+	CommitsThisWeek      int    `json:"commitsThisWeek"`
+	ExplanationsThisWeek int    `json:"explanationsThisWeek"`
+	GoalMet              bool   `json:"goalMet"`
+	// CurrentStreak counts consecutive active days ending today, or
+	// yesterday when nothing was studied yet today.
+	CurrentStreak int `json:"currentStreak"`
+	LongestStreak int `json:"longestStreak"`
+	// WeeklyStreak counts consecutive weeks that met the goal, including
+	// this week once it is met.
+	WeeklyStreak  int           `json:"weeklyStreak"`
+	LastSevenDays []DayActivity `json:"lastSevenDays"`
+	TotalCommits  int           `json:"totalCommits"`
+}
+
+type weekTally struct {
+	commits      map[string]bool
+	explanations int
+}
+
+// Compute derives progress from events as of now. A commit studied several
+// times counts once per week.
+func Compute(events []Event, goal Goal, now time.Time) Progress {
+	now = now.Local()
+	today := startOfDay(now)
+	thisWeek := startOfWeek(today)
+
+	days := map[string]int{}
+	weeks := map[string]*weekTally{}
+	allCommits := map[string]bool{}
+	for _, e := range events {
+		t := e.Time.Local()
+		day := startOfDay(t).Format(dateLayout)
+		days[day]++
+		wk := startOfWeek(startOfDay(t)).Format(dateLayout)
+		tally := weeks[wk]
+		if tally == nil {
+			tally = &weekTally{commits: map[string]bool{}}
+			weeks[wk] = tally
+		}
+		if e.Kind == KindCommit {
+			key := e.Repo + "\x00" + e.Ref
+			tally.commits[key] = true
+			allCommits[key] = true
+		} else {
+			tally.explanations++
+		}
+	}
+
+	p := Progress{Goal: goal, WeekStart: thisWeek.Format(dateLayout), TotalCommits: len(allCommits)}
+	if tally := weeks[p.WeekStart]; tally != nil {
+		p.CommitsThisWeek = len(tally.commits)
+		p.ExplanationsThisWeek = tally.explanations
+	}
+	p.GoalMet = goal.Met(p.CommitsThisWeek, p.ExplanationsThisWeek)
+
+	day := today
+	if days[day.Format(dateLayout)] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day.Format(dateLayout)] > 0 {
+		p.CurrentStreak++
+		day = day.AddDate(0, 0, -1)
+	}
+	p.LongestStreak = longestRun(days)
+
+	week := thisWeek
+	if !p.GoalMet {
+		week = week.AddDate(0, 0, -7)
+	}
+	for {
+		tally := weeks[week.Format(dateLayout)]
+		if tally == nil || !goal.Met(len(tally.commits), tally.explanations) {
+			break
+		}
+		p.WeeklyStreak++
+		week = week.AddDate(0, 0, -7)
+	}
+
+	for i := 6; i >= 0; i-- {
+		d := today.AddDate(0, 0, -i).Format(dateLayout)
+		p.LastSevenDays = append(p.LastSevenDays, DayActivity{Date: d, Events: days[d]})
+	}
+	return p
+}
+
+func longestRun(days map[string]int) int {
+	longest := 0
+	for d := range days {
+		start, _ := time.ParseInLocation(dateLayout, d, time.Local)
+		if days[start.AddDate(0, 0, -1).Format(dateLayout)] > 0 {
+			continue
+		}
+		n := 0
+		for days[start.AddDate(0, 0, n).Format(dateLayout)] > 0 {
+			n++
+		}
+		longest = max(longest, n)
+	}
+	return longest
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
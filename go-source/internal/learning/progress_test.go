@@ -0,0 +1,54 @@
+package learning
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestComputeProgress(t *testing.T) {
+	// Wednesday; the week started on Monday the 12th.
+	now := time.Date(2026, 10, 14, 18, 0, 0, 0, time.Local)
+	at := func(day int) time.Time { return time.Date(2026, 10, day, 10, 0, 0, 0, time.Local) }
+	events := []Event{
+		// Previous week meets a 2-commit goal.
+		{Time: at(6), Repo: "r", Kind: KindCommit, Ref: "a"},
+		{Time: at(7), Repo: "r", Kind: KindCommit, Ref: "b"},
+		// A gap on the 8th, then a run of four days ending today.
+		{Time: at(11), Repo: "r", Kind: "explain"},
+		{Time: at(12), Repo: "r", Kind: KindCommit, Ref: "c"},
+		{Time: at(13), Repo: "r", Kind: KindCommit, Ref: "c"},
+		{Time: at(14), Repo: "r", Kind: KindCommit, Ref: "d"},
+	}
+	p := Compute(events, Goal{CommitsPerWeek: 2}, now)
+	if p.WeekStart != "2026-10-12" || p.CommitsThisWeek != 2 || !p.GoalMet {
+		t.Fatalf("unexpected week: %+v", p)
+	}
+	if p.CurrentStreak != 4 || p.LongestStreak != 4 {
+		t.Fatalf("expected a 4-day streak, got current %d longest %d", p.CurrentStreak, p.LongestStreak)
+	}
+	if p.WeeklyStreak != 2 || p.TotalCommits != 4 {
+		t.Fatalf("expected 2 goal weeks and 4 commits, got %d and %d", p.WeeklyStreak, p.TotalCommits)
+	}
+	if len(p.LastSevenDays) != 7 || p.LastSevenDays[6].Date != "2026-10-14" || p.LastSevenDays[6].Events != 1 {
+		t.Fatalf("unexpected last seven days: %+v", p.LastSevenDays)
+	}
+
+	// Nothing yet today keeps yesterday's streak alive; an unmet week does
+	// not break the weekly streak before it ends.
+	p = Compute(events[:5], Goal{CommitsPerWeek: 2}, now)
+	if p.CurrentStreak != 3 || p.GoalMet || p.WeeklyStreak != 1 {
+		t.Fatalf("unexpected progress before today's activity: %+v", p)
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "learning.jsonl"))
+	if err := s.Append(Event{Repo: "r", Kind: KindCommit, Ref: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	events, err := s.Events()
+	if err != nil || len(events) != 1 || events[0].Time.IsZero() {
+		t.Fatalf("Events = %+v, %v", events, err)
+	}
+}
@@ -0,0 +1,98 @@
+package learning
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+// KindQuiz is recorded when a quiz is finished.
+const KindQuiz = "quiz"
+
+// QuizScore is the result of one finished quiz.
+type QuizScore struct {
+	Time    time.Time `json:"time"`
+	Repo    string    `json:"repo"`
+	Ref     string    `json:"ref,omitempty"`
+	Correct int       `json:"correct"`
+	Total   int       `json:"total"`
+}
+
+// Percent is the share of correct answers, 0 for an empty quiz.
+func (s QuizScore) Percent() int {
+	if s.Total == 0 {
+		return 0
+	}
+	return s.Correct * 100 / s.Total
+}
+
+// QuizStore keeps quiz scores as JSON lines, next to the learning events.
+type QuizStore struct {
+	path string
+}
+
+// OpenQuizzes returns the quiz store in config.DataDir.
+func OpenQuizzes() *QuizStore {
+	return NewQuizStore(filepath.Join(config.DataDir(), "quiz-scores.jsonl"))
+}
+
+func NewQuizStore(path string) *QuizStore {
+	return &QuizStore{path: path}
+}
+
+func (s *QuizStore) Append(score QuizScore) error {
+	if score.Time.IsZero() {
+		score.Time = time.Now()
+	}
+	line, err := json.Marshal(score)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Scores returns the recorded scores, oldest first, limited to repo unless
+// it is empty.
+func (s *QuizStore) Scores(repo string) ([]QuizScore, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []QuizScore
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var score QuizScore
+		if json.Unmarshal(sc.Bytes(), &score) == nil && (repo == "" || score.Repo == repo) {
+			out = append(out, score)
+		}
+	}
+	return out, sc.Err()
+}
+
+// QuizAverage is the share of all answers in scores that were correct.
+func QuizAverage(scores []QuizScore) int {
+	var total QuizScore
+	for _, s := range scores {
+		total.Correct += s.Correct
+		total.Total += s.Total
+	}
+	return total.Percent()
+}
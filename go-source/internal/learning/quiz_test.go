@@ -0,0 +1,30 @@
+package learning
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestQuizStore(t *testing.T) {
+	s := NewQuizStore(filepath.Join(t.TempDir(), "quiz-scores.jsonl"))
+	for _, score := range []QuizScore{
+		{Repo: "a", Ref: "HEAD", Correct: 3, Total: 4},
+		{Repo: "b", Correct: 1, Total: 4},
+		{Repo: "a", Correct: 4, Total: 6},
+	} {
+		if err := s.Append(score); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scores, err := s.Scores("a")
+	if err != nil || len(scores) != 2 || scores[0].Ref != "HEAD" || scores[0].Time.IsZero() {
+		t.Fatalf("Scores(a) = %+v, %v", scores, err)
+	}
+	if got := QuizAverage(scores); got != 70 {
+		t.Fatalf("QuizAverage = %d, want 70", got)
+	}
+	all, _ := s.Scores("")
+	if len(all) != 3 || all[1].Percent() != 25 {
+		t.Fatalf("Scores() = %+v", all)
+	}
+}
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// AnnotationResult holds the comments the model attached to diff lines.
+// Dropped lists comments whose file or line does not exist in the diff.
+type AnnotationResult struct {
+	Annotations []git.Annotation `json:"annotations"`
+	Dropped     []git.Annotation `json:"dropped"`
+	Budget      BudgetReport     `json:"budget"`
+	Usage       []map[string]any `json:"usage,omitempty"`
+}
+
+// CreateAnnotationPrompt shows every hunk line with the number the model must
+// cite, so comments can be placed without guessing at hunk offsets.
+func CreateAnnotationPrompt(diffs []git.ParsedDiff) string {
+	var sb strings.Builder
+	sb.WriteString("Review the following code changes like a pull request reviewer and attach comments to specific lines. Each line below is labeled with the side and number to cite: `new N` for added and unchanged lines, `old N` for removed lines.\n\n")
+	writeNumberedHunks(&sb, diffs)
+	sb.WriteString(`Reply with a single JSON object and nothing else:
+
+{"annotations": [{"file": "<path as in the ### heading>", "side": "new" | "old", "line": <number>, "severity": "critical" | "important" | "minor", "message": "<the comment>"}]}
+
+Comment only where there is something worth saying (bugs, risks, unclear code, missing handling); do not restate what a line does. Keep each message to one or two sentences.`)
+	return sb.String()
+}
+
+// writeNumberedHunks lists every hunk line labeled with the side and line
+// number a comment on it must cite.
+func writeNumberedHunks(sb *strings.Builder, diffs []git.ParsedDiff) {
+	for _, d := range diffs {
+		if len(d.Hunks) == 0 {
+			continue
+		}
+		fmt.Fprintf(sb, "### %s\n\n```\n", d.NewFile)
+		for _, h := range d.Hunks {
+			sb.WriteString(h.Header + "\n")
+			for _, l := range h.Lines {
+				switch l.Type {
+				case git.LineDelete:
+					fmt.Fprintf(sb, "old %4d | -%s\n", derefInt(l.OldLineNumber), l.Content)
+				case git.LineAdd:
+					fmt.Fprintf(sb, "new %4d | +%s\n", derefInt(l.NewLineNumber), l.Content)
+				default:
+					fmt.Fprintf(sb, "new %4d |  %s\n", derefInt(l.NewLineNumber), l.Content)
+				}
+			}
+		}
+		sb.WriteString("```\n\n")
+	}
+}
+
+func derefInt(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+// ParseAnnotations decodes an answer to CreateAnnotationPrompt.
+func ParseAnnotations(content string) ([]git.Annotation, error) {
+	var payload struct {
+		Annotations []git.Annotation `json:"annotations"`
+	}
+	if err := unmarshalJSONAnswer(content, &payload); err != nil {
+		return nil, fmt.Errorf("annotations: %w", err)
+	}
+	for i := range payload.Annotations {
+		payload.Annotations[i].Severity = string(normalizeSeverity(payload.Annotations[i].Severity))
+	}
+	return payload.Annotations, nil
+}
+
+// Annotate asks the model for line comments, one request per budget chunk,
+// and validates every comment against the diff's line numbers.
+func Annotate(client Chatter, formatter *git.DiffFormatter, diffs []git.ParsedDiff, budget TokenBudget) (AnnotationResult, error) {
+	return annotateWith(client, formatter, diffs, budget, CreateAnnotationPrompt)
+}
+
+func annotateWith(client Chatter, formatter *git.DiffFormatter, diffs []git.ParsedDiff, budget TokenBudget, prompt func([]git.ParsedDiff) string) (AnnotationResult, error) {
+	chunks, report := budget.Chunk(formatter, diffs)
+	result := AnnotationResult{Budget: report}
+	var all []git.Annotation
+	for _, chunk := range chunks {
+		resp, err := client.Chat([]ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: prompt(chunk)}})
+		if err != nil {
+			return AnnotationResult{}, err
+		}
+		result.Usage = append(result.Usage, resp.Usage)
+		parsed, err := ParseAnnotations(resp.Content)
+		if err != nil {
+			return AnnotationResult{}, err
+		}
+		all = append(all, parsed...)
+	}
+	result.Annotations, result.Dropped = git.ValidateAnnotations(diffs, all)
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+type cannedChatter struct {
+	answer  string
+	prompts []string
+}
+
+func (c *cannedChatter) Chat(messages []ChatMessage) (LLMResponse, error) {
+	c.prompts = append(c.prompts, messages[len(messages)-1].Content)
+	return LLMResponse{Content: c.answer}, nil
+}
+
+const annotateDiff = `diff --git a/app.go b/app.go
+--- a/app.go
++++ b/app.go
+@@ -10,3 +10,3 @@ func run() {
+ 	cfg := load()
+-	return cfg.Start()
++	go cfg.Start()
+ 	return nil
+`
+
+func TestAnnotateValidatesLines(t *testing.T) {
+	diffs := git.NewDiffParser().Parse(annotateDiff)
+	chat := &cannedChatter{answer: "```json\n" + `{"annotations": [
+		{"file": "app.go", "side": "new", "line": 11, "severity": "high", "message": "the error from Start is lost"},
+		{"file": "b/app.go", "side": "old", "line": 11, "message": "previously returned the error"},
+		{"file": "app.go", "side": "old", "line": 10, "message": "context line by old number"},
+		{"file": "app.go", "line": 40, "message": "not in the diff"},
+		{"file": "other.go", "line": 11, "message": "wrong file"}
+	]}` + "\n```"}
+
+	result, err := Annotate(chat, git.NewDiffFormatter(), diffs, NewTokenBudget(0, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(chat.prompts[0], "new   11 | +\tgo cfg.Start()") || !strings.Contains(chat.prompts[0], "old   11 | -\treturn cfg.Start()") {
+		t.Fatalf("prompt should label lines with their side and number:\n%s", chat.prompts[0])
+	}
+	if len(result.Annotations) != 3 || len(result.Dropped) != 2 {
+		t.Fatalf("expected 3 valid and 2 dropped annotations, got %+v", result)
+	}
+	first := result.Annotations[0]
+	if first.Severity != "critical" || first.Side != git.SideNew || first.Line != 11 {
+		t.Fatalf("unexpected first annotation: %+v", first)
+	}
+	if second := result.Annotations[1]; second.File != "app.go" || second.Side != git.SideOld {
+		t.Fatalf("expected the b/ prefix to be stripped: %+v", second)
+	}
+	if third := result.Annotations[2]; third.Side != git.SideNew || third.Line != 10 {
+		t.Fatalf("expected the context line to move to the new side: %+v", third)
+	}
+
+	out := git.NewDiffFormatter().ToTerminal(diffs, git.FormatterOptions{Annotations: result.Annotations})
+	_, below, found := strings.Cut(out, "+\tgo cfg.Start()\n")
+	if next, _, _ := strings.Cut(below, "\n"); !found || !strings.Contains(next, "[critical] the error from Start is lost") {
+		t.Fatalf("expected the comment right below its line:\n%s", out)
+	}
+}
+
+func TestTeachingNotesDropRemovedLines(t *testing.T) {
+	diffs := git.NewDiffParser().Parse(annotateDiff)
+	chat := &cannedChatter{answer: `{"annotations": [
+		{"file": "app.go", "side": "new", "line": 11, "message": "go starts Start concurrently"},
+		{"file": "app.go", "side": "old", "line": 11, "message": "removed code"}
+	]}`}
+	result, err := TeachingNotes(chat, git.NewDiffFormatter(), diffs, NewTokenBudget(0, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Annotations) != 1 || len(result.Dropped) != 1 || result.Annotations[0].Severity != "" {
+		t.Fatalf("expected one new-side note without severity, got %+v", result)
+	}
+	if !strings.Contains(chat.prompts[0], "NOTE:") || !strings.Contains(chat.prompts[0], "new   11 | +\tgo cfg.Start()") {
+		t.Fatalf("unexpected teaching prompt:\n%s", chat.prompts[0])
+	}
+}
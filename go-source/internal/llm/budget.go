@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"difflearn-go/internal/git"
+)
+
+const DefaultContextTokens = 100000
+
+// Chatter is the subset of Client used by the budgeted map-reduce pipeline.
+type Chatter interface {
+	Chat(messages []ChatMessage) (LLMResponse, error)
+}
+
+type TokenBudget struct {
+	MaxTokens     int
+	ReserveTokens int
+}
+
+type BudgetReport struct {
+	EstimatedTokens int      `json:"estimatedTokens"`
+	BudgetTokens    int      `json:"budgetTokens"`
+	Chunks          int      `json:"chunks"`
+	TruncatedFiles  []string `json:"truncatedFiles"`
+	OmittedFiles    []string `json:"omittedFiles"`
+	OmittedHunks    int      `json:"omittedHunks"`
+}
+
+func (r BudgetReport) HasOmissions() bool {
+	return len(r.TruncatedFiles) > 0 || len(r.OmittedFiles) > 0 || r.OmittedHunks > 0
+}
+
+func (r BudgetReport) Notice() string {
+	if !r.HasOmissions() && r.Chunks <= 1 {
+		return ""
+	}
+	parts := make([]string, 0)
+	if r.Chunks > 1 {
+		parts = append(parts, fmt.Sprintf("diff split into %d chunks", r.Chunks))
+	}
+	if len(r.TruncatedFiles) > 0 {
+		parts = append(parts, fmt.Sprintf("%d hunk(s) dropped from %s", r.OmittedHunks, strings.Join(r.TruncatedFiles, ", ")))
+	}
+	if len(r.OmittedFiles) > 0 {
+		parts = append(parts, "omitted "+strings.Join(r.OmittedFiles, ", "))
+	}
+	return fmt.Sprintf("Large diff (~%d tokens, budget %d): %s.", r.EstimatedTokens, r.BudgetTokens, strings.Join(parts, "; "))
+}
+
+func NewTokenBudget(contextTokens, responseTokens int) TokenBudget {
+	if contextTokens <= 0 {
+		contextTokens = DefaultContextTokens
+	}
+	return TokenBudget{MaxTokens: contextTokens, ReserveTokens: responseTokens + EstimateTokens(SystemPrompt)}
+}
+
+func (b TokenBudget) Available() int {
+	available := b.MaxTokens - b.ReserveTokens
+	if available < 256 {
+		return 256
+	}
+	return available
+}
+
+// EstimateTokens approximates BPE tokenization: word-like runs cost roughly one
+// token per four characters, while punctuation and newlines cost one each.
+func EstimateTokens(text string) int {
+	tokens := 0
+	run := 0
+	flush := func() {
+		if run > 0 {
+			tokens += (run + 3) / 4
+			run = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			run++
+		case r == '\n':
+			flush()
+			tokens++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+func EstimateDiffTokens(formatter *git.DiffFormatter, diffs []git.ParsedDiff) int {
+	return EstimateTokens(formatter.ToMarkdown(diffs))
+}
+
+// Fit trims diffs to the budget by dropping trailing hunks from oversized files
+// and then whole files once nothing else fits.
+func (b TokenBudget) Fit(formatter *git.DiffFormatter, diffs []git.ParsedDiff) ([]git.ParsedDiff, BudgetReport) {
+	report := BudgetReport{EstimatedTokens: EstimateDiffTokens(formatter, diffs), BudgetTokens: b.Available(), Chunks: 1}
+	if report.EstimatedTokens <= report.BudgetTokens {
+		return diffs, report
+	}
+
+	kept := make([]git.ParsedDiff, 0, len(diffs))
+	used := EstimateDiffTokens(formatter, nil)
+	for _, d := range diffs {
+		cost := EstimateDiffTokens(formatter, []git.ParsedDiff{d}) - EstimateDiffTokens(formatter, nil)
+		if used+cost <= report.BudgetTokens {
+			kept = append(kept, d)
+			used += cost
+			continue
+		}
+		trimmed, dropped := truncateHunks(formatter, d, report.BudgetTokens-used)
+		if len(trimmed.Hunks) == 0 {
+			report.OmittedFiles = append(report.OmittedFiles, d.NewFile)
+			continue
+		}
+		kept = append(kept, trimmed)
+		used += EstimateDiffTokens(formatter, []git.ParsedDiff{trimmed}) - EstimateDiffTokens(formatter, nil)
+		report.TruncatedFiles = append(report.TruncatedFiles, d.NewFile)
+		report.OmittedHunks += dropped
+	}
+	return kept, report
+}
+
+// Chunk groups whole files into chunks that each fit the budget. Files that are
+// larger than a single chunk are truncated to fit on their own.
+func (b TokenBudget) Chunk(formatter *git.DiffFormatter, diffs []git.ParsedDiff) ([][]git.ParsedDiff, BudgetReport) {
+	report := BudgetReport{EstimatedTokens: EstimateDiffTokens(formatter, diffs), BudgetTokens: b.Available()}
+	overhead := EstimateDiffTokens(formatter, nil)
+	chunks := make([][]git.ParsedDiff, 0)
+	current := make([]git.ParsedDiff, 0)
+	used := overhead
+
+	for _, d := range diffs {
+		cost := EstimateDiffTokens(formatter, []git.ParsedDiff{d}) - overhead
+		if overhead+cost > report.BudgetTokens {
+			trimmed, dropped := truncateHunks(formatter, d, report.BudgetTokens-overhead)
+			if len(trimmed.Hunks) == 0 {
+				report.OmittedFiles = append(report.OmittedFiles, d.NewFile)
+				continue
+			}
+			report.TruncatedFiles = append(report.TruncatedFiles, d.NewFile)
+			report.OmittedHunks += dropped
+			d = trimmed
+			cost = EstimateDiffTokens(formatter, []git.ParsedDiff{d}) - overhead
+		}
+		if used+cost > report.BudgetTokens && len(current) > 0 {
+			chunks = append(chunks, current)
+			current = make([]git.ParsedDiff, 0)
+			used = overhead
+		}
+		current = append(current, d)
+		used += cost
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	report.Chunks = len(chunks)
+	return chunks, report
+}
+
+func truncateHunks(formatter *git.DiffFormatter, d git.ParsedDiff, budget int) (git.ParsedDiff, int) {
+	overhead := EstimateDiffTokens(formatter, nil)
+	trimmed := d
+	trimmed.Hunks = make([]git.ParsedHunk, 0, len(d.Hunks))
+	for _, h := range d.Hunks {
+		candidate := trimmed
+		candidate.Hunks = append(append([]git.ParsedHunk{}, trimmed.Hunks...), h)
+		if EstimateDiffTokens(formatter, []git.ParsedDiff{candidate})-overhead > budget {
+			break
+		}
+		trimmed = candidate
+	}
+	return trimmed, len(d.Hunks) - len(trimmed.Hunks)
+}
+
+// RunBudgeted sends the prompt produced by build when the diff fits the budget.
+// Otherwise it runs build on each chunk (map) and merges the partial answers
+// with a final reduce call.
+func RunBudgeted(client Chatter, formatter *git.DiffFormatter, diffs []git.ParsedDiff, budget TokenBudget, build func([]git.ParsedDiff) string) (LLMResponse, BudgetReport, error) {
+	chunks, report := budget.Chunk(formatter, diffs)
+	if len(chunks) <= 1 {
+		selected := diffs
+		if len(chunks) == 1 {
+			selected = chunks[0]
+		}
+		resp, err := client.Chat([]ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: build(selected)}})
+		return resp, report, err
+	}
+
+	partials := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		prompt := CreateChunkPrompt(build(chunk), i+1, len(chunks))
+		resp, err := client.Chat([]ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: prompt}})
+		if err != nil {
+			return LLMResponse{}, report, fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		partials = append(partials, resp.Content)
+	}
+
+	resp, err := client.Chat([]ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: CreateReducePrompt(partials, report)}})
+	return resp, report, err
+}
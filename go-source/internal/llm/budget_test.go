@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+)
+
+type fakeChatter struct {
+	prompts []string
+}
+
+func (f *fakeChatter) Chat(messages []ChatMessage) (LLMResponse, error) {
+	f.prompts = append(f.prompts, messages[len(messages)-1].Content)
+	return LLMResponse{Content: fmt.Sprintf("answer %d", len(f.prompts))}, nil
+}
+
+func largeDiff(name string, hunks, linesPerHunk int) git.ParsedDiff {
+	d := git.ParsedDiff{OldFile: name, NewFile: name}
+	for h := 0; h < hunks; h++ {
+		hunk := git.ParsedHunk{Header: fmt.Sprintf("@@ -%d,1 +%d,1 @@", h*10+1, h*10+1)}
+		for i := 0; i < linesPerHunk; i++ {
+			hunk.Lines = append(hunk.Lines, git.ParsedLine{Type: git.LineAdd, Content: "value := computeSomethingExpensive(input, options)"})
+			d.Additions++
+		}
+		d.Hunks = append(d.Hunks, hunk)
+	}
+	return d
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens(""); got != 0 {
+		t.Fatalf("expected 0 tokens, got %d", got)
+	}
+	if got := EstimateTokens("hello world"); got != 4 {
+		t.Fatalf("expected 4 tokens, got %d", got)
+	}
+	if got := EstimateTokens("a(b)"); got != 4 {
+		t.Fatalf("expected punctuation to count, got %d", got)
+	}
+}
+
+func TestFitTruncatesAndOmits(t *testing.T) {
+	f := git.NewDiffFormatter()
+	diffs := []git.ParsedDiff{largeDiff("a.go", 4, 20), largeDiff("b.go", 4, 20)}
+	oneHunk := EstimateDiffTokens(f, []git.ParsedDiff{largeDiff("a.go", 1, 20)})
+
+	b := TokenBudget{MaxTokens: oneHunk * 2}
+	kept, report := b.Fit(f, diffs)
+	if !report.HasOmissions() {
+		t.Fatalf("expected omissions, got %+v", report)
+	}
+	if len(kept) == 0 || len(kept[0].Hunks) >= 4 {
+		t.Fatalf("expected first file truncated, got %+v", kept)
+	}
+	if !strings.Contains(report.Notice(), "b.go") {
+		t.Fatalf("expected notice to mention omitted file: %s", report.Notice())
+	}
+}
+
+func TestRunBudgetedMapReduce(t *testing.T) {
+	f := git.NewDiffFormatter()
+	diffs := []git.ParsedDiff{largeDiff("a.go", 1, 20), largeDiff("b.go", 1, 20), largeDiff("c.go", 1, 20)}
+	perFile := EstimateDiffTokens(f, diffs[:1])
+
+	chat := &fakeChatter{}
+	budget := TokenBudget{MaxTokens: perFile + perFile/2}
+	resp, report, err := RunBudgeted(chat, f, diffs, budget, func(d []git.ParsedDiff) string { return CreateSummaryPrompt(f, d) })
+	if err != nil {
+		t.Fatalf("RunBudgeted() error = %v", err)
+	}
+	if report.Chunks != 3 {
+		t.Fatalf("expected 3 chunks, got %d", report.Chunks)
+	}
+	if len(chat.prompts) != 4 {
+		t.Fatalf("expected 3 map calls and 1 reduce call, got %d", len(chat.prompts))
+	}
+	if !strings.Contains(chat.prompts[3], "answer 1") || resp.Content != "answer 4" {
+		t.Fatalf("expected reduce prompt to include partial answers")
+	}
+}
+
+func TestMeasurePrompt(t *testing.T) {
+	f := git.NewDiffFormatter()
+	diffs := []git.ParsedDiff{largeDiff("small.go", 1, 2), largeDiff("big.go", 20, 30)}
+	cfg := config.Config{Provider: config.ProviderOllama, Model: "qwen", MaxTokens: 1000, ContextTokens: 3000}
+	size := MeasurePrompt(cfg, f, diffs, func(d []git.ParsedDiff) string { return CreateExplainPrompt(f, d) })
+
+	if len(size.Files) != 2 || size.Files[0].File != "big.go" || size.Files[0].Tokens <= size.Files[1].Tokens {
+		t.Fatalf("files should be sorted by size: %+v", size.Files)
+	}
+	if size.PromptTokens <= size.DiffTokens || !size.Budget.HasOmissions() {
+		t.Fatalf("unexpected totals: %+v", size)
+	}
+	var ollama, google ProviderFit
+	for _, p := range size.Providers {
+		switch p.Provider {
+		case config.ProviderOllama:
+			ollama = p
+		case config.ProviderGoogle:
+			google = p
+		}
+	}
+	if !ollama.Active || ollama.Model != "qwen" || ollama.Fits || !google.Fits {
+		t.Fatalf("unexpected fits: ollama %+v, google %+v", ollama, google)
+	}
+}
@@ -0,0 +1,217 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"difflearn-go/internal/config"
+)
+
+// cliCall is one way of running a CLI provider: the command line, and how
+// to read what it prints. The prompt always goes to stdin, which has no
+// length limit.
+type cliCall struct {
+	command string
+	args    []string
+	parse   func(out []byte) (LLMResponse, error)
+}
+
+func (c *Client) chatCLI(messages []ChatMessage) (LLMResponse, error) {
+	system := ""
+	var sb strings.Builder
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = m.Content
+			continue
+		}
+		role := "User"
+		if m.Role == "assistant" {
+			role = "Assistant"
+		}
+		sb.WriteString(role + ": " + m.Content + "\n\n")
+	}
+	prompt := sb.String()
+	if system != "" {
+		prompt = system + "\n\n" + prompt
+	}
+
+	structured, plain, err := cliCallsFor(c.cfg)
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	resp, err := runCLI(structured, prompt)
+	// Older CLI versions reject the structured output flags; plain text
+	// still answers, only without a usage report.
+	if err != nil && rejectedFlag(err) {
+		resp, err = runCLI(plain, prompt)
+	}
+	return resp, err
+}
+
+// cliCallsFor returns the structured-output call for the configured CLI
+// provider and a plain-text one to fall back on. The model is passed only
+// when one other than the provider's placeholder is configured.
+func cliCallsFor(cfg config.Config) (structured, plain cliCall, err error) {
+	var model []string
+	if cfg.Model != "" && cfg.Model != config.DefaultModel(cfg.Provider) {
+		model = []string{"--model", cfg.Model}
+	}
+	with := func(args ...string) []string { return append(args, model...) }
+	switch cfg.Provider {
+	case config.ProviderClaude:
+		return cliCall{"claude", with("-p", "--output-format", "json"), parseClaudeJSON},
+			cliCall{"claude", with("-p"), parsePlain}, nil
+	case config.ProviderCursor:
+		return cliCall{"agent", with("-p", "--output-format", "json"), parseClaudeJSON},
+			cliCall{"agent", with("-p"), parsePlain}, nil
+	case config.ProviderGeminiCLI:
+		return cliCall{"gemini", with("--output-format", "json"), parseGeminiJSON},
+			cliCall{"gemini", with(), parsePlain}, nil
+	case config.ProviderCodex:
+		// "-" (read the prompt from stdin) has to come last.
+		return cliCall{"codex", append(with("exec", "--json"), "-"), parseCodexJSONL},
+			cliCall{"codex", append(with("exec"), "-"), parsePlain}, nil
+	}
+	return cliCall{}, cliCall{}, fmt.Errorf("unsupported CLI provider: %s", cfg.Provider)
+}
+
+func runCLI(call cliCall, input string) (LLMResponse, error) {
+	cmd := exec.Command(call.command, call.args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(string(out))
+		}
+		return LLMResponse{}, fmt.Errorf("%s failed: %s", call.command, msg)
+	}
+	return call.parse(out)
+}
+
+func rejectedFlag(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"output-format", "unknown option", "unknown argument", "unexpected argument", "unrecognized"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePlain(out []byte) (LLMResponse, error) {
+	return LLMResponse{Content: strings.TrimSpace(string(out))}, nil
+}
+
+// parseClaudeJSON reads the result object of `claude -p --output-format
+// json`, which the Cursor agent prints too.
+func parseClaudeJSON(out []byte) (LLMResponse, error) {
+	var result struct {
+		Result  string         `json:"result"`
+		IsError bool           `json:"is_error"`
+		Usage   map[string]any `json:"usage"`
+		Cost    float64        `json:"total_cost_usd"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(out), &result); err != nil {
+		return parsePlain(out)
+	}
+	if result.IsError {
+		return LLMResponse{}, fmt.Errorf("%s", strings.TrimSpace(result.Result))
+	}
+	if result.Usage != nil && result.Cost > 0 {
+		result.Usage["total_cost_usd"] = result.Cost
+	}
+	return LLMResponse{Content: strings.TrimSpace(result.Result), Usage: result.Usage}, nil
+}
+
+// parseGeminiJSON reads `gemini --output-format json`, whose token counts
+// are kept per model.
+func parseGeminiJSON(out []byte) (LLMResponse, error) {
+	var result struct {
+		Response string `json:"response"`
+		Error    *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Stats struct {
+			Models map[string]struct {
+				Tokens struct {
+					Prompt     float64 `json:"prompt"`
+					Candidates float64 `json:"candidates"`
+					Total      float64 `json:"total"`
+				} `json:"tokens"`
+			} `json:"models"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(out), &result); err != nil {
+		return parsePlain(out)
+	}
+	if result.Error != nil && result.Response == "" {
+		return LLMResponse{}, fmt.Errorf("%s", result.Error.Message)
+	}
+	var prompt, completion, total float64
+	for _, m := range result.Stats.Models {
+		prompt += m.Tokens.Prompt
+		completion += m.Tokens.Candidates
+		total += m.Tokens.Total
+	}
+	resp := LLMResponse{Content: strings.TrimSpace(result.Response)}
+	if total > 0 {
+		resp.Usage = map[string]any{"prompt_tokens": prompt, "completion_tokens": completion, "total_tokens": total}
+	}
+	return resp, nil
+}
+
+// parseCodexJSONL reads the event stream of `codex exec --json`: the last
+// agent message is the answer and turn.completed carries the usage. Older
+// releases wrap events in "msg" and report no usage.
+func parseCodexJSONL(out []byte) (LLMResponse, error) {
+	var resp LLMResponse
+	events := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event struct {
+			Type  string         `json:"type"`
+			Usage map[string]any `json:"usage"`
+			Item  struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"item"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+			Message string `json:"message"`
+			Msg     *struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"msg"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &event) != nil {
+			continue
+		}
+		events++
+		switch {
+		case event.Type == "item.completed" && event.Item.Type == "agent_message":
+			resp.Content = event.Item.Text
+		case event.Type == "turn.completed" && event.Usage != nil:
+			resp.Usage = event.Usage
+		case event.Type == "turn.failed" && event.Error != nil:
+			return LLMResponse{}, fmt.Errorf("%s", event.Error.Message)
+		case event.Type == "error":
+			return LLMResponse{}, fmt.Errorf("%s", event.Message)
+		case event.Msg != nil && event.Msg.Type == "agent_message":
+			resp.Content = event.Msg.Message
+		}
+	}
+	if events == 0 {
+		return parsePlain(out)
+	}
+	resp.Content = strings.TrimSpace(resp.Content)
+	return resp, nil
+}
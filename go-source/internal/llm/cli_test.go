@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/config"
+)
+
+func TestParseClaudeJSON(t *testing.T) {
+	resp, err := parseClaudeJSON([]byte(`{"type":"result","is_error":false,"result":"  **Fine.**\n","usage":{"input_tokens":120,"output_tokens":30},"total_cost_usd":0.01}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "**Fine.**" || usedTokens(resp, nil) != 150 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if _, err := parseClaudeJSON([]byte(`{"is_error":true,"result":"Invalid API key"}`)); err == nil || err.Error() != "Invalid API key" {
+		t.Fatalf("expected the CLI's error, got %v", err)
+	}
+	if resp, _ := parseClaudeJSON([]byte("plain answer\n")); resp.Content != "plain answer" {
+		t.Fatalf("non-JSON output should pass through: %+v", resp)
+	}
+}
+
+func TestParseGeminiJSON(t *testing.T) {
+	resp, err := parseGeminiJSON([]byte(`{"response":"Looks good","stats":{"models":{"gemini-2.5-pro":{"tokens":{"prompt":90,"candidates":10,"total":100}}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Looks good" || usedTokens(resp, nil) != 100 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestParseCodexJSONL(t *testing.T) {
+	out := strings.Join([]string{
+		`{"type":"thread.started","thread_id":"t1"}`,
+		`{"type":"item.completed","item":{"type":"reasoning","text":"thinking"}}`,
+		`{"type":"item.completed","item":{"type":"agent_message","text":"The change adds tags."}}`,
+		`{"type":"turn.completed","usage":{"input_tokens":200,"cached_input_tokens":50,"output_tokens":40}}`,
+	}, "\n")
+	resp, err := parseCodexJSONL([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "The change adds tags." || usedTokens(resp, nil) != 240 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if _, err := parseCodexJSONL([]byte(`{"type":"turn.failed","error":{"message":"rate limited"}}`)); err == nil {
+		t.Fatal("expected turn.failed to be an error")
+	}
+}
+
+func TestChatCLIPassesModelAndFallsBack(t *testing.T) {
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	t.Setenv("DIFFLEARN_LLM_BUDGET", "")
+	// An old claude without --output-format that echoes its arguments.
+	script := `#!/bin/sh
+for a in "$@"; do [ "$a" = "--output-format" ] && { echo "error: unknown option '--output-format'" >&2; exit 1; }; done
+cat >/dev/null
+echo "args: $*"
+`
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{Provider: config.ProviderClaude, Model: "sonnet", UseCLI: true}
+	resp, err := NewClient(cfg).Chat([]ChatMessage{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "args: -p --model sonnet" {
+		t.Fatalf("unexpected fallback call: %q", resp.Content)
+	}
+
+	cfg.Model = config.DefaultModel(config.ProviderClaude)
+	resp, err = NewClient(cfg).Chat([]ChatMessage{{Role: "user", Content: "hi"}})
+	if err != nil || resp.Content != "args: -p" {
+		t.Fatalf("the placeholder model must not be passed: %q, %v", resp.Content, err)
+	}
+}
@@ -3,15 +3,13 @@ package llm
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"os/exec"
 	"strings"
 	"time"
 
 	"difflearn-go/internal/config"
+	"difflearn-go/internal/usage"
 )
 
 type ChatMessage struct {
@@ -22,23 +20,129 @@ type ChatMessage struct {
 type LLMResponse struct {
 	Content string         `json:"content"`
 	Usage   map[string]any `json:"usage,omitempty"`
+	// Provider and Model answered the request, which is a fallback when
+	// the configured provider failed.
+	Provider config.LLMProvider `json:"provider,omitempty"`
+	Model    string             `json:"model,omitempty"`
 }
 
+// OnFallback, when set, is told each time a fallback provider answers
+// because the configured one failed with err.
+var OnFallback func(failed, answered config.Config, err error)
+
 type Client struct {
 	cfg        config.Config
 	httpClient *http.Client
+	// key and endpoint attribute calls in the usage ledger.
+	key      string
+	endpoint string
 }
 
 func NewClient(cfg config.Config) *Client {
 	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: 120 * time.Second}}
 }
 
+// For returns a copy of the client whose calls count against the budgets of
+// the API token key and endpoint.
+func (c *Client) For(key, endpoint string) *Client {
+	cp := *c
+	cp.key, cp.endpoint = key, endpoint
+	return &cp
+}
+
+// level is the request's level, or the default one.
+func (c *Client) level() Level {
+	if c.cfg.Level != "" {
+		return Level(c.cfg.Level)
+	}
+	return DefaultLevel()
+}
+
+// language is the request's answer language, or the default one.
+func (c *Client) language() string {
+	if c.cfg.Language != "" {
+		return LanguageName(c.cfg.Language)
+	}
+	return DefaultLanguage()
+}
+
+// Chat sends messages to the provider. Every call is checked against the
+// DIFFLEARN_LLM_BUDGET caps first and recorded in the usage ledger after.
 func (c *Client) Chat(messages []ChatMessage) (LLMResponse, error) {
+	budget, err := usage.LoadBudget()
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	ledger := usage.Open()
+	if err := ledger.Check(budget, c.key, c.endpoint, time.Now()); err != nil {
+		return LLMResponse{}, err
+	}
+	messages = withRepoFacts(messages)
+	messages = withSection(messages, LevelPrompt(c.level()))
+	messages = withSection(messages, LanguagePrompt(c.language()))
+	resp, answered, err := c.chatWithFallbacks(messages)
+	if err != nil {
+		return resp, err
+	}
+	resp.Provider, resp.Model = answered.Provider, answered.Model
+	_ = ledger.Add(usage.Record{Key: c.key, Endpoint: c.endpoint, Tokens: usedTokens(resp, messages)})
+	return resp, nil
+}
+
+// chatWithFallbacks asks the configured provider and, when it fails, the
+// DIFFLEARN_FALLBACK providers in order, returning the configuration that
+// answered. When all fail the error is the configured provider's, with the
+// fallbacks' errors appended.
+func (c *Client) chatWithFallbacks(messages []ChatMessage) (LLMResponse, config.Config, error) {
+	resp, err := c.chat(messages)
+	if err == nil {
+		return resp, c.cfg, nil
+	}
+	var failures []string
+	for _, fb := range config.Fallbacks(c.cfg) {
+		cp := *c
+		cp.cfg = fb
+		r, fbErr := cp.chat(messages)
+		if fbErr == nil {
+			if OnFallback != nil {
+				OnFallback(c.cfg, fb, err)
+			}
+			return r, fb, nil
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", fb.Provider, fbErr))
+	}
+	if len(failures) > 0 {
+		return resp, c.cfg, fmt.Errorf("%w (fallbacks failed too: %s)", err, strings.Join(failures, "; "))
+	}
+	return resp, c.cfg, err
+}
+
+// usedTokens reads the token count from the provider's usage report, or
+// estimates it when there is none (CLI providers, Google).
+func usedTokens(resp LLMResponse, messages []ChatMessage) int {
+	number := func(key string) int {
+		n, _ := resp.Usage[key].(float64)
+		return int(n)
+	}
+	if n := number("total_tokens"); n > 0 {
+		return n
+	}
+	if n := number("prompt_tokens") + number("completion_tokens") + number("input_tokens") + number("output_tokens"); n > 0 {
+		return n
+	}
+	n := EstimateTokens(resp.Content)
+	for _, m := range messages {
+		n += EstimateTokens(m.Content)
+	}
+	return n
+}
+
+func (c *Client) chat(messages []ChatMessage) (LLMResponse, error) {
 	if c.cfg.UseCLI {
 		return c.chatCLI(messages)
 	}
 	switch c.cfg.Provider {
-	case config.ProviderOpenAI, config.ProviderOllama, config.ProviderLMStudio:
+	case config.ProviderOpenAI, config.ProviderOllama, config.ProviderLMStudio, config.ProviderOpenAICompatible:
 		return c.chatOpenAICompat(messages)
 	case config.ProviderAnthropic:
 		return c.chatAnthropic(messages)
@@ -67,61 +171,9 @@ func (c *Client) StreamChat(messages []ChatMessage) (<-chan string, <-chan error
 	return chunks, errs
 }
 
-func (c *Client) chatCLI(messages []ChatMessage) (LLMResponse, error) {
-	system := ""
-	var sb strings.Builder
-	for _, m := range messages {
-		if m.Role == "system" {
-			system = m.Content
-			continue
-		}
-		role := "User"
-		if m.Role == "assistant" {
-			role = "Assistant"
-		}
-		sb.WriteString(role + ": " + m.Content + "\n\n")
-	}
-	prompt := sb.String()
-	if system != "" {
-		prompt = system + "\n\n" + prompt
-	}
-
-	switch c.cfg.Provider {
-	case config.ProviderGeminiCLI:
-		out, err := runCLIWithStdin("gemini", []string{}, prompt)
-		return LLMResponse{Content: out}, err
-	case config.ProviderClaude:
-		out, err := runCLIWithStdin("claude", []string{"-p", prompt}, "")
-		return LLMResponse{Content: out}, err
-	case config.ProviderCursor:
-		out, err := runCLIWithStdin("agent", []string{"-p", prompt, "--output-format", "text"}, "")
-		if err != nil && strings.Contains(strings.ToLower(err.Error()), "output-format") {
-			out, err = runCLIWithStdin("agent", []string{"-p", prompt}, "")
-		}
-		return LLMResponse{Content: out}, err
-	case config.ProviderCodex:
-		out, err := runCLIWithStdin("codex", []string{"exec", "-"}, prompt)
-		return LLMResponse{Content: out}, err
-	default:
-		return LLMResponse{}, fmt.Errorf("unsupported CLI provider: %s", c.cfg.Provider)
-	}
-}
-
-func runCLIWithStdin(command string, args []string, input string) (string, error) {
-	cmd := exec.Command(command, args...)
-	if input != "" {
-		cmd.Stdin = strings.NewReader(input)
-	}
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("%s failed: %s", command, strings.TrimSpace(string(out)))
-	}
-	return strings.TrimSpace(string(out)), nil
-}
-
 func (c *Client) chatOpenAICompat(messages []ChatMessage) (LLMResponse, error) {
 	url := "https://api.openai.com/v1/chat/completions"
-	if c.cfg.Provider == config.ProviderOllama || c.cfg.Provider == config.ProviderLMStudio {
+	if c.cfg.Provider == config.ProviderOllama || c.cfg.Provider == config.ProviderLMStudio || c.cfg.Provider == config.ProviderOpenAICompatible {
 		url = strings.TrimRight(c.cfg.BaseURL, "/") + "/chat/completions"
 	}
 
@@ -134,19 +186,14 @@ func (c *Client) chatOpenAICompat(messages []ChatMessage) (LLMResponse, error) {
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
-	if c.cfg.Provider == config.ProviderOpenAI {
+	if c.cfg.Provider == config.ProviderOpenAI || (c.cfg.Provider == config.ProviderOpenAICompatible && c.cfg.APIKey != "") {
 		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	respBody, err := c.do(req)
 	if err != nil {
 		return LLMResponse{}, err
 	}
-	defer resp.Body.Close()
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode >= 300 {
-		return LLMResponse{}, errors.New(string(respBody))
-	}
 	var parsed struct {
 		Choices []struct {
 			Message ChatMessage `json:"message"`
@@ -184,15 +231,10 @@ func (c *Client) chatAnthropic(messages []ChatMessage) (LLMResponse, error) {
 	req.Header.Set("x-api-key", c.cfg.APIKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
-	resp, err := c.httpClient.Do(req)
+	respBody, err := c.do(req)
 	if err != nil {
 		return LLMResponse{}, err
 	}
-	defer resp.Body.Close()
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode >= 300 {
-		return LLMResponse{}, errors.New(string(respBody))
-	}
 	var parsed struct {
 		Content []struct {
 			Text string `json:"text"`
@@ -223,15 +265,10 @@ func (c *Client) chatGoogle(messages []ChatMessage) (LLMResponse, error) {
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	respBody, err := c.do(req)
 	if err != nil {
 		return LLMResponse{}, err
 	}
-	defer resp.Body.Close()
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode >= 300 {
-		return LLMResponse{}, errors.New(string(respBody))
-	}
 	var parsed struct {
 		Candidates []struct {
 			Content struct {
@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"difflearn-go/internal/config"
+)
+
+func TestOpenAICompatibleEndpoint(t *testing.T) {
+	var gotPath, gotAuth, gotModel string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotModel = body.Model
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.Config{Provider: config.ProviderOpenAICompatible, BaseURL: srv.URL + "/v1/", Model: "llama-3.3-70b", APIKey: "gsk"}
+	resp, err := NewClient(cfg).chat([]ChatMessage{{Role: "user", Content: "hello"}})
+	if err != nil || resp.Content != "hi" {
+		t.Fatalf("chat = %+v, %v", resp, err)
+	}
+	if gotPath != "/v1/chat/completions" || gotAuth != "Bearer gsk" || gotModel != "llama-3.3-70b" {
+		t.Fatalf("request went to %s with %q for %s", gotPath, gotAuth, gotModel)
+	}
+
+	cfg.APIKey = ""
+	if _, err := NewClient(cfg).chat([]ChatMessage{{Role: "user", Content: "hello"}}); err != nil || gotAuth != "" {
+		t.Fatalf("a keyless server should get no Authorization header: %q, %v", gotAuth, err)
+	}
+}
+
+func TestListModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = w.Write([]byte(`{"data":[{"id":"zeta"},{"id":"alpha"}]}`))
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"llama3.2:latest"},{"name":"qwen2.5-coder:7b"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	models, err := NewClient(config.Config{Provider: config.ProviderOpenAICompatible, BaseURL: srv.URL + "/v1"}).ListModels()
+	if err != nil || len(models) != 2 || models[0] != "alpha" {
+		t.Fatalf("openai-style models = %v, %v", models, err)
+	}
+	models, err = NewClient(config.Config{Provider: config.ProviderOllama, BaseURL: srv.URL + "/v1"}).ListModels()
+	if err != nil || len(models) != 2 || models[0] != "llama3.2:latest" {
+		t.Fatalf("ollama models = %v, %v", models, err)
+	}
+	if _, err := NewClient(config.Config{Provider: config.ProviderClaude, UseCLI: true}).ListModels(); err == nil {
+		t.Fatal("CLI providers have no model list")
+	}
+}
+
+func TestChatFallsBack(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	t.Setenv("DIFFLEARN_RETRIES", "0")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down/chat/completions" {
+			http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"from the fallback"}}]}`))
+	}))
+	defer srv.Close()
+	primary := config.Config{Provider: config.ProviderLMStudio, BaseURL: srv.URL + "/down", Model: "local", APIKey: "local"}
+
+	if _, err := NewClient(primary).Chat([]ChatMessage{{Role: "user", Content: "hi"}}); err == nil {
+		t.Fatal("without fallbacks the error should come through")
+	}
+
+	t.Setenv("DIFFLEARN_BASE_URL", srv.URL+"/up")
+	t.Setenv("DIFFLEARN_FALLBACK", "openai-compatible:backup")
+	var failed, answered config.LLMProvider
+	OnFallback = func(f, a config.Config, err error) { failed, answered = f.Provider, a.Provider }
+	defer func() { OnFallback = nil }()
+	resp, err := NewClient(primary).Chat([]ChatMessage{{Role: "user", Content: "hi"}})
+	if err != nil || resp.Content != "from the fallback" {
+		t.Fatalf("chat = %+v, %v", resp, err)
+	}
+	if resp.Provider != config.ProviderOpenAICompatible || resp.Model != "backup" || failed != config.ProviderLMStudio || answered != resp.Provider {
+		t.Fatalf("answered by %s/%s, reported %s -> %s", resp.Provider, resp.Model, failed, answered)
+	}
+}
@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"sync"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+type ModelAnswer struct {
+	Provider   config.LLMProvider `json:"provider"`
+	Model      string             `json:"model"`
+	Content    string             `json:"content,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	DurationMs int64              `json:"durationMs"`
+	Usage      map[string]any     `json:"usage,omitempty"`
+}
+
+// CompareModels sends the same messages to every config concurrently and
+// returns the answers in the order the configs were given.
+func CompareModels(cfgs []config.Config, messages []ChatMessage) []ModelAnswer {
+	return CompareModelsFor("", "", cfgs, messages)
+}
+
+// CompareModelsFor is CompareModels with the calls counted against the
+// budgets of an API token and endpoint, as Client.For does.
+func CompareModelsFor(key, endpoint string, cfgs []config.Config, messages []ChatMessage) []ModelAnswer {
+	answers := make([]ModelAnswer, len(cfgs))
+	var wg sync.WaitGroup
+	for i, cfg := range cfgs {
+		wg.Add(1)
+		go func(i int, cfg config.Config) {
+			defer wg.Done()
+			start := time.Now()
+			resp, err := NewClient(cfg).For(key, endpoint).Chat(messages)
+			answer := ModelAnswer{Provider: cfg.Provider, Model: cfg.Model, Content: resp.Content, Usage: resp.Usage, DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				answer.Error = err.Error()
+			}
+			answers[i] = answer
+		}(i, cfg)
+	}
+	wg.Wait()
+	return answers
+}
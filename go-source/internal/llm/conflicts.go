@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// conflictContextLines is how much unchanged code around each conflict the
+// prompt shows.
+const conflictContextLines = 8
+
+// CreateConflictPrompt asks for an explanation of each conflict in f and a
+// resolution that ParseConflictResolutions can read back.
+func CreateConflictPrompt(f git.ConflictedFile) string {
+	var sb strings.Builder
+	for i, c := range f.Conflicts {
+		before, after := f.Context(i, conflictContextLines)
+		sb.WriteString(fmt.Sprintf("### Conflict %d (line %d)\n\n", i+1, c.Line))
+		writeFenced(&sb, "Code before", before)
+		writeFenced(&sb, fmt.Sprintf("Ours (%s)", c.OursLabel), c.Ours)
+		if c.HasBase {
+			writeFenced(&sb, "Base (common ancestor)", c.Base)
+		}
+		writeFenced(&sb, fmt.Sprintf("Theirs (%s)", c.TheirsLabel), c.Theirs)
+		writeFenced(&sb, "Code after", after)
+	}
+	return fmt.Sprintf(`The file %s has %d merge conflict(s):
+
+%sFor each conflict, explain what our side and their side changed (relative to the base when it is given), why the changes clash and what combining them requires. Point out when one side looks like it should simply win, and when a correct merge needs code neither side has.
+
+Then, after all the explanations, add one section per conflict, in order, headed exactly "### Resolution N" and containing only one fenced code block with the text that replaces the whole conflict region: no conflict markers and none of the code before or after it. Use an empty code block to drop the region.`, f.Path, len(f.Conflicts), sb.String())
+}
+
+func writeFenced(sb *strings.Builder, title, code string) {
+	if code == "" {
+		sb.WriteString(title + ": (empty)\n\n")
+		return
+	}
+	sb.WriteString(title + ":\n```\n" + code)
+	if !strings.HasSuffix(code, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("```\n\n")
+}
+
+var resolutionRe = regexp.MustCompile("(?sm)^#+ *Resolution (\\d+)[^\\n]*\\n+```[^\\n]*\\n(.*?)^```[ \\t]*$")
+
+// ParseConflictResolutions extracts the n resolutions CreateConflictPrompt
+// asks for, in conflict order.
+func ParseConflictResolutions(answer string, n int) ([]string, error) {
+	found := map[int]string{}
+	for _, m := range resolutionRe.FindAllStringSubmatch(answer, -1) {
+		id, _ := strconv.Atoi(m[1])
+		found[id] = m[2]
+	}
+	out := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		r, ok := found[i]
+		if !ok {
+			return nil, fmt.Errorf("the answer has no resolution for conflict %d", i)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"time"
+
+	"difflearn-go/internal/git"
+)
+
+// ConversationTurn is one answered question of a Conversation.
+type ConversationTurn struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// Conversation keeps the questions and answers of a chat about a diff so
+// follow-up questions see what was said before. The diff itself is not
+// stored: every question sends the current diff with the first question, so
+// the context stays fresh while the working tree changes.
+type Conversation struct {
+	ID      string             `json:"id"`
+	Turns   []ConversationTurn `json:"turns"`
+	Updated time.Time          `json:"updated"`
+}
+
+// Ask sends question with the diff and the earlier turns, records the answer
+// and returns it. The first question is a plain budgeted question about the
+// diff. For follow-ups the oldest turns are dropped when they take more than
+// half of the budget and the diff is trimmed to the rest.
+func (c *Conversation) Ask(client Chatter, formatter *git.DiffFormatter, diffs []git.ParsedDiff, budget TokenBudget, question string) (LLMResponse, BudgetReport, error) {
+	turns := append(c.Turns[:len(c.Turns):len(c.Turns)], ConversationTurn{Question: question})
+	turns = trimTurns(turns, budget.Available()/2)
+
+	var resp LLMResponse
+	var report BudgetReport
+	var err error
+	if len(turns) == 1 {
+		resp, report, err = RunBudgeted(client, formatter, diffs, budget, func(d []git.ParsedDiff) string {
+			return CreateQuestionPrompt(formatter, d, question)
+		})
+	} else {
+		for _, t := range turns {
+			budget.ReserveTokens += EstimateTokens(t.Question) + EstimateTokens(t.Answer)
+		}
+		var fitted []git.ParsedDiff
+		fitted, report = budget.Fit(formatter, diffs)
+		resp, err = client.Chat(conversationMessages(formatter, fitted, turns))
+	}
+	if err != nil {
+		return LLMResponse{}, report, err
+	}
+	turns[len(turns)-1].Answer = resp.Content
+	c.Turns = turns
+	c.Updated = time.Now()
+	return resp, report, nil
+}
+
+// Reset forgets the earlier turns.
+func (c *Conversation) Reset() {
+	c.Turns = nil
+	c.Updated = time.Now()
+}
+
+// conversationMessages lays the turns out as alternating user and assistant
+// messages; the first question carries the diff.
+func conversationMessages(formatter *git.DiffFormatter, diffs []git.ParsedDiff, turns []ConversationTurn) []ChatMessage {
+	messages := make([]ChatMessage, 0, 2*len(turns)+1)
+	messages = append(messages, ChatMessage{Role: "system", Content: SystemPrompt})
+	for i, t := range turns {
+		content := t.Question
+		if i == 0 {
+			content = CreateQuestionPrompt(formatter, diffs, t.Question)
+		}
+		messages = append(messages, ChatMessage{Role: "user", Content: content})
+		if i < len(turns)-1 {
+			messages = append(messages, ChatMessage{Role: "assistant", Content: t.Answer})
+		}
+	}
+	return messages
+}
+
+// trimTurns drops the oldest turns until the rest fit in limit tokens. The
+// newest turn is always kept.
+func trimTurns(turns []ConversationTurn, limit int) []ConversationTurn {
+	total := 0
+	for _, t := range turns {
+		total += EstimateTokens(t.Question) + EstimateTokens(t.Answer)
+	}
+	for len(turns) > 1 && total > limit {
+		total -= EstimateTokens(turns[0].Question) + EstimateTokens(turns[0].Answer)
+		turns = turns[1:]
+	}
+	return turns
+}
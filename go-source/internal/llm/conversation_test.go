@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+type messageRecorder struct {
+	calls [][]ChatMessage
+}
+
+func (m *messageRecorder) Chat(messages []ChatMessage) (LLMResponse, error) {
+	m.calls = append(m.calls, messages)
+	return LLMResponse{Content: fmt.Sprintf("answer %d", len(m.calls))}, nil
+}
+
+func TestConversationAskKeepsEarlierTurns(t *testing.T) {
+	formatter := git.NewDiffFormatter()
+	diffs := []git.ParsedDiff{largeDiff("main.go", 1, 3)}
+	budget := NewTokenBudget(100000, 1000)
+	rec := &messageRecorder{}
+	var c Conversation
+
+	if _, _, err := c.Ask(rec, formatter, diffs, budget, "1 what changed?"); err != nil {
+		t.Fatal(err)
+	}
+	resp, _, err := c.Ask(rec, formatter, diffs, budget, "2 and why?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "answer 2" || len(c.Turns) != 2 || c.Turns[0].Answer != "answer 1" {
+		t.Fatalf("unexpected turns %+v (response %q)", c.Turns, resp.Content)
+	}
+
+	second := rec.calls[1]
+	roles := make([]string, len(second))
+	for i, m := range second {
+		roles[i] = m.Role
+	}
+	if strings.Join(roles, ",") != "system,user,assistant,user" {
+		t.Fatalf("unexpected roles %v", roles)
+	}
+	if !strings.Contains(second[1].Content, "main.go") || !strings.Contains(second[1].Content, "1 what changed?") {
+		t.Fatalf("first question should carry the diff: %q", second[1].Content)
+	}
+	if second[3].Content != "2 and why?" {
+		t.Fatalf("follow-up should be sent as asked, got %q", second[3].Content)
+	}
+
+	c.Reset()
+	if _, _, err := c.Ask(rec, formatter, diffs, budget, "3 again"); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.calls[2]) != 2 {
+		t.Fatalf("reset conversation should start over, got %d messages", len(rec.calls[2]))
+	}
+}
+
+func TestTrimTurnsDropsOldest(t *testing.T) {
+	turns := []ConversationTurn{
+		{Question: strings.Repeat("old ", 100), Answer: strings.Repeat("answer ", 100)},
+		{Question: "recent", Answer: "short"},
+		{Question: "new"},
+	}
+	got := trimTurns(turns, 50)
+	if len(got) != 2 || got[0].Question != "recent" {
+		t.Fatalf("expected the oldest turn to be dropped, got %+v", got)
+	}
+	if got := trimTurns(turns[2:], 0); len(got) != 1 {
+		t.Fatalf("the newest turn must be kept, got %+v", got)
+	}
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// Flashcard is one question-and-answer card about a concept a change
+// teaches. Code is an optional short excerpt of the hunk it came from.
+type Flashcard struct {
+	File    string `json:"file"`
+	Front   string `json:"front"`
+	Back    string `json:"back"`
+	Code    string `json:"code,omitempty"`
+	Concept string `json:"concept,omitempty"`
+}
+
+// CreateFlashcardPrompt asks for up to count flashcards on the most
+// instructive hunks of the diff.
+func CreateFlashcardPrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff, count int) string {
+	return fmt.Sprintf(`Turn the following code changes into at most %d flashcards for spaced-repetition study. Pick the hunks that teach something worth remembering: a language feature, an API, a design pattern, a bug class and its fix, a non-obvious reason for the change. Skip formatting, renames and boilerplate. Each card asks one question on the front and answers it on the back in two or three sentences that make sense months later without the diff.
+
+%s
+
+Reply with a single JSON object and nothing else, in exactly this shape:
+
+{"cards": [{"file": "<path as shown in the diff>", "front": "<question>", "back": "<answer>", "code": "<up to 8 lines of the hunk the card is about, or empty>", "concept": "<one or two words naming the concept>"}]}`, count, formatter.ToMarkdown(diffs))
+}
+
+// ParseFlashcards extracts the cards from a flashcard answer, dropping cards
+// without both sides.
+func ParseFlashcards(content string) ([]Flashcard, error) {
+	var result struct {
+		Cards []Flashcard `json:"cards"`
+	}
+	if err := unmarshalJSONAnswer(content, &result); err != nil {
+		return nil, fmt.Errorf("flashcards: %w", err)
+	}
+	cards := make([]Flashcard, 0, len(result.Cards))
+	for _, c := range result.Cards {
+		if strings.TrimSpace(c.Front) != "" && strings.TrimSpace(c.Back) != "" {
+			cards = append(cards, c)
+		}
+	}
+	return cards, nil
+}
+
+// GenerateFlashcards asks for flashcards on diffs, trimmed to fit the budget
+// since the answer must be a single JSON object.
+func GenerateFlashcards(client Chatter, formatter *git.DiffFormatter, diffs []git.ParsedDiff, budget TokenBudget, count int) ([]Flashcard, BudgetReport, error) {
+	fitted, report := budget.Fit(formatter, diffs)
+	resp, err := client.Chat([]ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: CreateFlashcardPrompt(formatter, fitted, count)}})
+	if err != nil {
+		return nil, report, err
+	}
+	cards, err := ParseFlashcards(resp.Content)
+	return cards, report, err
+}
+
+// FormatAnkiCSV writes cards as a CSV file Anki imports directly: its header
+// lines pick the Basic note type, the deck and the tags column. The back
+// carries the code excerpt and the source file; tags are "difflearn", the
+// extra tags and the card's concept.
+func FormatAnkiCSV(cards []Flashcard, deck string, tags ...string) string {
+	var b bytes.Buffer
+	b.WriteString("#separator:Comma\n#html:true\n#notetype:Basic\n")
+	if deck != "" {
+		fmt.Fprintf(&b, "#deck:%s\n", deck)
+	}
+	b.WriteString("#tags column:3\n")
+	w := csv.NewWriter(&b)
+	for _, c := range cards {
+		back := htmlText(c.Back)
+		if code := strings.TrimRight(c.Code, "\n"); code != "" {
+			back += "<br><br><pre><code>" + htmlEscape(code) + "</code></pre>"
+		}
+		if c.File != "" {
+			back += "<br><small>" + htmlEscape(c.File) + "</small>"
+		}
+		cardTags := append([]string{"difflearn"}, tags...)
+		if c.Concept != "" {
+			cardTags = append(cardTags, c.Concept)
+		}
+		if c.File != "" {
+			cardTags = append(cardTags, path.Base(c.File))
+		}
+		for i, t := range cardTags {
+			cardTags[i] = strings.Join(strings.Fields(t), "_")
+		}
+		_ = w.Write([]string{htmlText(c.Front), back, strings.Join(cardTags, " ")})
+	}
+	w.Flush()
+	return b.String()
+}
+
+func htmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// htmlText escapes s and keeps its line breaks.
+func htmlText(s string) string {
+	return strings.ReplaceAll(htmlEscape(strings.TrimSpace(s)), "\n", "<br>")
+}
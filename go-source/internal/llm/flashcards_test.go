@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFlashcards(t *testing.T) {
+	cards, err := ParseFlashcards(`Sure! {"cards": [{"file": "a.go", "front": "Why defer?", "back": "To close the file."}, {"front": "No back", "back": " "}]}`)
+	if err != nil || len(cards) != 1 || cards[0].File != "a.go" {
+		t.Fatalf("ParseFlashcards = %+v, %v", cards, err)
+	}
+}
+
+func TestFormatAnkiCSV(t *testing.T) {
+	got := FormatAnkiCSV([]Flashcard{{
+		File:    "internal/store/db.go",
+		Front:   "What does `defer rows.Close()` guarantee?",
+		Back:    "The rows are closed\non every return path.",
+		Code:    "rows, err := db.Query(q)\ndefer rows.Close()",
+		Concept: "resource cleanup",
+	}}, "DiffLearn::shop", "shop")
+	for _, want := range []string{
+		"#separator:Comma\n#html:true\n#notetype:Basic\n#deck:DiffLearn::shop\n#tags column:3\n",
+		"What does `defer rows.Close()` guarantee?,",
+		`"The rows are closed<br>on every return path.<br><br><pre><code>rows, err := db.Query(q)` + "\n" + `defer rows.Close()</code></pre><br><small>internal/store/db.go</small>"`,
+		",difflearn shop resource_cleanup db.go\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+}
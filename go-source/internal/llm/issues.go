@@ -0,0 +1,20 @@
+package llm
+
+import (
+	"fmt"
+
+	"difflearn-go/internal/git"
+)
+
+// CreateIssueSummaryPrompt asks for a release-notes entry describing what the
+// commits referencing one issue changed.
+func CreateIssueSummaryPrompt(formatter *git.DiffFormatter, issue string, commits []git.CommitInfo, diffs []git.ParsedDiff) string {
+	return fmt.Sprintf(`These commits reference the issue %s:
+
+%s
+Their changes:
+
+%s
+
+Write a release-notes entry for %s in two to four sentences: what users or maintainers get from the change, then anything notable about how it was done (migrations, new settings, behavior changes). Do not repeat the issue reference or list the commits, and output only the entry.`, issue, commitLog(commits), formatter.ToMarkdown(diffs), issue)
+}
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"strings"
+	"sync"
+)
+
+// languageNames spells out common language codes, so DIFFLEARN_LANGUAGE=de
+// works as well as German.
+var languageNames = map[string]string{
+	"ar": "Arabic", "cs": "Czech", "da": "Danish", "de": "German", "el": "Greek",
+	"en": "English", "es": "Spanish", "fi": "Finnish", "fr": "French", "he": "Hebrew",
+	"hi": "Hindi", "hu": "Hungarian", "id": "Indonesian", "it": "Italian", "ja": "Japanese",
+	"ko": "Korean", "nl": "Dutch", "no": "Norwegian", "pl": "Polish", "pt": "Portuguese",
+	"pt-br": "Brazilian Portuguese", "ro": "Romanian", "ru": "Russian", "sv": "Swedish",
+	"th": "Thai", "tr": "Turkish", "uk": "Ukrainian", "vi": "Vietnamese",
+	"zh": "Simplified Chinese", "zh-cn": "Simplified Chinese", "zh-tw": "Traditional Chinese",
+}
+
+// LanguageName returns the language answers should be written in for a
+// code such as "de" or "pt_BR", or the value itself when it already names
+// a language. Empty and "auto" leave the choice to the model.
+func LanguageName(value string) string {
+	value = strings.TrimSpace(value)
+	key := strings.ToLower(strings.ReplaceAll(value, "_", "-"))
+	if key == "" || key == "auto" {
+		return ""
+	}
+	if name, ok := languageNames[key]; ok {
+		return name
+	}
+	// A locale such as fr-CA or de_AT.UTF-8 falls back to its language.
+	if base, _, found := strings.Cut(key, "-"); found {
+		if name, ok := languageNames[base]; ok {
+			return name
+		}
+	}
+	return value
+}
+
+var defaultLanguage struct {
+	sync.Mutex
+	name string
+}
+
+// SetDefaultLanguage sets the answer language of requests whose config
+// names none (DIFFLEARN_LANGUAGE, --lang).
+func SetDefaultLanguage(value string) {
+	defaultLanguage.Lock()
+	defer defaultLanguage.Unlock()
+	defaultLanguage.name = LanguageName(value)
+}
+
+// DefaultLanguage is the language set by SetDefaultLanguage.
+func DefaultLanguage() string {
+	defaultLanguage.Lock()
+	defer defaultLanguage.Unlock()
+	return defaultLanguage.name
+}
+
+// LanguagePrompt is the system prompt section asking for answers in
+// language.
+func LanguagePrompt(language string) string {
+	if language == "" {
+		return ""
+	}
+	return "Write your answer in " + language + ". Keep code, identifiers, file paths, commands and quoted diff lines exactly as they are, and keep any requested JSON keys in English."
+}
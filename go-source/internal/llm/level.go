@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Level is the audience answers are written for.
+type Level string
+
+const (
+	LevelDefault      Level = ""
+	LevelBeginner     Level = "beginner"
+	LevelIntermediate Level = "intermediate"
+	LevelExpert       Level = "expert"
+)
+
+// Levels lists the levels accepted by ParseLevel.
+var Levels = []Level{LevelBeginner, LevelIntermediate, LevelExpert}
+
+// ParseLevel accepts beginner, intermediate and expert, plus a few common
+// synonyms; empty keeps the prompts unchanged.
+func ParseLevel(value string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "default":
+		return LevelDefault, nil
+	case "beginner", "junior", "novice":
+		return LevelBeginner, nil
+	case "intermediate", "mid":
+		return LevelIntermediate, nil
+	case "expert", "senior":
+		return LevelExpert, nil
+	}
+	return "", fmt.Errorf("unknown level %q (expected beginner, intermediate or expert)", value)
+}
+
+var defaultLevel struct {
+	sync.Mutex
+	level Level
+}
+
+// SetDefaultLevel sets the level of requests whose config names none
+// (DIFFLEARN_LEVEL, --level).
+func SetDefaultLevel(level Level) {
+	defaultLevel.Lock()
+	defer defaultLevel.Unlock()
+	defaultLevel.level = level
+}
+
+// DefaultLevel is the level set by SetDefaultLevel.
+func DefaultLevel() Level {
+	defaultLevel.Lock()
+	defer defaultLevel.Unlock()
+	return defaultLevel.level
+}
+
+// LevelPrompt is the system prompt section that pitches answers at level.
+func LevelPrompt(level Level) string {
+	switch level {
+	case LevelBeginner:
+		return "Audience: a junior developer who is still learning this codebase and its language. Define jargon and project-specific terms the first time they appear, explain why the code is written this way and not only what it does, point out the concepts worth reading up on, and prefer small concrete examples. Keep review findings gentle and explain how to fix each one."
+	case LevelIntermediate:
+		return "Audience: a developer comfortable with the language who is new to this part of the codebase. Skip basic language features, but explain design decisions, how the change fits the surrounding code and any non-obvious behavior."
+	case LevelExpert:
+		return "Audience: a senior engineer reviewing the change. Be terse: skip explanations of language features and common patterns, lead with risks, edge cases, performance and design trade-offs, and reference exact files and lines."
+	}
+	return ""
+}
+
+// withSection adds section to a leading system message.
+func withSection(messages []ChatMessage, section string) []ChatMessage {
+	if section == "" || len(messages) == 0 || messages[0].Role != "system" {
+		return messages
+	}
+	return append([]ChatMessage{{Role: "system", Content: messages[0].Content + "\n\n" + section}}, messages[1:]...)
+}
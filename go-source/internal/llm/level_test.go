@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/config"
+)
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]Level{"": LevelDefault, "Beginner": LevelBeginner, "senior": LevelExpert, "mid": LevelIntermediate} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseLevel("guru"); err == nil {
+		t.Fatal("unknown levels must be rejected")
+	}
+}
+
+func TestWithSection(t *testing.T) {
+	messages := []ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: "explain"}}
+	if got := withSection(messages, LevelPrompt(LevelDefault)); got[0].Content != SystemPrompt {
+		t.Fatal("the default level must leave the system prompt alone")
+	}
+	got := withSection(messages, LevelPrompt(LevelBeginner))
+	if !strings.HasSuffix(got[0].Content, LevelPrompt(LevelBeginner)) || got[1].Content != "explain" {
+		t.Fatalf("beginner section missing: %+v", got)
+	}
+	if messages[0].Content != SystemPrompt {
+		t.Fatal("withSection must not modify its input")
+	}
+}
+
+func TestClientLevelPrecedence(t *testing.T) {
+	SetDefaultLevel(LevelExpert)
+	t.Cleanup(func() { SetDefaultLevel(LevelDefault) })
+	if got := NewClient(config.Config{}).level(); got != LevelExpert {
+		t.Fatalf("expected the default level, got %q", got)
+	}
+	if got := NewClient(config.Config{Level: "beginner"}).For("", "explain").level(); got != LevelBeginner {
+		t.Fatalf("the request's level should win, got %q", got)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	for in, want := range map[string]string{"": "", "auto": "", "de": "German", "pt_BR": "Brazilian Portuguese", "fr-CA": "French", "Klingon": "Klingon"} {
+		if got := LanguageName(in); got != want {
+			t.Errorf("LanguageName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	SetDefaultLanguage("es")
+	t.Cleanup(func() { SetDefaultLanguage("") })
+	if got := NewClient(config.Config{}).language(); got != "Spanish" {
+		t.Fatalf("expected the default language, got %q", got)
+	}
+	if got := NewClient(config.Config{Language: "ja"}).language(); got != "Japanese" {
+		t.Fatalf("the request's language should win, got %q", got)
+	}
+	if !strings.Contains(LanguagePrompt("German"), "in German") || LanguagePrompt("") != "" {
+		t.Fatal("unexpected language prompt")
+	}
+}
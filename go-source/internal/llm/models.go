@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"difflearn-go/internal/config"
+)
+
+// ListModels asks the provider which models it offers: /models of OpenAI
+// and OpenAI-style servers, /api/tags of Ollama, Anthropic's and Google's
+// model lists. CLI providers have no such list.
+func (c *Client) ListModels() ([]string, error) {
+	if c.cfg.UseCLI {
+		return nil, errors.New("CLI providers have no model list; set one with difflearn config set model <name>")
+	}
+	var models []string
+	var err error
+	switch c.cfg.Provider {
+	case config.ProviderOpenAI, config.ProviderLMStudio, config.ProviderOpenAICompatible:
+		url := "https://api.openai.com/v1/models"
+		var headers map[string]string
+		if c.cfg.Provider == config.ProviderOpenAI || (c.cfg.Provider == config.ProviderOpenAICompatible && c.cfg.APIKey != "") {
+			headers = map[string]string{"Authorization": "Bearer " + c.cfg.APIKey}
+		}
+		if c.cfg.Provider != config.ProviderOpenAI {
+			url = strings.TrimRight(c.cfg.BaseURL, "/") + "/models"
+		}
+		var list struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		err = c.getJSON(url, headers, &list)
+		for _, m := range list.Data {
+			models = append(models, m.ID)
+		}
+	case config.ProviderOllama:
+		// The native API lives next to the OpenAI-style one under /v1.
+		host := strings.TrimSuffix(strings.TrimRight(c.cfg.BaseURL, "/"), "/v1")
+		var tags struct {
+			Models []struct {
+				Name string `json:"name"`
+			} `json:"models"`
+		}
+		err = c.getJSON(host+"/api/tags", nil, &tags)
+		for _, m := range tags.Models {
+			models = append(models, m.Name)
+		}
+	case config.ProviderAnthropic:
+		var list struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		err = c.getJSON("https://api.anthropic.com/v1/models?limit=1000", map[string]string{"x-api-key": c.cfg.APIKey, "anthropic-version": "2023-06-01"}, &list)
+		for _, m := range list.Data {
+			models = append(models, m.ID)
+		}
+	case config.ProviderGoogle:
+		var list struct {
+			Models []struct {
+				Name    string   `json:"name"`
+				Methods []string `json:"supportedGenerationMethods"`
+			} `json:"models"`
+		}
+		err = c.getJSON("https://generativelanguage.googleapis.com/v1beta/models?pageSize=1000&key="+c.cfg.APIKey, nil, &list)
+		for _, m := range list.Models {
+			for _, method := range m.Methods {
+				if method == "generateContent" {
+					models = append(models, strings.TrimPrefix(m.Name, "models/"))
+					break
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", c.cfg.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+func (c *Client) getJSON(url string, headers map[string]string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	body, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
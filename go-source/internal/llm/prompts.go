@@ -2,7 +2,10 @@ package llm
 
 import (
 	"fmt"
+	"strings"
 
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/config"
 	"difflearn-go/internal/git"
 )
 
@@ -29,22 +32,174 @@ Keep responses focused and actionable.`
 
 func CreateExplainPrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff) string {
 	diffMarkdown := formatter.ToMarkdown(diffs)
-	return fmt.Sprintf("Please explain the following code changes. Describe what was changed, why it might have been changed, and any implications:\n\n%s\n\nProvide a clear, structured explanation that would help someone understand these changes quickly.", diffMarkdown)
+	return templatedPrompt("explain", formatter, diffs, "", fmt.Sprintf("Please explain the following code changes. Describe what was changed, why it might have been changed, and any implications:\n\n%s\n\nProvide a clear, structured explanation that would help someone understand these changes quickly.", diffMarkdown))
+}
+
+func CreateFileExplainPrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff, filePath string) string {
+	diffMarkdown := formatter.ToMarkdown(diffs)
+	return fmt.Sprintf("Please explain the changes made to `%s`. Focus only on this file: walk through each hunk, describe what the code did before and after, and note how the change affects callers or related code:\n\n%s\n\nKeep the explanation scoped to this file.", filePath, diffMarkdown)
+}
+
+// CreateNarrativePrompt explains a change as its author would tell it:
+// the order it was probably written in, step by step, in the first person.
+func CreateNarrativePrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff) string {
+	diffMarkdown := formatter.ToMarkdown(diffs)
+	return fmt.Sprintf(`Explain the following code changes as their author, in the first person, by retelling how the change was most likely written:
+
+%s
+
+Reconstruct a plausible order of work rather than listing files: usually the groundwork first (new types, configuration, dependencies, signatures), then the core logic, then the code wiring it in, then tests, then cleanup such as renames, removed code and docs. Follow the dependencies in the diff, not the file order, and say so when the order is a guess.
+
+Write one short "## Step N: ..." section per step. In each, say what I set out to do, which files and hunks I touched (quote the key lines), and why I did it that way or what I had to keep in mind. Only describe what the diff shows; do not invent motives, history or code that is not there. End with a "## Looking back" paragraph on what the change achieves as a whole and what I would point a reviewer at.`, diffMarkdown)
+}
+
+// CreateExerciseRequirementsPrompt turns a commit into a task a learner
+// can attempt from its parent without having seen the change.
+func CreateExerciseRequirementsPrompt(formatter *git.DiffFormatter, message string, diffs []git.ParsedDiff) string {
+	diffMarkdown := formatter.ToMarkdown(diffs)
+	return fmt.Sprintf(`A learner is going to make the following commit themselves, starting from its parent, as an exercise. They will see its message and what you write, but not the diff.
+
+Commit message:
+
+%s
+
+%s
+
+Write the requirements they should work from:
+- "## Goal": one or two sentences on what the change achieves and why.
+- "## Requirements": a numbered list of observable behaviours the finished change must have, precise enough to check an attempt against, including edge cases the diff handles and any tests it adds.
+- "## Hints": which files or areas to look at, and constraints such as existing helpers to reuse.
+
+Do not quote or paraphrase the new code, name new functions or variables the learner has to invent, or describe the implementation step by step; the point is for them to work that out.`, strings.TrimSpace(message), diffMarkdown)
+}
+
+// CreateExerciseFeedbackPrompt compares a learner's attempt at a commit
+// with the real one. difference is the real commit diffed against the
+// attempt: deleted lines are only in the commit, added lines only in the
+// attempt.
+func CreateExerciseFeedbackPrompt(formatter *git.DiffFormatter, message string, real, difference []git.ParsedDiff) string {
+	return fmt.Sprintf(`A learner tried to make the following commit themselves, starting from its parent and knowing only its message and a list of requirements.
+
+Commit message:
+
+%s
+
+## The real commit
+
+%s
+
+## The attempt compared with the real commit
+
+In this diff, lines starting with '-' are in the real commit but not in the attempt, and lines starting with '+' are in the attempt but not in the real commit. Files not listed are identical.
+
+%s
+
+Explain the differences to the learner, grouped by what they mean rather than by file:
+- What the attempt gets right, including places where it differs only in style or naming.
+- What it misses or gets wrong compared with the commit (behaviour, edge cases, tests), and why it matters.
+- Where the attempt is arguably better, or an equally valid alternative.
+End with the one or two things most worth learning from the real commit. Be encouraging but precise, and quote the lines you refer to.`, strings.TrimSpace(message), formatter.ToMarkdown(real), formatter.ToMarkdown(difference))
+}
+
+// CreateRevertPrompt asks why the change of pair was reverted and, when it
+// was reapplied, what changed on the way back. rangeDiff is git range-diff
+// output comparing the original with the reapplied commit.
+func CreateRevertPrompt(formatter *git.DiffFormatter, pair git.RevertPair, original []git.ParsedDiff, rangeDiff string) string {
+	message := func(c git.CommitInfo) string {
+		return strings.TrimSpace(c.Message + "\n\n" + c.Body)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "A change in this repository was reverted.\n\n## The original commit (%s)\n\n%s\n\n%s\n\n## The revert (%s)\n\n%s\n\n", short(pair.Original.Hash), message(pair.Original), formatter.ToMarkdown(original), short(pair.Revert.Hash), message(pair.Revert))
+	if pair.Reapply != nil {
+		fmt.Fprintf(&b, "## The reapplied commit (%s)\n\n%s\n\n", short(pair.Reapply.Hash), message(*pair.Reapply))
+		if strings.TrimSpace(rangeDiff) == "" {
+			b.WriteString("Its patch is the same as the original's.\n\n")
+		} else {
+			fmt.Fprintf(&b, "How it differs from the original, as git range-diff shows it (lines starting with '-' were in the original, '+' in the reapplied commit; the outer column compares the patches):\n\n```\n%s\n```\n\n", rangeDiff)
+		}
+	}
+	b.WriteString("Explain the story of this change:\n- What the original commit did.\n- Why it was most likely reverted. Use what the revert message says; otherwise reason from the change itself (risky behaviour, missing pieces, breakage it could cause) and say that it is a guess.\n")
+	if pair.Reapply != nil {
+		b.WriteString("- What differs in the reapplied commit and how those differences address the reason for the revert. If nothing differs, say what probably changed around it instead.\n")
+	}
+	b.WriteString("End with what the episode teaches about making this kind of change safely.")
+	return b.String()
 }
 
 func CreateReviewPrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff) string {
 	diffMarkdown := formatter.ToMarkdown(diffs)
-	return fmt.Sprintf("Please review the following code changes. Look for:\n- Potential bugs or errors\n- Security concerns\n- Performance issues\n- Code style and best practices\n- Suggestions for improvement\n\n%s\n\nProvide constructive feedback organized by severity (critical, important, minor).", diffMarkdown)
+	return templatedPrompt("review", formatter, diffs, "", fmt.Sprintf("Please review the following code changes. Look for:\n- Potential bugs or errors\n- Security concerns\n- Performance issues\n- Code style and best practices\n- Suggestions for improvement\n\n%s\n\nProvide constructive feedback organized by severity (critical, important, minor).", diffMarkdown))
+}
+
+func CreateRangeReviewPrompt(formatter *git.DiffFormatter, rangeSpec string, commits []git.CommitInfo, diffs []git.ParsedDiff) string {
+	diffMarkdown := formatter.ToMarkdown(diffs)
+	return fmt.Sprintf("Please review the revision range `%s`. It contains these commits:\n\n%s\nCombined changes:\n\n%s\n\nSummarize what the range accomplishes as a whole, then list potential bugs, security concerns and design issues organized by severity (critical, important, minor). Mention which commit introduced an issue when it is clear.", rangeSpec, commitLog(commits), diffMarkdown)
+}
+
+// commitLog lists commits as "- <short hash> <subject> (<author>)" lines.
+func commitLog(commits []git.CommitInfo) string {
+	var log strings.Builder
+	for _, c := range commits {
+		log.WriteString(fmt.Sprintf("- %s %s (%s)\n", short(c.Hash), c.Message, c.Author))
+	}
+	return log.String()
+}
+
+// short abbreviates a commit hash to seven characters.
+func short(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// ReviewContext carries the optional inputs that shape a review prompt.
+type ReviewContext struct {
+	Findings []analysis.Finding
+	Rubric   []config.RubricCriterion
+	// Structured asks for JSON issues (see ParseReviewResult) instead of prose.
+	Structured bool
+	// Ticket is the issue the change is meant to address; the review then
+	// also checks the diff against it.
+	Ticket string
+}
+
+// CreateReviewPromptWithContext builds the review prompt, organizing it by the
+// team rubric when one is configured and adding static pre-check findings so
+// the model can confirm, dismiss or expand on them.
+func CreateReviewPromptWithContext(formatter *git.DiffFormatter, diffs []git.ParsedDiff, rc ReviewContext) string {
+	if rc.Structured {
+		return CreateStructuredReviewPrompt(formatter, diffs, rc)
+	}
+	prompt := CreateReviewPrompt(formatter, diffs)
+	if len(rc.Rubric) > 0 {
+		diffMarkdown := formatter.ToMarkdown(diffs)
+		prompt = fmt.Sprintf("Please review the following code changes against the team's review rubric.\n\n%s\n\n%s", diffMarkdown, rubricInstructions(rc.Rubric))
+	}
+	if len(rc.Findings) > 0 {
+		prompt += "\n\nAutomated static pre-checks reported the following. Confirm the ones that are real problems, call out false positives briefly, and do not repeat them verbatim:\n\n" + analysis.FormatFindings(rc.Findings)
+	}
+	return WithTicket(prompt, rc.Ticket)
+}
+
+// WithTicket adds the issue a change is meant to address to a prose prompt
+// and asks for a section judging whether the diff delivers it. An empty
+// ticket leaves the prompt unchanged.
+func WithTicket(prompt, ticket string) string {
+	if strings.TrimSpace(ticket) == "" {
+		return prompt
+	}
+	return prompt + "\n\nThe change is meant to address this issue:\n\n<ticket>\n" + ticket + "\n</ticket>\n\nEnd with a `## Ticket coverage` section that judges whether the diff actually addresses the requirement: list what it covers, what is missing or only partly done, and changes unrelated to the ticket. Say plainly when the diff does not address it."
 }
 
 func CreateSummaryPrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff) string {
 	diffMarkdown := formatter.ToMarkdown(diffs)
-	return fmt.Sprintf("Please provide a brief summary of these changes in 2-3 sentences. Focus on the main purpose and impact:\n\n%s", diffMarkdown)
+	return templatedPrompt("summary", formatter, diffs, "", fmt.Sprintf("Please provide a brief summary of these changes in 2-3 sentences. Focus on the main purpose and impact:\n\n%s", diffMarkdown))
 }
 
 func CreateQuestionPrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff, question string) string {
 	diffMarkdown := formatter.ToMarkdown(diffs)
-	return fmt.Sprintf("Given the following code changes:\n\n%s\n\nUser question: %s\n\nPlease answer the question based on the diff context provided.", diffMarkdown, question)
+	return templatedPrompt("question", formatter, diffs, question, fmt.Sprintf("Given the following code changes:\n\n%s\n\nUser question: %s\n\nPlease answer the question based on the diff context provided.", diffMarkdown, question))
 }
 
 func CreateLineQuestionPrompt(diff git.ParsedDiff, hunkIndex int, question string) string {
@@ -65,3 +220,28 @@ func CreateLineQuestionPrompt(diff git.ParsedDiff, hunkIndex int, question strin
 	}
 	return fmt.Sprintf("In file `%s`, looking at this specific change:\n\n```diff\n%s\n%s```\n\nUser question: %s\n\nPlease answer focusing on this specific change.", diff.NewFile, h.Header, lines, question)
 }
+
+func CreateChunkPrompt(prompt string, index, total int) string {
+	return fmt.Sprintf("This is part %d of %d of a large diff that was split to fit the context window. Answer only for the files shown here; the parts will be combined afterwards.\n\n%s", index, total, prompt)
+}
+
+func CreateReducePrompt(partials []string, report BudgetReport) string {
+	var sb strings.Builder
+	sb.WriteString("The following are partial answers, each covering a different part of one large diff. Merge them into a single coherent response in the same format, removing repetition and keeping every distinct finding.\n\n")
+	for i, p := range partials {
+		sb.WriteString(fmt.Sprintf("### Part %d\n\n%s\n\n", i+1, p))
+	}
+	if report.HasOmissions() {
+		sb.WriteString("Mention briefly that some content was omitted for size: " + report.Notice() + "\n")
+	}
+	return sb.String()
+}
+
+func CreateCritiquePrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff, draft string, rubric []config.RubricCriterion) string {
+	diffMarkdown := formatter.ToMarkdown(diffs)
+	format := "organized by severity (critical, important, minor)."
+	if len(rubric) > 0 {
+		format = "keeping the rubric structure:\n\n" + rubricInstructions(rubric) + "\n\n"
+	}
+	return fmt.Sprintf("Below is a code diff followed by a draft review of it. Act as a skeptical second reviewer:\n- Check every finding against the diff and drop any claim that the diff does not support (wrong file, wrong line, code that is not actually there)\n- Correct severities that are over- or under-stated\n- Add important issues the draft missed\n\n%s\n\n## Draft review\n\n%s\n\nReply with the final review only, %s Do not mention the draft.", diffMarkdown, draft, format)
+}
@@ -58,3 +58,159 @@ func TestCreateLineQuestionPrompt(t *testing.T) {
 	}
 }
 
+func TestCreateFileExplainPrompt(t *testing.T) {
+	prompt := CreateFileExplainPrompt(git.NewDiffFormatter(), []git.ParsedDiff{sampleDiff()}, "main.go")
+	if !strings.Contains(prompt, "`main.go`") {
+		t.Fatalf("file prompt missing file path")
+	}
+	if !strings.Contains(prompt, "+new()") {
+		t.Fatalf("file prompt missing diff content")
+	}
+}
+
+func TestCreateDiscussionPrompt(t *testing.T) {
+	commits := []git.CommitInfo{{Hash: "0123456789abcdef", Message: "Add handler", Author: "Ana"}}
+	prompt := CreateDiscussionPrompt(git.NewDiffFormatter(), "commit 0123456", commits, []git.ParsedDiff{sampleDiff()}, 6)
+	for _, want := range []string{"about 6 open-ended", "- 0123456 Add handler (Ana)", "## Design trade-offs", "Listen for:", "main.go"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestCreateIssueSummaryPrompt(t *testing.T) {
+	commits := []git.CommitInfo{{Hash: "0123456789abcdef", Message: "Add CSV export (#12)", Author: "Ana"}}
+	prompt := CreateIssueSummaryPrompt(git.NewDiffFormatter(), "#12", commits, []git.ParsedDiff{sampleDiff()})
+	for _, want := range []string{"reference the issue #12", "- 0123456 Add CSV export (#12) (Ana)", "release-notes entry", "main.go"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestTicketInPrompts(t *testing.T) {
+	formatter := git.NewDiffFormatter()
+	diffs := []git.ParsedDiff{sampleDiff()}
+	if got := WithTicket("prompt", " "); got != "prompt" {
+		t.Fatalf("an empty ticket should not change the prompt, got %q", got)
+	}
+	review := CreateReviewPromptWithContext(formatter, diffs, ReviewContext{Ticket: "Export reports as CSV"})
+	for _, want := range []string{"<ticket>\nExport reports as CSV\n</ticket>", "## Ticket coverage"} {
+		if !strings.Contains(review, want) {
+			t.Fatalf("expected %q in review prompt:\n%s", want, review)
+		}
+	}
+	structured := CreateReviewPromptWithContext(formatter, diffs, ReviewContext{Ticket: "Export reports as CSV", Structured: true})
+	if !strings.Contains(structured, `"criterion": "ticket"`) || strings.Contains(structured, "## Ticket coverage") {
+		t.Fatalf("unexpected structured prompt:\n%s", structured)
+	}
+}
+
+func TestConflictPromptAndResolutions(t *testing.T) {
+	f := git.ParseConflictMarkers("main.go", "package main\n<<<<<<< HEAD\nconst v = 2\n||||||| base\nconst v = 1\n=======\nconst v = 3\n>>>>>>> feature\n")
+	prompt := CreateConflictPrompt(f)
+	for _, want := range []string{"main.go has 1 merge conflict", "Ours (HEAD):\n```\nconst v = 2\n```", "Base (common ancestor):", "Theirs (feature):", "### Resolution N"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+
+	answer := "Both sides bump v.\n\n### Resolution 1\n\n```go\nconst v = 3\n```\n"
+	got, err := ParseConflictResolutions(answer, 1)
+	if err != nil || len(got) != 1 || got[0] != "const v = 3\n" {
+		t.Fatalf("ParseConflictResolutions = %q, %v", got, err)
+	}
+	if got, err := ParseConflictResolutions("### Resolution 1\n```\n```\n", 1); err != nil || got[0] != "" {
+		t.Fatalf("empty resolution = %q, %v", got, err)
+	}
+	if _, err := ParseConflictResolutions(answer, 2); err == nil {
+		t.Fatal("expected an error for a missing resolution")
+	}
+}
+
+func TestCreateReleaseSummaryPrompt(t *testing.T) {
+	commits := []git.CommitInfo{{Hash: "0123456789abcdef", Message: "Add login", Author: "Ana"}}
+	prompt := CreateReleaseSummaryPrompt(git.NewDiffFormatter(), "v1.0", "v1.1", commits, []git.ParsedDiff{sampleDiff()})
+	for _, want := range []string{"v1.0 and v1.1", "- 0123456 Add login (Ana)", "main.go", "breaking"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("release prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestCreateStoryPrompts(t *testing.T) {
+	f := git.NewDiffFormatter()
+	commits := []git.CommitInfo{{Hash: "aaaaaaa1", Message: "Add parser"}, {Hash: "bbbbbbb2", Message: "Use parser"}}
+	diffs := []git.ParsedDiff{sampleDiff()}
+	step := CreateStoryStepPrompt(f, "the branch feature", commits, 1, diffs)
+	for _, want := range []string{"step 2 of 2", `"Use parser"`, "- aaaaaaa Add parser", "main.go"} {
+		if !strings.Contains(step, want) {
+			t.Fatalf("story step prompt missing %q:\n%s", want, step)
+		}
+	}
+	questions := CreateComprehensionPrompt(f, commits[0], diffs, 3)
+	if !strings.Contains(questions, "Write 3 short questions") || !strings.Contains(questions, `"Answer:"`) {
+		t.Fatalf("unexpected comprehension prompt:\n%s", questions)
+	}
+}
+
+func TestCreateRepoFactsPrompt(t *testing.T) {
+	got := CreateRepoFactsPrompt(git.RepoProfile{Name: "shop", Branch: "main", Languages: []string{"Go"}, Frameworks: []string{"Gin"}, Manifests: []string{"go.mod"}})
+	for _, want := range []string{"Project: shop", "Current branch: main", "Primary languages: Go", "(from go.mod): Gin"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in %s", want, got)
+		}
+	}
+	if CreateRepoFactsPrompt(git.RepoProfile{}) != "" {
+		t.Fatal("an empty profile should add nothing")
+	}
+}
+
+func TestWithRepoFacts(t *testing.T) {
+	t.Setenv("DIFFLEARN_REPO_CONTEXT", "")
+	UseRepository(t.TempDir())
+	defer UseRepository("")
+	// Not a repository: profiling fails and messages pass through.
+	messages := []ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: "hi"}}
+	if got := withRepoFacts(messages); got[0].Content != SystemPrompt {
+		t.Fatalf("unexpected system prompt: %s", got[0].Content)
+	}
+
+	repoFacts.Lock()
+	repoFacts.loaded, repoFacts.text = true, "Repository context: test"
+	repoFacts.Unlock()
+	got := withRepoFacts(messages)
+	if !strings.HasSuffix(got[0].Content, "Repository context: test") || messages[0].Content != SystemPrompt || got[1].Content != "hi" {
+		t.Fatalf("facts not appended to a copy: %+v", got)
+	}
+	t.Setenv("DIFFLEARN_REPO_CONTEXT", "false")
+	if got := withRepoFacts(messages); got[0].Content != SystemPrompt {
+		t.Fatal("DIFFLEARN_REPO_CONTEXT=false should leave the prompt alone")
+	}
+}
+
+func TestCreateNarrativePrompt(t *testing.T) {
+	prompt := CreateNarrativePrompt(git.NewDiffFormatter(), []git.ParsedDiff{sampleDiff()})
+	for _, want := range []string{"main.go", "+new()", "first person", "## Step N", "## Looking back"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("narrative prompt lacks %q", want)
+		}
+	}
+}
+
+func TestExercisePrompts(t *testing.T) {
+	f := git.NewDiffFormatter()
+	diffs := []git.ParsedDiff{sampleDiff()}
+	prompt := CreateExerciseRequirementsPrompt(f, "Add new\n", diffs)
+	for _, want := range []string{"Add new", "+new()", "## Requirements", "Do not quote"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("requirements prompt lacks %q", want)
+		}
+	}
+	prompt = CreateExerciseFeedbackPrompt(f, "Add new", diffs, diffs)
+	for _, want := range []string{"## The real commit", "in the real commit but not in the attempt", "main.go"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("feedback prompt lacks %q", want)
+		}
+	}
+}
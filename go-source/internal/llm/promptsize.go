@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"sort"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+)
+
+// FileTokens is one file's share of a prompt.
+type FileTokens struct {
+	File      string `json:"file"`
+	Tokens    int    `json:"tokens"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// ProviderFit says whether a prompt fits a provider's context window in a
+// single request together with the reserved response tokens.
+type ProviderFit struct {
+	Provider      config.LLMProvider `json:"provider"`
+	Model         string             `json:"model"`
+	ContextWindow int                `json:"contextWindow"`
+	Fits          bool               `json:"fits"`
+	Active        bool               `json:"active"`
+}
+
+// PromptSize estimates what a request would cost before it is sent.
+// PromptTokens covers the system and user prompts; Budget is how DiffLearn
+// would split or trim the diff under the configured DIFFLEARN_CONTEXT_TOKENS.
+type PromptSize struct {
+	PromptTokens   int           `json:"promptTokens"`
+	DiffTokens     int           `json:"diffTokens"`
+	ResponseTokens int           `json:"responseTokens"`
+	Files          []FileTokens  `json:"files"`
+	Budget         BudgetReport  `json:"budget"`
+	Providers      []ProviderFit `json:"providers"`
+}
+
+// MeasurePrompt estimates the prompt build makes from diffs, file by file
+// (largest first), and checks it against every known provider.
+func MeasurePrompt(cfg config.Config, formatter *git.DiffFormatter, diffs []git.ParsedDiff, build func([]git.ParsedDiff) string) PromptSize {
+	size := PromptSize{
+		PromptTokens:   EstimateTokens(SystemPrompt) + EstimateTokens(build(diffs)),
+		DiffTokens:     EstimateDiffTokens(formatter, diffs),
+		ResponseTokens: cfg.MaxTokens,
+		Files:          make([]FileTokens, 0, len(diffs)),
+	}
+	overhead := EstimateDiffTokens(formatter, nil)
+	for _, d := range diffs {
+		name := d.NewFile
+		if d.IsDeleted {
+			name = d.OldFile
+		}
+		size.Files = append(size.Files, FileTokens{File: name, Tokens: EstimateDiffTokens(formatter, []git.ParsedDiff{d}) - overhead, Additions: d.Additions, Deletions: d.Deletions})
+	}
+	sort.SliceStable(size.Files, func(i, j int) bool { return size.Files[i].Tokens > size.Files[j].Tokens })
+	_, size.Budget = NewTokenBudget(cfg.ContextTokens, cfg.MaxTokens).Chunk(formatter, diffs)
+
+	for _, p := range config.KnownProviders() {
+		fit := ProviderFit{Provider: p, Model: config.DefaultModel(p), ContextWindow: config.ContextWindow(p), Active: p == cfg.Provider}
+		if fit.Active {
+			fit.Model = cfg.Model
+		}
+		// The window of a model behind an OpenAI-compatible endpoint is
+		// unknown; the configured context is the best guess when it is
+		// active, and otherwise there is nothing to compare against.
+		if fit.ContextWindow == 0 {
+			if !fit.Active {
+				continue
+			}
+			fit.ContextWindow = cfg.ContextTokens
+		}
+		fit.Fits = size.PromptTokens+size.ResponseTokens <= fit.ContextWindow
+		size.Providers = append(size.Providers, fit)
+	}
+	return size
+}
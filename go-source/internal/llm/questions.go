@@ -0,0 +1,24 @@
+package llm
+
+import (
+	"fmt"
+
+	"difflearn-go/internal/git"
+)
+
+// CreateDiscussionPrompt asks for open-ended questions about a change rather
+// than answers, for mentors running code-reading sessions. subject names the
+// commit or branch and commits gives the messages behind it.
+func CreateDiscussionPrompt(formatter *git.DiffFormatter, subject string, commits []git.CommitInfo, diffs []git.ParsedDiff, count int) string {
+	history := ""
+	if len(commits) > 0 {
+		history = "Commits:\n\n" + commitLog(commits) + "\n"
+	}
+	return fmt.Sprintf(`A mentor will walk a group of developers through %s in a code-reading session. Write about %d open-ended discussion questions about it. Do not answer them.
+
+%sChanges:
+
+%s
+
+Group the questions under these Markdown headings, skipping any that do not apply: "## Design trade-offs", "## Alternative approaches", "## Risks and edge cases", "## Testing and maintenance". Number the questions. Each question must point at specific code (file, function or hunk) and have no single right answer. Under each question add an italic line starting with "Listen for:" giving the mentor the points a good discussion should reach. Start with a one-sentence summary of the change and output nothing after the last question.`, subject, count, history, formatter.ToMarkdown(diffs))
+}
@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// QuizQuestion is one question of a quiz. Multiple-choice questions have
+// Choices and the index of the right one in Correct; short-answer questions
+// have a model Answer the learner's reply is graded against.
+type QuizQuestion struct {
+	Type        string   `json:"type"`
+	Question    string   `json:"question"`
+	Choices     []string `json:"choices,omitempty"`
+	Correct     int      `json:"correct,omitempty"`
+	Answer      string   `json:"answer,omitempty"`
+	Explanation string   `json:"explanation"`
+}
+
+const (
+	QuizChoice = "choice"
+	QuizShort  = "short"
+)
+
+// QuizGrade is the verdict on a short answer.
+type QuizGrade struct {
+	Correct  bool   `json:"correct"`
+	Feedback string `json:"feedback"`
+}
+
+// CreateQuizPrompt asks for count questions about the diff as JSON, mostly
+// multiple choice with a few short-answer questions.
+func CreateQuizPrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff, count int) string {
+	return fmt.Sprintf(`Write a quiz of %d questions that checks whether a developer understood the following code changes: what they do, why, and what they affect. Ask about behavior and reasoning rather than trivia such as line numbers. Make about two thirds of the questions multiple choice with four plausible options, and the rest short answer.
+
+%s
+
+Reply with a single JSON object and nothing else, in exactly this shape:
+
+{"questions": [{"type": "choice", "question": "<question>", "choices": ["<option>", "<option>", "<option>", "<option>"], "correct": <index of the right option, from 0>, "explanation": "<why it is right>"}, {"type": "short", "question": "<question>", "answer": "<a model answer in one or two sentences>", "explanation": "<what a good answer must mention>"}]}`, count, formatter.ToMarkdown(diffs))
+}
+
+// ParseQuiz extracts the questions from a quiz answer, dropping the ones that
+// cannot be asked: no question text, or a multiple-choice question without
+// two options and a valid correct index.
+func ParseQuiz(content string) ([]QuizQuestion, error) {
+	var result struct {
+		Questions []QuizQuestion `json:"questions"`
+	}
+	if err := unmarshalJSONAnswer(content, &result); err != nil {
+		return nil, fmt.Errorf("quiz: %w", err)
+	}
+	questions := make([]QuizQuestion, 0, len(result.Questions))
+	for _, q := range result.Questions {
+		if strings.TrimSpace(q.Question) == "" {
+			continue
+		}
+		if len(q.Choices) > 0 {
+			if len(q.Choices) < 2 || q.Correct < 0 || q.Correct >= len(q.Choices) {
+				continue
+			}
+			q.Type = QuizChoice
+		} else {
+			q.Type = QuizShort
+		}
+		questions = append(questions, q)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("quiz: no usable questions in response")
+	}
+	return questions, nil
+}
+
+// GenerateQuiz asks for a quiz on diffs, trimmed to fit the budget since the
+// answer must be a single JSON object.
+func GenerateQuiz(client Chatter, formatter *git.DiffFormatter, diffs []git.ParsedDiff, budget TokenBudget, count int) ([]QuizQuestion, BudgetReport, error) {
+	fitted, report := budget.Fit(formatter, diffs)
+	resp, err := client.Chat([]ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: CreateQuizPrompt(formatter, fitted, count)}})
+	if err != nil {
+		return nil, report, err
+	}
+	questions, err := ParseQuiz(resp.Content)
+	return questions, report, err
+}
+
+// CreateGradePrompt asks whether answer is a correct reply to a short-answer
+// question.
+func CreateGradePrompt(q QuizQuestion, answer string) string {
+	return fmt.Sprintf(`Grade a learner's answer to a quiz question about a code change. Accept answers that get the substance right even when worded differently or incomplete in minor details.
+
+Question: %s
+Model answer: %s
+What a good answer must mention: %s
+Learner's answer: %s
+
+Reply with a single JSON object and nothing else: {"correct": true | false, "feedback": "<one or two sentences for the learner>"}`, q.Question, q.Answer, q.Explanation, answer)
+}
+
+// GradeShortAnswer asks the model to grade a short answer. An empty answer
+// is wrong without asking.
+func GradeShortAnswer(client Chatter, q QuizQuestion, answer string) (QuizGrade, error) {
+	if strings.TrimSpace(answer) == "" {
+		return QuizGrade{Feedback: q.Answer}, nil
+	}
+	resp, err := client.Chat([]ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: CreateGradePrompt(q, answer)}})
+	if err != nil {
+		return QuizGrade{}, err
+	}
+	var grade QuizGrade
+	if err := unmarshalJSONAnswer(resp.Content, &grade); err != nil {
+		return QuizGrade{}, fmt.Errorf("grade: %w", err)
+	}
+	return grade, nil
+}
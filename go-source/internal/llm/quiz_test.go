@@ -0,0 +1,44 @@
+package llm
+
+import "testing"
+
+func TestParseQuiz(t *testing.T) {
+	content := "Here is the quiz:\n```json\n" + `{"questions": [
+		{"type": "choice", "question": "What does the change add?", "choices": ["A cache", "A retry", "A log line", "Nothing"], "correct": 1, "explanation": "It retries."},
+		{"type": "short", "question": "Why retry?", "answer": "Transient failures.", "explanation": "Mention transient errors."},
+		{"type": "choice", "question": "Broken", "choices": ["only one"], "correct": 0},
+		{"type": "choice", "question": "Out of range", "choices": ["a", "b"], "correct": 2},
+		{"type": "short", "question": " "}
+	]}` + "\n```"
+	questions, err := ParseQuiz(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(questions) != 2 {
+		t.Fatalf("expected 2 usable questions, got %+v", questions)
+	}
+	if questions[0].Type != QuizChoice || questions[0].Correct != 1 || questions[1].Type != QuizShort || questions[1].Answer != "Transient failures." {
+		t.Fatalf("unexpected questions: %+v", questions)
+	}
+	if _, err := ParseQuiz(`{"questions": []}`); err == nil {
+		t.Fatal("expected an error for a quiz without questions")
+	}
+}
+
+type gradeChatter struct{ reply string }
+
+func (g gradeChatter) Chat(messages []ChatMessage) (LLMResponse, error) {
+	return LLMResponse{Content: g.reply}, nil
+}
+
+func TestGradeShortAnswer(t *testing.T) {
+	q := QuizQuestion{Type: QuizShort, Question: "Why?", Answer: "Because."}
+	grade, err := GradeShortAnswer(gradeChatter{`{"correct": true, "feedback": "Right."}`}, q, "because")
+	if err != nil || !grade.Correct || grade.Feedback != "Right." {
+		t.Fatalf("GradeShortAnswer = %+v, %v", grade, err)
+	}
+	grade, err = GradeShortAnswer(gradeChatter{"unused"}, q, "  ")
+	if err != nil || grade.Correct {
+		t.Fatalf("empty answer should be wrong without asking: %+v, %v", grade, err)
+	}
+}
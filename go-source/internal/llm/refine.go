@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"difflearn-go/internal/git"
+)
+
+type RefinedReview struct {
+	Draft  string           `json:"draft"`
+	Final  string           `json:"final"`
+	Budget BudgetReport     `json:"budget"`
+	Usage  []map[string]any `json:"usage,omitempty"`
+}
+
+// ReviewWithRefinement runs the review prompt and then a critique pass that
+// checks the draft's claims against the diff and returns the corrected review.
+func ReviewWithRefinement(client Chatter, formatter *git.DiffFormatter, diffs []git.ParsedDiff, budget TokenBudget, rc ReviewContext) (RefinedReview, error) {
+	build := func(d []git.ParsedDiff) string { return CreateReviewPromptWithContext(formatter, d, rc) }
+	draft, report, err := RunBudgeted(client, formatter, diffs, budget, build)
+	if err != nil {
+		return RefinedReview{}, err
+	}
+
+	// The critique needs the diff and the draft in one prompt, so leave room for the draft.
+	critiqueBudget := budget
+	critiqueBudget.ReserveTokens += EstimateTokens(draft.Content)
+	fitted, _ := critiqueBudget.Fit(formatter, diffs)
+
+	final, err := client.Chat([]ChatMessage{{Role: "system", Content: SystemPrompt}, {Role: "user", Content: CreateCritiquePrompt(formatter, fitted, draft.Content, rc.Rubric)}})
+	if err != nil {
+		return RefinedReview{}, err
+	}
+	return RefinedReview{Draft: draft.Content, Final: final.Content, Budget: report, Usage: []map[string]any{draft.Usage, final.Usage}}, nil
+}
@@ -0,0 +1,27 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+func TestReviewWithRefinementCritiquesDraft(t *testing.T) {
+	f := git.NewDiffFormatter()
+	chat := &fakeChatter{}
+
+	result, err := ReviewWithRefinement(chat, f, []git.ParsedDiff{sampleDiff()}, NewTokenBudget(0, 1000), ReviewContext{})
+	if err != nil {
+		t.Fatalf("ReviewWithRefinement() error = %v", err)
+	}
+	if len(chat.prompts) != 2 {
+		t.Fatalf("expected draft and critique calls, got %d", len(chat.prompts))
+	}
+	if !strings.Contains(chat.prompts[1], "## Draft review") || !strings.Contains(chat.prompts[1], "answer 1") {
+		t.Fatalf("critique prompt missing draft: %s", chat.prompts[1])
+	}
+	if result.Draft != "answer 1" || result.Final != "answer 2" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,30 @@
+package llm
+
+import (
+	"fmt"
+
+	"difflearn-go/internal/git"
+)
+
+// CreateReleaseSummaryPrompt asks for release notes covering everything
+// between two releases.
+func CreateReleaseSummaryPrompt(formatter *git.DiffFormatter, from, to string, commits []git.CommitInfo, diffs []git.ParsedDiff) string {
+	return fmt.Sprintf(`Summarize what changed between the releases %s and %s. The commits in between:
+
+%s
+Combined changes:
+
+%s
+
+Write release notes in Markdown: a one-paragraph overview, then sections for new features, fixes and breaking or behavior changes (leave out empty sections), and finally anything users must do to upgrade, such as new settings or migrations. Describe changes by their effect rather than by commit.`, from, to, commitLog(commits), formatter.ToMarkdown(diffs))
+}
+
+// CreateChangelogEntryPrompt asks for a short changelog description of one
+// notable commit.
+func CreateChangelogEntryPrompt(formatter *git.DiffFormatter, commit git.CommitInfo, diffs []git.ParsedDiff) string {
+	return fmt.Sprintf(`The commit "%s" made these changes:
+
+%s
+
+Describe in one or two sentences what this change means for users of the project, for a CHANGELOG.md entry. Mention new options, changed defaults or required actions if there are any. Output only the description, without the commit subject, hash or a bullet.`, commit.Message, formatter.ToMarkdown(diffs))
+}
@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+)
+
+var repoFacts struct {
+	sync.Mutex
+	path   string
+	loaded bool
+	text   string
+}
+
+// UseRepository makes the system prompt of later requests describe the
+// repository at path and picks up its prompt templates. The repository is
+// profiled on the first request, so commands that never reach the model pay
+// nothing.
+func UseRepository(path string) {
+	repoFacts.Lock()
+	if path != repoFacts.path {
+		repoFacts.path, repoFacts.loaded, repoFacts.text = path, false, ""
+	}
+	repoFacts.Unlock()
+	applySystemTemplate(path)
+}
+
+// currentRepoPath is the repository set by UseRepository.
+func currentRepoPath() string {
+	repoFacts.Lock()
+	defer repoFacts.Unlock()
+	return repoFacts.path
+}
+
+// CreateRepoFactsPrompt turns a profile into a system prompt section.
+func CreateRepoFactsPrompt(p git.RepoProfile) string {
+	var facts []string
+	if p.Name != "" {
+		facts = append(facts, "- Project: "+p.Name)
+	}
+	if p.Branch != "" {
+		facts = append(facts, "- Current branch: "+p.Branch)
+	}
+	if len(p.Languages) > 0 {
+		facts = append(facts, "- Primary languages: "+strings.Join(p.Languages, ", "))
+	}
+	if len(p.Frameworks) > 0 {
+		facts = append(facts, fmt.Sprintf("- Frameworks and libraries (from %s): %s", strings.Join(p.Manifests, ", "), strings.Join(p.Frameworks, ", ")))
+	}
+	if len(facts) == 0 {
+		return ""
+	}
+	return "Repository context:\n" + strings.Join(facts, "\n") + "\n\nUse the terminology and idioms of this stack."
+}
+
+// currentRepoFacts profiles the repository set by UseRepository once and
+// returns its facts, or "" when there is none or DIFFLEARN_REPO_CONTEXT is
+// off.
+func currentRepoFacts() string {
+	repoFacts.Lock()
+	defer repoFacts.Unlock()
+	if repoFacts.path == "" || !config.RepoContext() {
+		return ""
+	}
+	if !repoFacts.loaded {
+		repoFacts.loaded = true
+		if p, err := git.NewGitExtractor(repoFacts.path).GetRepoProfile(); err == nil {
+			repoFacts.text = CreateRepoFactsPrompt(p)
+		}
+	}
+	return repoFacts.text
+}
+
+// withRepoFacts appends the repository facts to a leading system message.
+func withRepoFacts(messages []ChatMessage) []ChatMessage {
+	if len(messages) == 0 || messages[0].Role != "system" {
+		return messages
+	}
+	facts := currentRepoFacts()
+	if facts == "" {
+		return messages
+	}
+	return append([]ChatMessage{{Role: "system", Content: messages[0].Content + "\n\n" + facts}}, messages[1:]...)
+}
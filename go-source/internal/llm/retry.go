@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+// OnRetry, when set, is told before each retry of an API request: the
+// number of the retry, how long it waits first and why.
+var OnRetry func(cfg config.Config, retry int, wait time.Duration, err error)
+
+var (
+	// retryBaseDelay is the wait before the first retry; it doubles with
+	// each further one.
+	retryBaseDelay = time.Second
+	maxRetryDelay  = time.Minute
+)
+
+// do sends req and returns the response body. Rate limits (429) and server
+// errors (5xx) are retried up to config.Retries times, waiting as long as
+// the Retry-After header asks or else with exponential backoff. Any other
+// failure, or the last one, is an error holding the response body.
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	retries := config.Retries()
+	for retry := 0; ; retry++ {
+		attempt := req
+		if retry > 0 && req.GetBody != nil {
+			attempt = req.Clone(req.Context())
+			attempt.Body, _ = req.GetBody()
+		}
+		resp, err := c.httpClient.Do(attempt)
+		if err != nil {
+			return nil, err
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return body, nil
+		}
+		err = errors.New(string(body))
+		if len(body) == 0 {
+			err = errors.New(resp.Status)
+		}
+		if !retryable(resp.StatusCode) || retry >= retries {
+			return nil, err
+		}
+		wait := retryDelay(retry, resp.Header.Get("Retry-After"), time.Now())
+		if OnRetry != nil {
+			OnRetry(c.cfg, retry+1, wait, fmt.Errorf("%s: %w", resp.Status, err))
+		}
+		time.Sleep(wait)
+	}
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryDelay is the wait before retry number retry+1: what a Retry-After
+// header of seconds or an HTTP date asks for, else retryBaseDelay doubled
+// per retry, at most maxRetryDelay.
+func retryDelay(retry int, retryAfter string, now time.Time) time.Duration {
+	wait := maxRetryDelay
+	if retry < 16 {
+		wait = retryBaseDelay << retry
+	}
+	if s, err := strconv.Atoi(retryAfter); err == nil && s >= 0 {
+		wait = time.Duration(s) * time.Second
+	} else if t, err := http.ParseTime(retryAfter); err == nil {
+		wait = max(t.Sub(now), 0)
+	}
+	return min(wait, maxRetryDelay)
+}
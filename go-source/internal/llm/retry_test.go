@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+func TestChatRetriesRateLimits(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("DIFFLEARN_RETRIES", "2")
+	defer func(d time.Duration) { retryBaseDelay = d }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	var calls int
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch calls {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		case 2:
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"}}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	OnRetry = func(_ config.Config, retry int, wait time.Duration, err error) { waits = append(waits, wait) }
+	defer func() { OnRetry = nil }()
+
+	cfg := config.Config{Provider: config.ProviderOpenAICompatible, BaseURL: srv.URL, Model: "m"}
+	resp, err := NewClient(cfg).chat([]ChatMessage{{Role: "user", Content: "hello"}})
+	if err != nil || resp.Content != "hi" {
+		t.Fatalf("chat = %+v, %v", resp, err)
+	}
+	if calls != 3 || bodies[2] != bodies[0] || len(bodies[0]) == 0 {
+		t.Fatalf("%d calls with bodies %q", calls, bodies)
+	}
+	if len(waits) != 2 || waits[0] != 0 || waits[1] != 2*time.Millisecond {
+		t.Fatalf("waits = %v", waits)
+	}
+
+	calls, waits = 0, nil
+	t.Setenv("DIFFLEARN_RETRIES", "0")
+	if _, err := NewClient(cfg).chat([]ChatMessage{{Role: "user", Content: "hello"}}); err == nil || calls != 1 || len(waits) != 0 {
+		t.Fatalf("with retries off: %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		retry      int
+		retryAfter string
+		want       time.Duration
+	}{
+		{0, "", time.Second},
+		{3, "", 8 * time.Second},
+		{30, "", time.Minute},
+		{0, "7", 7 * time.Second},
+		{0, "3600", time.Minute},
+		{0, now.Add(20 * time.Second).Format(http.TimeFormat), 20 * time.Second},
+		{2, "soon", 4 * time.Second},
+	}
+	for _, c := range cases {
+		if got := retryDelay(c.retry, c.retryAfter, now); got != c.want {
+			t.Errorf("retryDelay(%d, %q) = %v, want %v", c.retry, c.retryAfter, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/config"
+)
+
+type CriterionResult struct {
+	Name    string  `json:"name"`
+	Weight  float64 `json:"weight"`
+	Gate    bool    `json:"gate,omitempty"`
+	Score   float64 `json:"score"`
+	Verdict string  `json:"verdict"`
+	Notes   string  `json:"notes"`
+	// Found is false when the model's answer had no section for the criterion.
+	Found bool `json:"found"`
+}
+
+const rubricMaxScore = 5
+
+var (
+	rubricHeadingRe = regexp.MustCompile(`^#{2,4}\s+(.+?)\s*$`)
+	rubricScoreRe   = regexp.MustCompile(`(?i)^\W*score\W*:?\W*(\d+(?:\.\d+)?)`)
+	rubricVerdictRe = regexp.MustCompile(`(?i)^\W*verdict\W*:?\W*(pass|fail)`)
+	headingSuffixRe = regexp.MustCompile(`\s*\(weight[^)]*\)\s*$`)
+)
+
+func rubricInstructions(rubric []config.RubricCriterion) string {
+	var sb strings.Builder
+	sb.WriteString("Organize the review by these criteria (weight in parentheses):\n\n")
+	for _, c := range rubric {
+		line := fmt.Sprintf("- **%s** (weight %g)", c.Name, c.Weight)
+		if c.Description != "" {
+			line += ": " + c.Description
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nFor every criterion output a section in exactly this form:\n\n### <criterion name>\nScore: <1-%d>\nVerdict: pass | fail\n<findings for this criterion, each tagged critical, important or minor>\n\nEnd with a short `### Overall` section.", rubricMaxScore))
+	return sb.String()
+}
+
+// ParseRubricReview extracts per-criterion scores and verdicts from a review
+// written with the rubric format.
+func ParseRubricReview(content string, rubric []config.RubricCriterion) []CriterionResult {
+	results := make([]CriterionResult, len(rubric))
+	index := map[string]int{}
+	for i, c := range rubric {
+		results[i] = CriterionResult{Name: c.Name, Weight: c.Weight, Gate: c.Gate}
+		index[normalizeHeading(c.Name)] = i
+	}
+
+	current := -1
+	notes := make([][]string, len(rubric))
+	for _, line := range strings.Split(content, "\n") {
+		if m := rubricHeadingRe.FindStringSubmatch(line); m != nil {
+			current = -1
+			if i, ok := index[normalizeHeading(m[1])]; ok {
+				current = i
+				results[i].Found = true
+			}
+			continue
+		}
+		if current < 0 {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if m := rubricScoreRe.FindStringSubmatch(trimmed); m != nil {
+			score, _ := strconv.ParseFloat(m[1], 64)
+			results[current].Score = score
+			continue
+		}
+		if m := rubricVerdictRe.FindStringSubmatch(trimmed); m != nil {
+			results[current].Verdict = strings.ToLower(m[1])
+			continue
+		}
+		notes[current] = append(notes[current], line)
+	}
+	for i := range results {
+		results[i].Notes = strings.TrimSpace(strings.Join(notes[i], "\n"))
+	}
+	return results
+}
+
+func normalizeHeading(s string) string {
+	s = headingSuffixRe.ReplaceAllString(strings.TrimSpace(s), "")
+	return strings.ToLower(strings.TrimSpace(strings.Trim(s, "*_`: ")))
+}
+
+// WeightedScore is the weight-averaged score of the criteria the review covered.
+func WeightedScore(results []CriterionResult) float64 {
+	total, weights := 0.0, 0.0
+	for _, r := range results {
+		if !r.Found {
+			continue
+		}
+		total += r.Score * r.Weight
+		weights += r.Weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return total / weights
+}
+
+// FailedGates returns the gating criteria whose verdict was fail.
+func FailedGates(results []CriterionResult) []CriterionResult {
+	failed := make([]CriterionResult, 0)
+	for _, r := range results {
+		if r.Gate && r.Verdict == "fail" {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// FormatRubricScorecard renders results as a short Markdown table.
+func FormatRubricScorecard(results []CriterionResult) string {
+	var sb strings.Builder
+	sb.WriteString("| Criterion | Weight | Score | Verdict |\n|---|---|---|---|\n")
+	for _, r := range results {
+		score, verdict := "-", "missing"
+		if r.Found {
+			score = fmt.Sprintf("%g/%d", r.Score, rubricMaxScore)
+			verdict = defaultString(r.Verdict, "-")
+		}
+		name := r.Name
+		if r.Gate {
+			name += " (gate)"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %g | %s | %s |\n", name, r.Weight, score, verdict))
+	}
+	sb.WriteString(fmt.Sprintf("\nWeighted score: %.1f/%d", WeightedScore(results), rubricMaxScore))
+	return sb.String()
+}
+
+func defaultString(v, d string) string {
+	if v == "" {
+		return d
+	}
+	return v
+}
+
+// FailedStructuredGates returns the names of gating criteria that a
+// structured review tagged with a critical issue. Structured answers carry
+// no per-criterion verdicts, so a critical issue stands in for a fail.
+func FailedStructuredGates(result ReviewResult, rubric []config.RubricCriterion) []string {
+	failed := make([]string, 0)
+	for _, c := range rubric {
+		if !c.Gate {
+			continue
+		}
+		for _, issue := range result.Issues {
+			if issue.Severity == analysis.SeverityCritical && normalizeHeading(issue.Criterion) == normalizeHeading(c.Name) {
+				failed = append(failed, c.Name)
+				break
+			}
+		}
+	}
+	return failed
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+)
+
+func testRubric() []config.RubricCriterion {
+	return []config.RubricCriterion{
+		{Name: "Correctness", Description: "Logic errors", Weight: 3, Gate: true},
+		{Name: "Readability", Weight: 1},
+		{Name: "Tests", Weight: 1},
+	}
+}
+
+func TestCreateReviewPromptWithRubric(t *testing.T) {
+	prompt := CreateReviewPromptWithContext(git.NewDiffFormatter(), []git.ParsedDiff{sampleDiff()}, ReviewContext{Rubric: testRubric()})
+	for _, want := range []string{"**Correctness** (weight 3): Logic errors", "Verdict: pass | fail", "### Overall"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "organized by severity") {
+		t.Fatalf("rubric prompt should replace the severity layout")
+	}
+}
+
+func TestParseRubricReview(t *testing.T) {
+	content := `### **Correctness** (weight 3)
+**Score:** 2/5
+Verdict: FAIL
+- critical: nil map write in handler
+
+### Readability
+Score: 4
+Verdict: pass
+Clear naming.
+
+### Overall
+Needs a fix before merge.`
+
+	results := ParseRubricReview(content, testRubric())
+	if !results[0].Found || results[0].Score != 2 || results[0].Verdict != "fail" || !strings.Contains(results[0].Notes, "nil map") {
+		t.Fatalf("unexpected correctness result: %+v", results[0])
+	}
+	if results[1].Score != 4 || results[1].Verdict != "pass" || results[1].Notes != "Clear naming." {
+		t.Fatalf("unexpected readability result: %+v", results[1])
+	}
+	if results[2].Found {
+		t.Fatalf("tests criterion should be missing: %+v", results[2])
+	}
+	if got := WeightedScore(results); got != 2.5 {
+		t.Fatalf("expected weighted score 2.5, got %v", got)
+	}
+	if failed := FailedGates(results); len(failed) != 1 || failed[0].Name != "Correctness" {
+		t.Fatalf("expected correctness gate to fail, got %+v", failed)
+	}
+}
+
+func TestFailedStructuredGates(t *testing.T) {
+	result := ReviewResult{Issues: []ReviewIssue{
+		{Severity: analysis.SeverityCritical, Criterion: "correctness"},
+		{Severity: analysis.SeverityCritical, Criterion: "Readability"},
+		{Severity: analysis.SeverityImportant, Criterion: "Tests"},
+	}}
+	rubric := testRubric()
+	rubric[2].Gate = true
+	failed := FailedStructuredGates(result, rubric)
+	if len(failed) != 1 || failed[0] != "Correctness" {
+		t.Fatalf("FailedStructuredGates = %v", failed)
+	}
+}
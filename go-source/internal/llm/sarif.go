@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"encoding/json"
+
+	"difflearn-go/internal/analysis"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// ToSARIF renders static pre-check findings and structured review issues as
+// a SARIF 2.1.0 log for code scanning tools. result may be nil when only the
+// pre-checks ran. Review issues use the rule id "ai-review", or
+// "ai-review/<criterion>" when tagged with a rubric criterion.
+func ToSARIF(findings []analysis.Finding, result *ReviewResult, toolVersion string) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "DiffLearn",
+			InformationURI: "https://github.com/lertsoft/DiffLearn",
+			Version:        toolVersion,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	seen := map[string]bool{}
+	addRule := func(id, description string) {
+		if !seen[id] {
+			seen[id] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}})
+		}
+	}
+
+	for _, f := range findings {
+		addRule(f.Rule, "Static pre-check: "+f.Rule)
+		run.Results = append(run.Results, sarifResult{
+			RuleID:     f.Rule,
+			Level:      sarifLevel(f.Severity),
+			Message:    sarifMessage{Text: f.Message},
+			Locations:  sarifLocations(f.File, f.Line),
+			Properties: map[string]string{"severity": string(f.Severity), "source": f.Source},
+		})
+	}
+	if result != nil {
+		for _, issue := range result.Issues {
+			id, description := "ai-review", "AI code review"
+			if issue.Criterion != "" {
+				id, description = "ai-review/"+issue.Criterion, "AI code review: "+issue.Criterion
+			}
+			addRule(id, description)
+			text := issue.Message
+			if issue.Suggestion != "" {
+				text += "\n\nSuggestion: " + issue.Suggestion
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:     id,
+				Level:      sarifLevel(issue.Severity),
+				Message:    sarifMessage{Text: text},
+				Locations:  sarifLocations(issue.File, issue.Line),
+				Properties: map[string]string{"severity": string(issue.Severity), "source": "ai"},
+			})
+		}
+	}
+	return json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}, "", "  ")
+}
+
+func sarifLevel(s analysis.Severity) string {
+	switch s {
+	case analysis.SeverityCritical:
+		return "error"
+	case analysis.SeverityImportant:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func sarifLocations(file string, line int) []sarifLocation {
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: file}}}
+	if line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+	}
+	return []sarifLocation{loc}
+}
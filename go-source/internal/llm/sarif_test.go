@@ -0,0 +1,34 @@
+package llm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"difflearn-go/internal/analysis"
+)
+
+func TestToSARIF(t *testing.T) {
+	findings := []analysis.Finding{{Rule: "secret", Severity: analysis.SeverityCritical, File: "a.go", Line: 4, Message: "possible secret", Source: "rule"}}
+	result := &ReviewResult{Issues: []ReviewIssue{
+		{File: "b.go", Severity: analysis.SeverityMinor, Message: "naming", Suggestion: "rename it", Criterion: "Readability"},
+	}}
+	b, err := ToSARIF(findings, result, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	if log.Version != "2.1.0" || len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 2 {
+		t.Fatalf("unexpected log: %s", b)
+	}
+	first, second := run.Results[0], run.Results[1]
+	if first.Level != "error" || first.Locations[0].PhysicalLocation.Region.StartLine != 4 {
+		t.Fatalf("unexpected finding result: %+v", first)
+	}
+	if second.RuleID != "ai-review/Readability" || second.Level != "note" || second.Locations[0].PhysicalLocation.Region != nil {
+		t.Fatalf("unexpected issue result: %+v", second)
+	}
+}
@@ -0,0 +1,31 @@
+package llm
+
+import (
+	"fmt"
+
+	"difflearn-go/internal/git"
+)
+
+// CreateStoryStepPrompt explains commit step (0-based) of commits, oldest
+// first, as one chapter of how subject was built.
+func CreateStoryStepPrompt(formatter *git.DiffFormatter, subject string, commits []git.CommitInfo, step int, diffs []git.ParsedDiff) string {
+	return fmt.Sprintf(`A developer is learning how %s was built by reading its commits in order, oldest first:
+
+%s
+Explain step %d of %d, "%s", whose changes are:
+
+%s
+
+Start with one sentence on what this step contributes to the whole, then walk through the important hunks: what the code did before, what it does now and why the author probably did it this way. Mention earlier steps this builds on and what it sets up for later ones where that is clear. Keep it under 300 words.`, subject, commitLog(commits), step+1, len(commits), commits[step].Message, formatter.ToMarkdown(diffs))
+}
+
+// CreateComprehensionPrompt asks for questions that check a reader
+// understood one commit, each followed by an "Answer:" line the reader can
+// reveal after thinking about it.
+func CreateComprehensionPrompt(formatter *git.DiffFormatter, commit git.CommitInfo, diffs []git.ParsedDiff, count int) string {
+	return fmt.Sprintf(`Write %d short questions that check whether a developer understood the commit "%s":
+
+%s
+
+Ask about behavior and reasoning (what happens now in a given case, why a line was needed, what would break without it), not trivia such as names or line numbers. Number the questions as a Markdown list and put the answer on the line after each question, starting with "Answer:". Output nothing else.`, count, commit.Message, formatter.ToMarkdown(diffs))
+}
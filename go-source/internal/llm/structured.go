@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/git"
+)
+
+// ReviewIssue is one finding of a structured review. Line refers to the new
+// side of the diff; 0 means the issue is about the file as a whole.
+type ReviewIssue struct {
+	File       string            `json:"file"`
+	Line       int               `json:"line,omitempty"`
+	Severity   analysis.Severity `json:"severity"`
+	Message    string            `json:"message"`
+	Suggestion string            `json:"suggestion,omitempty"`
+	Criterion  string            `json:"criterion,omitempty"`
+}
+
+// ReviewResult is the parsed answer to a structured review prompt.
+type ReviewResult struct {
+	Summary string        `json:"summary"`
+	Issues  []ReviewIssue `json:"issues"`
+}
+
+const structuredReviewFormat = `Reply with a single JSON object and nothing else, in exactly this shape:
+
+{"summary": "<one or two sentences>", "issues": [{"file": "<path as shown in the diff>", "line": <line number in the new file, or 0 for the whole file>, "severity": "critical" | "important" | "minor", "message": "<what is wrong>", "suggestion": "<how to fix it>"}]}
+
+Only report real problems; use an empty issues array when there are none.`
+
+// CreateStructuredReviewPrompt asks for the review as JSON so it can be
+// rendered as a table or attached to diff lines.
+func CreateStructuredReviewPrompt(formatter *git.DiffFormatter, diffs []git.ParsedDiff, rc ReviewContext) string {
+	diffMarkdown := formatter.ToMarkdown(diffs)
+	prompt := fmt.Sprintf("Please review the following code changes. Look for potential bugs, security concerns, performance issues and violations of best practices.\n\n%s\n\n%s", diffMarkdown, structuredReviewFormat)
+	if len(rc.Rubric) > 0 {
+		names := make([]string, 0, len(rc.Rubric))
+		for _, c := range rc.Rubric {
+			names = append(names, c.Name)
+		}
+		prompt += "\n\nTag every issue with a \"criterion\" field naming one of the team's review criteria: " + strings.Join(names, ", ") + "."
+	}
+	if len(rc.Findings) > 0 {
+		prompt += "\n\nAutomated static pre-checks reported the following. Include the ones that are real problems as issues and drop false positives:\n\n" + analysis.FormatFindings(rc.Findings)
+	}
+	if strings.TrimSpace(rc.Ticket) != "" {
+		prompt += "\n\nThe change is meant to address this issue:\n\n<ticket>\n" + rc.Ticket + "\n</ticket>\n\nIn the summary, say whether the diff addresses it. Report each requirement that is missing or only partly done as an issue with \"criterion\": \"ticket\", the most relevant file (or an empty file) and line 0."
+	}
+	return prompt
+}
+
+// ParseReviewResult extracts the JSON object from a structured review answer,
+// tolerating code fences and surrounding prose. Issues are sorted by
+// severity, then file and line.
+func ParseReviewResult(content string) (ReviewResult, error) {
+	var result ReviewResult
+	if err := unmarshalJSONAnswer(content, &result); err != nil {
+		return ReviewResult{}, fmt.Errorf("structured review: %w", err)
+	}
+	for i := range result.Issues {
+		result.Issues[i].Severity = normalizeSeverity(string(result.Issues[i].Severity))
+	}
+	sort.SliceStable(result.Issues, func(i, j int) bool {
+		a, b := result.Issues[i], result.Issues[j]
+		if a.Severity != b.Severity {
+			return analysis.SeverityAtLeast(a.Severity, b.Severity)
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	if result.Issues == nil {
+		result.Issues = []ReviewIssue{}
+	}
+	return result, nil
+}
+
+// unmarshalJSONAnswer decodes the outermost JSON object in an LLM answer,
+// ignoring code fences and any prose around it.
+func unmarshalJSONAnswer(content string, v any) error {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON object in response")
+	}
+	return json.Unmarshal([]byte(content[start:end+1]), v)
+}
+
+// normalizeSeverity maps the labels models commonly use onto the three
+// severities DiffLearn reports.
+func normalizeSeverity(s string) analysis.Severity {
+	if sev, ok := analysis.ParseSeverity(s); ok {
+		return sev
+	}
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "blocker", "high", "error":
+		return analysis.SeverityCritical
+	case "low", "info", "nit", "suggestion":
+		return analysis.SeverityMinor
+	default:
+		return analysis.SeverityImportant
+	}
+}
+
+// CountBySeverity returns how many issues have each severity.
+func (r ReviewResult) CountBySeverity() map[analysis.Severity]int {
+	counts := map[analysis.Severity]int{}
+	for _, issue := range r.Issues {
+		counts[issue.Severity]++
+	}
+	return counts
+}
+
+// FormatReviewMarkdown renders a structured review as a Markdown table for
+// surfaces that only show text.
+func FormatReviewMarkdown(r ReviewResult) string {
+	var sb strings.Builder
+	if r.Summary != "" {
+		sb.WriteString(r.Summary + "\n\n")
+	}
+	if len(r.Issues) == 0 {
+		sb.WriteString("No issues found.")
+		return sb.String()
+	}
+	sb.WriteString("| Severity | Location | Issue | Suggestion |\n|---|---|---|---|\n")
+	for _, issue := range r.Issues {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", issue.Severity, issue.Location(), escapeCell(issue.Message), escapeCell(issue.Suggestion)))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Location is file:line, or just the file for file-level issues.
+func (i ReviewIssue) Location() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return i.File
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/analysis"
+	"difflearn-go/internal/git"
+)
+
+func TestCreateStructuredReviewPrompt(t *testing.T) {
+	rc := ReviewContext{Rubric: testRubric(), Structured: true}
+	prompt := CreateReviewPromptWithContext(git.NewDiffFormatter(), []git.ParsedDiff{sampleDiff()}, rc)
+	for _, want := range []string{`"severity": "critical" | "important" | "minor"`, "main.go", `"criterion" field`, "Correctness, Readability, Tests"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestParseReviewResult(t *testing.T) {
+	content := "Here is the review:\n```json\n" + `{
+  "summary": "Adds a handler.",
+  "issues": [
+    {"file": "b.go", "line": 3, "severity": "nit", "message": "naming"},
+    {"file": "a.go", "line": 12, "severity": "High", "message": "nil map write", "suggestion": "initialize the map"},
+    {"file": "a.go", "severity": "important", "message": "missing tests"}
+  ]
+}` + "\n```"
+
+	result, err := ParseReviewResult(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Summary != "Adds a handler." || len(result.Issues) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	got := []string{}
+	for _, issue := range result.Issues {
+		got = append(got, string(issue.Severity)+" "+issue.Location())
+	}
+	if want := "critical a.go:12,important a.go,minor b.go:3"; strings.Join(got, ",") != want {
+		t.Fatalf("expected issues sorted as %s, got %s", want, strings.Join(got, ","))
+	}
+	if counts := result.CountBySeverity(); counts[analysis.SeverityCritical] != 1 || counts[analysis.SeverityMinor] != 1 {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+	if md := FormatReviewMarkdown(result); !strings.Contains(md, "| critical | a.go:12 | nil map write | initialize the map |") {
+		t.Fatalf("unexpected markdown:\n%s", md)
+	}
+
+	if _, err := ParseReviewResult("I could not review this."); err == nil {
+		t.Fatalf("expected an error for prose answers")
+	}
+}
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// CreateTeachingPrompt asks for comments that teach a reader the code, to be
+// embedded as NOTE comments next to the lines they explain.
+func CreateTeachingPrompt(diffs []git.ParsedDiff) string {
+	var sb strings.Builder
+	sb.WriteString("You are annotating the following code changes for a developer who is learning this codebase. Each line below is labeled with the number to cite. Write teaching comments that will be inserted as `NOTE:` comments directly above the lines they explain.\n\n")
+	writeNumberedHunks(&sb, diffs)
+	sb.WriteString(`Reply with a single JSON object and nothing else:
+
+{"annotations": [{"file": "<path as in the ### heading>", "side": "new", "line": <number of an added or unchanged line>, "message": "<the teaching comment>"}]}
+
+Write one to three comments per hunk. Explain why the code is written this way, the concepts, idioms or APIs it relies on, and how it connects to the rest of the change; do not restate what a line literally does. Keep each message to one sentence of plain text, without markdown.`)
+	return sb.String()
+}
+
+// TeachingNotes asks the model for teaching comments and validates them like
+// Annotate. Comments on removed lines are dropped because there is no code
+// left to place them above.
+func TeachingNotes(client Chatter, formatter *git.DiffFormatter, diffs []git.ParsedDiff, budget TokenBudget) (AnnotationResult, error) {
+	result, err := annotateWith(client, formatter, diffs, budget, CreateTeachingPrompt)
+	if err != nil {
+		return AnnotationResult{}, err
+	}
+	kept := result.Annotations[:0]
+	for _, a := range result.Annotations {
+		if a.Side == git.SideOld {
+			result.Dropped = append(result.Dropped, a)
+			continue
+		}
+		a.Severity = ""
+		kept = append(kept, a)
+	}
+	result.Annotations = kept
+	return result, nil
+}
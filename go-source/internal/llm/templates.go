@@ -0,0 +1,214 @@
+package llm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/git"
+)
+
+// PromptNames are the prompts a template file can replace.
+var PromptNames = []string{"system", "explain", "review", "summary", "question"}
+
+// builtinSystemPrompt is SystemPrompt before a template replaced it.
+var builtinSystemPrompt = SystemPrompt
+
+// PromptData is what prompt templates can use. Diff, Stats and the change
+// counts are empty in the system template, Question is only set for the
+// question template.
+type PromptData struct {
+	Diff          string
+	Stats         string
+	Files         int
+	Additions     int
+	Deletions     int
+	Question      string
+	Repo          string
+	Branch        string
+	DefaultBranch string
+	// Default is the built-in prompt, so a template can extend it.
+	Default string
+}
+
+// PromptTemplate is where a prompt comes from; Path is empty for built-in
+// prompts and Err reports a template that does not parse.
+type PromptTemplate struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Path   string `json:"path,omitempty"`
+	Err    error  `json:"-"`
+}
+
+// UserPromptDir holds the user's prompt templates, <name>.tmpl each.
+func UserPromptDir() string {
+	return filepath.Join(config.DataDir(), "prompts")
+}
+
+// RepoPromptDir holds the templates a repository ships for its team; they
+// win over the user's.
+func RepoPromptDir(repoPath string) string {
+	return filepath.Join(repoPath, ".difflearn", "prompts")
+}
+
+func templateFile(dir, name string) string {
+	return filepath.Join(dir, name+".tmpl")
+}
+
+// IsPromptName reports whether name is one of PromptNames.
+func IsPromptName(name string) bool {
+	for _, n := range PromptNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FindPromptTemplate returns the template used for name in repoPath.
+func FindPromptTemplate(repoPath, name string) PromptTemplate {
+	candidates := []struct{ source, dir string }{{"user", UserPromptDir()}}
+	if repoPath != "" {
+		candidates = append([]struct{ source, dir string }{{"repo", RepoPromptDir(repoPath)}}, candidates...)
+	}
+	for _, c := range candidates {
+		path := templateFile(c.dir, name)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		_, err = parsePromptTemplate(name, string(b))
+		return PromptTemplate{Name: name, Source: c.source, Path: path, Err: err}
+	}
+	return PromptTemplate{Name: name, Source: "built-in"}
+}
+
+// ListPromptTemplates returns the template in use for every prompt.
+func ListPromptTemplates(repoPath string) []PromptTemplate {
+	list := make([]PromptTemplate, 0, len(PromptNames))
+	for _, name := range PromptNames {
+		list = append(list, FindPromptTemplate(repoPath, name))
+	}
+	return list
+}
+
+func parsePromptTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// renderPromptTemplate executes the template for name, reporting false when
+// there is none or it fails, so the built-in prompt is used instead.
+func renderPromptTemplate(repoPath, name string, data PromptData) (string, bool) {
+	t := FindPromptTemplate(repoPath, name)
+	if t.Path == "" || t.Err != nil {
+		return "", false
+	}
+	b, err := os.ReadFile(t.Path)
+	if err != nil {
+		return "", false
+	}
+	tmpl, err := parsePromptTemplate(name, string(b))
+	if err != nil {
+		return "", false
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(out.String()), true
+}
+
+// templatedPrompt renders the user template for name over diffs, or returns
+// the built-in prompt.
+func templatedPrompt(name string, formatter *git.DiffFormatter, diffs []git.ParsedDiff, question, builtin string) string {
+	repoPath := currentRepoPath()
+	if FindPromptTemplate(repoPath, name).Path == "" {
+		return builtin
+	}
+	data := repoPromptData(repoPath)
+	data.Diff = formatter.ToMarkdown(diffs)
+	data.Stats = formatter.ToSummary(diffs)
+	data.Files = len(diffs)
+	for _, d := range diffs {
+		data.Additions += d.Additions
+		data.Deletions += d.Deletions
+	}
+	data.Question = question
+	data.Default = builtin
+	if out, ok := renderPromptTemplate(repoPath, name, data); ok {
+		return out
+	}
+	return builtin
+}
+
+func repoPromptData(repoPath string) PromptData {
+	if repoPath == "" {
+		return PromptData{}
+	}
+	g := git.NewGitExtractor(repoPath)
+	data := PromptData{Repo: filepath.Base(repoPath), DefaultBranch: g.DefaultBranch()}
+	if abs, err := filepath.Abs(repoPath); err == nil {
+		data.Repo = filepath.Base(abs)
+	}
+	data.Branch, _ = g.GetCurrentBranch()
+	return data
+}
+
+// applySystemTemplate points SystemPrompt at the system template of
+// repoPath, or back at the built-in one.
+func applySystemTemplate(repoPath string) {
+	SystemPrompt = builtinSystemPrompt
+	if FindPromptTemplate(repoPath, "system").Path == "" {
+		return
+	}
+	data := repoPromptData(repoPath)
+	data.Default = builtinSystemPrompt
+	if out, ok := renderPromptTemplate(repoPath, "system", data); ok {
+		SystemPrompt = out
+	}
+}
+
+// PromptTemplateSeed is the starting content `prompts edit` writes for a
+// new template: the built-in prompt spelled as a template.
+func PromptTemplateSeed(name string) (string, error) {
+	header := "{{/* Variables: .Diff .Stats .Files .Additions .Deletions .Question .Repo .Branch .DefaultBranch .Default (the built-in prompt) */}}\n"
+	switch name {
+	case "system":
+		return "{{/* Variables: .Repo .Branch .DefaultBranch .Default (the built-in prompt) */}}\n" + builtinSystemPrompt + "\n", nil
+	case "explain":
+		return header + "Please explain the following code changes. Describe what was changed, why it might have been changed, and any implications:\n\n{{.Diff}}\n\nProvide a clear, structured explanation that would help someone understand these changes quickly.\n", nil
+	case "review":
+		return header + "Please review the following code changes. Look for:\n- Potential bugs or errors\n- Security concerns\n- Performance issues\n- Code style and best practices\n- Suggestions for improvement\n\n{{.Diff}}\n\nProvide constructive feedback organized by severity (critical, important, minor).\n", nil
+	case "summary":
+		return header + "Please provide a brief summary of these changes in 2-3 sentences. Focus on the main purpose and impact:\n\n{{.Diff}}\n", nil
+	case "question":
+		return header + "Given the following code changes:\n\n{{.Diff}}\n\nUser question: {{.Question}}\n\nPlease answer the question based on the diff context provided.\n", nil
+	}
+	return "", fmt.Errorf("unknown prompt %q (want one of %s)", name, strings.Join(PromptNames, ", "))
+}
+
+// ErrPromptExists is returned by CreatePromptTemplate when the file is
+// already there.
+var ErrPromptExists = errors.New("prompt template already exists")
+
+// CreatePromptTemplate writes the seed of name into dir unless a template is
+// already there, and returns its path either way.
+func CreatePromptTemplate(dir, name string) (string, error) {
+	seed, err := PromptTemplateSeed(name)
+	if err != nil {
+		return "", err
+	}
+	path := templateFile(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return path, ErrPromptExists
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(seed), 0o644)
+}
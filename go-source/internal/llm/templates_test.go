@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+func writeTemplate(t *testing.T, dir, name, text string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".tmpl"), []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPromptTemplatesOverrideBuiltins(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	repo := t.TempDir()
+	UseRepository(repo)
+	t.Cleanup(func() { UseRepository("") })
+
+	f := git.NewDiffFormatter()
+	diffs := []git.ParsedDiff{sampleDiff()}
+	builtin := CreateSummaryPrompt(f, diffs)
+
+	writeTemplate(t, UserPromptDir(), "summary", "user: {{.Files}} file(s) +{{.Additions}} -{{.Deletions}}")
+	if got := CreateSummaryPrompt(f, diffs); got != "user: 1 file(s) +1 -1" {
+		t.Fatalf("user template not used: %q", got)
+	}
+	writeTemplate(t, RepoPromptDir(repo), "summary", "In {{.Repo}}: {{.Default}}")
+	if got := CreateSummaryPrompt(f, diffs); got != "In "+filepath.Base(repo)+": "+strings.TrimSpace(builtin) {
+		t.Fatalf("repository template should win and see the built-in prompt: %q", got)
+	}
+	writeTemplate(t, RepoPromptDir(repo), "question", "Q={{.Question}}\n{{.Diff}}")
+	if got := CreateQuestionPrompt(f, diffs, "why?"); !strings.HasPrefix(got, "Q=why?") || !strings.Contains(got, "main.go") {
+		t.Fatalf("question template not rendered: %q", got)
+	}
+
+	writeTemplate(t, RepoPromptDir(repo), "review", "{{.Nope}}")
+	if got := CreateReviewPrompt(f, diffs); !strings.Contains(got, "severity") {
+		t.Fatalf("a failing template should fall back to the built-in prompt: %q", got)
+	}
+	writeTemplate(t, RepoPromptDir(repo), "explain", "{{.Diff")
+	if tmpl := FindPromptTemplate(repo, "explain"); tmpl.Source != "repo" || tmpl.Err == nil {
+		t.Fatalf("expected a parse error for the repository template: %+v", tmpl)
+	}
+}
+
+func TestSystemPromptTemplate(t *testing.T) {
+	t.Setenv("DIFFLEARN_DATA_DIR", t.TempDir())
+	writeTemplate(t, UserPromptDir(), "system", "Custom. {{.Default}}")
+	UseRepository(t.TempDir())
+	if !strings.HasPrefix(SystemPrompt, "Custom. You are DiffLearn") {
+		t.Fatalf("system template not applied: %q", SystemPrompt)
+	}
+	os.RemoveAll(UserPromptDir())
+	UseRepository("")
+	if SystemPrompt != builtinSystemPrompt {
+		t.Fatal("the built-in system prompt should come back without a template")
+	}
+}
+
+func TestCreatePromptTemplate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range PromptNames {
+		path, err := CreatePromptTemplate(dir, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := os.ReadFile(path)
+		if _, err := parsePromptTemplate(name, string(b)); err != nil {
+			t.Fatalf("seed of %s does not parse: %v", name, err)
+		}
+		if _, err := CreatePromptTemplate(dir, name); err != ErrPromptExists {
+			t.Fatalf("expected ErrPromptExists, got %v", err)
+		}
+	}
+	if _, err := CreatePromptTemplate(dir, "nope"); err == nil {
+		t.Fatal("unknown prompt names must be rejected")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 
+	"difflearn-go/internal/analysis"
 	"difflearn-go/internal/config"
+	"difflearn-go/internal/discovery"
 	"difflearn-go/internal/git"
 	"difflearn-go/internal/llm"
 )
@@ -26,6 +28,7 @@ type rpcResp struct {
 }
 
 func Serve(repoPath string) error {
+	llm.UseRepository(repoPath)
 	g := git.NewGitExtractor(repoPath)
 	formatter := git.NewDiffFormatter()
 	s := bufio.NewScanner(os.Stdin)
@@ -38,7 +41,7 @@ func Serve(repoPath string) error {
 		resp := rpcResp{JSONRPC: "2.0", ID: req.ID}
 		switch req.Method {
 		case "tools/list":
-			resp.Result = map[string]any{"tools": []map[string]any{{"name": "get_local_diff", "description": "Get uncommitted changes"}, {"name": "get_commit_diff", "description": "Get diff for commit"}, {"name": "get_branch_diff", "description": "Get diff between branches"}, {"name": "get_commit_history", "description": "Get recent commits"}, {"name": "explain_diff", "description": "AI explanation"}, {"name": "review_diff", "description": "AI review"}, {"name": "ask_about_diff", "description": "Ask question"}}}
+			resp.Result = map[string]any{"tools": []map[string]any{{"name": "get_local_diff", "description": "Get uncommitted changes"}, {"name": "get_commit_diff", "description": "Get diff for commit"}, {"name": "get_branch_diff", "description": "Get diff between branches"}, {"name": "get_commit_history", "description": "Get recent commits"}, {"name": "explain_diff", "description": "AI explanation (level: beginner, intermediate or expert; language: e.g. German or de)"}, {"name": "review_diff", "description": "AI review (level: beginner, intermediate or expert; language: e.g. German or de)"}, {"name": "ask_about_diff", "description": "Ask question (level: beginner, intermediate or expert; language: e.g. German or de)"}, {"name": "list_providers", "description": "List allowed provider/model overrides"}, {"name": "get_web_ui", "description": "Find a running DiffLearn web server for this repository"}}}
 		case "tools/call":
 			var p struct {
 				Name      string                 `json:"name"`
@@ -91,9 +94,15 @@ func callTool(g *git.GitExtractor, formatter *git.DiffFormatter, name string, ar
 		return int(f)
 	}
 
+	whitespace := git.WhitespaceOptions{IgnoreWhitespace: sBool("ignoreWhitespace"), IgnoreSpaceChange: sBool("ignoreSpaceChange"), IgnoreBlankLines: sBool("ignoreBlankLines")}
+	algorithm, err := git.ParseDiffAlgorithm(sStr("algorithm"))
+	if err != nil {
+		return nil, err
+	}
+
 	switch name {
 	case "get_local_diff":
-		diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: sBool("staged")})
+		diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: sBool("staged"), Against: sStr("against"), IncludeUntracked: sBool("untracked"), Whitespace: whitespace, Algorithm: algorithm})
 		if err != nil {
 			return nil, err
 		}
@@ -102,7 +111,7 @@ func callTool(g *git.GitExtractor, formatter *git.DiffFormatter, name string, ar
 			return toText(formatter.ToJSON(diffs)), nil
 		}
 		if format == "raw" {
-			raw, err := g.GetRawDiff(map[bool]string{true: "staged", false: "local"}[sBool("staged")], nil)
+			raw, err := g.GetRawDiff(map[bool]string{true: "staged", false: "local"}[sBool("staged")], map[string]string{"against": sStr("against"), "untracked": fmt.Sprint(sBool("untracked")), "ignoreWhitespace": fmt.Sprint(whitespace.IgnoreWhitespace), "ignoreSpaceChange": fmt.Sprint(whitespace.IgnoreSpaceChange), "ignoreBlankLines": fmt.Sprint(whitespace.IgnoreBlankLines), "algorithm": string(algorithm)})
 			if err != nil {
 				return nil, err
 			}
@@ -110,13 +119,13 @@ func callTool(g *git.GitExtractor, formatter *git.DiffFormatter, name string, ar
 		}
 		return toText(formatter.ToMarkdown(diffs)), nil
 	case "get_commit_diff":
-		diffs, err := g.GetCommitDiff(sStr("commit1"), sStr("commit2"))
+		diffs, err := g.GetCommitDiffWithOptions(sStr("commit1"), sStr("commit2"), git.DiffOptions{Whitespace: whitespace, Algorithm: algorithm})
 		if err != nil {
 			return nil, err
 		}
 		return toText(formatter.ToMarkdown(diffs)), nil
 	case "get_branch_diff":
-		diffs, err := g.GetBranchDiff(sStr("branch1"), sStr("branch2"))
+		diffs, err := g.GetBranchDiffWithOptions(sStr("branch1"), sStr("branch2"), git.BranchModeTriple, git.DiffOptions{Whitespace: whitespace, Algorithm: algorithm})
 		if err != nil {
 			return nil, err
 		}
@@ -128,13 +137,38 @@ func callTool(g *git.GitExtractor, formatter *git.DiffFormatter, name string, ar
 		}
 		b, _ := json.MarshalIndent(commits, "", "  ")
 		return toText(string(b)), nil
+	case "list_providers":
+		b, _ := json.MarshalIndent(config.ListProviderOptions(config.LoadConfig()), "", "  ")
+		return toText(string(b)), nil
+	case "get_web_ui":
+		// Reuse a running server instead of starting another one.
+		server, ok := discovery.ForRepo(g.RepoPath())
+		if !ok {
+			return toText("No DiffLearn web server is running for this repository; start one with `difflearn web start`."), nil
+		}
+		b, _ := json.MarshalIndent(server, "", "  ")
+		out := toText(string(b))
+		out["structuredContent"] = server
+		return out, nil
 	case "explain_diff", "review_diff", "ask_about_diff":
-		cfg := config.LoadConfig()
-		diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: sBool("staged")})
+		cfg, err := config.WithOverrides(config.LoadConfig(), sStr("provider"), sStr("model"))
+		if err != nil {
+			return nil, err
+		}
+		level, err := llm.ParseLevel(sStr("level"))
+		if err != nil {
+			return nil, err
+		}
+		cfg.Level = string(level)
+		cfg.Language = sStr("language")
+		diffs, err := g.GetLocalDiff(git.DiffOptions{Staged: sBool("staged"), Whitespace: whitespace, Algorithm: algorithm})
 		if err != nil {
 			return nil, err
 		}
 		if !config.IsLLMAvailable(cfg) {
+			if name == "explain_diff" {
+				return toText(analysis.OfflineExplanation(diffs)), nil
+			}
 			return toText("No LLM configured."), nil
 		}
 		client := llm.NewClient(cfg)
@@ -142,8 +176,9 @@ func callTool(g *git.GitExtractor, formatter *git.DiffFormatter, name string, ar
 		if name == "explain_diff" {
 			prompt = llm.CreateExplainPrompt(formatter, diffs)
 		}
+		structured := name == "review_diff" && sBool("structured")
 		if name == "review_diff" {
-			prompt = llm.CreateReviewPrompt(formatter, diffs)
+			prompt = llm.CreateReviewPromptWithContext(formatter, diffs, llm.ReviewContext{Structured: structured})
 		}
 		if name == "ask_about_diff" {
 			prompt = llm.CreateQuestionPrompt(formatter, diffs, sStr("question"))
@@ -152,6 +187,14 @@ func callTool(g *git.GitExtractor, formatter *git.DiffFormatter, name string, ar
 		if err != nil {
 			return nil, err
 		}
+		if structured {
+			if result, err := llm.ParseReviewResult(resp.Content); err == nil {
+				b, _ := json.MarshalIndent(result, "", "  ")
+				out := toText(string(b))
+				out["structuredContent"] = result
+				return out, nil
+			}
+		}
 		return toText(resp.Content), nil
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
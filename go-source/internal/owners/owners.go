@@ -0,0 +1,149 @@
+// Package owners reads CODEOWNERS files and splits diffs by owning team.
+package owners
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"difflearn-go/internal/git"
+)
+
+// Locations are where GitHub and GitLab look for CODEOWNERS, in order.
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+type rule struct {
+	pattern string
+	owners  []string
+	re      *regexp.Regexp
+}
+
+// Codeowners maps paths to owners; the last matching rule wins.
+type Codeowners struct {
+	Path  string
+	rules []rule
+}
+
+// Load reads the first CODEOWNERS file found in repoPath.
+func Load(repoPath string) (*Codeowners, error) {
+	for _, name := range Locations {
+		b, err := os.ReadFile(filepath.Join(repoPath, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		c := Parse(string(b))
+		c.Path = name
+		return c, nil
+	}
+	return nil, fmt.Errorf("no CODEOWNERS file (looked in %s)", strings.Join(Locations, ", "))
+}
+
+// Parse reads CODEOWNERS rules. GitLab section headers are skipped, so their
+// rules are read as one list.
+func Parse(src string) *Codeowners {
+	c := &Codeowners{}
+	sc := bufio.NewScanner(strings.NewReader(src))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		c.rules = append(c.rules, rule{pattern: fields[0], owners: fields[1:], re: compilePattern(fields[0])})
+	}
+	return c
+}
+
+// Owners returns the owners of path, or nil when no rule assigns any.
+func (c *Codeowners) Owners(path string) []string {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].re.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// compilePattern turns a gitignore-style CODEOWNERS pattern into a regexp.
+// Patterns with a slash before their end are anchored at the repository
+// root, and a pattern naming a directory covers everything under it.
+func compilePattern(pattern string) *regexp.Regexp {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case trimmed[i] == '*':
+			sb.WriteString("[^/]*")
+		case trimmed[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(trimmed[i : i+1]))
+		}
+	}
+	if dirOnly {
+		sb.WriteString("/.*$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.MustCompile(sb.String())
+}
+
+// Group is the part of a diff one owner is responsible for. Owner is empty
+// for files no rule assigns.
+type Group struct {
+	Owner string           `json:"owner"`
+	Diffs []git.ParsedDiff `json:"diffs"`
+}
+
+// Split groups diffs by owner, sorted by owner with unowned files last. A
+// file with several owners appears in each of their groups.
+func Split(c *Codeowners, diffs []git.ParsedDiff) []Group {
+	byOwner := map[string][]git.ParsedDiff{}
+	for _, d := range diffs {
+		path := d.NewFile
+		if d.IsDeleted {
+			path = d.OldFile
+		}
+		owners := c.Owners(path)
+		if len(owners) == 0 {
+			owners = []string{""}
+		}
+		for _, o := range owners {
+			byOwner[o] = append(byOwner[o], d)
+		}
+	}
+	groups := make([]Group, 0, len(byOwner))
+	for o, d := range byOwner {
+		groups = append(groups, Group{Owner: o, Diffs: d})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i].Owner == "") != (groups[j].Owner == "") {
+			return groups[j].Owner == ""
+		}
+		return groups[i].Owner < groups[j].Owner
+	})
+	return groups
+}
@@ -0,0 +1,74 @@
+package owners
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+const sample = `# Default owners
+*                 @acme/core
+*.md              @acme/docs
+/apps/web/        @acme/web # frontend
+apps/*/api/**     @acme/api
+**/migrations     @acme/data @dba
+[Optional]
+/vendor/
+`
+
+func TestOwners(t *testing.T) {
+	c := Parse(sample)
+	cases := map[string][]string{
+		"main.go":                        {"@acme/core"},
+		"docs/guide.md":                  {"@acme/docs"},
+		"apps/web/src/App.tsx":           {"@acme/web"},
+		"apps/web/README.md":             {"@acme/web"},
+		"apps/billing/api/v1/handler.go": {"@acme/api"},
+		"services/db/migrations/001.sql": {"@acme/data", "@dba"},
+		"vendor/lib/lib.go":              {},
+	}
+	for path, want := range cases {
+		if got := c.Owners(path); len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+			t.Errorf("Owners(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	c := Parse("/web/ @web\n/db/ @data @dba\n")
+	diffs := []git.ParsedDiff{
+		{OldFile: "web/app.js", NewFile: "web/app.js"},
+		{OldFile: "db/schema.sql", NewFile: "db/schema.sql"},
+		{OldFile: "README", NewFile: "README"},
+		{OldFile: "web/old.js", NewFile: "web/old.js", IsDeleted: true},
+	}
+	groups := Split(c, diffs)
+	var owners []string
+	for _, g := range groups {
+		owners = append(owners, g.Owner)
+	}
+	if !reflect.DeepEqual(owners, []string{"@data", "@dba", "@web", ""}) {
+		t.Fatalf("unexpected owners: %q", owners)
+	}
+	if len(groups[2].Diffs) != 2 || groups[3].Diffs[0].NewFile != "README" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+	if err := PostWebhook(srv.URL, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if got["text"] != "hello" || got["content"] != "hello" {
+		t.Fatalf("unexpected payload: %v", got)
+	}
+}
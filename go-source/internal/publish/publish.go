@@ -0,0 +1,252 @@
+// Package publish writes a branch comparison as a static site: the web UI's
+// stylesheet and a single page laid out like its branch view, with the
+// commits, stats, AI sections and line comments baked in, so the result can
+// be uploaded to any static host and read without a DiffLearn server.
+package publish
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"difflearn-go/internal/git"
+	webassets "difflearn-go/web"
+)
+
+// Section is an AI answer shown above the files, as markdown.
+type Section struct {
+	Kind    string `json:"kind"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// Site is everything a published comparison shows.
+type Site struct {
+	Base        string           `json:"base"`
+	Target      string           `json:"target"`
+	Commits     []git.CommitInfo `json:"commits"`
+	Diffs       []git.ParsedDiff `json:"files"`
+	Sections    []Section        `json:"sections"`
+	Annotations []git.Annotation `json:"annotations"`
+	Generated   time.Time        `json:"generated"`
+}
+
+// Title names the comparison the way the web UI's branch view does.
+func (s Site) Title() string {
+	return s.Base + "..." + s.Target
+}
+
+// Write renders site into dir as index.html, styles.css and
+// comparison.json, creating dir when missing. Files of an earlier publish
+// are replaced; nothing else in dir is touched.
+func Write(dir string, site Site) error {
+	page, err := Page(site)
+	if err != nil {
+		return err
+	}
+	css, err := webassets.Assets.ReadFile("styles.css")
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(struct {
+		Title   string        `json:"title"`
+		Summary git.DiffStats `json:"summary"`
+		Site
+	}{site.Title(), git.NewDiffParser().GetStats(site.Diffs), site}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name, content := range map[string][]byte{"index.html": []byte(page), "styles.css": css, "comparison.json": data} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Page renders the comparison as a page that links styles.css.
+func Page(site Site) (string, error) {
+	var b strings.Builder
+	title := html.EscapeString(site.Title())
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"UTF-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	fmt.Fprintf(&b, "<title>DiffLearn - %s</title>\n", title)
+	b.WriteString("<link href=\"https://fonts.googleapis.com/css2?family=Inter:wght@400;500;600;700&family=JetBrains+Mono:wght@400;500&display=swap\" rel=\"stylesheet\">\n")
+	b.WriteString("<link rel=\"stylesheet\" href=\"styles.css\">\n</head>\n<body>\n<div class=\"app\">\n")
+
+	b.WriteString("<header class=\"header\">\n<div class=\"header-left\">\n<div class=\"brand-container\">\n<div class=\"brand-row\">\n")
+	b.WriteString("<h1 class=\"logo\"><span class=\"logo-icon\">🔍</span> DiffLearn</h1>\n")
+	fmt.Fprintf(&b, "<span class=\"tagline\">%s</span>\n</div>\n", title)
+	if !site.Generated.IsZero() {
+		fmt.Fprintf(&b, "<div class=\"cwd-display\">Published %s</div>\n", site.Generated.Format("2006-01-02 15:04 MST"))
+	}
+	b.WriteString("</div>\n</div>\n</header>\n<main class=\"main\">\n")
+
+	b.WriteString("<aside class=\"sidebar\" aria-label=\"Commits\">\n<div class=\"sidebar-header\">\n")
+	fmt.Fprintf(&b, "<h2>Commits (%d)</h2>\n</div>\n<div class=\"commit-list\">\n", len(site.Commits))
+	for _, c := range site.Commits {
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		meta := c.Author
+		if t, err := time.Parse(time.RFC3339, c.Date); err == nil {
+			meta += " · " + t.Format("2006-01-02")
+		}
+		fmt.Fprintf(&b, "<div class=\"commit-item\">\n<div class=\"commit-content\">\n<div class=\"commit-hash\">%s</div>\n<div class=\"commit-message\">%s</div>\n<div class=\"commit-meta\">%s</div>\n</div>\n</div>\n",
+			html.EscapeString(short(c.Hash)), html.EscapeString(subject), html.EscapeString(meta))
+	}
+	b.WriteString("</div>\n</aside>\n")
+
+	stats := git.NewDiffParser().GetStats(site.Diffs)
+	b.WriteString("<section class=\"diff-panel\" aria-label=\"Diff Viewer\">\n<div class=\"diff-header\">\n")
+	fmt.Fprintf(&b, "<h2>%s</h2>\n<div class=\"diff-stats\">\n<span class=\"stat-add\">+%d</span>\n<span class=\"stat-del\">-%d</span>\n<span>%d file(s)</span>\n</div>\n</div>\n",
+		title, stats.Additions, stats.Deletions, stats.Files)
+	b.WriteString("<div class=\"diff-content\">\n")
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	for _, s := range site.Sections {
+		// goldmark leaves out raw HTML unless told otherwise, so model
+		// output cannot inject markup into the page.
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(s.Content), &buf); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "<article class=\"session-note\">\n<p class=\"session-question\">%s</p>\n<div class=\"message-content\">\n%s</div>\n</article>\n",
+			html.EscapeString(s.Title), buf.String())
+	}
+	if len(site.Diffs) == 0 {
+		b.WriteString("<div class=\"empty-state\">\n<div class=\"empty-icon\">✨</div>\n<p>No changes</p>\n</div>\n")
+	}
+	notes := map[string][]git.Annotation{}
+	for _, a := range site.Annotations {
+		notes[a.File] = append(notes[a.File], a)
+	}
+	for _, d := range site.Diffs {
+		writeFile(&b, d, notes[fileName(d)])
+	}
+	b.WriteString("</div>\n</section>\n</main>\n</div>\n</body>\n</html>\n")
+	return b.String(), nil
+}
+
+func fileName(d git.ParsedDiff) string {
+	if d.NewFile == "" || d.IsDeleted {
+		return d.OldFile
+	}
+	return d.NewFile
+}
+
+func short(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// writeFile renders one file like the web UI's renderFileDiff, with its
+// annotations after the lines they point at, or under the file header when
+// the line is not shown.
+func writeFile(b *strings.Builder, d git.ParsedDiff, notes []git.Annotation) {
+	name := fileName(d)
+	status, label := "modified", "MOD"
+	switch {
+	case d.Submodule != nil:
+		status, label = "submodule", "SUB"
+	case d.IsNew:
+		status, label = "new", "NEW"
+	case d.IsDeleted:
+		status, label = "deleted", "DEL"
+	case d.IsCopied:
+		status, label = "copied", "CPY"
+	case d.IsRenamed:
+		status, label = "renamed", "REN"
+	}
+	fmt.Fprintf(b, "<div class=\"file-diff\" data-file=\"%s\">\n<div class=\"file-header\">\n<div class=\"file-name\">\n<span class=\"file-status %s\">%s</span>\n<span>%s</span>\n",
+		html.EscapeString(name), status, label, html.EscapeString(name))
+	if (d.IsRenamed || d.IsCopied) && d.OldFile != d.NewFile {
+		from := "from"
+		if d.IsCopied {
+			from = "copied from"
+		}
+		fmt.Fprintf(b, "<span class=\"file-origin\">%s %s</span>\n", from, html.EscapeString(d.OldFile))
+	}
+	b.WriteString("</div>\n")
+	if d.Submodule != nil {
+		fmt.Fprintf(b, "<div class=\"file-stats submodule-range\">%s</div>\n</div>\n</div>\n", html.EscapeString(git.SubmoduleRange(d.Submodule)))
+		return
+	}
+	fmt.Fprintf(b, "<div class=\"file-stats\">\n<span class=\"stat-add\">+%d</span>\n<span class=\"stat-del\">-%d</span>\n</div>\n</div>\n", d.Additions, d.Deletions)
+
+	placed := make([]bool, len(notes))
+	onLine := func(line git.ParsedLine) []int {
+		var found []int
+		for i, n := range notes {
+			side, number := "new", line.NewLineNumber
+			if line.Type == git.LineDelete {
+				side, number = "old", line.OldLineNumber
+			}
+			noteSide := n.Side
+			if noteSide != "old" {
+				noteSide = "new"
+			}
+			if !placed[i] && number != nil && n.Line == *number && noteSide == side {
+				found = append(found, i)
+			}
+		}
+		return found
+	}
+	var body strings.Builder
+	if d.Binary != nil {
+		fmt.Fprintf(&body, "<div class=\"binary-meta\">%s</div>\n", html.EscapeString(git.BinarySummary(d.Binary)))
+	}
+	for _, h := range d.Hunks {
+		fmt.Fprintf(&body, "<div class=\"hunk\">\n<div class=\"hunk-header\"><span class=\"hunk-title\">%s</span></div>\n", html.EscapeString(h.Header))
+		for _, line := range h.Lines {
+			class, prefix, number := "context", " ", line.NewLineNumber
+			switch line.Type {
+			case git.LineAdd:
+				class, prefix = "add", "+"
+			case git.LineDelete:
+				class, prefix, number = "del", "-", line.OldLineNumber
+			}
+			here := onLine(line)
+			if len(here) > 0 {
+				class += " annotated"
+			}
+			num := ""
+			if number != nil {
+				num = fmt.Sprint(*number)
+			}
+			fmt.Fprintf(&body, "<div class=\"diff-line %s\">\n<span class=\"line-num\">%s</span>\n<span class=\"line-content\">%s%s</span>\n</div>\n",
+				class, num, prefix, html.EscapeString(line.Content))
+			for _, i := range here {
+				placed[i] = true
+				writeNote(&body, notes[i])
+			}
+		}
+		body.WriteString("</div>\n")
+	}
+	for i, n := range notes {
+		if !placed[i] {
+			writeNote(b, n)
+		}
+	}
+	b.WriteString(body.String())
+	b.WriteString("</div>\n")
+}
+
+func writeNote(b *strings.Builder, n git.Annotation) {
+	severity := n.Severity
+	if severity == "" {
+		severity = "note"
+	}
+	fmt.Fprintf(b, "<div class=\"review-annotation severity-%s\" role=\"note\">\n<span class=\"review-severity\">%s</span>\n<span class=\"review-message\">%s</span>\n</div>\n",
+		html.EscapeString(severity), html.EscapeString(severity), html.EscapeString(n.Message))
+}
@@ -0,0 +1,115 @@
+package publish
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"difflearn-go/internal/git"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-func old() {}
++func greet() { println("<b>hi</b>") }
+ // end
+`
+
+func sampleSite() Site {
+	return Site{
+		Base:   "main",
+		Target: "feature",
+		Commits: []git.CommitInfo{
+			{Hash: "0123456789abcdef", Date: "2026-10-01T12:00:00Z", Message: "Rename old to greet\n\nBody", Author: "Ada"},
+		},
+		Diffs: git.NewDiffParser().Parse(sampleDiff),
+		Sections: []Section{
+			{Kind: "summary", Title: "Summary", Content: "Renames **old**.\n\n<script>alert(1)</script>"},
+		},
+		Annotations: []git.Annotation{
+			{File: "main.go", Line: 2, Severity: "minor", Message: "greet prints <b> tags"},
+			{File: "main.go", Line: 40, Message: "not on a shown line"},
+		},
+	}
+}
+
+func TestPage(t *testing.T) {
+	page, err := Page(sampleSite())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<link rel="stylesheet" href="styles.css">`,
+		"<title>DiffLearn - main...feature</title>",
+		`<div class="commit-hash">0123456</div>`,
+		`<div class="commit-message">Rename old to greet</div>`,
+		"Ada · 2026-10-01",
+		"<strong>old</strong>",
+		`<span class="stat-add">+1</span>`,
+		`<span class="file-status modified">MOD</span>`,
+		`+func greet() { println(&#34;&lt;b&gt;hi&lt;/b&gt;&#34;) }`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("raw HTML from a section reached the page")
+	}
+
+	// The note on line 2 follows the added line; the other one sits under
+	// the file header, before the first hunk.
+	added := strings.Index(page, `<div class="diff-line add annotated">`)
+	note := strings.Index(page, "greet prints &lt;b&gt; tags")
+	loose := strings.Index(page, "not on a shown line")
+	hunk := strings.Index(page, `<div class="hunk">`)
+	if added < 0 || note < added || loose < 0 || loose > hunk {
+		t.Errorf("annotations misplaced: added line at %d, its note at %d, loose note at %d, hunk at %d", added, note, loose, hunk)
+	}
+}
+
+func TestPageWithoutChanges(t *testing.T) {
+	page, err := Page(Site{Base: "main", Target: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(page, "No changes") || !strings.Contains(page, "Commits (0)") {
+		t.Errorf("empty comparison rendered as:\n%s", page)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	if err := Write(dir, sampleSite()); err != nil {
+		t.Fatal(err)
+	}
+	css, err := os.ReadFile(filepath.Join(dir, "styles.css"))
+	if err != nil || !strings.Contains(string(css), ".diff-line") {
+		t.Fatalf("styles.css not copied from the web assets: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index.html")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "comparison.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data struct {
+		Title   string        `json:"title"`
+		Summary git.DiffStats `json:"summary"`
+		Files   []git.ParsedDiff
+		Commits []git.CommitInfo
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Title != "main...feature" || data.Summary.Files != 1 || len(data.Files) != 1 || len(data.Commits) != 1 {
+		t.Errorf("comparison.json = %+v", data)
+	}
+}
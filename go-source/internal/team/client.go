@@ -0,0 +1,97 @@
+package team
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/learning"
+)
+
+// Client talks to a team server started with DIFFLEARN_TEAM_TOKEN set.
+type Client struct {
+	baseURL string
+	token   string
+	Member  string
+	http    *http.Client
+}
+
+func NewClient(baseURL, token, member string) *Client {
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), token: token, Member: member, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// ClientFromConfig reads DIFFLEARN_TEAM_URL, DIFFLEARN_TEAM_TOKEN and
+// DIFFLEARN_TEAM_MEMBER, using defaultMember when the last is unset.
+func ClientFromConfig(defaultMember string) (*Client, error) {
+	url := config.Setting("DIFFLEARN_TEAM_URL")
+	if url == "" {
+		return nil, fmt.Errorf("team mode is not configured: set DIFFLEARN_TEAM_URL and DIFFLEARN_TEAM_TOKEN")
+	}
+	member := config.Setting("DIFFLEARN_TEAM_MEMBER")
+	if member == "" {
+		member = defaultMember
+	}
+	if member == "" {
+		return nil, fmt.Errorf("set DIFFLEARN_TEAM_MEMBER or git's user.name to identify yourself to the team server")
+	}
+	return NewClient(url, config.Setting("DIFFLEARN_TEAM_TOKEN"), member), nil
+}
+
+// Push sends events to the server and returns how many it had not seen.
+func (c *Client) Push(events []learning.Event) (int, error) {
+	if events == nil {
+		events = []learning.Event{}
+	}
+	var data struct {
+		Added int `json:"added"`
+	}
+	err := c.do(http.MethodPost, "/team/events", pushRequest{Member: c.Member, Events: events}, &data)
+	return data.Added, err
+}
+
+func (c *Client) Summaries() ([]MemberSummary, error) {
+	var data []MemberSummary
+	err := c.do(http.MethodGet, "/team/progress", nil, &data)
+	return data, err
+}
+
+func (c *Client) do(method, path string, payload, out any) error {
+	var reader io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("team server: %s", resp.Status)
+	}
+	if !envelope.Success {
+		return fmt.Errorf("team server: %s", envelope.Error)
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
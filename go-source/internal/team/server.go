@@ -0,0 +1,67 @@
+package team
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/learning"
+)
+
+type pushRequest struct {
+	Member string           `json:"member"`
+	Events []learning.Event `json:"events"`
+}
+
+// Handler serves POST /team/events, where members push their events, and
+// GET /team/progress, where leads read every member's summary. Every
+// request must carry token as a bearer token.
+func Handler(token string, store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"success": false, "error": "missing or invalid team token"})
+			return
+		}
+		switch {
+		case r.URL.Path == "/team/events" && r.Method == http.MethodPost:
+			var body pushRequest
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"success": false, "error": "invalid JSON body"})
+				return
+			}
+			body.Member = strings.TrimSpace(body.Member)
+			if body.Member == "" {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"success": false, "error": "member is required"})
+				return
+			}
+			added, err := store.Add(body.Member, body.Events)
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"success": false, "error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"success": true, "data": map[string]any{"added": added}})
+		case r.URL.Path == "/team/progress" && r.Method == http.MethodGet:
+			events, err := store.Events()
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"success": false, "error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"success": true, "data": Summarize(events, learning.GoalFromConfig(), time.Now())})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]any{"success": false, "error": "not found"})
+		}
+	}
+}
+
+func authorized(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
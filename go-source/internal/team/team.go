@@ -0,0 +1,189 @@
+// Package team shares learning activity with a DiffLearn server so a team
+// lead can see which commits mentees studied and where they needed help.
+package team
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/learning"
+)
+
+// MemberEvent is a learning event pushed by a team member.
+type MemberEvent struct {
+	Member string `json:"member"`
+	learning.Event
+}
+
+// Struggle is a commit a member asked about or requested several AI answers
+// for, a hint that it was hard to follow.
+type Struggle struct {
+	Repo      string `json:"repo"`
+	Ref       string `json:"ref"`
+	Requests  int    `json:"requests"`
+	Questions int    `json:"questions"`
+}
+
+type MemberSummary struct {
+	Member     string            `json:"member"`
+	Progress   learning.Progress `json:"progress"`
+	LastActive time.Time         `json:"lastActive"`
+	// RecentCommits lists the last commits studied, newest first.
+	RecentCommits []string   `json:"recentCommits"`
+	Questions     int        `json:"questions"`
+	Struggles     []Struggle `json:"struggles"`
+}
+
+const recentCommits = 10
+
+// Store keeps every member's events on the server.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns the store in config.DataDir.
+func Open() *Store {
+	return NewStore(filepath.Join(config.DataDir(), "team.jsonl"))
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Add appends a member's events, skipping ones already stored, so clients
+// can push their whole history on every sync. It returns how many were new.
+func (s *Store) Add(member string, events []learning.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.events()
+	if err != nil {
+		return 0, err
+	}
+	seen := map[string]bool{}
+	for _, e := range existing {
+		seen[eventKey(e)] = true
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	added := 0
+	for _, e := range events {
+		me := MemberEvent{Member: member, Event: e}
+		key := eventKey(me)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		line, err := json.Marshal(me)
+		if err != nil {
+			return added, err
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			return added, err
+		}
+		added++
+	}
+	return added, nil
+}
+
+func (s *Store) Events() ([]MemberEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events()
+}
+
+func (s *Store) events() ([]MemberEvent, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []MemberEvent
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e MemberEvent
+		if json.Unmarshal(sc.Bytes(), &e) == nil && e.Member != "" {
+			out = append(out, e)
+		}
+	}
+	return out, sc.Err()
+}
+
+func eventKey(e MemberEvent) string {
+	return fmt.Sprintf("%s\x00%d\x00%s\x00%s\x00%s", e.Member, e.Time.UnixNano(), e.Repo, e.Kind, e.Ref)
+}
+
+// Summarize computes each member's progress against goal, sorted by name.
+func Summarize(events []MemberEvent, goal learning.Goal, now time.Time) []MemberSummary {
+	byMember := map[string][]learning.Event{}
+	for _, e := range events {
+		byMember[e.Member] = append(byMember[e.Member], e.Event)
+	}
+	out := make([]MemberSummary, 0, len(byMember))
+	for member, evs := range byMember {
+		sort.SliceStable(evs, func(i, j int) bool { return evs[i].Time.Before(evs[j].Time) })
+		s := MemberSummary{Member: member, Progress: learning.Compute(evs, goal, now), RecentCommits: []string{}, Struggles: []Struggle{}}
+		s.LastActive = evs[len(evs)-1].Time
+
+		seen := map[string]bool{}
+		for i := len(evs) - 1; i >= 0 && len(s.RecentCommits) < recentCommits; i-- {
+			e := evs[i]
+			if e.Kind == learning.KindCommit && e.Ref != "" && !seen[e.Ref] {
+				seen[e.Ref] = true
+				s.RecentCommits = append(s.RecentCommits, e.Ref)
+			}
+		}
+
+		struggles := map[string]*Struggle{}
+		for _, e := range evs {
+			if e.Kind == "ask" {
+				s.Questions++
+			}
+			if e.Kind == learning.KindCommit || e.Ref == "" {
+				continue
+			}
+			key := e.Repo + "\x00" + e.Ref
+			st := struggles[key]
+			if st == nil {
+				st = &Struggle{Repo: filepath.Base(e.Repo), Ref: e.Ref}
+				struggles[key] = st
+			}
+			st.Requests++
+			if e.Kind == "ask" {
+				st.Questions++
+			}
+		}
+		for _, st := range struggles {
+			if st.Questions > 0 || st.Requests > 1 {
+				s.Struggles = append(s.Struggles, *st)
+			}
+		}
+		sort.Slice(s.Struggles, func(i, j int) bool {
+			a, b := s.Struggles[i], s.Struggles[j]
+			if a.Requests != b.Requests {
+				return a.Requests > b.Requests
+			}
+			return a.Ref < b.Ref
+		})
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Member < out[j].Member })
+	return out
+}
@@ -0,0 +1,55 @@
+package team
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"difflearn-go/internal/learning"
+)
+
+func TestPushAndSummaries(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "team.jsonl"))
+	srv := httptest.NewServer(Handler("secret", store))
+	defer srv.Close()
+
+	now := time.Now()
+	events := []learning.Event{
+		{Time: now.Add(-3 * time.Hour), Repo: "/src/app", Kind: learning.KindCommit, Ref: "abc"},
+		{Time: now.Add(-2 * time.Hour), Repo: "/src/app", Kind: "explain", Ref: "abc"},
+		{Time: now.Add(-time.Hour), Repo: "/src/app", Kind: "ask", Ref: "abc"},
+		{Time: now, Repo: "/src/app", Kind: learning.KindCommit, Ref: "def"},
+	}
+	client := NewClient(srv.URL, "secret", "ana")
+	if added, err := client.Push(events); err != nil || added != 4 {
+		t.Fatalf("Push = %d, %v", added, err)
+	}
+	// Pushing the whole history again only adds what is new.
+	if added, err := client.Push(events[:2]); err != nil || added != 0 {
+		t.Fatalf("second Push = %d, %v", added, err)
+	}
+
+	summaries, err := client.Summaries()
+	if err != nil || len(summaries) != 1 {
+		t.Fatalf("Summaries = %+v, %v", summaries, err)
+	}
+	s := summaries[0]
+	if s.Member != "ana" || s.Questions != 1 || s.Progress.TotalCommits != 2 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if len(s.RecentCommits) != 2 || s.RecentCommits[0] != "def" {
+		t.Fatalf("unexpected recent commits: %v", s.RecentCommits)
+	}
+	if len(s.Struggles) != 1 || s.Struggles[0] != (Struggle{Repo: "app", Ref: "abc", Requests: 2, Questions: 1}) {
+		t.Fatalf("unexpected struggles: %+v", s.Struggles)
+	}
+}
+
+func TestHandlerRejectsWrongToken(t *testing.T) {
+	srv := httptest.NewServer(Handler("secret", NewStore(filepath.Join(t.TempDir(), "team.jsonl"))))
+	defer srv.Close()
+	if _, err := NewClient(srv.URL, "wrong", "ana").Summaries(); err == nil {
+		t.Fatal("expected an error for a wrong token")
+	}
+}
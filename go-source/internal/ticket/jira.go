@@ -0,0 +1,87 @@
+package ticket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"difflearn-go/internal/config"
+)
+
+// Jira reads issues through the REST API v2, whose description field is
+// plain text rather than the v3 document format.
+type Jira struct {
+	baseURL string
+	// Email and Token authenticate Jira Cloud (API token); Token alone is
+	// sent as a bearer personal access token for Server and Data Center.
+	Email string
+	Token string
+}
+
+// NewJira reads JIRA_URL, JIRA_EMAIL and JIRA_API_TOKEN; it returns nil when
+// JIRA_URL is unset.
+func NewJira() *Jira {
+	base := config.Setting("JIRA_URL")
+	if base == "" {
+		return nil
+	}
+	return newJira(base, config.Setting("JIRA_EMAIL"), config.Setting("JIRA_API_TOKEN"))
+}
+
+func newJira(base, email, token string) *Jira {
+	return &Jira{baseURL: strings.TrimRight(base, "/"), Email: email, Token: token}
+}
+
+// Owns reports whether link points into this Jira site.
+func (j *Jira) Owns(link *url.URL) bool {
+	u, err := url.Parse(j.baseURL)
+	return err == nil && strings.EqualFold(u.Host, link.Host)
+}
+
+func (j *Jira) Get(key string) (Ticket, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary,description,status", j.baseURL, url.PathEscape(key)), nil)
+	if err != nil {
+		return Ticket{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case j.Email != "" && j.Token != "":
+		req.SetBasicAuth(j.Email, j.Token)
+	case j.Token != "":
+		req.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Ticket{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Ticket{}, err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			ErrorMessages []string `json:"errorMessages"`
+		}
+		_ = json.Unmarshal(body, &e)
+		msg := resp.Status
+		if len(e.ErrorMessages) > 0 {
+			msg = strings.Join(e.ErrorMessages, "; ")
+		}
+		return Ticket{}, fmt.Errorf("jira %s: %s (check JIRA_EMAIL and JIRA_API_TOKEN)", key, msg)
+	}
+	var issue struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(body, &issue); err != nil {
+		return Ticket{}, err
+	}
+	return Ticket{Source: j.baseURL + "/browse/" + issue.Key, Title: issue.Key + ": " + issue.Fields.Summary, Body: issue.Fields.Description}, nil
+}
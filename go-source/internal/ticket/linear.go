@@ -0,0 +1,81 @@
+package ticket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"difflearn-go/internal/config"
+)
+
+const defaultLinearAPI = "https://api.linear.app/graphql"
+
+// Linear reads issues through the GraphQL API with a personal API key.
+type Linear struct {
+	endpoint string
+	APIKey   string
+}
+
+// NewLinear reads LINEAR_API_KEY (and LINEAR_API_URL, mostly for tests);
+// it returns nil when the key is unset.
+func NewLinear() *Linear {
+	key := config.Setting("LINEAR_API_KEY")
+	if key == "" {
+		return nil
+	}
+	endpoint := config.Setting("LINEAR_API_URL")
+	if endpoint == "" {
+		endpoint = defaultLinearAPI
+	}
+	return newLinear(endpoint, key)
+}
+
+func newLinear(endpoint, key string) *Linear {
+	return &Linear{endpoint: endpoint, APIKey: key}
+}
+
+const linearIssueQuery = `query($id: String!) { issue(id: $id) { identifier title description url } }`
+
+// Get accepts an identifier such as ENG-123.
+func (l *Linear) Get(id string) (Ticket, error) {
+	payload, err := json.Marshal(map[string]any{"query": linearIssueQuery, "variables": map[string]string{"id": id}})
+	if err != nil {
+		return Ticket{}, err
+	}
+	req, err := http.NewRequest(http.MethodPost, l.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return Ticket{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", l.APIKey)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Ticket{}, err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Data struct {
+			Issue *struct {
+				Identifier  string `json:"identifier"`
+				Title       string `json:"title"`
+				Description string `json:"description"`
+				URL         string `json:"url"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Ticket{}, fmt.Errorf("linear %s: %s", id, resp.Status)
+	}
+	if len(out.Errors) > 0 {
+		return Ticket{}, fmt.Errorf("linear %s: %s", id, out.Errors[0].Message)
+	}
+	if resp.StatusCode >= 300 || out.Data.Issue == nil {
+		return Ticket{}, fmt.Errorf("linear %s: %s (check LINEAR_API_KEY)", id, resp.Status)
+	}
+	i := out.Data.Issue
+	return Ticket{Source: i.URL, Title: i.Identifier + ": " + i.Title, Body: i.Description}, nil
+}
@@ -0,0 +1,173 @@
+// Package ticket loads the issue or ticket a change is meant to address, so
+// prompts can check the diff against the requirement.
+package ticket
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"difflearn-go/internal/config"
+	"difflearn-go/internal/forge"
+)
+
+// maxChars keeps a long ticket or web page from crowding out the diff.
+const maxChars = 20000
+
+type Ticket struct {
+	// Source is the URL or file the ticket came from; empty for inline text.
+	Source string `json:"source,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Body   string `json:"body"`
+}
+
+// Text is the ticket as it goes into a prompt.
+func (t Ticket) Text() string {
+	var sb strings.Builder
+	if t.Title != "" {
+		sb.WriteString("# " + t.Title + "\n\n")
+	}
+	if t.Source != "" {
+		sb.WriteString("Source: " + t.Source + "\n\n")
+	}
+	sb.WriteString(strings.TrimSpace(t.Body))
+	text := sb.String()
+	if len(text) > maxChars {
+		text = text[:maxChars] + "\n\n[ticket truncated]"
+	}
+	return text
+}
+
+// Load resolves arg: an issue URL on GitHub, GitLab or Bitbucket is read
+// through the forge API, a Jira or Linear issue key or URL through the
+// tracker's API, any other http(s) URL is fetched and reduced to text, an
+// existing file is read, and anything else is the ticket text.
+func Load(arg string) (Ticket, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return Ticket{}, fmt.Errorf("ticket is empty")
+	}
+	if ref, ok := forge.ParseIssueURL(arg); ok {
+		client, err := forge.New(ref.Kind)
+		if err != nil {
+			return Ticket{}, err
+		}
+		issue, err := client.GetIssue(ref)
+		if err != nil {
+			return Ticket{}, err
+		}
+		return Ticket{Source: arg, Title: issue.Title, Body: issue.Body}, nil
+	}
+	if issueKeyRe.MatchString(arg) {
+		return loadKey(arg)
+	}
+	if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+		if t, ok, err := loadTrackerURL(arg); ok {
+			return t, err
+		}
+		return fetchPage(arg)
+	}
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			return Ticket{}, err
+		}
+		return Ticket{Source: arg, Body: string(b)}, nil
+	}
+	return Ticket{Body: arg}, nil
+}
+
+var (
+	issueKeyRe   = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-\d+$`)
+	jiraBrowseRe = regexp.MustCompile(`/browse/([A-Z][A-Z0-9_]+-\d+)`)
+	linearURLRe  = regexp.MustCompile(`^https://linear\.app/[^/]+/issue/([A-Z][A-Z0-9_]+-\d+)`)
+)
+
+// loadKey fetches an issue key such as PROJ-123 from Jira or Linear. With
+// both configured, DIFFLEARN_TICKET_TRACKER (jira or linear) decides and
+// Jira is the default.
+func loadKey(key string) (Ticket, error) {
+	jira, linear := NewJira(), NewLinear()
+	if linear != nil && (jira == nil || config.Setting("DIFFLEARN_TICKET_TRACKER") == "linear") {
+		return linear.Get(key)
+	}
+	if jira != nil {
+		return jira.Get(key)
+	}
+	return Ticket{}, fmt.Errorf("%s looks like an issue key: set JIRA_URL (with JIRA_EMAIL and JIRA_API_TOKEN) or LINEAR_API_KEY to fetch it", key)
+}
+
+// loadTrackerURL reads Linear issue URLs and browse URLs of the configured
+// Jira site through their APIs; ok is false for other URLs.
+func loadTrackerURL(raw string) (Ticket, bool, error) {
+	if m := linearURLRe.FindStringSubmatch(raw); m != nil {
+		if linear := NewLinear(); linear != nil {
+			t, err := linear.Get(m[1])
+			return t, true, err
+		}
+	}
+	if m := jiraBrowseRe.FindStringSubmatch(raw); m != nil {
+		link, err := url.Parse(raw)
+		if jira := NewJira(); jira != nil && err == nil && jira.Owns(link) {
+			t, err := jira.Get(m[1])
+			return t, true, err
+		}
+	}
+	return Ticket{}, false, nil
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+func fetchPage(url string) (Ticket, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return Ticket{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return Ticket{}, fmt.Errorf("fetching ticket %s: %s", url, resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return Ticket{}, err
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return Ticket{Source: url, Body: string(b)}, nil
+	}
+	title, text := htmlToText(string(b))
+	return Ticket{Source: url, Title: title, Body: text}, nil
+}
+
+var (
+	titleRe    = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	skipRe     = regexp.MustCompile(`(?is)<(script|style|head|nav|footer|svg)[^>]*>.*?</(script|style|head|nav|footer|svg)>`)
+	blockRe    = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6]|/tr|/pre)[^>]*>`)
+	tagRe      = regexp.MustCompile(`(?s)<[^>]+>`)
+	spacesRe   = regexp.MustCompile(`[ \t]+`)
+	newlinesRe = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// htmlToText is a rough reduction of a web page to its title and readable
+// text; good enough for a prompt, not for display.
+func htmlToText(page string) (string, string) {
+	title := ""
+	if m := titleRe.FindStringSubmatch(page); m != nil {
+		title = strings.TrimSpace(html.UnescapeString(m[1]))
+	}
+	text := skipRe.ReplaceAllString(page, "")
+	text = blockRe.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(tagRe.ReplaceAllString(text, ""))
+	text = spacesRe.ReplaceAllString(text, " ")
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	text = newlinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return title, strings.TrimSpace(text)
+}
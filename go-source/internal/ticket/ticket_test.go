@@ -0,0 +1,96 @@
+package ticket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTextAndFile(t *testing.T) {
+	tk, err := Load("Users can reset their password")
+	if err != nil || tk.Body != "Users can reset their password" || tk.Source != "" {
+		t.Fatalf("Load(text) = %+v, %v", tk, err)
+	}
+	path := filepath.Join(t.TempDir(), "ticket.md")
+	if err := os.WriteFile(path, []byte("Add rate limiting\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tk, err = Load(path)
+	if err != nil || tk.Source != path || !strings.Contains(tk.Text(), "Add rate limiting") {
+		t.Fatalf("Load(file) = %+v, %v", tk, err)
+	}
+}
+
+func TestLoadWebPage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>PROJ-12 &amp; friends</title><script>var x = 1;</script></head>
+<body><nav>Home</nav><h1>Export CSV</h1><p>Users  need to <b>export</b> reports.</p><ul><li>Include totals</li></ul></body></html>`))
+	}))
+	defer srv.Close()
+	tk, err := Load(srv.URL + "/browse/PROJ-12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tk.Title != "PROJ-12 & friends" || tk.Body != "Export CSV\nUsers need to export reports.\nInclude totals" {
+		t.Fatalf("unexpected ticket: %+v", tk)
+	}
+}
+
+func TestLoadJiraAndLinearKeys(t *testing.T) {
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "jt" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/rest/api/2/issue/PROJ-7" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"key":"PROJ-7","fields":{"summary":"Export CSV","description":"Include totals"}}`))
+	}))
+	defer jira.Close()
+	linear := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]string `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.Header.Get("Authorization") != "lin_key" || body.Variables["id"] != "ENG-3" {
+			w.Write([]byte(`{"errors":[{"message":"not found"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"issue":{"identifier":"ENG-3","title":"Dark mode","description":"Follow the OS","url":"https://linear.app/acme/issue/ENG-3/dark-mode"}}}`))
+	}))
+	defer linear.Close()
+
+	t.Setenv("JIRA_URL", jira.URL)
+	t.Setenv("JIRA_EMAIL", "me@example.com")
+	t.Setenv("JIRA_API_TOKEN", "jt")
+	t.Setenv("LINEAR_API_KEY", "lin_key")
+	t.Setenv("LINEAR_API_URL", linear.URL)
+	t.Setenv("DIFFLEARN_TICKET_TRACKER", "")
+
+	tk, err := Load("PROJ-7")
+	if err != nil || tk.Title != "PROJ-7: Export CSV" || tk.Body != "Include totals" || tk.Source != jira.URL+"/browse/PROJ-7" {
+		t.Fatalf("Load(PROJ-7) = %+v, %v", tk, err)
+	}
+	if tk, err := Load(jira.URL + "/browse/PROJ-7"); err != nil || tk.Body != "Include totals" {
+		t.Fatalf("Load(browse URL) = %+v, %v", tk, err)
+	}
+	if tk, err := Load("https://linear.app/acme/issue/ENG-3/dark-mode"); err != nil || tk.Title != "ENG-3: Dark mode" {
+		t.Fatalf("Load(linear URL) = %+v, %v", tk, err)
+	}
+	t.Setenv("DIFFLEARN_TICKET_TRACKER", "linear")
+	if tk, err := Load("ENG-3"); err != nil || tk.Body != "Follow the OS" {
+		t.Fatalf("Load(ENG-3) = %+v, %v", tk, err)
+	}
+	t.Setenv("JIRA_URL", "")
+	t.Setenv("LINEAR_API_KEY", "")
+	if _, err := Load("ENG-3"); err == nil || !strings.Contains(err.Error(), "looks like an issue key") {
+		t.Fatalf("expected a configuration hint, got %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+// Package usage keeps a ledger of LLM calls and enforces the daily and
+// monthly budgets configured with DIFFLEARN_LLM_BUDGET.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"difflearn-go/internal/config"
+)
+
+// ErrBudgetExceeded is wrapped by every *ExceededError.
+var ErrBudgetExceeded = errors.New("LLM budget exceeded")
+
+// Scopes a cap can apply to besides an endpoint name.
+const (
+	ScopeGlobal = ""
+	// ScopeToken caps each API token separately.
+	ScopeToken = "token"
+)
+
+// Cap is one limit, e.g. 200000 tokens per day for the review endpoint.
+type Cap struct {
+	// Scope is ScopeGlobal, ScopeToken or an endpoint such as "review".
+	Scope string `json:"scope,omitempty"`
+	// Unit is "tokens" or "requests".
+	Unit string `json:"unit"`
+	// Period is "day" or "month", in local time.
+	Period string `json:"period"`
+	Limit  int    `json:"limit"`
+}
+
+func (c Cap) String() string {
+	s := fmt.Sprintf("%d %s/%s", c.Limit, c.Unit, c.Period)
+	if c.Scope != ScopeGlobal {
+		s = c.Scope + ":" + s
+	}
+	return s
+}
+
+// Budget is the set of caps in force. An empty Budget allows everything.
+type Budget []Cap
+
+// ParseBudget reads comma-separated caps of the form
+// "[scope:]<n> tokens|requests/day|month", e.g.
+// "500000 tokens/month, token:50 requests/day, review:100000 tokens/day".
+func ParseBudget(spec string) (Budget, error) {
+	var budget Budget
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		c := Cap{}
+		rest := entry
+		if scope, after, ok := strings.Cut(entry, ":"); ok {
+			c.Scope, rest = strings.TrimSpace(strings.TrimPrefix(scope, "/")), after
+		}
+		fields := strings.Fields(rest)
+		invalid := fmt.Errorf("invalid budget %q (use e.g. 100000 tokens/day or token:50 requests/month)", entry)
+		if len(fields) != 2 {
+			return nil, invalid
+		}
+		limit, err := strconv.Atoi(strings.ReplaceAll(fields[0], "_", ""))
+		unit, period, ok := strings.Cut(strings.ToLower(fields[1]), "/")
+		if err != nil || limit < 0 || !ok {
+			return nil, invalid
+		}
+		c.Limit, c.Unit, c.Period = limit, strings.TrimSuffix(unit, "s")+"s", period
+		if (c.Unit != "tokens" && c.Unit != "requests") || (c.Period != "day" && c.Period != "month") {
+			return nil, invalid
+		}
+		budget = append(budget, c)
+	}
+	return budget, nil
+}
+
+// LoadBudget reads DIFFLEARN_LLM_BUDGET.
+func LoadBudget() (Budget, error) {
+	return ParseBudget(config.Setting("DIFFLEARN_LLM_BUDGET"))
+}
+
+// Record is one LLM call.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Key identifies the API token that made the call; empty for the CLI
+	// and servers without tokens.
+	Key      string `json:"key,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Tokens   int    `json:"tokens"`
+}
+
+// ExceededError says which cap stopped a call and when it resets.
+type ExceededError struct {
+	Cap    Cap
+	Used   int
+	Resets time.Time
+}
+
+func (e *ExceededError) Error() string {
+	scope := "global"
+	switch e.Cap.Scope {
+	case ScopeGlobal:
+	case ScopeToken:
+		scope = "per-token"
+	default:
+		scope = e.Cap.Scope
+	}
+	return fmt.Sprintf("%s: %s %s budget of %d %s is used up (%d); it resets %s", ErrBudgetExceeded, scope, periodAdjective(e.Cap.Period), e.Cap.Limit, e.Cap.Unit, e.Used, e.Resets.Format("2006-01-02 15:04"))
+}
+
+func (e *ExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+func periodAdjective(period string) string {
+	if period == "month" {
+		return "monthly"
+	}
+	return "daily"
+}
+
+// periodStart returns the start of the day or month containing now, and the
+// start of the next one.
+func periodStart(period string, now time.Time) (time.Time, time.Time) {
+	y, m, d := now.Date()
+	if period == "month" {
+		start := time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Ledger is an append-only JSON Lines file of records shared by the CLI and
+// the server.
+type Ledger struct {
+	path string
+}
+
+// ledgerMu serializes ledger access within the process; the server checks
+// and records concurrently.
+var ledgerMu sync.Mutex
+
+// Open returns the ledger in config.DataDir.
+func Open() *Ledger {
+	return NewLedger(filepath.Join(config.DataDir(), "llm-usage.jsonl"))
+}
+
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+func (l *Ledger) Add(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Records returns the records made since since.
+func (l *Ledger) Records(since time.Time) ([]Record, error) {
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records []Record
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var r Record
+		if json.Unmarshal(s.Bytes(), &r) == nil && !r.Time.Before(since) {
+			records = append(records, r)
+		}
+	}
+	return records, s.Err()
+}
+
+// CapStatus is how much of a cap is used in its current period.
+type CapStatus struct {
+	Cap
+	Used   int       `json:"used"`
+	Resets time.Time `json:"resets"`
+}
+
+// Status reports every cap that applies to key: the global and endpoint
+// caps, and the per-token caps when key is set.
+func (l *Ledger) Status(budget Budget, key string, now time.Time) ([]CapStatus, error) {
+	records, err := l.month(now)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]CapStatus, 0, len(budget))
+	for _, c := range budget {
+		if c.Scope == ScopeToken && key == "" {
+			continue
+		}
+		statuses = append(statuses, capStatus(records, c, key, now))
+	}
+	return statuses, nil
+}
+
+// Check returns an *ExceededError for the first cap the next call by key to
+// endpoint would break. Calls are checked before they are made and recorded
+// after, so a token cap can be overshot by the call that reaches it.
+func (l *Ledger) Check(budget Budget, key, endpoint string, now time.Time) error {
+	if len(budget) == 0 {
+		return nil
+	}
+	records, err := l.month(now)
+	if err != nil {
+		return err
+	}
+	for _, c := range budget {
+		if (c.Scope == ScopeToken && key == "") || (isEndpoint(c.Scope) && c.Scope != endpoint) {
+			continue
+		}
+		if st := capStatus(records, c, key, now); st.Used >= c.Limit {
+			return &ExceededError{Cap: c, Used: st.Used, Resets: st.Resets}
+		}
+	}
+	return nil
+}
+
+// Summary adds up key's records of the current month (all records when key
+// is empty).
+func (l *Ledger) Summary(key string, now time.Time) (Summary, error) {
+	records, err := l.month(now)
+	if err != nil {
+		return Summary{}, err
+	}
+	return Summarize(records, key, now), nil
+}
+
+func (l *Ledger) month(now time.Time) ([]Record, error) {
+	start, _ := periodStart("month", now)
+	return l.Records(start)
+}
+
+func isEndpoint(scope string) bool {
+	return scope != ScopeGlobal && scope != ScopeToken
+}
+
+func capStatus(records []Record, c Cap, key string, now time.Time) CapStatus {
+	start, resets := periodStart(c.Period, now)
+	st := CapStatus{Cap: c, Resets: resets}
+	for _, r := range records {
+		if r.Time.Before(start) || (c.Scope == ScopeToken && r.Key != key) || (isEndpoint(c.Scope) && r.Endpoint != c.Scope) {
+			continue
+		}
+		if c.Unit == "tokens" {
+			st.Used += r.Tokens
+		} else {
+			st.Used++
+		}
+	}
+	return st
+}
+
+// Totals counts requests and tokens.
+type Totals struct {
+	Requests int `json:"requests"`
+	Tokens   int `json:"tokens"`
+}
+
+// Summary is the usage of the current day and month, overall and per
+// endpoint.
+type Summary struct {
+	Day             Totals            `json:"day"`
+	Month           Totals            `json:"month"`
+	DayByEndpoint   map[string]Totals `json:"dayByEndpoint"`
+	MonthByEndpoint map[string]Totals `json:"monthByEndpoint"`
+}
+
+// Summarize adds up the records of key (all records when key is empty).
+func Summarize(records []Record, key string, now time.Time) Summary {
+	dayStart, _ := periodStart("day", now)
+	monthStart, _ := periodStart("month", now)
+	s := Summary{DayByEndpoint: map[string]Totals{}, MonthByEndpoint: map[string]Totals{}}
+	add := func(t Totals, r Record) Totals {
+		return Totals{Requests: t.Requests + 1, Tokens: t.Tokens + r.Tokens}
+	}
+	for _, r := range records {
+		if key != "" && r.Key != key {
+			continue
+		}
+		endpoint := r.Endpoint
+		if endpoint == "" {
+			endpoint = "other"
+		}
+		if !r.Time.Before(monthStart) {
+			s.Month = add(s.Month, r)
+			s.MonthByEndpoint[endpoint] = add(s.MonthByEndpoint[endpoint], r)
+		}
+		if !r.Time.Before(dayStart) {
+			s.Day = add(s.Day, r)
+			s.DayByEndpoint[endpoint] = add(s.DayByEndpoint[endpoint], r)
+		}
+	}
+	return s
+}
+
+// Endpoints returns the endpoint names of a summary, sorted.
+func (s Summary) Endpoints() []string {
+	names := make([]string, 0, len(s.MonthByEndpoint))
+	for name := range s.MonthByEndpoint {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
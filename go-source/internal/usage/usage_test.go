@@ -0,0 +1,89 @@
+package usage
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBudget(t *testing.T) {
+	budget, err := ParseBudget("500_000 tokens/month, token:50 requests/day, /review:1000 token/day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Budget{
+		{Unit: "tokens", Period: "month", Limit: 500000},
+		{Scope: ScopeToken, Unit: "requests", Period: "day", Limit: 50},
+		{Scope: "review", Unit: "tokens", Period: "day", Limit: 1000},
+	}
+	if len(budget) != len(want) {
+		t.Fatalf("got %v", budget)
+	}
+	for i := range want {
+		if budget[i] != want[i] {
+			t.Errorf("cap %d: got %+v, want %+v", i, budget[i], want[i])
+		}
+	}
+	for _, bad := range []string{"100", "100 tokens", "ten tokens/day", "100 tokens/week", "100 calls/day"} {
+		if _, err := ParseBudget(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestLedgerCheck(t *testing.T) {
+	l := NewLedger(filepath.Join(t.TempDir(), "usage.jsonl"))
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	for _, r := range []Record{
+		{Time: now.AddDate(0, -1, 0), Key: "ai:a", Endpoint: "review", Tokens: 9000},
+		{Time: now.Add(-24 * time.Hour), Key: "ai:a", Endpoint: "review", Tokens: 400},
+		{Time: now.Add(-time.Hour), Key: "ai:a", Endpoint: "review", Tokens: 300},
+		{Time: now.Add(-time.Hour), Key: "ai:b", Endpoint: "explain", Tokens: 200},
+	} {
+		if err := l.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	budget, _ := ParseBudget("1000 tokens/month, token:1 requests/day, review:300 tokens/day")
+	if err := l.Check(budget[:1], "", "", now); err != nil {
+		t.Fatalf("900 of 1000 monthly tokens should pass: %v", err)
+	}
+	err := l.Check(budget[1:2], "ai:a", "explain", now)
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected the per-token cap to stop ai:a, got %v", err)
+	}
+	if exceeded.Used != 1 || !exceeded.Resets.Equal(time.Date(2026, 3, 16, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("got %+v", exceeded)
+	}
+	if !strings.Contains(err.Error(), "per-token daily budget of 1 requests") {
+		t.Errorf("unclear message: %v", err)
+	}
+	if err := l.Check(budget[1:2], "", "explain", now); err != nil {
+		t.Errorf("per-token caps should not apply without a token: %v", err)
+	}
+	if err := l.Check(budget[2:], "ai:c", "explain", now); err != nil {
+		t.Errorf("the review cap should not apply to explain: %v", err)
+	}
+	if err := l.Check(budget[2:], "ai:c", "review", now); err == nil {
+		t.Error("expected the review cap to be used up")
+	}
+
+	statuses, err := l.Status(budget, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].Used != 900 || statuses[1].Used != 300 {
+		t.Errorf("got %+v", statuses)
+	}
+	sum, err := l.Summary("ai:a", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Day != (Totals{Requests: 1, Tokens: 300}) || sum.Month != (Totals{Requests: 2, Tokens: 700}) || sum.MonthByEndpoint["review"].Requests != 2 {
+		t.Errorf("got %+v", sum)
+	}
+}
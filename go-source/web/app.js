@@ -11,9 +11,17 @@ let currentView = 'local';
 let currentDiff = null;
 let currentCommit = null;
 let commits = [];
+let historyCursor = '';
+// Revert pairs of the recent history, from /history/reverts.
+let revertPairs = [];
+let sessionQuery = '';
+let sessionSearchTimeout;
+// The /ask conversation follow-up questions continue, for the diff in key.
+let chatConversation = { id: '', key: '' };
 let pendingContext = null;
 let selectedForCompare = []; // Array of commit hashes selected for comparison (max 2)
 let branchEntries = [];
+let tagEntries = [];
 let currentBranchName = '';
 let branchSelection = {
     switchTo: '',
@@ -21,6 +29,7 @@ let branchSelection = {
     target: '',
     mode: 'triple',
 };
+let includeUntracked = localStorage.getItem('includeUntracked') === 'true';
 let currentDiffContext = {
     type: 'local',
     staged: false,
@@ -33,6 +42,10 @@ let currentDiffContext = {
 // DOM Elements
 const elements = {
     llmStatus: document.getElementById('llmStatus'),
+    progressBadge: document.getElementById('progressBadge'),
+    modelSelect: document.getElementById('modelSelect'),
+    levelSelect: document.getElementById('levelSelect'),
+    languageSelect: document.getElementById('languageSelect'),
     refreshBtn: document.getElementById('refreshBtn'),
     commitList: document.getElementById('commitList'),
     diffHeader: document.getElementById('diffHeader'),
@@ -42,6 +55,7 @@ const elements = {
     explainBtn: document.getElementById('explainBtn'),
     reviewBtn: document.getElementById('reviewBtn'),
     summaryBtn: document.getElementById('summaryBtn'),
+    annotateBtn: document.getElementById('annotateBtn'),
     exportBtn: document.getElementById('exportBtn'),
     chatPanel: document.getElementById('chatPanel'),
     chatMessages: document.getElementById('chatMessages'),
@@ -56,12 +70,18 @@ const elements = {
 // API Functions
 // ============================================
 
-async function fetchJSON(url, options = {}) {
+// API token for servers started with DIFFLEARN_API_TOKENS; asked for on the
+// first 401 and kept in localStorage.
+let apiToken = localStorage.getItem('difflearnToken') || '';
+
+async function fetchJSON(url, options = {}, retried = false) {
     try {
-        const response = await fetch(API_URL + url, {
-            headers: { 'Content-Type': 'application/json' },
-            ...options,
-        });
+        const headers = { 'Content-Type': 'application/json' };
+        if (apiToken) headers.Authorization = `Bearer ${apiToken}`;
+        const response = await fetch(API_URL + url, { headers, ...options });
+        if (response.status === 401 && !retried && await askForToken()) {
+            return fetchJSON(url, options, true);
+        }
         return await response.json();
     } catch (error) {
         console.error('API Error:', error);
@@ -69,6 +89,27 @@ async function fetchJSON(url, options = {}) {
     }
 }
 
+// askForToken prompts once for all the requests rejected at the same time.
+let tokenPrompt = null;
+function askForToken() {
+    if (!tokenPrompt) {
+        tokenPrompt = Promise.resolve().then(() => {
+            const token = prompt('This DiffLearn server needs an API token:');
+            tokenPrompt = null;
+            if (!token) return false;
+            apiToken = token.trim();
+            localStorage.setItem('difflearnToken', apiToken);
+            return true;
+        });
+    }
+    return tokenPrompt;
+}
+
+// withToken adds the API token to URLs the browser loads itself.
+function withToken(url) {
+    return apiToken ? `${url}&token=${encodeURIComponent(apiToken)}` : url;
+}
+
 async function checkLLMStatus() {
     const result = await fetchJSON('/');
     const statusDot = elements.llmStatus.querySelector('.status-dot');
@@ -93,17 +134,147 @@ async function checkLLMStatus() {
     }
 }
 
+// Adds the branch, HEAD, working tree state and main languages from /repo
+// under the directory line.
+async function loadRepoInfo() {
+    const result = await fetchJSON('/repo');
+    const cwdEl = document.getElementById('cwdDisplay');
+    if (!result.success || !cwdEl) return;
+    const repo = result.data;
+    const parts = [
+        repo.detached ? `detached at ${repo.head.slice(0, 7)}` : `${repo.currentBranch} @ ${(repo.head || '').slice(0, 7) || 'no commits'}`,
+        repo.dirty ? 'uncommitted changes' : 'clean',
+        `${repo.totalCommits} commit(s)`,
+    ];
+    if (repo.languages.length) {
+        parts.push(repo.languages.slice(0, 3).map(l => `${l.name} ${l.percent}%`).join(', '));
+    }
+    cwdEl.textContent = `You are seeing the Diffs for this directory: ${repo.root} (${parts.join(' · ')})`;
+    cwdEl.title = [
+        repo.defaultBranch ? `Default branch: ${repo.defaultBranch}` : '',
+        ...repo.remotes.map(r => `${r.name}: ${r.fetchUrl}`),
+    ].filter(Boolean).join('\n');
+}
+
+// Shows the study streak and weekly goal from /progress; refreshed after
+// anything that counts as studying (opening a commit, AI answers).
+async function loadProgress() {
+    const result = await fetchJSON('/progress');
+    if (!result.success || !elements.progressBadge) return;
+    const p = result.data;
+    const goal = p.goal.commitsPerWeek;
+    const week = goal > 0 ? `${p.commitsThisWeek}/${goal}` : `${p.commitsThisWeek}`;
+    elements.progressBadge.textContent = `🔥 ${p.currentStreak}d · 📅 ${week}${p.goalMet ? ' ✅' : ''}`;
+    elements.progressBadge.title = `Study streak: ${p.currentStreak} day(s), longest ${p.longestStreak}\n` +
+        `Commits studied this week: ${week}\n` +
+        `AI explanations this week: ${p.explanationsThisWeek}${p.goal.explanationsPerWeek > 0 ? `/${p.goal.explanationsPerWeek}` : ''}\n` +
+        `Weeks in a row with the goal met: ${p.weeklyStreak}`;
+    elements.progressBadge.setAttribute('aria-label', elements.progressBadge.title.replace(/\n/g, '. '));
+    elements.progressBadge.style.display = '';
+}
+
+async function loadProviderOptions() {
+    const result = await fetchJSON('/providers');
+    const select = elements.modelSelect;
+    if (!select || !result.success) {
+        return;
+    }
+
+    const options = [];
+    for (const option of result.data.providers || []) {
+        if (!option.available) {
+            continue;
+        }
+        for (const model of option.models || []) {
+            if (model === '*') {
+                continue;
+            }
+            options.push({ provider: option.provider, model });
+        }
+    }
+    if (options.length <= 1) {
+        select.style.display = 'none';
+        return;
+    }
+
+    const active = result.data.active || {};
+    const saved = localStorage.getItem('modelSelection');
+    const values = options.map(o => `${o.provider}:${o.model}`);
+    const selected = saved && values.includes(saved) ? saved : `${active.provider}:${active.model}`;
+    select.innerHTML = options.map(o => {
+        const value = `${o.provider}:${o.model}`;
+        return `<option value="${escapeHtml(value)}"${value === selected ? ' selected' : ''}>${escapeHtml(o.model)} (${escapeHtml(o.provider)})</option>`;
+    }).join('');
+    select.style.display = '';
+}
+
+function initModelSelect() {
+    elements.modelSelect?.addEventListener('change', () => localStorage.setItem('modelSelection', elements.modelSelect.value));
+}
+
+function getModelSelectionPayload() {
+    const select = elements.modelSelect;
+    if (!select || select.style.display === 'none' || !select.value) {
+        return {};
+    }
+    const [provider, ...rest] = select.value.split(':');
+    return { provider, model: rest.join(':') };
+}
+
 async function fetchLocalDiff(staged = false) {
-    const url = staged ? '/diff/local?staged=true' : '/diff/local';
-    return await fetchJSON(url);
+    const params = new URLSearchParams();
+    if (staged) params.set('staged', 'true');
+    if (!staged && includeUntracked) params.set('untracked', 'true');
+    const query = params.toString();
+    return await fetchJSON(query ? `/diff/local?${query}` : '/diff/local');
 }
 
 async function fetchCommitDiff(sha) {
     return await fetchJSON(`/diff/commit/${sha}`);
 }
 
-async function fetchHistory(limit = 20) {
-    return await fetchJSON(`/history?limit=${limit}`);
+// Size bucket and type guess of a history commit, e.g. "M feat", so
+// learners can pick small, focused commits to study.
+function commitLabelHtml(commit) {
+    if (!commit.size) return '';
+    const lines = `+${commit.additions || 0} −${commit.deletions || 0} lines`;
+    const type = commit.type ? ` ${escapeHtml(commit.type)}` : '';
+    return `<span class="commit-label size-${commit.size.toLowerCase()}" title="${lines}">${commit.size}${type}</span>`;
+}
+
+// revertBadgeHtml marks a commit that was reverted, reverts another or
+// brings a reverted change back, naming the other commits of the pair.
+function revertBadgeHtml(commit) {
+    const shortHash = (c) => c.hash.slice(0, 7);
+    for (const pair of revertPairs) {
+        let text = '';
+        let title = '';
+        if (pair.original.hash === commit.hash) {
+            text = '↩ reverted';
+            title = `Reverted in ${shortHash(pair.revert)}` + (pair.reapply ? `, reapplied in ${shortHash(pair.reapply)}` : '');
+        } else if (pair.revert.hash === commit.hash) {
+            text = '↩ revert';
+            title = `Reverts ${shortHash(pair.original)}`;
+        } else if (pair.reapply && pair.reapply.hash === commit.hash) {
+            text = '↪ reapply';
+            title = `Reapplies ${shortHash(pair.original)}`;
+        } else {
+            continue;
+        }
+        title += `; difflearn history reverts ${shortHash(commit)} explains why`;
+        return `<span class="commit-label revert-label" title="${escapeHtml(title)}">${text}</span>`;
+    }
+    return '';
+}
+
+async function fetchRevertPairs() {
+    return await fetchJSON('/history/reverts');
+}
+
+async function fetchHistory(limit = 20, cursor = '') {
+    const params = new URLSearchParams({ limit, files: 50 });
+    if (cursor) params.set('cursor', cursor);
+    return await fetchJSON(`/history?${params.toString()}`);
 }
 
 async function fetchBranches() {
@@ -119,6 +290,19 @@ async function fetchBranchDiff(base, target, mode = 'triple') {
     return await fetchJSON(`/diff/branch?${params.toString()}`);
 }
 
+async function fetchBranchSummary(base, target, mode = 'triple') {
+    const params = new URLSearchParams({ base, target, mode });
+    return await fetchJSON(`/diff/branch/summary?${params.toString()}`);
+}
+
+async function fetchBranchFile(base, target, path, oldPath = '', mode = 'triple') {
+    const params = new URLSearchParams({ base, target, path, mode });
+    if (oldPath) {
+        params.set('oldPath', oldPath);
+    }
+    return await fetchJSON(`/diff/branch/file?${params.toString()}`);
+}
+
 async function switchBranch(branch, autoStash = true) {
     return await fetchJSON('/branch/switch', {
         method: 'POST',
@@ -126,7 +310,43 @@ async function switchBranch(branch, autoStash = true) {
     });
 }
 
+function initLevelSelect() {
+    const select = elements.levelSelect;
+    if (!select) {
+        return;
+    }
+    select.value = localStorage.getItem('audienceLevel') || '';
+    select.addEventListener('change', () => localStorage.setItem('audienceLevel', select.value));
+}
+
+// The audience level AI answers are written for; empty uses the server's
+// DIFFLEARN_LEVEL.
+function getLevelPayload() {
+    const level = elements.levelSelect ? elements.levelSelect.value : '';
+    return level ? { level } : {};
+}
+
+function initLanguageSelect() {
+    const select = elements.languageSelect;
+    if (!select) {
+        return;
+    }
+    select.value = localStorage.getItem('answerLanguage') || '';
+    select.addEventListener('change', () => localStorage.setItem('answerLanguage', select.value));
+}
+
+// The language AI answers are written in; empty uses the server's
+// DIFFLEARN_LANGUAGE.
+function getLanguagePayload() {
+    const language = elements.languageSelect ? elements.languageSelect.value : '';
+    return language ? { language } : {};
+}
+
 function getDiffRequestPayload() {
+    return { ...getDiffContextPayload(), ...getModelSelectionPayload(), ...getLevelPayload(), ...getLanguagePayload() };
+}
+
+function getDiffContextPayload() {
     if (currentDiffContext.type === 'branch_compare' && currentDiffContext.branchBase && currentDiffContext.branchTarget) {
         return {
             branchBase: currentDiffContext.branchBase,
@@ -141,8 +361,10 @@ function getDiffRequestPayload() {
         };
     }
 
+    const staged = currentDiffContext.type === 'staged';
     return {
-        staged: currentDiffContext.type === 'staged',
+        staged,
+        untracked: !staged && includeUntracked,
     };
 }
 
@@ -167,6 +389,21 @@ async function reviewDiff(contextPayload = {}) {
     });
 }
 
+async function annotateDiff(contextPayload = {}) {
+    return await fetchJSON('/annotate', {
+        method: 'POST',
+        body: JSON.stringify(contextPayload),
+    });
+}
+
+async function fetchSessions(query = '') {
+    return await fetchJSON(`/sessions?limit=50&q=${encodeURIComponent(query)}`);
+}
+
+async function fetchSession(id) {
+    return await fetchJSON(`/sessions/${encodeURIComponent(id)}`);
+}
+
 async function summarizeDiff(contextPayload = {}) {
     return await fetchJSON('/summary', {
         method: 'POST',
@@ -183,6 +420,8 @@ function renderCommitList() {
         renderHistoryList();
     } else if (currentView === 'branches') {
         renderBranchView();
+    } else if (currentView === 'sessions') {
+        renderSessionList();
     } else {
         renderLocalChangesItem();
     }
@@ -192,6 +431,11 @@ function getBranchByRef(ref) {
     return branchEntries.find(branch => branch.ref === ref || branch.name === ref);
 }
 
+// Base and target also accept tags; only branches can be switched to.
+function isComparableRef(ref) {
+    return Boolean(getBranchByRef(ref) || tagEntries.find(tag => tag.name === ref));
+}
+
 function getBranchLabel(branch) {
     if (!branch) return '';
     const tags = [];
@@ -201,12 +445,19 @@ function getBranchLabel(branch) {
     return `${branch.name}${suffix}`;
 }
 
-function renderBranchOptions(selectedRef) {
-    return branchEntries.map(branch => `
+function renderBranchOptions(selectedRef, withTags = false) {
+    const branches = branchEntries.map(branch => `
       <option value="${escapeHtml(branch.ref)}" ${branch.ref === selectedRef ? 'selected' : ''}>
         ${escapeHtml(getBranchLabel(branch))}
       </option>
     `).join('');
+    if (!withTags || tagEntries.length === 0) return branches;
+    const tags = tagEntries.map(tag => `
+      <option value="${escapeHtml(tag.name)}" ${tag.name === selectedRef ? 'selected' : ''}>
+        ${escapeHtml(tag.name)}
+      </option>
+    `).join('');
+    return `<optgroup label="Branches">${branches}</optgroup><optgroup label="Tags">${tags}</optgroup>`;
 }
 
 function updateBranchNotice() {
@@ -289,6 +540,7 @@ async function renderBranchView() {
     }
 
     branchEntries = result.data.branches || [];
+    tagEntries = result.data.tags || [];
     currentBranchName = result.data.currentBranch || '';
 
     const currentEntry = branchEntries.find(branch => branch.current)
@@ -308,11 +560,16 @@ async function renderBranchView() {
     if (!getBranchByRef(branchSelection.switchTo)) {
         branchSelection.switchTo = currentEntry.ref;
     }
-    if (!getBranchByRef(branchSelection.base)) {
-        branchSelection.base = currentEntry.ref;
+    // Compare against the default branch unless it is the one checked out.
+    const defaultName = result.data.defaultBranch || '';
+    const defaultEntry = branchEntries.find(branch => branch.kind === 'local' && branch.name === defaultName)
+        || branchEntries.find(branch => branch.kind === 'remote' && branch.localName === defaultName);
+    if (!isComparableRef(branchSelection.base)) {
+        branchSelection.base = defaultEntry && defaultEntry.ref !== currentEntry.ref ? defaultEntry.ref : currentEntry.ref;
     }
-    if (!getBranchByRef(branchSelection.target) || branchSelection.target === branchSelection.base) {
-        const fallbackTarget = branchEntries.find(branch => branch.ref !== branchSelection.base) || currentEntry;
+    if (!isComparableRef(branchSelection.target) || branchSelection.target === branchSelection.base) {
+        const fallbackTarget = (currentEntry.ref !== branchSelection.base && currentEntry)
+            || branchEntries.find(branch => branch.ref !== branchSelection.base) || currentEntry;
         branchSelection.target = fallbackTarget.ref;
     }
     if (branchSelection.mode !== 'double') {
@@ -328,17 +585,17 @@ async function renderBranchView() {
         </div>
         <div class=\"branch-row\">
           <label class=\"branch-label\" for=\"branchBaseSelect\">Base</label>
-          <select id=\"branchBaseSelect\" class=\"branch-select\">${renderBranchOptions(branchSelection.base)}</select>
+          <select id=\"branchBaseSelect\" class=\"branch-select\">${renderBranchOptions(branchSelection.base, true)}</select>
         </div>
         <div class=\"branch-row\">
           <label class=\"branch-label\" for=\"branchTargetSelect\">Target</label>
-          <select id=\"branchTargetSelect\" class=\"branch-select\">${renderBranchOptions(branchSelection.target)}</select>
+          <select id=\"branchTargetSelect\" class=\"branch-select\">${renderBranchOptions(branchSelection.target, true)}</select>
         </div>
         <div class=\"branch-mode-row\">
           <label><input type=\"radio\" name=\"branchCompareMode\" value=\"triple\" ${branchSelection.mode === 'triple' ? 'checked' : ''}> merge-base...target</label>
           <label><input type=\"radio\" name=\"branchCompareMode\" value=\"double\" ${branchSelection.mode === 'double' ? 'checked' : ''}> base..target</label>
         </div>
-        <button id=\"compareBranchesBtn\" class=\"compare-go-btn\">Compare Branches</button>
+        <button id=\"compareBranchesBtn\" class=\"compare-go-btn\">Compare</button>
         <div id=\"branchNotice\" class=\"branch-notice\" style=\"display:none;\"></div>
       </div>
     `;
@@ -357,20 +614,33 @@ async function renderLocalChangesItem() {
       <div class="commit-message">${label}</div>
       <div class="commit-meta">
         <span>Click to view</span>
+        ${staged ? '' : `
+        <label class="untracked-toggle">
+          <input type="checkbox" id="untrackedToggle" ${includeUntracked ? 'checked' : ''}>
+          Include untracked
+        </label>`}
       </div>
     </div>
   `;
 
+    document.getElementById('untrackedToggle')?.addEventListener('change', async (e) => {
+        includeUntracked = e.target.checked;
+        localStorage.setItem('includeUntracked', String(includeUntracked));
+        await loadLocalDiff(false);
+    });
+
     // Auto-load local changes
     await loadLocalDiff(staged);
 }
 
-async function renderHistoryList() {
-    elements.commitList.innerHTML = '<div class="loading">Loading commits...</div>';
+async function renderHistoryList(loadMore = false) {
+    if (!loadMore) {
+        elements.commitList.innerHTML = '<div class="loading">Loading commits...</div>';
+    }
 
-    const result = await fetchHistory(30);
+    const result = await fetchHistory(30, loadMore ? historyCursor : '');
 
-    if (!result.success || !result.data || result.data.length === 0) {
+    if (!loadMore && (!result.success || !result.data || result.data.length === 0)) {
         elements.commitList.innerHTML = `
       <div class="empty-state">
         <div class="empty-icon">📭</div>
@@ -380,7 +650,112 @@ async function renderHistoryList() {
         return;
     }
 
-    commits = result.data;
+    if (result.success) {
+        commits = loadMore ? commits.concat(result.data || []) : result.data;
+        historyCursor = result.pagination?.nextCursor || '';
+    }
+    renderCommitItems();
+    if (!loadMore) {
+        // Finding reverts reads patches, so the list does not wait for it.
+        fetchRevertPairs().then(pairs => {
+            revertPairs = pairs.success ? pairs.data : [];
+            if (revertPairs.length > 0 && currentView === 'history') renderCommitItems();
+        }).catch(() => {});
+    }
+
+    // Auto-load first commit if no compare selection
+    if (!loadMore && commits.length > 0 && selectedForCompare.length === 0) {
+        await loadCommitDiff(commits[0].hash);
+    }
+}
+
+// Past explain/review/ask answers, searchable by question, ref and text.
+async function renderSessionList() {
+    elements.commitList.innerHTML = `
+      <input type="search" id="sessionSearch" class="session-search" placeholder="Search past answers..."
+             aria-label="Search past answers" value="${escapeHtml(sessionQuery)}">
+      <div id="sessionItems"><div class="loading">Loading notes...</div></div>
+    `;
+    const search = document.getElementById('sessionSearch');
+    search.addEventListener('input', () => {
+        clearTimeout(sessionSearchTimeout);
+        sessionSearchTimeout = setTimeout(() => {
+            sessionQuery = search.value.trim();
+            renderSessionItems();
+        }, 250);
+    });
+    await renderSessionItems();
+}
+
+async function renderSessionItems() {
+    const container = document.getElementById('sessionItems');
+    if (!container) return;
+
+    const result = await fetchSessions(sessionQuery);
+    if (!result.success || !result.data || result.data.length === 0) {
+        container.innerHTML = `
+      <div class="empty-state">
+        <div class="empty-icon">📓</div>
+        <p>${result.success ? 'No saved answers yet' : `Error loading notes: ${escapeHtml(result.error || 'Unknown error')}`}</p>
+      </div>
+    `;
+        return;
+    }
+
+    container.innerHTML = result.data.map(session => `
+    <div class="commit-item session-item" data-type="session" data-id="${session.id}" role="option" tabindex="0" aria-selected="false">
+      <div class="commit-content">
+        <div class="commit-hash">${escapeHtml(session.kind)} · ${escapeHtml(session.ref || 'working tree')}</div>
+        <div class="commit-message">${escapeHtml(sessionTitle(session))}</div>
+        <div class="commit-meta">
+          <span>${formatDate(session.time)}</span>
+          <span>${escapeHtml(session.model || session.provider)}</span>
+        </div>
+      </div>
+    </div>
+  `).join('');
+}
+
+function sessionTitle(session) {
+    if (session.selection && session.selection.question) return session.selection.question;
+    const line = (session.response || '').split('\n').find(l => l.trim() !== '') || '';
+    return line.replace(/^[#>*\-\s]+/, '').slice(0, 80);
+}
+
+async function loadSession(id) {
+    document.querySelectorAll('.commit-item').forEach(el => {
+        el.classList.toggle('active', el.dataset.id === id);
+        el.setAttribute('aria-selected', el.dataset.id === id ? 'true' : 'false');
+    });
+    elements.diffContent.innerHTML = '<div class="loading">Loading note...</div>';
+
+    const result = await fetchSession(id);
+    if (!result.success) {
+        elements.diffContent.innerHTML = `
+      <div class="empty-state">
+        <div class="empty-icon">❌</div>
+        <p>Error loading note: ${escapeHtml(result.error || 'Unknown error')}</p>
+      </div>
+    `;
+        return;
+    }
+
+    const session = result.data;
+    elements.diffHeader.querySelector('h2').textContent = `${session.kind}: ${session.ref || 'working tree'}`;
+    elements.diffStats.innerHTML = `<span>${new Date(session.time).toLocaleString()}</span>`;
+    elements.quickActions.style.display = 'none';
+    const question = session.selection && session.selection.question;
+    elements.diffContent.innerHTML = `
+    <article class="session-note">
+      ${question ? `<p class="session-question">${escapeHtml(question)}</p>` : ''}
+      <div class="message-content">${marked.parse(session.response || '')}</div>
+      <div class="session-meta">${escapeHtml([session.provider, session.model, session.diffHash && `diff ${session.diffHash.slice(0, 12)}`].filter(Boolean).join(' · '))}</div>
+      ${session.prompt ? `<details class="session-prompt"><summary>Prompt</summary><pre>${escapeHtml(session.prompt)}</pre></details>` : ''}
+    </article>
+  `;
+}
+
+function renderCommitItems() {
 
     // Build the compare bar if commits are selected
     let compareBarHtml = '';
@@ -413,14 +788,15 @@ async function renderHistoryList() {
     elements.commitList.innerHTML = compareBarHtml + commits.map((commit, index) => {
         const isSelected = selectedForCompare.includes(commit.hash);
         const canSelect = selectedForCompare.length < 2 || isSelected;
+        const isActive = selectedForCompare.length === 0 && (currentCommit ? commit.hash === currentCommit : index === 0);
 
         return `
-    <div class="commit-item ${index === 0 && selectedForCompare.length === 0 ? 'active' : ''} ${isSelected ? 'compare-selected' : ''}" 
+    <div class="commit-item ${isActive ? 'active' : ''} ${isSelected ? 'compare-selected' : ''}" 
          data-type="commit" 
          data-sha="${commit.hash}"
          role="option"
          tabindex="0"
-         aria-selected="${index === 0 && selectedForCompare.length === 0 ? 'true' : 'false'}">
+         aria-selected="${isActive ? 'true' : 'false'}">
       <button class="compare-btn ${isSelected ? 'selected' : ''} ${!canSelect ? 'disabled' : ''}" 
               data-sha="${commit.hash}" 
               title="${isSelected ? 'Remove from compare' : 'Add to compare'}"
@@ -431,13 +807,15 @@ async function renderHistoryList() {
         <div class="commit-hash">${commit.hash.slice(0, 7)}</div>
         <div class="commit-message">${escapeHtml(commit.message.split('\n')[0])}</div>
         <div class="commit-meta">
+          ${commitLabelHtml(commit)}
+          ${revertBadgeHtml(commit)}
           <span>${formatDate(commit.date)}</span>
           <span>${escapeHtml(commit.author)}</span>
         </div>
       </div>
     </div>
   `;
-    }).join('');
+    }).join('') + (historyCursor ? '<button class="load-more-btn" id="loadMoreCommits">Load more commits</button>' : '');
 
     // Add compare bar event listeners
     const compareGoBtn = document.getElementById('compareGoBtn');
@@ -465,9 +843,14 @@ async function renderHistoryList() {
         });
     });
 
-    // Auto-load first commit if no compare selection
-    if (commits.length > 0 && selectedForCompare.length === 0) {
-        await loadCommitDiff(commits[0].hash);
+    const loadMoreBtn = document.getElementById('loadMoreCommits');
+    if (loadMoreBtn) {
+        loadMoreBtn.addEventListener('click', async (e) => {
+            e.stopPropagation();
+            loadMoreBtn.disabled = true;
+            loadMoreBtn.textContent = 'Loading...';
+            await renderHistoryList(true);
+        });
     }
 }
 
@@ -543,6 +926,7 @@ async function loadCommitDiff(sha) {
     const commit = commits.find(c => c.hash === sha);
     const title = commit ? `${sha.slice(0, 7)}: ${commit.message.split('\n')[0]}` : sha.slice(0, 7);
     renderDiff(result.data, title);
+    loadProgress();
 }
 
 function renderDiff(data, title) {
@@ -569,11 +953,15 @@ function renderDiff(data, title) {
     }
 
     // Render files
-    elements.diffContent.innerHTML = files.map(file => renderFileDiff(file)).join('');
+    elements.diffContent.innerHTML = files.map((file, index) => renderFileDiff(file, index)).join('');
     elements.quickActions.style.display = 'flex';
+    bindHunkEvents();
+}
 
+function bindHunkEvents() {
     // Add click handlers for hunk headers
-    document.querySelectorAll('.hunk-header').forEach(header => {
+    document.querySelectorAll('.hunk-header:not([data-bound])').forEach(header => {
+        header.dataset.bound = 'true';
         header.addEventListener('click', (e) => {
             const btn = e.target.closest('.ask-btn');
             if (btn) {
@@ -585,23 +973,75 @@ function renderDiff(data, title) {
     });
 }
 
-function renderFileDiff(file) {
-    const status = file.isNew ? 'new' : file.isDeleted ? 'deleted' : file.isRenamed ? 'renamed' : 'modified';
-    const statusLabel = file.isNew ? 'NEW' : file.isDeleted ? 'DEL' : file.isRenamed ? 'REN' : 'MOD';
+function renderFileDiff(file, index = 0) {
+    if (file.submodule) {
+        return renderSubmoduleDiff(file, index);
+    }
+    const status = file.isNew ? 'new' : file.isDeleted ? 'deleted' : file.isCopied ? 'copied' : file.isRenamed ? 'renamed' : 'modified';
+    const statusLabel = file.isNew ? 'NEW' : file.isDeleted ? 'DEL' : file.isCopied ? 'CPY' : file.isRenamed ? 'REN' : 'MOD';
+    const origin = (file.isRenamed || file.isCopied) && file.oldFile !== file.newFile
+        ? `<span class="file-origin" title="${file.similarity ? `${file.similarity}% similar` : ''}">${file.isCopied ? 'copied from' : 'from'} ${escapeHtml(file.oldFile)}</span>`
+        : '';
 
     return `
-    <div class="file-diff">
-      <div class="file-header">
+    <div class="file-diff${file.lazy ? ' lazy' : ''}" data-index="${index}" data-file="${escapeHtml(file.newFile || file.oldFile)}">
+      <div class="file-header"${file.lazy ? ' title="Click to load changes"' : ''}>
         <div class="file-name">
           <span class="file-status ${status}">${statusLabel}</span>
           <span>${escapeHtml(file.newFile || file.oldFile)}</span>
+          ${origin}
         </div>
         <div class="file-stats">
           <span class="stat-add">+${file.additions}</span>
           <span class="stat-del">-${file.deletions}</span>
         </div>
       </div>
-      ${file.hunks.map((hunk, idx) => renderHunk(hunk, idx, file.newFile)).join('')}
+      ${file.binary ? renderBinaryPreview(file) : (file.hunks || []).map((hunk, idx) => renderHunk(hunk, idx, file.newFile)).join('')}
+    </div>
+  `;
+}
+
+function formatBytes(n) {
+    if (n >= 1048576) return `${(n / 1048576).toFixed(1)} MB`;
+    if (n >= 1024) return `${(n / 1024).toFixed(1)} KB`;
+    return `${n} B`;
+}
+
+function renderBinaryPreview(file) {
+    const bin = file.binary;
+    const sizes = !bin.oldBlob
+        ? formatBytes(bin.newSize)
+        : !bin.newBlob
+            ? `${formatBytes(bin.oldSize)} removed`
+            : `${formatBytes(bin.oldSize)} → ${formatBytes(bin.newSize)}`;
+    const meta = `<div class="binary-meta">${escapeHtml(bin.mimeType || 'binary')} · ${sizes}</div>`;
+    if (!bin.isImage) {
+        return meta;
+    }
+    const side = (label, oid, path) => oid
+        ? `<figure><img src="${withToken(`${API_URL}/diff/blob/${encodeURIComponent(oid)}?path=${encodeURIComponent(path)}`)}" alt="${label}: ${escapeHtml(path)}" loading="lazy"><figcaption>${label}</figcaption></figure>`
+        : '';
+    return `${meta}
+    <div class="binary-preview">
+      ${side('Before', bin.oldBlob, file.oldFile)}
+      ${side('After', bin.newBlob, file.newFile)}
+    </div>`;
+}
+
+function renderSubmoduleDiff(file, index) {
+    const sha = (value) => value ? escapeHtml(value.slice(0, 7)) : '(none)';
+    const sub = file.submodule;
+    return `
+    <div class="file-diff" data-index="${index}">
+      <div class="file-header">
+        <div class="file-name">
+          <span class="file-status submodule">SUB</span>
+          <span>${escapeHtml(file.newFile || file.oldFile)}</span>
+        </div>
+        <div class="file-stats submodule-range">
+          <code>${sha(sub.oldCommit)}</code> → <code>${sha(sub.newCommit)}</code>${sub.dirty ? ' (dirty)' : ''}
+        </div>
+      </div>
     </div>
   `;
 }
@@ -629,7 +1069,7 @@ function renderDiffLine(line) {
     const lineNum = type === 'delete' ? (line.oldLineNumber || '') : (line.newLineNumber || '');
 
     return `
-    <div class="diff-line ${cssClass}">
+    <div class="diff-line ${cssClass}" data-new-line="${type === 'delete' ? '' : (line.newLineNumber || '')}" data-old-line="${type === 'delete' ? (line.oldLineNumber || '') : ''}">
       <span class="line-num">${lineNum}</span>
       <span class="line-content">${prefix}${escapeHtml(line.content)}</span>
     </div>
@@ -739,11 +1179,20 @@ async function handleChat(question, contextOverride = null) {
     const loadingEl = addLoadingMessage();
 
     try {
-        const result = await askQuestion(question, getDiffRequestPayload());
+        // Follow-ups continue the conversation while the diff stays the same.
+        const key = JSON.stringify(getDiffContextPayload());
+        const payload = getDiffRequestPayload();
+        if (chatConversation.id && chatConversation.key === key) {
+            payload.conversationId = chatConversation.id;
+        }
+        const result = await askQuestion(question, payload);
 
         removeLoadingMessage();
 
         if (result.success && result.data) {
+            if (result.data.conversationId) {
+                chatConversation = { id: result.data.conversationId, key };
+            }
             const answer = result.data.answer || result.data.prompt || 'No response';
             addMessage('assistant', answer, context);
         } else {
@@ -756,6 +1205,7 @@ async function handleChat(question, contextOverride = null) {
 
     elements.sendBtn.disabled = false;
     elements.chatInput.focus();
+    loadProgress();
 }
 
 async function askAboutHunk(fileName, hunkIndex) {
@@ -771,6 +1221,7 @@ async function askAboutHunk(fileName, hunkIndex) {
 }
 
 function clearChat() {
+    chatConversation = { id: '', key: '' };
     elements.chatMessages.innerHTML = `
     <div class="chat-welcome">
       <p>👋 Ask questions about the selected diff!</p>
@@ -791,7 +1242,8 @@ async function handleQuickAction(action) {
     const questions = {
         explain: 'Please explain these changes.',
         review: 'Please review these changes for potential issues.',
-        summary: 'Please provide a summary of these changes.'
+        summary: 'Please provide a summary of these changes.',
+        annotate: 'Please comment on specific lines of these changes.'
     };
 
     const context = getContextLabel();
@@ -809,17 +1261,28 @@ async function handleQuickAction(action) {
                 result = await explainDiff(requestPayload);
                 break;
             case 'review':
-                result = await reviewDiff(requestPayload);
+                result = await reviewDiff({ ...requestPayload, structured: true });
+                if (result.success && result.data?.structured) {
+                    applyLineAnnotations(result.data.structured.issues);
+                }
                 break;
             case 'summary':
                 result = await summarizeDiff(requestPayload);
                 break;
+            case 'annotate':
+                result = await annotateDiff(requestPayload);
+                if (result.success && result.data?.annotations) {
+                    applyLineAnnotations(result.data.annotations);
+                    const dropped = result.data.dropped?.length || 0;
+                    result.data.notice = `💬 Added ${result.data.annotations.length} inline comment(s) to the diff.${dropped ? ` ${dropped} referred to lines outside the diff and were dropped.` : ''}`;
+                }
+                break;
         }
 
         removeLoadingMessage();
 
         if (result.success && result.data) {
-            const content = result.data.explanation || result.data.review || result.data.summary || result.data.prompt || 'No response';
+            const content = result.data.explanation || result.data.review || result.data.summary || result.data.notice || result.data.prompt || 'No response';
             addMessage('assistant', content, context);
         } else {
             addMessage('assistant', `Error: ${result.error || 'Unknown error'}`, context);
@@ -831,6 +1294,33 @@ async function handleQuickAction(action) {
 
     btn.disabled = false;
     btn.innerHTML = originalText;
+    loadProgress();
+}
+
+// Attaches review issues or AI annotations below the diff lines they refer to.
+// Items without a matching line are shown under the file header.
+function applyLineAnnotations(issues = []) {
+    document.querySelectorAll('.review-annotation').forEach(el => el.remove());
+    issues.forEach(issue => {
+        const fileEl = [...document.querySelectorAll('.file-diff')].find(el => el.dataset.file === issue.file);
+        if (!fileEl) return;
+        const note = document.createElement('div');
+        note.className = `review-annotation severity-${issue.severity}`;
+        note.setAttribute('role', 'note');
+        note.innerHTML = `
+          <span class="review-severity">${escapeHtml(issue.severity)}</span>
+          <span class="review-message">${escapeHtml(issue.message)}</span>
+          ${issue.suggestion ? `<div class="review-suggestion">💡 ${escapeHtml(issue.suggestion)}</div>` : ''}
+        `;
+        const side = issue.side === 'old' ? 'old' : 'new';
+        const lineEl = issue.line ? fileEl.querySelector(`.diff-line[data-${side}-line="${issue.line}"]`) : null;
+        if (lineEl) {
+            lineEl.classList.add('annotated');
+            lineEl.after(note);
+        } else {
+            fileEl.querySelector('.file-header').after(note);
+        }
+    });
 }
 
 // ============================================
@@ -899,6 +1389,7 @@ const SLASH_COMMANDS = [
     { cmd: '/explain', desc: 'Get AI explanation of changes', action: 'explain' },
     { cmd: '/review', desc: 'Get AI code review', action: 'review' },
     { cmd: '/summarize', desc: 'Get AI summary of changes', action: 'summary' },
+    { cmd: '/annotate', desc: 'Get AI comments on specific lines', action: 'annotate' },
     { cmd: '/export', desc: 'Export diff as markdown', action: 'export' },
     { cmd: '/local', desc: 'Switch to local changes view', action: 'local' },
     { cmd: '/staged', desc: 'Switch to staged changes view', action: 'staged' },
@@ -929,6 +1420,7 @@ function handleSlashCommand(input) {
         case 'explain':
         case 'review':
         case 'summary':
+        case 'annotate':
             if (!currentDiff || !currentDiff.files || currentDiff.files.length === 0) {
                 addMessage('assistant', '⚠️ No changes to analyze. Select local/staged changes or a commit with content first.');
             } else {
@@ -1000,12 +1492,12 @@ function toggleCompareCommit(hash) {
         selectedForCompare.push(hash);
     }
     // Re-render to update UI
-    renderHistoryList();
+    renderCommitItems();
 }
 
 function clearCompareSelection() {
     selectedForCompare = [];
-    renderHistoryList();
+    renderCommitItems();
 }
 
 async function loadComparisonDiff() {
@@ -1061,7 +1553,7 @@ async function loadBranchComparisonDiff(baseRef, targetRef, mode = 'triple') {
     elements.diffContent.innerHTML = '<div class="loading">Loading branch comparison...</div>';
 
     try {
-        const result = await fetchBranchDiff(baseRef, targetRef, mode);
+        const result = await fetchBranchSummary(baseRef, targetRef, mode);
 
         if (!result.success) {
             elements.diffContent.innerHTML = `
@@ -1073,7 +1565,20 @@ async function loadBranchComparisonDiff(baseRef, targetRef, mode = 'triple') {
             return;
         }
 
-        currentDiff = result.data;
+        // The summary only carries per-file stats; hunks are fetched when a file is expanded.
+        const files = (result.data.files || []).map(stat => ({
+            oldFile: stat.oldPath || stat.path,
+            newFile: stat.path,
+            isBinary: stat.isBinary,
+            isNew: false,
+            isDeleted: false,
+            isRenamed: Boolean(stat.oldPath && stat.oldPath !== stat.path),
+            additions: stat.additions,
+            deletions: stat.deletions,
+            hunks: [],
+            lazy: true,
+        }));
+        currentDiff = { summary: result.data.summary, files, comparison: result.data.comparison };
         currentCommit = null;
         currentDiffContext = {
             type: 'branch_compare',
@@ -1088,7 +1593,8 @@ async function loadBranchComparisonDiff(baseRef, targetRef, mode = 'triple') {
         const targetBranch = getBranchByRef(targetRef);
         const operator = mode === 'double' ? '..' : '...';
         const title = `${baseBranch?.name || baseRef} ${operator} ${targetBranch?.name || targetRef}`;
-        renderDiff(result.data, title);
+        renderDiff(currentDiff, title);
+        bindLazyFileEvents();
 
         const comparison = result.data?.comparison;
         if (comparison?.messages?.length > 0) {
@@ -1104,6 +1610,44 @@ async function loadBranchComparisonDiff(baseRef, targetRef, mode = 'triple') {
     }
 }
 
+function bindLazyFileEvents() {
+    document.querySelectorAll('.file-diff.lazy .file-header').forEach(header => {
+        header.addEventListener('click', () => expandLazyFile(header.closest('.file-diff')));
+    });
+}
+
+async function expandLazyFile(fileEl) {
+    if (!fileEl || !fileEl.classList.contains('lazy') || fileEl.dataset.loading === 'true') {
+        return;
+    }
+    const index = Number(fileEl.dataset.index);
+    const file = currentDiff?.files?.[index];
+    if (!file) {
+        return;
+    }
+
+    fileEl.dataset.loading = 'true';
+    fileEl.insertAdjacentHTML('beforeend', '<div class="loading">Loading hunks...</div>');
+    const { branchBase, branchTarget, branchMode } = currentDiffContext;
+    const result = await fetchBranchFile(branchBase, branchTarget, file.newFile, file.isRenamed ? file.oldFile : '', branchMode);
+    fileEl.querySelector('.loading')?.remove();
+    fileEl.dataset.loading = 'false';
+
+    if (!result.success) {
+        fileEl.insertAdjacentHTML('beforeend', `<div class="empty-state"><p>${escapeHtml(result.error || 'Unknown error')}</p></div>`);
+        return;
+    }
+
+    const loaded = (result.data.files || [])[0];
+    if (loaded) {
+        Object.assign(file, loaded, { lazy: false });
+    } else {
+        file.lazy = false;
+    }
+    fileEl.outerHTML = renderFileDiff(file, index);
+    bindHunkEvents();
+}
+
 async function handleBranchSwitch(branchRef) {
     const selected = getBranchByRef(branchRef);
     const branchName = selected?.name || branchRef;
@@ -1139,6 +1683,9 @@ async function handleBranchSwitch(branchRef) {
     `;
 
     await checkLLMStatus();
+    loadRepoInfo();
+    loadProgress();
+    await loadProviderOptions();
     await renderCommitList();
 }
 
@@ -1169,6 +1716,8 @@ elements.commitList.addEventListener('click', async (e) => {
         return;
     }
 
+    if (e.target.closest('.untracked-toggle')) return;
+
     const item = e.target.closest('.commit-item');
     if (!item) return;
 
@@ -1177,6 +1726,8 @@ elements.commitList.addEventListener('click', async (e) => {
         await loadLocalDiff(staged);
     } else if (item.dataset.type === 'commit') {
         await loadCommitDiff(item.dataset.sha);
+    } else if (item.dataset.type === 'session') {
+        await loadSession(item.dataset.id);
     }
 });
 
@@ -1198,6 +1749,7 @@ elements.clearChatBtn.addEventListener('click', clearChat);
 elements.explainBtn.addEventListener('click', () => handleQuickAction('explain'));
 elements.reviewBtn.addEventListener('click', () => handleQuickAction('review'));
 elements.summaryBtn.addEventListener('click', () => handleQuickAction('summary'));
+elements.annotateBtn.addEventListener('click', () => handleQuickAction('annotate'));
 if (elements.exportBtn) {
     elements.exportBtn.addEventListener('click', handleExport);
 }
@@ -1430,7 +1982,13 @@ async function init() {
     initTheme();
     initShortcutsModal();
     initKeyboardShortcuts();
+    initLevelSelect();
+    initLanguageSelect();
+    initModelSelect();
     await checkLLMStatus();
+    loadRepoInfo();
+    loadProgress();
+    await loadProviderOptions();
     await renderCommitList();
 }
 